@@ -0,0 +1,275 @@
+// Package alerts tells customers when an album they are watching gets cheaper or comes back into
+// stock. It wraps the album, inventory and order repositories so every change made through them is
+// checked against the alerts waiting on that album.
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/notify"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// queueSize is how many alerts can wait to be sent. When the queue is full, further alerts stay
+// active and fire on a later change.
+const queueSize = 256
+
+// Watcher checks album changes against the alerts waiting on them and queues the ones that fire.
+// Run sends them in the background, so a slow mail server never holds up the change that fired them.
+type Watcher struct {
+	Alerts    repository.AlertRepository
+	Albums    repository.AlbumRepository
+	Inventory repository.InventoryRepository
+	Notifier  notify.Notifier
+
+	now   func() time.Time
+	queue chan delivery
+
+	mu     sync.Mutex
+	queued map[string]bool // IDs of the alerts in the queue, so a second change does not send them twice
+}
+
+// delivery is a fired alert waiting to be sent.
+type delivery struct {
+	alert repository.Alert
+	msg   notify.Message
+}
+
+func NewWatcher(alerts repository.AlertRepository, albums repository.AlbumRepository, inventory repository.InventoryRepository, notifier notify.Notifier) *Watcher {
+	return &Watcher{
+		Alerts:    alerts,
+		Albums:    albums,
+		Inventory: inventory,
+		Notifier:  notifier,
+		now:       time.Now,
+		queue:     make(chan delivery, queueSize),
+		queued:    make(map[string]bool),
+	}
+}
+
+// Run sends queued alerts until ctx is cancelled.
+func (w *Watcher) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case d := <-w.queue:
+			w.send(d)
+		}
+	}
+}
+
+// priceDropFires reports whether a price-drop alert fires when the album's price changes from before to after.
+func priceDropFires(alert repository.Alert, before, after repository.Album) bool {
+	if alert.Kind != repository.AlertPriceDrop || before.Currency != after.Currency || after.Price >= before.Price {
+		return false
+	}
+	return alert.TargetPrice == nil || after.Price <= *alert.TargetPrice
+}
+
+// backInStockFires reports whether a back-in-stock alert fires when format of the album becomes
+// available. Alerts for any format only fire if no other format was available already.
+func backInStockFires(alert repository.Alert, format repository.Format, levels []repository.StockLevel) bool {
+	if alert.Kind != repository.AlertBackInStock {
+		return false
+	}
+	if alert.Format != "" {
+		return alert.Format == format
+	}
+	for _, level := range levels {
+		if level.Format != format && level.Available > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// PriceChanged sends the price-drop alerts that fire now the album's price has changed from before to after.
+func (w *Watcher) PriceChanged(before, after repository.Album) error {
+	if after.Price >= before.Price {
+		return nil
+	}
+	subject := fmt.Sprintf("Price drop: %s by %s is now %s %s", after.Title, after.Artist, after.Price, after.Currency)
+	body := fmt.Sprintf("%s by %s has dropped from %s %s to %s %s.", after.Title, after.Artist,
+		before.Price, before.Currency, after.Price, after.Currency)
+	return w.fire(after.ID, func(alert repository.Alert) bool { return priceDropFires(alert, before, after) }, subject, body)
+}
+
+// StockChanged sends the back-in-stock alerts that fire when the available quantity of format of
+// the album changes from availableBefore to availableAfter.
+func (w *Watcher) StockChanged(albumID string, format repository.Format, availableBefore, availableAfter int) error {
+	if availableBefore > 0 || availableAfter <= 0 {
+		return nil
+	}
+	album, err := w.Albums.GetByID(albumID)
+	if err != nil {
+		return err
+	}
+	levels, err := w.Inventory.GetStock(albumID)
+	if err != nil {
+		return err
+	}
+	subject := fmt.Sprintf("Back in stock: %s by %s", album.Title, album.Artist)
+	body := fmt.Sprintf("%s by %s is back in stock on %s.", album.Title, album.Artist, format)
+	return w.fire(albumID, func(alert repository.Alert) bool { return backInStockFires(alert, format, levels) }, subject, body)
+}
+
+// fire queues the message for every alert on the album that fires.
+func (w *Watcher) fire(albumID string, fires func(repository.Alert) bool, subject, body string) error {
+	alerts, err := w.Alerts.ListActiveByAlbum(albumID)
+	if err != nil {
+		return err
+	}
+	for _, alert := range alerts {
+		if !fires(alert) {
+			continue
+		}
+		w.enqueue(delivery{alert: alert, msg: notify.Message{
+			To:      alert.Email,
+			Subject: subject,
+			Body:    body + "\n\nThis alert has now been used; subscribe again to hear about further changes.",
+		}})
+	}
+	return nil
+}
+
+// enqueue adds the delivery to the queue unless its alert is already waiting or the queue is full.
+func (w *Watcher) enqueue(d delivery) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.queued[d.alert.ID] {
+		return
+	}
+	select {
+	case w.queue <- d:
+		w.queued[d.alert.ID] = true
+	default:
+		log.Printf("alerts: queue full; alert %s will fire on a later change", d.alert.ID)
+	}
+}
+
+// send delivers a queued alert and marks it so it does not fire again. An alert that cannot be
+// sent stays active for the next change.
+func (w *Watcher) send(d delivery) {
+	defer func() {
+		w.mu.Lock()
+		delete(w.queued, d.alert.ID)
+		w.mu.Unlock()
+	}()
+	if err := w.Notifier.Send(d.msg); err != nil {
+		log.Printf("alerts: sending alert %s: %v", d.alert.ID, err)
+		return
+	}
+	if err := w.Alerts.MarkTriggered(d.alert.ID, w.now().UTC()); err != nil {
+		log.Printf("alerts: marking alert %s sent: %v", d.alert.ID, err)
+	}
+}
+
+// WatchAlbums returns albums with Update checking price-drop alerts after every successful change.
+func WatchAlbums(albums repository.AlbumRepository, w *Watcher) repository.AlbumRepository {
+	return &watchedAlbums{AlbumRepository: albums, watcher: w}
+}
+
+type watchedAlbums struct {
+	repository.AlbumRepository
+	watcher *Watcher
+}
+
+func (r *watchedAlbums) Update(album repository.Album) error {
+	before, getErr := r.AlbumRepository.GetByID(album.ID)
+	if err := r.AlbumRepository.Update(album); err != nil {
+		return err
+	}
+	if getErr == nil {
+		if err := r.watcher.PriceChanged(before, album); err != nil {
+			log.Printf("alerts: checking price-drop alerts on album %s: %v", album.ID, err)
+		}
+	}
+	return nil
+}
+
+// WatchInventory returns inventory with Adjust and Release checking back-in-stock alerts whenever
+// they make a format available again.
+func WatchInventory(inventory repository.InventoryRepository, w *Watcher) repository.InventoryRepository {
+	return &watchedInventory{InventoryRepository: inventory, watcher: w}
+}
+
+type watchedInventory struct {
+	repository.InventoryRepository
+	watcher *Watcher
+}
+
+func (r *watchedInventory) Adjust(adjustment repository.StockAdjustment) (repository.StockLevel, error) {
+	level, err := r.InventoryRepository.Adjust(adjustment)
+	if err == nil {
+		r.stockChanged(level, adjustment.Delta)
+	}
+	return level, err
+}
+
+func (r *watchedInventory) Release(albumID string, format repository.Format, quantity int) (repository.StockLevel, error) {
+	level, err := r.InventoryRepository.Release(albumID, format, quantity)
+	if err == nil {
+		r.stockChanged(level, quantity)
+	}
+	return level, err
+}
+
+// stockChanged checks alerts after a change that made increase more units of level available.
+func (r *watchedInventory) stockChanged(level repository.StockLevel, increase int) {
+	if err := r.watcher.StockChanged(level.AlbumID, level.Format, level.Available-increase, level.Available); err != nil {
+		log.Printf("alerts: checking back-in-stock alerts on album %s: %v", level.AlbumID, err)
+	}
+}
+
+// stockReleased checks alerts after quantity units of format of the album were released by
+// something other than the inventory, such as an order cancelled inside its own transaction.
+func (w *Watcher) stockReleased(albumID string, format repository.Format, quantity int) error {
+	levels, err := w.Inventory.GetStock(albumID)
+	if err != nil {
+		return err
+	}
+	for _, level := range levels {
+		if level.Format == format {
+			return w.StockChanged(albumID, format, level.Available-quantity, level.Available)
+		}
+	}
+	return nil
+}
+
+// WatchOrders returns orders with UpdateStatus checking back-in-stock alerts when cancelling an order
+// releases the stock it held. Order repositories release that stock themselves, so it never passes
+// through WatchInventory.
+func WatchOrders(orders repository.OrderRepository, w *Watcher) repository.OrderRepository {
+	return &watchedOrders{OrderRepository: orders, watcher: w}
+}
+
+type watchedOrders struct {
+	repository.OrderRepository
+	watcher *Watcher
+}
+
+func (r *watchedOrders) UpdateStatus(id string, status repository.OrderStatus, actor, note string) (repository.Order, error) {
+	order, err := r.OrderRepository.UpdateStatus(id, status, actor, note)
+	if err != nil || status != repository.OrderCancelled {
+		return order, err
+	}
+	type stock struct {
+		albumID string
+		format  repository.Format
+	}
+	released := make(map[stock]int)
+	for _, line := range order.Lines {
+		released[stock{line.AlbumID, line.Format}] += line.Quantity
+	}
+	for s, quantity := range released {
+		if err := r.watcher.stockReleased(s.albumID, s.format, quantity); err != nil {
+			log.Printf("alerts: checking back-in-stock alerts on album %s: %v", s.albumID, err)
+		}
+	}
+	return order, nil
+}
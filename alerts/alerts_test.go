@@ -0,0 +1,272 @@
+package alerts
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/notify"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+type testAlerts struct {
+	repository.AlertRepository
+	alerts []repository.Alert
+}
+
+func (r *testAlerts) ListActiveByAlbum(albumID string) ([]repository.Alert, error) {
+	var active []repository.Alert
+	for _, alert := range r.alerts {
+		if alert.AlbumID == albumID && alert.Active() {
+			active = append(active, alert)
+		}
+	}
+	return active, nil
+}
+
+func (r *testAlerts) MarkTriggered(id string, at time.Time) error {
+	for i := range r.alerts {
+		if r.alerts[i].ID == id {
+			r.alerts[i].TriggeredAt = &at
+			return nil
+		}
+	}
+	return repository.ErrAlertNotFound
+}
+
+type testAlbums struct {
+	repository.AlbumRepository
+	albums map[string]repository.Album
+}
+
+func (r *testAlbums) GetByID(id string) (repository.Album, error) {
+	album, ok := r.albums[id]
+	if !ok {
+		return repository.Album{}, errors.New("album not found")
+	}
+	return album, nil
+}
+
+func (r *testAlbums) Update(album repository.Album) error {
+	r.albums[album.ID] = album
+	return nil
+}
+
+type testInventory struct {
+	repository.InventoryRepository
+	levels map[repository.Format]repository.StockLevel
+}
+
+func (r *testInventory) GetStock(albumID string) ([]repository.StockLevel, error) {
+	var levels []repository.StockLevel
+	for _, level := range r.levels {
+		levels = append(levels, level)
+	}
+	return levels, nil
+}
+
+func (r *testInventory) Adjust(adjustment repository.StockAdjustment) (repository.StockLevel, error) {
+	level := r.levels[adjustment.Format]
+	level.AlbumID, level.Format = adjustment.AlbumID, adjustment.Format
+	level.OnHand += adjustment.Delta
+	level.Available = level.OnHand - level.Reserved
+	r.levels[adjustment.Format] = level
+	return level, nil
+}
+
+type testNotifier struct {
+	sent []notify.Message
+	err  error
+}
+
+func (n *testNotifier) Send(msg notify.Message) error {
+	if n.err != nil {
+		return n.err
+	}
+	n.sent = append(n.sent, msg)
+	return nil
+}
+
+type testOrders struct {
+	repository.OrderRepository
+	inventory *testInventory
+	order     repository.Order
+}
+
+func (r *testOrders) UpdateStatus(id string, status repository.OrderStatus, actor, note string) (repository.Order, error) {
+	if status == repository.OrderCancelled {
+		for _, line := range r.order.Lines {
+			level := r.inventory.levels[line.Format]
+			level.Reserved -= line.Quantity
+			level.Available = level.OnHand - level.Reserved
+			r.inventory.levels[line.Format] = level
+		}
+	}
+	r.order.Status = status
+	return r.order, nil
+}
+
+type fixture struct {
+	alerts    *testAlerts
+	albums    repository.AlbumRepository
+	inventory repository.InventoryRepository
+	orders    *testOrders
+	notifier  *testNotifier
+	watcher   *Watcher
+}
+
+func setup(alerts ...repository.Alert) fixture {
+	f := fixture{
+		alerts:   &testAlerts{alerts: alerts},
+		notifier: &testNotifier{},
+	}
+	albums := &testAlbums{albums: map[string]repository.Album{
+		"1": {ID: "1", Title: "Thriller", Artist: "Michael Jackson", Price: money.MustParse("25.99"), Currency: "GBP"},
+	}}
+	inventory := &testInventory{levels: map[repository.Format]repository.StockLevel{}}
+	f.watcher = NewWatcher(f.alerts, albums, inventory, f.notifier)
+	f.albums = WatchAlbums(albums, f.watcher)
+	f.inventory = WatchInventory(inventory, f.watcher)
+	f.orders = &testOrders{inventory: inventory}
+	return f
+}
+
+// flush sends every queued alert, as Run would.
+func (f fixture) flush() {
+	for {
+		select {
+		case d := <-f.watcher.queue:
+			f.watcher.send(d)
+		default:
+			return
+		}
+	}
+}
+
+func target(s string) *money.Amount {
+	a := money.MustParse(s)
+	return &a
+}
+
+func TestWatchAlbums_PriceDrop(t *testing.T) {
+	f := setup(
+		repository.Alert{ID: "a", AlbumID: "1", Email: "ann@example.com", Kind: repository.AlertPriceDrop},
+		repository.Alert{ID: "b", AlbumID: "1", Email: "bob@example.com", Kind: repository.AlertPriceDrop, TargetPrice: target("15.00")},
+		repository.Alert{ID: "c", AlbumID: "1", Email: "cat@example.com", Kind: repository.AlertBackInStock},
+	)
+	album, _ := f.albums.GetByID("1")
+
+	album.Price = money.MustParse("19.99")
+	require.NoError(t, f.albums.Update(album))
+	f.flush()
+
+	require.Len(t, f.notifier.sent, 1, "only the alert without a target fires")
+	assert.Equal(t, "ann@example.com", f.notifier.sent[0].To)
+	assert.Equal(t, "Price drop: Thriller by Michael Jackson is now 19.99 GBP", f.notifier.sent[0].Subject)
+	assert.Contains(t, f.notifier.sent[0].Body, "from 25.99 GBP to 19.99 GBP")
+	assert.False(t, f.alerts.alerts[0].Active())
+
+	album.Price = money.MustParse("14.99")
+	require.NoError(t, f.albums.Update(album))
+	f.flush()
+	require.Len(t, f.notifier.sent, 2, "alerts fire once; the target is now met")
+	assert.Equal(t, "bob@example.com", f.notifier.sent[1].To)
+}
+
+func TestWatchAlbums_PriceRiseDoesNotFire(t *testing.T) {
+	f := setup(repository.Alert{ID: "a", AlbumID: "1", Email: "ann@example.com", Kind: repository.AlertPriceDrop})
+	album, _ := f.albums.GetByID("1")
+
+	album.Price = money.MustParse("29.99")
+	require.NoError(t, f.albums.Update(album))
+	album.Title = "Thriller (Remastered)"
+	require.NoError(t, f.albums.Update(album))
+	f.flush()
+
+	assert.Empty(t, f.notifier.sent)
+}
+
+func TestWatchInventory_BackInStock(t *testing.T) {
+	f := setup(
+		repository.Alert{ID: "any", AlbumID: "1", Email: "ann@example.com", Kind: repository.AlertBackInStock},
+		repository.Alert{ID: "cd", AlbumID: "1", Email: "bob@example.com", Kind: repository.AlertBackInStock, Format: repository.FormatCD},
+	)
+
+	_, err := f.inventory.Adjust(repository.StockAdjustment{AlbumID: "1", Format: repository.FormatLP, Delta: 5})
+	require.NoError(t, err)
+	f.flush()
+
+	require.Len(t, f.notifier.sent, 1)
+	assert.Equal(t, "ann@example.com", f.notifier.sent[0].To)
+	assert.Equal(t, "Back in stock: Thriller by Michael Jackson", f.notifier.sent[0].Subject)
+	assert.Contains(t, f.notifier.sent[0].Body, "on LP")
+
+	_, err = f.inventory.Adjust(repository.StockAdjustment{AlbumID: "1", Format: repository.FormatLP, Delta: 5})
+	require.NoError(t, err)
+	f.flush()
+	assert.Len(t, f.notifier.sent, 1, "stock that was already available is not news")
+
+	_, err = f.inventory.Adjust(repository.StockAdjustment{AlbumID: "1", Format: repository.FormatCD, Delta: 1})
+	require.NoError(t, err)
+	f.flush()
+	require.Len(t, f.notifier.sent, 2)
+	assert.Equal(t, "bob@example.com", f.notifier.sent[1].To)
+}
+
+func TestBackInStockFires_AnyFormatNeedsAlbumSoldOut(t *testing.T) {
+	alert := repository.Alert{Kind: repository.AlertBackInStock}
+	levels := []repository.StockLevel{{Format: repository.FormatLP, Available: 2}, {Format: repository.FormatCD, Available: 1}}
+
+	assert.False(t, backInStockFires(alert, repository.FormatCD, levels), "the LP was in stock all along")
+	assert.True(t, backInStockFires(alert, repository.FormatCD, levels[1:]))
+}
+
+func TestWatcher_FailedSendStaysActive(t *testing.T) {
+	f := setup(repository.Alert{ID: "a", AlbumID: "1", Email: "ann@example.com", Kind: repository.AlertPriceDrop})
+	f.notifier.err = errors.New("smtp unavailable")
+	album, _ := f.albums.GetByID("1")
+
+	album.Price = money.MustParse("19.99")
+	require.NoError(t, f.albums.Update(album), "a failed alert does not fail the update")
+	f.flush()
+
+	assert.True(t, f.alerts.alerts[0].Active())
+}
+
+func TestWatcher_SendsInBackground(t *testing.T) {
+	f := setup(repository.Alert{ID: "a", AlbumID: "1", Email: "ann@example.com", Kind: repository.AlertPriceDrop})
+	album, _ := f.albums.GetByID("1")
+
+	album.Price = money.MustParse("19.99")
+	require.NoError(t, f.albums.Update(album))
+	assert.Empty(t, f.notifier.sent, "the update does not wait for the alert to be sent")
+	album.Price = money.MustParse("17.99")
+	require.NoError(t, f.albums.Update(album))
+
+	f.flush()
+	assert.Len(t, f.notifier.sent, 1, "an alert waiting to be sent is not queued again")
+}
+
+func TestWatchOrders_CancelledOrderBringsStockBack(t *testing.T) {
+	f := setup(repository.Alert{ID: "a", AlbumID: "1", Email: "ann@example.com", Kind: repository.AlertBackInStock, Format: repository.FormatLP})
+	f.orders.inventory.levels[repository.FormatLP] = repository.StockLevel{AlbumID: "1", Format: repository.FormatLP, OnHand: 2, Reserved: 2}
+	f.orders.order = repository.Order{ID: "order-1", Status: repository.OrderPending, Lines: []repository.OrderLine{
+		{AlbumID: "1", Format: repository.FormatLP, Quantity: 1},
+		{AlbumID: "1", Format: repository.FormatLP, Quantity: 1},
+	}}
+	orders := WatchOrders(f.orders, f.watcher)
+
+	_, err := orders.UpdateStatus("order-1", repository.OrderPaid, "payments", "")
+	require.NoError(t, err)
+	f.flush()
+	assert.Empty(t, f.notifier.sent)
+
+	_, err = orders.UpdateStatus("order-1", repository.OrderCancelled, "user-1", "")
+	require.NoError(t, err)
+	f.flush()
+	require.Len(t, f.notifier.sent, 1, "the LPs the order held are available again")
+	assert.Equal(t, "Back in stock: Thriller by Michael Jackson", f.notifier.sent[0].Subject)
+}
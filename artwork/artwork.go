@@ -0,0 +1,164 @@
+// Package artwork checks uploaded sleeve images, makes the thumbnails albums are shown with, and
+// proxies artwork kept elsewhere.
+package artwork
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	// ErrUnsupportedType is returned for uploads that are not JPEG, PNG or WebP images.
+	ErrUnsupportedType = errors.New("artwork must be a JPEG, PNG or WebP image")
+	// ErrTooManyPixels is returned for images too large to decode safely.
+	ErrTooManyPixels = fmt.Errorf("artwork must be at most %d by %d pixels", MaxDimension, MaxDimension)
+)
+
+// MaxDimension is the widest or tallest image accepted. A 600dpi scan of a 12" sleeve is about
+// 7,500 pixels across.
+const MaxDimension = 10000
+
+// ThumbnailSizes are the longest sides, in pixels, of the thumbnails made for each image.
+var ThumbnailSizes = []int{100, 300, 600}
+
+// contentTypes maps the image formats accepted to their content types.
+var contentTypes = map[string]string{
+	"jpeg": "image/jpeg",
+	"png":  "image/png",
+	"webp": "image/webp",
+}
+
+// Image is an encoded image and what it is.
+type Image struct {
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// Extension returns the file extension for the image's content type, without a dot.
+func (i Image) Extension() string {
+	for format, contentType := range contentTypes {
+		if contentType == i.ContentType {
+			if format == "jpeg" {
+				return "jpg"
+			}
+			return format
+		}
+	}
+	return "bin"
+}
+
+// Thumbnail is a smaller copy of an image made for one of ThumbnailSizes.
+type Thumbnail struct {
+	Image
+	Size int
+}
+
+// Processed is an uploaded image with its thumbnails, smallest first.
+type Processed struct {
+	Original   Image
+	Thumbnails []Thumbnail
+}
+
+// Process checks the upload is a JPEG, PNG or WebP image by its content rather than its name, and
+// makes its thumbnails. Images smaller than a thumbnail size are not enlarged. There is no WebP
+// encoder for Go, so WebP uploads get PNG thumbnails if they have transparency and JPEG ones if not;
+// other uploads get thumbnails in their own format.
+func Process(data []byte) (Processed, error) {
+	img, contentType, err := decode(data)
+	if err != nil {
+		return Processed{}, err
+	}
+	bounds := img.Bounds()
+	processed := Processed{Original: Image{Data: data, ContentType: contentType, Width: bounds.Dx(), Height: bounds.Dy()}}
+	for _, size := range ThumbnailSizes {
+		thumbnail, err := resize(img, size, thumbnailType(img, contentType))
+		if err != nil {
+			return Processed{}, err
+		}
+		processed.Thumbnails = append(processed.Thumbnails, Thumbnail{Image: thumbnail, Size: size})
+	}
+	return processed, nil
+}
+
+// Resize checks the data is a JPEG, PNG or WebP image, as Process does, and scales it so its longest
+// side is at most size pixels, encoding it the way Process encodes thumbnails.
+func Resize(data []byte, size int) (Image, error) {
+	img, contentType, err := decode(data)
+	if err != nil {
+		return Image{}, err
+	}
+	return resize(img, size, thumbnailType(img, contentType))
+}
+
+// decode checks the data is a supported image of an acceptable size by its content and decodes it,
+// returning its content type.
+func decode(data []byte) (image.Image, string, error) {
+	contentType := http.DetectContentType(data)
+	supported := false
+	for _, t := range contentTypes {
+		supported = supported || t == contentType
+	}
+	if !supported {
+		return nil, "", ErrUnsupportedType
+	}
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || contentTypes[format] != contentType {
+		return nil, "", ErrUnsupportedType
+	}
+	if config.Width > MaxDimension || config.Height > MaxDimension {
+		return nil, "", ErrTooManyPixels
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("artwork could not be read: %w", err)
+	}
+	return img, contentType, nil
+}
+
+// thumbnailType returns the content type to encode an image's thumbnails with.
+func thumbnailType(img image.Image, contentType string) string {
+	if contentType == "image/webp" {
+		if opaque, ok := img.(interface{ Opaque() bool }); ok && !opaque.Opaque() {
+			return "image/png"
+		}
+		return "image/jpeg"
+	}
+	return contentType
+}
+
+// resize scales the image so its longest side is at most size pixels, keeping its shape, and encodes it.
+func resize(img image.Image, size int, contentType string) (Image, error) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > size || height > size {
+		if width >= height {
+			width, height = size, max(1, height*size/width)
+		} else {
+			width, height = max(1, width*size/height), size
+		}
+	}
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), img, bounds, draw.Src, nil)
+
+	var buf bytes.Buffer
+	var err error
+	if contentType == "image/png" {
+		err = png.Encode(&buf, scaled)
+	} else {
+		err = jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: 85})
+	}
+	if err != nil {
+		return Image{}, err
+	}
+	return Image{Data: buf.Bytes(), ContentType: contentType, Width: width, Height: height}, nil
+}
@@ -0,0 +1,102 @@
+package artwork
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodedImage(t *testing.T, width, height int, encode func(*bytes.Buffer, image.Image) error) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, encode(&buf, img))
+	return buf.Bytes()
+}
+
+func encodeJPEG(buf *bytes.Buffer, img image.Image) error { return jpeg.Encode(buf, img, nil) }
+func encodePNG(buf *bytes.Buffer, img image.Image) error  { return png.Encode(buf, img) }
+
+func TestProcess_JPEG(t *testing.T) {
+	processed, err := Process(encodedImage(t, 800, 400, encodeJPEG))
+
+	require.NoError(t, err)
+	assert.Equal(t, "image/jpeg", processed.Original.ContentType)
+	assert.Equal(t, "jpg", processed.Original.Extension())
+	assert.Equal(t, 800, processed.Original.Width)
+	require.Len(t, processed.Thumbnails, len(ThumbnailSizes))
+	for i, thumbnail := range processed.Thumbnails {
+		assert.Equal(t, ThumbnailSizes[i], thumbnail.Size)
+		assert.Equal(t, ThumbnailSizes[i], thumbnail.Width, "the longest side is scaled to the size")
+		assert.Equal(t, ThumbnailSizes[i]/2, thumbnail.Height, "the shape is kept")
+		assert.Equal(t, "image/jpeg", thumbnail.ContentType)
+		config, format, err := image.DecodeConfig(bytes.NewReader(thumbnail.Data))
+		require.NoError(t, err)
+		assert.Equal(t, "jpeg", format)
+		assert.Equal(t, thumbnail.Width, config.Width)
+	}
+}
+
+func TestProcess_SmallPNGIsNotEnlarged(t *testing.T) {
+	processed, err := Process(encodedImage(t, 200, 250, encodePNG))
+
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", processed.Original.ContentType)
+	assert.Equal(t, 80, processed.Thumbnails[0].Width)
+	assert.Equal(t, 100, processed.Thumbnails[0].Height)
+	assert.Equal(t, 200, processed.Thumbnails[2].Width)
+	assert.Equal(t, 250, processed.Thumbnails[2].Height)
+	assert.Equal(t, "image/png", processed.Thumbnails[2].ContentType)
+}
+
+func TestProcess_RejectsOtherContent(t *testing.T) {
+	_, err := Process([]byte("GIF89a not really a sleeve"))
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+
+	_, err = Process([]byte("<html><body>hello</body></html>"))
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+
+	truncated := encodedImage(t, 50, 50, encodePNG)[:20]
+	_, err = Process(truncated)
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestProcess_RejectsHugeImages(t *testing.T) {
+	// Only the header is read before the image is rejected, so a small file claiming to be huge is
+	// never decoded.
+	data := encodedImage(t, 1, 1, encodePNG)
+	binary.BigEndian.PutUint32(data[16:20], 20001) // the IHDR chunk's width
+	binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+
+	_, err := Process(data)
+
+	assert.ErrorIs(t, err, ErrTooManyPixels)
+}
+
+func TestProcess_WebP(t *testing.T) {
+	// A 1x1 lossless WebP with transparency.
+	data, err := base64.StdEncoding.DecodeString("UklGRhoAAABXRUJQVlA4TA0AAAAvAAAAEAcQERGIiP4HAA==")
+	require.NoError(t, err)
+
+	processed, err := Process(data)
+
+	require.NoError(t, err)
+	assert.Equal(t, "image/webp", processed.Original.ContentType)
+	assert.Equal(t, "webp", processed.Original.Extension())
+	assert.Equal(t, 1, processed.Thumbnails[0].Width)
+	assert.Equal(t, "image/png", processed.Thumbnails[0].ContentType, "transparency is kept")
+}
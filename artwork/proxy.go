@@ -0,0 +1,302 @@
+package artwork
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	// ErrForbiddenURL is returned for artwork addresses the proxy will not fetch: those that are not
+	// http or https, not on one of its hosts, or that resolve to a private address.
+	ErrForbiddenURL = errors.New("artwork can only be fetched over http or https from an allowed image host")
+	// ErrUpstream is returned when remote artwork cannot be fetched or is not an image.
+	ErrUpstream = errors.New("artwork could not be fetched")
+	// errPrivateAddress is returned when a host resolves to an address inside our own network.
+	errPrivateAddress = errors.New("artwork host resolves to a private address")
+)
+
+// MaxProxySize is the largest size, in pixels along the longest side, artwork is proxied at.
+const MaxProxySize = 1200
+
+// mzstaticSize matches the size at the end of an iTunes artwork address, as in ".../100x100bb.jpg".
+var mzstaticSize = regexp.MustCompile(`/\d+x\d+[a-z]*(-\d+)?\.(jpg|jpeg|png|webp)$`)
+
+// Proxy fetches remote artwork, such as the iTunes images the seed data links to, so browsers load it
+// from the shop instead. Only artwork on Hosts, or their subdomains, is fetched, and never from an
+// address inside our own network, whatever a host resolves to. iTunes addresses are rewritten to ask
+// for the size wanted rather than the 100 pixel thumbnail search results link to. Artwork is resized
+// to the size asked for and kept in CacheDir for TTL.
+type Proxy struct {
+	CacheDir string
+	Hosts    []string
+	TTL      time.Duration
+	MaxBytes int64
+
+	// Client fetches the artwork. The one NewProxy makes refuses to connect to private addresses.
+	Client *http.Client
+
+	now func() time.Time
+}
+
+func NewProxy(cacheDir string, hosts []string, ttl time.Duration, maxBytes int64) *Proxy {
+	p := &Proxy{CacheDir: cacheDir, Hosts: hosts, TTL: ttl, MaxBytes: maxBytes, now: time.Now}
+	dialer := &net.Dialer{Timeout: 5 * time.Second, Control: refusePrivateAddresses}
+	p.Client = &http.Client{
+		Timeout: 15 * time.Second,
+		// No Proxy, so requests cannot be sent on through one configured in the environment.
+		Transport: &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: 5 * time.Second},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 3 {
+				return errors.New("too many redirects")
+			}
+			return p.check(req.URL)
+		},
+	}
+	return p
+}
+
+// refusePrivateAddresses stops connections to loopback, private, link-local, carrier-grade NAT and
+// other addresses that are not on the public internet. It runs after the host is resolved, so a
+// public name pointing at a private address is refused too.
+func refusePrivateAddresses(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !publicAddress(ip) {
+		return errPrivateAddress
+	}
+	return nil
+}
+
+// nonPublicRanges are ranges netip does not count as private that are still not on the public
+// internet. Some IPv6 ranges carry an IPv4 address that a gateway forwards to, so they can reach
+// our network too: NAT64 and 6to4 addresses are unwrapped and their IPv4 address checked instead,
+// and the rest are refused outright.
+var nonPublicRanges = []netip.Prefix{
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use NAT64
+	netip.MustParsePrefix("2001::/32"),      // Teredo
+	netip.MustParsePrefix("2001:db8::/32"),  // documentation
+}
+
+var (
+	nat64     = netip.MustParsePrefix("64:ff9b::/96")
+	sixToFour = netip.MustParsePrefix("2002::/16")
+)
+
+func publicAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if embedded, ok := embeddedIPv4(ip); ok {
+		return publicAddress(embedded)
+	}
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicRanges {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// embeddedIPv4 returns the IPv4 address a NAT64 or 6to4 address is translated to.
+func embeddedIPv4(ip netip.Addr) (netip.Addr, bool) {
+	b := ip.As16()
+	switch {
+	case nat64.Contains(ip):
+		return netip.AddrFrom4([4]byte(b[12:16])), true
+	case sixToFour.Contains(ip):
+		return netip.AddrFrom4([4]byte(b[2:6])), true
+	}
+	return netip.Addr{}, false
+}
+
+// check reports whether the proxy may fetch the address.
+func (p *Proxy) check(u *url.URL) error {
+	if (u.Scheme != "http" && u.Scheme != "https") || u.User != nil || u.Hostname() == "" {
+		return ErrForbiddenURL
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	for _, allowed := range p.Hosts {
+		allowed = strings.ToLower(allowed)
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return nil
+		}
+	}
+	return ErrForbiddenURL
+}
+
+// Get returns the artwork at the address, resized so its longest side is at most size pixels, and
+// when it was fetched. A size of 0 asks for MaxProxySize from iTunes, and the image as it is from
+// anywhere else.
+func (p *Proxy) Get(ctx context.Context, rawURL string, size int) (Image, time.Time, error) {
+	if size < 0 || size > MaxProxySize {
+		return Image{}, time.Time{}, fmt.Errorf("size must be at most %d", MaxProxySize)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Image{}, time.Time{}, ErrForbiddenURL
+	}
+	if err := p.check(u); err != nil {
+		return Image{}, time.Time{}, err
+	}
+	path := p.cachePath(u.String(), size)
+	if img, fetched, ok := p.cached(path); ok {
+		return img, fetched, nil
+	}
+
+	data, err := p.fetch(ctx, rewriteMzstatic(u, size))
+	if err != nil {
+		return Image{}, time.Time{}, err
+	}
+	img := Image{Data: data, ContentType: http.DetectContentType(data)}
+	if size > 0 {
+		img, err = Resize(data, size)
+	} else {
+		_, img.ContentType, err = decode(data)
+	}
+	if err != nil {
+		return Image{}, time.Time{}, fmt.Errorf("%w: %v", ErrUpstream, err)
+	}
+	fetched := p.now()
+	if err := p.store(path, img.Data, fetched); err != nil {
+		return Image{}, time.Time{}, err
+	}
+	return img, fetched, nil
+}
+
+// rewriteMzstatic returns the address with the size of an iTunes artwork address changed to the
+// size wanted. Other addresses are returned unchanged.
+func rewriteMzstatic(u *url.URL, size int) string {
+	host := strings.ToLower(u.Hostname())
+	if host != "mzstatic.com" && !strings.HasSuffix(host, ".mzstatic.com") {
+		return u.String()
+	}
+	if size == 0 {
+		size = MaxProxySize
+	}
+	rewritten := *u
+	rewritten.Path = mzstaticSize.ReplaceAllString(u.Path, fmt.Sprintf("/%dx%dbb.jpg", size, size))
+	rewritten.RawPath = ""
+	return rewritten.String()
+}
+
+// fetch downloads the artwork, refusing anything but a successful response of at most MaxBytes.
+func (p *Proxy) fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUpstream, err)
+	}
+	req.Header.Set("Accept", "image/jpeg, image/png, image/webp")
+	resp, err := p.Client.Do(req)
+	if errors.Is(err, ErrForbiddenURL) || errors.Is(err, errPrivateAddress) {
+		return nil, ErrForbiddenURL
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUpstream, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s", ErrUpstream, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, p.MaxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUpstream, err)
+	}
+	if int64(len(data)) > p.MaxBytes {
+		return nil, fmt.Errorf("%w: larger than %d bytes", ErrUpstream, p.MaxBytes)
+	}
+	return data, nil
+}
+
+// cachePath returns the file the artwork at the address is cached in at the size.
+func (p *Proxy) cachePath(rawURL string, size int) string {
+	sum := sha256.Sum256([]byte(strconv.Itoa(size) + " " + rawURL))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(p.CacheDir, name[:2], name)
+}
+
+// cached returns the artwork in the cache file and when it was fetched, if it is there and has not expired.
+func (p *Proxy) cached(path string) (Image, time.Time, bool) {
+	info, err := os.Stat(path)
+	if err != nil || p.now().Sub(info.ModTime()) > p.TTL {
+		return Image{}, time.Time{}, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Image{}, time.Time{}, false
+	}
+	return Image{Data: data, ContentType: http.DetectContentType(data)}, info.ModTime(), true
+}
+
+// store writes the cache file through a temporary file, so it is never read half written. The
+// file's modification time records when the artwork was fetched.
+func (p *Proxy) store(path string, data []byte, fetched time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".fetch-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chtimes(tmp.Name(), fetched, fetched); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Prune deletes cached artwork older than TTL, returning how many files it deleted.
+func (p *Proxy) Prune() (int, error) {
+	pruned := 0
+	err := filepath.WalkDir(p.CacheDir, func(path string, d os.DirEntry, err error) error {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if p.now().Sub(info.ModTime()) > p.TTL {
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			pruned++
+		}
+		return nil
+	})
+	return pruned, err
+}
@@ -2,8 +2,11 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Config holds runtime configuration loaded from environment variables.
@@ -25,6 +28,68 @@ type Config struct {
 	// Cassandra specific
 	CassandraHosts    string // comma-separated
 	CassandraKeyspace string
+
+	// Carts idle for longer than CartTTL are removed: by TTL in Cassandra, and by a cleanup
+	// job running every CartCleanupInterval in Postgres.
+	CartTTL             time.Duration
+	CartCleanupInterval time.Duration
+
+	// PriceScheduleInterval is how often scheduled price changes are checked for and applied.
+	PriceScheduleInterval time.Duration
+
+	// RecommendationInterval is how often album recommendations are recomputed for the catalogue.
+	RecommendationInterval time.Duration
+
+	// Deleted albums stay in the trash, where they can be restored, for TrashRetention. A purge job
+	// running every TrashPurgeInterval then deletes them for good.
+	TrashRetention     time.Duration
+	TrashPurgeInterval time.Duration
+
+	// PaymentProvider chooses the payment processor. Only "fake", a local gateway for
+	// development and tests, is built in. PaymentWebhookSecret signs its webhooks and must be set.
+	PaymentProvider      string
+	PaymentWebhookSecret string
+
+	// StaffToken is the bearer token staff send to reach the admin routes that move money: issuing
+	// and adjusting gift cards, and refunding payments and returns.
+	StaffToken string
+
+	// PricesIncludeTax says whether album prices already include tax (as UK and EU shoppers expect)
+	// or have it added at checkout. TaxHomeCountry is the destination assumed when none is given.
+	PricesIncludeTax bool
+	TaxHomeCountry   string
+
+	// Notifier chooses how customer alerts are delivered: "log" writes them to NotifyLogFile (or the
+	// standard log if unset) for local use, and "smtp" emails them through SMTPAddr from SMTPFrom.
+	Notifier      string
+	NotifyLogFile string
+	SMTPAddr      string
+	SMTPFrom      string
+	SMTPUsername  string
+	SMTPPassword  string
+
+	// ArtworkStorage chooses where uploaded album artwork is kept: "local" keeps it in ArtworkDir,
+	// served by this server at ArtworkBaseURL, and "s3" keeps it in S3Bucket of an S3-compatible
+	// service at S3Endpoint, served from S3PublicURL or the bucket itself. Uploads larger than
+	// ArtworkMaxBytes are refused.
+	ArtworkStorage    string
+	ArtworkDir        string
+	ArtworkBaseURL    string
+	ArtworkMaxBytes   int64
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3PublicURL       string
+
+	// ArtworkProxyHosts are the hosts GET /artwork fetches artwork from, each including its
+	// subdomains. Fetched artwork is cached in ArtworkCacheDir for ArtworkCacheTTL, and a job running
+	// every ArtworkCachePruneInterval deletes what has expired.
+	ArtworkProxyHosts         []string
+	ArtworkCacheDir           string
+	ArtworkCacheTTL           time.Duration
+	ArtworkCachePruneInterval time.Duration
 }
 
 // LoadFromEnv reads environment variables and returns a Config.
@@ -36,6 +101,31 @@ func LoadFromEnv() (*Config, error) {
 		PostgresURL:       strings.TrimSpace(os.Getenv("POSTGRES_URL")),
 		CassandraHosts:    strings.TrimSpace(os.Getenv("CASSANDRA_HOSTS")),
 		CassandraKeyspace: strings.TrimSpace(os.Getenv("CASSANDRA_KEYSPACE")),
+
+		PaymentProvider:      strings.ToLower(strings.TrimSpace(os.Getenv("PAYMENT_PROVIDER"))),
+		PaymentWebhookSecret: os.Getenv("PAYMENT_WEBHOOK_SECRET"),
+		StaffToken:           strings.TrimSpace(os.Getenv("STAFF_TOKEN")),
+
+		TaxHomeCountry: strings.ToUpper(strings.TrimSpace(os.Getenv("TAX_HOME_COUNTRY"))),
+
+		Notifier:      strings.ToLower(strings.TrimSpace(os.Getenv("NOTIFIER"))),
+		NotifyLogFile: strings.TrimSpace(os.Getenv("NOTIFY_LOG_FILE")),
+		SMTPAddr:      strings.TrimSpace(os.Getenv("SMTP_ADDR")),
+		SMTPFrom:      strings.TrimSpace(os.Getenv("SMTP_FROM")),
+		SMTPUsername:  os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:  os.Getenv("SMTP_PASSWORD"),
+
+		ArtworkStorage:    strings.ToLower(strings.TrimSpace(os.Getenv("ARTWORK_STORAGE"))),
+		ArtworkDir:        strings.TrimSpace(os.Getenv("ARTWORK_DIR")),
+		ArtworkBaseURL:    strings.TrimSpace(os.Getenv("ARTWORK_BASE_URL")),
+		S3Endpoint:        strings.TrimSpace(os.Getenv("S3_ENDPOINT")),
+		S3Region:          strings.TrimSpace(os.Getenv("S3_REGION")),
+		S3Bucket:          strings.TrimSpace(os.Getenv("S3_BUCKET")),
+		S3AccessKeyID:     strings.TrimSpace(os.Getenv("S3_ACCESS_KEY_ID")),
+		S3SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		S3PublicURL:       strings.TrimSpace(os.Getenv("S3_PUBLIC_URL")),
+
+		ArtworkCacheDir: strings.TrimSpace(os.Getenv("ARTWORK_CACHE_DIR")),
 	}
 
 	// sensible defaults
@@ -55,6 +145,105 @@ func LoadFromEnv() (*Config, error) {
 		}
 	}
 
+	if c.PaymentProvider == "" {
+		c.PaymentProvider = "fake"
+	}
+	if c.PaymentProvider != "fake" {
+		return nil, fmt.Errorf("unsupported PAYMENT_PROVIDER %q", c.PaymentProvider)
+	}
+	// Anyone with the secret can sign a webhook marking an order paid, so there is no default.
+	if c.PaymentWebhookSecret == "" {
+		return nil, errors.New("PAYMENT_WEBHOOK_SECRET must be set")
+	}
+	// Likewise anyone with the staff token can issue gift cards and refund orders.
+	if c.StaffToken == "" {
+		return nil, errors.New("STAFF_TOKEN must be set")
+	}
+
+	if c.Notifier == "" {
+		c.Notifier = "log"
+	}
+	switch c.Notifier {
+	case "log":
+	case "smtp":
+		if c.SMTPAddr == "" || c.SMTPFrom == "" {
+			return nil, errors.New("SMTP_ADDR and SMTP_FROM must be set when NOTIFIER=smtp")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported NOTIFIER %q", c.Notifier)
+	}
+
+	if c.ArtworkStorage == "" {
+		c.ArtworkStorage = "local"
+	}
+	switch c.ArtworkStorage {
+	case "local":
+		if c.ArtworkDir == "" {
+			c.ArtworkDir = "uploads/artwork"
+		}
+		if c.ArtworkBaseURL == "" {
+			c.ArtworkBaseURL = "/uploads/artwork"
+		}
+	case "s3":
+		if c.S3Endpoint == "" || c.S3Bucket == "" || c.S3AccessKeyID == "" || c.S3SecretAccessKey == "" {
+			return nil, errors.New("S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY must be set when ARTWORK_STORAGE=s3")
+		}
+		if c.S3Region == "" {
+			c.S3Region = "us-east-1"
+		}
+	default:
+		return nil, fmt.Errorf("unsupported ARTWORK_STORAGE %q", c.ArtworkStorage)
+	}
+
+	for _, host := range strings.Split(os.Getenv("ARTWORK_PROXY_HOSTS"), ",") {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			c.ArtworkProxyHosts = append(c.ArtworkProxyHosts, host)
+		}
+	}
+	if len(c.ArtworkProxyHosts) == 0 {
+		c.ArtworkProxyHosts = []string{"mzstatic.com"}
+	}
+	if c.ArtworkCacheDir == "" {
+		c.ArtworkCacheDir = "uploads/artwork-cache"
+	}
+
+	if c.TaxHomeCountry == "" {
+		c.TaxHomeCountry = "GB"
+	}
+
+	var err error
+	if c.PricesIncludeTax, err = boolFromEnv("PRICES_INCLUDE_TAX", true); err != nil {
+		return nil, err
+	}
+	if c.CartTTL, err = durationFromEnv("CART_TTL", 30*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if c.CartCleanupInterval, err = durationFromEnv("CART_CLEANUP_INTERVAL", time.Hour); err != nil {
+		return nil, err
+	}
+	if c.PriceScheduleInterval, err = durationFromEnv("PRICE_SCHEDULE_INTERVAL", time.Minute); err != nil {
+		return nil, err
+	}
+	if c.RecommendationInterval, err = durationFromEnv("RECOMMENDATION_INTERVAL", time.Hour); err != nil {
+		return nil, err
+	}
+	if c.TrashRetention, err = durationFromEnv("TRASH_RETENTION", 30*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if c.TrashPurgeInterval, err = durationFromEnv("TRASH_PURGE_INTERVAL", time.Hour); err != nil {
+		return nil, err
+	}
+
+	if c.ArtworkMaxBytes, err = bytesFromEnv("ARTWORK_MAX_BYTES", 10<<20); err != nil {
+		return nil, err
+	}
+	if c.ArtworkCacheTTL, err = durationFromEnv("ARTWORK_CACHE_TTL", 7*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if c.ArtworkCachePruneInterval, err = durationFromEnv("ARTWORK_CACHE_PRUNE_INTERVAL", time.Hour); err != nil {
+		return nil, err
+	}
+
 	// Normalise cassandra hosts (ensure comma separated if space separated)
 	if c.CassandraHosts != "" {
 		c.CassandraHosts = strings.ReplaceAll(c.CassandraHosts, " ", ",")
@@ -62,3 +251,43 @@ func LoadFromEnv() (*Config, error) {
 
 	return c, nil
 }
+
+// durationFromEnv parses a duration such as "720h" from the named variable, returning def if it is unset.
+func durationFromEnv(name string, def time.Duration) (time.Duration, error) {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%s must be a positive duration such as 720h, got %q", name, value)
+	}
+	return d, nil
+}
+
+// bytesFromEnv parses a positive number of bytes such as "10485760" from the named variable, returning
+// def if it is unset.
+func bytesFromEnv(name string, def int64) (int64, error) {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%s must be a positive number of bytes, got %q", name, value)
+	}
+	return n, nil
+}
+
+// boolFromEnv parses a boolean such as "true" or "0" from the named variable, returning def if it is unset.
+func boolFromEnv(name string, def bool) (bool, error) {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false, got %q", name, value)
+	}
+	return b, nil
+}
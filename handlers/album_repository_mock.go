@@ -2,7 +2,10 @@ package handlers
 
 import (
 	"os"
+	"sort"
+	"time"
 
+	"github.com/tvergilio/motown-house-backend/money"
 	"github.com/tvergilio/motown-house-backend/repository"
 )
 
@@ -10,6 +13,8 @@ import (
 
 type mockAlbumRepo struct {
 	albums []repository.Album
+	// trash holds the deleted albums, out of the way of every other lookup.
+	trash []repository.Album
 }
 
 func (m *mockAlbumRepo) GetAll() ([]repository.Album, error) {
@@ -25,19 +30,93 @@ func (m *mockAlbumRepo) GetByID(id string) (repository.Album, error) {
 	return repository.Album{}, os.ErrNotExist
 }
 
+func (m *mockAlbumRepo) GetByBarcode(barcode string) (repository.Album, error) {
+	for _, a := range m.albums {
+		if a.Barcode == barcode {
+			return a, nil
+		}
+	}
+	return repository.Album{}, repository.ErrAlbumNotFound
+}
+
+func (m *mockAlbumRepo) GetByCatalogueNumber(labelID, catalogueNumber string) (repository.Album, error) {
+	for _, a := range m.albums {
+		if a.LabelID == labelID && a.CatalogueNumber == catalogueNumber {
+			return a, nil
+		}
+	}
+	return repository.Album{}, repository.ErrAlbumNotFound
+}
+
+func (m *mockAlbumRepo) GetByTag(tag string) ([]repository.Album, error) {
+	var albums []repository.Album
+	for _, a := range m.albums {
+		for _, t := range a.Tags {
+			if t == tag {
+				albums = append(albums, a)
+				break
+			}
+		}
+	}
+	return albums, nil
+}
+
+func (m *mockAlbumRepo) TagCounts() ([]repository.TagCount, error) {
+	byTag := map[string]int{}
+	for _, a := range m.albums {
+		for _, t := range a.Tags {
+			byTag[t]++
+		}
+	}
+	counts := []repository.TagCount{}
+	for tag, count := range byTag {
+		counts = append(counts, repository.TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Tag < counts[j].Tag
+	})
+	return counts, nil
+}
+
+// identifiersTaken returns the error for an album other than the given one already having the
+// album's barcode or catalogue number, as the real repositories do.
+func (m *mockAlbumRepo) identifiersTaken(album repository.Album) error {
+	for _, a := range m.albums {
+		if a.ID == album.ID {
+			continue
+		}
+		if album.Barcode != "" && a.Barcode == album.Barcode {
+			return repository.ErrDuplicateBarcode
+		}
+		if album.CatalogueNumber != "" && a.LabelID == album.LabelID && a.CatalogueNumber == album.CatalogueNumber {
+			return repository.ErrDuplicateCatalogueNumber
+		}
+	}
+	return nil
+}
+
 func (m *mockAlbumRepo) Create(album repository.Album) error {
+	if err := m.identifiersTaken(album); err != nil {
+		return err
+	}
 	m.albums = append(m.albums, album)
 	return nil
 }
 
 func (m *mockAlbumRepo) Update(album repository.Album) error {
+	if err := m.identifiersTaken(album); err != nil {
+		return err
+	}
 	for i, a := range m.albums {
 		if a.ID == album.ID {
 			m.albums[i] = album
 			return nil // Success
 		}
 	}
-	return os.ErrNotExist // Return error if album not found
+	return repository.ErrAlbumNotFound // Albums missing or in the trash cannot be updated
 }
 
 func (m *mockAlbumRepo) Delete(id string) error {
@@ -47,9 +126,44 @@ func (m *mockAlbumRepo) Delete(id string) error {
 			return nil
 		}
 	}
+	for i, a := range m.trash {
+		if a.ID == id {
+			m.trash = append(m.trash[:i], m.trash[i+1:]...)
+			return nil
+		}
+	}
 	return os.ErrNotExist
 }
 
+func (m *mockAlbumRepo) Trash(id, actor string) error {
+	for i, a := range m.albums {
+		if a.ID == id {
+			now := time.Now()
+			a.DeletedAt, a.DeletedBy = &now, actor
+			m.albums = append(m.albums[:i], m.albums[i+1:]...)
+			m.trash = append([]repository.Album{a}, m.trash...)
+			return nil
+		}
+	}
+	return repository.ErrAlbumNotFound
+}
+
+func (m *mockAlbumRepo) GetTrash() ([]repository.Album, error) {
+	return append([]repository.Album(nil), m.trash...), nil
+}
+
+func (m *mockAlbumRepo) Restore(id string) error {
+	for i, a := range m.trash {
+		if a.ID == id {
+			a.DeletedAt, a.DeletedBy = nil, ""
+			m.trash = append(m.trash[:i], m.trash[i+1:]...)
+			m.albums = append(m.albums, a)
+			return nil
+		}
+	}
+	return repository.ErrAlbumNotFound
+}
+
 // Mock iTunes repository for testing
 type mockITunesRepo struct{}
 
@@ -59,7 +173,7 @@ func (m *mockITunesRepo) Search(term string) ([]repository.AlbumResponse, error)
 		{
 			Title:    "Thriller",
 			Artist:   "Michael Jackson",
-			Price:    9.99,
+			Price:    money.MustParse("9.99"),
 			Year:     1982,
 			Genre:    "Pop",
 			ImageURL: "https://example.com/thriller.jpg",
@@ -67,7 +181,7 @@ func (m *mockITunesRepo) Search(term string) ([]repository.AlbumResponse, error)
 		{
 			Title:    "Bad",
 			Artist:   "Michael Jackson",
-			Price:    8.99,
+			Price:    money.MustParse("8.99"),
 			Year:     1987,
 			Genre:    "Pop",
 			ImageURL: "https://example.com/bad.jpg",
@@ -79,9 +193,9 @@ func (m *mockITunesRepo) Search(term string) ([]repository.AlbumResponse, error)
 func newTestHandler() *AlbumHandler {
 	mockRepo := &mockAlbumRepo{
 		albums: []repository.Album{
-			{ID: "1", Title: "Thriller", Artist: "Michael Jackson", Price: 25.99, Year: 1982, ImageUrl: "https://example.com/thriller.jpg", Genre: "Pop"},
-			{ID: "2", Title: "Songs in the Key of Life", Artist: "Stevie Wonder", Price: 42.50, Year: 1976, ImageUrl: "https://example.com/songs.jpg", Genre: "Motown"},
-			{ID: "101", Title: "Thriller", Artist: "Michael Jackson", Price: 42.99, Year: 1982, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music115/v4/32/4f/fd/324ffda2-9e51-8f6a-0c2d-c6fd2b41ac55/074643811224.jpg/100x100bb.jpg", Genre: "Pop"},
+			{ID: "1", Title: "Thriller", Artist: "Michael Jackson", Price: money.MustParse("25.99"), Currency: "GBP", Year: 1982, ImageUrl: "https://example.com/thriller.jpg", Genre: "Pop"},
+			{ID: "2", Title: "Songs in the Key of Life", Artist: "Stevie Wonder", Price: money.MustParse("42.50"), Currency: "GBP", Year: 1976, ImageUrl: "https://example.com/songs.jpg", Genre: "Motown"},
+			{ID: "101", Title: "Thriller", Artist: "Michael Jackson", Price: money.MustParse("42.99"), Currency: "GBP", Year: 1982, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music115/v4/32/4f/fd/324ffda2-9e51-8f6a-0c2d-c6fd2b41ac55/074643811224.jpg/100x100bb.jpg", Genre: "Pop"},
 		},
 	}
 	mockITunesRepo := &mockITunesRepo{}
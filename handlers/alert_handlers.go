@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+type AlertHandler struct {
+	Repo   repository.AlertRepository
+	Albums repository.AlbumRepository
+}
+
+func NewAlertHandler(repo repository.AlertRepository, albums repository.AlbumRepository) *AlertHandler {
+	return &AlertHandler{Repo: repo, Albums: albums}
+}
+
+// AlertRequest is the body accepted by POST /albums/:id/alerts. TargetPrice optionally holds a
+// price-drop alert back until the price reaches it; Format optionally limits a back-in-stock alert
+// to one format.
+type AlertRequest struct {
+	Kind        string        `json:"kind" binding:"required"`
+	Email       string        `json:"email" binding:"required"`
+	TargetPrice *money.Amount `json:"targetPrice"`
+	Format      string        `json:"format"`
+}
+
+// PostAlert handles POST /albums/:id/alerts, subscribing the signed-in user to an alert on the album.
+// Subscribing again to the same alert while it is waiting returns the existing one.
+func (h *AlertHandler) PostAlert(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	albumID, ok := getAlbumIDFromUri(c)
+	if !ok {
+		return
+	}
+	var req AlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	kind, err := repository.ParseAlertKind(req.Kind)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	alert := repository.Alert{UserID: userID, Email: strings.TrimSpace(req.Email), AlbumID: albumID, Kind: kind, TargetPrice: req.TargetPrice}
+	if req.Format != "" {
+		if alert.Format, err = repository.ParseFormat(req.Format); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if err := alert.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := h.Albums.GetByID(albumID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "album not found"})
+		return
+	}
+
+	existing, err := h.Repo.ListByUser(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for _, other := range existing {
+		if other.Active() && other.Matches(alert) {
+			c.IndentedJSON(http.StatusOK, other)
+			return
+		}
+	}
+	created, err := h.Repo.Create(alert)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusCreated, created)
+}
+
+// GetMyAlerts handles GET /alerts, listing the signed-in user's alerts, including those that have fired.
+func (h *AlertHandler) GetMyAlerts(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	alerts, err := h.Repo.ListByUser(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if alerts == nil {
+		alerts = []repository.Alert{}
+	}
+	c.IndentedJSON(http.StatusOK, alerts)
+}
+
+// DeleteMyAlert handles DELETE /alerts/:id, unsubscribing from an alert.
+func (h *AlertHandler) DeleteMyAlert(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	alert, err := h.Repo.GetByID(c.Param("id"))
+	if err == nil && alert.UserID != userID {
+		err = repository.ErrAlertNotFound
+	}
+	if err == nil {
+		err = h.Repo.Delete(alert.ID)
+	}
+	if errors.Is(err, repository.ErrAlertNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "alert not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
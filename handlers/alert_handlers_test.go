@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+func setupAlertRouter(alerts *mockAlertRepo) *gin.Engine {
+	handler := NewAlertHandler(alerts, newTestHandler().Repo)
+	r := gin.Default()
+	r.POST("/albums/:id/alerts", handler.PostAlert)
+	r.GET("/alerts", handler.GetMyAlerts)
+	r.DELETE("/alerts/:id", handler.DeleteMyAlert)
+	return r
+}
+
+func Test_PostAlert_Success(t *testing.T) {
+	alerts := newMockAlertRepo()
+	r := setupAlertRouter(alerts)
+
+	w := doRequest(r, "POST", "/albums/1/alerts", "user-1", `{"kind":"price-drop","email":"ann@example.com","targetPrice":20}`)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var alert repository.Alert
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &alert))
+	assert.Equal(t, repository.AlertPriceDrop, alert.Kind)
+	assert.Equal(t, "1", alert.AlbumID)
+	require.NotNil(t, alert.TargetPrice)
+	assert.Equal(t, money.MustParse("20.00"), *alert.TargetPrice)
+}
+
+func Test_PostAlert_SubscribingTwiceKeepsOneAlert(t *testing.T) {
+	alerts := newMockAlertRepo()
+	r := setupAlertRouter(alerts)
+	body := `{"kind":"back-in-stock","email":"ann@example.com","format":"cd"}`
+
+	doRequest(r, "POST", "/albums/1/alerts", "user-1", body)
+	w := doRequest(r, "POST", "/albums/1/alerts", "user-1", body)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, alerts.alerts, 1)
+
+	require.NoError(t, alerts.MarkTriggered(alerts.alerts[0].ID, time.Now()))
+	w = doRequest(r, "POST", "/albums/1/alerts", "user-1", body)
+	assert.Equal(t, http.StatusCreated, w.Code, "an alert that has fired can be subscribed to again")
+}
+
+func Test_PostAlert_Invalid(t *testing.T) {
+	r := setupAlertRouter(newMockAlertRepo())
+
+	for name, body := range map[string]string{
+		"unknown kind":          `{"kind":"sold-out","email":"ann@example.com"}`,
+		"bad email":             `{"kind":"price-drop","email":"not an email"}`,
+		"target on stock alert": `{"kind":"back-in-stock","email":"ann@example.com","targetPrice":10}`,
+		"format on price alert": `{"kind":"price-drop","email":"ann@example.com","format":"LP"}`,
+		"unknown format":        `{"kind":"back-in-stock","email":"ann@example.com","format":"8-track"}`,
+		"missing email":         `{"kind":"price-drop"}`,
+		"negative target":       `{"kind":"price-drop","email":"ann@example.com","targetPrice":-1}`,
+	} {
+		w := doRequest(r, "POST", "/albums/1/alerts", "user-1", body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, name)
+	}
+}
+
+func Test_PostAlert_UnknownAlbumAndAnonymous(t *testing.T) {
+	r := setupAlertRouter(newMockAlertRepo())
+	body := `{"kind":"price-drop","email":"ann@example.com"}`
+
+	assert.Equal(t, http.StatusNotFound, doRequest(r, "POST", "/albums/999/alerts", "user-1", body).Code)
+	assert.Equal(t, http.StatusUnauthorized, doRequest(r, "POST", "/albums/1/alerts", "", body).Code)
+}
+
+func Test_DeleteMyAlert(t *testing.T) {
+	alerts := newMockAlertRepo()
+	r := setupAlertRouter(alerts)
+	doRequest(r, "POST", "/albums/1/alerts", "user-1", `{"kind":"price-drop","email":"ann@example.com"}`)
+	id := alerts.alerts[0].ID
+
+	assert.Equal(t, http.StatusNotFound, doRequest(r, "DELETE", "/alerts/"+id, "user-2", "").Code)
+	assert.Equal(t, http.StatusNoContent, doRequest(r, "DELETE", "/alerts/"+id, "user-1", "").Code)
+
+	var mine []repository.Alert
+	w := doRequest(r, "GET", "/alerts", "user-1", "")
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &mine))
+	assert.Empty(t, mine)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// In-memory mock implementation of AlertRepository for testing
+
+type mockAlertRepo struct {
+	alerts []repository.Alert
+	nextID int
+}
+
+func newMockAlertRepo() *mockAlertRepo {
+	return &mockAlertRepo{}
+}
+
+func (m *mockAlertRepo) Create(alert repository.Alert) (repository.Alert, error) {
+	m.nextID++
+	alert.ID = fmt.Sprintf("alert-%d", m.nextID)
+	alert.CreatedAt = time.Now()
+	m.alerts = append(m.alerts, alert)
+	return alert, nil
+}
+
+func (m *mockAlertRepo) GetByID(id string) (repository.Alert, error) {
+	for _, alert := range m.alerts {
+		if alert.ID == id {
+			return alert, nil
+		}
+	}
+	return repository.Alert{}, repository.ErrAlertNotFound
+}
+
+func (m *mockAlertRepo) ListByUser(userID string) ([]repository.Alert, error) {
+	var alerts []repository.Alert
+	for _, alert := range m.alerts {
+		if alert.UserID == userID {
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts, nil
+}
+
+func (m *mockAlertRepo) ListActiveByAlbum(albumID string) ([]repository.Alert, error) {
+	var alerts []repository.Alert
+	for _, alert := range m.alerts {
+		if alert.AlbumID == albumID && alert.Active() {
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts, nil
+}
+
+func (m *mockAlertRepo) MarkTriggered(id string, at time.Time) error {
+	for i := range m.alerts {
+		if m.alerts[i].ID == id {
+			m.alerts[i].TriggeredAt = &at
+			return nil
+		}
+	}
+	return repository.ErrAlertNotFound
+}
+
+func (m *mockAlertRepo) Delete(id string) error {
+	for i, alert := range m.alerts {
+		if alert.ID == id {
+			m.alerts = append(m.alerts[:i], m.alerts[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrAlertNotFound
+}
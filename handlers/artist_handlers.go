@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+type ArtistHandler struct {
+	Repo   repository.ArtistRepository
+	Albums repository.AlbumRepository
+}
+
+func NewArtistHandler(repo repository.ArtistRepository, albums repository.AlbumRepository) *ArtistHandler {
+	return &ArtistHandler{Repo: repo, Albums: albums}
+}
+
+// ArtistRequest is the body accepted by POST /artists and PUT /artists/:id. SortName defaults to the
+// name, with a leading "The" moved to the end.
+type ArtistRequest struct {
+	Name           string   `json:"name" binding:"required"`
+	SortName       string   `json:"sortName"`
+	Aliases        []string `json:"aliases"`
+	Bio            string   `json:"bio"`
+	ImageURL       string   `json:"imageUrl"`
+	ITunesArtistID int64    `json:"itunesArtistId"`
+}
+
+func (r ArtistRequest) artist(id string) repository.Artist {
+	return repository.Artist{
+		ID:             id,
+		Name:           r.Name,
+		SortName:       r.SortName,
+		Aliases:        r.Aliases,
+		Bio:            r.Bio,
+		ImageURL:       r.ImageURL,
+		ITunesArtistID: r.ITunesArtistID,
+	}
+}
+
+func respondWithArtistError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrArtistNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "artist not found"})
+	case errors.Is(err, repository.ErrDuplicateArtist), errors.Is(err, repository.ErrArtistHasAlbums):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// bindArtist reads and validates an artist from the request body. It writes an error response and
+// returns false if the body is not a valid artist.
+func bindArtist(c *gin.Context, id string) (repository.Artist, bool) {
+	var req ArtistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return repository.Artist{}, false
+	}
+	artist := req.artist(id)
+	if err := artist.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return repository.Artist{}, false
+	}
+	return artist, true
+}
+
+// GetArtists handles GET /artists, listing every artist by sort name.
+func (h *ArtistHandler) GetArtists(c *gin.Context) {
+	artists, err := h.Repo.List()
+	if err != nil {
+		respondWithArtistError(c, err)
+		return
+	}
+	if artists == nil {
+		artists = []repository.Artist{}
+	}
+	c.IndentedJSON(http.StatusOK, artists)
+}
+
+// GetArtist handles GET /artists/:id.
+func (h *ArtistHandler) GetArtist(c *gin.Context) {
+	artist, err := h.Repo.GetByID(c.Param("id"))
+	if err != nil {
+		respondWithArtistError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, artist)
+}
+
+// PostArtist handles POST /artists. Names and aliases must not already be used by another artist.
+func (h *ArtistHandler) PostArtist(c *gin.Context) {
+	artist, ok := bindArtist(c, "")
+	if !ok {
+		return
+	}
+	created, err := h.Repo.Create(artist)
+	if err != nil {
+		respondWithArtistError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusCreated, created)
+}
+
+// PutArtist handles PUT /artists/:id, replacing the artist's details. Renaming an artist renames
+// its albums too.
+func (h *ArtistHandler) PutArtist(c *gin.Context) {
+	artist, ok := bindArtist(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	updated, err := h.Repo.Update(artist)
+	if err != nil {
+		respondWithArtistError(c, err)
+		return
+	}
+	if err := h.renameAlbums(updated); err != nil {
+		// The artist has already been updated; albums showing the old name can be fixed by saving the artist again.
+		log.Printf("PutArtist: failed to rename albums of artist %s: %v", updated.ID, err)
+	}
+	c.IndentedJSON(http.StatusOK, updated)
+}
+
+// renameAlbums gives the artist's albums the artist's current name.
+func (h *ArtistHandler) renameAlbums(artist repository.Artist) error {
+	albums, err := h.Albums.GetAll()
+	if err != nil {
+		return err
+	}
+	for _, album := range albums {
+		if album.ArtistID != artist.ID || album.Artist == artist.Name {
+			continue
+		}
+		album.Artist = artist.Name
+		if err := h.Albums.Update(album); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteArtist handles DELETE /artists/:id. Artists that still have albums cannot be deleted.
+func (h *ArtistHandler) DeleteArtist(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := h.Repo.GetByID(id); err != nil {
+		respondWithArtistError(c, err)
+		return
+	}
+	albums, err := h.Albums.GetAll()
+	if err != nil {
+		respondWithArtistError(c, err)
+		return
+	}
+	for _, album := range albums {
+		if album.ArtistID == id {
+			respondWithArtistError(c, repository.ErrArtistHasAlbums)
+			return
+		}
+	}
+	if err := h.Repo.Delete(id); err != nil {
+		respondWithArtistError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// setupArtistRouter serves the album and artist routes, with the seeded albums already linked to their artists.
+func setupArtistRouter(t *testing.T) (*gin.Engine, *AlbumHandler, *mockArtistRepo) {
+	t.Helper()
+	artists := newMockArtistRepo()
+	albums := newTestHandler()
+	albums.Artists = artists
+	_, err := repository.LinkAlbumArtists(albums.Repo, artists)
+	require.NoError(t, err)
+	handler := NewArtistHandler(artists, albums.Repo)
+	r := gin.Default()
+	r.POST("/albums", albums.PostAlbums)
+	r.PUT("/albums/:id", albums.PutAlbum)
+	r.GET("/artists", handler.GetArtists)
+	r.POST("/artists", handler.PostArtist)
+	r.GET("/artists/:id", handler.GetArtist)
+	r.PUT("/artists/:id", handler.PutArtist)
+	r.DELETE("/artists/:id", handler.DeleteArtist)
+	r.GET("/artists/:id/albums", albums.GetArtistAlbums)
+	return r, albums, artists
+}
+
+func Test_LinkAlbumArtists_OneArtistPerName(t *testing.T) {
+	_, albums, artists := setupArtistRouter(t)
+
+	list, _ := artists.List()
+	require.Len(t, list, 2)
+	all, _ := albums.Repo.GetAll()
+	assert.Equal(t, all[0].ArtistID, all[2].ArtistID, "both Thrillers are by the same Michael Jackson")
+	assert.NotEqual(t, all[0].ArtistID, all[1].ArtistID)
+}
+
+func Test_PostAlbums_LinksArtistByNameOrAlias(t *testing.T) {
+	r, albums, artists := setupArtistRouter(t)
+	stevie, err := artists.GetByName("Stevie Wonder")
+	require.NoError(t, err)
+	stevie.Aliases = repository.Aliases{"Little Stevie Wonder"}
+	_, err = artists.Update(stevie)
+	require.NoError(t, err)
+
+	for _, name := range []string{"stevie   wonder", "Little Stevie Wonder"} {
+		body := `{"title":"Innervisions","artist":"` + name + `","price":9.99,"year":1973,"imageUrl":"x","genre":"Soul"}`
+		w := doRequest(r, "POST", "/albums", "staff-1", body)
+
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var album repository.Album
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &album))
+		assert.Equal(t, stevie.ID, album.ArtistID, name)
+		assert.Equal(t, "Stevie Wonder", album.Artist, name)
+	}
+
+	w := doRequest(r, "POST", "/albums", "staff-1", `{"title":"What's Going On","artist":"Marvin  Gaye","price":9.99,"year":1971,"imageUrl":"x","genre":"Soul"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	marvin, err := artists.GetByName("Marvin Gaye")
+	require.NoError(t, err, "new artists are created as albums arrive")
+	assert.Equal(t, "Marvin Gaye", marvin.Name)
+	all, _ := albums.Repo.GetAll()
+	assert.Equal(t, marvin.ID, all[len(all)-1].ArtistID)
+}
+
+func Test_PutAlbum_ArtistIDWinsOverName(t *testing.T) {
+	r, albums, artists := setupArtistRouter(t)
+	stevie, _ := artists.GetByName("Stevie Wonder")
+
+	w := doRequest(r, "PUT", "/albums/1", "staff-1",
+		`{"title":"Thriller","artist":"Michael Jackson","artistId":"`+stevie.ID+`","price":25.99,"year":1982,"imageUrl":"x","genre":"Pop"}`)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	album, _ := albums.Repo.GetByID("1")
+	assert.Equal(t, stevie.ID, album.ArtistID)
+	assert.Equal(t, "Stevie Wonder", album.Artist)
+
+	w = doRequest(r, "PUT", "/albums/1", "staff-1",
+		`{"title":"Thriller","artistId":"artist-404","price":25.99,"year":1982,"imageUrl":"x","genre":"Pop"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_PostArtist(t *testing.T) {
+	r, _, _ := setupArtistRouter(t)
+
+	w := doRequest(r, "POST", "/artists", "staff-1",
+		`{"name":" The  Temptations ","aliases":["Temptations","The Elgins"],"bio":"Motown vocal group.","itunesArtistId":6450}`)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	artist := decodeJSON[repository.Artist](t, w.Body.Bytes())
+	assert.Equal(t, "The Temptations", artist.Name)
+	assert.Equal(t, "Temptations, The", artist.SortName)
+	assert.Equal(t, repository.Aliases{"The Elgins"}, artist.Aliases, "an alias matching the name is dropped")
+	assert.Equal(t, int64(6450), artist.ITunesArtistID)
+
+	w = doRequest(r, "POST", "/artists", "staff-1", `{"name":"Elgins"}`)
+	assert.Equal(t, http.StatusConflict, w.Code, "names already used as an alias are taken")
+
+	w = doRequest(r, "POST", "/artists", "staff-1", `{"name":"  "}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_GetArtists_SortedBySortName(t *testing.T) {
+	r, _, _ := setupArtistRouter(t)
+	doRequest(r, "POST", "/artists", "staff-1", `{"name":"Diana Ross","sortName":"Ross, Diana"}`)
+
+	w := doRequest(r, "GET", "/artists", "", "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var artists []repository.Artist
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &artists))
+	require.Len(t, artists, 3)
+	assert.Equal(t, "Michael Jackson", artists[0].Name)
+	assert.Equal(t, "Diana Ross", artists[1].Name)
+	assert.Equal(t, "Stevie Wonder", artists[2].Name)
+}
+
+func Test_PutArtist_RenamesAlbums(t *testing.T) {
+	r, albums, artists := setupArtistRouter(t)
+	michael, _ := artists.GetByName("Michael Jackson")
+
+	w := doRequest(r, "PUT", "/artists/"+michael.ID, "staff-1", `{"name":"Michael Joseph Jackson","aliases":["Michael Jackson"]}`)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	for _, id := range []string{"1", "101"} {
+		album, _ := albums.Repo.GetByID(id)
+		assert.Equal(t, "Michael Joseph Jackson", album.Artist)
+	}
+	stevie, _ := albums.Repo.GetByID("2")
+	assert.Equal(t, "Stevie Wonder", stevie.Artist)
+
+	w = doRequest(r, "PUT", "/artists/missing", "staff-1", `{"name":"Nobody"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_GetArtistAlbums(t *testing.T) {
+	r, _, artists := setupArtistRouter(t)
+	michael, _ := artists.GetByName("Michael Jackson")
+	doRequest(r, "POST", "/albums", "staff-1", `{"title":"Off the Wall","artist":"Michael Jackson","price":9.99,"year":1979,"imageUrl":"x","genre":"Pop"}`)
+
+	w := doRequest(r, "GET", "/artists/"+michael.ID+"/albums", "", "")
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var listed []repository.Album
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed, 3)
+	assert.Equal(t, "Off the Wall", listed[0].Title, "oldest first")
+	for _, album := range listed {
+		assert.Equal(t, michael.ID, album.ArtistID)
+	}
+
+	lonely, _ := artists.Create(repository.Artist{Name: "Tammi Terrell"})
+	w = doRequest(r, "GET", "/artists/"+lonely.ID+"/albums", "", "")
+	assert.JSONEq(t, `[]`, w.Body.String())
+
+	w = doRequest(r, "GET", "/artists/missing/albums", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_DeleteArtist(t *testing.T) {
+	r, _, artists := setupArtistRouter(t)
+	stevie, _ := artists.GetByName("Stevie Wonder")
+
+	w := doRequest(r, "DELETE", "/artists/"+stevie.ID, "staff-1", "")
+	assert.Equal(t, http.StatusConflict, w.Code, "artists with albums are kept")
+
+	lonely, _ := artists.Create(repository.Artist{Name: "Tammi Terrell"})
+	w = doRequest(r, "DELETE", "/artists/"+lonely.ID, "staff-1", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = doRequest(r, "GET", "/artists/"+lonely.ID, "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// In-memory mock implementation of ArtistRepository for testing
+
+type mockArtistRepo struct {
+	artists []repository.Artist
+	nextID  int
+}
+
+func newMockArtistRepo() *mockArtistRepo {
+	return &mockArtistRepo{}
+}
+
+// nameTaken reports whether an artist other than id goes by any of the artist's names.
+func (m *mockArtistRepo) nameTaken(artist repository.Artist, id string) bool {
+	names := append([]string{artist.Name}, artist.Aliases...)
+	for _, name := range names {
+		if existing, err := m.GetByName(name); err == nil && existing.ID != id {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *mockArtistRepo) Create(artist repository.Artist) (repository.Artist, error) {
+	artist.Normalize()
+	if m.nameTaken(artist, "") {
+		return repository.Artist{}, repository.ErrDuplicateArtist
+	}
+	m.nextID++
+	artist.ID = fmt.Sprintf("artist-%d", m.nextID)
+	artist.CreatedAt = time.Now()
+	artist.UpdatedAt = artist.CreatedAt
+	m.artists = append(m.artists, artist)
+	return artist, nil
+}
+
+func (m *mockArtistRepo) GetByID(id string) (repository.Artist, error) {
+	for _, artist := range m.artists {
+		if artist.ID == id {
+			return artist, nil
+		}
+	}
+	return repository.Artist{}, repository.ErrArtistNotFound
+}
+
+func (m *mockArtistRepo) GetByName(name string) (repository.Artist, error) {
+	key := repository.ArtistNameKey(name)
+	for _, artist := range m.artists {
+		if repository.ArtistNameKey(artist.Name) == key {
+			return artist, nil
+		}
+		for _, alias := range artist.Aliases {
+			if repository.ArtistNameKey(alias) == key {
+				return artist, nil
+			}
+		}
+	}
+	return repository.Artist{}, repository.ErrArtistNotFound
+}
+
+func (m *mockArtistRepo) List() ([]repository.Artist, error) {
+	artists := append([]repository.Artist(nil), m.artists...)
+	sort.SliceStable(artists, func(i, j int) bool {
+		return strings.ToLower(artists[i].SortName) < strings.ToLower(artists[j].SortName)
+	})
+	return artists, nil
+}
+
+func (m *mockArtistRepo) Update(artist repository.Artist) (repository.Artist, error) {
+	artist.Normalize()
+	for i, existing := range m.artists {
+		if existing.ID != artist.ID {
+			continue
+		}
+		if m.nameTaken(artist, artist.ID) {
+			return repository.Artist{}, repository.ErrDuplicateArtist
+		}
+		artist.CreatedAt = existing.CreatedAt
+		artist.UpdatedAt = time.Now()
+		m.artists[i] = artist
+		return artist, nil
+	}
+	return repository.Artist{}, repository.ErrArtistNotFound
+}
+
+func (m *mockArtistRepo) Delete(id string) error {
+	for i, artist := range m.artists {
+		if artist.ID == id {
+			m.artists = append(m.artists[:i], m.artists[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrArtistNotFound
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tvergilio/motown-house-backend/artwork"
+	"github.com/tvergilio/motown-house-backend/repository"
+	"github.com/tvergilio/motown-house-backend/storage"
+)
+
+// DefaultMaxArtworkBytes is the largest artwork upload accepted when no other limit is set.
+const DefaultMaxArtworkBytes = 10 << 20
+
+type ArtworkHandler struct {
+	Repo   repository.ArtworkRepository
+	Albums repository.AlbumRepository
+	Store  storage.Store
+
+	// MaxBytes is the largest image accepted.
+	MaxBytes int64
+}
+
+func NewArtworkHandler(repo repository.ArtworkRepository, albums repository.AlbumRepository, store storage.Store) *ArtworkHandler {
+	return &ArtworkHandler{Repo: repo, Albums: albums, Store: store, MaxBytes: DefaultMaxArtworkBytes}
+}
+
+// GetArtwork handles GET /albums/:id/artwork, returning the album's uploaded artwork and its thumbnails.
+func (h *ArtworkHandler) GetArtwork(c *gin.Context) {
+	id, ok := getExistingAlbumID(c, h.Albums)
+	if !ok {
+		return
+	}
+	uploaded, err := h.Repo.Get(id)
+	if errors.Is(err, repository.ErrArtworkNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "artwork not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, uploaded)
+}
+
+// PostArtwork handles POST /albums/:id/artwork, a multipart upload of a sleeve image in the "artwork"
+// field. The image is checked by its content, stored with its thumbnails, and becomes the album's
+// image; any artwork uploaded before is replaced and its files deleted.
+func (h *ArtworkHandler) PostArtwork(c *gin.Context) {
+	id, ok := getAlbumIDFromUri(c)
+	if !ok {
+		return
+	}
+	album, err := h.Albums.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "album not found"})
+		return
+	}
+	data, ok := h.readUpload(c)
+	if !ok {
+		return
+	}
+	processed, err := artwork.Process(data)
+	switch {
+	case errors.Is(err, artwork.ErrUnsupportedType):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, artwork.ErrTooManyPixels):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	uploaded, err := h.store(c, id, processed)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	previous, previousErr := h.Repo.Get(id)
+	saved, err := h.Repo.Save(uploaded)
+	if err != nil {
+		h.deleteFiles(c, uploaded)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	album.ImageUrl = saved.URL
+	if err := h.Albums.Update(album); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if previousErr == nil {
+		h.deleteFiles(c, previous)
+	}
+	c.IndentedJSON(http.StatusCreated, saved)
+}
+
+// readUpload reads the image from the "artwork" field of the multipart body, refusing bodies larger
+// than MaxBytes allows. It writes an error response and returns false if there is no image to read.
+func (h *ArtworkHandler) readUpload(c *gin.Context) ([]byte, bool) {
+	tooLarge := fmt.Sprintf("artwork must be at most %d bytes", h.MaxBytes)
+	// Allow for the multipart headers and boundaries around the image.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.MaxBytes+64<<10)
+	file, header, err := c.Request.FormFile("artwork")
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": tooLarge})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a multipart form with the image in its artwork field is required"})
+		return nil, false
+	}
+	defer file.Close()
+	if header.Size > h.MaxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": tooLarge})
+		return nil, false
+	}
+	data, err := io.ReadAll(io.LimitReader(file, h.MaxBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if int64(len(data)) > h.MaxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": tooLarge})
+		return nil, false
+	}
+	return data, true
+}
+
+// store puts the image and its thumbnails in storage under a new folder for the album, so a
+// replaced image never shares an address with the one before and cannot be served stale from a
+// cache. If any file cannot be stored, those already stored are deleted.
+func (h *ArtworkHandler) store(c *gin.Context, albumID string, processed artwork.Processed) (repository.Artwork, error) {
+	folder := make([]byte, 8)
+	if _, err := rand.Read(folder); err != nil {
+		return repository.Artwork{}, err
+	}
+	prefix := "albums/" + albumID + "/" + hex.EncodeToString(folder) + "/"
+	original := processed.Original
+	uploaded := repository.Artwork{
+		AlbumID:     albumID,
+		Key:         prefix + "original." + original.Extension(),
+		ContentType: original.ContentType,
+		Width:       original.Width,
+		Height:      original.Height,
+		Bytes:       len(original.Data),
+		Thumbnails:  repository.Thumbnails{},
+		UploadedBy:  currentUserID(c),
+	}
+	uploaded.URL = h.Store.URL(uploaded.Key)
+	if err := h.Store.Put(c.Request.Context(), uploaded.Key, original.Data, original.ContentType); err != nil {
+		return repository.Artwork{}, err
+	}
+	for _, thumbnail := range processed.Thumbnails {
+		key := fmt.Sprintf("%s%d.%s", prefix, thumbnail.Size, thumbnail.Extension())
+		if err := h.Store.Put(c.Request.Context(), key, thumbnail.Data, thumbnail.ContentType); err != nil {
+			h.deleteFiles(c, uploaded)
+			return repository.Artwork{}, err
+		}
+		uploaded.Thumbnails = append(uploaded.Thumbnails, repository.Thumbnail{
+			Size:        thumbnail.Size,
+			Key:         key,
+			URL:         h.Store.URL(key),
+			ContentType: thumbnail.ContentType,
+			Width:       thumbnail.Width,
+			Height:      thumbnail.Height,
+		})
+	}
+	return uploaded, nil
+}
+
+// deleteFiles removes the artwork's files from storage. Files left behind only take up space, so
+// failures are logged rather than failing the request.
+func (h *ArtworkHandler) deleteFiles(c *gin.Context, uploaded repository.Artwork) {
+	for _, key := range uploaded.Keys() {
+		if err := h.Store.Delete(c.Request.Context(), key); err != nil {
+			log.Printf("PostArtwork: failed to delete artwork file %s: %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+func setupArtworkRouter() (*gin.Engine, *ArtworkHandler, *mockStore) {
+	store := newMockStore()
+	handler := NewArtworkHandler(newMockArtworkRepo(), newTestHandler().Repo, store)
+
+	r := gin.Default()
+	r.GET("/albums/:id/artwork", handler.GetArtwork)
+	r.POST("/albums/:id/artwork", handler.PostArtwork)
+	return r, handler, store
+}
+
+func sleevePNG(t *testing.T, size int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for x := 0; x < size; x++ {
+		img.Set(x, x, color.RGBA{R: 200, A: 255})
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func uploadArtwork(r *gin.Engine, albumID, field, filename string, data []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile(field, filename)
+	part.Write(data)
+	form.Close()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/albums/"+albumID+"/artwork", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("X-User-ID", "staff-1")
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func Test_PostArtwork(t *testing.T) {
+	r, handler, store := setupArtworkRouter()
+
+	w := uploadArtwork(r, "2", "artwork", "scan.png", sleevePNG(t, 800))
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var uploaded repository.Artwork
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &uploaded))
+	assert.Equal(t, "image/png", uploaded.ContentType)
+	assert.Equal(t, 800, uploaded.Width)
+	assert.Equal(t, "staff-1", uploaded.UploadedBy)
+	assert.True(t, strings.HasPrefix(uploaded.URL, "https://cdn.example.com/albums/2/"), uploaded.URL)
+	require.Len(t, uploaded.Thumbnails, 3)
+	assert.Equal(t, 300, uploaded.Thumbnails[1].Width)
+	assert.Len(t, store.files, 4, "the image and three thumbnails are stored")
+	for _, key := range uploaded.Keys() {
+		assert.Contains(t, store.files, key)
+	}
+
+	album, err := handler.Albums.GetByID("2")
+	require.NoError(t, err)
+	assert.Equal(t, uploaded.URL, album.ImageUrl)
+
+	w = doRequest(r, "GET", "/albums/2/artwork", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), uploaded.URL)
+}
+
+func Test_PostArtwork_ReplacesEarlierArtwork(t *testing.T) {
+	r, _, store := setupArtworkRouter()
+	w := uploadArtwork(r, "2", "artwork", "scan.png", sleevePNG(t, 400))
+	require.Equal(t, http.StatusCreated, w.Code)
+	var first repository.Artwork
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &first))
+
+	w = uploadArtwork(r, "2", "artwork", "better-scan.png", sleevePNG(t, 500))
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	var second repository.Artwork
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &second))
+	assert.NotEqual(t, first.URL, second.URL, "a new image gets a new address")
+	for _, key := range first.Keys() {
+		assert.NotContains(t, store.files, key, "the earlier files are deleted")
+	}
+	assert.Len(t, store.files, 4)
+}
+
+func Test_PostArtwork_SniffsContent(t *testing.T) {
+	r, _, store := setupArtworkRouter()
+
+	w := uploadArtwork(r, "2", "artwork", "sleeve.png", []byte("<html><script>alert(1)</script></html>"))
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Empty(t, store.files)
+}
+
+func Test_PostArtwork_TooLarge(t *testing.T) {
+	r, handler, store := setupArtworkRouter()
+	handler.MaxBytes = 1000
+
+	w := uploadArtwork(r, "2", "artwork", "scan.png", append(sleevePNG(t, 10), make([]byte, 2000)...))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code, w.Body.String())
+	assert.Empty(t, store.files)
+}
+
+func Test_PostArtwork_BadRequests(t *testing.T) {
+	r, _, _ := setupArtworkRouter()
+
+	assert.Equal(t, http.StatusBadRequest, uploadArtwork(r, "2", "image", "scan.png", sleevePNG(t, 10)).Code, "the field must be artwork")
+	assert.Equal(t, http.StatusBadRequest, doRequest(r, "POST", "/albums/2/artwork", "staff-1", `{}`).Code)
+	assert.Equal(t, http.StatusNotFound, uploadArtwork(r, "999", "artwork", "scan.png", sleevePNG(t, 10)).Code)
+}
+
+func Test_GetArtwork_NoneUploaded(t *testing.T) {
+	r, _, _ := setupArtworkRouter()
+
+	w := doRequest(r, "GET", "/albums/1/artwork", "", "")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"message":"artwork not found"}`, w.Body.String())
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tvergilio/motown-house-backend/artwork"
+)
+
+type ArtworkProxyHandler struct {
+	Proxy *artwork.Proxy
+}
+
+func NewArtworkProxyHandler(proxy *artwork.Proxy) *ArtworkProxyHandler {
+	return &ArtworkProxyHandler{Proxy: proxy}
+}
+
+// GetProxiedArtwork handles GET /artwork?url=X&size=N, serving the remote artwork at X through the
+// shop so browsers never contact the host it is on. With a size, the image is scaled so its longest
+// side is at most that many pixels. Responses can be cached by browsers for as long as the proxy
+// caches them, and are revalidated with their ETag.
+func (h *ArtworkProxyHandler) GetProxiedArtwork(c *gin.Context) {
+	rawURL := c.Query("url")
+	if rawURL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url is required"})
+		return
+	}
+	size := 0
+	if s := c.Query("size"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > artwork.MaxProxySize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("size must be a number of pixels from 1 to %d", artwork.MaxProxySize)})
+			return
+		}
+		size = n
+	}
+	img, fetched, err := h.Proxy.Get(c.Request.Context(), rawURL, size)
+	switch {
+	case errors.Is(err, artwork.ErrForbiddenURL):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, artwork.ErrUpstream):
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	sum := sha256.Sum256(img.Data)
+	c.Header("Content-Type", img.ContentType)
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(h.Proxy.TTL.Seconds())))
+	c.Header("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	c.Header("X-Content-Type-Options", "nosniff")
+	http.ServeContent(c.Writer, c.Request, "", fetched, bytes.NewReader(img.Data))
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"image"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/artwork"
+)
+
+func setupArtworkProxyRouter(t *testing.T) (*gin.Engine, *httptest.Server) {
+	sleeve := sleevePNG(t, 800)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/cover.png" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(sleeve)
+	}))
+	t.Cleanup(upstream.Close)
+	proxy := artwork.NewProxy(t.TempDir(), []string{"127.0.0.1"}, 24*time.Hour, 1<<20)
+	// The test server listens on the loopback address, which the proxy's own transport refuses.
+	proxy.Client.Transport = &http.Transport{}
+	handler := NewArtworkProxyHandler(proxy)
+
+	r := gin.Default()
+	r.GET("/artwork", handler.GetProxiedArtwork)
+	return r, upstream
+}
+
+func proxyPath(rawURL, size string) string {
+	path := "/artwork?url=" + url.QueryEscape(rawURL)
+	if size != "" {
+		path += "&size=" + size
+	}
+	return path
+}
+
+func Test_GetProxiedArtwork(t *testing.T) {
+	r, upstream := setupArtworkProxyRouter(t)
+
+	w := doRequest(r, "GET", proxyPath(upstream.URL+"/cover.png", "300"), "", "")
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	assert.Equal(t, "public, max-age=86400", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	assert.NotEmpty(t, w.Header().Get("Last-Modified"))
+	config, _, err := image.DecodeConfig(w.Body)
+	require.NoError(t, err)
+	assert.Equal(t, 300, config.Width)
+
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	req := httptest.NewRequest("GET", proxyPath(upstream.URL+"/cover.png", "300"), nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+}
+
+func Test_GetProxiedArtwork_BadRequests(t *testing.T) {
+	r, upstream := setupArtworkProxyRouter(t)
+
+	assert.Equal(t, http.StatusBadRequest, doRequest(r, "GET", "/artwork", "", "").Code)
+	assert.Equal(t, http.StatusBadRequest, doRequest(r, "GET", proxyPath(upstream.URL+"/cover.png", "0"), "", "").Code)
+	assert.Equal(t, http.StatusBadRequest, doRequest(r, "GET", proxyPath(upstream.URL+"/cover.png", "5000"), "", "").Code)
+	assert.Equal(t, http.StatusBadRequest, doRequest(r, "GET", proxyPath(upstream.URL+"/cover.png", "big"), "", "").Code)
+}
+
+func Test_GetProxiedArtwork_ForbiddenHosts(t *testing.T) {
+	r, _ := setupArtworkProxyRouter(t)
+
+	for _, rawURL := range []string{"http://169.254.169.254/latest/meta-data/", "file:///etc/passwd", "https://example.com/cover.jpg"} {
+		w := doRequest(r, "GET", proxyPath(rawURL, ""), "", "")
+		assert.Equal(t, http.StatusForbidden, w.Code, rawURL)
+	}
+}
+
+func Test_GetProxiedArtwork_UpstreamFailure(t *testing.T) {
+	r, upstream := setupArtworkProxyRouter(t)
+
+	w := doRequest(r, "GET", proxyPath(upstream.URL+"/missing.png", ""), "", "")
+
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	assert.True(t, strings.Contains(w.Body.String(), "404"), w.Body.String())
+}
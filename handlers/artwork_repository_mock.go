@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// In-memory mock implementations of ArtworkRepository and storage.Store for testing
+
+type mockArtworkRepo struct {
+	artwork map[string]repository.Artwork
+}
+
+func newMockArtworkRepo() *mockArtworkRepo {
+	return &mockArtworkRepo{artwork: map[string]repository.Artwork{}}
+}
+
+func (m *mockArtworkRepo) Save(artwork repository.Artwork) (repository.Artwork, error) {
+	artwork.UploadedAt = time.Now()
+	m.artwork[artwork.AlbumID] = artwork
+	return artwork, nil
+}
+
+func (m *mockArtworkRepo) Get(albumID string) (repository.Artwork, error) {
+	artwork, ok := m.artwork[albumID]
+	if !ok {
+		return repository.Artwork{}, repository.ErrArtworkNotFound
+	}
+	return artwork, nil
+}
+
+func (m *mockArtworkRepo) Delete(albumID string) error {
+	if _, ok := m.artwork[albumID]; !ok {
+		return repository.ErrArtworkNotFound
+	}
+	delete(m.artwork, albumID)
+	return nil
+}
+
+type mockStore struct {
+	files map[string][]byte
+}
+
+func newMockStore() *mockStore {
+	return &mockStore{files: map[string][]byte{}}
+}
+
+func (m *mockStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	m.files[key] = data
+	return nil
+}
+
+func (m *mockStore) Delete(ctx context.Context, key string) error {
+	delete(m.files, key)
+	return nil
+}
+
+func (m *mockStore) URL(key string) string {
+	return "https://cdn.example.com/" + key
+}
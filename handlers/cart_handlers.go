@@ -0,0 +1,455 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tvergilio/motown-house-backend/promotions"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+type CartHandler struct {
+	Repo      repository.CartRepository
+	Albums    repository.AlbumRepository
+	Inventory repository.InventoryRepository
+
+	// Promotions is optional; when set, carts are priced with any promotions that apply.
+	Promotions repository.PromotionRepository
+
+	// Genres is optional; when set, promotions for a genre also apply to its sub-genres.
+	Genres repository.GenreRepository
+
+	// Releases is optional; when set, a release of an album can be added to the cart and is priced
+	// as that release.
+	Releases repository.ReleaseRepository
+}
+
+func NewCartHandler(repo repository.CartRepository, albums repository.AlbumRepository, inventory repository.InventoryRepository) *CartHandler {
+	return &CartHandler{
+		Repo:      repo,
+		Albums:    albums,
+		Inventory: inventory,
+	}
+}
+
+// AddCartItemRequest is the body accepted by POST /carts/:id/items. Quantity defaults to 1. With a
+// ReleaseID the item is that release of the album, and Format defaults to the release's.
+type AddCartItemRequest struct {
+	AlbumID   string `json:"albumId" binding:"required"`
+	ReleaseID string `json:"releaseId"`
+	Format    string `json:"format"`
+	Quantity  int    `json:"quantity"`
+}
+
+// UpdateCartItemRequest is the body accepted by PUT /carts/:id/items/:albumId/:format, with a
+// releaseId query parameter for a release. A quantity of zero removes the item.
+type UpdateCartItemRequest struct {
+	Quantity *int `json:"quantity" binding:"required"`
+}
+
+// CouponRequest is the body accepted by PUT /carts/:id/coupon.
+type CouponRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// getCart loads the cart named in the URI. Carts owned by a user are only visible to that user;
+// anyone else gets the same 404 as for a cart that does not exist.
+func (h *CartHandler) getCart(c *gin.Context) (repository.Cart, bool) {
+	cart, err := h.Repo.GetByID(c.Param("id"))
+	if errors.Is(err, repository.ErrCartNotFound) || (err == nil && cart.UserID != "" && cart.UserID != currentUserID(c)) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "cart not found"})
+		return repository.Cart{}, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return repository.Cart{}, false
+	}
+	return cart, true
+}
+
+// available returns how many units of the album in the given format can still be sold.
+func (h *CartHandler) available(albumID string, format repository.Format) (int, error) {
+	levels, err := h.Inventory.GetStock(albumID)
+	if err != nil {
+		return 0, err
+	}
+	for _, level := range levels {
+		if level.Format == format {
+			return level.Available, nil
+		}
+	}
+	return 0, nil
+}
+
+// lineAlbum returns the album a cart item or order line is priced from: the album itself, or the
+// album as the release named by releaseID, which fails with ErrReleaseNotFound if releases are not
+// configured.
+func lineAlbum(albums repository.AlbumRepository, releases repository.ReleaseRepository, albumID, releaseID string) (repository.Album, error) {
+	album, err := albums.GetByID(albumID)
+	if err != nil || releaseID == "" {
+		return album, err
+	}
+	if releases == nil {
+		return repository.Album{}, repository.ErrReleaseNotFound
+	}
+	release, err := releases.GetByID(albumID, releaseID)
+	if err != nil {
+		return repository.Album{}, err
+	}
+	return album.ForRelease(release), nil
+}
+
+// quantityInFormat returns how many units of the album in the format the cart holds, counting
+// every release of it, since releases in a format share the album's stock in that format.
+func quantityInFormat(cart repository.Cart, albumID string, format repository.Format) int {
+	quantity := 0
+	for _, item := range cart.Items {
+		if item.AlbumID == albumID && item.Format == format {
+			quantity += item.Quantity
+		}
+	}
+	return quantity
+}
+
+// priceCart recalculates every line from the album's or release's current price, promotions and stock.
+func (h *CartHandler) priceCart(cart *repository.Cart) error {
+	if cart.Items == nil {
+		cart.Items = []repository.CartItem{}
+	}
+	active, err := loadPromotions(h.Promotions)
+	if err != nil {
+		return err
+	}
+	genres, err := loadGenres(h.Genres)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	cart.Subtotal, cart.Discount = 0, 0
+	for i := range cart.Items {
+		item := &cart.Items[i]
+		album, err := lineAlbum(h.Albums, h.Releases, item.AlbumID, item.ReleaseID)
+		if err != nil {
+			// The album or release has been removed from the catalogue; it stays visible but cannot be bought.
+			item.UnitPrice, item.Discount, item.Promotion, item.LineTotal, item.Available = 0, 0, "", 0, 0
+			continue
+		}
+		available, err := h.available(item.AlbumID, item.Format)
+		if err != nil {
+			return err
+		}
+		price := promotions.PriceLine(active, genres, cart.CouponCode, promotions.Line{Album: album, Quantity: item.Quantity}, now)
+		item.Title = album.Title
+		item.Artist = album.Artist
+		item.UnitPrice = price.UnitPrice
+		item.Discount = price.Discount
+		item.Promotion = ""
+		if price.Promotion != nil {
+			item.Promotion = price.Promotion.Name
+		}
+		item.LineTotal = price.Total
+		item.Available = available
+		cart.Subtotal += price.Subtotal
+		cart.Discount += price.Discount
+	}
+	cart.Total = cart.Subtotal - cart.Discount
+	return nil
+}
+
+// respondWithCart prices the cart and writes it with the given status.
+func (h *CartHandler) respondWithCart(c *gin.Context, status int, cart repository.Cart) {
+	if err := h.priceCart(&cart); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(status, cart)
+}
+
+// checkStock writes a 409 response and returns false if quantity exceeds the stock available.
+func (h *CartHandler) checkStock(c *gin.Context, albumID string, format repository.Format, quantity int) bool {
+	available, err := h.available(albumID, format)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	if quantity > available {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("only %d available", available), "available": available})
+		return false
+	}
+	return true
+}
+
+// saveCart persists the cart, writing an error response and returning false on failure.
+func (h *CartHandler) saveCart(c *gin.Context, cart repository.Cart) bool {
+	if err := h.Repo.Save(cart); err != nil {
+		if errors.Is(err, repository.ErrCartNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "cart not found"})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+// PostCart handles POST /carts. Signed-in users get their existing cart back if they have one.
+func (h *CartHandler) PostCart(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID != "" {
+		existing, err := h.Repo.GetByUser(userID)
+		if err == nil {
+			h.respondWithCart(c, http.StatusOK, existing)
+			return
+		}
+		if !errors.Is(err, repository.ErrCartNotFound) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	cart, err := h.Repo.Create(repository.Cart{UserID: userID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	h.respondWithCart(c, http.StatusCreated, cart)
+}
+
+// GetCart handles GET /carts/:id.
+func (h *CartHandler) GetCart(c *gin.Context) {
+	cart, ok := h.getCart(c)
+	if !ok {
+		return
+	}
+	h.respondWithCart(c, http.StatusOK, cart)
+}
+
+// PostCartItem handles POST /carts/:id/items, adding to the quantity if the item is already in the cart.
+func (h *CartHandler) PostCartItem(c *gin.Context) {
+	cart, ok := h.getCart(c)
+	if !ok {
+		return
+	}
+	var req AddCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+	if req.Quantity < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "quantity must be positive"})
+		return
+	}
+	album, err := lineAlbum(h.Albums, h.Releases, req.AlbumID, req.ReleaseID)
+	if errors.Is(err, repository.ErrReleaseNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "release not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "album not found"})
+		return
+	}
+	if req.Format == "" && req.ReleaseID != "" {
+		req.Format = string(album.Format)
+	}
+	format, err := repository.ParseFormat(req.Format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.ReleaseID != "" && format != album.Format {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("release %s is %s, not %s", req.ReleaseID, album.Format, format)})
+		return
+	}
+
+	i := cart.FindItem(req.AlbumID, req.ReleaseID, format)
+	if i < 0 {
+		cart.Items = append(cart.Items, repository.CartItem{AlbumID: req.AlbumID, ReleaseID: req.ReleaseID, Format: format})
+		i = len(cart.Items) - 1
+	}
+	cart.Items[i].Quantity += req.Quantity
+	if !h.checkStock(c, req.AlbumID, format, quantityInFormat(cart, req.AlbumID, format)) {
+		return
+	}
+	if !h.saveCart(c, cart) {
+		return
+	}
+	h.respondWithCart(c, http.StatusOK, cart)
+}
+
+// cartItemFromUri finds the item named by the :albumId and :format URI parameters and the releaseId
+// query parameter, writing a 400 or 404 response and returning -1 if it is not in the cart.
+func cartItemFromUri(c *gin.Context, cart repository.Cart) int {
+	format, err := repository.ParseFormat(c.Param("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return -1
+	}
+	i := cart.FindItem(c.Param("albumId"), c.Query("releaseId"), format)
+	if i < 0 {
+		c.JSON(http.StatusNotFound, gin.H{"message": "item not in cart"})
+	}
+	return i
+}
+
+// PutCartItem handles PUT /carts/:id/items/:albumId/:format, setting the quantity of an item.
+func (h *CartHandler) PutCartItem(c *gin.Context) {
+	cart, ok := h.getCart(c)
+	if !ok {
+		return
+	}
+	var req UpdateCartItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if *req.Quantity < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "quantity cannot be negative"})
+		return
+	}
+	i := cartItemFromUri(c, cart)
+	if i < 0 {
+		return
+	}
+	if *req.Quantity == 0 {
+		cart.Items = append(cart.Items[:i], cart.Items[i+1:]...)
+	} else {
+		cart.Items[i].Quantity = *req.Quantity
+		if !h.checkStock(c, cart.Items[i].AlbumID, cart.Items[i].Format, quantityInFormat(cart, cart.Items[i].AlbumID, cart.Items[i].Format)) {
+			return
+		}
+	}
+	if !h.saveCart(c, cart) {
+		return
+	}
+	h.respondWithCart(c, http.StatusOK, cart)
+}
+
+// DeleteCartItem handles DELETE /carts/:id/items/:albumId/:format.
+func (h *CartHandler) DeleteCartItem(c *gin.Context) {
+	cart, ok := h.getCart(c)
+	if !ok {
+		return
+	}
+	i := cartItemFromUri(c, cart)
+	if i < 0 {
+		return
+	}
+	cart.Items = append(cart.Items[:i], cart.Items[i+1:]...)
+	if !h.saveCart(c, cart) {
+		return
+	}
+	h.respondWithCart(c, http.StatusOK, cart)
+}
+
+// MergeCart handles POST /carts/:id/merge, called when a customer signs in. The anonymous cart
+// becomes the user's cart, or if they already have one its items are added to it, capped at the
+// stock available, and the anonymous cart is deleted.
+func (h *CartHandler) MergeCart(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	source, ok := h.getCart(c)
+	if !ok {
+		return
+	}
+	if source.UserID == userID {
+		h.respondWithCart(c, http.StatusOK, source)
+		return
+	}
+
+	target, err := h.Repo.GetByUser(userID)
+	if errors.Is(err, repository.ErrCartNotFound) {
+		source.UserID = userID
+		if !h.saveCart(c, source) {
+			return
+		}
+		h.respondWithCart(c, http.StatusOK, source)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, item := range source.Items {
+		available, err := h.available(item.AlbumID, item.Format)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		i := target.FindItem(item.AlbumID, item.ReleaseID, item.Format)
+		if i < 0 {
+			target.Items = append(target.Items, repository.CartItem{AlbumID: item.AlbumID, ReleaseID: item.ReleaseID, Format: item.Format})
+			i = len(target.Items) - 1
+		}
+		// Other releases in the format already in the cart take their share of the stock first.
+		available -= quantityInFormat(target, item.AlbumID, item.Format) - target.Items[i].Quantity
+		target.Items[i].Quantity = max(min(target.Items[i].Quantity+item.Quantity, available), target.Items[i].Quantity)
+		if target.Items[i].Quantity == 0 {
+			target.Items = append(target.Items[:i], target.Items[i+1:]...)
+		}
+	}
+	if !h.saveCart(c, target) {
+		return
+	}
+	if err := h.Repo.Delete(source.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	h.respondWithCart(c, http.StatusOK, target)
+}
+
+// PutCoupon handles PUT /carts/:id/coupon, applying a coupon code to the cart. Only one code can be
+// applied at a time; a new code replaces the old one.
+func (h *CartHandler) PutCoupon(c *gin.Context) {
+	cart, ok := h.getCart(c)
+	if !ok {
+		return
+	}
+	var req CouponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if h.Promotions == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "coupon not found"})
+		return
+	}
+	promotion, err := h.Promotions.GetByCode(req.Code)
+	if errors.Is(err, repository.ErrPromotionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "coupon not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !promotion.AvailableAt(time.Now()) {
+		c.JSON(http.StatusConflict, gin.H{"error": "coupon has expired or is no longer available"})
+		return
+	}
+	cart.CouponCode = promotion.Code
+	if !h.saveCart(c, cart) {
+		return
+	}
+	h.respondWithCart(c, http.StatusOK, cart)
+}
+
+// DeleteCoupon handles DELETE /carts/:id/coupon.
+func (h *CartHandler) DeleteCoupon(c *gin.Context) {
+	cart, ok := h.getCart(c)
+	if !ok {
+		return
+	}
+	cart.CouponCode = ""
+	if !h.saveCart(c, cart) {
+		return
+	}
+	h.respondWithCart(c, http.StatusOK, cart)
+}
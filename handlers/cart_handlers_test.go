@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+func setupCartRouter(carts *mockCartRepo, inventory *mockInventoryRepo) *gin.Engine {
+	handler := NewCartHandler(carts, newTestHandler().Repo, inventory)
+	r := gin.Default()
+	r.POST("/carts", handler.PostCart)
+	r.GET("/carts/:id", handler.GetCart)
+	r.POST("/carts/:id/items", handler.PostCartItem)
+	r.PUT("/carts/:id/items/:albumId/:format", handler.PutCartItem)
+	r.DELETE("/carts/:id/items/:albumId/:format", handler.DeleteCartItem)
+	r.POST("/carts/:id/merge", handler.MergeCart)
+	return r
+}
+
+// newCartTestInventory stocks the albums seeded by newTestHandler.
+func newCartTestInventory() *mockInventoryRepo {
+	return newMockInventoryRepo(
+		repository.StockLevel{AlbumID: "1", Format: repository.FormatLP, OnHand: 5},
+		repository.StockLevel{AlbumID: "2", Format: repository.FormatCD, OnHand: 3, Reserved: 1},
+	)
+}
+
+func Test_PostCart_CreatesAnonymousCart(t *testing.T) {
+	r := setupCartRouter(newMockCartRepo(), newCartTestInventory())
+
+	w := doRequest(r, "POST", "/carts", "", "")
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	cart := decodeJSON[repository.Cart](t, w.Body.Bytes())
+	assert.NotEmpty(t, cart.ID)
+	assert.Empty(t, cart.UserID)
+	assert.Empty(t, cart.Items)
+}
+
+func Test_PostCart_ReturnsExistingUserCart(t *testing.T) {
+	carts := newMockCartRepo()
+	existing, _ := carts.Create(repository.Cart{UserID: "user-1"})
+	r := setupCartRouter(carts, newCartTestInventory())
+
+	w := doRequest(r, "POST", "/carts", "user-1", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, existing.ID, decodeJSON[repository.Cart](t, w.Body.Bytes()).ID)
+}
+
+func Test_PostCartItem_PricesFromAlbum(t *testing.T) {
+	carts := newMockCartRepo()
+	cart, _ := carts.Create(repository.Cart{})
+	r := setupCartRouter(carts, newCartTestInventory())
+
+	doRequest(r, "POST", "/carts/"+cart.ID+"/items", "", `{"albumId":"1","format":"LP","quantity":2}`)
+	w := doRequest(r, "POST", "/carts/"+cart.ID+"/items", "", `{"albumId":"1","format":"lp"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	priced := decodeJSON[repository.Cart](t, w.Body.Bytes())
+	require.Len(t, priced.Items, 1)
+	assert.Equal(t, 3, priced.Items[0].Quantity)
+	assert.Equal(t, "Thriller", priced.Items[0].Title)
+	assert.Equal(t, money.MustParse("25.99"), priced.Items[0].UnitPrice)
+	assert.Equal(t, money.MustParse("77.97"), priced.Items[0].LineTotal)
+	assert.Equal(t, money.MustParse("77.97"), priced.Subtotal)
+}
+
+func Test_PostCartItem_RecalculatesWhenPriceChanges(t *testing.T) {
+	carts := newMockCartRepo()
+	cart, _ := carts.Create(repository.Cart{Items: []repository.CartItem{{AlbumID: "2", Format: repository.FormatCD, Quantity: 1}}})
+	albumHandler := newTestHandler()
+	handler := NewCartHandler(carts, albumHandler.Repo, newCartTestInventory())
+	album, _ := albumHandler.Repo.GetByID("2")
+	album.Price = money.MustParse("19.99")
+	require.NoError(t, albumHandler.Repo.Update(album))
+	r := gin.Default()
+	r.GET("/carts/:id", handler.GetCart)
+
+	w := doRequest(r, "GET", "/carts/"+cart.ID, "", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, money.MustParse("19.99"), decodeJSON[repository.Cart](t, w.Body.Bytes()).Subtotal)
+}
+
+func Test_PostCartItem_ExceedsStock(t *testing.T) {
+	carts := newMockCartRepo()
+	cart, _ := carts.Create(repository.Cart{})
+	r := setupCartRouter(carts, newCartTestInventory())
+
+	w := doRequest(r, "POST", "/carts/"+cart.ID+"/items", "", `{"albumId":"2","format":"CD","quantity":3}`)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "only 2 available")
+	stored, _ := carts.GetByID(cart.ID)
+	assert.Empty(t, stored.Items)
+}
+
+func Test_PostCartItem_PricesRelease(t *testing.T) {
+	carts := newMockCartRepo()
+	cart, _ := carts.Create(repository.Cart{})
+	releases := newMockReleaseRepo()
+	original, _ := releases.Create(repository.Release{AlbumID: "1", Format: repository.FormatLP, Edition: "Original", Price: money.MustParse("60.00"), Currency: "GBP"})
+	handler := NewCartHandler(carts, newTestHandler().Repo, newCartTestInventory())
+	handler.Releases = releases
+	r := gin.Default()
+	r.POST("/carts/:id/items", handler.PostCartItem)
+	r.PUT("/carts/:id/items/:albumId/:format", handler.PutCartItem)
+
+	w := doRequest(r, "POST", "/carts/"+cart.ID+"/items", "", `{"albumId":"1","releaseId":"`+original.ID+`","quantity":2}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = doRequest(r, "POST", "/carts/"+cart.ID+"/items", "", `{"albumId":"1","format":"LP","quantity":3}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	priced := decodeJSON[repository.Cart](t, w.Body.Bytes())
+	require.Len(t, priced.Items, 2, "a release is a line of its own")
+	assert.Equal(t, original.ID, priced.Items[0].ReleaseID)
+	assert.Equal(t, repository.FormatLP, priced.Items[0].Format, "the format defaults to the release's")
+	assert.Equal(t, money.MustParse("60.00"), priced.Items[0].UnitPrice)
+	assert.Equal(t, money.MustParse("25.99"), priced.Items[1].UnitPrice)
+	assert.Equal(t, money.MustParse("197.97"), priced.Total)
+
+	w = doRequest(r, "POST", "/carts/"+cart.ID+"/items", "", `{"albumId":"1","releaseId":"`+original.ID+`"}`)
+	assert.Equal(t, http.StatusConflict, w.Code, "releases share the album's stock in their format")
+	w = doRequest(r, "PUT", "/carts/"+cart.ID+"/items/1/LP?releaseId="+original.ID, "", `{"quantity":1}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, 1, decodeJSON[repository.Cart](t, w.Body.Bytes()).Items[0].Quantity)
+
+	w = doRequest(r, "POST", "/carts/"+cart.ID+"/items", "", `{"albumId":"1","releaseId":"`+original.ID+`","format":"CD"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doRequest(r, "POST", "/carts/"+cart.ID+"/items", "", `{"albumId":"1","releaseId":"release-404"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "release not found")
+}
+
+func Test_PostCartItem_UnknownAlbum(t *testing.T) {
+	carts := newMockCartRepo()
+	cart, _ := carts.Create(repository.Cart{})
+	r := setupCartRouter(carts, newCartTestInventory())
+
+	w := doRequest(r, "POST", "/carts/"+cart.ID+"/items", "", `{"albumId":"999","format":"CD"}`)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "album not found")
+}
+
+func Test_GetCart_OtherUsersCartIsHidden(t *testing.T) {
+	carts := newMockCartRepo()
+	cart, _ := carts.Create(repository.Cart{UserID: "user-1"})
+	r := setupCartRouter(carts, newCartTestInventory())
+
+	assert.Equal(t, http.StatusNotFound, doRequest(r, "GET", "/carts/"+cart.ID, "user-2", "").Code)
+	assert.Equal(t, http.StatusNotFound, doRequest(r, "GET", "/carts/"+cart.ID, "", "").Code)
+	assert.Equal(t, http.StatusOK, doRequest(r, "GET", "/carts/"+cart.ID, "user-1", "").Code)
+}
+
+func Test_PutCartItem_ChangesAndRemoves(t *testing.T) {
+	carts := newMockCartRepo()
+	cart, _ := carts.Create(repository.Cart{Items: []repository.CartItem{{AlbumID: "1", Format: repository.FormatLP, Quantity: 1}}})
+	r := setupCartRouter(carts, newCartTestInventory())
+
+	w := doRequest(r, "PUT", "/carts/"+cart.ID+"/items/1/LP", "", `{"quantity":4}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 4, decodeJSON[repository.Cart](t, w.Body.Bytes()).Items[0].Quantity)
+
+	w = doRequest(r, "PUT", "/carts/"+cart.ID+"/items/1/LP", "", `{"quantity":6}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = doRequest(r, "PUT", "/carts/"+cart.ID+"/items/1/LP", "", `{"quantity":0}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, decodeJSON[repository.Cart](t, w.Body.Bytes()).Items)
+}
+
+func Test_PutCartItem_MissingQuantity(t *testing.T) {
+	carts := newMockCartRepo()
+	cart, _ := carts.Create(repository.Cart{Items: []repository.CartItem{{AlbumID: "1", Format: repository.FormatLP, Quantity: 1}}})
+	r := setupCartRouter(carts, newCartTestInventory())
+
+	w := doRequest(r, "PUT", "/carts/"+cart.ID+"/items/1/LP", "", `{}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_DeleteCartItem_NotInCart(t *testing.T) {
+	carts := newMockCartRepo()
+	cart, _ := carts.Create(repository.Cart{})
+	r := setupCartRouter(carts, newCartTestInventory())
+
+	w := doRequest(r, "DELETE", "/carts/"+cart.ID+"/items/1/CD", "", "")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "item not in cart")
+}
+
+func Test_MergeCart_ClaimsAnonymousCart(t *testing.T) {
+	carts := newMockCartRepo()
+	cart, _ := carts.Create(repository.Cart{Items: []repository.CartItem{{AlbumID: "1", Format: repository.FormatLP, Quantity: 1}}})
+	r := setupCartRouter(carts, newCartTestInventory())
+
+	w := doRequest(r, "POST", "/carts/"+cart.ID+"/merge", "user-1", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	merged := decodeJSON[repository.Cart](t, w.Body.Bytes())
+	assert.Equal(t, cart.ID, merged.ID)
+	assert.Equal(t, "user-1", merged.UserID)
+}
+
+func Test_MergeCart_IntoExistingUserCart(t *testing.T) {
+	carts := newMockCartRepo()
+	userCart, _ := carts.Create(repository.Cart{UserID: "user-1", Items: []repository.CartItem{{AlbumID: "1", Format: repository.FormatLP, Quantity: 4}}})
+	anonymous, _ := carts.Create(repository.Cart{Items: []repository.CartItem{
+		{AlbumID: "1", Format: repository.FormatLP, Quantity: 3},
+		{AlbumID: "2", Format: repository.FormatCD, Quantity: 1},
+	}})
+	r := setupCartRouter(carts, newCartTestInventory())
+
+	w := doRequest(r, "POST", "/carts/"+anonymous.ID+"/merge", "user-1", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	merged := decodeJSON[repository.Cart](t, w.Body.Bytes())
+	assert.Equal(t, userCart.ID, merged.ID)
+	require.Len(t, merged.Items, 2)
+	assert.Equal(t, 5, merged.Items[0].Quantity, "merged quantity is capped at the stock available")
+	assert.Equal(t, 1, merged.Items[1].Quantity)
+	_, err := carts.GetByID(anonymous.ID)
+	assert.ErrorIs(t, err, repository.ErrCartNotFound)
+}
+
+func Test_MergeCart_RequiresUser(t *testing.T) {
+	carts := newMockCartRepo()
+	cart, _ := carts.Create(repository.Cart{})
+	r := setupCartRouter(carts, newCartTestInventory())
+
+	w := doRequest(r, "POST", "/carts/"+cart.ID+"/merge", "", "")
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
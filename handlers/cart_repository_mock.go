@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// In-memory mock implementation of CartRepository for testing
+
+type mockCartRepo struct {
+	carts  map[string]repository.Cart
+	nextID int
+}
+
+func newMockCartRepo() *mockCartRepo {
+	return &mockCartRepo{carts: make(map[string]repository.Cart)}
+}
+
+// copyCart stops handlers from mutating stored carts through a shared Items slice.
+func copyCart(cart repository.Cart) repository.Cart {
+	cart.Items = append([]repository.CartItem(nil), cart.Items...)
+	return cart
+}
+
+func (m *mockCartRepo) Create(cart repository.Cart) (repository.Cart, error) {
+	m.nextID++
+	cart.ID = fmt.Sprintf("cart-%d", m.nextID)
+	cart.CreatedAt = time.Now()
+	cart.UpdatedAt = cart.CreatedAt
+	m.carts[cart.ID] = copyCart(cart)
+	return cart, nil
+}
+
+func (m *mockCartRepo) GetByID(id string) (repository.Cart, error) {
+	cart, ok := m.carts[id]
+	if !ok {
+		return repository.Cart{}, repository.ErrCartNotFound
+	}
+	return copyCart(cart), nil
+}
+
+func (m *mockCartRepo) GetByUser(userID string) (repository.Cart, error) {
+	for _, cart := range m.carts {
+		if cart.UserID == userID {
+			return copyCart(cart), nil
+		}
+	}
+	return repository.Cart{}, repository.ErrCartNotFound
+}
+
+func (m *mockCartRepo) Save(cart repository.Cart) error {
+	if _, ok := m.carts[cart.ID]; !ok {
+		return repository.ErrCartNotFound
+	}
+	cart.UpdatedAt = time.Now()
+	m.carts[cart.ID] = copyCart(cart)
+	return nil
+}
+
+func (m *mockCartRepo) Delete(id string) error {
+	delete(m.carts, id)
+	return nil
+}
+
+func (m *mockCartRepo) DeleteIdleSince(cutoff time.Time) (int64, error) {
+	var deleted int64
+	for id, cart := range m.carts {
+		if cart.UpdatedAt.Before(cutoff) {
+			delete(m.carts, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+type CollectionHandler struct {
+	Repo   repository.CollectionRepository
+	Albums repository.AlbumRepository
+}
+
+func NewCollectionHandler(repo repository.CollectionRepository, albums repository.AlbumRepository) *CollectionHandler {
+	return &CollectionHandler{Repo: repo, Albums: albums}
+}
+
+// CollectionRequest is the body accepted by POST /collections and PUT /collections/:id. A collection
+// with rules is a smart collection; one without lists albumIds in order.
+type CollectionRequest struct {
+	Title       string                     `json:"title" binding:"required"`
+	Description string                     `json:"description"`
+	CoverURL    string                     `json:"coverUrl"`
+	AlbumIDs    []string                   `json:"albumIds"`
+	Rules       repository.CollectionRules `json:"rules"`
+}
+
+// CollectionAlbumRequest is the body accepted by POST /collections/:id/albums. Position counts from
+// 1; without it the album goes at the end.
+type CollectionAlbumRequest struct {
+	AlbumID  string `json:"albumId" binding:"required"`
+	Position int    `json:"position"`
+}
+
+func respondWithCollectionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrCollectionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "collection not found"})
+	case errors.Is(err, repository.ErrDuplicateCollection), errors.Is(err, repository.ErrSmartCollection):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// bindCollection reads and validates a collection from the request body, checking every album it
+// lists exists. It writes an error response and returns false if the body is not a valid collection.
+func (h *CollectionHandler) bindCollection(c *gin.Context, id string) (repository.Collection, bool) {
+	var req CollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return repository.Collection{}, false
+	}
+	collection := repository.Collection{
+		ID:          id,
+		Title:       req.Title,
+		Description: req.Description,
+		CoverURL:    req.CoverURL,
+		AlbumIDs:    req.AlbumIDs,
+		Rules:       req.Rules,
+	}
+	collection.Normalize()
+	if err := collection.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return repository.Collection{}, false
+	}
+	for _, albumID := range collection.AlbumIDs {
+		if !h.albumExists(c, albumID) {
+			return repository.Collection{}, false
+		}
+	}
+	return collection, true
+}
+
+// albumExists reports whether the album exists. It writes an error response and returns false if not.
+func (h *CollectionHandler) albumExists(c *gin.Context, id string) bool {
+	if _, err := h.Albums.GetByID(id); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown album " + id})
+		return false
+	}
+	return true
+}
+
+// GetCollections handles GET /collections, listing every collection by title. The albums in a
+// collection are listed by GET /collections/:id/albums.
+func (h *CollectionHandler) GetCollections(c *gin.Context) {
+	collections, err := h.Repo.List()
+	if err != nil {
+		respondWithCollectionError(c, err)
+		return
+	}
+	if collections == nil {
+		collections = []repository.Collection{}
+	}
+	c.IndentedJSON(http.StatusOK, collections)
+}
+
+// GetCollection handles GET /collections/:id.
+func (h *CollectionHandler) GetCollection(c *gin.Context) {
+	collection, err := h.Repo.GetByID(c.Param("id"))
+	if err != nil {
+		respondWithCollectionError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, collection)
+}
+
+// PostCollection handles POST /collections. The collection's ID is made from its title, which must
+// not already be used by another collection.
+func (h *CollectionHandler) PostCollection(c *gin.Context) {
+	collection, ok := h.bindCollection(c, "")
+	if !ok {
+		return
+	}
+	created, err := h.Repo.Create(collection)
+	if err != nil {
+		respondWithCollectionError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusCreated, created)
+}
+
+// PutCollection handles PUT /collections/:id, replacing the collection. It keeps its ID when retitled.
+func (h *CollectionHandler) PutCollection(c *gin.Context) {
+	collection, ok := h.bindCollection(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	updated, err := h.Repo.Update(collection)
+	if err != nil {
+		respondWithCollectionError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, updated)
+}
+
+// DeleteCollection handles DELETE /collections/:id. The albums in it are not affected.
+func (h *CollectionHandler) DeleteCollection(c *gin.Context) {
+	if err := h.Repo.Delete(c.Param("id")); err != nil {
+		respondWithCollectionError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// getCurated returns the curated collection named by the :id parameter. It writes an error response
+// and returns false if there is no such collection or it is a smart one.
+func (h *CollectionHandler) getCurated(c *gin.Context) (repository.Collection, bool) {
+	collection, err := h.Repo.GetByID(c.Param("id"))
+	if err == nil && collection.Kind == repository.CollectionSmart {
+		err = repository.ErrSmartCollection
+	}
+	if err != nil {
+		respondWithCollectionError(c, err)
+		return repository.Collection{}, false
+	}
+	return collection, true
+}
+
+// PostCollectionAlbum handles POST /collections/:id/albums, putting an album into a curated
+// collection at the position given, or moving it there if it is already in the collection.
+func (h *CollectionHandler) PostCollectionAlbum(c *gin.Context) {
+	var req CollectionAlbumRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Position < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "position must be 1 or more"})
+		return
+	}
+	collection, ok := h.getCurated(c)
+	if !ok || !h.albumExists(c, req.AlbumID) {
+		return
+	}
+	ids := repository.AlbumIDs{}
+	for _, id := range collection.AlbumIDs {
+		if id != req.AlbumID {
+			ids = append(ids, id)
+		}
+	}
+	at := len(ids)
+	if req.Position > 0 && req.Position-1 < at {
+		at = req.Position - 1
+	}
+	ids = append(ids[:at], append(repository.AlbumIDs{req.AlbumID}, ids[at:]...)...)
+	collection.AlbumIDs = ids
+	updated, err := h.Repo.Update(collection)
+	if err != nil {
+		respondWithCollectionError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, updated)
+}
+
+// DeleteCollectionAlbum handles DELETE /collections/:id/albums/:albumId, taking an album out of a
+// curated collection.
+func (h *CollectionHandler) DeleteCollectionAlbum(c *gin.Context) {
+	collection, ok := h.getCurated(c)
+	if !ok {
+		return
+	}
+	ids := repository.AlbumIDs{}
+	for _, id := range collection.AlbumIDs {
+		if id != c.Param("albumId") {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == len(collection.AlbumIDs) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "album not in collection"})
+		return
+	}
+	collection.AlbumIDs = ids
+	if _, err := h.Repo.Update(collection); err != nil {
+		respondWithCollectionError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
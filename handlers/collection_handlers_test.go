@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// setupCollectionRouter serves the album, tag and collection routes over the mock catalogue and
+// genre taxonomy, with no collections yet.
+func setupCollectionRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	collections := newMockCollectionRepo()
+	albums := newTestHandler()
+	albums.Genres = newMockGenreRepo()
+	albums.Collections = collections
+	handler := NewCollectionHandler(collections, albums.Repo)
+	r := setupRouter(albums)
+	r.GET("/tags", albums.GetTags)
+	r.GET("/collections", handler.GetCollections)
+	r.GET("/collections/:id", handler.GetCollection)
+	r.POST("/collections", handler.PostCollection)
+	r.PUT("/collections/:id", handler.PutCollection)
+	r.DELETE("/collections/:id", handler.DeleteCollection)
+	r.GET("/collections/:id/albums", albums.GetCollectionAlbums)
+	r.POST("/collections/:id/albums", handler.PostCollectionAlbum)
+	r.DELETE("/collections/:id/albums/:albumId", handler.DeleteCollectionAlbum)
+	return r
+}
+
+// collectionTitles returns the titles of the albums in a list of albums.
+func collectionTitles(t *testing.T, body []byte) []string {
+	t.Helper()
+	var albums []repository.Album
+	require.NoError(t, json.Unmarshal(body, &albums))
+	titles := []string{}
+	for _, album := range albums {
+		titles = append(titles, album.Title)
+	}
+	return titles
+}
+
+// tagAlbums tags Thriller as a staff pick and Songs in the Key of Life as a staff pick and a double album.
+func tagAlbums(t *testing.T, r *gin.Engine) {
+	t.Helper()
+	for _, put := range []struct{ id, body string }{
+		{"1", `{"title":"Thriller","artist":"Michael Jackson","price":25.99,"year":1982,"imageUrl":"x","genre":"Pop","tags":["Staff Pick"]}`},
+		{"2", `{"title":"Songs in the Key of Life","artist":"Stevie Wonder","price":42.50,"year":1976,"imageUrl":"x","genre":"Motown","tags":["staff  pick","Double Album","double album"]}`},
+	} {
+		w := doRequest(r, "PUT", "/albums/"+put.id, "staff-1", put.body)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+}
+
+func Test_Tags(t *testing.T) {
+	r := setupCollectionRouter(t)
+	tagAlbums(t, r)
+
+	w := doRequest(r, "GET", "/albums/2", "", "")
+	assert.Equal(t, repository.Tags{"staff pick", "double album"}, decodeJSON[repository.Album](t, w.Body.Bytes()).Tags, "tags are stored tidied and once each")
+
+	w = doRequest(r, "GET", "/albums?tag=STAFF%20PICK", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.ElementsMatch(t, []string{"Thriller", "Songs in the Key of Life"}, collectionTitles(t, w.Body.Bytes()))
+	w = doRequest(r, "GET", "/albums?tag=staff%20pick&genre=soul", "", "")
+	assert.Equal(t, []string{"Songs in the Key of Life"}, collectionTitles(t, w.Body.Bytes()))
+	w = doRequest(r, "GET", "/albums?tag=unknown", "", "")
+	assert.JSONEq(t, `[]`, w.Body.String())
+
+	w = doRequest(r, "GET", "/tags", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"tag":"staff pick","count":2},{"tag":"double album","count":1}]`, w.Body.String())
+
+	w = doRequest(r, "PUT", "/albums/1", "staff-1", `{"title":"Thriller","artist":"Michael Jackson","price":25.99,"year":1982,"imageUrl":"x","genre":"Pop","tags":["`+strings.Repeat("x", repository.MaxTagLength+1)+`"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_CuratedCollection(t *testing.T) {
+	r := setupCollectionRouter(t)
+
+	w := doRequest(r, "POST", "/collections", "staff-1", `{"title":"Staff  Picks","description":"Chosen by the shop","coverUrl":"https://example.com/picks.jpg","albumIds":["2"]}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	collection := decodeJSON[repository.Collection](t, w.Body.Bytes())
+	assert.Equal(t, "staff-picks", collection.ID)
+	assert.Equal(t, "Staff Picks", collection.Title)
+	assert.Equal(t, repository.CollectionCurated, collection.Kind)
+
+	w = doRequest(r, "POST", "/collections", "staff-1", `{"title":"Staff Picks!"}`)
+	assert.Equal(t, http.StatusConflict, w.Code, "titles make IDs, so they must differ")
+
+	w = doRequest(r, "POST", "/collections/staff-picks/albums", "staff-1", `{"albumId":"1","position":1}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, repository.AlbumIDs{"1", "2"}, decodeJSON[repository.Collection](t, w.Body.Bytes()).AlbumIDs)
+	w = doRequest(r, "POST", "/collections/staff-picks/albums", "staff-1", `{"albumId":"101"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = doRequest(r, "POST", "/collections/staff-picks/albums", "staff-1", `{"albumId":"1"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, repository.AlbumIDs{"2", "101", "1"}, decodeJSON[repository.Collection](t, w.Body.Bytes()).AlbumIDs, "adding an album again moves it")
+
+	w = doRequest(r, "GET", "/collections/staff-picks/albums", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"Songs in the Key of Life", "Thriller", "Thriller"}, collectionTitles(t, w.Body.Bytes()))
+
+	w = doRequest(r, "DELETE", "/collections/staff-picks/albums/101", "staff-1", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = doRequest(r, "DELETE", "/collections/staff-picks/albums/101", "staff-1", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doRequest(r, "POST", "/collections/staff-picks/albums", "staff-1", `{"albumId":"999"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doRequest(r, "PUT", "/collections/staff-picks", "staff-1", `{"title":"Shop Favourites","albumIds":["1"]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	collection = decodeJSON[repository.Collection](t, w.Body.Bytes())
+	assert.Equal(t, "staff-picks", collection.ID, "retitling keeps the ID")
+	assert.Equal(t, "", collection.Description)
+
+	w = doRequest(r, "GET", "/collections", "", "")
+	assert.Len(t, decodeJSON[[]repository.Collection](t, w.Body.Bytes()), 1)
+	w = doRequest(r, "DELETE", "/collections/staff-picks", "staff-1", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = doRequest(r, "GET", "/collections/staff-picks", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_SmartCollection(t *testing.T) {
+	r := setupCollectionRouter(t)
+	tagAlbums(t, r)
+
+	w := doRequest(r, "POST", "/collections", "staff-1", `{"title":"Seventies Soul","rules":"genre=Soul AND year<1980"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	collection := decodeJSON[repository.Collection](t, w.Body.Bytes())
+	assert.Equal(t, repository.CollectionSmart, collection.Kind)
+
+	w = doRequest(r, "GET", "/collections/seventies-soul/albums", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"Songs in the Key of Life"}, collectionTitles(t, w.Body.Bytes()), "Motown is a sub-genre of Soul")
+
+	w = doRequest(r, "POST", "/collections", "staff-1", `{"title":"Picks Under £30","rules":"tag=staff pick AND price<£30"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = doRequest(r, "GET", "/collections/picks-under-30/albums", "", "")
+	assert.Equal(t, []string{"Thriller"}, collectionTitles(t, w.Body.Bytes()))
+
+	w = doRequest(r, "POST", "/collections/seventies-soul/albums", "staff-1", `{"albumId":"1"}`)
+	assert.Equal(t, http.StatusConflict, w.Code, "smart collections are chosen by their rules")
+
+	for _, body := range []string{
+		`{"title":"Bad","rules":"genre<Soul"}`,
+		`{"title":"Bad","rules":"colour=red"}`,
+		`{"title":"Bad","rules":"year<1980","albumIds":["1"]}`,
+		`{"title":"Bad","albumIds":["999"]}`,
+		`{"title":"Bad","coverUrl":"file:///etc/passwd"}`,
+		`{"description":"no title"}`,
+	} {
+		w = doRequest(r, "POST", "/collections", "staff-1", body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+	w = doRequest(r, "GET", "/collections/missing/albums", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
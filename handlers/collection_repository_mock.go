@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// In-memory mock implementation of CollectionRepository for testing
+
+type mockCollectionRepo struct {
+	collections map[string]repository.Collection
+}
+
+func newMockCollectionRepo() *mockCollectionRepo {
+	return &mockCollectionRepo{collections: map[string]repository.Collection{}}
+}
+
+func (m *mockCollectionRepo) Create(collection repository.Collection) (repository.Collection, error) {
+	collection.Normalize()
+	if _, ok := m.collections[collection.ID]; ok {
+		return repository.Collection{}, repository.ErrDuplicateCollection
+	}
+	collection.CreatedAt = time.Now()
+	collection.UpdatedAt = collection.CreatedAt
+	m.collections[collection.ID] = collection
+	return collection, nil
+}
+
+func (m *mockCollectionRepo) GetByID(id string) (repository.Collection, error) {
+	collection, ok := m.collections[id]
+	if !ok {
+		return repository.Collection{}, repository.ErrCollectionNotFound
+	}
+	return collection, nil
+}
+
+func (m *mockCollectionRepo) List() ([]repository.Collection, error) {
+	collections := []repository.Collection{}
+	for _, collection := range m.collections {
+		collections = append(collections, collection)
+	}
+	sort.Slice(collections, func(i, j int) bool {
+		return strings.ToLower(collections[i].Title) < strings.ToLower(collections[j].Title)
+	})
+	return collections, nil
+}
+
+func (m *mockCollectionRepo) Update(collection repository.Collection) (repository.Collection, error) {
+	current, ok := m.collections[collection.ID]
+	if !ok {
+		return repository.Collection{}, repository.ErrCollectionNotFound
+	}
+	collection.Normalize()
+	collection.CreatedAt = current.CreatedAt
+	collection.UpdatedAt = time.Now()
+	m.collections[collection.ID] = collection
+	return collection, nil
+}
+
+func (m *mockCollectionRepo) Delete(id string) error {
+	if _, ok := m.collections[id]; !ok {
+		return repository.ErrCollectionNotFound
+	}
+	delete(m.collections, id)
+	return nil
+}
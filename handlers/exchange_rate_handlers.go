@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+type ExchangeRateHandler struct {
+	Repo repository.ExchangeRateRepository
+}
+
+func NewExchangeRateHandler(repo repository.ExchangeRateRepository) *ExchangeRateHandler {
+	return &ExchangeRateHandler{Repo: repo}
+}
+
+// ExchangeRatesRequest is the body accepted by PUT /admin/exchange-rates. It replaces the whole table.
+type ExchangeRatesRequest struct {
+	Rates []ExchangeRateRequest `json:"rates" binding:"required"`
+}
+
+// ExchangeRateRequest is one currency in an exchange-rate upload: how many units of it one unit of
+// the base currency buys, and optionally the step converted prices are rounded to.
+type ExchangeRateRequest struct {
+	Currency string       `json:"currency"`
+	Rate     float64      `json:"rate"`
+	RoundTo  money.Amount `json:"roundTo"`
+}
+
+// rates validates the upload and returns the rates to store.
+func (req ExchangeRatesRequest) rates() ([]repository.ExchangeRate, error) {
+	seen := make(map[string]bool, len(req.Rates))
+	rates := make([]repository.ExchangeRate, 0, len(req.Rates))
+	for _, r := range req.Rates {
+		if strings.TrimSpace(r.Currency) == "" {
+			return nil, errors.New("currency is required")
+		}
+		currency, err := money.ParseCurrency(r.Currency)
+		if err != nil {
+			return nil, err
+		}
+		if seen[currency] {
+			return nil, fmt.Errorf("%s is listed more than once", currency)
+		}
+		seen[currency] = true
+		rate := repository.ExchangeRate{Currency: currency, Rate: r.Rate, RoundTo: r.RoundTo}
+		if err := rate.Validate(); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+// exchangeRates is the exchange-rate table in the form prices are converted with.
+type exchangeRates struct {
+	rates    money.Rates
+	rounding map[string]money.Amount
+}
+
+// loadExchangeRates reads the exchange-rate table. Without a repository there are no rates, so only
+// prices set by hand for a currency can be shown in it.
+func loadExchangeRates(repo repository.ExchangeRateRepository) (exchangeRates, error) {
+	loaded := exchangeRates{rates: money.Rates{}, rounding: map[string]money.Amount{}}
+	if repo == nil {
+		return loaded, nil
+	}
+	rates, err := repo.List()
+	if err != nil {
+		return exchangeRates{}, err
+	}
+	for _, rate := range rates {
+		loaded.rates[rate.Currency] = rate.Rate
+		loaded.rounding[rate.Currency] = rate.Rounding()
+	}
+	return loaded, nil
+}
+
+// price returns what the album costs in currency: the price staff have set for that currency if
+// there is one, otherwise the list price converted and rounded to the currency's rounding step.
+func (r exchangeRates) price(album repository.Album, currency string) (money.Amount, error) {
+	if price, ok := album.CurrencyPrices[currency]; ok {
+		return price, nil
+	}
+	converted, err := r.rates.Convert(album.Price, album.Currency, currency)
+	if err != nil {
+		return 0, err
+	}
+	return converted.RoundTo(r.roundingFor(currency)), nil
+}
+
+// roundingFor returns the step prices in currency are rounded to.
+func (r exchangeRates) roundingFor(currency string) money.Amount {
+	if rounding, ok := r.rounding[currency]; ok {
+		return rounding
+	}
+	return money.DefaultRounding(currency)
+}
+
+// requestedCurrency returns the currency the caller asked for with ?currency= or the Accept-Currency
+// header, or "" if they did not ask for one.
+func requestedCurrency(c *gin.Context) (string, error) {
+	currency := c.Query("currency")
+	if currency == "" && c.Request != nil {
+		currency = c.GetHeader("Accept-Currency")
+	}
+	if strings.TrimSpace(currency) == "" {
+		return "", nil
+	}
+	return money.ParseCurrency(currency)
+}
+
+// localise shows the albums' prices in currency. Sale prices keep the same proportion of the list
+// price they have in the album's own currency.
+func localise(albums []repository.Album, rates exchangeRates, currency string) error {
+	for i := range albums {
+		album := &albums[i]
+		if album.Currency == currency {
+			continue
+		}
+		price, err := rates.price(*album, currency)
+		if err != nil {
+			return err
+		}
+		if album.SalePrice != nil {
+			sale := price
+			if album.Price > 0 {
+				sale = money.FromMinor(int64(math.Round(float64(price) * float64(*album.SalePrice) / float64(album.Price))))
+				sale = sale.RoundTo(rates.roundingFor(currency))
+			}
+			album.SalePrice = &sale
+		}
+		album.Price = price
+		album.Currency = currency
+	}
+	return nil
+}
+
+// GetExchangeRates handles GET /exchange-rates, listing the currencies prices can be shown in.
+func (h *ExchangeRateHandler) GetExchangeRates(c *gin.Context) {
+	rates, err := h.Repo.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if rates == nil {
+		rates = []repository.ExchangeRate{}
+	}
+	c.IndentedJSON(http.StatusOK, gin.H{"base": money.DefaultCurrency, "rates": rates})
+}
+
+// PutExchangeRates handles PUT /admin/exchange-rates, replacing the exchange-rate table with the
+// uploaded one. Currencies left out can no longer be converted to.
+func (h *ExchangeRateHandler) PutExchangeRates(c *gin.Context) {
+	var req ExchangeRatesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rates, err := req.rates()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	stored, err := h.Repo.Replace(rates)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if stored == nil {
+		stored = []repository.ExchangeRate{}
+	}
+	c.IndentedJSON(http.StatusOK, gin.H{"base": money.DefaultCurrency, "rates": stored})
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+func setupExchangeRateRouter() (*gin.Engine, *AlbumHandler) {
+	rates := &mockExchangeRateRepo{}
+	_, _ = rates.Replace([]repository.ExchangeRate{
+		{Currency: "EUR", Rate: 1.17},
+		{Currency: "USD", Rate: 1.27},
+		{Currency: "CHF", Rate: 1.13},
+	})
+	albums := newTestHandler()
+	albums.ExchangeRates = rates
+	handler := NewExchangeRateHandler(rates)
+
+	r := gin.Default()
+	r.GET("/albums", albums.GetAlbums)
+	r.GET("/albums/:id", albums.GetAlbumByID)
+	r.PUT("/albums/:id", albums.PutAlbum)
+	r.GET("/exchange-rates", handler.GetExchangeRates)
+	r.PUT("/admin/exchange-rates", handler.PutExchangeRates)
+	return r, albums
+}
+
+func getAlbumIn(t *testing.T, r *gin.Engine, path, acceptCurrency string) repository.Album {
+	t.Helper()
+	req := httptest.NewRequest("GET", path, nil)
+	if acceptCurrency != "" {
+		req.Header.Set("Accept-Currency", acceptCurrency)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var album repository.Album
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &album))
+	return album
+}
+
+func Test_GetAlbum_ConvertedWithExchangeRate(t *testing.T) {
+	r, _ := setupExchangeRateRouter()
+
+	album := getAlbumIn(t, r, "/albums/2?currency=eur", "")
+
+	assert.Equal(t, "EUR", album.Currency)
+	assert.Equal(t, money.MustParse("49.73"), album.Price, "42.50 GBP at 1.17, to the nearest cent")
+}
+
+func Test_GetAlbum_AcceptCurrencyHeaderAndRounding(t *testing.T) {
+	r, _ := setupExchangeRateRouter()
+
+	album := getAlbumIn(t, r, "/albums/2", "CHF")
+
+	assert.Equal(t, "CHF", album.Currency)
+	assert.Equal(t, money.MustParse("48.05"), album.Price, "48.025 francs rounds to the nearest 0.05")
+}
+
+func Test_GetAlbum_CurrencyPriceBeatsConversion(t *testing.T) {
+	r, _ := setupExchangeRateRouter()
+	w := doRequest(r, "PUT", "/albums/2", "staff-1",
+		`{"title":"Songs in the Key of Life","artist":"Stevie Wonder","price":42.50,"currencyPrices":{"usd":49.99},"year":1976,"imageUrl":"https://example.com/songs.jpg","genre":"Motown"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	assert.Equal(t, money.MustParse("49.99"), getAlbumIn(t, r, "/albums/2", "USD").Price)
+	assert.Equal(t, money.MustParse("49.73"), getAlbumIn(t, r, "/albums/2", "EUR").Price)
+	assert.Equal(t, money.MustParse("42.50"), getAlbumIn(t, r, "/albums/2", "").Price)
+}
+
+func Test_GetAlbums_ConvertsSalePrices(t *testing.T) {
+	r, albums := setupExchangeRateRouter()
+	albums.Promotions = newMockPromotionRepo(motownWeekend)
+
+	album := getAlbumIn(t, r, "/albums/2", "EUR")
+
+	require.NotNil(t, album.SalePrice)
+	assert.Equal(t, money.MustParse("39.78"), *album.SalePrice, "the sale price stays 20% off in euros")
+}
+
+func Test_GetAlbums_UnavailableCurrency(t *testing.T) {
+	r, _ := setupExchangeRateRouter()
+
+	w := doRequest(r, "GET", "/albums?currency=JPY", "", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "not available in JPY")
+
+	w = doRequest(r, "GET", "/albums?currency=euros", "", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_PutAlbum_InvalidCurrencyPrices(t *testing.T) {
+	r, _ := setupExchangeRateRouter()
+
+	for _, prices := range []string{`{"GBP":40}`, `{"EU":40}`, `{"EUR":-1}`} {
+		w := doRequest(r, "PUT", "/albums/2", "staff-1",
+			`{"title":"Songs in the Key of Life","artist":"Stevie Wonder","price":42.50,"currencyPrices":`+prices+`,"year":1976,"imageUrl":"https://example.com/songs.jpg","genre":"Motown"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code, prices)
+	}
+}
+
+func Test_PutExchangeRates_ReplacesTable(t *testing.T) {
+	r, _ := setupExchangeRateRouter()
+
+	w := doRequest(r, "PUT", "/admin/exchange-rates", "staff-1",
+		`{"rates":[{"currency":"usd","rate":1.25},{"currency":"JPY","rate":190.5}]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var listed struct {
+		Base  string                    `json:"base"`
+		Rates []repository.ExchangeRate `json:"rates"`
+	}
+	w = doRequest(r, "GET", "/exchange-rates", "", "")
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	assert.Equal(t, money.DefaultCurrency, listed.Base)
+	require.Len(t, listed.Rates, 2)
+	assert.Equal(t, "JPY", listed.Rates[0].Currency)
+	assert.Equal(t, "USD", listed.Rates[1].Currency)
+
+	assert.Equal(t, http.StatusBadRequest, doRequest(r, "GET", "/albums/2?currency=EUR", "", "").Code, "EUR was left out of the upload")
+	assert.Equal(t, money.MustParse("8096.00"), getAlbumIn(t, r, "/albums/2", "JPY").Price, "yen are rounded to whole units")
+}
+
+func Test_PutExchangeRates_Validation(t *testing.T) {
+	r, _ := setupExchangeRateRouter()
+
+	for _, body := range []string{
+		`{}`,
+		`{"rates":[{"currency":"GBP","rate":1}]}`,
+		`{"rates":[{"currency":"EUR","rate":0}]}`,
+		`{"rates":[{"currency":"EUR","rate":1.1},{"currency":"eur","rate":1.2}]}`,
+		`{"rates":[{"currency":"EURO","rate":1.1}]}`,
+		`{"rates":[{"rate":1.1}]}`,
+	} {
+		w := doRequest(r, "PUT", "/admin/exchange-rates", "staff-1", body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"sort"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// In-memory mock implementation of ExchangeRateRepository for testing
+
+type mockExchangeRateRepo struct {
+	rates []repository.ExchangeRate
+}
+
+func (m *mockExchangeRateRepo) List() ([]repository.ExchangeRate, error) {
+	return append([]repository.ExchangeRate(nil), m.rates...), nil
+}
+
+func (m *mockExchangeRateRepo) Replace(rates []repository.ExchangeRate) ([]repository.ExchangeRate, error) {
+	m.rates = nil
+	for _, rate := range rates {
+		rate.UpdatedAt = time.Now()
+		m.rates = append(m.rates, rate)
+	}
+	sort.Slice(m.rates, func(i, j int) bool { return m.rates[i].Currency < m.rates[j].Currency })
+	return m.List()
+}
@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+type GenreHandler struct {
+	Repo   repository.GenreRepository
+	Albums repository.AlbumRepository
+}
+
+func NewGenreHandler(repo repository.GenreRepository, albums repository.AlbumRepository) *GenreHandler {
+	return &GenreHandler{Repo: repo, Albums: albums}
+}
+
+// GenreRequest is the body accepted by POST /genres and PUT /genres/:id. A genre without a
+// parentId is at the top of the tree.
+type GenreRequest struct {
+	Name     string   `json:"name" binding:"required"`
+	ParentID string   `json:"parentId"`
+	Aliases  []string `json:"aliases"`
+}
+
+// GenreNode is a genre with its sub-genres, as listed by GET /genres.
+type GenreNode struct {
+	repository.Genre
+	Children []GenreNode `json:"children"`
+}
+
+// GenreDetail is a genre as shown by GET /genres/:id: with the genres above it, starting from the
+// top of the tree, and the genres directly below it.
+type GenreDetail struct {
+	repository.Genre
+	Path     []repository.Genre `json:"path"`
+	Children []repository.Genre `json:"children"`
+}
+
+// loadGenres returns the genre taxonomy, or nil if genres are not configured.
+func loadGenres(repo repository.GenreRepository) (*repository.GenreTree, error) {
+	if repo == nil {
+		return nil, nil
+	}
+	genres, err := repo.List()
+	if err != nil {
+		return nil, err
+	}
+	return repository.NewGenreTree(genres), nil
+}
+
+func respondWithGenreError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrGenreNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "genre not found"})
+	case errors.Is(err, repository.ErrDuplicateGenre), errors.Is(err, repository.ErrGenreInUse),
+		errors.Is(err, repository.ErrGenreCycle):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// bindGenre reads and validates a genre from the request body, checking its parent against the
+// taxonomy. It writes an error response and returns false if the body is not a valid genre.
+func bindGenre(c *gin.Context, tree *repository.GenreTree, id string) (repository.Genre, bool) {
+	var req GenreRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return repository.Genre{}, false
+	}
+	genre := repository.Genre{ID: id, Name: req.Name, ParentID: req.ParentID, Aliases: req.Aliases}
+	if err := genre.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return repository.Genre{}, false
+	}
+	genre.Normalize()
+	if err := tree.CheckParent(genre.ID, genre.ParentID); errors.Is(err, repository.ErrGenreCycle) {
+		respondWithGenreError(c, err)
+		return repository.Genre{}, false
+	} else if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return repository.Genre{}, false
+	}
+	return genre, true
+}
+
+// GetGenres handles GET /genres, returning the whole taxonomy as a tree ordered by name.
+func (h *GenreHandler) GetGenres(c *gin.Context) {
+	tree, err := loadGenres(h.Repo)
+	if err != nil {
+		respondWithGenreError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, genreNodes(tree, tree.Roots()))
+}
+
+func genreNodes(tree *repository.GenreTree, genres []repository.Genre) []GenreNode {
+	nodes := []GenreNode{}
+	for _, genre := range genres {
+		nodes = append(nodes, GenreNode{Genre: genre, Children: genreNodes(tree, tree.Children(genre.ID))})
+	}
+	return nodes
+}
+
+// GetGenre handles GET /genres/:id, for browsing the taxonomy one genre at a time.
+func (h *GenreHandler) GetGenre(c *gin.Context) {
+	tree, err := loadGenres(h.Repo)
+	if err != nil {
+		respondWithGenreError(c, err)
+		return
+	}
+	genre, ok := tree.Get(c.Param("id"))
+	if !ok {
+		respondWithGenreError(c, repository.ErrGenreNotFound)
+		return
+	}
+	path := tree.Path(genre.ID)
+	c.IndentedJSON(http.StatusOK, GenreDetail{
+		Genre:    genre,
+		Path:     path[:len(path)-1],
+		Children: tree.Children(genre.ID),
+	})
+}
+
+// PostGenre handles POST /genres. The new genre's ID is made from its name, and neither may
+// already be used by another genre.
+func (h *GenreHandler) PostGenre(c *gin.Context) {
+	tree, err := loadGenres(h.Repo)
+	if err != nil {
+		respondWithGenreError(c, err)
+		return
+	}
+	genre, ok := bindGenre(c, tree, "")
+	if !ok {
+		return
+	}
+	created, err := h.Repo.Create(genre)
+	if err != nil {
+		respondWithGenreError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusCreated, created)
+}
+
+// PutGenre handles PUT /genres/:id, replacing the genre's name, parent and aliases. A genre cannot
+// be moved under one of its own sub-genres. Albums filed under a name the genre no longer goes by
+// are given its current name.
+func (h *GenreHandler) PutGenre(c *gin.Context) {
+	tree, err := loadGenres(h.Repo)
+	if err != nil {
+		respondWithGenreError(c, err)
+		return
+	}
+	id := c.Param("id")
+	if _, ok := tree.Get(id); !ok {
+		respondWithGenreError(c, repository.ErrGenreNotFound)
+		return
+	}
+	genre, ok := bindGenre(c, tree, id)
+	if !ok {
+		return
+	}
+	updated, err := h.Repo.Update(genre)
+	if err != nil {
+		respondWithGenreError(c, err)
+		return
+	}
+	if err := h.renameAlbums(tree, updated); err != nil {
+		// The genre has already been updated; albums showing an old name can be fixed by saving the genre again.
+		log.Printf("PutGenre: failed to rename albums of genre %s: %v", updated.ID, err)
+	}
+	c.IndentedJSON(http.StatusOK, updated)
+}
+
+// renameAlbums gives the genre's current name to the albums filed under any name it went by before.
+func (h *GenreHandler) renameAlbums(before *repository.GenreTree, genre repository.Genre) error {
+	albums, err := h.Albums.GetAll()
+	if err != nil {
+		return err
+	}
+	for _, album := range albums {
+		current, ok := before.Lookup(album.Genre)
+		if !ok || current.ID != genre.ID || album.Genre == genre.Name {
+			continue
+		}
+		album.Genre = genre.Name
+		if err := h.Albums.Update(album); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteGenre handles DELETE /genres/:id. Genres that still have sub-genres or albums cannot be deleted.
+func (h *GenreHandler) DeleteGenre(c *gin.Context) {
+	tree, err := loadGenres(h.Repo)
+	if err != nil {
+		respondWithGenreError(c, err)
+		return
+	}
+	id := c.Param("id")
+	if _, ok := tree.Get(id); !ok {
+		respondWithGenreError(c, repository.ErrGenreNotFound)
+		return
+	}
+	if len(tree.Children(id)) > 0 {
+		respondWithGenreError(c, repository.ErrGenreInUse)
+		return
+	}
+	albums, err := h.Albums.GetAll()
+	if err != nil {
+		respondWithGenreError(c, err)
+		return
+	}
+	for _, album := range albums {
+		if tree.Includes(id, album.Genre) {
+			respondWithGenreError(c, repository.ErrGenreInUse)
+			return
+		}
+	}
+	if err := h.Repo.Delete(id); err != nil {
+		respondWithGenreError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// setupGenreRouter serves the album and genre routes over the mock catalogue, whose albums are
+// filed under Pop and Motown.
+func setupGenreRouter(t *testing.T) (*gin.Engine, *AlbumHandler, *mockGenreRepo) {
+	t.Helper()
+	genres := newMockGenreRepo()
+	albums := newTestHandler()
+	albums.Genres = genres
+	handler := NewGenreHandler(genres, albums.Repo)
+	r := gin.Default()
+	r.GET("/albums", albums.GetAlbums)
+	r.POST("/albums", albums.PostAlbums)
+	r.PUT("/albums/:id", albums.PutAlbum)
+	r.GET("/api/search", albums.SearchAlbums)
+	r.GET("/genres", handler.GetGenres)
+	r.POST("/genres", handler.PostGenre)
+	r.GET("/genres/:id", handler.GetGenre)
+	r.PUT("/genres/:id", handler.PutGenre)
+	r.DELETE("/genres/:id", handler.DeleteGenre)
+	return r, albums, genres
+}
+
+func Test_GetGenres_Tree(t *testing.T) {
+	r, _, _ := setupGenreRouter(t)
+
+	w := doRequest(r, "GET", "/genres", "", "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var roots []GenreNode
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &roots))
+	require.Len(t, roots, 2)
+	assert.Equal(t, "Pop", roots[0].Name)
+	assert.Empty(t, roots[0].Children)
+	soul := roots[1]
+	assert.Equal(t, "soul", soul.ID)
+	require.Len(t, soul.Children, 1)
+	require.Len(t, soul.Children[0].Children, 1)
+	assert.Equal(t, "Northern Soul", soul.Children[0].Children[0].Name)
+}
+
+func Test_GetGenre_PathAndChildren(t *testing.T) {
+	r, _, _ := setupGenreRouter(t)
+
+	w := doRequest(r, "GET", "/genres/motown", "", "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var detail GenreDetail
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &detail))
+	assert.Equal(t, "Motown", detail.Name)
+	require.Len(t, detail.Path, 1)
+	assert.Equal(t, "soul", detail.Path[0].ID)
+	require.Len(t, detail.Children, 1)
+	assert.Equal(t, "northern-soul", detail.Children[0].ID)
+
+	w = doRequest(r, "GET", "/genres/missing", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_PostGenre(t *testing.T) {
+	r, _, _ := setupGenreRouter(t)
+
+	w := doRequest(r, "POST", "/genres", "staff-1", `{"name":" Philly  Soul ","parentId":"soul","aliases":["Philadelphia Soul"]}`)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	genre := decodeJSON[repository.Genre](t, w.Body.Bytes())
+	assert.Equal(t, "philly-soul", genre.ID)
+	assert.Equal(t, "Philly Soul", genre.Name)
+	assert.Equal(t, "soul", genre.ParentID)
+
+	w = doRequest(r, "POST", "/genres", "staff-1", `{"name":"R & B / Soul"}`)
+	assert.Equal(t, http.StatusConflict, w.Code, "names already used as an alias are taken")
+
+	w = doRequest(r, "POST", "/genres", "staff-1", `{"name":"Funk","parentId":"missing"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doRequest(r, "POST", "/genres", "staff-1", `{"name":"--"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_PutGenre_RejectsCycles(t *testing.T) {
+	r, _, _ := setupGenreRouter(t)
+
+	w := doRequest(r, "PUT", "/genres/soul", "staff-1", `{"name":"Soul","parentId":"northern-soul"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = doRequest(r, "PUT", "/genres/motown", "staff-1", `{"name":"Motown","parentId":"motown"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = doRequest(r, "PUT", "/genres/motown", "staff-1", `{"name":"Motown","parentId":"pop"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "pop", decodeJSON[repository.Genre](t, w.Body.Bytes()).ParentID)
+
+	w = doRequest(r, "PUT", "/genres/missing", "staff-1", `{"name":"Nothing"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_PutGenre_RenamesAlbums(t *testing.T) {
+	r, albums, _ := setupGenreRouter(t)
+
+	w := doRequest(r, "PUT", "/genres/motown", "staff-1", `{"name":"Tamla Motown","parentId":"soul","aliases":["Motown"]}`)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	album, _ := albums.Repo.GetByID("2")
+	assert.Equal(t, "Tamla Motown", album.Genre)
+	thriller, _ := albums.Repo.GetByID("1")
+	assert.Equal(t, "Pop", thriller.Genre)
+}
+
+func Test_DeleteGenre(t *testing.T) {
+	r, _, _ := setupGenreRouter(t)
+
+	w := doRequest(r, "DELETE", "/genres/soul", "staff-1", "")
+	assert.Equal(t, http.StatusConflict, w.Code, "genres with sub-genres are kept")
+
+	w = doRequest(r, "DELETE", "/genres/pop", "staff-1", "")
+	assert.Equal(t, http.StatusConflict, w.Code, "genres with albums are kept")
+
+	w = doRequest(r, "DELETE", "/genres/northern-soul", "staff-1", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = doRequest(r, "GET", "/genres/northern-soul", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_PostAlbums_GenreMustBeInTaxonomy(t *testing.T) {
+	r, _, _ := setupGenreRouter(t)
+
+	w := doRequest(r, "POST", "/albums", "staff-1", `{"title":"Diana","artist":"Diana Ross","price":9.99,"year":1980,"imageUrl":"x","genre":"r&b / soul"}`)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var album repository.Album
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &album))
+	assert.Equal(t, "Soul", album.Genre, "aliases are filed under the genre's name")
+
+	w = doRequest(r, "PUT", "/albums/1", "staff-1", `{"title":"Thriller","artist":"Michael Jackson","price":25.99,"year":1982,"imageUrl":"x","genre":"Polka"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `unknown genre \"Polka\"`)
+}
+
+func Test_GetAlbums_FilterByGenreIncludesSubGenres(t *testing.T) {
+	r, _, _ := setupGenreRouter(t)
+
+	for _, genre := range []string{"soul", "R%26B%2FSoul", "motown"} {
+		w := doRequest(r, "GET", "/albums?genre="+genre, "", "")
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var listed []repository.Album
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+		require.Len(t, listed, 1, genre)
+		assert.Equal(t, "Songs in the Key of Life", listed[0].Title, genre)
+	}
+
+	w := doRequest(r, "GET", "/albums?genre=northern-soul", "", "")
+	assert.JSONEq(t, `[]`, w.Body.String())
+
+	w = doRequest(r, "GET", "/albums?genre=polka", "", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_GetAlbums_FilterByGenreNeedsTaxonomy(t *testing.T) {
+	r := setupRouter(newTestHandler())
+
+	w := doRequest(r, "GET", "/albums?genre=soul", "", "")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_SearchAlbums_UsesTaxonomyNames(t *testing.T) {
+	r, _, genres := setupGenreRouter(t)
+	_, err := genres.Update(repository.Genre{ID: "pop", Name: "Pop Music", Aliases: repository.Aliases{"Pop"}})
+	require.NoError(t, err)
+
+	w := doRequest(r, "GET", "/api/search?term=thriller", "", "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var results []repository.AlbumResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &results))
+	require.NotEmpty(t, results)
+	for _, result := range results {
+		assert.Equal(t, "Pop Music", result.Genre)
+	}
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// In-memory mock implementation of GenreRepository for testing
+
+type mockGenreRepo struct {
+	genres []repository.Genre
+}
+
+// newMockGenreRepo returns a repository holding a small taxonomy: Soul (also known as R&B/Soul)
+// with Motown and Northern Soul below it, and Pop.
+func newMockGenreRepo() *mockGenreRepo {
+	m := &mockGenreRepo{}
+	for _, genre := range []repository.Genre{
+		{Name: "Soul", Aliases: repository.Aliases{"R&B/Soul"}},
+		{Name: "Motown", ParentID: "soul"},
+		{Name: "Northern Soul", ParentID: "motown"},
+		{Name: "Pop"},
+	} {
+		if _, err := m.Create(genre); err != nil {
+			panic(err)
+		}
+	}
+	return m
+}
+
+// nameTaken reports whether a genre other than id goes by any of the genre's names.
+func (m *mockGenreRepo) nameTaken(genre repository.Genre, id string) bool {
+	tree := repository.NewGenreTree(m.genres)
+	for _, name := range append([]string{genre.Name}, genre.Aliases...) {
+		if existing, ok := tree.Lookup(name); ok && existing.ID != id {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *mockGenreRepo) Create(genre repository.Genre) (repository.Genre, error) {
+	genre.Normalize()
+	if _, err := m.GetByID(genre.ID); err == nil || m.nameTaken(genre, "") {
+		return repository.Genre{}, repository.ErrDuplicateGenre
+	}
+	genre.CreatedAt = time.Now()
+	genre.UpdatedAt = genre.CreatedAt
+	m.genres = append(m.genres, genre)
+	return genre, nil
+}
+
+func (m *mockGenreRepo) GetByID(id string) (repository.Genre, error) {
+	for _, genre := range m.genres {
+		if genre.ID == id {
+			return genre, nil
+		}
+	}
+	return repository.Genre{}, repository.ErrGenreNotFound
+}
+
+func (m *mockGenreRepo) List() ([]repository.Genre, error) {
+	genres := append([]repository.Genre(nil), m.genres...)
+	sort.SliceStable(genres, func(i, j int) bool {
+		return strings.ToLower(genres[i].Name) < strings.ToLower(genres[j].Name)
+	})
+	return genres, nil
+}
+
+func (m *mockGenreRepo) Update(genre repository.Genre) (repository.Genre, error) {
+	genre.Normalize()
+	for i, existing := range m.genres {
+		if existing.ID != genre.ID {
+			continue
+		}
+		if m.nameTaken(genre, genre.ID) {
+			return repository.Genre{}, repository.ErrDuplicateGenre
+		}
+		genre.CreatedAt = existing.CreatedAt
+		genre.UpdatedAt = time.Now()
+		m.genres[i] = genre
+		return genre, nil
+	}
+	return repository.Genre{}, repository.ErrGenreNotFound
+}
+
+func (m *mockGenreRepo) Delete(id string) error {
+	for i, genre := range m.genres {
+		if genre.ID == id {
+			m.genres = append(m.genres[:i], m.genres[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrGenreNotFound
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// giftCardAlphabet leaves out letters and digits that are easily confused when read off a card
+// (0/O, 1/I/L). Its 31 symbols give a 16-character code over 79 bits of randomness.
+const giftCardAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+const giftCardCodeLength = 16
+
+type GiftCardHandler struct {
+	Repo repository.GiftCardRepository
+}
+
+func NewGiftCardHandler(repo repository.GiftCardRepository) *GiftCardHandler {
+	return &GiftCardHandler{Repo: repo}
+}
+
+// IssueGiftCardRequest is the body accepted by POST /admin/gift-cards.
+type IssueGiftCardRequest struct {
+	Amount money.Amount `json:"amount"`
+	Note   string       `json:"note"`
+}
+
+// AdjustGiftCardRequest is the body accepted by POST /admin/gift-cards/:id/adjust. Amount is added
+// to the balance, so a negative amount takes money off. Staff must say why.
+type AdjustGiftCardRequest struct {
+	Amount money.Amount `json:"amount"`
+	Note   string       `json:"note" binding:"required"`
+}
+
+// GiftCardBalanceRequest is the body accepted by POST /gift-cards/balance.
+type GiftCardBalanceRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// IssuedGiftCard is a newly issued gift card with its code, which is shown only this once.
+type IssuedGiftCard struct {
+	repository.GiftCard
+	Code string `json:"code"`
+}
+
+// GiftCardBalance is what customers see when they check a gift card.
+type GiftCardBalance struct {
+	Last4   string       `json:"last4"`
+	Balance money.Amount `json:"balance"`
+}
+
+// newGiftCardCode returns a random code formatted in groups of four, such as ABCD-2345-EFGH-6789.
+func newGiftCardCode() (string, error) {
+	var b strings.Builder
+	max := big.NewInt(int64(len(giftCardAlphabet)))
+	for i := 0; i < giftCardCodeLength; i++ {
+		if i > 0 && i%4 == 0 {
+			b.WriteByte('-')
+		}
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(giftCardAlphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+func respondWithGiftCardError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrGiftCardNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "gift card not found"})
+	case errors.Is(err, repository.ErrInsufficientBalance):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// withEntries loads the card's ledger, writing an error response and returning false on failure.
+func (h *GiftCardHandler) withEntries(c *gin.Context, card *repository.GiftCard) bool {
+	entries, err := h.Repo.Entries(card.ID)
+	if err != nil {
+		respondWithGiftCardError(c, err)
+		return false
+	}
+	if entries == nil {
+		entries = []repository.GiftCardEntry{}
+	}
+	card.Entries = entries
+	return true
+}
+
+// IssueGiftCard handles POST /admin/gift-cards, issuing a gift card worth amount. The response holds
+// the card's code, which cannot be looked up again.
+func (h *GiftCardHandler) IssueGiftCard(c *gin.Context) {
+	var req IssueGiftCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Amount <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "amount must be positive"})
+		return
+	}
+	for {
+		code, err := newGiftCardCode()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		card, err := h.Repo.Create(code, req.Amount, currentUserID(c), strings.TrimSpace(req.Note))
+		if errors.Is(err, repository.ErrDuplicateGiftCardCode) {
+			continue
+		}
+		if err != nil {
+			respondWithGiftCardError(c, err)
+			return
+		}
+		c.IndentedJSON(http.StatusCreated, IssuedGiftCard{GiftCard: card, Code: code})
+		return
+	}
+}
+
+// ListGiftCards handles GET /admin/gift-cards, optionally filtered with ?kind=gift-card or ?kind=store-credit.
+func (h *GiftCardHandler) ListGiftCards(c *gin.Context) {
+	kind := repository.GiftCardKind(c.Query("kind"))
+	if kind != "" && kind != repository.GiftCardVoucher && kind != repository.StoreCredit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be gift-card or store-credit"})
+		return
+	}
+	cards, err := h.Repo.List(kind)
+	if err != nil {
+		respondWithGiftCardError(c, err)
+		return
+	}
+	if cards == nil {
+		cards = []repository.GiftCard{}
+	}
+	c.IndentedJSON(http.StatusOK, cards)
+}
+
+// GetGiftCard handles GET /admin/gift-cards/:id, showing the card with its ledger.
+func (h *GiftCardHandler) GetGiftCard(c *gin.Context) {
+	card, err := h.Repo.GetByID(c.Param("id"))
+	if err != nil {
+		respondWithGiftCardError(c, err)
+		return
+	}
+	if h.withEntries(c, &card) {
+		c.IndentedJSON(http.StatusOK, card)
+	}
+}
+
+// AdjustGiftCard handles POST /admin/gift-cards/:id/adjust, correcting a balance by hand.
+func (h *GiftCardHandler) AdjustGiftCard(c *gin.Context) {
+	var req AdjustGiftCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Amount == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "amount must not be zero"})
+		return
+	}
+	card, err := h.Repo.Apply(c.Param("id"), repository.GiftCardEntry{
+		Amount: req.Amount,
+		Reason: repository.EntryAdjusted,
+		Actor:  currentUserID(c),
+		Note:   strings.TrimSpace(req.Note),
+	})
+	if err != nil {
+		respondWithGiftCardError(c, err)
+		return
+	}
+	if h.withEntries(c, &card) {
+		c.IndentedJSON(http.StatusOK, card)
+	}
+}
+
+// CheckGiftCardBalance handles POST /gift-cards/balance. The code is sent in the body rather than
+// the URL so it does not end up in access logs.
+func (h *GiftCardHandler) CheckGiftCardBalance(c *gin.Context) {
+	var req GiftCardBalanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	card, err := h.Repo.GetByCode(req.Code)
+	if err != nil {
+		respondWithGiftCardError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, GiftCardBalance{Last4: card.Last4, Balance: card.Balance})
+}
+
+// GetMyStoreCredit handles GET /store-credit, showing the signed-in user's store credit and how it
+// was earned and spent. Customers who have never had any see a zero balance.
+func (h *GiftCardHandler) GetMyStoreCredit(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	card, err := h.Repo.GetStoreCredit(userID)
+	if errors.Is(err, repository.ErrGiftCardNotFound) {
+		c.IndentedJSON(http.StatusOK, repository.GiftCard{Kind: repository.StoreCredit, UserID: userID, Entries: []repository.GiftCardEntry{}})
+		return
+	}
+	if err != nil {
+		respondWithGiftCardError(c, err)
+		return
+	}
+	if h.withEntries(c, &card) {
+		c.IndentedJSON(http.StatusOK, card)
+	}
+}
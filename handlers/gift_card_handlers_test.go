@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+func setupGiftCardRouter(cards *mockGiftCardRepo) *gin.Engine {
+	handler := NewGiftCardHandler(cards)
+	r := gin.Default()
+	r.POST("/gift-cards/balance", handler.CheckGiftCardBalance)
+	r.GET("/store-credit", handler.GetMyStoreCredit)
+	r.GET("/admin/gift-cards", handler.ListGiftCards)
+	r.POST("/admin/gift-cards", handler.IssueGiftCard)
+	r.GET("/admin/gift-cards/:id", handler.GetGiftCard)
+	r.POST("/admin/gift-cards/:id/adjust", handler.AdjustGiftCard)
+	return r
+}
+
+func Test_IssueGiftCard_ReturnsCodeOnce(t *testing.T) {
+	cards := newMockGiftCardRepo()
+	r := setupGiftCardRouter(cards)
+
+	w := doRequest(r, "POST", "/admin/gift-cards", "staff-1", `{"amount":"25.00","note":"Competition prize"}`)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var issued IssuedGiftCard
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &issued))
+	assert.Regexp(t, regexp.MustCompile(`^[A-Z2-9]{4}-[A-Z2-9]{4}-[A-Z2-9]{4}-[A-Z2-9]{4}$`), issued.Code)
+	assert.Equal(t, issued.Code[len(issued.Code)-4:], issued.Last4)
+	assert.Equal(t, money.MustParse("25.00"), issued.Balance)
+	assert.Equal(t, repository.GiftCardVoucher, issued.Kind)
+	assert.NotContains(t, w.Body.String(), "codeHash")
+
+	w = doRequest(r, "GET", "/admin/gift-cards/"+issued.ID, "staff-1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), issued.Code, "the code is only shown when the card is issued")
+	card := decodeJSON[repository.GiftCard](t, w.Body.Bytes())
+	require.Len(t, card.Entries, 1)
+	assert.Equal(t, repository.EntryIssued, card.Entries[0].Reason)
+	assert.Equal(t, "staff-1", card.Entries[0].Actor)
+	assert.Equal(t, "Competition prize", card.Entries[0].Note)
+}
+
+func Test_IssueGiftCard_RejectsNonPositiveAmounts(t *testing.T) {
+	r := setupGiftCardRouter(newMockGiftCardRepo())
+
+	for _, body := range []string{`{}`, `{"amount":"0"}`, `{"amount":"-5.00"}`} {
+		w := doRequest(r, "POST", "/admin/gift-cards", "staff-1", body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
+
+func Test_CheckGiftCardBalance(t *testing.T) {
+	cards := newMockGiftCardRepo()
+	r := setupGiftCardRouter(cards)
+	_, err := cards.Create("ABCD-EFGH-JKMN-PQRS", money.MustParse("40.00"), "staff-1", "")
+	require.NoError(t, err)
+
+	w := doRequest(r, "POST", "/gift-cards/balance", "", `{"code":"abcd-efgh-jkmn-pqrs"}`)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `{"last4":"PQRS","balance":40.00}`, w.Body.String())
+
+	w = doRequest(r, "POST", "/gift-cards/balance", "", `{"code":"ZZZZ-ZZZZ-ZZZZ-ZZZZ"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_AdjustGiftCard(t *testing.T) {
+	cards := newMockGiftCardRepo()
+	r := setupGiftCardRouter(cards)
+	card, _ := cards.Create("ABCD-EFGH-JKMN-PQRS", money.MustParse("10.00"), "staff-1", "")
+
+	w := doRequest(r, "POST", "/admin/gift-cards/"+card.ID+"/adjust", "staff-2", `{"amount":"-4.00","note":"Goodwill reversed"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	adjusted := decodeJSON[repository.GiftCard](t, w.Body.Bytes())
+	assert.Equal(t, money.MustParse("6.00"), adjusted.Balance)
+	require.Len(t, adjusted.Entries, 2)
+	assert.Equal(t, repository.EntryAdjusted, adjusted.Entries[1].Reason)
+	assert.Equal(t, money.MustParse("6.00"), adjusted.Entries[1].Balance)
+
+	w = doRequest(r, "POST", "/admin/gift-cards/"+card.ID+"/adjust", "staff-2", `{"amount":"-10.00","note":"Too much"}`)
+	assert.Equal(t, http.StatusConflict, w.Code, "a balance cannot go below zero")
+
+	w = doRequest(r, "POST", "/admin/gift-cards/"+card.ID+"/adjust", "staff-2", `{"amount":"1.00"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "adjustments need a note")
+
+	w = doRequest(r, "POST", "/admin/gift-cards/missing/adjust", "staff-2", `{"amount":"1.00","note":"x"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_ListGiftCards_FiltersByKind(t *testing.T) {
+	cards := newMockGiftCardRepo()
+	r := setupGiftCardRouter(cards)
+	cards.Create("ABCD-EFGH-JKMN-PQRS", money.MustParse("10.00"), "staff-1", "")
+	cards.AddStoreCredit("user-1", repository.GiftCardEntry{Amount: money.MustParse("5.00"), Reason: repository.EntryRefunded})
+
+	w := doRequest(r, "GET", "/admin/gift-cards?kind=store-credit", "staff-1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed []repository.GiftCard
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed, 1)
+	assert.Equal(t, "user-1", listed[0].UserID)
+
+	w = doRequest(r, "GET", "/admin/gift-cards", "staff-1", "")
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	assert.Len(t, listed, 2)
+
+	w = doRequest(r, "GET", "/admin/gift-cards?kind=voucher", "staff-1", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_GetMyStoreCredit(t *testing.T) {
+	cards := newMockGiftCardRepo()
+	r := setupGiftCardRouter(cards)
+
+	w := doRequest(r, "GET", "/store-credit", "user-1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, money.Amount(0), decodeJSON[repository.GiftCard](t, w.Body.Bytes()).Balance)
+
+	cards.AddStoreCredit("user-1", repository.GiftCardEntry{Amount: money.MustParse("12.50"), Reason: repository.EntryRefunded, Note: "return 7"})
+	w = doRequest(r, "GET", "/store-credit", "user-1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	credit := decodeJSON[repository.GiftCard](t, w.Body.Bytes())
+	assert.Equal(t, money.MustParse("12.50"), credit.Balance)
+	require.Len(t, credit.Entries, 1)
+	assert.Equal(t, "return 7", credit.Entries[0].Note)
+
+	w = doRequest(r, "GET", "/store-credit", "", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func Test_RequireStaff_GuardsMoneyRoutes(t *testing.T) {
+	cards := newMockGiftCardRepo()
+	handler := NewGiftCardHandler(cards)
+	r := gin.Default()
+	r.POST("/admin/gift-cards", RequireStaff("s3cret"), handler.IssueGiftCard)
+	issue := func(authorization string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/admin/gift-cards", strings.NewReader(`{"amount":"25.00"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-User-ID", "staff-1")
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, issue(""), "an X-User-ID header alone is not enough")
+	assert.Equal(t, http.StatusUnauthorized, issue("Bearer wrong"))
+	assert.Equal(t, http.StatusUnauthorized, issue("s3cret"))
+	cardsIssued, err := cards.List(repository.GiftCardVoucher)
+	require.NoError(t, err)
+	assert.Empty(t, cardsIssued)
+
+	assert.Equal(t, http.StatusCreated, issue("Bearer s3cret"))
+}
+
+func Test_RequireStaff_NoTokenConfigured(t *testing.T) {
+	r := gin.Default()
+	r.POST("/admin/gift-cards", RequireStaff(""), func(c *gin.Context) { c.Status(http.StatusCreated) })
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/admin/gift-cards", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "an empty token never matches")
+}
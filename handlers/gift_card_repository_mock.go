@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// In-memory mock implementation of GiftCardRepository for testing
+
+type mockGiftCardRepo struct {
+	cards   []repository.GiftCard
+	entries map[string][]repository.GiftCardEntry
+}
+
+func newMockGiftCardRepo() *mockGiftCardRepo {
+	return &mockGiftCardRepo{entries: make(map[string][]repository.GiftCardEntry)}
+}
+
+func (m *mockGiftCardRepo) open(card repository.GiftCard, entry repository.GiftCardEntry) repository.GiftCard {
+	card.ID = fmt.Sprintf("card-%d", len(m.cards)+1)
+	card.InitialBalance = entry.Amount
+	card.Balance = entry.Amount
+	card.CreatedAt = time.Now()
+	card.UpdatedAt = card.CreatedAt
+	m.cards = append(m.cards, card)
+	m.record(card, entry)
+	return card
+}
+
+func (m *mockGiftCardRepo) record(card repository.GiftCard, entry repository.GiftCardEntry) {
+	entry.ID = fmt.Sprintf("entry-%d", len(m.entries[card.ID])+1)
+	entry.GiftCardID = card.ID
+	entry.Balance = card.Balance
+	entry.CreatedAt = time.Now()
+	m.entries[card.ID] = append(m.entries[card.ID], entry)
+}
+
+func (m *mockGiftCardRepo) Create(code string, initialBalance money.Amount, actor, note string) (repository.GiftCard, error) {
+	hash := repository.HashGiftCardCode(code)
+	for _, card := range m.cards {
+		if card.CodeHash == hash {
+			return repository.GiftCard{}, repository.ErrDuplicateGiftCardCode
+		}
+	}
+	normalized := repository.NormalizeGiftCardCode(code)
+	card := repository.GiftCard{Kind: repository.GiftCardVoucher, CodeHash: hash, Last4: normalized[len(normalized)-4:]}
+	return m.open(card, repository.GiftCardEntry{Amount: initialBalance, Reason: repository.EntryIssued, Actor: actor, Note: note}), nil
+}
+
+func (m *mockGiftCardRepo) find(match func(repository.GiftCard) bool) (repository.GiftCard, error) {
+	for _, card := range m.cards {
+		if match(card) {
+			return card, nil
+		}
+	}
+	return repository.GiftCard{}, repository.ErrGiftCardNotFound
+}
+
+func (m *mockGiftCardRepo) GetByID(id string) (repository.GiftCard, error) {
+	return m.find(func(card repository.GiftCard) bool { return card.ID == id })
+}
+
+func (m *mockGiftCardRepo) GetByCode(code string) (repository.GiftCard, error) {
+	hash := repository.HashGiftCardCode(code)
+	return m.find(func(card repository.GiftCard) bool { return card.CodeHash == hash })
+}
+
+func (m *mockGiftCardRepo) GetStoreCredit(userID string) (repository.GiftCard, error) {
+	return m.find(func(card repository.GiftCard) bool {
+		return card.Kind == repository.StoreCredit && card.UserID == userID
+	})
+}
+
+func (m *mockGiftCardRepo) List(kind repository.GiftCardKind) ([]repository.GiftCard, error) {
+	var cards []repository.GiftCard
+	for _, card := range m.cards {
+		if kind == "" || card.Kind == kind {
+			cards = append(cards, card)
+		}
+	}
+	return cards, nil
+}
+
+func (m *mockGiftCardRepo) Apply(id string, entry repository.GiftCardEntry) (repository.GiftCard, error) {
+	for i, card := range m.cards {
+		if card.ID != id {
+			continue
+		}
+		if card.Balance+entry.Amount < 0 {
+			return repository.GiftCard{}, repository.ErrInsufficientBalance
+		}
+		card.Balance += entry.Amount
+		card.UpdatedAt = time.Now()
+		m.cards[i] = card
+		m.record(card, entry)
+		return card, nil
+	}
+	return repository.GiftCard{}, repository.ErrGiftCardNotFound
+}
+
+func (m *mockGiftCardRepo) AddStoreCredit(userID string, entry repository.GiftCardEntry) (repository.GiftCard, error) {
+	if card, err := m.GetStoreCredit(userID); err == nil {
+		return m.Apply(card.ID, entry)
+	}
+	return m.open(repository.GiftCard{Kind: repository.StoreCredit, UserID: userID}, entry), nil
+}
+
+func (m *mockGiftCardRepo) Entries(id string) ([]repository.GiftCardEntry, error) {
+	return m.entries[id], nil
+}
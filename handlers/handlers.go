@@ -1,16 +1,73 @@
 package handlers
 
 import (
+	"crypto/subtle"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/promotions"
 	"github.com/tvergilio/motown-house-backend/repository"
+	"github.com/tvergilio/motown-house-backend/tax"
 )
 
 type AlbumHandler struct {
 	Repo       repository.AlbumRepository
 	ITunesRepo repository.ITunesRepository
+
+	// Inventory is optional; when set, album responses include stock levels.
+	Inventory repository.InventoryRepository
+
+	// Promotions is optional; when set, album responses include sale prices.
+	Promotions repository.PromotionRepository
+
+	// Prices is optional; when set, price changes made through PutAlbum are recorded in the price history.
+	Prices repository.PriceRepository
+
+	// ExchangeRates is optional; when set, album prices can be shown in other currencies by conversion.
+	ExchangeRates repository.ExchangeRateRepository
+
+	// TaxRates is optional; when set, album prices can be shown with or without tax for a destination.
+	// Tax says whether the stored prices include it.
+	TaxRates repository.TaxRateRepository
+	Tax      tax.Policy
+
+	// Reviews is optional; when set, album responses include the average rating and number of
+	// approved reviews, and GET /albums can be sorted by rating.
+	Reviews repository.ReviewRepository
+
+	// Artists is optional; when set, albums are linked to their artist when they are created or
+	// updated, and can be listed by artist.
+	Artists repository.ArtistRepository
+
+	// Labels is optional; when set, albums are linked to the label they were released on when they
+	// are created or updated, and can be listed by label or looked up by catalogue number.
+	Labels repository.LabelRepository
+
+	// Genres is optional; when set, album genres must be in the genre taxonomy, GET /albums can be
+	// filtered by genre including its sub-genres, and iTunes search results use the taxonomy's names.
+	Genres repository.GenreRepository
+
+	// Releases is optional; when set, albums are shown with their releases, and GET /albums can list
+	// each release as an album of its own.
+	Releases repository.ReleaseRepository
+
+	// UsedItems is optional; when set, album responses include the number of used copies for sale,
+	// and GET /albums can be filtered by the grade of those copies.
+	UsedItems repository.UsedItemRepository
+
+	// Collections is optional; when set, the albums in a collection can be listed.
+	Collections repository.CollectionRepository
+
+	// Revisions is optional; when set, every edit made through PutAlbum is kept as a revision that
+	// can be rolled back to.
+	Revisions repository.RevisionRepository
 }
 
 func NewAlbumHandler(repo repository.AlbumRepository, itunesRepo repository.ITunesRepository) *AlbumHandler {
@@ -21,14 +78,743 @@ func NewAlbumHandler(repo repository.AlbumRepository, itunesRepo repository.ITun
 }
 
 func (h *AlbumHandler) GetAlbums(c *gin.Context) {
-	albums, err := h.Repo.GetAll()
+	currency, err := requestedCurrency(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	display, err := requestedTaxDisplay(c, h.Tax)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sortBy := c.Query("sort")
+	if sortBy != "" && (sortBy != "rating" || h.Reviews == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("albums cannot be sorted by %q", sortBy)})
+		return
+	}
+	view := c.DefaultQuery("view", "grouped")
+	if view != "grouped" && (view != "flat" || h.Releases == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("albums cannot be shown in the %q view", view)})
+		return
+	}
+	var albums []repository.Album
+	if tag := c.Query("tag"); tag != "" {
+		albums, err = h.Repo.GetByTag(repository.NormalizeTag(tag))
+	} else {
+		albums, err = h.Repo.GetAll()
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if albums == nil {
+		albums = []repository.Album{}
+	}
+	albums, ok := h.filterByGenre(c, albums)
+	if !ok {
+		return
+	}
+	if albums, ok = h.filterByGrade(c, albums); !ok {
+		return
+	}
+	if albums, err = h.withReleases(albums, view == "flat"); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.decorate(albums); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if sortBy == "rating" {
+		sortByRating(albums)
+	}
+	if !h.showIn(c, albums, currency) || !h.showTax(c, albums, display) {
+		return
+	}
+	c.IndentedJSON(http.StatusOK, albums)
+}
+
+// GetArtistAlbums handles GET /artists/:id/albums, listing the artist's albums oldest first.
+func (h *AlbumHandler) GetArtistAlbums(c *gin.Context) {
+	artist, err := h.Artists.GetByID(c.Param("id"))
+	if errors.Is(err, repository.ErrArtistNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "artist not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	all, err := h.Repo.GetAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	albums := []repository.Album{}
+	for _, album := range all {
+		if album.ArtistID == artist.ID {
+			albums = append(albums, album)
+		}
+	}
+	sort.SliceStable(albums, func(i, j int) bool {
+		if albums[i].Year != albums[j].Year {
+			return albums[i].Year < albums[j].Year
+		}
+		return albums[i].Title < albums[j].Title
+	})
+	if !h.prepareAlbums(c, albums) {
+		return
+	}
+	c.IndentedJSON(http.StatusOK, albums)
+}
+
+// GetLabelAlbums handles GET /labels/:id/albums, listing the albums released on the label in
+// catalogue number order. Albums without a catalogue number come last, oldest first.
+func (h *AlbumHandler) GetLabelAlbums(c *gin.Context) {
+	label, ok := h.getLabel(c)
+	if !ok {
+		return
+	}
+	all, err := h.Repo.GetAll()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	albums := []repository.Album{}
+	for _, album := range all {
+		if album.LabelID == label.ID {
+			albums = append(albums, album)
+		}
+	}
+	sort.SliceStable(albums, func(i, j int) bool {
+		a, b := albums[i], albums[j]
+		if (a.CatalogueNumber == "") != (b.CatalogueNumber == "") {
+			return b.CatalogueNumber == ""
+		}
+		if a.CatalogueNumber != b.CatalogueNumber {
+			return a.CatalogueNumber < b.CatalogueNumber
+		}
+		return a.Year < b.Year
+	})
+	if !h.prepareAlbums(c, albums) {
+		return
+	}
 	c.IndentedJSON(http.StatusOK, albums)
 }
 
+// GetCollectionAlbums handles GET /collections/:id/albums, listing a curated collection's albums in
+// the order they were put in it, or the albums matching a smart collection's rules oldest first.
+func (h *AlbumHandler) GetCollectionAlbums(c *gin.Context) {
+	collection, err := h.Collections.GetByID(c.Param("id"))
+	if errors.Is(err, repository.ErrCollectionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "collection not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	all, err := h.Repo.GetAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	tree, err := loadGenres(h.Genres)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	albums := collection.Select(all, tree)
+	if !h.prepareAlbums(c, albums) {
+		return
+	}
+	c.IndentedJSON(http.StatusOK, albums)
+}
+
+// GetTags handles GET /tags, listing every tag in use with the number of albums that have it, most
+// used first.
+func (h *AlbumHandler) GetTags(c *gin.Context) {
+	counts, err := h.Repo.TagCounts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if counts == nil {
+		counts = []repository.TagCount{}
+	}
+	c.IndentedJSON(http.StatusOK, counts)
+}
+
+// GetAlbumByCatalogueNumber handles GET /labels/:id/catalogue/:number, finding the album the
+// label released under that catalogue number.
+func (h *AlbumHandler) GetAlbumByCatalogueNumber(c *gin.Context) {
+	label, ok := h.getLabel(c)
+	if !ok {
+		return
+	}
+	album, err := h.Repo.GetByCatalogueNumber(label.ID, repository.NormalizeCatalogueNumber(c.Param("number")))
+	h.showAlbum(c, album, err)
+}
+
+// GetAlbumByBarcode handles GET /albums/barcode/:barcode. Either the UPC or the EAN form of a
+// barcode finds the album. Albums and releases share one namespace of barcodes, so if releases are
+// configured a release's barcode finds its album, shown as that release.
+func (h *AlbumHandler) GetAlbumByBarcode(c *gin.Context) {
+	barcode, err := repository.NormalizeBarcode(c.Param("barcode"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	album, err := h.Repo.GetByBarcode(barcode)
+	if errors.Is(err, repository.ErrAlbumNotFound) && h.Releases != nil {
+		album, err = h.getReleaseAlbum(barcode)
+	}
+	h.showAlbum(c, album, err)
+}
+
+// getReleaseAlbum returns the album with the release that has the barcode, as that release.
+func (h *AlbumHandler) getReleaseAlbum(barcode string) (repository.Album, error) {
+	release, err := h.Releases.GetByBarcode(barcode)
+	if errors.Is(err, repository.ErrReleaseNotFound) {
+		return repository.Album{}, repository.ErrAlbumNotFound
+	}
+	if err != nil {
+		return repository.Album{}, err
+	}
+	album, err := h.Repo.GetByID(release.AlbumID)
+	if err != nil {
+		// The album is gone or in the trash, so its releases cannot be looked up either.
+		return repository.Album{}, repository.ErrAlbumNotFound
+	}
+	return album.ForRelease(release), nil
+}
+
+// getLabel returns the label named by the :id parameter. It writes an error response and returns
+// false if there is no such label.
+func (h *AlbumHandler) getLabel(c *gin.Context) (repository.Label, bool) {
+	label, err := h.Labels.GetByID(c.Param("id"))
+	if errors.Is(err, repository.ErrLabelNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "label not found"})
+		return repository.Label{}, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return repository.Label{}, false
+	}
+	return label, true
+}
+
+// showAlbum writes an album that was looked up, or the error looking it up.
+func (h *AlbumHandler) showAlbum(c *gin.Context, album repository.Album, err error) {
+	if errors.Is(err, repository.ErrAlbumNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "album not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	albums := []repository.Album{album}
+	if !h.prepareAlbums(c, albums) {
+		return
+	}
+	c.IndentedJSON(http.StatusOK, albums[0])
+}
+
+// prepareAlbums fills in the albums' stock, sale prices and ratings, and shows their prices in the
+// currency and with the tax the caller asked for. It writes an error response and returns false
+// if it cannot.
+func (h *AlbumHandler) prepareAlbums(c *gin.Context, albums []repository.Album) bool {
+	currency, err := requestedCurrency(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	display, err := requestedTaxDisplay(c, h.Tax)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	if err := h.decorate(albums); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	return h.showIn(c, albums, currency) && h.showTax(c, albums, display)
+}
+
+// linkArtist puts the album's artist name into its standard form and, if artists are configured,
+// links the album to its artist: the one given by artistId, or else the one that goes by the
+// album's artist name, which is created if there is none yet. The album takes the artist's name.
+// It writes an error response and returns false if the album cannot be linked.
+func (h *AlbumHandler) linkArtist(c *gin.Context, album *repository.Album) bool {
+	album.Artist = repository.NormalizeArtistName(album.Artist)
+	album.ArtistID = strings.TrimSpace(album.ArtistID)
+	if h.Artists == nil {
+		return true
+	}
+	var artist repository.Artist
+	var err error
+	switch {
+	case album.ArtistID != "":
+		artist, err = h.Artists.GetByID(album.ArtistID)
+		if errors.Is(err, repository.ErrArtistNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "artistId does not match an artist"})
+			return false
+		}
+	case album.Artist != "":
+		artist, err = repository.ResolveArtist(h.Artists, album.Artist)
+	default:
+		return true
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	album.ArtistID = artist.ID
+	album.Artist = artist.Name
+	return true
+}
+
+// linkLabel puts an album's or release's label name into its standard form and, if labels are
+// configured, links it to its label: the one given by labelId, or else the one that goes by the
+// label name, which is created if there is none yet. The label name becomes the label's. Anything
+// with a catalogue number must have a label. It writes an error response and returns false if the
+// label cannot be linked.
+func linkLabel(c *gin.Context, labels repository.LabelRepository, labelID, name *string, catalogueNumber string) bool {
+	*name = strings.Join(strings.Fields(*name), " ")
+	*labelID = strings.TrimSpace(*labelID)
+	if catalogueNumber != "" && *name == "" && *labelID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a catalogueNumber needs a label"})
+		return false
+	}
+	if labels == nil {
+		return true
+	}
+	var label repository.Label
+	var err error
+	switch {
+	case *labelID != "":
+		label, err = labels.GetByID(*labelID)
+		if errors.Is(err, repository.ErrLabelNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "labelId does not match a label"})
+			return false
+		}
+	case *name != "":
+		label, err = repository.ResolveLabel(labels, *name)
+	default:
+		return true
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	*labelID = label.ID
+	*name = label.Name
+	return true
+}
+
+// checkGenre files the album under a genre in the taxonomy, if genres are configured, giving it the
+// genre's name in place of an alias. It writes an error response and returns false if the album's
+// genre is not in the taxonomy.
+func (h *AlbumHandler) checkGenre(c *gin.Context, album *repository.Album) bool {
+	tree, err := loadGenres(h.Genres)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	if tree == nil {
+		return true
+	}
+	genre, ok := tree.Lookup(album.Genre)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown genre %q", album.Genre)})
+		return false
+	}
+	album.Genre = genre.Name
+	return true
+}
+
+// filterByGenre keeps the albums in the genre the caller asked for with ?genre=, given by ID or by
+// name, or in any of its sub-genres. It writes an error response and returns false if the genre
+// is not in the taxonomy.
+func (h *AlbumHandler) filterByGenre(c *gin.Context, albums []repository.Album) ([]repository.Album, bool) {
+	name := c.Query("genre")
+	if name == "" {
+		return albums, true
+	}
+	tree, err := loadGenres(h.Genres)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if tree == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "albums cannot be filtered by genre"})
+		return nil, false
+	}
+	genre, ok := tree.Get(name)
+	if !ok {
+		genre, ok = tree.Lookup(name)
+	}
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown genre %q", name)})
+		return nil, false
+	}
+	filtered := []repository.Album{}
+	for _, album := range albums {
+		if tree.Includes(genre.ID, album.Genre) {
+			filtered = append(filtered, album)
+		}
+	}
+	return filtered, true
+}
+
+// filterByGrade keeps the albums with a used copy for sale at the grades given by ?mediaGrade= and
+// ?sleeveGrade=, if either is. It writes an error response and returns false if they cannot be used.
+func (h *AlbumHandler) filterByGrade(c *gin.Context, albums []repository.Album) ([]repository.Album, bool) {
+	if c.Query("mediaGrade") == "" && c.Query("sleeveGrade") == "" {
+		return albums, true
+	}
+	if h.UsedItems == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "albums cannot be filtered by grade"})
+		return nil, false
+	}
+	filter, ok := usedItemFilter(c)
+	if !ok {
+		return nil, false
+	}
+	items, err := h.UsedItems.List(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	graded := make(map[string]bool, len(items))
+	for _, item := range items {
+		graded[item.AlbumID] = true
+	}
+	filtered := []repository.Album{}
+	for _, album := range albums {
+		if graded[album.ID] {
+			filtered = append(filtered, album)
+		}
+	}
+	return filtered, true
+}
+
+// showIn converts the albums' prices to the currency the caller asked for, if any. It writes an
+// error response and returns false if prices are not available in that currency.
+func (h *AlbumHandler) showIn(c *gin.Context, albums []repository.Album, currency string) bool {
+	c.Header("Vary", "Accept-Currency")
+	if currency == "" {
+		return true
+	}
+	rates, err := loadExchangeRates(h.ExchangeRates)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	if err := localise(albums, rates, currency); err != nil {
+		if errors.Is(err, money.ErrNoRate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "prices are not available in " + currency})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return false
+	}
+	return true
+}
+
+// showTax shows the albums' prices with or without tax for the destination the caller asked about,
+// if any. It writes an error response and returns false if the tax rates cannot be loaded.
+func (h *AlbumHandler) showTax(c *gin.Context, albums []repository.Album, display *taxDisplay) bool {
+	if display == nil {
+		return true
+	}
+	rates, err := loadTaxRates(h.TaxRates)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	for i := range albums {
+		display.apply(&albums[i], rates, h.Tax)
+	}
+	return true
+}
+
+// decorate fills in the fields of each album that come from other parts of the shop.
+func (h *AlbumHandler) decorate(albums []repository.Album) error {
+	if err := h.attachStock(albums); err != nil {
+		return err
+	}
+	if err := h.attachRatings(albums); err != nil {
+		return err
+	}
+	if err := h.attachUsedCounts(albums); err != nil {
+		return err
+	}
+	return h.attachSalePrices(albums)
+}
+
+// attachUsedCounts fills in the number of used copies of each album for sale, if used items are configured.
+func (h *AlbumHandler) attachUsedCounts(albums []repository.Album) error {
+	if h.UsedItems == nil || len(albums) == 0 {
+		return nil
+	}
+	filter := repository.UsedItemFilter{Status: repository.UsedItemAvailable}
+	if len(albums) == 1 {
+		filter.AlbumID = albums[0].ID
+	}
+	items, err := h.UsedItems.List(filter)
+	if err != nil {
+		return err
+	}
+	counts := make(map[string]int)
+	for _, item := range items {
+		counts[item.AlbumID]++
+	}
+	for i := range albums {
+		count := counts[albums[i].ID]
+		albums[i].UsedCount = &count
+	}
+	return nil
+}
+
+// attachRatings fills in the rating and review count of each album, if reviews are configured.
+func (h *AlbumHandler) attachRatings(albums []repository.Album) error {
+	if h.Reviews == nil || len(albums) == 0 {
+		return nil
+	}
+	ratings, err := h.Reviews.Ratings()
+	if err != nil {
+		return err
+	}
+	byAlbum := make(map[string]repository.RatingSummary, len(ratings))
+	for _, rating := range ratings {
+		byAlbum[rating.AlbumID] = rating
+	}
+	for i := range albums {
+		summary := byAlbum[albums[i].ID]
+		count := summary.Count
+		albums[i].ReviewCount = &count
+		if count > 0 {
+			average := summary.Average
+			albums[i].Rating = &average
+		}
+	}
+	return nil
+}
+
+// sortByRating orders albums from the highest rated down, breaking ties by the number of reviews.
+// Albums nobody has reviewed come last, in their original order.
+func sortByRating(albums []repository.Album) {
+	rating := func(album repository.Album) (float64, int) {
+		if album.Rating == nil {
+			return -1, 0
+		}
+		return *album.Rating, *album.ReviewCount
+	}
+	sort.SliceStable(albums, func(i, j int) bool {
+		ri, ci := rating(albums[i])
+		rj, cj := rating(albums[j])
+		if ri != rj {
+			return ri > rj
+		}
+		return ci > cj
+	})
+}
+
+// withReleases returns the albums with their releases, if releases are configured. Grouped, each
+// album lists its releases; flat, each release takes the album's place as an album of its own, and
+// albums without releases are listed once as they are.
+func (h *AlbumHandler) withReleases(albums []repository.Album, flat bool) ([]repository.Album, error) {
+	if h.Releases == nil || len(albums) == 0 {
+		return albums, nil
+	}
+	var releases []repository.Release
+	var err error
+	if len(albums) == 1 {
+		releases, err = h.Releases.ListByAlbum(albums[0].ID)
+	} else {
+		releases, err = h.Releases.List()
+	}
+	if err != nil {
+		return nil, err
+	}
+	byAlbum := make(map[string][]repository.Release)
+	for _, release := range releases {
+		byAlbum[release.AlbumID] = append(byAlbum[release.AlbumID], release)
+	}
+	if !flat {
+		for i := range albums {
+			albums[i].Releases = byAlbum[albums[i].ID]
+		}
+		return albums, nil
+	}
+	entries := make([]repository.Album, 0, len(albums))
+	for _, album := range albums {
+		if len(byAlbum[album.ID]) == 0 {
+			entries = append(entries, album)
+			continue
+		}
+		for _, release := range byAlbum[album.ID] {
+			entries = append(entries, album.ForRelease(release))
+		}
+	}
+	return entries, nil
+}
+
+// attachStock fills in the stock fields of each album from the inventory, if one is configured.
+// Releases share the stock of their album in their format, so an album listed as one of its
+// releases shows only that.
+func (h *AlbumHandler) attachStock(albums []repository.Album) error {
+	if h.Inventory == nil || len(albums) == 0 {
+		return nil
+	}
+	var levels []repository.StockLevel
+	var err error
+	if len(albums) == 1 {
+		levels, err = h.Inventory.GetStock(albums[0].ID)
+	} else {
+		levels, err = h.Inventory.GetAllStock()
+	}
+	if err != nil {
+		return err
+	}
+	byAlbum := make(map[string][]repository.StockLevel)
+	for _, level := range levels {
+		byAlbum[level.AlbumID] = append(byAlbum[level.AlbumID], level)
+	}
+	for i := range albums {
+		albums[i].Stock = completeStock(albums[i].ID, byAlbum[albums[i].ID])
+		if albums[i].Format != "" {
+			albums[i].Stock = stockInFormat(albums[i].Stock, albums[i].Format)
+		}
+		inStock := false
+		for _, level := range albums[i].Stock {
+			if level.Available > 0 {
+				inStock = true
+			}
+		}
+		albums[i].InStock = &inStock
+	}
+	return nil
+}
+
+// attachSalePrices fills in the sale price of each album that an automatic promotion applies to.
+func (h *AlbumHandler) attachSalePrices(albums []repository.Album) error {
+	active, err := loadPromotions(h.Promotions)
+	if err != nil || len(active) == 0 {
+		return err
+	}
+	genres, err := loadGenres(h.Genres)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for i := range albums {
+		price, promotion := promotions.SalePrice(active, genres, albums[i], now)
+		if promotion == nil {
+			continue
+		}
+		albums[i].SalePrice = &price
+		albums[i].Promotion = promotion.Name
+	}
+	return nil
+}
+
+// stockInFormat returns the stock levels for the format alone.
+func stockInFormat(levels []repository.StockLevel, format repository.Format) []repository.StockLevel {
+	for _, level := range levels {
+		if level.Format == format {
+			return []repository.StockLevel{level}
+		}
+	}
+	return []repository.StockLevel{}
+}
+
+// completeStock returns one stock level per known format, filling in zeroes for formats never stocked.
+func completeStock(albumID string, levels []repository.StockLevel) []repository.StockLevel {
+	complete := make([]repository.StockLevel, 0, len(repository.Formats))
+	for _, format := range repository.Formats {
+		level := repository.StockLevel{AlbumID: albumID, Format: format}
+		for _, l := range levels {
+			if l.Format == format {
+				level = l
+			}
+		}
+		complete = append(complete, level)
+	}
+	return complete
+}
+
+// normalizePricing validates an album's currency and the prices set for other currencies, putting
+// the currency codes and tax category into their standard form. Carts, orders and payments are in
+// money.DefaultCurrency, so an album's own price must be too; prices in other currencies are set
+// with currencyPrices or converted with the exchange rates.
+func normalizePricing(album *repository.Album) error {
+	album.TaxCategory = tax.NormalizeCategory(album.TaxCategory)
+	currency, err := money.ParseCurrency(album.Currency)
+	if err != nil {
+		return err
+	}
+	if currency != money.DefaultCurrency {
+		return fmt.Errorf("price must be in %s; set the %s price with currencyPrices", money.DefaultCurrency, currency)
+	}
+	album.Currency = currency
+	if len(album.CurrencyPrices) == 0 {
+		album.CurrencyPrices = nil
+		return nil
+	}
+	prices := make(repository.CurrencyPrices, len(album.CurrencyPrices))
+	for code, price := range album.CurrencyPrices {
+		if strings.TrimSpace(code) == "" {
+			return errors.New("currencyPrices keys must be currency codes")
+		}
+		code, err := money.ParseCurrency(code)
+		if err != nil {
+			return err
+		}
+		if code == currency {
+			return fmt.Errorf("the %s price is set with price, not currencyPrices", code)
+		}
+		if price < 0 {
+			return fmt.Errorf("the %s price cannot be negative", code)
+		}
+		prices[code] = price
+	}
+	album.CurrencyPrices = prices
+	return nil
+}
+
+// normalizeIdentifiers puts the album's catalogue number and barcode into their standard form,
+// checking the barcode's check digit.
+func normalizeIdentifiers(album *repository.Album) error {
+	album.CatalogueNumber = repository.NormalizeCatalogueNumber(album.CatalogueNumber)
+	if strings.TrimSpace(album.Barcode) == "" {
+		album.Barcode = ""
+		return nil
+	}
+	barcode, err := repository.NormalizeBarcode(album.Barcode)
+	if err != nil {
+		return err
+	}
+	album.Barcode = barcode
+	return nil
+}
+
+// respondWithAlbumSaveError writes the response for an album that could not be created or updated.
+func respondWithAlbumSaveError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrAlbumNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "album not found"})
+		return
+	}
+	if errors.Is(err, repository.ErrDuplicateBarcode) || errors.Is(err, repository.ErrDuplicateCatalogueNumber) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
 // AlbumIDUri is used for binding and validating the `id` URI parameter in routes like /albums/:id.
 // This struct is specific to HTTP request handling and should not be used in the domain or repository layers.
 type AlbumIDUri struct {
@@ -46,17 +832,69 @@ func getAlbumIDFromUri(c *gin.Context) (string, bool) {
 	return uri.ID, true
 }
 
+// currentUserID returns the caller's identity from the X-User-ID header, or "" for anonymous requests.
+// Authentication happens upstream of this service; the header is trusted as-is.
+func currentUserID(c *gin.Context) string {
+	return strings.TrimSpace(c.GetHeader("X-User-ID"))
+}
+
+// requireUserID returns the caller's user ID, writing a 401 response and returning false for anonymous callers.
+func requireUserID(c *gin.Context) (string, bool) {
+	userID := currentUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "X-User-ID header is required"})
+		return "", false
+	}
+	return userID, true
+}
+
+// RequireStaff returns middleware that lets a request through only if it carries the staff token as
+// "Authorization: Bearer <token>". It guards the routes that move money, which must not be reachable
+// with an X-User-ID header alone.
+func RequireStaff(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		given, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || token == "" || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(given)), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "a staff token is required"})
+			return
+		}
+		c.Next()
+	}
+}
+
 func (h *AlbumHandler) GetAlbumByID(c *gin.Context) {
 	id, ok := getAlbumIDFromUri(c)
 	if !ok {
 		return
 	}
+	currency, err := requestedCurrency(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	display, err := requestedTaxDisplay(c, h.Tax)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	album, err := h.Repo.GetByID(id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"message": "album not found"})
 		return
 	}
-	c.IndentedJSON(http.StatusOK, album)
+	albums, err := h.withReleases([]repository.Album{album}, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.decorate(albums); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !h.showIn(c, albums, currency) || !h.showTax(c, albums, display) {
+		return
+	}
+	c.IndentedJSON(http.StatusOK, albums[0])
 }
 
 func (h *AlbumHandler) PostAlbums(c *gin.Context) {
@@ -70,8 +908,28 @@ func (h *AlbumHandler) PostAlbums(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "imageUrl and genre are required and cannot be empty"})
 		return
 	}
+	if err := normalizePricing(&newAlbum); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := normalizeIdentifiers(&newAlbum); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tags, err := repository.NormalizeTags(newAlbum.Tags)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	newAlbum.Tags = tags
+	if !h.linkArtist(c, &newAlbum) || !h.checkGenre(c, &newAlbum) {
+		return
+	}
+	if !linkLabel(c, h.Labels, &newAlbum.LabelID, &newAlbum.Label, newAlbum.CatalogueNumber) {
+		return
+	}
 	if err := h.Repo.Create(newAlbum); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondWithAlbumSaveError(c, err)
 		return
 	}
 	c.IndentedJSON(http.StatusCreated, newAlbum)
@@ -92,21 +950,103 @@ func (h *AlbumHandler) PutAlbum(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "imageUrl and genre are required and cannot be empty"})
 		return
 	}
-	updatedAlbum.ID = id
-	err := h.Repo.Update(updatedAlbum)
+	if err := normalizePricing(&updatedAlbum); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := normalizeIdentifiers(&updatedAlbum); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tags, err := repository.NormalizeTags(updatedAlbum.Tags)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	updatedAlbum.Tags = tags
+	if !h.linkArtist(c, &updatedAlbum) || !h.checkGenre(c, &updatedAlbum) {
+		return
+	}
+	if !linkLabel(c, h.Labels, &updatedAlbum.LabelID, &updatedAlbum.Label, updatedAlbum.CatalogueNumber) {
+		return
+	}
+	updatedAlbum.ID = id
+	var previous repository.Album
+	var previousErr error
+	if h.Prices != nil || h.Revisions != nil {
+		previous, previousErr = h.Repo.GetByID(id)
+	}
+	if err := h.Repo.Update(updatedAlbum); err != nil {
+		respondWithAlbumSaveError(c, err)
+		return
+	}
+	if previousErr == nil {
+		recordPriceChange(c, "PutAlbum", h.Prices, previous, updatedAlbum, "")
+	}
+	if h.Revisions != nil {
+		h.recordRevision(c, previous, previousErr == nil, updatedAlbum)
+	}
 	c.IndentedJSON(http.StatusOK, updatedAlbum)
 }
 
+// recordPriceChange records the change in the album's price, if any, in the price history, when
+// there is one. The album has already been updated, so a missing history entry is logged rather than
+// failing the request.
+func recordPriceChange(c *gin.Context, caller string, prices repository.PriceRepository, previous, updated repository.Album, note string) {
+	if prices == nil || previous.Price == updated.Price {
+		return
+	}
+	if _, err := prices.RecordChange(repository.PriceChange{
+		AlbumID:  updated.ID,
+		OldPrice: previous.Price,
+		NewPrice: updated.Price,
+		Source:   repository.PriceSourceManual,
+		Actor:    currentUserID(c),
+		Note:     note,
+	}); err != nil {
+		log.Printf("%s: failed to record price change for album %s: %v", caller, updated.ID, err)
+	}
+}
+
+// recordRevision keeps the updated album as its next revision. An album edited for the first time
+// is first recorded as it was before, when that is known, so the edit can be rolled back. Failures
+// are logged, since the album has already been updated.
+func (h *AlbumHandler) recordRevision(c *gin.Context, previous repository.Album, knowPrevious bool, updated repository.Album) {
+	actor := currentUserID(c)
+	if knowPrevious {
+		existing, err := h.Revisions.List(updated.ID)
+		if err != nil {
+			log.Printf("PutAlbum: failed to list revisions of album %s: %v", updated.ID, err)
+			return
+		}
+		if len(existing) == 0 {
+			if _, err := h.Revisions.Record(repository.AlbumRevision{
+				AlbumID: updated.ID,
+				Album:   repository.SnapshotOf(previous),
+				Note:    "before the first recorded edit",
+			}); err != nil {
+				log.Printf("PutAlbum: failed to record the original revision of album %s: %v", updated.ID, err)
+				return
+			}
+		}
+	}
+	if _, err := h.Revisions.Record(repository.AlbumRevision{
+		AlbumID: updated.ID,
+		Album:   repository.SnapshotOf(updated),
+		Actor:   actor,
+	}); err != nil {
+		log.Printf("PutAlbum: failed to record revision of album %s: %v", updated.ID, err)
+	}
+}
+
+// DeleteAlbum handles DELETE /albums/:id, moving the album to the trash. It can be restored until
+// the purge job deletes it for good.
 func (h *AlbumHandler) DeleteAlbum(c *gin.Context) {
 	id, ok := getAlbumIDFromUri(c)
 	if !ok {
 		return
 	}
-	err := h.Repo.Delete(id)
+	err := h.Repo.Trash(id, currentUserID(c))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"message": "album not found"})
 		return
@@ -114,6 +1054,44 @@ func (h *AlbumHandler) DeleteAlbum(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// GetTrash handles GET /albums/trash, listing deleted albums, most recently deleted first, with
+// when and by whom they were deleted.
+func (h *AlbumHandler) GetTrash(c *gin.Context) {
+	albums, err := h.Repo.GetTrash()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if albums == nil {
+		albums = []repository.Album{}
+	}
+	c.IndentedJSON(http.StatusOK, albums)
+}
+
+// RestoreAlbum handles POST /albums/:id/restore, putting a deleted album back into the catalogue as
+// it was, releases and stock included.
+func (h *AlbumHandler) RestoreAlbum(c *gin.Context) {
+	id, ok := getAlbumIDFromUri(c)
+	if !ok {
+		return
+	}
+	err := h.Repo.Restore(id)
+	if errors.Is(err, repository.ErrAlbumNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "album not in trash"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	album, err := h.Repo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, album)
+}
+
 // SearchAlbums handles GET /api/search endpoint to search iTunes for albums
 func (h *AlbumHandler) SearchAlbums(c *gin.Context) {
 	// Get the search term from the query parameter
@@ -130,6 +1108,36 @@ func (h *AlbumHandler) SearchAlbums(c *gin.Context) {
 		c.JSON(http.StatusBadGateway, gin.H{"error": message})
 		return
 	}
+	tree, err := loadGenres(h.Genres)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if tree != nil {
+		// Results keep the iTunes genre name when the taxonomy does not know it.
+		for i := range searchResults {
+			if genre, ok := tree.Lookup(searchResults[i].Genre); ok {
+				searchResults[i].Genre = genre.Name
+			}
+		}
+	}
+	if h.Labels != nil {
+		labels, err := h.Labels.List()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		byKey := make(map[string]string, len(labels))
+		for _, label := range labels {
+			byKey[repository.LabelNameKey(label.Name)] = label.Name
+		}
+		// Results keep the label named in the copyright line when there is no such label yet.
+		for i := range searchResults {
+			if name, ok := byKey[repository.LabelNameKey(searchResults[i].Label)]; ok && searchResults[i].Label != "" {
+				searchResults[i].Label = name
+			}
+		}
+	}
 
 	c.JSON(http.StatusOK, searchResults)
 }
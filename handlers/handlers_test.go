@@ -7,10 +7,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
 	"github.com/tvergilio/motown-house-backend/repository"
 )
 
@@ -20,6 +23,27 @@ func TestMain(m *testing.M) {
 	os.Exit(code)
 }
 
+// decodeJSON decodes a JSON response body into a T, failing the test if it cannot.
+func decodeJSON[T any](t *testing.T, body []byte) T {
+	t.Helper()
+	var v T
+	require.NoError(t, json.Unmarshal(body, &v))
+	return v
+}
+
+// doRequest sends a request with a JSON body to the router as the given user, or anonymously if
+// userID is empty.
+func doRequest(r *gin.Engine, method, path, userID, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	if userID != "" {
+		req.Header.Set("X-User-ID", userID)
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
 func Test_GetAlbums_StatusAndContent(t *testing.T) {
 	handler := newTestHandler()
 	w := httptest.NewRecorder()
@@ -94,7 +118,7 @@ func Test_GetAlbumByID_Found(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "Thriller", album.Title)
 	assert.Equal(t, "Michael Jackson", album.Artist)
-	assert.Equal(t, 42.99, album.Price)
+	assert.Equal(t, money.MustParse("42.99"), album.Price)
 	assert.Equal(t, 1982, album.Year)
 	assert.Equal(t, "https://is1-ssl.mzstatic.com/image/thumb/Music115/v4/32/4f/fd/324ffda2-9e51-8f6a-0c2d-c6fd2b41ac55/074643811224.jpg/100x100bb.jpg", album.ImageUrl)
 }
@@ -116,7 +140,7 @@ func Test_PostAlbums_Success(t *testing.T) {
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
 
-	album := repository.Album{ID: "104", Title: "Bad", Artist: "Michael Jackson", Price: 29.99, Year: 1987, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music115/v4/8d/97/f4/8d97f427-2d17-1a51-1714-324483eb5fc1/886443546264.jpg/100x100bb.jpg", Genre: "Pop"}
+	album := repository.Album{ID: "104", Title: "Bad", Artist: "Michael Jackson", Price: money.MustParse("29.99"), Year: 1987, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music115/v4/8d/97/f4/8d97f427-2d17-1a51-1714-324483eb5fc1/886443546264.jpg/100x100bb.jpg", Genre: "Pop"}
 	jsonBytes, _ := json.Marshal(album)
 	c.Request = httptest.NewRequest("POST", "/albums", io.NopCloser(bytes.NewReader(jsonBytes)))
 	c.Request.Header.Set("Content-Type", "application/json")
@@ -129,6 +153,43 @@ func Test_PostAlbums_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, album.Title, resp.Title)
 	assert.Equal(t, album.Artist, resp.Artist)
+	assert.Equal(t, album.Price, resp.Price)
+	assert.Equal(t, money.DefaultCurrency, resp.Currency)
+}
+
+func Test_PostAlbums_ExactPriceAndCurrency(t *testing.T) {
+	handler := newTestHandler()
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	body := `{"title": "Bad", "artist": "Michael Jackson", "price": 39.99, "currency": "gbp", "year": 1987, "imageUrl": "https://example.com/bad.jpg", "genre": "Pop"}`
+	c.Request = httptest.NewRequest("POST", "/albums", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.PostAlbums(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"price": 39.99,`)
+	assert.Contains(t, w.Body.String(), `"currency": "GBP"`)
+}
+
+func Test_PostAlbums_InvalidPriceOrCurrency(t *testing.T) {
+	handler := newTestHandler()
+	r := setupRouter(handler)
+
+	for _, body := range []string{
+		`{"title": "Bad", "artist": "Michael Jackson", "price": 39.999, "year": 1987, "imageUrl": "https://example.com/bad.jpg", "genre": "Pop"}`,
+		`{"title": "Bad", "artist": "Michael Jackson", "price": 39.99, "currency": "dollars", "year": 1987, "imageUrl": "https://example.com/bad.jpg", "genre": "Pop"}`,
+		// Carts and orders are in GBP, so albums cannot be priced in another currency.
+		`{"title": "Bad", "artist": "Michael Jackson", "price": 39.99, "currency": "usd", "year": 1987, "imageUrl": "https://example.com/bad.jpg", "genre": "Pop"}`,
+	} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/albums", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
 }
 
 func Test_PostAlbums_InvalidJSON(t *testing.T) {
@@ -155,7 +216,7 @@ func Test_PutAlbums_Success(t *testing.T) {
 		ID:       "101",
 		Title:    "Thriller 25",
 		Artist:   "Michael Jackson",
-		Price:    45.99,
+		Price:    money.MustParse("45.99"),
 		Year:     1982,
 		ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music115/v4/8d/97/f4/8d97f427-2d17-1a51-1714-324483eb5fc1/886443546264.jpg/100x100bb.jpg",
 		Genre:    "Pop",
@@ -192,13 +253,13 @@ func Test_PutAlbums_NonExistentID(t *testing.T) {
 	r := setupRouter(handler)
 	w := httptest.NewRecorder()
 
-	updatedAlbum := repository.Album{ID: "999", Title: "Ghost Album", Artist: "Nobody", Price: 10.0, Year: 2000, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music115/v4/8d/97/f4/8d97f427-2d17-1a51-1714-324483eb5fc1/886443546264.jpg/100x100bb.jpg", Genre: "Pop"}
+	updatedAlbum := repository.Album{ID: "999", Title: "Ghost Album", Artist: "Nobody", Price: money.MustParse("10.00"), Year: 2000, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music115/v4/8d/97/f4/8d97f427-2d17-1a51-1714-324483eb5fc1/886443546264.jpg/100x100bb.jpg", Genre: "Pop"}
 	jsonBytes, _ := json.Marshal(updatedAlbum)
 	req := httptest.NewRequest("PUT", "/albums/999", bytes.NewReader(jsonBytes))
 	req.Header.Set("Content-Type", "application/json")
 
 	r.ServeHTTP(w, req)
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, http.StatusNotFound, w.Code)
 }
 
 func Test_PutAlbums_IDMismatch(t *testing.T) {
@@ -206,7 +267,7 @@ func Test_PutAlbums_IDMismatch(t *testing.T) {
 	r := setupRouter(handler)
 	w := httptest.NewRecorder()
 
-	updatedAlbum := repository.Album{ID: "102", Title: "Mismatch", Artist: "Test", Price: 20.0, Year: 2020, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music115/v4/8d97f427-2d17-1a51-1714-324483eb5fc1/886443546264.jpg/100x100bb.jpg", Genre: "Pop"}
+	updatedAlbum := repository.Album{ID: "102", Title: "Mismatch", Artist: "Test", Price: money.MustParse("20.00"), Year: 2020, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music115/v4/8d97f427-2d17-1a51-1714-324483eb5fc1/886443546264.jpg/100x100bb.jpg", Genre: "Pop"}
 	jsonBytes, _ := json.Marshal(updatedAlbum)
 	req := httptest.NewRequest("PUT", "/albums/101", bytes.NewReader(jsonBytes))
 	req.Header.Set("Content-Type", "application/json")
@@ -251,7 +312,7 @@ func Test_PutAlbums_NegativePriceOrYear(t *testing.T) {
 	r := setupRouter(handler)
 	w := httptest.NewRecorder()
 
-	updatedAlbum := repository.Album{ID: "101", Title: "Negative", Artist: "Test", Price: -10.0, Year: -1980, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music115/v4/8d/97/f4/8d97f427-2d17-1a51-1714-324483eb5fc1/886443546264.jpg/100x100bb.jpg", Genre: "Pop"}
+	updatedAlbum := repository.Album{ID: "101", Title: "Negative", Artist: "Test", Price: money.MustParse("-10.00"), Year: -1980, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music115/v4/8d/97/f4/8d97f427-2d17-1a51-1714-324483eb5fc1/886443546264.jpg/100x100bb.jpg", Genre: "Pop"}
 	jsonBytes, _ := json.Marshal(updatedAlbum)
 	req := httptest.NewRequest("PUT", "/albums/101", bytes.NewReader(jsonBytes))
 	req.Header.Set("Content-Type", "application/json")
@@ -298,7 +359,7 @@ func Test_PutAlbums_LargePayload(t *testing.T) {
 	for i := range largeTitle {
 		largeTitle[i] = 'A'
 	}
-	updatedAlbum := repository.Album{ID: "101", Title: string(largeTitle), Artist: "Test", Price: 10.0, Year: 2020, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music115/v4/8d/97/f4/8d97f427-2d27-1a51-1714-324483eb5fc1/886443546264.jpg/100x100bb.jpg", Genre: "Motown"}
+	updatedAlbum := repository.Album{ID: "101", Title: string(largeTitle), Artist: "Test", Price: money.MustParse("10.00"), Year: 2020, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music115/v4/8d/97/f4/8d97f427-2d27-1a51-1714-324483eb5fc1/886443546264.jpg/100x100bb.jpg", Genre: "Motown"}
 	jsonBytes, _ := json.Marshal(updatedAlbum)
 	req := httptest.NewRequest("PUT", "/albums/101", bytes.NewReader(jsonBytes))
 	req.Header.Set("Content-Type", "application/json")
@@ -428,7 +489,75 @@ func Test_SearchAlbums_ValidResponse(t *testing.T) {
 		assert.NotEmpty(t, result.Title, "title should not be empty")
 		assert.NotEmpty(t, result.Artist, "artist should not be empty")
 		assert.NotEmpty(t, result.Genre, "genre should not be empty")
-		assert.Greater(t, result.Price, 0.0, "price should be greater than 0")
+		assert.Greater(t, result.Price, money.Amount(0), "price should be greater than 0")
 		assert.Greater(t, result.Year, 0, "year should be greater than 0")
 	}
 }
+
+// setupTrashRouter serves the album routes along with the trash.
+func setupTrashRouter(handler *AlbumHandler) *gin.Engine {
+	r := setupRouter(handler)
+	r.GET("/albums/trash", handler.GetTrash)
+	r.POST("/albums/:id/restore", handler.RestoreAlbum)
+	return r
+}
+
+func Test_DeleteAlbum_MovesToTrash(t *testing.T) {
+	r := setupTrashRouter(newTestHandler())
+
+	w := doRequest(r, "DELETE", "/albums/2", "staff-1", "")
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	w = doRequest(r, "GET", "/albums/2", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doRequest(r, "GET", "/albums", "", "")
+	var albums []repository.Album
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &albums))
+	assert.Len(t, albums, 2)
+
+	w = doRequest(r, "GET", "/albums/trash", "staff-1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &albums))
+	require.Len(t, albums, 1)
+	assert.Equal(t, "Songs in the Key of Life", albums[0].Title)
+	assert.Equal(t, "staff-1", albums[0].DeletedBy)
+	require.NotNil(t, albums[0].DeletedAt)
+
+	w = doRequest(r, "DELETE", "/albums/2", "staff-1", "")
+	assert.Equal(t, http.StatusNotFound, w.Code, "albums in the trash cannot be deleted again")
+}
+
+func Test_PutAlbum_InTrash(t *testing.T) {
+	handler := newTestHandler()
+	revisions := newMockRevisionRepo()
+	handler.Revisions = revisions
+	r := setupTrashRouter(handler)
+	require.Equal(t, http.StatusNoContent, doRequest(r, "DELETE", "/albums/2", "staff-1", "").Code)
+
+	w := doRequest(r, "PUT", "/albums/2", "staff-1", `{"title":"Songs in the Key of Life (Remastered)","artist":"Stevie Wonder","price":42.50,"year":1976,"imageUrl":"https://example.com/songs.jpg","genre":"Motown"}`)
+
+	assert.Equal(t, http.StatusNotFound, w.Code, "albums in the trash cannot be edited")
+	assert.Empty(t, revisions.revisions["2"], "no revision is kept of an edit that was not made")
+	require.Equal(t, http.StatusOK, doRequest(r, "POST", "/albums/2/restore", "staff-1", "").Code)
+	album := decodeJSON[repository.Album](t, doRequest(r, "GET", "/albums/2", "", "").Body.Bytes())
+	assert.Equal(t, "Songs in the Key of Life", album.Title)
+}
+
+func Test_RestoreAlbum(t *testing.T) {
+	r := setupTrashRouter(newTestHandler())
+	require.Equal(t, http.StatusNoContent, doRequest(r, "DELETE", "/albums/2", "staff-1", "").Code)
+
+	w := doRequest(r, "POST", "/albums/2/restore", "staff-1", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	album := decodeJSON[repository.Album](t, w.Body.Bytes())
+	assert.Equal(t, "Songs in the Key of Life", album.Title)
+	assert.Nil(t, album.DeletedAt)
+
+	w = doRequest(r, "GET", "/albums/2", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = doRequest(r, "GET", "/albums/trash", "staff-1", "")
+	assert.JSONEq(t, `[]`, w.Body.String())
+
+	w = doRequest(r, "POST", "/albums/2/restore", "staff-1", "")
+	assert.Equal(t, http.StatusNotFound, w.Code, "only albums in the trash can be restored")
+}
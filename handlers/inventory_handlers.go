@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+type InventoryHandler struct {
+	Repo   repository.InventoryRepository
+	Albums repository.AlbumRepository
+}
+
+func NewInventoryHandler(repo repository.InventoryRepository, albums repository.AlbumRepository) *InventoryHandler {
+	return &InventoryHandler{
+		Repo:   repo,
+		Albums: albums,
+	}
+}
+
+// StockAdjustmentRequest is the body accepted by POST /albums/:id/stock/adjustments.
+type StockAdjustmentRequest struct {
+	Format string `json:"format" binding:"required"`
+	Delta  int    `json:"delta"`
+	Reason string `json:"reason" binding:"required"`
+	Note   string `json:"note"`
+}
+
+// getExistingAlbumID reads the album ID from the URI and checks the album exists,
+// writing a 400 or 404 response and returning false if not.
+func getExistingAlbumID(c *gin.Context, albums repository.AlbumRepository) (string, bool) {
+	id, ok := getAlbumIDFromUri(c)
+	if !ok {
+		return "", false
+	}
+	if _, err := albums.GetByID(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "album not found"})
+		return "", false
+	}
+	return id, true
+}
+
+// GetStock handles GET /albums/:id/stock, returning one entry per format.
+func (h *InventoryHandler) GetStock(c *gin.Context) {
+	id, ok := getExistingAlbumID(c, h.Albums)
+	if !ok {
+		return
+	}
+	levels, err := h.Repo.GetStock(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, completeStock(id, levels))
+}
+
+// PostAdjustment handles POST /albums/:id/stock/adjustments, letting staff add or remove units on hand.
+func (h *InventoryHandler) PostAdjustment(c *gin.Context) {
+	id, ok := getExistingAlbumID(c, h.Albums)
+	if !ok {
+		return
+	}
+	var req StockAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	format, err := repository.ParseFormat(req.Format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	reason, err := repository.ParseAdjustmentReason(req.Reason)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Delta == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "delta must not be zero"})
+		return
+	}
+
+	level, err := h.Repo.Adjust(repository.StockAdjustment{
+		AlbumID: id,
+		Format:  format,
+		Delta:   req.Delta,
+		Reason:  reason,
+		Note:    req.Note,
+		Actor:   currentUserID(c),
+	})
+	if errors.Is(err, repository.ErrInsufficientStock) {
+		c.JSON(http.StatusConflict, gin.H{"error": "adjustment would leave fewer units on hand than are reserved", "stock": level})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, level)
+}
+
+// GetAdjustments handles GET /albums/:id/stock/adjustments, newest first.
+func (h *InventoryHandler) GetAdjustments(c *gin.Context) {
+	id, ok := getExistingAlbumID(c, h.Albums)
+	if !ok {
+		return
+	}
+	adjustments, err := h.Repo.ListAdjustments(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if adjustments == nil {
+		adjustments = []repository.StockAdjustment{}
+	}
+	c.IndentedJSON(http.StatusOK, adjustments)
+}
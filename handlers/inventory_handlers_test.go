@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+func setupInventoryRouter(inventory *mockInventoryRepo) *gin.Engine {
+	albumHandler := newTestHandler()
+	albumHandler.Inventory = inventory
+	handler := NewInventoryHandler(inventory, albumHandler.Repo)
+
+	r := setupRouter(albumHandler)
+	r.GET("/albums/:id/stock", handler.GetStock)
+	r.POST("/albums/:id/stock/adjustments", handler.PostAdjustment)
+	r.GET("/albums/:id/stock/adjustments", handler.GetAdjustments)
+	return r
+}
+
+func postAdjustment(r *gin.Engine, albumID, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/albums/"+albumID+"/stock/adjustments", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", "staff-1")
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func Test_GetStock_ReturnsEveryFormat(t *testing.T) {
+	inventory := newMockInventoryRepo(repository.StockLevel{AlbumID: "101", Format: repository.FormatLP, OnHand: 5, Reserved: 2})
+	r := setupInventoryRouter(inventory)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/albums/101/stock", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var levels []repository.StockLevel
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &levels))
+	require.Len(t, levels, 3)
+	assert.Equal(t, repository.FormatLP, levels[0].Format)
+	assert.Equal(t, 3, levels[0].Available)
+	assert.Equal(t, repository.FormatCD, levels[1].Format)
+	assert.Equal(t, 0, levels[1].OnHand)
+}
+
+func Test_GetStock_UnknownAlbum(t *testing.T) {
+	r := setupInventoryRouter(newMockInventoryRepo())
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/albums/999/stock", nil))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "album not found")
+}
+
+func Test_PostAdjustment_Success(t *testing.T) {
+	inventory := newMockInventoryRepo()
+	r := setupInventoryRouter(inventory)
+
+	w := postAdjustment(r, "101", `{"format":"lp","delta":10,"reason":"received","note":"delivery from distributor"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var level repository.StockLevel
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &level))
+	assert.Equal(t, repository.FormatLP, level.Format)
+	assert.Equal(t, 10, level.OnHand)
+	assert.Equal(t, 10, level.Available)
+
+	require.Len(t, inventory.adjustments, 1)
+	assert.Equal(t, "staff-1", inventory.adjustments[0].Actor)
+	assert.Equal(t, repository.ReasonReceived, inventory.adjustments[0].Reason)
+}
+
+func Test_PostAdjustment_InvalidFormat(t *testing.T) {
+	r := setupInventoryRouter(newMockInventoryRepo())
+
+	w := postAdjustment(r, "101", `{"format":"8-track","delta":1,"reason":"received"}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid format")
+}
+
+func Test_PostAdjustment_InvalidReason(t *testing.T) {
+	r := setupInventoryRouter(newMockInventoryRepo())
+
+	w := postAdjustment(r, "101", `{"format":"CD","delta":1,"reason":"because"}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid reason")
+}
+
+func Test_PostAdjustment_ZeroDelta(t *testing.T) {
+	r := setupInventoryRouter(newMockInventoryRepo())
+
+	w := postAdjustment(r, "101", `{"format":"CD","delta":0,"reason":"stocktake"}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_PostAdjustment_BelowReserved(t *testing.T) {
+	inventory := newMockInventoryRepo(repository.StockLevel{AlbumID: "101", Format: repository.FormatCD, OnHand: 3, Reserved: 2})
+	r := setupInventoryRouter(inventory)
+
+	w := postAdjustment(r, "101", `{"format":"CD","delta":-2,"reason":"damaged"}`)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, 3, inventory.level("101", repository.FormatCD).OnHand)
+	assert.Empty(t, inventory.adjustments)
+}
+
+func Test_GetAdjustments_NewestFirst(t *testing.T) {
+	inventory := newMockInventoryRepo()
+	r := setupInventoryRouter(inventory)
+	postAdjustment(r, "101", `{"format":"LP","delta":4,"reason":"received"}`)
+	postAdjustment(r, "101", `{"format":"LP","delta":-1,"reason":"damaged"}`)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/albums/101/stock/adjustments", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var adjustments []repository.StockAdjustment
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &adjustments))
+	require.Len(t, adjustments, 2)
+	assert.Equal(t, repository.ReasonDamaged, adjustments[0].Reason)
+	assert.Equal(t, -1, adjustments[0].Delta)
+}
+
+func Test_GetAlbums_IncludesStock(t *testing.T) {
+	inventory := newMockInventoryRepo(repository.StockLevel{AlbumID: "1", Format: repository.FormatCD, OnHand: 2})
+	r := setupInventoryRouter(inventory)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/albums", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var albums []repository.Album
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &albums))
+	require.Len(t, albums, 3)
+	require.NotNil(t, albums[0].InStock)
+	assert.True(t, *albums[0].InStock)
+	assert.Len(t, albums[0].Stock, 3)
+	require.NotNil(t, albums[1].InStock)
+	assert.False(t, *albums[1].InStock, "album with no inventory should be out of stock")
+}
+
+func Test_GetAlbumByID_OutOfStockWhenAllReserved(t *testing.T) {
+	inventory := newMockInventoryRepo(repository.StockLevel{AlbumID: "101", Format: repository.FormatLP, OnHand: 1, Reserved: 1})
+	r := setupInventoryRouter(inventory)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/albums/101", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var album repository.Album
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &album))
+	require.NotNil(t, album.InStock)
+	assert.False(t, *album.InStock)
+}
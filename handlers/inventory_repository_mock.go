@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// In-memory mock implementation of InventoryRepository for testing
+
+type mockInventoryRepo struct {
+	levels      map[string]repository.StockLevel // keyed by albumID + "/" + format
+	adjustments []repository.StockAdjustment
+}
+
+func newMockInventoryRepo(levels ...repository.StockLevel) *mockInventoryRepo {
+	m := &mockInventoryRepo{levels: make(map[string]repository.StockLevel)}
+	for _, level := range levels {
+		level.Available = level.OnHand - level.Reserved
+		m.levels[level.AlbumID+"/"+string(level.Format)] = level
+	}
+	return m
+}
+
+func (m *mockInventoryRepo) level(albumID string, format repository.Format) repository.StockLevel {
+	if level, ok := m.levels[albumID+"/"+string(format)]; ok {
+		return level
+	}
+	return repository.StockLevel{AlbumID: albumID, Format: format}
+}
+
+func (m *mockInventoryRepo) save(level repository.StockLevel) repository.StockLevel {
+	level.Available = level.OnHand - level.Reserved
+	m.levels[level.AlbumID+"/"+string(level.Format)] = level
+	return level
+}
+
+func (m *mockInventoryRepo) GetStock(albumID string) ([]repository.StockLevel, error) {
+	var levels []repository.StockLevel
+	for _, format := range repository.Formats {
+		if level, ok := m.levels[albumID+"/"+string(format)]; ok {
+			levels = append(levels, level)
+		}
+	}
+	return levels, nil
+}
+
+func (m *mockInventoryRepo) GetAllStock() ([]repository.StockLevel, error) {
+	levels := make([]repository.StockLevel, 0, len(m.levels))
+	for _, level := range m.levels {
+		levels = append(levels, level)
+	}
+	return levels, nil
+}
+
+func (m *mockInventoryRepo) Adjust(adjustment repository.StockAdjustment) (repository.StockLevel, error) {
+	level := m.level(adjustment.AlbumID, adjustment.Format)
+	if level.OnHand+adjustment.Delta < level.Reserved {
+		return level, repository.ErrInsufficientStock
+	}
+	level.OnHand += adjustment.Delta
+	adjustment.ID = fmt.Sprint(len(m.adjustments) + 1)
+	adjustment.CreatedAt = time.Now()
+	m.adjustments = append([]repository.StockAdjustment{adjustment}, m.adjustments...)
+	return m.save(level), nil
+}
+
+func (m *mockInventoryRepo) Reserve(albumID string, format repository.Format, quantity int) (repository.StockLevel, error) {
+	level := m.level(albumID, format)
+	if level.Reserved+quantity > level.OnHand {
+		return level, repository.ErrInsufficientStock
+	}
+	level.Reserved += quantity
+	return m.save(level), nil
+}
+
+func (m *mockInventoryRepo) Release(albumID string, format repository.Format, quantity int) (repository.StockLevel, error) {
+	level := m.level(albumID, format)
+	if level.Reserved < quantity {
+		return level, repository.ErrInsufficientStock
+	}
+	level.Reserved -= quantity
+	return m.save(level), nil
+}
+
+func (m *mockInventoryRepo) Fulfil(albumID string, format repository.Format, quantity int) (repository.StockLevel, error) {
+	level := m.level(albumID, format)
+	if level.Reserved < quantity {
+		return level, repository.ErrInsufficientStock
+	}
+	level.Reserved -= quantity
+	level.OnHand -= quantity
+	return m.save(level), nil
+}
+
+func (m *mockInventoryRepo) ListAdjustments(albumID string) ([]repository.StockAdjustment, error) {
+	var adjustments []repository.StockAdjustment
+	for _, a := range m.adjustments {
+		if a.AlbumID == albumID {
+			adjustments = append(adjustments, a)
+		}
+	}
+	return adjustments, nil
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+type LabelHandler struct {
+	Repo   repository.LabelRepository
+	Albums repository.AlbumRepository
+
+	// Releases is optional; when set, releases are renamed with their label, and labels that
+	// releases came out on cannot be deleted.
+	Releases repository.ReleaseRepository
+}
+
+func NewLabelHandler(repo repository.LabelRepository, albums repository.AlbumRepository) *LabelHandler {
+	return &LabelHandler{Repo: repo, Albums: albums}
+}
+
+// LabelRequest is the body accepted by POST /labels and PUT /labels/:id.
+type LabelRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+func respondWithLabelError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrLabelNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "label not found"})
+	case errors.Is(err, repository.ErrDuplicateLabel), errors.Is(err, repository.ErrLabelHasAlbums):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// bindLabel reads and validates a label from the request body. It writes an error response and
+// returns false if the body is not a valid label.
+func bindLabel(c *gin.Context, id string) (repository.Label, bool) {
+	var req LabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return repository.Label{}, false
+	}
+	label := repository.Label{ID: id, Name: req.Name}
+	if err := label.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return repository.Label{}, false
+	}
+	return label, true
+}
+
+// GetLabels handles GET /labels, listing every label by name.
+func (h *LabelHandler) GetLabels(c *gin.Context) {
+	labels, err := h.Repo.List()
+	if err != nil {
+		respondWithLabelError(c, err)
+		return
+	}
+	if labels == nil {
+		labels = []repository.Label{}
+	}
+	c.IndentedJSON(http.StatusOK, labels)
+}
+
+// GetLabel handles GET /labels/:id.
+func (h *LabelHandler) GetLabel(c *gin.Context) {
+	label, err := h.Repo.GetByID(c.Param("id"))
+	if err != nil {
+		respondWithLabelError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, label)
+}
+
+// PostLabel handles POST /labels. The name must not already be used by another label.
+func (h *LabelHandler) PostLabel(c *gin.Context) {
+	label, ok := bindLabel(c, "")
+	if !ok {
+		return
+	}
+	created, err := h.Repo.Create(label)
+	if err != nil {
+		respondWithLabelError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusCreated, created)
+}
+
+// PutLabel handles PUT /labels/:id. Renaming a label renames it on its albums too.
+func (h *LabelHandler) PutLabel(c *gin.Context) {
+	label, ok := bindLabel(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	updated, err := h.Repo.Update(label)
+	if err != nil {
+		respondWithLabelError(c, err)
+		return
+	}
+	if err := h.renameAlbums(updated); err != nil {
+		// The label has already been updated; albums showing the old name can be fixed by saving the label again.
+		log.Printf("PutLabel: failed to rename albums of label %s: %v", updated.ID, err)
+	}
+	c.IndentedJSON(http.StatusOK, updated)
+}
+
+// renameAlbums gives the label's albums and releases the label's current name.
+func (h *LabelHandler) renameAlbums(label repository.Label) error {
+	albums, err := h.Albums.GetAll()
+	if err != nil {
+		return err
+	}
+	for _, album := range albums {
+		if album.LabelID != label.ID || album.Label == label.Name {
+			continue
+		}
+		album.Label = label.Name
+		if err := h.Albums.Update(album); err != nil {
+			return err
+		}
+	}
+	if h.Releases == nil {
+		return nil
+	}
+	releases, err := h.Releases.List()
+	if err != nil {
+		return err
+	}
+	for _, release := range releases {
+		if release.LabelID != label.ID || release.Label == label.Name {
+			continue
+		}
+		release.Label = label.Name
+		if _, err := h.Releases.Update(release); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteLabel handles DELETE /labels/:id. Labels that albums or releases came out on cannot be deleted.
+func (h *LabelHandler) DeleteLabel(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := h.Repo.GetByID(id); err != nil {
+		respondWithLabelError(c, err)
+		return
+	}
+	albums, err := h.Albums.GetAll()
+	if err != nil {
+		respondWithLabelError(c, err)
+		return
+	}
+	for _, album := range albums {
+		if album.LabelID == id {
+			respondWithLabelError(c, repository.ErrLabelHasAlbums)
+			return
+		}
+	}
+	if h.Releases != nil {
+		releases, err := h.Releases.List()
+		if err != nil {
+			respondWithLabelError(c, err)
+			return
+		}
+		for _, release := range releases {
+			if release.LabelID == id {
+				respondWithLabelError(c, repository.ErrLabelHasAlbums)
+				return
+			}
+		}
+	}
+	if err := h.Repo.Delete(id); err != nil {
+		respondWithLabelError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// setupLabelRouter serves the album and label routes, with labels for Tamla (label-1) and Gordy (label-2).
+func setupLabelRouter(t *testing.T) (*gin.Engine, *AlbumHandler, *mockLabelRepo) {
+	t.Helper()
+	labels := newMockLabelRepo()
+	albums := newTestHandler()
+	albums.Labels = labels
+	handler := NewLabelHandler(labels, albums.Repo)
+	r := gin.Default()
+	r.POST("/albums", albums.PostAlbums)
+	r.PUT("/albums/:id", albums.PutAlbum)
+	r.GET("/albums/:id", albums.GetAlbumByID)
+	r.GET("/albums/barcode/:barcode", albums.GetAlbumByBarcode)
+	r.GET("/labels", handler.GetLabels)
+	r.POST("/labels", handler.PostLabel)
+	r.GET("/labels/:id", handler.GetLabel)
+	r.PUT("/labels/:id", handler.PutLabel)
+	r.DELETE("/labels/:id", handler.DeleteLabel)
+	r.GET("/labels/:id/albums", albums.GetLabelAlbums)
+	r.GET("/labels/:id/catalogue/:number", albums.GetAlbumByCatalogueNumber)
+	return r, albums, labels
+}
+
+const songsInTheKeyOfLife = `{"title":"Songs in the Key of Life","artist":"Stevie Wonder","price":42.50,"year":1976,"imageUrl":"x","genre":"Motown",`
+
+func Test_PutAlbum_LinksLabelAndNormalizesIdentifiers(t *testing.T) {
+	r, _, labels := setupLabelRouter(t)
+
+	w := doRequest(r, "PUT", "/albums/2", "staff-1", songsInTheKeyOfLife+`"label":"Tamla Records","catalogueNumber":" t13-340c2 ","barcode":"0 36000-29145 2"}`)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	album := decodeJSON[repository.Album](t, w.Body.Bytes())
+	assert.Equal(t, "label-1", album.LabelID)
+	assert.Equal(t, "Tamla", album.Label, "the album takes the label's spelling")
+	assert.Equal(t, "T13-340C2", album.CatalogueNumber)
+	assert.Equal(t, "0036000291452", album.Barcode, "UPCs are stored as EAN-13")
+
+	w = doRequest(r, "POST", "/albums", "staff-1", `{"title":"Ain't No Mountain High Enough","artist":"Diana Ross","price":9.99,"year":1970,"imageUrl":"x","genre":"Soul","label":"Motown"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	motown, err := labels.GetByName("Motown")
+	require.NoError(t, err, "new labels are created as albums arrive")
+	assert.Equal(t, motown.ID, decodeJSON[repository.Album](t, w.Body.Bytes()).LabelID)
+}
+
+func Test_PostAlbums_RejectsBadIdentifiers(t *testing.T) {
+	r, _, _ := setupLabelRouter(t)
+
+	w := doRequest(r, "POST", "/albums", "staff-1", songsInTheKeyOfLife+`"barcode":"036000291453"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "check digit")
+
+	w = doRequest(r, "POST", "/albums", "staff-1", songsInTheKeyOfLife+`"catalogueNumber":"T13-340C2"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "catalogue numbers belong to a label")
+
+	w = doRequest(r, "POST", "/albums", "staff-1", songsInTheKeyOfLife+`"labelId":"label-404"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_PostAlbums_IdentifiersMustBeUnique(t *testing.T) {
+	r, _, _ := setupLabelRouter(t)
+	w := doRequest(r, "PUT", "/albums/2", "staff-1", songsInTheKeyOfLife+`"labelId":"label-1","catalogueNumber":"T13-340C2","barcode":"4006381333931"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = doRequest(r, "POST", "/albums", "staff-1", songsInTheKeyOfLife+`"barcode":"4006381333931"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = doRequest(r, "POST", "/albums", "staff-1", songsInTheKeyOfLife+`"label":"tamla","catalogueNumber":"t13-340c2"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = doRequest(r, "POST", "/albums", "staff-1", songsInTheKeyOfLife+`"label":"Gordy","catalogueNumber":"T13-340C2"}`)
+	assert.Equal(t, http.StatusCreated, w.Code, "other labels can use the same catalogue number")
+
+	w = doRequest(r, "PUT", "/albums/2", "staff-1", songsInTheKeyOfLife+`"labelId":"label-1","catalogueNumber":"T13-340C2","barcode":"4006381333931"}`)
+	assert.Equal(t, http.StatusOK, w.Code, "an album keeps its own identifiers")
+}
+
+func Test_GetAlbumByBarcode(t *testing.T) {
+	r, _, _ := setupLabelRouter(t)
+	doRequest(r, "PUT", "/albums/2", "staff-1", songsInTheKeyOfLife+`"barcode":"036000291452"}`)
+
+	for _, barcode := range []string{"036000291452", "0036000291452"} {
+		w := doRequest(r, "GET", "/albums/barcode/"+barcode, "", "")
+		require.Equal(t, http.StatusOK, w.Code, barcode)
+		assert.Equal(t, "2", decodeJSON[repository.Album](t, w.Body.Bytes()).ID)
+	}
+
+	w := doRequest(r, "GET", "/albums/barcode/4006381333931", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doRequest(r, "GET", "/albums/barcode/123", "", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_GetAlbumByCatalogueNumber(t *testing.T) {
+	r, _, _ := setupLabelRouter(t)
+	doRequest(r, "PUT", "/albums/2", "staff-1", songsInTheKeyOfLife+`"label":"Tamla","catalogueNumber":"T13-340C2"}`)
+
+	w := doRequest(r, "GET", "/labels/label-1/catalogue/t13-340c2", "", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "2", decodeJSON[repository.Album](t, w.Body.Bytes()).ID)
+
+	w = doRequest(r, "GET", "/labels/label-2/catalogue/T13-340C2", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doRequest(r, "GET", "/labels/label-404/catalogue/T13-340C2", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_GetLabelAlbums_ByCatalogueNumber(t *testing.T) {
+	r, _, _ := setupLabelRouter(t)
+	doRequest(r, "PUT", "/albums/2", "staff-1", songsInTheKeyOfLife+`"label":"Tamla","catalogueNumber":"T13-340C2"}`)
+	doRequest(r, "PUT", "/albums/1", "staff-1", `{"title":"Thriller","artist":"Michael Jackson","price":25.99,"year":1982,"imageUrl":"x","genre":"Pop","label":"Tamla"}`)
+	doRequest(r, "POST", "/albums", "staff-1", `{"title":"Talking Book","artist":"Stevie Wonder","price":9.99,"year":1972,"imageUrl":"x","genre":"Soul","label":"Tamla","catalogueNumber":"T 319L"}`)
+
+	w := doRequest(r, "GET", "/labels/label-1/albums", "", "")
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var listed []repository.Album
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed, 3)
+	assert.Equal(t, "Talking Book", listed[0].Title)
+	assert.Equal(t, "Songs in the Key of Life", listed[1].Title)
+	assert.Equal(t, "Thriller", listed[2].Title, "albums without a catalogue number come last")
+
+	w = doRequest(r, "GET", "/labels/label-2/albums", "", "")
+	assert.JSONEq(t, `[]`, w.Body.String())
+}
+
+func Test_PostLabel(t *testing.T) {
+	r, _, _ := setupLabelRouter(t)
+
+	w := doRequest(r, "POST", "/labels", "staff-1", `{"name":" Rare  Earth "}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var label repository.Label
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &label))
+	assert.Equal(t, "Rare Earth", label.Name)
+
+	w = doRequest(r, "POST", "/labels", "staff-1", `{"name":"Gordy Records"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = doRequest(r, "POST", "/labels", "staff-1", `{"name":" "}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doRequest(r, "GET", "/labels", "", "")
+	var labels []repository.Label
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &labels))
+	require.Len(t, labels, 3)
+	assert.Equal(t, "Gordy", labels[0].Name)
+}
+
+func Test_PutLabel_RenamesAlbums(t *testing.T) {
+	r, albums, _ := setupLabelRouter(t)
+	doRequest(r, "PUT", "/albums/2", "staff-1", songsInTheKeyOfLife+`"label":"Tamla"}`)
+
+	w := doRequest(r, "PUT", "/labels/label-1", "staff-1", `{"name":"Tamla Motown"}`)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	album, _ := albums.Repo.GetByID("2")
+	assert.Equal(t, "Tamla Motown", album.Label)
+
+	w = doRequest(r, "PUT", "/labels/label-2", "staff-1", `{"name":"tamla motown"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = doRequest(r, "PUT", "/labels/missing", "staff-1", `{"name":"Nobody"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_DeleteLabel(t *testing.T) {
+	r, _, _ := setupLabelRouter(t)
+	doRequest(r, "PUT", "/albums/2", "staff-1", songsInTheKeyOfLife+`"label":"Tamla"}`)
+
+	w := doRequest(r, "DELETE", "/labels/label-1", "staff-1", "")
+	assert.Equal(t, http.StatusConflict, w.Code, "labels with albums are kept")
+
+	w = doRequest(r, "DELETE", "/labels/label-2", "staff-1", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = doRequest(r, "GET", "/labels/label-2", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
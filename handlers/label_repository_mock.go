@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// In-memory mock implementation of LabelRepository for testing
+
+type mockLabelRepo struct {
+	labels []repository.Label
+	nextID int
+}
+
+// newMockLabelRepo returns a repository holding the Tamla and Gordy labels, as label-1 and label-2.
+func newMockLabelRepo() *mockLabelRepo {
+	m := &mockLabelRepo{}
+	for _, name := range []string{"Tamla", "Gordy"} {
+		if _, err := m.Create(repository.Label{Name: name}); err != nil {
+			panic(err)
+		}
+	}
+	return m
+}
+
+// nameTaken reports whether a label other than id goes by the label's name.
+func (m *mockLabelRepo) nameTaken(label repository.Label, id string) bool {
+	existing, err := m.GetByName(label.Name)
+	return err == nil && existing.ID != id
+}
+
+func (m *mockLabelRepo) Create(label repository.Label) (repository.Label, error) {
+	label.Normalize()
+	if m.nameTaken(label, "") {
+		return repository.Label{}, repository.ErrDuplicateLabel
+	}
+	m.nextID++
+	label.ID = fmt.Sprintf("label-%d", m.nextID)
+	label.CreatedAt = time.Now()
+	label.UpdatedAt = label.CreatedAt
+	m.labels = append(m.labels, label)
+	return label, nil
+}
+
+func (m *mockLabelRepo) GetByID(id string) (repository.Label, error) {
+	for _, label := range m.labels {
+		if label.ID == id {
+			return label, nil
+		}
+	}
+	return repository.Label{}, repository.ErrLabelNotFound
+}
+
+func (m *mockLabelRepo) GetByName(name string) (repository.Label, error) {
+	key := repository.LabelNameKey(name)
+	for _, label := range m.labels {
+		if repository.LabelNameKey(label.Name) == key {
+			return label, nil
+		}
+	}
+	return repository.Label{}, repository.ErrLabelNotFound
+}
+
+func (m *mockLabelRepo) List() ([]repository.Label, error) {
+	labels := append([]repository.Label(nil), m.labels...)
+	sort.SliceStable(labels, func(i, j int) bool {
+		return strings.ToLower(labels[i].Name) < strings.ToLower(labels[j].Name)
+	})
+	return labels, nil
+}
+
+func (m *mockLabelRepo) Update(label repository.Label) (repository.Label, error) {
+	label.Normalize()
+	for i, existing := range m.labels {
+		if existing.ID != label.ID {
+			continue
+		}
+		if m.nameTaken(label, label.ID) {
+			return repository.Label{}, repository.ErrDuplicateLabel
+		}
+		label.CreatedAt = existing.CreatedAt
+		label.UpdatedAt = time.Now()
+		m.labels[i] = label
+		return label, nil
+	}
+	return repository.Label{}, repository.ErrLabelNotFound
+}
+
+func (m *mockLabelRepo) Delete(id string) error {
+	for i, label := range m.labels {
+		if label.ID == id {
+			m.labels = append(m.labels[:i], m.labels[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrLabelNotFound
+}
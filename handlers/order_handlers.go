@@ -0,0 +1,349 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tvergilio/motown-house-backend/promotions"
+	"github.com/tvergilio/motown-house-backend/repository"
+	"github.com/tvergilio/motown-house-backend/tax"
+)
+
+type OrderHandler struct {
+	Repo   repository.OrderRepository
+	Carts  repository.CartRepository
+	Albums repository.AlbumRepository
+
+	// Promotions is optional; when set, checkout applies and redeems any promotions that apply.
+	Promotions repository.PromotionRepository
+
+	// Genres is optional; when set, promotions for a genre also apply to its sub-genres.
+	Genres repository.GenreRepository
+
+	// Releases is optional; when set, cart items for a release of an album are charged as that release.
+	Releases repository.ReleaseRepository
+
+	// TaxRates is optional; when set, checkout charges tax for the destination. Tax says whether
+	// album prices already include it.
+	TaxRates repository.TaxRateRepository
+	Tax      tax.Policy
+}
+
+func NewOrderHandler(repo repository.OrderRepository, carts repository.CartRepository, albums repository.AlbumRepository) *OrderHandler {
+	return &OrderHandler{
+		Repo:   repo,
+		Carts:  carts,
+		Albums: albums,
+	}
+}
+
+// CheckoutRequest is the body accepted by POST /checkout. Country and Region are where the order
+// is shipped; the shop's home country is assumed when Country is left out.
+type CheckoutRequest struct {
+	CartID  string `json:"cartId" binding:"required"`
+	Country string `json:"country"`
+	Region  string `json:"region"`
+}
+
+// destination returns where the order is going, for working out its tax.
+func (req CheckoutRequest) destination(policy tax.Policy) (tax.Destination, error) {
+	country := req.Country
+	if strings.TrimSpace(country) == "" {
+		country = policy.Home.Country
+	}
+	if strings.TrimSpace(country) == "" {
+		return tax.Destination{}, nil
+	}
+	return tax.ParseDestination(country, req.Region)
+}
+
+// OrderStatusRequest is the body accepted by POST /admin/orders/:id/status.
+type OrderStatusRequest struct {
+	Status string `json:"status" binding:"required"`
+	Note   string `json:"note"`
+}
+
+// respondWithOrderError maps repository errors from order operations to HTTP responses.
+func respondWithOrderError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrOrderNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "order not found"})
+	case errors.Is(err, repository.ErrInvalidTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrInsufficientStock):
+		c.JSON(http.StatusConflict, gin.H{"error": "not enough stock to fulfil the order"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// Checkout handles POST /checkout. It turns the cart into a pending order, snapshotting each album's
+// title, artist, current price, discount and tax, redeems the promotions used, reserves the stock and
+// empties the cart.
+func (h *OrderHandler) Checkout(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	var req CheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	dest, err := req.destination(h.Tax)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	cart, err := h.Carts.GetByID(req.CartID)
+	if errors.Is(err, repository.ErrCartNotFound) || (err == nil && cart.UserID != "" && cart.UserID != userID) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "cart not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if len(cart.Items) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cart is empty"})
+		return
+	}
+
+	active, err := loadPromotions(h.Promotions)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	genres, err := loadGenres(h.Genres)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	rates, err := loadTaxRates(h.TaxRates)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	now := time.Now()
+	order := repository.Order{UserID: userID, Country: dest.Country, Region: dest.Region, TaxIncluded: h.Tax.PricesIncludeTax}
+	var used []repository.Promotion
+	for _, item := range cart.Items {
+		album, err := lineAlbum(h.Albums, h.Releases, item.AlbumID, item.ReleaseID)
+		if errors.Is(err, repository.ErrReleaseNotFound) {
+			c.JSON(http.StatusConflict, gin.H{"error": "release " + item.ReleaseID + " of album " + item.AlbumID + " is no longer available"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "album " + item.AlbumID + " is no longer available"})
+			return
+		}
+		price := promotions.PriceLine(active, genres, cart.CouponCode, promotions.Line{Album: album, Quantity: item.Quantity}, now)
+		line := repository.OrderLine{
+			AlbumID:   item.AlbumID,
+			ReleaseID: item.ReleaseID,
+			Format:    item.Format,
+			Title:     album.Title,
+			Artist:    album.Artist,
+			UnitPrice: price.UnitPrice,
+			Quantity:  item.Quantity,
+			Discount:  price.Discount,
+			LineTotal: price.Total,
+		}
+		if price.Promotion != nil {
+			line.Promotion = price.Promotion.Name
+			used = appendPromotion(used, *price.Promotion)
+			if price.Promotion.Code != "" {
+				order.CouponCode = price.Promotion.Code
+			}
+		}
+		chargeTax(&line, rates, dest, album.TaxCategory, h.Tax)
+		order.Lines = append(order.Lines, line)
+		order.Discount += line.Discount
+		order.Tax += line.Tax
+		order.Total += line.LineTotal
+		if !h.Tax.PricesIncludeTax {
+			order.Total += line.Tax
+		}
+	}
+
+	redeemed, err := h.redeem(used)
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	created, err := h.Repo.Create(order)
+	if err != nil {
+		h.unredeem(redeemed)
+		respondWithOrderError(c, err)
+		return
+	}
+	if err := h.Carts.Delete(cart.ID); err != nil {
+		log.Printf("Checkout: order %s created but cart %s could not be deleted: %v", created.ID, cart.ID, err)
+	}
+	c.IndentedJSON(http.StatusCreated, created)
+}
+
+// chargeTax works out the tax on the line's total at the rate for the destination and the album's
+// tax category, recording which rate was used.
+func chargeTax(line *repository.OrderLine, rates []repository.TaxRate, dest tax.Destination, category string, policy tax.Policy) {
+	line.TaxCategory = tax.NormalizeCategory(category)
+	if line.TaxCategory == "" {
+		line.TaxCategory = tax.CategoryStandard
+	}
+	rate, ok := tax.Find(rates, dest, line.TaxCategory)
+	if !ok {
+		return
+	}
+	line.TaxName = rate.Name
+	line.TaxRate = rate.Rate
+	_, line.Tax = tax.Split(line.LineTotal, rate.Rate, policy.PricesIncludeTax)
+}
+
+// appendPromotion adds promotion to used unless it is already there.
+func appendPromotion(used []repository.Promotion, promotion repository.Promotion) []repository.Promotion {
+	for _, p := range used {
+		if p.ID == promotion.ID {
+			return used
+		}
+	}
+	return append(used, promotion)
+}
+
+// redeem counts one use of each promotion, once per order. If any has reached its usage limit the
+// uses already counted are given back and an error naming the promotion is returned.
+func (h *OrderHandler) redeem(used []repository.Promotion) ([]repository.Promotion, error) {
+	var redeemed []repository.Promotion
+	for _, promotion := range used {
+		if err := h.Promotions.Redeem(promotion.ID); err != nil {
+			h.unredeem(redeemed)
+			if errors.Is(err, repository.ErrPromotionExhausted) || errors.Is(err, repository.ErrPromotionNotFound) {
+				return nil, errors.New("promotion " + promotion.Name + " is no longer available")
+			}
+			return nil, err
+		}
+		redeemed = append(redeemed, promotion)
+	}
+	return redeemed, nil
+}
+
+// unredeem gives back uses counted for an order that was not placed.
+func (h *OrderHandler) unredeem(redeemed []repository.Promotion) {
+	for _, promotion := range redeemed {
+		if err := h.Promotions.Unredeem(promotion.ID); err != nil {
+			log.Printf("Checkout: failed to give back a use of promotion %s: %v", promotion.ID, err)
+		}
+	}
+}
+
+// GetMyOrders handles GET /orders, listing the caller's orders newest first.
+func (h *OrderHandler) GetMyOrders(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	orders, err := h.Repo.ListByUser(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if orders == nil {
+		orders = []repository.Order{}
+	}
+	c.IndentedJSON(http.StatusOK, orders)
+}
+
+// getOwnedOrder loads the order named in the URI, treating other users' orders as not found.
+func getOwnedOrder(c *gin.Context, orders repository.OrderRepository) (repository.Order, bool) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return repository.Order{}, false
+	}
+	order, err := orders.GetByID(c.Param("id"))
+	if err == nil && order.UserID != userID {
+		err = repository.ErrOrderNotFound
+	}
+	if err != nil {
+		respondWithOrderError(c, err)
+		return repository.Order{}, false
+	}
+	return order, true
+}
+
+// GetMyOrder handles GET /orders/:id.
+func (h *OrderHandler) GetMyOrder(c *gin.Context) {
+	order, ok := getOwnedOrder(c, h.Repo)
+	if !ok {
+		return
+	}
+	c.IndentedJSON(http.StatusOK, order)
+}
+
+// CancelMyOrder handles POST /orders/:id/cancel. Customers can cancel until the order ships.
+func (h *OrderHandler) CancelMyOrder(c *gin.Context) {
+	order, ok := getOwnedOrder(c, h.Repo)
+	if !ok {
+		return
+	}
+	updated, err := h.Repo.UpdateStatus(order.ID, repository.OrderCancelled, order.UserID, "cancelled by customer")
+	if err != nil {
+		respondWithOrderError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, updated)
+}
+
+// ListOrders handles GET /admin/orders, optionally filtered with ?status=.
+func (h *OrderHandler) ListOrders(c *gin.Context) {
+	var status repository.OrderStatus
+	if s := c.Query("status"); s != "" {
+		parsed, err := repository.ParseOrderStatus(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		status = parsed
+	}
+	orders, err := h.Repo.List(status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if orders == nil {
+		orders = []repository.Order{}
+	}
+	c.IndentedJSON(http.StatusOK, orders)
+}
+
+// GetOrder handles GET /admin/orders/:id.
+func (h *OrderHandler) GetOrder(c *gin.Context) {
+	order, err := h.Repo.GetByID(c.Param("id"))
+	if err != nil {
+		respondWithOrderError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, order)
+}
+
+// PostOrderStatus handles POST /admin/orders/:id/status, moving an order through its lifecycle.
+func (h *OrderHandler) PostOrderStatus(c *gin.Context) {
+	var req OrderStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	status, err := repository.ParseOrderStatus(req.Status)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	updated, err := h.Repo.UpdateStatus(c.Param("id"), status, currentUserID(c), req.Note)
+	if err != nil {
+		respondWithOrderError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, updated)
+}
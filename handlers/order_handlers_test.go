@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+type orderTestFixture struct {
+	router    *gin.Engine
+	orders    *mockOrderRepo
+	carts     *mockCartRepo
+	inventory *mockInventoryRepo
+	albums    repository.AlbumRepository
+	releases  *mockReleaseRepo
+}
+
+func setupOrderRouter() orderTestFixture {
+	f := orderTestFixture{
+		carts:     newMockCartRepo(),
+		inventory: newCartTestInventory(),
+		albums:    newTestHandler().Repo,
+		releases:  newMockReleaseRepo(),
+	}
+	f.orders = newMockOrderRepo(f.inventory)
+	handler := NewOrderHandler(f.orders, f.carts, f.albums)
+	handler.Releases = f.releases
+
+	r := gin.Default()
+	r.POST("/checkout", handler.Checkout)
+	r.GET("/orders", handler.GetMyOrders)
+	r.GET("/orders/:id", handler.GetMyOrder)
+	r.POST("/orders/:id/cancel", handler.CancelMyOrder)
+	r.GET("/admin/orders", handler.ListOrders)
+	r.GET("/admin/orders/:id", handler.GetOrder)
+	r.POST("/admin/orders/:id/status", handler.PostOrderStatus)
+	f.router = r
+	return f
+}
+
+// checkout creates a cart with the given items and checks it out as user-1.
+func (f orderTestFixture) checkout(t *testing.T, items ...repository.CartItem) repository.Order {
+	t.Helper()
+	cart, _ := f.carts.Create(repository.Cart{Items: items})
+	w := doRequest(f.router, "POST", "/checkout", "user-1", `{"cartId":"`+cart.ID+`"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var order repository.Order
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &order))
+	return order
+}
+
+func Test_Checkout_CreatesPendingOrderAndReservesStock(t *testing.T) {
+	f := setupOrderRouter()
+
+	order := f.checkout(t,
+		repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 2},
+		repository.CartItem{AlbumID: "2", Format: repository.FormatCD, Quantity: 1},
+	)
+
+	assert.Equal(t, repository.OrderPending, order.Status)
+	assert.Equal(t, "user-1", order.UserID)
+	require.Len(t, order.Lines, 2)
+	assert.Equal(t, "Thriller", order.Lines[0].Title)
+	assert.Equal(t, "Michael Jackson", order.Lines[0].Artist)
+	assert.Equal(t, money.MustParse("25.99"), order.Lines[0].UnitPrice)
+	assert.Equal(t, money.MustParse("51.98"), order.Lines[0].LineTotal)
+	assert.Equal(t, money.MustParse("94.48"), order.Total)
+	assert.Equal(t, 2, f.inventory.level("1", repository.FormatLP).Reserved)
+	assert.Equal(t, 2, f.inventory.level("2", repository.FormatCD).Reserved)
+	assert.Empty(t, f.carts.carts, "cart is removed after checkout")
+}
+
+func Test_Checkout_SnapshotsPrice(t *testing.T) {
+	f := setupOrderRouter()
+	order := f.checkout(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+
+	album, _ := f.albums.GetByID("1")
+	album.Price = money.MustParse("99.99")
+	require.NoError(t, f.albums.Update(album))
+
+	w := doRequest(f.router, "GET", "/orders/"+order.ID, "user-1", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got repository.Order
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, money.MustParse("25.99"), got.Lines[0].UnitPrice)
+}
+
+func Test_Checkout_ChargesReleasePrice(t *testing.T) {
+	f := setupOrderRouter()
+	original, _ := f.releases.Create(repository.Release{AlbumID: "1", Format: repository.FormatLP, Edition: "Original", Price: money.MustParse("60.00"), Currency: "GBP"})
+
+	order := f.checkout(t,
+		repository.CartItem{AlbumID: "1", ReleaseID: original.ID, Format: repository.FormatLP, Quantity: 1},
+		repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1},
+	)
+
+	require.Len(t, order.Lines, 2)
+	assert.Equal(t, original.ID, order.Lines[0].ReleaseID)
+	assert.Equal(t, money.MustParse("60.00"), order.Lines[0].UnitPrice)
+	assert.Empty(t, order.Lines[1].ReleaseID)
+	assert.Equal(t, money.MustParse("25.99"), order.Lines[1].UnitPrice)
+	assert.Equal(t, money.MustParse("85.99"), order.Total)
+	assert.Equal(t, 2, f.inventory.level("1", repository.FormatLP).Reserved)
+
+	require.NoError(t, f.releases.Delete("1", original.ID))
+	cart, _ := f.carts.Create(repository.Cart{Items: []repository.CartItem{{AlbumID: "1", ReleaseID: original.ID, Format: repository.FormatLP, Quantity: 1}}})
+	w := doRequest(f.router, "POST", "/checkout", "user-1", `{"cartId":"`+cart.ID+`"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func Test_Checkout_InsufficientStockReservesNothing(t *testing.T) {
+	f := setupOrderRouter()
+	cart, _ := f.carts.Create(repository.Cart{Items: []repository.CartItem{
+		{AlbumID: "1", Format: repository.FormatLP, Quantity: 1},
+		{AlbumID: "2", Format: repository.FormatCD, Quantity: 5},
+	}})
+
+	w := doRequest(f.router, "POST", "/checkout", "user-1", `{"cartId":"`+cart.ID+`"}`)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, 0, f.inventory.level("1", repository.FormatLP).Reserved)
+	assert.Len(t, f.carts.carts, 1, "cart is kept when checkout fails")
+}
+
+func Test_Checkout_EmptyCart(t *testing.T) {
+	f := setupOrderRouter()
+	cart, _ := f.carts.Create(repository.Cart{})
+
+	w := doRequest(f.router, "POST", "/checkout", "user-1", `{"cartId":"`+cart.ID+`"}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "cart is empty")
+}
+
+func Test_Checkout_RequiresUser(t *testing.T) {
+	f := setupOrderRouter()
+
+	w := doRequest(f.router, "POST", "/checkout", "", `{"cartId":"cart-1"}`)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func Test_Checkout_OtherUsersCart(t *testing.T) {
+	f := setupOrderRouter()
+	cart, _ := f.carts.Create(repository.Cart{UserID: "user-2", Items: []repository.CartItem{{AlbumID: "1", Format: repository.FormatLP, Quantity: 1}}})
+
+	w := doRequest(f.router, "POST", "/checkout", "user-1", `{"cartId":"`+cart.ID+`"}`)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_GetMyOrders_OnlyOwnOrders(t *testing.T) {
+	f := setupOrderRouter()
+	f.checkout(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+
+	w := doRequest(f.router, "GET", "/orders", "user-2", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, "[]", w.Body.String())
+}
+
+func Test_GetMyOrder_OtherUser(t *testing.T) {
+	f := setupOrderRouter()
+	order := f.checkout(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+
+	w := doRequest(f.router, "GET", "/orders/"+order.ID, "user-2", "")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_CancelMyOrder_ReleasesStock(t *testing.T) {
+	f := setupOrderRouter()
+	order := f.checkout(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 2})
+
+	w := doRequest(f.router, "POST", "/orders/"+order.ID+"/cancel", "user-1", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status": "cancelled"`)
+	assert.Equal(t, 0, f.inventory.level("1", repository.FormatLP).Reserved)
+}
+
+func Test_PostOrderStatus_Lifecycle(t *testing.T) {
+	f := setupOrderRouter()
+	order := f.checkout(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 2})
+	path := "/admin/orders/" + order.ID + "/status"
+
+	assert.Equal(t, http.StatusOK, doRequest(f.router, "POST", path, "staff-1", `{"status":"paid"}`).Code)
+	assert.Equal(t, http.StatusOK, doRequest(f.router, "POST", path, "staff-1", `{"status":"shipped","note":"Royal Mail"}`).Code)
+	level := f.inventory.level("1", repository.FormatLP)
+	assert.Equal(t, 3, level.OnHand, "shipping removes units from stock")
+	assert.Equal(t, 0, level.Reserved)
+
+	w := doRequest(f.router, "POST", "/orders/"+order.ID+"/cancel", "user-1", "")
+	assert.Equal(t, http.StatusConflict, w.Code, "shipped orders cannot be cancelled")
+
+	w = doRequest(f.router, "POST", path, "staff-1", `{"status":"delivered"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var delivered repository.Order
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &delivered))
+	assert.Equal(t, repository.OrderDelivered, delivered.Status)
+	require.Len(t, delivered.History, 4)
+	assert.Equal(t, "Royal Mail", delivered.History[2].Note)
+	assert.Equal(t, "staff-1", delivered.History[2].Actor)
+}
+
+func Test_PostOrderStatus_InvalidTransition(t *testing.T) {
+	f := setupOrderRouter()
+	order := f.checkout(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+
+	w := doRequest(f.router, "POST", "/admin/orders/"+order.ID+"/status", "staff-1", `{"status":"delivered"}`)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func Test_PostOrderStatus_UnknownStatus(t *testing.T) {
+	f := setupOrderRouter()
+	order := f.checkout(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+
+	w := doRequest(f.router, "POST", "/admin/orders/"+order.ID+"/status", "staff-1", `{"status":"lost"}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_ListOrders_FilterByStatus(t *testing.T) {
+	f := setupOrderRouter()
+	first := f.checkout(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+	f.checkout(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+	doRequest(f.router, "POST", "/admin/orders/"+first.ID+"/status", "staff-1", `{"status":"paid"}`)
+
+	w := doRequest(f.router, "GET", "/admin/orders?status=paid", "staff-1", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var orders []repository.Order
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &orders))
+	require.Len(t, orders, 1)
+	assert.Equal(t, first.ID, orders[0].ID)
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// In-memory mock implementation of OrderRepository for testing. Like the real repositories it
+// reserves, releases and fulfils stock through the inventory as orders change status.
+
+type mockOrderRepo struct {
+	orders    []repository.Order
+	inventory *mockInventoryRepo
+}
+
+func newMockOrderRepo(inventory *mockInventoryRepo) *mockOrderRepo {
+	return &mockOrderRepo{inventory: inventory}
+}
+
+func (m *mockOrderRepo) Create(order repository.Order) (repository.Order, error) {
+	for i, line := range order.Lines {
+		if _, err := m.inventory.Reserve(line.AlbumID, line.Format, line.Quantity); err != nil {
+			for _, done := range order.Lines[:i] {
+				_, _ = m.inventory.Release(done.AlbumID, done.Format, done.Quantity)
+			}
+			return repository.Order{}, err
+		}
+	}
+	order.ID = fmt.Sprintf("order-%d", len(m.orders)+1)
+	order.Status = repository.OrderPending
+	order.CreatedAt = time.Now()
+	order.UpdatedAt = order.CreatedAt
+	order.History = []repository.OrderEvent{{Status: repository.OrderPending, Actor: order.UserID, CreatedAt: order.CreatedAt}}
+	m.orders = append(m.orders, order)
+	return order, nil
+}
+
+func (m *mockOrderRepo) GetByID(id string) (repository.Order, error) {
+	for _, order := range m.orders {
+		if order.ID == id {
+			return order, nil
+		}
+	}
+	return repository.Order{}, repository.ErrOrderNotFound
+}
+
+func (m *mockOrderRepo) ListByUser(userID string) ([]repository.Order, error) {
+	var orders []repository.Order
+	for _, order := range m.orders {
+		if order.UserID == userID {
+			orders = append(orders, order)
+		}
+	}
+	return orders, nil
+}
+
+func (m *mockOrderRepo) List(status repository.OrderStatus) ([]repository.Order, error) {
+	var orders []repository.Order
+	for _, order := range m.orders {
+		if status == "" || order.Status == status {
+			orders = append(orders, order)
+		}
+	}
+	return orders, nil
+}
+
+func (m *mockOrderRepo) UpdateStatus(id string, status repository.OrderStatus, actor, note string) (repository.Order, error) {
+	for i, order := range m.orders {
+		if order.ID != id {
+			continue
+		}
+		if !order.Status.CanTransitionTo(status) {
+			return repository.Order{}, repository.ErrInvalidTransition
+		}
+		for _, line := range order.Lines {
+			switch status {
+			case repository.OrderCancelled:
+				_, _ = m.inventory.Release(line.AlbumID, line.Format, line.Quantity)
+			case repository.OrderShipped:
+				_, _ = m.inventory.Fulfil(line.AlbumID, line.Format, line.Quantity)
+			}
+		}
+		order.Status = status
+		order.UpdatedAt = time.Now()
+		order.History = append(order.History, repository.OrderEvent{Status: status, Actor: actor, Note: note, CreatedAt: order.UpdatedAt})
+		m.orders[i] = order
+		return order, nil
+	}
+	return repository.Order{}, repository.ErrOrderNotFound
+}
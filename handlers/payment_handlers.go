@@ -0,0 +1,463 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/payments"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// errNotRefundable is returned when refunding a payment that was never captured or is already fully refunded.
+var errNotRefundable = errors.New("payment cannot be refunded")
+
+type PaymentHandler struct {
+	Repo     repository.PaymentRepository
+	Orders   repository.OrderRepository
+	Provider payments.Provider
+
+	// GiftCards is optional; when set, orders can be paid with gift cards and store credit, alone or
+	// alongside the payment provider.
+	GiftCards repository.GiftCardRepository
+}
+
+func NewPaymentHandler(repo repository.PaymentRepository, orders repository.OrderRepository, provider payments.Provider) *PaymentHandler {
+	return &PaymentHandler{
+		Repo:     repo,
+		Orders:   orders,
+		Provider: provider,
+	}
+}
+
+// CreatePaymentRequest is the body accepted by POST /orders/:id/payments. It pays with exactly one of:
+// PaymentMethod, the provider's token for the customer's card (the fake provider accepts tok_visa and
+// declines tok_declined); GiftCardCode, a gift card's code; or StoreCredit, the customer's store credit.
+type CreatePaymentRequest struct {
+	PaymentMethod string `json:"paymentMethod"`
+	GiftCardCode  string `json:"giftCardCode"`
+	StoreCredit   bool   `json:"storeCredit"`
+}
+
+// validate checks the request names exactly one way to pay.
+func (req CreatePaymentRequest) validate() error {
+	methods := 0
+	for _, set := range []bool{strings.TrimSpace(req.PaymentMethod) != "", strings.TrimSpace(req.GiftCardCode) != "", req.StoreCredit} {
+		if set {
+			methods++
+		}
+	}
+	if methods != 1 {
+		return errors.New("exactly one of paymentMethod, giftCardCode or storeCredit is required")
+	}
+	return nil
+}
+
+// paysFromBalance reports whether the intent was paid from a gift card or store credit rather than
+// through the payment provider. ProviderRef is then the card's ID.
+func paysFromBalance(intent repository.PaymentIntent) bool {
+	return intent.Provider == string(repository.GiftCardVoucher) || intent.Provider == string(repository.StoreCredit)
+}
+
+// RefundRequest is the body accepted by POST /admin/payments/:id/refund. Amount defaults to everything not yet refunded.
+type RefundRequest struct {
+	Amount *money.Amount `json:"amount"`
+}
+
+// PostPayment handles POST /orders/:id/payments, authorising and capturing the order total.
+// Clients must send an Idempotency-Key header; retrying with the same key returns the original
+// payment instead of charging again.
+func (h *PaymentHandler) PostPayment(c *gin.Context) {
+	order, ok := getOwnedOrder(c, h.Orders)
+	if !ok {
+		return
+	}
+	key := strings.TrimSpace(c.GetHeader("Idempotency-Key"))
+	if key == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key header is required"})
+		return
+	}
+	var req CreatePaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Keys only need to be unique per customer, so scope them to stop customers colliding.
+	key = order.UserID + ":" + key
+
+	existing, err := h.Repo.GetByIdempotencyKey(key)
+	if err == nil {
+		respondWithExistingPayment(c, existing, order.ID)
+		return
+	}
+	if !errors.Is(err, repository.ErrPaymentNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if order.Status != repository.OrderPending {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("order is %s, not awaiting payment", order.Status)})
+		return
+	}
+
+	outstanding, err := outstandingBalance(h.Repo, order)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if outstanding <= 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "order is already paid for"})
+		return
+	}
+	provider, amount := h.Provider.Name(), outstanding
+	var card repository.GiftCard
+	if req.PaymentMethod == "" {
+		if card, ok = h.balanceToPayWith(c, order, req); !ok {
+			return
+		}
+		provider, amount = string(card.Kind), money.Min(card.Balance, outstanding)
+	}
+
+	intent, err := h.Repo.Create(repository.PaymentIntent{
+		OrderID:        order.ID,
+		IdempotencyKey: key,
+		Provider:       provider,
+		Amount:         amount,
+		Status:         repository.PaymentPending,
+	})
+	if errors.Is(err, repository.ErrDuplicateIdempotencyKey) || errors.Is(err, repository.ErrPaymentInProgress) {
+		// A concurrent retry may have won the race to create the intent.
+		if existing, getErr := h.Repo.GetByIdempotencyKey(key); getErr == nil {
+			respondWithExistingPayment(c, existing, order.ID)
+			return
+		}
+	}
+	if errors.Is(err, repository.ErrPaymentInProgress) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The order can have no other payment in flight now, so work the balance out again in case one
+	// was captured since it was first read.
+	if outstanding, err = outstandingBalance(h.Repo, order); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if outstanding <= 0 {
+		intent.Status = repository.PaymentFailed
+		intent.FailureReason = "order is already paid for"
+		if _, err := h.Repo.Update(intent); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusConflict, gin.H{"error": "order is already paid for"})
+		return
+	}
+	intent.Amount = money.Min(intent.Amount, outstanding)
+
+	if card.ID != "" {
+		intent, err = h.redeem(intent, card, order.UserID)
+	} else {
+		intent, err = h.charge(intent, req.PaymentMethod)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if intent.Status == repository.PaymentFailed {
+		c.IndentedJSON(http.StatusPaymentRequired, intent)
+		return
+	}
+	c.IndentedJSON(http.StatusCreated, intent)
+}
+
+// balanceToPayWith finds the gift card or store credit the request pays with, writing an error
+// response and returning false if it cannot be used.
+func (h *PaymentHandler) balanceToPayWith(c *gin.Context, order repository.Order, req CreatePaymentRequest) (repository.GiftCard, bool) {
+	if h.GiftCards == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "gift cards and store credit are not accepted"})
+		return repository.GiftCard{}, false
+	}
+	var card repository.GiftCard
+	var err error
+	if req.StoreCredit {
+		card, err = h.GiftCards.GetStoreCredit(order.UserID)
+	} else {
+		card, err = h.GiftCards.GetByCode(req.GiftCardCode)
+	}
+	switch {
+	case errors.Is(err, repository.ErrGiftCardNotFound) && req.StoreCredit:
+		c.JSON(http.StatusConflict, gin.H{"error": "you have no store credit"})
+	case errors.Is(err, repository.ErrGiftCardNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "gift card not found"})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	case card.Balance <= 0:
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("%s has no balance left", card.Kind)})
+	default:
+		return card, true
+	}
+	return repository.GiftCard{}, false
+}
+
+// outstandingBalance returns how much of the order's total is not yet covered by captured payments.
+func outstandingBalance(repo repository.PaymentRepository, order repository.Order) (money.Amount, error) {
+	intents, err := repo.ListByOrder(order.ID)
+	if err != nil {
+		return 0, err
+	}
+	outstanding := order.Total
+	for _, intent := range intents {
+		if intent.Status == repository.PaymentCaptured || intent.Status == repository.PaymentRefunded {
+			outstanding -= intent.Amount - intent.RefundedAmount
+		}
+	}
+	return outstanding, nil
+}
+
+// respondWithExistingPayment replays the result of an earlier request with the same idempotency key.
+func respondWithExistingPayment(c *gin.Context, intent repository.PaymentIntent, orderID string) {
+	if intent.OrderID != orderID {
+		c.JSON(http.StatusConflict, gin.H{"error": "Idempotency-Key was already used for a different order"})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, intent)
+}
+
+// charge authorises and captures a new intent with the provider, recording the outcome. A declined
+// payment is not an error: the intent is returned with status failed and the reason.
+func (h *PaymentHandler) charge(intent repository.PaymentIntent, paymentMethod string) (repository.PaymentIntent, error) {
+	auth, err := h.Provider.Authorize(payments.AuthorizeRequest{
+		IdempotencyKey: intent.ID,
+		Amount:         intent.Amount,
+		PaymentMethod:  paymentMethod,
+		Description:    "order " + intent.OrderID,
+	})
+	if err == nil {
+		intent.ProviderRef = auth.Reference
+		intent.Status = repository.PaymentAuthorized
+		if intent, err = h.Repo.Update(intent); err != nil {
+			return repository.PaymentIntent{}, err
+		}
+		err = h.Provider.Capture(intent.ProviderRef, intent.Amount)
+	}
+	if err != nil {
+		intent.Status = repository.PaymentFailed
+		intent.FailureReason = err.Error()
+		return h.Repo.Update(intent)
+	}
+	intent.Status = repository.PaymentCaptured
+	if intent, err = h.Repo.Update(intent); err != nil {
+		return repository.PaymentIntent{}, err
+	}
+	return intent, markOrderPaid(h.Orders, h.Repo, intent)
+}
+
+// redeem pays a new intent from a gift card or store credit. If another payment spent the balance
+// first, the intent fails like a declined card.
+func (h *PaymentHandler) redeem(intent repository.PaymentIntent, card repository.GiftCard, actor string) (repository.PaymentIntent, error) {
+	intent.ProviderRef = card.ID
+	_, err := h.GiftCards.Apply(card.ID, repository.GiftCardEntry{
+		Amount:  -intent.Amount,
+		Reason:  repository.EntryRedeemed,
+		OrderID: intent.OrderID,
+		Actor:   actor,
+		Note:    "payment " + intent.ID,
+	})
+	if errors.Is(err, repository.ErrInsufficientBalance) {
+		intent.Status = repository.PaymentFailed
+		intent.FailureReason = err.Error()
+		return h.Repo.Update(intent)
+	}
+	if err != nil {
+		return repository.PaymentIntent{}, err
+	}
+	intent.Status = repository.PaymentCaptured
+	if intent, err = h.Repo.Update(intent); err != nil {
+		return repository.PaymentIntent{}, err
+	}
+	return intent, markOrderPaid(h.Orders, h.Repo, intent)
+}
+
+// markOrderPaid moves the intent's order from pending to paid once its captured payments cover the
+// total. It is safe to call more than once.
+func markOrderPaid(orders repository.OrderRepository, payments repository.PaymentRepository, intent repository.PaymentIntent) error {
+	order, err := orders.GetByID(intent.OrderID)
+	if err != nil {
+		return err
+	}
+	if outstanding, err := outstandingBalance(payments, order); err != nil || outstanding > 0 {
+		return err
+	}
+	_, err = orders.UpdateStatus(intent.OrderID, repository.OrderPaid, "payments", "payment "+intent.ID)
+	if errors.Is(err, repository.ErrInvalidTransition) {
+		if order, getErr := orders.GetByID(intent.OrderID); getErr == nil && order.Status == repository.OrderCancelled {
+			log.Printf("Payment %s captured for cancelled order %s; it needs refunding", intent.ID, intent.OrderID)
+		}
+		return nil
+	}
+	return err
+}
+
+// GetOrderPayments handles GET /orders/:id/payments.
+func (h *PaymentHandler) GetOrderPayments(c *gin.Context) {
+	order, ok := getOwnedOrder(c, h.Orders)
+	if !ok {
+		return
+	}
+	intents, err := h.Repo.ListByOrder(order.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if intents == nil {
+		intents = []repository.PaymentIntent{}
+	}
+	c.IndentedJSON(http.StatusOK, intents)
+}
+
+// Webhook handles POST /payments/webhook. The provider signs each event in the Payment-Signature
+// header; unsigned or tampered requests are rejected. Events are applied idempotently, since
+// providers deliver them at least once.
+func (h *PaymentHandler) Webhook(c *gin.Context) {
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	event, err := h.Provider.VerifyWebhook(payload, c.GetHeader("Payment-Signature"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	intent, err := h.Repo.GetByProviderRef(h.Provider.Name(), event.Reference)
+	if errors.Is(err, repository.ErrPaymentNotFound) {
+		// Acknowledge anyway so the provider stops redelivering an event we will never match.
+		c.JSON(http.StatusOK, gin.H{"message": "ignored"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	intent, err = h.applyEvent(intent, event)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, intent)
+}
+
+// applyEvent updates an intent, and its order, from a webhook event. Events that describe a state
+// the intent has already reached leave it unchanged.
+func (h *PaymentHandler) applyEvent(intent repository.PaymentIntent, event payments.Event) (repository.PaymentIntent, error) {
+	var err error
+	switch event.Type {
+	case payments.EventCaptured:
+		if intent.Status == repository.PaymentCaptured || intent.Status == repository.PaymentRefunded {
+			return intent, nil
+		}
+		intent.Status = repository.PaymentCaptured
+		intent.FailureReason = ""
+		if intent, err = h.Repo.Update(intent); err != nil {
+			return repository.PaymentIntent{}, err
+		}
+		return intent, markOrderPaid(h.Orders, h.Repo, intent)
+	case payments.EventFailed:
+		if intent.Status != repository.PaymentPending && intent.Status != repository.PaymentAuthorized {
+			return intent, nil
+		}
+		intent.Status = repository.PaymentFailed
+		intent.FailureReason = event.Reason
+		return h.Repo.Update(intent)
+	case payments.EventRefunded:
+		if event.Amount <= intent.RefundedAmount {
+			return intent, nil
+		}
+		intent.RefundedAmount = event.Amount
+		if intent.RefundedAmount >= intent.Amount {
+			intent.Status = repository.PaymentRefunded
+		}
+		return h.Repo.Update(intent)
+	}
+	return intent, nil
+}
+
+// refundPayment refunds amount of a captured payment and records it. Payments made through the
+// provider are refunded through it; payments from a gift card or store credit go back onto the card.
+func refundPayment(repo repository.PaymentRepository, provider payments.Provider, cards repository.GiftCardRepository,
+	intent repository.PaymentIntent, amount money.Amount) (repository.PaymentIntent, error) {
+	remaining := intent.Amount - intent.RefundedAmount
+	if intent.Status != repository.PaymentCaptured || remaining <= 0 {
+		return repository.PaymentIntent{}, errNotRefundable
+	}
+	if amount <= 0 || amount > remaining {
+		return repository.PaymentIntent{}, fmt.Errorf("%w: refund must be between 0.01 and %s", errNotRefundable, remaining)
+	}
+	var err error
+	if paysFromBalance(intent) {
+		if cards == nil {
+			return repository.PaymentIntent{}, errors.New("gift cards are not configured")
+		}
+		_, err = cards.Apply(intent.ProviderRef, repository.GiftCardEntry{
+			Amount:  amount,
+			Reason:  repository.EntryRefunded,
+			OrderID: intent.OrderID,
+			Actor:   "payments",
+			Note:    "refund of payment " + intent.ID,
+		})
+	} else {
+		_, err = provider.Refund(intent.ProviderRef, amount)
+	}
+	if err != nil {
+		return repository.PaymentIntent{}, err
+	}
+	intent.RefundedAmount += amount
+	if intent.RefundedAmount >= intent.Amount {
+		intent.Status = repository.PaymentRefunded
+	}
+	return repo.Update(intent)
+}
+
+// PostRefund handles POST /admin/payments/:id/refund.
+func (h *PaymentHandler) PostRefund(c *gin.Context) {
+	var req RefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	intent, err := h.Repo.GetByID(c.Param("id"))
+	if errors.Is(err, repository.ErrPaymentNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "payment not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	amount := intent.Amount - intent.RefundedAmount
+	if req.Amount != nil {
+		amount = *req.Amount
+	}
+	refunded, err := refundPayment(h.Repo, h.Provider, h.GiftCards, intent, amount)
+	switch {
+	case errors.Is(err, errNotRefundable), errors.Is(err, payments.ErrInvalidAmount):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+	default:
+		c.IndentedJSON(http.StatusOK, refunded)
+	}
+}
@@ -0,0 +1,349 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/payments"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+type paymentTestFixture struct {
+	orderTestFixture
+	payments  *mockPaymentRepo
+	provider  *payments.FakeProvider
+	giftCards *mockGiftCardRepo
+}
+
+func setupPaymentRouter() paymentTestFixture {
+	f := paymentTestFixture{
+		orderTestFixture: setupOrderRouter(),
+		payments:         newMockPaymentRepo(),
+		provider:         payments.NewFakeProvider("test-secret"),
+		giftCards:        newMockGiftCardRepo(),
+	}
+	handler := NewPaymentHandler(f.payments, f.orders, f.provider)
+	handler.GiftCards = f.giftCards
+	f.router.POST("/orders/:id/payments", handler.PostPayment)
+	f.router.GET("/orders/:id/payments", handler.GetOrderPayments)
+	f.router.POST("/payments/webhook", handler.Webhook)
+	f.router.POST("/admin/payments/:id/refund", handler.PostRefund)
+	return f
+}
+
+func (f paymentTestFixture) pay(orderID, key, method string) *httptest.ResponseRecorder {
+	return f.payWith(orderID, key, `{"paymentMethod":"`+method+`"}`)
+}
+
+func (f paymentTestFixture) payWith(orderID, key, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/orders/"+orderID+"/payments", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", "user-1")
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	f.router.ServeHTTP(w, req)
+	return w
+}
+
+func (f paymentTestFixture) sendWebhook(t *testing.T, event payments.Event) *httptest.ResponseRecorder {
+	t.Helper()
+	payload, signature, err := f.provider.SignedEvent(event)
+	require.NoError(t, err)
+	return f.sendSignedWebhook(payload, signature)
+}
+
+func (f paymentTestFixture) sendSignedWebhook(payload []byte, signature string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/payments/webhook", bytes.NewReader(payload))
+	req.Header.Set("Payment-Signature", signature)
+	f.router.ServeHTTP(w, req)
+	return w
+}
+
+func Test_PostPayment_CapturesAndMarksOrderPaid(t *testing.T) {
+	f := setupPaymentRouter()
+	order := f.checkout(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 2})
+
+	w := f.pay(order.ID, "key-1", payments.FakeMethodSuccess)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	intent := decodeJSON[repository.PaymentIntent](t, w.Body.Bytes())
+	assert.Equal(t, repository.PaymentCaptured, intent.Status)
+	assert.Equal(t, money.MustParse("51.98"), intent.Amount)
+	assert.NotEmpty(t, intent.ProviderRef)
+	paid, _ := f.orders.GetByID(order.ID)
+	assert.Equal(t, repository.OrderPaid, paid.Status)
+}
+
+func Test_PostPayment_IsIdempotent(t *testing.T) {
+	f := setupPaymentRouter()
+	order := f.checkout(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+
+	first := decodeJSON[repository.PaymentIntent](t, f.pay(order.ID, "key-1", payments.FakeMethodSuccess).Body.Bytes())
+	w := f.pay(order.ID, "key-1", payments.FakeMethodSuccess)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, first.ID, decodeJSON[repository.PaymentIntent](t, w.Body.Bytes()).ID)
+	assert.Len(t, f.payments.intents, 1)
+}
+
+func Test_PostPayment_KeyReusedForAnotherOrder(t *testing.T) {
+	f := setupPaymentRouter()
+	first := f.checkout(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+	second := f.checkout(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+	f.pay(first.ID, "key-1", payments.FakeMethodSuccess)
+
+	w := f.pay(second.ID, "key-1", payments.FakeMethodSuccess)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func Test_PostPayment_RequiresIdempotencyKey(t *testing.T) {
+	f := setupPaymentRouter()
+	order := f.checkout(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+
+	w := f.pay(order.ID, "", payments.FakeMethodSuccess)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_PostPayment_Declined(t *testing.T) {
+	f := setupPaymentRouter()
+	order := f.checkout(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+
+	w := f.pay(order.ID, "key-1", payments.FakeMethodDeclined)
+
+	assert.Equal(t, http.StatusPaymentRequired, w.Code)
+	intent := decodeJSON[repository.PaymentIntent](t, w.Body.Bytes())
+	assert.Equal(t, repository.PaymentFailed, intent.Status)
+	assert.Contains(t, intent.FailureReason, "declined")
+	pending, _ := f.orders.GetByID(order.ID)
+	assert.Equal(t, repository.OrderPending, pending.Status)
+
+	w = f.pay(order.ID, "key-2", payments.FakeMethodSuccess)
+	assert.Equal(t, http.StatusCreated, w.Code, "a new key can retry after a decline")
+}
+
+func Test_PostPayment_OrderNotPending(t *testing.T) {
+	f := setupPaymentRouter()
+	order := f.checkout(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+	doRequest(f.router, "POST", "/orders/"+order.ID+"/cancel", "user-1", "")
+
+	w := f.pay(order.ID, "key-1", payments.FakeMethodSuccess)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func Test_PostPayment_AnotherPaymentInProgress(t *testing.T) {
+	f := setupPaymentRouter()
+	order := f.checkout(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+	_, err := f.payments.Create(repository.PaymentIntent{OrderID: order.ID, IdempotencyKey: "user-1:key-1", Amount: order.Total, Status: repository.PaymentAuthorized})
+	require.NoError(t, err)
+
+	w := f.pay(order.ID, "key-2", payments.FakeMethodSuccess)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Len(t, f.payments.intents, 1)
+}
+
+func Test_PostPayment_RechecksBalanceOnceClaimed(t *testing.T) {
+	f := setupPaymentRouter()
+	order := f.checkout(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+	// Another request captures the whole total after this one has read the balance.
+	f.payments.beforeCreate = func() {
+		f.payments.beforeCreate = nil
+		f.payments.intents = append(f.payments.intents, repository.PaymentIntent{
+			ID: "payment-0", OrderID: order.ID, IdempotencyKey: "user-1:key-1", Amount: order.Total, Status: repository.PaymentCaptured,
+		})
+	}
+
+	w := f.pay(order.ID, "key-2", payments.FakeMethodSuccess)
+
+	assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	require.Len(t, f.payments.intents, 2)
+	assert.Equal(t, repository.PaymentFailed, f.payments.intents[1].Status, "the second payment is never charged")
+}
+
+func Test_GetOrderPayments(t *testing.T) {
+	f := setupPaymentRouter()
+	order := f.checkout(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+	f.pay(order.ID, "key-1", payments.FakeMethodDeclined)
+	f.pay(order.ID, "key-2", payments.FakeMethodSuccess)
+
+	w := doRequest(f.router, "GET", "/orders/"+order.ID+"/payments", "user-1", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var intents []repository.PaymentIntent
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &intents))
+	assert.Len(t, intents, 2)
+	assert.Equal(t, http.StatusNotFound, doRequest(f.router, "GET", "/orders/"+order.ID+"/payments", "user-2", "").Code)
+}
+
+func Test_Webhook_CapturedAdvancesOrder(t *testing.T) {
+	f := setupPaymentRouter()
+	order := f.checkout(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+	intent, _ := f.payments.Create(repository.PaymentIntent{
+		OrderID: order.ID, IdempotencyKey: "k", Provider: "fake", ProviderRef: "fake_abc",
+		Amount: order.Total, Status: repository.PaymentAuthorized,
+	})
+	event := payments.Event{ID: "evt_1", Type: payments.EventCaptured, Reference: "fake_abc", Amount: order.Total}
+
+	w := f.sendWebhook(t, event)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = f.sendWebhook(t, event)
+	require.Equal(t, http.StatusOK, w.Code, "redelivered events are harmless")
+
+	updated, _ := f.payments.GetByID(intent.ID)
+	assert.Equal(t, repository.PaymentCaptured, updated.Status)
+	paid, _ := f.orders.GetByID(order.ID)
+	assert.Equal(t, repository.OrderPaid, paid.Status)
+	assert.Len(t, paid.History, 2)
+}
+
+func Test_Webhook_RejectsBadSignature(t *testing.T) {
+	f := setupPaymentRouter()
+	payload, signature, err := f.provider.SignedEvent(payments.Event{Type: payments.EventCaptured, Reference: "fake_abc"})
+	require.NoError(t, err)
+
+	w := f.sendSignedWebhook(append(payload, ' '), signature)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = f.sendSignedWebhook(payload, "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_Webhook_UnknownReferenceIsAcknowledged(t *testing.T) {
+	f := setupPaymentRouter()
+
+	w := f.sendWebhook(t, payments.Event{Type: payments.EventCaptured, Reference: "fake_unknown"})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "ignored")
+}
+
+func Test_PostRefund_PartialThenFull(t *testing.T) {
+	f := setupPaymentRouter()
+	order := f.checkout(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 2})
+	intent := decodeJSON[repository.PaymentIntent](t, f.pay(order.ID, "key-1", payments.FakeMethodSuccess).Body.Bytes())
+	path := "/admin/payments/" + intent.ID + "/refund"
+
+	w := doRequest(f.router, "POST", path, "staff-1", `{"amount":10}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	partial := decodeJSON[repository.PaymentIntent](t, w.Body.Bytes())
+	assert.Equal(t, money.MustParse("10.00"), partial.RefundedAmount)
+	assert.Equal(t, repository.PaymentCaptured, partial.Status)
+
+	w = doRequest(f.router, "POST", path, "staff-1", `{"amount":100}`)
+	assert.Equal(t, http.StatusConflict, w.Code, "cannot refund more than was paid")
+
+	w = doRequest(f.router, "POST", path, "staff-1", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	full := decodeJSON[repository.PaymentIntent](t, w.Body.Bytes())
+	assert.Equal(t, money.MustParse("51.98"), full.RefundedAmount)
+	assert.Equal(t, repository.PaymentRefunded, full.Status)
+}
+
+func Test_PostRefund_NotCaptured(t *testing.T) {
+	f := setupPaymentRouter()
+	order := f.checkout(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+	intent := decodeJSON[repository.PaymentIntent](t, f.pay(order.ID, "key-1", payments.FakeMethodDeclined).Body.Bytes())
+
+	w := doRequest(f.router, "POST", "/admin/payments/"+intent.ID+"/refund", "staff-1", "")
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func Test_PostRefund_UnknownPayment(t *testing.T) {
+	f := setupPaymentRouter()
+
+	w := doRequest(f.router, "POST", "/admin/payments/nope/refund", "staff-1", "")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_PostPayment_SplitsGiftCardAndCard(t *testing.T) {
+	f := setupPaymentRouter()
+	order := f.checkout(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 2})
+	card, err := f.giftCards.Create("ABCD-EFGH-JKMN-PQRS", money.MustParse("20.00"), "staff-1", "")
+	require.NoError(t, err)
+
+	w := f.payWith(order.ID, "key-1", `{"giftCardCode":"abcd efgh jkmn pqrs"}`)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	fromCard := decodeJSON[repository.PaymentIntent](t, w.Body.Bytes())
+	assert.Equal(t, "gift-card", fromCard.Provider)
+	assert.Equal(t, money.MustParse("20.00"), fromCard.Amount)
+	card, _ = f.giftCards.GetByID(card.ID)
+	assert.Equal(t, money.Amount(0), card.Balance)
+	pending, _ := f.orders.GetByID(order.ID)
+	assert.Equal(t, repository.OrderPending, pending.Status, "the order is not paid until the rest is")
+
+	w = f.pay(order.ID, "key-2", payments.FakeMethodSuccess)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, money.MustParse("31.98"), decodeJSON[repository.PaymentIntent](t, w.Body.Bytes()).Amount)
+	paid, _ := f.orders.GetByID(order.ID)
+	assert.Equal(t, repository.OrderPaid, paid.Status)
+
+	w = f.payWith(order.ID, "key-3", `{"giftCardCode":"ABCD-EFGH-JKMN-PQRS"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func Test_PostPayment_StoreCreditCoversOrder(t *testing.T) {
+	f := setupPaymentRouter()
+	order := f.checkout(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+
+	w := f.payWith(order.ID, "key-1", `{"storeCredit":true}`)
+	assert.Equal(t, http.StatusConflict, w.Code, "customers without store credit cannot pay with it")
+
+	credit, err := f.giftCards.AddStoreCredit("user-1", repository.GiftCardEntry{Amount: money.MustParse("100.00"), Reason: repository.EntryRefunded})
+	require.NoError(t, err)
+	w = f.payWith(order.ID, "key-2", `{"storeCredit":true}`)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, money.MustParse("25.99"), decodeJSON[repository.PaymentIntent](t, w.Body.Bytes()).Amount)
+	credit, _ = f.giftCards.GetByID(credit.ID)
+	assert.Equal(t, money.MustParse("74.01"), credit.Balance)
+	paid, _ := f.orders.GetByID(order.ID)
+	assert.Equal(t, repository.OrderPaid, paid.Status)
+}
+
+func Test_PostPayment_NeedsOneWayToPay(t *testing.T) {
+	f := setupPaymentRouter()
+	order := f.checkout(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+
+	for name, body := range map[string]string{
+		"nothing": `{}`,
+		"two":     `{"paymentMethod":"tok_visa","storeCredit":true}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, http.StatusBadRequest, f.payWith(order.ID, "key-"+name, body).Code)
+		})
+	}
+	w := f.payWith(order.ID, "key-unknown", `{"giftCardCode":"NOPE-NOPE-NOPE-NOPE"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_PostRefund_GiftCardPaymentGoesBackOnCard(t *testing.T) {
+	f := setupPaymentRouter()
+	order := f.checkout(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+	card, _ := f.giftCards.Create("ABCD-EFGH-JKMN-PQRS", money.MustParse("50.00"), "staff-1", "")
+	intent := decodeJSON[repository.PaymentIntent](t, f.payWith(order.ID, "key-1", `{"giftCardCode":"ABCD-EFGH-JKMN-PQRS"}`).Body.Bytes())
+
+	w := doRequest(f.router, "POST", "/admin/payments/"+intent.ID+"/refund", "staff-1", "")
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, repository.PaymentRefunded, decodeJSON[repository.PaymentIntent](t, w.Body.Bytes()).Status)
+	card, _ = f.giftCards.GetByID(card.ID)
+	assert.Equal(t, money.MustParse("50.00"), card.Balance)
+	entries, _ := f.giftCards.Entries(card.ID)
+	require.Len(t, entries, 3)
+	assert.Equal(t, repository.EntryRefunded, entries[2].Reason)
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// In-memory mock implementation of PaymentRepository for testing
+
+type mockPaymentRepo struct {
+	intents []repository.PaymentIntent
+
+	// beforeCreate, if set, runs at the start of Create, standing in for a concurrent request.
+	beforeCreate func()
+}
+
+func newMockPaymentRepo() *mockPaymentRepo {
+	return &mockPaymentRepo{}
+}
+
+func (m *mockPaymentRepo) Create(intent repository.PaymentIntent) (repository.PaymentIntent, error) {
+	if m.beforeCreate != nil {
+		m.beforeCreate()
+	}
+	for _, existing := range m.intents {
+		if existing.IdempotencyKey == intent.IdempotencyKey {
+			return repository.PaymentIntent{}, repository.ErrDuplicateIdempotencyKey
+		}
+		if existing.OrderID == intent.OrderID && (existing.Status == repository.PaymentPending || existing.Status == repository.PaymentAuthorized) {
+			return repository.PaymentIntent{}, repository.ErrPaymentInProgress
+		}
+	}
+	intent.ID = fmt.Sprintf("payment-%d", len(m.intents)+1)
+	intent.CreatedAt = time.Now()
+	intent.UpdatedAt = intent.CreatedAt
+	m.intents = append(m.intents, intent)
+	return intent, nil
+}
+
+func (m *mockPaymentRepo) find(match func(repository.PaymentIntent) bool) (repository.PaymentIntent, error) {
+	for _, intent := range m.intents {
+		if match(intent) {
+			return intent, nil
+		}
+	}
+	return repository.PaymentIntent{}, repository.ErrPaymentNotFound
+}
+
+func (m *mockPaymentRepo) GetByID(id string) (repository.PaymentIntent, error) {
+	return m.find(func(intent repository.PaymentIntent) bool { return intent.ID == id })
+}
+
+func (m *mockPaymentRepo) GetByIdempotencyKey(key string) (repository.PaymentIntent, error) {
+	return m.find(func(intent repository.PaymentIntent) bool { return intent.IdempotencyKey == key })
+}
+
+func (m *mockPaymentRepo) GetByProviderRef(provider, ref string) (repository.PaymentIntent, error) {
+	return m.find(func(intent repository.PaymentIntent) bool {
+		return intent.Provider == provider && intent.ProviderRef == ref
+	})
+}
+
+func (m *mockPaymentRepo) ListByOrder(orderID string) ([]repository.PaymentIntent, error) {
+	var intents []repository.PaymentIntent
+	for _, intent := range m.intents {
+		if intent.OrderID == orderID {
+			intents = append(intents, intent)
+		}
+	}
+	return intents, nil
+}
+
+func (m *mockPaymentRepo) Update(intent repository.PaymentIntent) (repository.PaymentIntent, error) {
+	for i, existing := range m.intents {
+		if existing.ID == intent.ID {
+			intent.IdempotencyKey = existing.IdempotencyKey
+			intent.UpdatedAt = time.Now()
+			m.intents[i] = intent
+			return intent, nil
+		}
+	}
+	return repository.PaymentIntent{}, repository.ErrPaymentNotFound
+}
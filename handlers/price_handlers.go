@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+type PriceHandler struct {
+	Repo   repository.PriceRepository
+	Albums repository.AlbumRepository
+}
+
+func NewPriceHandler(repo repository.PriceRepository, albums repository.AlbumRepository) *PriceHandler {
+	return &PriceHandler{
+		Repo:   repo,
+		Albums: albums,
+	}
+}
+
+// PriceHistoryResponse is returned by GET /albums/:id/prices.
+type PriceHistoryResponse struct {
+	AlbumID   string                      `json:"albumId"`
+	Price     money.Amount                `json:"price"`
+	Currency  string                      `json:"currency"`
+	History   []repository.PriceChange    `json:"history"`
+	Scheduled []repository.ScheduledPrice `json:"scheduled"`
+}
+
+// SchedulePriceRequest is the body accepted by POST /albums/:id/prices/scheduled.
+type SchedulePriceRequest struct {
+	Price       *money.Amount `json:"price" binding:"required"`
+	EffectiveAt time.Time     `json:"effectiveAt" binding:"required"`
+	Note        string        `json:"note"`
+}
+
+// GetPrices handles GET /albums/:id/prices, returning the current price, every past change newest
+// first, and scheduled changes in the order they take effect.
+func (h *PriceHandler) GetPrices(c *gin.Context) {
+	id, ok := getAlbumIDFromUri(c)
+	if !ok {
+		return
+	}
+	album, err := h.Albums.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "album not found"})
+		return
+	}
+	history, err := h.Repo.ListChanges(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	scheduled, err := h.Repo.ListScheduled(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if history == nil {
+		history = []repository.PriceChange{}
+	}
+	if scheduled == nil {
+		scheduled = []repository.ScheduledPrice{}
+	}
+	c.IndentedJSON(http.StatusOK, PriceHistoryResponse{AlbumID: album.ID, Price: album.Price, Currency: album.Currency, History: history, Scheduled: scheduled})
+}
+
+// PostScheduledPrice handles POST /albums/:id/prices/scheduled, letting staff schedule a price
+// change, such as the start or end of a sale, for a future time.
+func (h *PriceHandler) PostScheduledPrice(c *gin.Context) {
+	id, ok := getExistingAlbumID(c, h.Albums)
+	if !ok {
+		return
+	}
+	var req SchedulePriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if *req.Price < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "price cannot be negative"})
+		return
+	}
+	if !req.EffectiveAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "effectiveAt must be in the future"})
+		return
+	}
+	scheduled, err := h.Repo.Schedule(repository.ScheduledPrice{
+		AlbumID:     id,
+		Price:       *req.Price,
+		EffectiveAt: req.EffectiveAt.UTC(),
+		Actor:       currentUserID(c),
+		Note:        req.Note,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusCreated, scheduled)
+}
+
+// DeleteScheduledPrice handles DELETE /albums/:id/prices/scheduled/:scheduleId, cancelling a change
+// that has not taken effect yet. The cancelled change is kept for the audit trail.
+func (h *PriceHandler) DeleteScheduledPrice(c *gin.Context) {
+	scheduled, err := h.Repo.GetScheduled(c.Param("scheduleId"))
+	if err == nil && scheduled.AlbumID != c.Param("id") {
+		err = repository.ErrScheduledPriceNotFound
+	}
+	if err == nil {
+		err = h.Repo.SetScheduleStatus(scheduled.ID, repository.SchedulePending, repository.ScheduleCancelled)
+	}
+	switch {
+	case errors.Is(err, repository.ErrScheduledPriceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "scheduled price change not found"})
+		return
+	case errors.Is(err, repository.ErrScheduleStatusChanged):
+		c.JSON(http.StatusConflict, gin.H{"error": "only pending price changes can be cancelled"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	cancelled, err := h.Repo.GetScheduled(scheduled.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, cancelled)
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+func setupPriceRouter() (*gin.Engine, *mockPriceRepo) {
+	prices := newMockPriceRepo()
+	albums := newTestHandler()
+	albums.Prices = prices
+	handler := NewPriceHandler(prices, albums.Repo)
+
+	r := gin.Default()
+	r.PUT("/albums/:id", albums.PutAlbum)
+	r.GET("/albums/:id/prices", handler.GetPrices)
+	r.POST("/albums/:id/prices/scheduled", handler.PostScheduledPrice)
+	r.DELETE("/albums/:id/prices/scheduled/:scheduleId", handler.DeleteScheduledPrice)
+	return r, prices
+}
+
+func getPriceHistory(t *testing.T, r *gin.Engine, albumID string) PriceHistoryResponse {
+	t.Helper()
+	w := doRequest(r, "GET", "/albums/"+albumID+"/prices", "", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var history PriceHistoryResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+	return history
+}
+
+func Test_PutAlbum_RecordsPriceChange(t *testing.T) {
+	r, _ := setupPriceRouter()
+	doRequest(r, "PUT", "/albums/1", "staff-1", `{"title":"Thriller (Remastered)","artist":"Michael Jackson","price":25.99,"year":1982,"imageUrl":"https://example.com/thriller.jpg","genre":"Pop"}`)
+	w := doRequest(r, "PUT", "/albums/1", "staff-1", `{"title":"Thriller","artist":"Michael Jackson","price":19.99,"year":1982,"imageUrl":"https://example.com/thriller.jpg","genre":"Pop"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	history := getPriceHistory(t, r, "1")
+	assert.Equal(t, money.MustParse("19.99"), history.Price)
+	require.Len(t, history.History, 1, "edits that keep the price are not recorded")
+	assert.Equal(t, money.MustParse("25.99"), history.History[0].OldPrice)
+	assert.Equal(t, money.MustParse("19.99"), history.History[0].NewPrice)
+	assert.Equal(t, repository.PriceSourceManual, history.History[0].Source)
+	assert.Equal(t, "staff-1", history.History[0].Actor)
+}
+
+func Test_GetPrices_UnknownAlbum(t *testing.T) {
+	r, _ := setupPriceRouter()
+
+	w := doRequest(r, "GET", "/albums/999/prices", "", "")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_PostScheduledPrice(t *testing.T) {
+	r, _ := setupPriceRouter()
+	midnight := time.Now().Add(24 * time.Hour).Truncate(24 * time.Hour).Format(time.RFC3339)
+
+	w := doRequest(r, "POST", "/albums/2/prices/scheduled", "staff-1", `{"price":30,"effectiveAt":"`+midnight+`","note":"weekend sale"}`)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var scheduled repository.ScheduledPrice
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &scheduled))
+	assert.Equal(t, money.MustParse("30.00"), scheduled.Price)
+	assert.Equal(t, repository.SchedulePending, scheduled.Status)
+	assert.Equal(t, "staff-1", scheduled.Actor)
+
+	history := getPriceHistory(t, r, "2")
+	assert.Equal(t, money.MustParse("42.50"), history.Price, "the price does not change until the effective time")
+	assert.Len(t, history.Scheduled, 1)
+}
+
+func Test_PostScheduledPrice_Validation(t *testing.T) {
+	r, _ := setupPriceRouter()
+	future := time.Now().Add(time.Hour).Format(time.RFC3339)
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+
+	assert.Equal(t, http.StatusBadRequest, doRequest(r, "POST", "/albums/2/prices/scheduled", "staff-1", `{"effectiveAt":"`+future+`"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, doRequest(r, "POST", "/albums/2/prices/scheduled", "staff-1", `{"price":-1,"effectiveAt":"`+future+`"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, doRequest(r, "POST", "/albums/2/prices/scheduled", "staff-1", `{"price":29.999,"effectiveAt":"`+future+`"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, doRequest(r, "POST", "/albums/2/prices/scheduled", "staff-1", `{"price":10,"effectiveAt":"`+past+`"}`).Code)
+	assert.Equal(t, http.StatusNotFound, doRequest(r, "POST", "/albums/999/prices/scheduled", "staff-1", `{"price":10,"effectiveAt":"`+future+`"}`).Code)
+}
+
+func Test_DeleteScheduledPrice(t *testing.T) {
+	r, prices := setupPriceRouter()
+	scheduled, _ := prices.Schedule(repository.ScheduledPrice{AlbumID: "2", Price: money.MustParse("30.00"), EffectiveAt: time.Now().Add(time.Hour)})
+
+	assert.Equal(t, http.StatusNotFound, doRequest(r, "DELETE", "/albums/1/prices/scheduled/"+scheduled.ID, "staff-1", "").Code, "the change belongs to another album")
+
+	w := doRequest(r, "DELETE", "/albums/2/prices/scheduled/"+scheduled.ID, "staff-1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status": "cancelled"`)
+
+	w = doRequest(r, "DELETE", "/albums/2/prices/scheduled/"+scheduled.ID, "staff-1", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
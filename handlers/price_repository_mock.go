@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// In-memory mock implementation of PriceRepository for testing
+
+type mockPriceRepo struct {
+	changes   []repository.PriceChange
+	scheduled []repository.ScheduledPrice
+}
+
+func newMockPriceRepo() *mockPriceRepo {
+	return &mockPriceRepo{}
+}
+
+func (m *mockPriceRepo) RecordChange(change repository.PriceChange) (repository.PriceChange, error) {
+	change.ID = fmt.Sprintf("change-%d", len(m.changes)+1)
+	change.ChangedAt = time.Now()
+	m.changes = append(m.changes, change)
+	return change, nil
+}
+
+func (m *mockPriceRepo) ListChanges(albumID string) ([]repository.PriceChange, error) {
+	var changes []repository.PriceChange
+	for i := len(m.changes) - 1; i >= 0; i-- {
+		if m.changes[i].AlbumID == albumID {
+			changes = append(changes, m.changes[i])
+		}
+	}
+	return changes, nil
+}
+
+func (m *mockPriceRepo) Schedule(scheduled repository.ScheduledPrice) (repository.ScheduledPrice, error) {
+	scheduled.ID = fmt.Sprintf("schedule-%d", len(m.scheduled)+1)
+	scheduled.Status = repository.SchedulePending
+	scheduled.CreatedAt = time.Now()
+	scheduled.UpdatedAt = scheduled.CreatedAt
+	m.scheduled = append(m.scheduled, scheduled)
+	return scheduled, nil
+}
+
+func (m *mockPriceRepo) GetScheduled(id string) (repository.ScheduledPrice, error) {
+	for _, scheduled := range m.scheduled {
+		if scheduled.ID == id {
+			return scheduled, nil
+		}
+	}
+	return repository.ScheduledPrice{}, repository.ErrScheduledPriceNotFound
+}
+
+func (m *mockPriceRepo) ListScheduled(albumID string) ([]repository.ScheduledPrice, error) {
+	var scheduled []repository.ScheduledPrice
+	for _, s := range m.scheduled {
+		if s.AlbumID == albumID {
+			scheduled = append(scheduled, s)
+		}
+	}
+	sort.SliceStable(scheduled, func(i, j int) bool { return scheduled[i].EffectiveAt.Before(scheduled[j].EffectiveAt) })
+	return scheduled, nil
+}
+
+func (m *mockPriceRepo) Due(now time.Time) ([]repository.ScheduledPrice, error) {
+	var due []repository.ScheduledPrice
+	for _, s := range m.scheduled {
+		if s.Status == repository.SchedulePending && !s.EffectiveAt.After(now) {
+			due = append(due, s)
+		}
+	}
+	return due, nil
+}
+
+func (m *mockPriceRepo) SetScheduleStatus(id string, from, to repository.ScheduleStatus) error {
+	for i, s := range m.scheduled {
+		if s.ID != id {
+			continue
+		}
+		if s.Status != from {
+			return repository.ErrScheduleStatusChanged
+		}
+		m.scheduled[i].Status = to
+		m.scheduled[i].UpdatedAt = time.Now()
+		return nil
+	}
+	return repository.ErrScheduledPriceNotFound
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+type PromotionHandler struct {
+	Repo repository.PromotionRepository
+}
+
+func NewPromotionHandler(repo repository.PromotionRepository) *PromotionHandler {
+	return &PromotionHandler{Repo: repo}
+}
+
+// PromotionRequest is the body accepted by POST and PUT /admin/promotions. Leave Code empty for a
+// promotion that applies automatically. Active defaults to true.
+type PromotionRequest struct {
+	Name       string                    `json:"name" binding:"required"`
+	Code       string                    `json:"code"`
+	Kind       repository.DiscountKind   `json:"kind" binding:"required"`
+	Value      money.Amount              `json:"value"`
+	Scope      repository.PromotionScope `json:"scope"`
+	StartsAt   *time.Time                `json:"startsAt"`
+	EndsAt     *time.Time                `json:"endsAt"`
+	UsageLimit int                       `json:"usageLimit"`
+	Active     *bool                     `json:"active"`
+}
+
+func (req PromotionRequest) promotion() repository.Promotion {
+	promotion := repository.Promotion{
+		Name:       strings.TrimSpace(req.Name),
+		Code:       repository.NormalizeCode(req.Code),
+		Kind:       repository.DiscountKind(strings.ToLower(string(req.Kind))),
+		Value:      req.Value,
+		Scope:      req.Scope,
+		StartsAt:   req.StartsAt,
+		EndsAt:     req.EndsAt,
+		UsageLimit: req.UsageLimit,
+		Active:     true,
+	}
+	if req.Active != nil {
+		promotion.Active = *req.Active
+	}
+	return promotion
+}
+
+// loadPromotions returns every promotion, or none when promotions are not configured.
+// Which of them currently apply is decided by the promotions engine.
+func loadPromotions(repo repository.PromotionRepository) ([]repository.Promotion, error) {
+	if repo == nil {
+		return nil, nil
+	}
+	return repo.List()
+}
+
+func respondWithPromotionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrPromotionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "promotion not found"})
+	case errors.Is(err, repository.ErrDuplicateCode):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// ListPromotions handles GET /admin/promotions.
+func (h *PromotionHandler) ListPromotions(c *gin.Context) {
+	promotions, err := h.Repo.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if promotions == nil {
+		promotions = []repository.Promotion{}
+	}
+	c.IndentedJSON(http.StatusOK, promotions)
+}
+
+// GetPromotion handles GET /admin/promotions/:id.
+func (h *PromotionHandler) GetPromotion(c *gin.Context) {
+	promotion, err := h.Repo.GetByID(c.Param("id"))
+	if err != nil {
+		respondWithPromotionError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, promotion)
+}
+
+// bindPromotion reads and validates a PromotionRequest, writing a 400 response on failure.
+func bindPromotion(c *gin.Context) (repository.Promotion, bool) {
+	var req PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return repository.Promotion{}, false
+	}
+	promotion := req.promotion()
+	if err := promotion.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return repository.Promotion{}, false
+	}
+	return promotion, true
+}
+
+// PostPromotion handles POST /admin/promotions.
+func (h *PromotionHandler) PostPromotion(c *gin.Context) {
+	promotion, ok := bindPromotion(c)
+	if !ok {
+		return
+	}
+	created, err := h.Repo.Create(promotion)
+	if err != nil {
+		respondWithPromotionError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusCreated, created)
+}
+
+// PutPromotion handles PUT /admin/promotions/:id. The coupon code cannot be changed.
+func (h *PromotionHandler) PutPromotion(c *gin.Context) {
+	promotion, ok := bindPromotion(c)
+	if !ok {
+		return
+	}
+	promotion.ID = c.Param("id")
+	updated, err := h.Repo.Update(promotion)
+	if err != nil {
+		respondWithPromotionError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, updated)
+}
+
+// DeletePromotion handles DELETE /admin/promotions/:id.
+func (h *PromotionHandler) DeletePromotion(c *gin.Context) {
+	if err := h.Repo.Delete(c.Param("id")); err != nil {
+		respondWithPromotionError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+var motownWeekend = repository.Promotion{
+	Name:   "20% off all Motown",
+	Kind:   repository.DiscountPercentage,
+	Value:  money.MustParse("20"),
+	Scope:  repository.PromotionScope{Genres: []string{"Motown"}},
+	Active: true,
+}
+
+func setupPromotionRouter(promotions *mockPromotionRepo) orderTestFixture {
+	f := setupOrderRouter()
+	carts := NewCartHandler(f.carts, f.albums, f.inventory)
+	carts.Promotions = promotions
+	orders := NewOrderHandler(f.orders, f.carts, f.albums)
+	orders.Promotions = promotions
+	admin := NewPromotionHandler(promotions)
+	albums := newTestHandler()
+	albums.Promotions = promotions
+
+	r := gin.Default()
+	r.GET("/albums", albums.GetAlbums)
+	r.GET("/albums/:id", albums.GetAlbumByID)
+	r.GET("/carts/:id", carts.GetCart)
+	r.PUT("/carts/:id/coupon", carts.PutCoupon)
+	r.DELETE("/carts/:id/coupon", carts.DeleteCoupon)
+	r.POST("/checkout", orders.Checkout)
+	r.GET("/admin/promotions", admin.ListPromotions)
+	r.POST("/admin/promotions", admin.PostPromotion)
+	r.GET("/admin/promotions/:id", admin.GetPromotion)
+	r.PUT("/admin/promotions/:id", admin.PutPromotion)
+	r.DELETE("/admin/promotions/:id", admin.DeletePromotion)
+	f.router = r
+	return f
+}
+
+func Test_PostPromotion_CreatesAndNormalisesCode(t *testing.T) {
+	promotions := newMockPromotionRepo()
+	f := setupPromotionRouter(promotions)
+
+	w := doRequest(f.router, "POST", "/admin/promotions", "staff-1",
+		`{"name":"Summer","code":" summer10 ","kind":"fixed","value":10,"usageLimit":100}`)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created repository.Promotion
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "SUMMER10", created.Code)
+	assert.True(t, created.Active)
+
+	w = doRequest(f.router, "POST", "/admin/promotions", "staff-1", `{"name":"Again","code":"SUMMER10","kind":"fixed","value":5}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func Test_PostPromotion_Validation(t *testing.T) {
+	f := setupPromotionRouter(newMockPromotionRepo())
+
+	testCases := map[string]string{
+		"unknown kind":      `{"name":"x","kind":"free","value":1}`,
+		"percentage > 100":  `{"name":"x","kind":"percentage","value":150}`,
+		"negative fixed":    `{"name":"x","kind":"fixed","value":-1}`,
+		"inverted years":    `{"name":"x","kind":"bogo","scope":{"yearFrom":1980,"yearTo":1970}}`,
+		"ends before start": `{"name":"x","kind":"bogo","startsAt":"2025-06-02T00:00:00Z","endsAt":"2025-06-01T00:00:00Z"}`,
+	}
+	for name, body := range testCases {
+		t.Run(name, func(t *testing.T) {
+			w := doRequest(f.router, "POST", "/admin/promotions", "staff-1", body)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func Test_PutPromotion_Deactivate(t *testing.T) {
+	promotions := newMockPromotionRepo(motownWeekend)
+	f := setupPromotionRouter(promotions)
+
+	w := doRequest(f.router, "PUT", "/admin/promotions/promo-1", "staff-1",
+		`{"name":"20% off all Motown","kind":"percentage","value":20,"scope":{"genres":["Motown"]},"active":false}`)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.False(t, promotions.promotions[0].Active)
+	assert.Equal(t, http.StatusNotFound, doRequest(f.router, "PUT", "/admin/promotions/nope", "staff-1", `{"name":"x","kind":"bogo"}`).Code)
+}
+
+func Test_DeletePromotion(t *testing.T) {
+	promotions := newMockPromotionRepo(motownWeekend)
+	f := setupPromotionRouter(promotions)
+
+	assert.Equal(t, http.StatusNoContent, doRequest(f.router, "DELETE", "/admin/promotions/promo-1", "staff-1", "").Code)
+	assert.Equal(t, http.StatusNotFound, doRequest(f.router, "GET", "/admin/promotions/promo-1", "staff-1", "").Code)
+}
+
+func Test_GetAlbums_ShowsSalePrice(t *testing.T) {
+	f := setupPromotionRouter(newMockPromotionRepo(motownWeekend))
+
+	w := doRequest(f.router, "GET", "/albums/2", "", "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var album repository.Album
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &album))
+	require.NotNil(t, album.SalePrice)
+	assert.Equal(t, money.MustParse("34.00"), *album.SalePrice)
+	assert.Equal(t, money.MustParse("42.50"), album.Price, "the list price is unchanged")
+	assert.Equal(t, "20% off all Motown", album.Promotion)
+
+	w = doRequest(f.router, "GET", "/albums/1", "", "")
+	assert.NotContains(t, w.Body.String(), "salePrice")
+}
+
+func Test_GetCart_AppliesAutomaticPromotion(t *testing.T) {
+	f := setupPromotionRouter(newMockPromotionRepo(motownWeekend))
+	cart, _ := f.carts.Create(repository.Cart{Items: []repository.CartItem{
+		{AlbumID: "1", Format: repository.FormatLP, Quantity: 1},
+		{AlbumID: "2", Format: repository.FormatCD, Quantity: 2},
+	}})
+
+	w := doRequest(f.router, "GET", "/carts/"+cart.ID, "", "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	priced := decodeJSON[repository.Cart](t, w.Body.Bytes())
+	assert.Equal(t, money.MustParse("110.99"), priced.Subtotal)
+	assert.Equal(t, money.MustParse("17.00"), priced.Discount)
+	assert.Equal(t, money.MustParse("93.99"), priced.Total)
+	assert.Equal(t, money.MustParse("68.00"), priced.Items[1].LineTotal)
+	assert.Equal(t, "20% off all Motown", priced.Items[1].Promotion)
+	assert.Zero(t, priced.Items[0].Discount)
+}
+
+func Test_Promotions_ApplyToSubGenres(t *testing.T) {
+	soulSale := repository.Promotion{Name: "10% off Soul", Kind: repository.DiscountPercentage, Value: money.MustParse("10"), Active: true,
+		Scope: repository.PromotionScope{Genres: []string{"Soul"}}}
+	promotions := newMockPromotionRepo(soulSale)
+	f := setupOrderRouter()
+	carts := NewCartHandler(f.carts, f.albums, f.inventory)
+	carts.Promotions = promotions
+	carts.Genres = newMockGenreRepo()
+	albums := newTestHandler()
+	albums.Promotions = promotions
+	albums.Genres = carts.Genres
+	r := gin.Default()
+	r.GET("/albums/:id", albums.GetAlbumByID)
+	r.GET("/carts/:id", carts.GetCart)
+	cart, _ := f.carts.Create(repository.Cart{Items: []repository.CartItem{{AlbumID: "2", Format: repository.FormatCD, Quantity: 1}}})
+
+	w := doRequest(r, "GET", "/albums/2", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	album := decodeJSON[repository.Album](t, w.Body.Bytes())
+	require.NotNil(t, album.SalePrice, "Motown is filed under Soul")
+	assert.Equal(t, money.MustParse("38.25"), *album.SalePrice)
+
+	w = doRequest(r, "GET", "/carts/"+cart.ID, "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	priced := decodeJSON[repository.Cart](t, w.Body.Bytes())
+	assert.Equal(t, money.MustParse("4.25"), priced.Discount)
+	assert.Equal(t, "10% off Soul", priced.Items[0].Promotion)
+}
+
+func Test_PutCoupon(t *testing.T) {
+	yesterday := time.Now().Add(-24 * time.Hour)
+	f := setupPromotionRouter(newMockPromotionRepo(
+		repository.Promotion{Name: "Tenner off", Code: "TENNER", Kind: repository.DiscountFixed, Value: money.MustParse("10"), Active: true},
+		repository.Promotion{Name: "Old", Code: "OLD", Kind: repository.DiscountFixed, Value: money.MustParse("10"), Active: true, EndsAt: &yesterday},
+	))
+	cart, _ := f.carts.Create(repository.Cart{Items: []repository.CartItem{{AlbumID: "1", Format: repository.FormatLP, Quantity: 1}}})
+	path := "/carts/" + cart.ID + "/coupon"
+
+	assert.Equal(t, http.StatusNotFound, doRequest(f.router, "PUT", path, "", `{"code":"NOPE"}`).Code)
+	assert.Equal(t, http.StatusConflict, doRequest(f.router, "PUT", path, "", `{"code":"old"}`).Code)
+
+	w := doRequest(f.router, "PUT", path, "", `{"code":"tenner"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	priced := decodeJSON[repository.Cart](t, w.Body.Bytes())
+	assert.Equal(t, "TENNER", priced.CouponCode)
+	assert.Equal(t, money.MustParse("15.99"), priced.Total)
+
+	w = doRequest(f.router, "DELETE", path, "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, money.MustParse("25.99"), decodeJSON[repository.Cart](t, w.Body.Bytes()).Total)
+}
+
+func Test_Checkout_AppliesAndRedeemsPromotions(t *testing.T) {
+	promotions := newMockPromotionRepo(
+		motownWeekend,
+		repository.Promotion{Name: "Tenner off", Code: "TENNER", Kind: repository.DiscountFixed, Value: money.MustParse("10"), Active: true, UsageLimit: 1},
+	)
+	f := setupPromotionRouter(promotions)
+	cart, _ := f.carts.Create(repository.Cart{CouponCode: "TENNER", Items: []repository.CartItem{
+		{AlbumID: "1", Format: repository.FormatLP, Quantity: 1},
+		{AlbumID: "2", Format: repository.FormatCD, Quantity: 1},
+	}})
+
+	w := doRequest(f.router, "POST", "/checkout", "user-1", `{"cartId":"`+cart.ID+`"}`)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var order repository.Order
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &order))
+	assert.Equal(t, "TENNER", order.CouponCode)
+	assert.Equal(t, money.MustParse("15.99"), order.Lines[0].LineTotal)
+	assert.Equal(t, "Tenner off", order.Lines[0].Promotion)
+	assert.Equal(t, money.MustParse("10.00"), order.Lines[1].Discount, "the coupon beats the 20% Motown discount on the second line too")
+	assert.Equal(t, money.MustParse("20.00"), order.Discount)
+	assert.Equal(t, money.MustParse("48.49"), order.Total)
+	assert.Equal(t, 1, promotions.promotions[1].UsageCount, "a promotion is redeemed once per order")
+	assert.Equal(t, 0, promotions.promotions[0].UsageCount)
+}
+
+func Test_Checkout_PromotionUsageLimitReached(t *testing.T) {
+	promotions := newMockPromotionRepo(repository.Promotion{Name: "Tenner off", Code: "TENNER", Kind: repository.DiscountFixed, Value: money.MustParse("10"), Active: true, UsageLimit: 1})
+	f := setupPromotionRouter(promotions)
+	first, _ := f.carts.Create(repository.Cart{CouponCode: "TENNER", Items: []repository.CartItem{{AlbumID: "1", Format: repository.FormatLP, Quantity: 1}}})
+	second, _ := f.carts.Create(repository.Cart{CouponCode: "TENNER", Items: []repository.CartItem{{AlbumID: "1", Format: repository.FormatLP, Quantity: 1}}})
+
+	require.Equal(t, http.StatusCreated, doRequest(f.router, "POST", "/checkout", "user-1", `{"cartId":"`+first.ID+`"}`).Code)
+	assert.Equal(t, 1, promotions.promotions[0].UsageCount)
+
+	w := doRequest(f.router, "POST", "/checkout", "user-1", `{"cartId":"`+second.ID+`"}`)
+	require.Equal(t, http.StatusCreated, w.Code, "once used up the coupon is simply not applied")
+	var order repository.Order
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &order))
+	assert.Equal(t, money.MustParse("25.99"), order.Total)
+	assert.Empty(t, order.CouponCode)
+}
+
+// exhaustedPromotionRepo simulates another checkout using the last redemption between pricing and redeeming.
+type exhaustedPromotionRepo struct {
+	*mockPromotionRepo
+}
+
+func (exhaustedPromotionRepo) Redeem(string) error {
+	return repository.ErrPromotionExhausted
+}
+
+func Test_Checkout_PromotionRedeemedConcurrently(t *testing.T) {
+	f := setupOrderRouter()
+	handler := NewOrderHandler(f.orders, f.carts, f.albums)
+	handler.Promotions = exhaustedPromotionRepo{newMockPromotionRepo(motownWeekend)}
+	r := gin.Default()
+	r.POST("/checkout", handler.Checkout)
+	cart, _ := f.carts.Create(repository.Cart{Items: []repository.CartItem{{AlbumID: "2", Format: repository.FormatCD, Quantity: 1}}})
+
+	w := doRequest(r, "POST", "/checkout", "user-1", `{"cartId":"`+cart.ID+`"}`)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "no longer available")
+	assert.Empty(t, f.orders.orders)
+	assert.Equal(t, 1, f.inventory.level("2", repository.FormatCD).Reserved, "no stock is reserved")
+}
+
+func Test_Checkout_ReleasesRedemptionWhenOrderFails(t *testing.T) {
+	promotions := newMockPromotionRepo(motownWeekend)
+	f := setupPromotionRouter(promotions)
+	cart, _ := f.carts.Create(repository.Cart{Items: []repository.CartItem{{AlbumID: "2", Format: repository.FormatCD, Quantity: 5}}})
+
+	w := doRequest(f.router, "POST", "/checkout", "user-1", `{"cartId":"`+cart.ID+`"}`)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, 0, promotions.promotions[0].UsageCount)
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// In-memory mock implementation of PromotionRepository for testing
+
+type mockPromotionRepo struct {
+	promotions []repository.Promotion
+	nextID     int
+}
+
+func newMockPromotionRepo(promotions ...repository.Promotion) *mockPromotionRepo {
+	m := &mockPromotionRepo{}
+	for _, promotion := range promotions {
+		_, _ = m.Create(promotion)
+	}
+	return m
+}
+
+func (m *mockPromotionRepo) Create(promotion repository.Promotion) (repository.Promotion, error) {
+	promotion.Code = repository.NormalizeCode(promotion.Code)
+	if promotion.Code != "" {
+		if _, err := m.GetByCode(promotion.Code); err == nil {
+			return repository.Promotion{}, repository.ErrDuplicateCode
+		}
+	}
+	m.nextID++
+	promotion.ID = fmt.Sprintf("promo-%d", m.nextID)
+	promotion.CreatedAt = time.Now()
+	m.promotions = append(m.promotions, promotion)
+	return promotion, nil
+}
+
+func (m *mockPromotionRepo) index(id string) int {
+	for i, promotion := range m.promotions {
+		if promotion.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *mockPromotionRepo) GetByID(id string) (repository.Promotion, error) {
+	if i := m.index(id); i >= 0 {
+		return m.promotions[i], nil
+	}
+	return repository.Promotion{}, repository.ErrPromotionNotFound
+}
+
+func (m *mockPromotionRepo) GetByCode(code string) (repository.Promotion, error) {
+	for _, promotion := range m.promotions {
+		if promotion.Code != "" && promotion.Code == repository.NormalizeCode(code) {
+			return promotion, nil
+		}
+	}
+	return repository.Promotion{}, repository.ErrPromotionNotFound
+}
+
+func (m *mockPromotionRepo) List() ([]repository.Promotion, error) {
+	return append([]repository.Promotion(nil), m.promotions...), nil
+}
+
+func (m *mockPromotionRepo) Update(promotion repository.Promotion) (repository.Promotion, error) {
+	i := m.index(promotion.ID)
+	if i < 0 {
+		return repository.Promotion{}, repository.ErrPromotionNotFound
+	}
+	existing := m.promotions[i]
+	promotion.Code = existing.Code
+	promotion.UsageCount = existing.UsageCount
+	promotion.CreatedAt = existing.CreatedAt
+	m.promotions[i] = promotion
+	return promotion, nil
+}
+
+func (m *mockPromotionRepo) Delete(id string) error {
+	i := m.index(id)
+	if i < 0 {
+		return repository.ErrPromotionNotFound
+	}
+	m.promotions = append(m.promotions[:i], m.promotions[i+1:]...)
+	return nil
+}
+
+func (m *mockPromotionRepo) Redeem(id string) error {
+	i := m.index(id)
+	if i < 0 {
+		return repository.ErrPromotionNotFound
+	}
+	if m.promotions[i].UsageLimit > 0 && m.promotions[i].UsageCount >= m.promotions[i].UsageLimit {
+		return repository.ErrPromotionExhausted
+	}
+	m.promotions[i].UsageCount++
+	return nil
+}
+
+func (m *mockPromotionRepo) Unredeem(id string) error {
+	if i := m.index(id); i >= 0 && m.promotions[i].UsageCount > 0 {
+		m.promotions[i].UsageCount--
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tvergilio/motown-house-backend/recommend"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+type RecommendationHandler struct {
+	Recommender *recommend.Recommender
+	Albums      repository.AlbumRepository
+}
+
+func NewRecommendationHandler(recommender *recommend.Recommender, albums repository.AlbumRepository) *RecommendationHandler {
+	return &RecommendationHandler{Recommender: recommender, Albums: albums}
+}
+
+// RecommendedAlbum is an album suggested alongside another, with its score and why it was suggested.
+type RecommendedAlbum struct {
+	repository.Album
+	Score   float64  `json:"score"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// RecommendationsResponse is what GET /albums/:id/recommendations returns. GeneratedAt is when the
+// recommendations were last worked out, and is left out until they first have been.
+type RecommendationsResponse struct {
+	AlbumID     string             `json:"albumId"`
+	Similar     []RecommendedAlbum `json:"similar"`
+	AlsoBought  []RecommendedAlbum `json:"alsoBought"`
+	GeneratedAt *time.Time         `json:"generatedAt,omitempty"`
+}
+
+// GetRecommendations handles GET /albums/:id/recommendations, returning albums similar to this one
+// and albums other customers bought or saved with it, best first. ?limit=N returns fewer of each.
+// Albums added since recommendations were last worked out have none yet.
+func (h *RecommendationHandler) GetRecommendations(c *gin.Context) {
+	albumID, ok := getAlbumIDFromUri(c)
+	if !ok {
+		return
+	}
+	limit := 0
+	if s := c.Query("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive whole number"})
+			return
+		}
+		limit = n
+	}
+	if _, err := h.Albums.GetByID(albumID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "album not found"})
+		return
+	}
+
+	response := RecommendationsResponse{AlbumID: albumID, Similar: []RecommendedAlbum{}, AlsoBought: []RecommendedAlbum{}}
+	recommendations, generatedAt, ok := h.Recommender.For(albumID)
+	if ok {
+		response.Similar = h.albums(recommendations.Similar, limit)
+		response.AlsoBought = h.albums(recommendations.AlsoBought, limit)
+	}
+	if !generatedAt.IsZero() {
+		response.GeneratedAt = &generatedAt
+	}
+	c.IndentedJSON(http.StatusOK, response)
+}
+
+// albums looks up the recommended albums, skipping any removed from the catalogue since the
+// recommendations were worked out, and stops at limit if it is set.
+func (h *RecommendationHandler) albums(recommendations []recommend.Recommendation, limit int) []RecommendedAlbum {
+	albums := []RecommendedAlbum{}
+	for _, recommendation := range recommendations {
+		if limit > 0 && len(albums) == limit {
+			break
+		}
+		album, err := h.Albums.GetByID(recommendation.AlbumID)
+		if err != nil {
+			continue
+		}
+		albums = append(albums, RecommendedAlbum{Album: album, Score: recommendation.Score, Reasons: recommendation.Reasons})
+	}
+	return albums
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/recommend"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+func setupRecommendationRouter(t *testing.T, wishlists *mockWishlistRepo) (*gin.Engine, *recommend.Recommender) {
+	t.Helper()
+	albums := newTestHandler().Repo
+	recommender := recommend.NewRecommender(albums, recommend.Content{})
+	recommender.AlsoBought = recommend.AlsoBought{Sources: []recommend.BasketSource{recommend.WishlistBaskets{Wishlists: wishlists}}}
+	handler := NewRecommendationHandler(recommender, albums)
+	r := gin.Default()
+	r.GET("/albums/:id/recommendations", handler.GetRecommendations)
+	return r, recommender
+}
+
+func Test_GetRecommendations_Success(t *testing.T) {
+	wishlists := newMockWishlistRepo()
+	require.NoError(t, wishlists.AddItem("user-1", repository.WishlistItem{AlbumID: "1"}))
+	require.NoError(t, wishlists.AddItem("user-1", repository.WishlistItem{AlbumID: "2"}))
+	r, recommender := setupRecommendationRouter(t, wishlists)
+	require.NoError(t, recommender.Refresh())
+
+	w := doRequest(r, "GET", "/albums/1/recommendations", "", "")
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	response := decodeJSON[RecommendationsResponse](t, w.Body.Bytes())
+	require.Len(t, response.Similar, 1)
+	assert.Equal(t, "101", response.Similar[0].ID)
+	assert.Equal(t, "Thriller", response.Similar[0].Title)
+	assert.Contains(t, response.Similar[0].Reasons, "same artist")
+	require.Len(t, response.AlsoBought, 1)
+	assert.Equal(t, "2", response.AlsoBought[0].ID)
+	assert.NotNil(t, response.GeneratedAt)
+}
+
+func Test_GetRecommendations_BeforeFirstRefresh(t *testing.T) {
+	r, _ := setupRecommendationRouter(t, newMockWishlistRepo())
+
+	w := doRequest(r, "GET", "/albums/1/recommendations", "", "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	response := decodeJSON[RecommendationsResponse](t, w.Body.Bytes())
+	assert.Empty(t, response.Similar)
+	assert.Empty(t, response.AlsoBought)
+	assert.Nil(t, response.GeneratedAt)
+	assert.Contains(t, w.Body.String(), `"similar": []`)
+}
+
+func Test_GetRecommendations_Limit(t *testing.T) {
+	wishlists := newMockWishlistRepo()
+	require.NoError(t, wishlists.AddItem("user-1", repository.WishlistItem{AlbumID: "1"}))
+	require.NoError(t, wishlists.AddItem("user-1", repository.WishlistItem{AlbumID: "2"}))
+	require.NoError(t, wishlists.AddItem("user-1", repository.WishlistItem{AlbumID: "101"}))
+	r, recommender := setupRecommendationRouter(t, wishlists)
+	require.NoError(t, recommender.Refresh())
+
+	w := doRequest(r, "GET", "/albums/1/recommendations?limit=1", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, decodeJSON[RecommendationsResponse](t, w.Body.Bytes()).AlsoBought, 1)
+
+	for _, limit := range []string{"0", "-1", "ten"} {
+		w = doRequest(r, "GET", "/albums/1/recommendations?limit="+limit, "", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code, limit)
+	}
+}
+
+func Test_GetRecommendations_NotFound(t *testing.T) {
+	r, _ := setupRecommendationRouter(t, newMockWishlistRepo())
+
+	w := doRequest(r, "GET", "/albums/999/recommendations", "", "")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+type ReleaseHandler struct {
+	Repo   repository.ReleaseRepository
+	Albums repository.AlbumRepository
+
+	// Labels is optional; when set, releases are linked to their label as albums are.
+	Labels repository.LabelRepository
+}
+
+func NewReleaseHandler(repo repository.ReleaseRepository, albums repository.AlbumRepository) *ReleaseHandler {
+	return &ReleaseHandler{Repo: repo, Albums: albums}
+}
+
+// ReleaseRequest is the body accepted by POST /albums/:id/releases and PUT
+// /albums/:id/releases/:releaseId. Year and currency default to the album's. Releases are bought
+// through carts, which are in money.DefaultCurrency, so the price must be too.
+type ReleaseRequest struct {
+	Format          string        `json:"format" binding:"required"`
+	Edition         string        `json:"edition"`
+	Year            int           `json:"year"`
+	LabelID         string        `json:"labelId"`
+	Label           string        `json:"label"`
+	CatalogueNumber string        `json:"catalogueNumber"`
+	Barcode         string        `json:"barcode"`
+	Price           *money.Amount `json:"price" binding:"required"`
+	Currency        string        `json:"currency"`
+}
+
+func respondWithReleaseError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrReleaseNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "release not found"})
+	case errors.Is(err, repository.ErrDuplicateBarcode):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// getAlbum returns the album named by the :id parameter. It writes an error response and returns
+// false if there is no such album.
+func (h *ReleaseHandler) getAlbum(c *gin.Context) (repository.Album, bool) {
+	album, err := h.Albums.GetByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "album not found"})
+		return repository.Album{}, false
+	}
+	return album, true
+}
+
+// bindRelease reads and validates a release of the album from the request body, putting its
+// identifiers into their standard form and linking its label. It writes an error response and
+// returns false if the body is not a valid release.
+func (h *ReleaseHandler) bindRelease(c *gin.Context, album repository.Album, id string) (repository.Release, bool) {
+	var req ReleaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return repository.Release{}, false
+	}
+	format, err := repository.ParseFormat(req.Format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return repository.Release{}, false
+	}
+	release := repository.Release{
+		ID:              id,
+		AlbumID:         album.ID,
+		Format:          format,
+		Edition:         req.Edition,
+		Year:            req.Year,
+		LabelID:         req.LabelID,
+		Label:           req.Label,
+		CatalogueNumber: repository.NormalizeCatalogueNumber(req.CatalogueNumber),
+		Price:           *req.Price,
+		Currency:        req.Currency,
+	}
+	if release.Year == 0 {
+		release.Year = album.Year
+	}
+	if release.Currency == "" {
+		release.Currency = album.Currency
+	}
+	if release.Currency, err = money.ParseCurrency(release.Currency); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return repository.Release{}, false
+	}
+	if release.Currency != money.DefaultCurrency {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("price must be in %s", money.DefaultCurrency)})
+		return repository.Release{}, false
+	}
+	if req.Barcode != "" {
+		if release.Barcode, err = repository.NormalizeBarcode(req.Barcode); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return repository.Release{}, false
+		}
+	}
+	if err := release.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return repository.Release{}, false
+	}
+	if !linkLabel(c, h.Labels, &release.LabelID, &release.Label, release.CatalogueNumber) {
+		return repository.Release{}, false
+	}
+	return release, true
+}
+
+// GetReleases handles GET /albums/:id/releases, listing the album's releases oldest first.
+func (h *ReleaseHandler) GetReleases(c *gin.Context) {
+	album, ok := h.getAlbum(c)
+	if !ok {
+		return
+	}
+	releases, err := h.Repo.ListByAlbum(album.ID)
+	if err != nil {
+		respondWithReleaseError(c, err)
+		return
+	}
+	if releases == nil {
+		releases = []repository.Release{}
+	}
+	c.IndentedJSON(http.StatusOK, releases)
+}
+
+// GetRelease handles GET /albums/:id/releases/:releaseId.
+func (h *ReleaseHandler) GetRelease(c *gin.Context) {
+	album, ok := h.getAlbum(c)
+	if !ok {
+		return
+	}
+	release, err := h.Repo.GetByID(album.ID, c.Param("releaseId"))
+	if err != nil {
+		respondWithReleaseError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, release)
+}
+
+// PostRelease handles POST /albums/:id/releases. No two releases can share a barcode.
+func (h *ReleaseHandler) PostRelease(c *gin.Context) {
+	album, ok := h.getAlbum(c)
+	if !ok {
+		return
+	}
+	release, ok := h.bindRelease(c, album, "")
+	if !ok {
+		return
+	}
+	created, err := h.Repo.Create(release)
+	if err != nil {
+		respondWithReleaseError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusCreated, created)
+}
+
+// PutRelease handles PUT /albums/:id/releases/:releaseId, replacing the release's details.
+func (h *ReleaseHandler) PutRelease(c *gin.Context) {
+	album, ok := h.getAlbum(c)
+	if !ok {
+		return
+	}
+	if _, err := h.Repo.GetByID(album.ID, c.Param("releaseId")); err != nil {
+		respondWithReleaseError(c, err)
+		return
+	}
+	release, ok := h.bindRelease(c, album, c.Param("releaseId"))
+	if !ok {
+		return
+	}
+	updated, err := h.Repo.Update(release)
+	if err != nil {
+		respondWithReleaseError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, updated)
+}
+
+// DeleteRelease handles DELETE /albums/:id/releases/:releaseId.
+func (h *ReleaseHandler) DeleteRelease(c *gin.Context) {
+	album, ok := h.getAlbum(c)
+	if !ok {
+		return
+	}
+	if err := h.Repo.Delete(album.ID, c.Param("releaseId")); err != nil {
+		respondWithReleaseError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// setupReleaseRouter serves the album, label and release routes over the mock catalogue, with labels
+// for Tamla (label-1) and Gordy (label-2) and no releases yet.
+func setupReleaseRouter(t *testing.T) (*gin.Engine, *AlbumHandler, *mockReleaseRepo) {
+	t.Helper()
+	releases := newMockReleaseRepo()
+	labels := newMockLabelRepo()
+	albums := newTestHandler()
+	albums.Labels = labels
+	albums.Releases = releases
+	handler := NewReleaseHandler(releases, albums.Repo)
+	handler.Labels = labels
+	labelHandler := NewLabelHandler(labels, albums.Repo)
+	labelHandler.Releases = releases
+	r := gin.Default()
+	r.GET("/albums", albums.GetAlbums)
+	r.GET("/albums/:id", albums.GetAlbumByID)
+	r.GET("/albums/barcode/:barcode", albums.GetAlbumByBarcode)
+	r.DELETE("/albums/:id", albums.DeleteAlbum)
+	r.GET("/albums/:id/releases", handler.GetReleases)
+	r.POST("/albums/:id/releases", handler.PostRelease)
+	r.GET("/albums/:id/releases/:releaseId", handler.GetRelease)
+	r.PUT("/albums/:id/releases/:releaseId", handler.PutRelease)
+	r.DELETE("/albums/:id/releases/:releaseId", handler.DeleteRelease)
+	r.PUT("/labels/:id", labelHandler.PutLabel)
+	r.DELETE("/labels/:id", labelHandler.DeleteLabel)
+	return r, albums, releases
+}
+
+// addReleases gives Songs in the Key of Life its original LP and a CD reissue.
+func addReleases(t *testing.T, r *gin.Engine) (lp, cd repository.Release) {
+	t.Helper()
+	w := doRequest(r, "POST", "/albums/2/releases", "staff-1", `{"format":"lp","edition":"Original","label":"Tamla","catalogueNumber":"t13-340c2","price":60}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	lp = decodeJSON[repository.Release](t, w.Body.Bytes())
+	w = doRequest(r, "POST", "/albums/2/releases", "staff-1", `{"format":"CD","edition":"2000 Remaster","year":2000,"barcode":"0 36000-29145 2","price":12.99}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	cd = decodeJSON[repository.Release](t, w.Body.Bytes())
+	return lp, cd
+}
+
+func Test_PostRelease(t *testing.T) {
+	r, _, _ := setupReleaseRouter(t)
+
+	lp, cd := addReleases(t, r)
+
+	assert.Equal(t, "2", lp.AlbumID)
+	assert.Equal(t, repository.FormatLP, lp.Format)
+	assert.Equal(t, 1976, lp.Year, "the year defaults to the album's")
+	assert.Equal(t, "GBP", lp.Currency, "the currency defaults to the album's")
+	assert.Equal(t, "label-1", lp.LabelID)
+	assert.Equal(t, "T13-340C2", lp.CatalogueNumber)
+	assert.Equal(t, money.MustParse("60"), lp.Price)
+	assert.Equal(t, 2000, cd.Year)
+	assert.Equal(t, "0036000291452", cd.Barcode)
+
+	w := doRequest(r, "POST", "/albums/1/releases", "staff-1", `{"format":"CD","barcode":"0036000291452","price":9.99}`)
+	assert.Equal(t, http.StatusConflict, w.Code, "no two releases can share a barcode")
+
+	for _, body := range []string{
+		`{"format":"8-track","price":9.99}`,
+		`{"format":"CD"}`,
+		`{"format":"CD","price":-1}`,
+		`{"format":"CD","price":9.99,"barcode":"123"}`,
+		`{"format":"CD","price":9.99,"currency":"pounds"}`,
+		`{"format":"CD","price":9.99,"currency":"USD"}`,
+	} {
+		w = doRequest(r, "POST", "/albums/1/releases", "staff-1", body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+
+	w = doRequest(r, "POST", "/albums/999/releases", "staff-1", `{"format":"CD","price":9.99}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_GetReleases(t *testing.T) {
+	r, _, _ := setupReleaseRouter(t)
+	lp, _ := addReleases(t, r)
+
+	w := doRequest(r, "GET", "/albums/2/releases", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var releases []repository.Release
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &releases))
+	require.Len(t, releases, 2)
+	assert.Equal(t, lp.ID, releases[0].ID, "releases are listed oldest first")
+
+	w = doRequest(r, "GET", "/albums/1/releases", "", "")
+	assert.JSONEq(t, `[]`, w.Body.String())
+
+	w = doRequest(r, "GET", "/albums/2/releases/"+lp.ID, "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "Original", decodeJSON[repository.Release](t, w.Body.Bytes()).Edition)
+
+	w = doRequest(r, "GET", "/albums/1/releases/"+lp.ID, "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code, "releases are found under their own album only")
+}
+
+func Test_PutAndDeleteRelease(t *testing.T) {
+	r, _, _ := setupReleaseRouter(t)
+	lp, _ := addReleases(t, r)
+
+	w := doRequest(r, "PUT", "/albums/2/releases/"+lp.ID, "staff-1", `{"format":"LP","edition":"Original","year":1976,"label":"Tamla","price":75}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, money.MustParse("75"), decodeJSON[repository.Release](t, w.Body.Bytes()).Price)
+
+	w = doRequest(r, "PUT", "/albums/2/releases/missing", "staff-1", `{"format":"LP","price":75}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doRequest(r, "DELETE", "/albums/2/releases/"+lp.ID, "staff-1", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = doRequest(r, "GET", "/albums/2/releases/"+lp.ID, "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_GetAlbums_GroupedAndFlatViews(t *testing.T) {
+	r, albums, _ := setupReleaseRouter(t)
+	albums.Inventory = newMockInventoryRepo(
+		repository.StockLevel{AlbumID: "2", Format: repository.FormatLP, OnHand: 0},
+		repository.StockLevel{AlbumID: "2", Format: repository.FormatCD, OnHand: 3},
+	)
+	lp, cd := addReleases(t, r)
+
+	w := doRequest(r, "GET", "/albums", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var grouped []repository.Album
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &grouped))
+	require.Len(t, grouped, 3)
+	assert.Empty(t, grouped[0].Releases)
+	require.Len(t, grouped[1].Releases, 2)
+	assert.Equal(t, lp.ID, grouped[1].Releases[0].ID)
+
+	w = doRequest(r, "GET", "/albums?view=flat", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var flat []repository.Album
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &flat))
+	require.Len(t, flat, 4, "albums without releases are listed once")
+	original, remaster := flat[1], flat[2]
+	assert.Equal(t, "2", original.ID)
+	assert.Equal(t, lp.ID, original.ReleaseID)
+	assert.Equal(t, money.MustParse("60"), original.Price)
+	assert.Equal(t, "Tamla", original.Label)
+	assert.Equal(t, cd.ID, remaster.ReleaseID)
+	assert.Equal(t, 2000, remaster.Year)
+	require.Len(t, remaster.Stock, 1, "releases show the stock in their own format")
+	assert.Equal(t, repository.FormatCD, remaster.Stock[0].Format)
+	assert.True(t, *remaster.InStock)
+	assert.False(t, *original.InStock)
+
+	w = doRequest(r, "GET", "/albums?view=tree", "", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_GetAlbums_FlatViewNeedsReleases(t *testing.T) {
+	r := setupRouter(newTestHandler())
+
+	w := doRequest(r, "GET", "/albums?view=flat", "", "")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_GetAlbumByID_IncludesReleases(t *testing.T) {
+	r, _, _ := setupReleaseRouter(t)
+	addReleases(t, r)
+
+	w := doRequest(r, "GET", "/albums/2", "", "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, decodeJSON[repository.Album](t, w.Body.Bytes()).Releases, 2)
+}
+
+func Test_GetAlbumByBarcode_FindsReleases(t *testing.T) {
+	r, _, _ := setupReleaseRouter(t)
+	_, cd := addReleases(t, r)
+
+	w := doRequest(r, "GET", "/albums/barcode/036000291452", "", "")
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	album := decodeJSON[repository.Album](t, w.Body.Bytes())
+	assert.Equal(t, "2", album.ID)
+	assert.Equal(t, cd.ID, album.ReleaseID, "the album is shown as the release with the barcode")
+	assert.Equal(t, repository.FormatCD, album.Format)
+
+	w = doRequest(r, "GET", "/albums/barcode/4006381333931", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doRequest(r, "DELETE", "/albums/2", "staff-1", "")
+	require.Equal(t, http.StatusNoContent, w.Code)
+	w = doRequest(r, "GET", "/albums/barcode/036000291452", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code, "releases of albums in the trash are not found")
+}
+
+func Test_DeleteAlbum_KeepsReleasesForRestore(t *testing.T) {
+	r, _, releases := setupReleaseRouter(t)
+	addReleases(t, r)
+
+	w := doRequest(r, "DELETE", "/albums/2", "staff-1", "")
+
+	require.Equal(t, http.StatusNoContent, w.Code)
+	remaining, err := releases.List()
+	require.NoError(t, err)
+	assert.Len(t, remaining, 2, "releases are deleted when the album is purged from the trash")
+}
+
+func Test_Labels_IncludeReleases(t *testing.T) {
+	r, _, releases := setupReleaseRouter(t)
+	addReleases(t, r)
+
+	w := doRequest(r, "DELETE", "/labels/label-1", "staff-1", "")
+	assert.Equal(t, http.StatusConflict, w.Code, "labels that releases came out on are kept")
+
+	w = doRequest(r, "PUT", "/labels/label-1", "staff-1", `{"name":"Tamla Records"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	all, err := releases.List()
+	require.NoError(t, err)
+	assert.Equal(t, "Tamla Records", all[0].Label)
+}
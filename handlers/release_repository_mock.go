@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// In-memory mock implementation of ReleaseRepository for testing
+
+type mockReleaseRepo struct {
+	releases []repository.Release
+	nextID   int
+}
+
+func newMockReleaseRepo() *mockReleaseRepo {
+	return &mockReleaseRepo{}
+}
+
+// barcodeTaken reports whether a release other than id has the release's barcode.
+func (m *mockReleaseRepo) barcodeTaken(release repository.Release, id string) bool {
+	if release.Barcode == "" {
+		return false
+	}
+	for _, existing := range m.releases {
+		if existing.Barcode == release.Barcode && existing.ID != id {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *mockReleaseRepo) Create(release repository.Release) (repository.Release, error) {
+	if m.barcodeTaken(release, "") {
+		return repository.Release{}, repository.ErrDuplicateBarcode
+	}
+	m.nextID++
+	release.ID = fmt.Sprintf("release-%d", m.nextID)
+	release.CreatedAt = time.Now()
+	release.UpdatedAt = release.CreatedAt
+	m.releases = append(m.releases, release)
+	return release, nil
+}
+
+func (m *mockReleaseRepo) GetByID(albumID, id string) (repository.Release, error) {
+	for _, release := range m.releases {
+		if release.AlbumID == albumID && release.ID == id {
+			return release, nil
+		}
+	}
+	return repository.Release{}, repository.ErrReleaseNotFound
+}
+
+func (m *mockReleaseRepo) GetByBarcode(barcode string) (repository.Release, error) {
+	for _, release := range m.releases {
+		if release.Barcode == barcode {
+			return release, nil
+		}
+	}
+	return repository.Release{}, repository.ErrReleaseNotFound
+}
+
+func (m *mockReleaseRepo) ListByAlbum(albumID string) ([]repository.Release, error) {
+	var releases []repository.Release
+	for _, release := range m.releases {
+		if release.AlbumID == albumID {
+			releases = append(releases, release)
+		}
+	}
+	sort.SliceStable(releases, func(i, j int) bool {
+		return releases[i].Year < releases[j].Year
+	})
+	return releases, nil
+}
+
+func (m *mockReleaseRepo) List() ([]repository.Release, error) {
+	releases := append([]repository.Release(nil), m.releases...)
+	sort.SliceStable(releases, func(i, j int) bool {
+		return releases[i].Year < releases[j].Year
+	})
+	return releases, nil
+}
+
+func (m *mockReleaseRepo) Update(release repository.Release) (repository.Release, error) {
+	for i, existing := range m.releases {
+		if existing.AlbumID != release.AlbumID || existing.ID != release.ID {
+			continue
+		}
+		if m.barcodeTaken(release, release.ID) {
+			return repository.Release{}, repository.ErrDuplicateBarcode
+		}
+		release.CreatedAt = existing.CreatedAt
+		release.UpdatedAt = time.Now()
+		m.releases[i] = release
+		return release, nil
+	}
+	return repository.Release{}, repository.ErrReleaseNotFound
+}
+
+func (m *mockReleaseRepo) Delete(albumID, id string) error {
+	for i, release := range m.releases {
+		if release.AlbumID == albumID && release.ID == id {
+			m.releases = append(m.releases[:i], m.releases[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrReleaseNotFound
+}
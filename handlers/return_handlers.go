@@ -0,0 +1,606 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/payments"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+type ReturnHandler struct {
+	Repo      repository.ReturnRepository
+	Orders    repository.OrderRepository
+	Inventory repository.InventoryRepository
+	Payments  repository.PaymentRepository
+	Provider  payments.Provider
+
+	// GiftCards is optional; when set, returns can be refunded as store credit, and payments made
+	// with gift cards or store credit are refunded back onto them.
+	GiftCards repository.GiftCardRepository
+
+	// UsedItems is optional; when set, each unit of a line kept as used is put up for sale as a used
+	// item at the grades it was given, priced at what was refunded for it until staff reprice it.
+	UsedItems repository.UsedItemRepository
+}
+
+func NewReturnHandler(repo repository.ReturnRepository, orders repository.OrderRepository, inventory repository.InventoryRepository,
+	paymentRepo repository.PaymentRepository, provider payments.Provider) *ReturnHandler {
+	return &ReturnHandler{
+		Repo:      repo,
+		Orders:    orders,
+		Inventory: inventory,
+		Payments:  paymentRepo,
+		Provider:  provider,
+	}
+}
+
+// ReturnRequest is the body accepted by POST /orders/:id/returns.
+type ReturnRequest struct {
+	Lines []ReturnLineRequest `json:"lines" binding:"required"`
+}
+
+// ReturnLineRequest is a quantity of one order line the customer wants to send back, and why. Lines
+// for a release of an album are named by its ReleaseID as well.
+type ReturnLineRequest struct {
+	AlbumID   string `json:"albumId" binding:"required"`
+	ReleaseID string `json:"releaseId"`
+	Format    string `json:"format" binding:"required"`
+	Quantity  int    `json:"quantity"`
+	Reason    string `json:"reason" binding:"required"`
+	Note      string `json:"note"`
+}
+
+// ReturnNoteRequest is the optional body accepted when approving, rejecting or cancelling a return.
+type ReturnNoteRequest struct {
+	Note string `json:"note"`
+}
+
+// ReceiveReturnRequest is the body accepted by POST /admin/returns/:id/receive, saying what happens
+// to each returned line now it is back in the shop.
+type ReceiveReturnRequest struct {
+	Lines []ReceiveLineRequest `json:"lines" binding:"required"`
+	Note  string               `json:"note"`
+}
+
+// ReceiveLineRequest says whether a returned line goes back into stock as new, is kept to sell as a
+// used item at the given grades, or is written off.
+type ReceiveLineRequest struct {
+	AlbumID     string `json:"albumId" binding:"required"`
+	Format      string `json:"format" binding:"required"`
+	Restock     string `json:"restock" binding:"required"`
+	MediaGrade  string `json:"mediaGrade"`
+	SleeveGrade string `json:"sleeveGrade"`
+}
+
+// RefundReturnRequest is the body accepted by POST /admin/returns/:id/refund. Amount defaults to
+// the full value of the returned items; staff can refund less, for example to keep a restocking fee.
+// StoreCredit refunds the amount as store credit instead of back to the order's payments.
+type RefundReturnRequest struct {
+	Amount      *money.Amount `json:"amount"`
+	Note        string        `json:"note"`
+	StoreCredit bool          `json:"storeCredit"`
+}
+
+// respondWithReturnError maps repository errors from return operations to HTTP responses.
+func respondWithReturnError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrReturnNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "return not found"})
+	case errors.Is(err, repository.ErrReturnStatusChanged):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// returnable reports whether the customer can send items from the order back.
+func returnable(order repository.Order) bool {
+	return order.Status == repository.OrderShipped || order.Status == repository.OrderDelivered
+}
+
+// paidFor returns what the customer paid for an order line, tax included.
+func paidFor(order repository.Order, line repository.OrderLine) money.Amount {
+	if order.TaxIncluded {
+		return line.LineTotal
+	}
+	return line.LineTotal + line.Tax
+}
+
+// share returns what quantity units of the line cost. Working it out from the running total of units
+// returned means several partial returns of a line add up to exactly what was paid for it.
+func share(paid money.Amount, lineQuantity, quantity int) money.Amount {
+	return money.FromMinor(int64(math.Round(float64(paid) * float64(quantity) / float64(lineQuantity))))
+}
+
+// returnKey identifies an order line by its album, release and format, for counting the units of it
+// returned so far.
+func returnKey(albumID, releaseID string, format repository.Format) string {
+	return albumID + "/" + releaseID + "/" + string(format)
+}
+
+// newReturn validates the requested lines against the order and the returns already made from it,
+// and works out the refund due for each.
+func newReturn(order repository.Order, req ReturnRequest, existing []repository.Return) (repository.Return, error) {
+	if len(req.Lines) == 0 {
+		return repository.Return{}, errors.New("at least one line is required")
+	}
+	returned := make(map[string]int)
+	for _, ret := range existing {
+		if !ret.Status.Open() {
+			continue
+		}
+		for _, line := range ret.Lines {
+			returned[returnKey(line.AlbumID, line.ReleaseID, line.Format)] += line.Quantity
+		}
+	}
+
+	ret := repository.Return{OrderID: order.ID, UserID: order.UserID}
+	for _, requested := range req.Lines {
+		format, err := repository.ParseFormat(requested.Format)
+		if err != nil {
+			return repository.Return{}, err
+		}
+		reason, err := repository.ParseReturnReason(requested.Reason)
+		if err != nil {
+			return repository.Return{}, err
+		}
+		var orderLine *repository.OrderLine
+		for i := range order.Lines {
+			if order.Lines[i].AlbumID == requested.AlbumID && order.Lines[i].ReleaseID == requested.ReleaseID && order.Lines[i].Format == format {
+				orderLine = &order.Lines[i]
+			}
+		}
+		if orderLine == nil {
+			return repository.Return{}, fmt.Errorf("album %s (%s) is not in the order", requested.AlbumID, format)
+		}
+		key := returnKey(orderLine.AlbumID, orderLine.ReleaseID, format)
+		if requested.Quantity <= 0 || returned[key]+requested.Quantity > orderLine.Quantity {
+			return repository.Return{}, fmt.Errorf("quantity for album %s (%s) must be between 1 and %d",
+				requested.AlbumID, format, orderLine.Quantity-returned[key])
+		}
+		paid := paidFor(order, *orderLine)
+		refund := share(paid, orderLine.Quantity, returned[key]+requested.Quantity) - share(paid, orderLine.Quantity, returned[key])
+		returned[key] += requested.Quantity
+
+		ret.Lines = append(ret.Lines, repository.ReturnLine{
+			AlbumID:   orderLine.AlbumID,
+			ReleaseID: orderLine.ReleaseID,
+			Format:    format,
+			Title:     orderLine.Title,
+			Quantity:  requested.Quantity,
+			Reason:    reason,
+			Note:      strings.TrimSpace(requested.Note),
+			Refund:    refund,
+		})
+		ret.RefundAmount += refund
+	}
+	return ret, nil
+}
+
+// PostReturn handles POST /orders/:id/returns, letting a customer ask to send back items from an
+// order that has shipped.
+func (h *ReturnHandler) PostReturn(c *gin.Context) {
+	order, ok := getOwnedOrder(c, h.Orders)
+	if !ok {
+		return
+	}
+	var req ReturnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !returnable(order) {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("order is %s; only shipped orders can be returned", order.Status)})
+		return
+	}
+	existing, err := h.Repo.ListByOrder(order.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ret, err := newReturn(order, req, existing)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	created, err := h.Repo.Create(ret)
+	if err != nil {
+		respondWithReturnError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusCreated, created)
+}
+
+// GetOrderReturns handles GET /orders/:id/returns.
+func (h *ReturnHandler) GetOrderReturns(c *gin.Context) {
+	order, ok := getOwnedOrder(c, h.Orders)
+	if !ok {
+		return
+	}
+	returns, err := h.Repo.ListByOrder(order.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if returns == nil {
+		returns = []repository.Return{}
+	}
+	c.IndentedJSON(http.StatusOK, returns)
+}
+
+// getOwnedReturn loads the return named in the URI, treating other users' returns as not found.
+func (h *ReturnHandler) getOwnedReturn(c *gin.Context) (repository.Return, bool) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return repository.Return{}, false
+	}
+	ret, err := h.Repo.GetByID(c.Param("id"))
+	if err == nil && ret.UserID != userID {
+		err = repository.ErrReturnNotFound
+	}
+	if err != nil {
+		respondWithReturnError(c, err)
+		return repository.Return{}, false
+	}
+	return ret, true
+}
+
+// GetMyReturn handles GET /returns/:id.
+func (h *ReturnHandler) GetMyReturn(c *gin.Context) {
+	ret, ok := h.getOwnedReturn(c)
+	if !ok {
+		return
+	}
+	c.IndentedJSON(http.StatusOK, ret)
+}
+
+// CancelMyReturn handles POST /returns/:id/cancel. Customers can cancel until the items arrive back.
+func (h *ReturnHandler) CancelMyReturn(c *gin.Context) {
+	ret, ok := h.getOwnedReturn(c)
+	if !ok {
+		return
+	}
+	h.transition(c, ret, repository.ReturnCancelled, ret.UserID)
+}
+
+// ListReturns handles GET /admin/returns, optionally filtered with ?status=.
+func (h *ReturnHandler) ListReturns(c *gin.Context) {
+	var status repository.ReturnStatus
+	if s := c.Query("status"); s != "" {
+		parsed, err := repository.ParseReturnStatus(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		status = parsed
+	}
+	returns, err := h.Repo.List(status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if returns == nil {
+		returns = []repository.Return{}
+	}
+	c.IndentedJSON(http.StatusOK, returns)
+}
+
+// GetReturn handles GET /admin/returns/:id.
+func (h *ReturnHandler) GetReturn(c *gin.Context) {
+	ret, err := h.Repo.GetByID(c.Param("id"))
+	if err != nil {
+		respondWithReturnError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, ret)
+}
+
+// ApproveReturn handles POST /admin/returns/:id/approve, telling the customer to send the items back.
+func (h *ReturnHandler) ApproveReturn(c *gin.Context) {
+	h.staffTransition(c, repository.ReturnApproved)
+}
+
+// RejectReturn handles POST /admin/returns/:id/reject.
+func (h *ReturnHandler) RejectReturn(c *gin.Context) {
+	h.staffTransition(c, repository.ReturnRejected)
+}
+
+func (h *ReturnHandler) staffTransition(c *gin.Context, status repository.ReturnStatus) {
+	ret, err := h.Repo.GetByID(c.Param("id"))
+	if err != nil {
+		respondWithReturnError(c, err)
+		return
+	}
+	h.transition(c, ret, status, currentUserID(c))
+}
+
+// transition moves a return to status, recording the note from the request body if there is one.
+func (h *ReturnHandler) transition(c *gin.Context, ret repository.Return, status repository.ReturnStatus, actor string) {
+	var req ReturnNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !ret.Status.CanTransitionTo(status) {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("return is %s and cannot be %s", ret.Status, status)})
+		return
+	}
+	from := ret.Status
+	ret.Status = status
+	updated, err := h.Repo.Update(ret, from, actor, req.Note)
+	if err != nil {
+		respondWithReturnError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, updated)
+}
+
+// ReceiveReturn handles POST /admin/returns/:id/receive. Lines restocked as new go back into the
+// inventory; lines kept as used record the grades they were given and are listed as used items;
+// the rest are written off. Receiving a return that is already received finishes restocking whatever
+// an earlier attempt left undone, keeping the decisions made then.
+func (h *ReturnHandler) ReceiveReturn(c *gin.Context) {
+	ret, err := h.Repo.GetByID(c.Param("id"))
+	if err != nil {
+		respondWithReturnError(c, err)
+		return
+	}
+	actor := currentUserID(c)
+	if ret.Status != repository.ReturnReceived {
+		var req ReceiveReturnRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !ret.Status.CanTransitionTo(repository.ReturnReceived) {
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("return is %s and cannot be received", ret.Status)})
+			return
+		}
+		if err := applyRestock(&ret, req.Lines); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		// Claim the return first so two members of staff cannot both decide what happens to the items.
+		ret.Status = repository.ReturnReceived
+		if ret, err = h.Repo.Update(ret, repository.ReturnApproved, actor, req.Note); err != nil {
+			respondWithReturnError(c, err)
+			return
+		}
+	}
+
+	if err := h.restock(ret, actor); err != nil {
+		if errors.Is(err, repository.ErrReturnStatusChanged) {
+			c.JSON(http.StatusConflict, gin.H{"error": "return is already being restocked"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf(
+			"return %s is received but %v; receive it again to finish restocking", ret.ID, err)})
+		return
+	}
+	if ret, err = h.Repo.GetByID(ret.ID); err != nil {
+		respondWithReturnError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, ret)
+}
+
+// restock puts the units of each line not yet restocked back into stock or up for sale. Units are
+// counted as restocked before the stock is changed and given back if that fails, so a receive that
+// stops part way can be run again without restocking anything twice.
+func (h *ReturnHandler) restock(ret repository.Return, actor string) error {
+	for i, line := range ret.Lines {
+		switch {
+		case line.Restocked >= line.Quantity:
+		case line.Restock == repository.RestockNew:
+			if err := h.claimRestock(ret.ID, i, line.Restocked, line.Quantity, func() error {
+				_, err := h.Inventory.Adjust(repository.StockAdjustment{
+					AlbumID: line.AlbumID,
+					Format:  line.Format,
+					Delta:   line.Quantity - line.Restocked,
+					Reason:  repository.ReasonReturned,
+					Note:    "return " + ret.ID,
+					Actor:   actor,
+				})
+				return err
+			}); err != nil {
+				return fmt.Errorf("stock for %s (%s) could not be updated: %w", line.AlbumID, line.Format, err)
+			}
+		case line.Restock == repository.RestockUsed && h.UsedItems != nil:
+			for n := line.Restocked; n < line.Quantity; n++ {
+				if err := h.claimRestock(ret.ID, i, n, n+1, func() error { return h.listUsed(ret, line) }); err != nil {
+					return fmt.Errorf("used copies of %s (%s) could not be listed: %w", line.AlbumID, line.Format, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// claimRestock counts a line's units from..to as restocked, then runs restock, giving the units back if it fails.
+func (h *ReturnHandler) claimRestock(returnID string, line, from, to int, restock func() error) error {
+	if err := h.Repo.SetRestocked(returnID, line, from, to); err != nil {
+		return err
+	}
+	if err := restock(); err != nil {
+		if undoErr := h.Repo.SetRestocked(returnID, line, to, from); undoErr != nil {
+			return errors.Join(err, undoErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// listUsed puts one unit of a returned line kept as used up for sale.
+func (h *ReturnHandler) listUsed(ret repository.Return, line repository.ReturnLine) error {
+	_, err := h.UsedItems.Create(repository.UsedItem{
+		AlbumID:     line.AlbumID,
+		Format:      line.Format,
+		MediaGrade:  line.MediaGrade,
+		SleeveGrade: line.SleeveGrade,
+		Notes:       "Returned in return " + ret.ID,
+		Photos:      repository.Photos{},
+		Price:       line.Refund / money.Amount(line.Quantity),
+		Currency:    money.DefaultCurrency,
+	})
+	return err
+}
+
+// applyRestock records what staff decided for each returned line. Every line must be covered.
+func applyRestock(ret *repository.Return, lines []ReceiveLineRequest) error {
+	decided := make(map[string]ReceiveLineRequest, len(lines))
+	for _, line := range lines {
+		format, err := repository.ParseFormat(line.Format)
+		if err != nil {
+			return err
+		}
+		decided[line.AlbumID+"/"+string(format)] = line
+	}
+	for i := range ret.Lines {
+		line := &ret.Lines[i]
+		req, ok := decided[line.AlbumID+"/"+string(line.Format)]
+		if !ok {
+			return fmt.Errorf("restock is required for album %s (%s)", line.AlbumID, line.Format)
+		}
+		restock, err := repository.ParseRestock(req.Restock)
+		if err != nil {
+			return err
+		}
+		line.Restock = restock
+		line.MediaGrade, line.SleeveGrade = "", ""
+		if restock != repository.RestockUsed {
+			continue
+		}
+		if line.MediaGrade, err = repository.ParseGrade(req.MediaGrade); err != nil {
+			return fmt.Errorf("mediaGrade: %w", err)
+		}
+		if line.SleeveGrade, err = repository.ParseGrade(req.SleeveGrade); err != nil {
+			return fmt.Errorf("sleeveGrade: %w", err)
+		}
+	}
+	return nil
+}
+
+// RefundReturn handles POST /admin/returns/:id/refund, paying the customer back for the items they
+// returned, either to the order's payments or as store credit.
+func (h *ReturnHandler) RefundReturn(c *gin.Context) {
+	var req RefundReturnRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ret, err := h.Repo.GetByID(c.Param("id"))
+	if err != nil {
+		respondWithReturnError(c, err)
+		return
+	}
+	if !ret.Status.CanTransitionTo(repository.ReturnRefunded) {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("return is %s and cannot be refunded", ret.Status)})
+		return
+	}
+	due := ret.RefundAmount - ret.RefundedAmount
+	amount := due
+	if req.Amount != nil {
+		amount = *req.Amount
+	}
+	if amount <= 0 || amount > due {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("amount must be between 0.01 and %s", due)})
+		return
+	}
+	if req.StoreCredit && h.GiftCards == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "store credit is not available"})
+		return
+	}
+
+	// Claim the refund first so it cannot be paid out twice. If the provider fails, the return goes
+	// back to received with whatever was refunded, and the rest can be retried.
+	actor := currentUserID(c)
+	ret.Status = repository.ReturnRefunded
+	claimed, err := h.Repo.Update(ret, repository.ReturnReceived, actor, req.Note)
+	if err != nil {
+		respondWithReturnError(c, err)
+		return
+	}
+	var refunded money.Amount
+	var refundErr error
+	how := "refunded "
+	if req.StoreCredit {
+		refunded, refundErr = h.creditStore(claimed, amount, actor)
+		how = "refunded as store credit "
+	} else {
+		refunded, refundErr = h.refund(claimed.OrderID, amount)
+	}
+	claimed.RefundedAmount += refunded
+	if refundErr == nil {
+		updated, err := h.Repo.Update(claimed, repository.ReturnRefunded, actor, how+refunded.String())
+		if err != nil {
+			respondWithReturnError(c, err)
+			return
+		}
+		c.IndentedJSON(http.StatusOK, updated)
+		return
+	}
+	claimed.Status = repository.ReturnReceived
+	if _, err := h.Repo.Update(claimed, repository.ReturnRefunded, actor, "refund failed: "+refundErr.Error()); err != nil {
+		log.Printf("RefundReturn: return %s could not be moved back to received after a failed refund: %v", claimed.ID, err)
+	}
+	switch {
+	case errors.Is(refundErr, errNotRefundable), errors.Is(refundErr, payments.ErrInvalidAmount):
+		c.JSON(http.StatusConflict, gin.H{"error": refundErr.Error()})
+	case req.StoreCredit:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": refundErr.Error()})
+	default:
+		c.JSON(http.StatusBadGateway, gin.H{"error": refundErr.Error()})
+	}
+}
+
+// creditStore adds amount to the customer's store credit and returns how much was credited.
+func (h *ReturnHandler) creditStore(ret repository.Return, amount money.Amount, actor string) (money.Amount, error) {
+	_, err := h.GiftCards.AddStoreCredit(ret.UserID, repository.GiftCardEntry{
+		Amount:  amount,
+		Reason:  repository.EntryRefunded,
+		OrderID: ret.OrderID,
+		Actor:   actor,
+		Note:    "return " + ret.ID,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return amount, nil
+}
+
+// refund pays amount back against the order's captured payments, oldest first, and returns how much
+// was refunded before any error.
+func (h *ReturnHandler) refund(orderID string, amount money.Amount) (money.Amount, error) {
+	intents, err := h.Payments.ListByOrder(orderID)
+	if err != nil {
+		return 0, err
+	}
+	var refunded money.Amount
+	for _, intent := range intents {
+		left := amount - refunded
+		if left <= 0 {
+			break
+		}
+		remaining := intent.Amount - intent.RefundedAmount
+		if intent.Status != repository.PaymentCaptured || remaining <= 0 {
+			continue
+		}
+		part := money.Min(left, remaining)
+		if _, err := refundPayment(h.Payments, h.Provider, h.GiftCards, intent, part); err != nil {
+			return refunded, err
+		}
+		refunded += part
+	}
+	if refunded < amount {
+		return refunded, fmt.Errorf("%w: only %s of the order's payments is left to refund", errNotRefundable, refunded)
+	}
+	return refunded, nil
+}
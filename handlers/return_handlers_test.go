@@ -0,0 +1,396 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/payments"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+type returnTestFixture struct {
+	paymentTestFixture
+	returns   *mockReturnRepo
+	usedItems *mockUsedItemRepo
+}
+
+func setupReturnRouter() returnTestFixture {
+	f := returnTestFixture{
+		paymentTestFixture: setupPaymentRouter(),
+		returns:            newMockReturnRepo(),
+		usedItems:          newMockUsedItemRepo(),
+	}
+	handler := NewReturnHandler(f.returns, f.orders, f.inventory, f.payments, f.provider)
+	handler.GiftCards = f.giftCards
+	handler.UsedItems = f.usedItems
+	f.router.POST("/orders/:id/returns", handler.PostReturn)
+	f.router.GET("/orders/:id/returns", handler.GetOrderReturns)
+	f.router.GET("/returns/:id", handler.GetMyReturn)
+	f.router.POST("/returns/:id/cancel", handler.CancelMyReturn)
+	f.router.GET("/admin/returns", handler.ListReturns)
+	f.router.GET("/admin/returns/:id", handler.GetReturn)
+	f.router.POST("/admin/returns/:id/approve", handler.ApproveReturn)
+	f.router.POST("/admin/returns/:id/reject", handler.RejectReturn)
+	f.router.POST("/admin/returns/:id/receive", handler.ReceiveReturn)
+	f.router.POST("/admin/returns/:id/refund", handler.RefundReturn)
+	return f
+}
+
+// shippedOrder checks out, pays for and ships an order for user-1.
+func (f returnTestFixture) shippedOrder(t *testing.T, items ...repository.CartItem) repository.Order {
+	t.Helper()
+	order := f.checkout(t, items...)
+	require.Equal(t, http.StatusCreated, f.pay(order.ID, "key-"+order.ID, payments.FakeMethodSuccess).Code)
+	order, err := f.orders.UpdateStatus(order.ID, repository.OrderShipped, "staff-1", "")
+	require.NoError(t, err)
+	return order
+}
+
+// approvedReturn requests a return of one LP of album 1 from the order and approves it.
+func (f returnTestFixture) approvedReturn(t *testing.T, order repository.Order) repository.Return {
+	t.Helper()
+	w := doRequest(f.router, "POST", "/orders/"+order.ID+"/returns", "user-1",
+		`{"lines":[{"albumId":"1","format":"lp","quantity":1,"reason":"warped"}]}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	ret := decodeJSON[repository.Return](t, w.Body.Bytes())
+	w = doRequest(f.router, "POST", "/admin/returns/"+ret.ID+"/approve", "staff-1", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	return decodeJSON[repository.Return](t, w.Body.Bytes())
+}
+
+func Test_Share_AddsUpToLineTotal(t *testing.T) {
+	paid := money.MustParse("10.00")
+	first := share(paid, 3, 1)
+	second := share(paid, 3, 2) - share(paid, 3, 1)
+	third := share(paid, 3, 3) - share(paid, 3, 2)
+
+	assert.Equal(t, money.MustParse("3.33"), first)
+	assert.Equal(t, money.MustParse("3.34"), second)
+	assert.Equal(t, paid, first+second+third)
+}
+
+func Test_PostReturn_Success(t *testing.T) {
+	f := setupReturnRouter()
+	order := f.shippedOrder(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 2})
+
+	w := doRequest(f.router, "POST", "/orders/"+order.ID+"/returns", "user-1",
+		`{"lines":[{"albumId":"1","format":"lp","quantity":1,"reason":"scratched","note":"side B skips"}]}`)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	ret := decodeJSON[repository.Return](t, w.Body.Bytes())
+	assert.Equal(t, repository.ReturnRequested, ret.Status)
+	assert.Equal(t, order.ID, ret.OrderID)
+	require.Len(t, ret.Lines, 1)
+	assert.Equal(t, repository.ReturnScratched, ret.Lines[0].Reason)
+	assert.Equal(t, "Thriller", ret.Lines[0].Title)
+	assert.Equal(t, money.MustParse("25.99"), ret.Lines[0].Refund)
+	assert.Equal(t, money.MustParse("25.99"), ret.RefundAmount)
+}
+
+func Test_PostReturn_OrderNotShipped(t *testing.T) {
+	f := setupReturnRouter()
+	order := f.checkout(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+
+	w := doRequest(f.router, "POST", "/orders/"+order.ID+"/returns", "user-1",
+		`{"lines":[{"albumId":"1","format":"lp","quantity":1,"reason":"scratched"}]}`)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func Test_PostReturn_InvalidLines(t *testing.T) {
+	f := setupReturnRouter()
+	order := f.shippedOrder(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+
+	for name, body := range map[string]string{
+		"no lines":       `{"lines":[]}`,
+		"not in order":   `{"lines":[{"albumId":"2","format":"cd","quantity":1,"reason":"warped"}]}`,
+		"too many":       `{"lines":[{"albumId":"1","format":"lp","quantity":2,"reason":"warped"}]}`,
+		"zero quantity":  `{"lines":[{"albumId":"1","format":"lp","quantity":0,"reason":"warped"}]}`,
+		"unknown reason": `{"lines":[{"albumId":"1","format":"lp","quantity":1,"reason":"bored"}]}`,
+	} {
+		w := doRequest(f.router, "POST", "/orders/"+order.ID+"/returns", "user-1", body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, name)
+	}
+}
+
+func Test_PostReturn_CountsOpenReturns(t *testing.T) {
+	f := setupReturnRouter()
+	order := f.shippedOrder(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+	body := `{"lines":[{"albumId":"1","format":"lp","quantity":1,"reason":"warped"}]}`
+	first := decodeJSON[repository.Return](t, doRequest(f.router, "POST", "/orders/"+order.ID+"/returns", "user-1", body).Body.Bytes())
+
+	w := doRequest(f.router, "POST", "/orders/"+order.ID+"/returns", "user-1", body)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "the only copy is already being returned")
+
+	doRequest(f.router, "POST", "/returns/"+first.ID+"/cancel", "user-1", "")
+	w = doRequest(f.router, "POST", "/orders/"+order.ID+"/returns", "user-1", body)
+	assert.Equal(t, http.StatusCreated, w.Code, "a cancelled return frees the quantity")
+}
+
+func Test_PostReturn_ReleaseLines(t *testing.T) {
+	f := setupReturnRouter()
+	original, _ := f.releases.Create(repository.Release{AlbumID: "1", Format: repository.FormatLP, Edition: "Original", Price: money.MustParse("60.00"), Currency: "GBP"})
+	order := f.shippedOrder(t,
+		repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1},
+		repository.CartItem{AlbumID: "1", ReleaseID: original.ID, Format: repository.FormatLP, Quantity: 1},
+	)
+
+	w := doRequest(f.router, "POST", "/orders/"+order.ID+"/returns", "user-1",
+		`{"lines":[{"albumId":"1","releaseId":"`+original.ID+`","format":"lp","quantity":1,"reason":"warped"}]}`)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	ret := decodeJSON[repository.Return](t, w.Body.Bytes())
+	require.Len(t, ret.Lines, 1)
+	assert.Equal(t, original.ID, ret.Lines[0].ReleaseID)
+	assert.Equal(t, money.MustParse("60.00"), ret.Lines[0].Refund, "the release's line is refunded at its price")
+
+	w = doRequest(f.router, "POST", "/orders/"+order.ID+"/returns", "user-1",
+		`{"lines":[{"albumId":"1","format":"lp","quantity":1,"reason":"warped"}]}`)
+	require.Equal(t, http.StatusCreated, w.Code, "the album's own line is still returnable")
+	assert.Equal(t, money.MustParse("25.99"), decodeJSON[repository.Return](t, w.Body.Bytes()).RefundAmount)
+}
+
+func Test_PostReturn_OtherUsersOrder(t *testing.T) {
+	f := setupReturnRouter()
+	order := f.shippedOrder(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+
+	w := doRequest(f.router, "POST", "/orders/"+order.ID+"/returns", "user-2",
+		`{"lines":[{"albumId":"1","format":"lp","quantity":1,"reason":"warped"}]}`)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_GetMyReturn_OtherUser(t *testing.T) {
+	f := setupReturnRouter()
+	ret := f.approvedReturn(t, f.shippedOrder(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1}))
+
+	assert.Equal(t, http.StatusOK, doRequest(f.router, "GET", "/returns/"+ret.ID, "user-1", "").Code)
+	assert.Equal(t, http.StatusNotFound, doRequest(f.router, "GET", "/returns/"+ret.ID, "user-2", "").Code)
+}
+
+func Test_ListReturns_FiltersByStatus(t *testing.T) {
+	f := setupReturnRouter()
+	f.approvedReturn(t, f.shippedOrder(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1}))
+
+	var returns []repository.Return
+	w := doRequest(f.router, "GET", "/admin/returns?status=approved", "staff-1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &returns))
+	assert.Len(t, returns, 1)
+
+	w = doRequest(f.router, "GET", "/admin/returns?status=requested", "staff-1", "")
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &returns))
+	assert.Empty(t, returns)
+
+	w = doRequest(f.router, "GET", "/admin/returns?status=lost", "staff-1", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_RejectReturn_IsFinal(t *testing.T) {
+	f := setupReturnRouter()
+	order := f.shippedOrder(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+	ret := decodeJSON[repository.Return](t, doRequest(f.router, "POST", "/orders/"+order.ID+"/returns", "user-1",
+		`{"lines":[{"albumId":"1","format":"lp","quantity":1,"reason":"changed-mind"}]}`).Body.Bytes())
+
+	w := doRequest(f.router, "POST", "/admin/returns/"+ret.ID+"/reject", "staff-1", `{"note":"outside the return window"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	rejected := decodeJSON[repository.Return](t, w.Body.Bytes())
+	assert.Equal(t, repository.ReturnRejected, rejected.Status)
+	assert.Equal(t, "outside the return window", rejected.History[len(rejected.History)-1].Note)
+
+	w = doRequest(f.router, "POST", "/admin/returns/"+ret.ID+"/approve", "staff-1", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func Test_ReceiveReturn_RestocksNewItems(t *testing.T) {
+	f := setupReturnRouter()
+	ret := f.approvedReturn(t, f.shippedOrder(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 2}))
+	before := f.inventory.level("1", repository.FormatLP).OnHand
+
+	w := doRequest(f.router, "POST", "/admin/returns/"+ret.ID+"/receive", "staff-1",
+		`{"lines":[{"albumId":"1","format":"lp","restock":"new"}]}`)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	received := decodeJSON[repository.Return](t, w.Body.Bytes())
+	assert.Equal(t, repository.ReturnReceived, received.Status)
+	assert.Equal(t, repository.RestockNew, received.Lines[0].Restock)
+	assert.Equal(t, before+1, f.inventory.level("1", repository.FormatLP).OnHand)
+	adjustments, _ := f.inventory.ListAdjustments("1")
+	last := adjustments[len(adjustments)-1]
+	assert.Equal(t, repository.ReasonReturned, last.Reason)
+	assert.Equal(t, "return "+ret.ID, last.Note)
+}
+
+func Test_ReceiveReturn_UsedNeedsGrades(t *testing.T) {
+	f := setupReturnRouter()
+	ret := f.approvedReturn(t, f.shippedOrder(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1}))
+	before := f.inventory.level("1", repository.FormatLP).OnHand
+
+	w := doRequest(f.router, "POST", "/admin/returns/"+ret.ID+"/receive", "staff-1",
+		`{"lines":[{"albumId":"1","format":"lp","restock":"used"}]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doRequest(f.router, "POST", "/admin/returns/"+ret.ID+"/receive", "staff-1",
+		`{"lines":[{"albumId":"1","format":"lp","restock":"used","mediaGrade":"vg+","sleeveGrade":"VG"}]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	received := decodeJSON[repository.Return](t, w.Body.Bytes())
+	assert.Equal(t, repository.Grade("VG+"), received.Lines[0].MediaGrade)
+	assert.Equal(t, repository.Grade("VG"), received.Lines[0].SleeveGrade)
+	assert.Equal(t, before, f.inventory.level("1", repository.FormatLP).OnHand, "used items are not new stock")
+}
+
+func Test_ReceiveReturn_ListsUsedItems(t *testing.T) {
+	f := setupReturnRouter()
+	ret := f.approvedReturn(t, f.shippedOrder(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1}))
+
+	w := doRequest(f.router, "POST", "/admin/returns/"+ret.ID+"/receive", "staff-1",
+		`{"lines":[{"albumId":"1","format":"lp","restock":"used","mediaGrade":"VG+","sleeveGrade":"G+"}]}`)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	items, err := f.usedItems.List(repository.UsedItemFilter{AlbumID: "1"})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, repository.Grade("VG+"), items[0].MediaGrade)
+	assert.Equal(t, repository.Grade("G+"), items[0].SleeveGrade)
+	assert.Equal(t, ret.Lines[0].Refund, items[0].Price, "used copies are priced at what was refunded for them")
+	assert.Equal(t, repository.UsedItemAvailable, items[0].Status)
+}
+
+func Test_ReceiveReturn_ResumesAfterFailure(t *testing.T) {
+	f := setupReturnRouter()
+	order := f.shippedOrder(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 2})
+	ret := decodeJSON[repository.Return](t, doRequest(f.router, "POST", "/orders/"+order.ID+"/returns", "user-1",
+		`{"lines":[{"albumId":"1","format":"lp","quantity":2,"reason":"warped"}]}`).Body.Bytes())
+	require.Equal(t, http.StatusOK, doRequest(f.router, "POST", "/admin/returns/"+ret.ID+"/approve", "staff-1", "").Code)
+	creates := 0
+	f.usedItems.failCreate = func() error {
+		if creates++; creates > 1 {
+			return errors.New("used items unavailable")
+		}
+		return nil
+	}
+
+	w := doRequest(f.router, "POST", "/admin/returns/"+ret.ID+"/receive", "staff-1",
+		`{"lines":[{"albumId":"1","format":"lp","restock":"used","mediaGrade":"VG","sleeveGrade":"VG"}]}`)
+	require.Equal(t, http.StatusInternalServerError, w.Code, w.Body.String())
+	require.Len(t, f.usedItems.items, 1)
+	stuck, _ := f.returns.GetByID(ret.ID)
+	assert.Equal(t, repository.ReturnReceived, stuck.Status)
+	assert.Equal(t, 1, stuck.Lines[0].Restocked, "the unit that failed is not counted")
+
+	f.usedItems.failCreate = nil
+	w = doRequest(f.router, "POST", "/admin/returns/"+ret.ID+"/receive", "staff-1", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, 2, decodeJSON[repository.Return](t, w.Body.Bytes()).Lines[0].Restocked)
+	assert.Len(t, f.usedItems.items, 2)
+
+	w = doRequest(f.router, "POST", "/admin/returns/"+ret.ID+"/receive", "staff-1", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Len(t, f.usedItems.items, 2, "receiving again restocks nothing twice")
+}
+
+func Test_ReceiveReturn_EveryLineRequired(t *testing.T) {
+	f := setupReturnRouter()
+	ret := f.approvedReturn(t, f.shippedOrder(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1}))
+
+	w := doRequest(f.router, "POST", "/admin/returns/"+ret.ID+"/receive", "staff-1",
+		`{"lines":[{"albumId":"2","format":"cd","restock":"none"}]}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_ReceiveReturn_NotApproved(t *testing.T) {
+	f := setupReturnRouter()
+	order := f.shippedOrder(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+	ret := decodeJSON[repository.Return](t, doRequest(f.router, "POST", "/orders/"+order.ID+"/returns", "user-1",
+		`{"lines":[{"albumId":"1","format":"lp","quantity":1,"reason":"warped"}]}`).Body.Bytes())
+
+	w := doRequest(f.router, "POST", "/admin/returns/"+ret.ID+"/receive", "staff-1",
+		`{"lines":[{"albumId":"1","format":"lp","restock":"none"}]}`)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func Test_RefundReturn_RefundsPayment(t *testing.T) {
+	f := setupReturnRouter()
+	order := f.shippedOrder(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 2})
+	ret := f.approvedReturn(t, order)
+	doRequest(f.router, "POST", "/admin/returns/"+ret.ID+"/receive", "staff-1",
+		`{"lines":[{"albumId":"1","format":"lp","restock":"none"}]}`)
+
+	w := doRequest(f.router, "POST", "/admin/returns/"+ret.ID+"/refund", "staff-1", "")
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	refunded := decodeJSON[repository.Return](t, w.Body.Bytes())
+	assert.Equal(t, repository.ReturnRefunded, refunded.Status)
+	assert.Equal(t, money.MustParse("25.99"), refunded.RefundedAmount)
+	intents, _ := f.payments.ListByOrder(order.ID)
+	assert.Equal(t, money.MustParse("25.99"), intents[0].RefundedAmount)
+
+	w = doRequest(f.router, "POST", "/admin/returns/"+ret.ID+"/refund", "staff-1", "")
+	assert.Equal(t, http.StatusConflict, w.Code, "a return is only refunded once")
+}
+
+func Test_RefundReturn_KeepsRestockingFee(t *testing.T) {
+	f := setupReturnRouter()
+	ret := f.approvedReturn(t, f.shippedOrder(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1}))
+	doRequest(f.router, "POST", "/admin/returns/"+ret.ID+"/receive", "staff-1",
+		`{"lines":[{"albumId":"1","format":"lp","restock":"new"}]}`)
+
+	w := doRequest(f.router, "POST", "/admin/returns/"+ret.ID+"/refund", "staff-1", `{"amount":30}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "cannot refund more than the items cost")
+
+	w = doRequest(f.router, "POST", "/admin/returns/"+ret.ID+"/refund", "staff-1", `{"amount":20,"note":"restocking fee"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, money.MustParse("20.00"), decodeJSON[repository.Return](t, w.Body.Bytes()).RefundedAmount)
+}
+
+func Test_RefundReturn_AsStoreCredit(t *testing.T) {
+	f := setupReturnRouter()
+	order := f.shippedOrder(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+	ret := f.approvedReturn(t, order)
+	doRequest(f.router, "POST", "/admin/returns/"+ret.ID+"/receive", "staff-1",
+		`{"lines":[{"albumId":"1","format":"lp","restock":"new"}]}`)
+
+	w := doRequest(f.router, "POST", "/admin/returns/"+ret.ID+"/refund", "staff-1", `{"storeCredit":true}`)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, money.MustParse("25.99"), decodeJSON[repository.Return](t, w.Body.Bytes()).RefundedAmount)
+	credit, err := f.giftCards.GetStoreCredit("user-1")
+	require.NoError(t, err)
+	assert.Equal(t, money.MustParse("25.99"), credit.Balance)
+	intents, _ := f.payments.ListByOrder(order.ID)
+	assert.Equal(t, money.Amount(0), intents[0].RefundedAmount, "the card payment is left alone")
+}
+
+func Test_RefundReturn_NothingToRefund(t *testing.T) {
+	f := setupReturnRouter()
+	order := f.shippedOrder(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+	ret := f.approvedReturn(t, order)
+	doRequest(f.router, "POST", "/admin/returns/"+ret.ID+"/receive", "staff-1",
+		`{"lines":[{"albumId":"1","format":"lp","restock":"none"}]}`)
+	intents, _ := f.payments.ListByOrder(order.ID)
+	doRequest(f.router, "POST", "/admin/payments/"+intents[0].ID+"/refund", "staff-1", "")
+
+	w := doRequest(f.router, "POST", "/admin/returns/"+ret.ID+"/refund", "staff-1", "")
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	stuck, _ := f.returns.GetByID(ret.ID)
+	assert.Equal(t, repository.ReturnReceived, stuck.Status, "a failed refund leaves the return to retry")
+}
+
+func Test_CancelMyReturn_AfterReceived(t *testing.T) {
+	f := setupReturnRouter()
+	ret := f.approvedReturn(t, f.shippedOrder(t, repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1}))
+	doRequest(f.router, "POST", "/admin/returns/"+ret.ID+"/receive", "staff-1",
+		`{"lines":[{"albumId":"1","format":"lp","restock":"none"}]}`)
+
+	w := doRequest(f.router, "POST", "/returns/"+ret.ID+"/cancel", "user-1", "")
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// In-memory mock implementation of ReturnRepository for testing
+
+type mockReturnRepo struct {
+	returns []repository.Return
+}
+
+func newMockReturnRepo() *mockReturnRepo {
+	return &mockReturnRepo{}
+}
+
+func (m *mockReturnRepo) Create(ret repository.Return) (repository.Return, error) {
+	ret.ID = fmt.Sprintf("return-%d", len(m.returns)+1)
+	ret.Status = repository.ReturnRequested
+	ret.CreatedAt = time.Now()
+	ret.UpdatedAt = ret.CreatedAt
+	ret.Lines = append([]repository.ReturnLine(nil), ret.Lines...)
+	ret.History = []repository.ReturnEvent{{Status: repository.ReturnRequested, Actor: ret.UserID, CreatedAt: ret.CreatedAt}}
+	m.returns = append(m.returns, ret)
+	return ret, nil
+}
+
+func (m *mockReturnRepo) GetByID(id string) (repository.Return, error) {
+	for _, ret := range m.returns {
+		if ret.ID == id {
+			return ret, nil
+		}
+	}
+	return repository.Return{}, repository.ErrReturnNotFound
+}
+
+func (m *mockReturnRepo) filter(match func(repository.Return) bool) ([]repository.Return, error) {
+	var returns []repository.Return
+	for _, ret := range m.returns {
+		if match(ret) {
+			returns = append(returns, ret)
+		}
+	}
+	return returns, nil
+}
+
+func (m *mockReturnRepo) ListByOrder(orderID string) ([]repository.Return, error) {
+	return m.filter(func(ret repository.Return) bool { return ret.OrderID == orderID })
+}
+
+func (m *mockReturnRepo) ListByUser(userID string) ([]repository.Return, error) {
+	return m.filter(func(ret repository.Return) bool { return ret.UserID == userID })
+}
+
+func (m *mockReturnRepo) List(status repository.ReturnStatus) ([]repository.Return, error) {
+	return m.filter(func(ret repository.Return) bool { return status == "" || ret.Status == status })
+}
+
+func (m *mockReturnRepo) Update(ret repository.Return, from repository.ReturnStatus, actor, note string) (repository.Return, error) {
+	for i, existing := range m.returns {
+		if existing.ID != ret.ID {
+			continue
+		}
+		if existing.Status != from {
+			return repository.Return{}, repository.ErrReturnStatusChanged
+		}
+		existing.Status = ret.Status
+		existing.RefundedAmount = ret.RefundedAmount
+		existing.Lines = append([]repository.ReturnLine(nil), ret.Lines...)
+		existing.UpdatedAt = time.Now()
+		existing.History = append(existing.History, repository.ReturnEvent{Status: ret.Status, Actor: actor, Note: note, CreatedAt: existing.UpdatedAt})
+		m.returns[i] = existing
+		return existing, nil
+	}
+	return repository.Return{}, repository.ErrReturnNotFound
+}
+
+func (m *mockReturnRepo) SetRestocked(id string, line, from, to int) error {
+	for _, ret := range m.returns {
+		if ret.ID != id {
+			continue
+		}
+		if ret.Lines[line].Restocked != from {
+			return repository.ErrReturnStatusChanged
+		}
+		ret.Lines[line].Restocked = to
+		return nil
+	}
+	return repository.ErrReturnNotFound
+}
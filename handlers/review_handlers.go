@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+type ReviewHandler struct {
+	Repo   repository.ReviewRepository
+	Albums repository.AlbumRepository
+}
+
+func NewReviewHandler(repo repository.ReviewRepository, albums repository.AlbumRepository) *ReviewHandler {
+	return &ReviewHandler{Repo: repo, Albums: albums}
+}
+
+// ReviewRequest is the body accepted by POST /albums/:id/reviews. Author is the name shown with the review.
+type ReviewRequest struct {
+	Rating int    `json:"rating" binding:"required"`
+	Author string `json:"author" binding:"required"`
+	Title  string `json:"title"`
+	Body   string `json:"body" binding:"required"`
+}
+
+func respondWithReviewError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrReviewNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "review not found"})
+	case errors.Is(err, repository.ErrDuplicateReview), errors.Is(err, repository.ErrReviewStatusChanged):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// GetAlbumReviews handles GET /albums/:id/reviews, listing the album's approved reviews, newest first.
+// Reviewers' user IDs are not shown.
+func (h *ReviewHandler) GetAlbumReviews(c *gin.Context) {
+	albumID, ok := getAlbumIDFromUri(c)
+	if !ok {
+		return
+	}
+	if _, err := h.Albums.GetByID(albumID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "album not found"})
+		return
+	}
+	reviews, err := h.Repo.ListByAlbum(albumID, repository.ReviewApproved)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if reviews == nil {
+		reviews = []repository.Review{}
+	}
+	for i := range reviews {
+		reviews[i].UserID = ""
+	}
+	c.IndentedJSON(http.StatusOK, reviews)
+}
+
+// PostReview handles POST /albums/:id/reviews. The review waits for moderation before it is shown
+// or counted in the album's rating. Each user can review an album once.
+func (h *ReviewHandler) PostReview(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	albumID, ok := getAlbumIDFromUri(c)
+	if !ok {
+		return
+	}
+	var req ReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	review := repository.Review{
+		AlbumID: albumID,
+		UserID:  userID,
+		Author:  strings.TrimSpace(req.Author),
+		Rating:  req.Rating,
+		Title:   strings.TrimSpace(req.Title),
+		Body:    strings.TrimSpace(req.Body),
+		Status:  repository.ReviewPending,
+	}
+	if err := review.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := h.Albums.GetByID(albumID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "album not found"})
+		return
+	}
+	created, err := h.Repo.Create(review)
+	if err != nil {
+		respondWithReviewError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusCreated, created)
+}
+
+// DeleteMyReview handles DELETE /reviews/:id. Customers can only delete their own reviews; once
+// deleted they can review the album again.
+func (h *ReviewHandler) DeleteMyReview(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	review, err := h.Repo.GetByID(c.Param("id"))
+	if err == nil && review.UserID != userID {
+		err = repository.ErrReviewNotFound
+	}
+	if err == nil {
+		err = h.Repo.Delete(review.ID)
+	}
+	if err != nil {
+		respondWithReviewError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListReviews handles GET /admin/reviews, optionally filtered with ?status=pending.
+func (h *ReviewHandler) ListReviews(c *gin.Context) {
+	var status repository.ReviewStatus
+	if s := c.Query("status"); s != "" {
+		parsed, err := repository.ParseReviewStatus(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		status = parsed
+	}
+	reviews, err := h.Repo.List(status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if reviews == nil {
+		reviews = []repository.Review{}
+	}
+	c.IndentedJSON(http.StatusOK, reviews)
+}
+
+// ApproveReview handles POST /admin/reviews/:id/approve, publishing the review and counting it in
+// the album's rating.
+func (h *ReviewHandler) ApproveReview(c *gin.Context) {
+	h.moderate(c, repository.ReviewApproved)
+}
+
+// RejectReview handles POST /admin/reviews/:id/reject. Rejecting an approved review takes it down.
+func (h *ReviewHandler) RejectReview(c *gin.Context) {
+	h.moderate(c, repository.ReviewRejected)
+}
+
+func (h *ReviewHandler) moderate(c *gin.Context, status repository.ReviewStatus) {
+	review, err := h.Repo.GetByID(c.Param("id"))
+	if err != nil {
+		respondWithReviewError(c, err)
+		return
+	}
+	if review.Status == status {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("review is already %s", status)})
+		return
+	}
+	updated, err := h.Repo.SetStatus(review.ID, review.Status, status)
+	if err != nil {
+		respondWithReviewError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, updated)
+}
+
+// DeleteReview handles DELETE /admin/reviews/:id.
+func (h *ReviewHandler) DeleteReview(c *gin.Context) {
+	if err := h.Repo.Delete(c.Param("id")); err != nil {
+		respondWithReviewError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+func setupReviewRouter(reviews *mockReviewRepo) *gin.Engine {
+	albums := newTestHandler()
+	albums.Reviews = reviews
+	handler := NewReviewHandler(reviews, albums.Repo)
+	r := gin.Default()
+	r.GET("/albums", albums.GetAlbums)
+	r.GET("/albums/:id", albums.GetAlbumByID)
+	r.GET("/albums/:id/reviews", handler.GetAlbumReviews)
+	r.POST("/albums/:id/reviews", handler.PostReview)
+	r.DELETE("/reviews/:id", handler.DeleteMyReview)
+	r.GET("/admin/reviews", handler.ListReviews)
+	r.POST("/admin/reviews/:id/approve", handler.ApproveReview)
+	r.POST("/admin/reviews/:id/reject", handler.RejectReview)
+	r.DELETE("/admin/reviews/:id", handler.DeleteReview)
+	return r
+}
+
+// approvedReview posts a review as userID and approves it.
+func approvedReview(t *testing.T, r *gin.Engine, albumID, userID string, rating int) repository.Review {
+	t.Helper()
+	body := fmt.Sprintf(`{"rating":%d,"author":%q,"body":"Worth a listen."}`, rating, userID)
+	w := doRequest(r, "POST", "/albums/"+albumID+"/reviews", userID, body)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var review repository.Review
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &review))
+	w = doRequest(r, "POST", "/admin/reviews/"+review.ID+"/approve", "staff", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &review))
+	return review
+}
+
+func Test_PostReview_WaitsForModeration(t *testing.T) {
+	reviews := newMockReviewRepo()
+	r := setupReviewRouter(reviews)
+
+	w := doRequest(r, "POST", "/albums/1/reviews", "user-1", `{"rating":5,"author":"Ann","title":"Classic","body":" A masterpiece. "}`)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var review repository.Review
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &review))
+	assert.Equal(t, repository.ReviewPending, review.Status)
+	assert.Equal(t, "A masterpiece.", review.Body)
+
+	w = doRequest(r, "GET", "/albums/1/reviews", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[]`, w.Body.String(), "pending reviews are not shown")
+}
+
+func Test_PostReview_OnePerUser(t *testing.T) {
+	r := setupReviewRouter(newMockReviewRepo())
+	body := `{"rating":4,"author":"Ann","body":"Great."}`
+
+	require.Equal(t, http.StatusCreated, doRequest(r, "POST", "/albums/1/reviews", "user-1", body).Code)
+	w := doRequest(r, "POST", "/albums/1/reviews", "user-1", body)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	assert.Equal(t, http.StatusCreated, doRequest(r, "POST", "/albums/2/reviews", "user-1", body).Code)
+	assert.Equal(t, http.StatusCreated, doRequest(r, "POST", "/albums/1/reviews", "user-2", body).Code)
+}
+
+func Test_PostReview_Invalid(t *testing.T) {
+	r := setupReviewRouter(newMockReviewRepo())
+
+	for name, body := range map[string]string{
+		"no rating":    `{"author":"Ann","body":"Great."}`,
+		"six stars":    `{"rating":6,"author":"Ann","body":"Great."}`,
+		"blank author": `{"rating":4,"author":" ","body":"Great."}`,
+		"no body":      `{"rating":4,"author":"Ann"}`,
+		"malformed":    `{"rating":`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := doRequest(r, "POST", "/albums/1/reviews", "user-1", body)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+
+	w := doRequest(r, "POST", "/albums/999/reviews", "user-1", `{"rating":4,"author":"Ann","body":"Great."}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	w = doRequest(r, "POST", "/albums/1/reviews", "", `{"rating":4,"author":"Ann","body":"Great."}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func Test_GetAlbumReviews_HidesUserIDs(t *testing.T) {
+	r := setupReviewRouter(newMockReviewRepo())
+	approvedReview(t, r, "1", "user-1", 5)
+	approvedReview(t, r, "1", "user-2", 3)
+
+	w := doRequest(r, "GET", "/albums/1/reviews", "", "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var reviews []repository.Review
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &reviews))
+	require.Len(t, reviews, 2)
+	assert.Equal(t, "user-2", reviews[0].Author, "newest first")
+	assert.NotContains(t, w.Body.String(), "userId")
+}
+
+func Test_Albums_IncludeRatings(t *testing.T) {
+	r := setupReviewRouter(newMockReviewRepo())
+	approvedReview(t, r, "1", "user-1", 5)
+	approvedReview(t, r, "1", "user-2", 4)
+	approvedReview(t, r, "1", "user-3", 4)
+
+	w := doRequest(r, "GET", "/albums/1", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var album repository.Album
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &album))
+	require.NotNil(t, album.Rating)
+	assert.Equal(t, 4.3, *album.Rating)
+	assert.Equal(t, 3, *album.ReviewCount)
+
+	w = doRequest(r, "GET", "/albums/2", "", "")
+	var unreviewed repository.Album
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &unreviewed))
+	assert.Nil(t, unreviewed.Rating)
+	assert.Equal(t, 0, *unreviewed.ReviewCount)
+}
+
+func Test_GetAlbums_SortByRating(t *testing.T) {
+	r := setupReviewRouter(newMockReviewRepo())
+	approvedReview(t, r, "1", "user-1", 3)
+	approvedReview(t, r, "101", "user-1", 5)
+	approvedReview(t, r, "2", "user-1", 5)
+	approvedReview(t, r, "2", "user-2", 5)
+
+	w := doRequest(r, "GET", "/albums?sort=rating", "", "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var albums []repository.Album
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &albums))
+	require.Len(t, albums, 3)
+	assert.Equal(t, "2", albums[0].ID, "ties are broken by the number of reviews")
+	assert.Equal(t, "101", albums[1].ID)
+	assert.Equal(t, "1", albums[2].ID)
+
+	w = doRequest(r, "GET", "/albums?sort=price", "", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_ModerateReview(t *testing.T) {
+	reviews := newMockReviewRepo()
+	r := setupReviewRouter(reviews)
+	review := approvedReview(t, r, "1", "user-1", 5)
+
+	w := doRequest(r, "POST", "/admin/reviews/"+review.ID+"/approve", "staff", "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = doRequest(r, "POST", "/admin/reviews/"+review.ID+"/reject", "staff", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	ratings, _ := reviews.Ratings()
+	assert.Empty(t, ratings, "a rejected review no longer counts")
+
+	w = doRequest(r, "GET", "/admin/reviews?status=rejected", "staff", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), review.ID)
+	w = doRequest(r, "GET", "/admin/reviews?status=hidden", "staff", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doRequest(r, "POST", "/admin/reviews/missing/approve", "staff", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_DeleteMyReview(t *testing.T) {
+	reviews := newMockReviewRepo()
+	r := setupReviewRouter(reviews)
+	review := approvedReview(t, r, "1", "user-1", 5)
+
+	w := doRequest(r, "DELETE", "/reviews/"+review.ID, "user-2", "")
+	assert.Equal(t, http.StatusNotFound, w.Code, "customers cannot delete other people's reviews")
+
+	w = doRequest(r, "DELETE", "/reviews/"+review.ID, "user-1", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, reviews.reviews)
+
+	w = doRequest(r, "POST", "/albums/1/reviews", "user-1", `{"rating":4,"author":"Ann","body":"Second thoughts."}`)
+	assert.Equal(t, http.StatusCreated, w.Code, "a deleted review can be written again")
+	w = doRequest(r, "DELETE", "/admin/reviews/review-2", "staff", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// In-memory mock implementation of ReviewRepository for testing
+
+type mockReviewRepo struct {
+	reviews []repository.Review
+	nextID  int
+}
+
+func newMockReviewRepo() *mockReviewRepo {
+	return &mockReviewRepo{}
+}
+
+func (m *mockReviewRepo) Create(review repository.Review) (repository.Review, error) {
+	for _, existing := range m.reviews {
+		if existing.AlbumID == review.AlbumID && existing.UserID == review.UserID {
+			return repository.Review{}, repository.ErrDuplicateReview
+		}
+	}
+	m.nextID++
+	review.ID = fmt.Sprintf("review-%d", m.nextID)
+	if review.Status == "" {
+		review.Status = repository.ReviewPending
+	}
+	// Space the timestamps out so newest-first ordering is deterministic.
+	review.CreatedAt = time.Now().Add(time.Duration(m.nextID) * time.Millisecond)
+	review.UpdatedAt = review.CreatedAt
+	m.reviews = append(m.reviews, review)
+	return review, nil
+}
+
+func (m *mockReviewRepo) GetByID(id string) (repository.Review, error) {
+	for _, review := range m.reviews {
+		if review.ID == id {
+			return review, nil
+		}
+	}
+	return repository.Review{}, repository.ErrReviewNotFound
+}
+
+func (m *mockReviewRepo) filter(match func(repository.Review) bool) ([]repository.Review, error) {
+	var reviews []repository.Review
+	for _, review := range m.reviews {
+		if match(review) {
+			reviews = append(reviews, review)
+		}
+	}
+	sort.SliceStable(reviews, func(i, j int) bool { return reviews[i].CreatedAt.After(reviews[j].CreatedAt) })
+	return reviews, nil
+}
+
+func (m *mockReviewRepo) ListByAlbum(albumID string, status repository.ReviewStatus) ([]repository.Review, error) {
+	return m.filter(func(review repository.Review) bool {
+		return review.AlbumID == albumID && (status == "" || review.Status == status)
+	})
+}
+
+func (m *mockReviewRepo) List(status repository.ReviewStatus) ([]repository.Review, error) {
+	return m.filter(func(review repository.Review) bool { return status == "" || review.Status == status })
+}
+
+func (m *mockReviewRepo) SetStatus(id string, from, to repository.ReviewStatus) (repository.Review, error) {
+	for i, review := range m.reviews {
+		if review.ID != id {
+			continue
+		}
+		if review.Status != from {
+			return repository.Review{}, repository.ErrReviewStatusChanged
+		}
+		review.Status = to
+		review.UpdatedAt = time.Now()
+		m.reviews[i] = review
+		return review, nil
+	}
+	return repository.Review{}, repository.ErrReviewNotFound
+}
+
+func (m *mockReviewRepo) Delete(id string) error {
+	for i, review := range m.reviews {
+		if review.ID == id {
+			m.reviews = append(m.reviews[:i], m.reviews[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrReviewNotFound
+}
+
+func (m *mockReviewRepo) Ratings() ([]repository.RatingSummary, error) {
+	totals := make(map[string][2]int)
+	for _, review := range m.reviews {
+		if review.Status == repository.ReviewApproved {
+			t := totals[review.AlbumID]
+			totals[review.AlbumID] = [2]int{t[0] + 1, t[1] + review.Rating}
+		}
+	}
+	var ratings []repository.RatingSummary
+	for albumID, t := range totals {
+		average := math.Round(float64(t[1])/float64(t[0])*10) / 10
+		ratings = append(ratings, repository.RatingSummary{AlbumID: albumID, Count: t[0], Average: average})
+	}
+	return ratings, nil
+}
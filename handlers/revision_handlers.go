@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+type RevisionHandler struct {
+	Repo   repository.RevisionRepository
+	Albums repository.AlbumRepository
+
+	// Prices is optional; when set, price changes made by rolling an album back are recorded in the
+	// price history.
+	Prices repository.PriceRepository
+}
+
+func NewRevisionHandler(repo repository.RevisionRepository, albums repository.AlbumRepository) *RevisionHandler {
+	return &RevisionHandler{Repo: repo, Albums: albums}
+}
+
+// RevisionDiffResponse is returned by GET /albums/:id/revisions/diff.
+type RevisionDiffResponse struct {
+	AlbumID string                   `json:"albumId"`
+	From    int                      `json:"from"`
+	To      int                      `json:"to"`
+	Changes []repository.FieldChange `json:"changes"`
+}
+
+func respondWithRevisionError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrRevisionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "revision not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+// parseRevisionNumber reads a revision number, which counts from 1. It writes an error response
+// and returns false if the value is not one.
+func parseRevisionNumber(c *gin.Context, name, value string) (int, bool) {
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s must be a revision number", name)})
+		return 0, false
+	}
+	return number, true
+}
+
+// GetRevisions handles GET /albums/:id/revisions, listing the album's revisions, newest first.
+func (h *RevisionHandler) GetRevisions(c *gin.Context) {
+	id, ok := getExistingAlbumID(c, h.Albums)
+	if !ok {
+		return
+	}
+	revisions, err := h.Repo.List(id)
+	if err != nil {
+		respondWithRevisionError(c, err)
+		return
+	}
+	if revisions == nil {
+		revisions = []repository.AlbumRevision{}
+	}
+	c.IndentedJSON(http.StatusOK, revisions)
+}
+
+// GetRevision handles GET /albums/:id/revisions/:number.
+func (h *RevisionHandler) GetRevision(c *gin.Context) {
+	id, ok := getExistingAlbumID(c, h.Albums)
+	if !ok {
+		return
+	}
+	number, ok := parseRevisionNumber(c, "number", c.Param("number"))
+	if !ok {
+		return
+	}
+	revision, err := h.Repo.Get(id, number)
+	if err != nil {
+		respondWithRevisionError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, revision)
+}
+
+// GetRevisionDiff handles GET /albums/:id/revisions/diff?from=N&to=M, listing the fields that
+// changed between two revisions. Without to, revision N is compared with the latest.
+func (h *RevisionHandler) GetRevisionDiff(c *gin.Context) {
+	id, ok := getExistingAlbumID(c, h.Albums)
+	if !ok {
+		return
+	}
+	fromNumber, ok := parseRevisionNumber(c, "from", c.Query("from"))
+	if !ok {
+		return
+	}
+	from, err := h.Repo.Get(id, fromNumber)
+	if err != nil {
+		respondWithRevisionError(c, err)
+		return
+	}
+	var to repository.AlbumRevision
+	if c.Query("to") == "" {
+		revisions, err := h.Repo.List(id)
+		if err != nil {
+			respondWithRevisionError(c, err)
+			return
+		}
+		to = revisions[0]
+	} else {
+		toNumber, ok := parseRevisionNumber(c, "to", c.Query("to"))
+		if !ok {
+			return
+		}
+		if to, err = h.Repo.Get(id, toNumber); err != nil {
+			respondWithRevisionError(c, err)
+			return
+		}
+	}
+	changes, err := from.Album.Diff(to.Album)
+	if err != nil {
+		respondWithRevisionError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, RevisionDiffResponse{AlbumID: id, From: from.Number, To: to.Number, Changes: changes})
+}
+
+// PostRollback handles POST /albums/:id/revisions/:number/rollback, putting the album back as it
+// was in that revision. The rollback is itself recorded as a new revision, which is returned.
+func (h *RevisionHandler) PostRollback(c *gin.Context) {
+	id, ok := getAlbumIDFromUri(c)
+	if !ok {
+		return
+	}
+	current, err := h.Albums.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "album not found"})
+		return
+	}
+	number, ok := parseRevisionNumber(c, "number", c.Param("number"))
+	if !ok {
+		return
+	}
+	target, err := h.Repo.Get(id, number)
+	if err != nil {
+		respondWithRevisionError(c, err)
+		return
+	}
+	album := target.Album.Album(id)
+	if err := h.Albums.Update(album); err != nil {
+		respondWithAlbumSaveError(c, err)
+		return
+	}
+	note := fmt.Sprintf("rolled back to revision %d", number)
+	recordPriceChange(c, "PostRollback", h.Prices, current, album, note)
+	revision, err := h.Repo.Record(repository.AlbumRevision{
+		AlbumID:      id,
+		Album:        target.Album,
+		Actor:        currentUserID(c),
+		Note:         note,
+		RolledBackTo: number,
+	})
+	if err != nil {
+		respondWithRevisionError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, revision)
+}
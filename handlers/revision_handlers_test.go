@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+func setupRevisionRouter() (*gin.Engine, *AlbumHandler, *mockPriceRepo) {
+	revisions := newMockRevisionRepo()
+	prices := newMockPriceRepo()
+	albums := newTestHandler()
+	albums.Revisions = revisions
+	albums.Prices = prices
+	handler := NewRevisionHandler(revisions, albums.Repo)
+	handler.Prices = prices
+
+	r := gin.Default()
+	r.PUT("/albums/:id", albums.PutAlbum)
+	r.GET("/albums/:id/revisions", handler.GetRevisions)
+	r.GET("/albums/:id/revisions/diff", handler.GetRevisionDiff)
+	r.GET("/albums/:id/revisions/:number", handler.GetRevision)
+	r.POST("/albums/:id/revisions/:number/rollback", handler.PostRollback)
+	return r, albums, prices
+}
+
+func getRevisions(t *testing.T, r *gin.Engine, albumID string) []repository.AlbumRevision {
+	t.Helper()
+	w := doRequest(r, "GET", "/albums/"+albumID+"/revisions", "", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var revisions []repository.AlbumRevision
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &revisions))
+	return revisions
+}
+
+func Test_PutAlbum_RecordsRevisions(t *testing.T) {
+	r, _, _ := setupRevisionRouter()
+	doRequest(r, "PUT", "/albums/1", "staff-1", `{"title":"Thriller (Remastered)","artist":"Michael Jackson","price":25.99,"year":1982,"imageUrl":"https://example.com/thriller.jpg","genre":"Pop"}`)
+	doRequest(r, "PUT", "/albums/1", "staff-2", `{"title":"Thriller (Remastered)","artist":"Michael Jackson","price":19.99,"year":1982,"imageUrl":"https://example.com/thriller.jpg","genre":"Pop"}`)
+
+	revisions := getRevisions(t, r, "1")
+
+	require.Len(t, revisions, 3, "the album as it was before its first edit is kept too")
+	assert.Equal(t, 3, revisions[0].Number)
+	assert.Equal(t, "staff-2", revisions[0].Actor)
+	assert.Equal(t, money.MustParse("19.99"), revisions[0].Album.Price)
+	assert.Equal(t, "Thriller (Remastered)", revisions[1].Album.Title)
+	assert.Equal(t, "Thriller", revisions[2].Album.Title)
+}
+
+func Test_GetRevisions_UnknownAlbum(t *testing.T) {
+	r, _, _ := setupRevisionRouter()
+
+	w := doRequest(r, "GET", "/albums/999/revisions", "", "")
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_GetRevision(t *testing.T) {
+	r, _, _ := setupRevisionRouter()
+	doRequest(r, "PUT", "/albums/1", "staff-1", `{"title":"Thriller (Remastered)","artist":"Michael Jackson","price":25.99,"year":1982,"imageUrl":"https://example.com/thriller.jpg","genre":"Pop"}`)
+
+	w := doRequest(r, "GET", "/albums/1/revisions/1", "", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var revision repository.AlbumRevision
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &revision))
+	assert.Equal(t, "Thriller", revision.Album.Title)
+
+	assert.Equal(t, http.StatusNotFound, doRequest(r, "GET", "/albums/1/revisions/9", "", "").Code)
+	assert.Equal(t, http.StatusBadRequest, doRequest(r, "GET", "/albums/1/revisions/first", "", "").Code)
+}
+
+func Test_GetRevisionDiff(t *testing.T) {
+	r, _, _ := setupRevisionRouter()
+	doRequest(r, "PUT", "/albums/1", "staff-1", `{"title":"Thriller (Remastered)","artist":"Michael Jackson","price":25.99,"year":1982,"imageUrl":"https://example.com/thriller.jpg","genre":"Pop"}`)
+	doRequest(r, "PUT", "/albums/1", "staff-1", `{"title":"Thriller (Remastered)","artist":"Michael Jackson","price":19.99,"year":1982,"imageUrl":"https://example.com/thriller.jpg","genre":"Pop","tags":["Staff Pick"]}`)
+
+	w := doRequest(r, "GET", "/albums/1/revisions/diff?from=1&to=2", "", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var diff RevisionDiffResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &diff))
+	require.Len(t, diff.Changes, 1)
+	assert.Equal(t, "title", diff.Changes[0].Field)
+	assert.JSONEq(t, `"Thriller"`, string(diff.Changes[0].From))
+	assert.JSONEq(t, `"Thriller (Remastered)"`, string(diff.Changes[0].To))
+
+	w = doRequest(r, "GET", "/albums/1/revisions/diff?from=1", "", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &diff))
+	assert.Equal(t, 3, diff.To, "without to, the latest revision is compared")
+	var fields []string
+	for _, change := range diff.Changes {
+		fields = append(fields, change.Field)
+	}
+	assert.Equal(t, []string{"price", "tags", "title"}, fields)
+
+	assert.Equal(t, http.StatusBadRequest, doRequest(r, "GET", "/albums/1/revisions/diff", "", "").Code)
+	assert.Equal(t, http.StatusNotFound, doRequest(r, "GET", "/albums/1/revisions/diff?from=1&to=7", "", "").Code)
+}
+
+func Test_PostRollback(t *testing.T) {
+	r, albums, prices := setupRevisionRouter()
+	doRequest(r, "PUT", "/albums/1", "staff-1", `{"title":"Thriller (Bad Edit)","artist":"Michael Jackson","price":2.59,"year":1982,"imageUrl":"https://example.com/thriller.jpg","genre":"Pop"}`)
+
+	w := doRequest(r, "POST", "/albums/1/revisions/1/rollback", "staff-2", "")
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var revision repository.AlbumRevision
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &revision))
+	assert.Equal(t, 3, revision.Number, "a rollback is a new revision")
+	assert.Equal(t, 1, revision.RolledBackTo)
+	assert.Equal(t, "staff-2", revision.Actor)
+	album, err := albums.Repo.GetByID("1")
+	require.NoError(t, err)
+	assert.Equal(t, "Thriller", album.Title)
+	assert.Equal(t, money.MustParse("25.99"), album.Price)
+	require.Len(t, prices.changes, 2)
+	assert.Equal(t, "rolled back to revision 1", prices.changes[1].Note)
+	assert.Len(t, getRevisions(t, r, "1"), 3)
+}
+
+func Test_PostRollback_UnknownRevision(t *testing.T) {
+	r, _, _ := setupRevisionRouter()
+
+	assert.Equal(t, http.StatusNotFound, doRequest(r, "POST", "/albums/1/revisions/1/rollback", "staff-1", "").Code)
+	assert.Equal(t, http.StatusNotFound, doRequest(r, "POST", "/albums/999/revisions/1/rollback", "staff-1", "").Code)
+}
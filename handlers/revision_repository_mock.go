@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// In-memory mock implementation of RevisionRepository for testing
+
+type mockRevisionRepo struct {
+	revisions map[string][]repository.AlbumRevision
+}
+
+func newMockRevisionRepo() *mockRevisionRepo {
+	return &mockRevisionRepo{revisions: map[string][]repository.AlbumRevision{}}
+}
+
+func (m *mockRevisionRepo) Record(revision repository.AlbumRevision) (repository.AlbumRevision, error) {
+	revision.Number = len(m.revisions[revision.AlbumID]) + 1
+	revision.CreatedAt = time.Now()
+	m.revisions[revision.AlbumID] = append(m.revisions[revision.AlbumID], revision)
+	return revision, nil
+}
+
+func (m *mockRevisionRepo) List(albumID string) ([]repository.AlbumRevision, error) {
+	var revisions []repository.AlbumRevision
+	for i := len(m.revisions[albumID]) - 1; i >= 0; i-- {
+		revisions = append(revisions, m.revisions[albumID][i])
+	}
+	return revisions, nil
+}
+
+func (m *mockRevisionRepo) Get(albumID string, number int) (repository.AlbumRevision, error) {
+	if number < 1 || number > len(m.revisions[albumID]) {
+		return repository.AlbumRevision{}, repository.ErrRevisionNotFound
+	}
+	return m.revisions[albumID][number-1], nil
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+	"github.com/tvergilio/motown-house-backend/tax"
+)
+
+type TaxHandler struct {
+	Repo repository.TaxRateRepository
+}
+
+func NewTaxHandler(repo repository.TaxRateRepository) *TaxHandler {
+	return &TaxHandler{Repo: repo}
+}
+
+// TaxRatesRequest is the body accepted by PUT /admin/tax-rates. It replaces the whole table.
+type TaxRatesRequest struct {
+	Rates []TaxRateRequest `json:"rates" binding:"required"`
+}
+
+// TaxRateRequest is one rate in a tax-rate upload. Region and category are optional; leaving them
+// out makes the rate apply to the whole country or to every category.
+type TaxRateRequest struct {
+	Country  string  `json:"country"`
+	Region   string  `json:"region"`
+	Category string  `json:"category"`
+	Name     string  `json:"name"`
+	Rate     float64 `json:"rate"`
+}
+
+// rates validates the upload and returns the rates to store.
+func (req TaxRatesRequest) rates() ([]repository.TaxRate, error) {
+	seen := make(map[string]bool, len(req.Rates))
+	rates := make([]repository.TaxRate, 0, len(req.Rates))
+	for _, r := range req.Rates {
+		dest, err := tax.ParseDestination(r.Country, r.Region)
+		if err != nil {
+			return nil, err
+		}
+		rate := repository.TaxRate{
+			Country:  dest.Country,
+			Region:   dest.Region,
+			Category: tax.NormalizeCategory(r.Category),
+			Name:     strings.TrimSpace(r.Name),
+			Rate:     r.Rate,
+		}
+		if seen[rate.Key()] {
+			return nil, fmt.Errorf("%s is listed more than once", rate.Key())
+		}
+		seen[rate.Key()] = true
+		if err := rate.Validate(); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
+
+// loadTaxRates reads the tax-rate table. Without a repository there are no rates, so no tax is charged.
+func loadTaxRates(repo repository.TaxRateRepository) ([]repository.TaxRate, error) {
+	if repo == nil {
+		return nil, nil
+	}
+	return repo.List()
+}
+
+// taxDisplay is how the caller asked to see album prices: for which destination, and whether
+// including tax.
+type taxDisplay struct {
+	dest      tax.Destination
+	inclusive bool
+}
+
+// requestedTaxDisplay reads ?country=, ?region= and ?taxDisplay=inclusive|exclusive. It returns nil
+// if the caller asked for none of them, in which case prices are shown as stored. The destination
+// defaults to the shop's home country and the display to however the stored prices are held.
+func requestedTaxDisplay(c *gin.Context, policy tax.Policy) (*taxDisplay, error) {
+	country, region, mode := c.Query("country"), c.Query("region"), c.Query("taxDisplay")
+	if country == "" && region == "" && mode == "" {
+		return nil, nil
+	}
+	if country == "" {
+		country = policy.Home.Country
+	}
+	if strings.TrimSpace(country) == "" {
+		return nil, errors.New("country is required to show tax")
+	}
+	dest, err := tax.ParseDestination(country, region)
+	if err != nil {
+		return nil, err
+	}
+	display := &taxDisplay{dest: dest, inclusive: policy.PricesIncludeTax}
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "":
+	case "inclusive":
+		display.inclusive = true
+	case "exclusive":
+		display.inclusive = false
+	default:
+		return nil, fmt.Errorf("invalid taxDisplay %q: must be inclusive or exclusive", mode)
+	}
+	return display, nil
+}
+
+// apply shows the album's price, and sale price if it has one, with or without tax. The tax filled
+// in is the tax on what the customer would pay: the sale price when there is one.
+func (d taxDisplay) apply(album *repository.Album, rates []repository.TaxRate, policy tax.Policy) {
+	var percent float64
+	if rate, ok := tax.Find(rates, d.dest, album.TaxCategory); ok {
+		percent = rate.Rate
+	}
+	show := func(amount money.Amount) (money.Amount, money.Amount) {
+		net, due := tax.Split(amount, percent, policy.PricesIncludeTax)
+		if d.inclusive {
+			return net + due, due
+		}
+		return net, due
+	}
+	var due money.Amount
+	album.Price, due = show(album.Price)
+	if album.SalePrice != nil {
+		var sale money.Amount
+		sale, due = show(*album.SalePrice)
+		album.SalePrice = &sale
+	}
+	inclusive := d.inclusive
+	album.Tax = &due
+	album.PriceIncludesTax = &inclusive
+}
+
+// GetTaxRates handles GET /tax-rates, listing the rates charged by destination and category.
+func (h *TaxHandler) GetTaxRates(c *gin.Context) {
+	rates, err := h.Repo.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if rates == nil {
+		rates = []repository.TaxRate{}
+	}
+	c.IndentedJSON(http.StatusOK, rates)
+}
+
+// PutTaxRates handles PUT /admin/tax-rates, replacing the tax-rate table with the uploaded one.
+// Orders already placed keep the tax they were charged.
+func (h *TaxHandler) PutTaxRates(c *gin.Context) {
+	var req TaxRatesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	rates, err := req.rates()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	stored, err := h.Repo.Replace(rates)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if stored == nil {
+		stored = []repository.TaxRate{}
+	}
+	c.IndentedJSON(http.StatusOK, stored)
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+	"github.com/tvergilio/motown-house-backend/tax"
+)
+
+var testTaxRates = []repository.TaxRate{
+	{Country: "GB", Name: "VAT", Rate: 20},
+	{Country: "GB", Category: "zero-rated", Name: "VAT", Rate: 0},
+	{Country: "DE", Name: "MwSt", Rate: 19},
+	{Country: "US", Region: "CA", Name: "California sales tax", Rate: 7.25},
+}
+
+func setupTaxRouter(pricesIncludeTax bool) orderTestFixture {
+	f := setupOrderRouter()
+	rates := newMockTaxRateRepo(testTaxRates...)
+	policy := tax.Policy{PricesIncludeTax: pricesIncludeTax, Home: tax.Destination{Country: "GB"}}
+	orders := NewOrderHandler(f.orders, f.carts, f.albums)
+	orders.TaxRates = rates
+	orders.Tax = policy
+	albums := NewAlbumHandler(f.albums, nil)
+	albums.TaxRates = rates
+	albums.Tax = policy
+	admin := NewTaxHandler(rates)
+
+	r := gin.Default()
+	r.GET("/albums/:id", albums.GetAlbumByID)
+	r.POST("/checkout", orders.Checkout)
+	r.GET("/tax-rates", admin.GetTaxRates)
+	r.PUT("/admin/tax-rates", admin.PutTaxRates)
+	f.router = r
+	return f
+}
+
+// checkoutTo creates a cart with the given items and checks it out as user-1, shipping to country and region.
+func (f orderTestFixture) checkoutTo(t *testing.T, country, region string, items ...repository.CartItem) repository.Order {
+	t.Helper()
+	cart, _ := f.carts.Create(repository.Cart{Items: items})
+	w := doRequest(f.router, "POST", "/checkout", "user-1", `{"cartId":"`+cart.ID+`","country":"`+country+`","region":"`+region+`"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var order repository.Order
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &order))
+	return order
+}
+
+func Test_Checkout_AddsSalesTax(t *testing.T) {
+	f := setupTaxRouter(false)
+
+	order := f.checkoutTo(t, "us", "ca", repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 2})
+
+	assert.Equal(t, "US", order.Country)
+	assert.Equal(t, "CA", order.Region)
+	assert.False(t, order.TaxIncluded)
+	require.Len(t, order.Lines, 1)
+	assert.Equal(t, "California sales tax", order.Lines[0].TaxName)
+	assert.Equal(t, 7.25, order.Lines[0].TaxRate)
+	assert.Equal(t, tax.CategoryStandard, order.Lines[0].TaxCategory)
+	assert.Equal(t, money.MustParse("51.98"), order.Lines[0].LineTotal)
+	assert.Equal(t, money.MustParse("3.77"), order.Lines[0].Tax)
+	assert.Equal(t, money.MustParse("3.77"), order.Tax)
+	assert.Equal(t, money.MustParse("55.75"), order.Total, "tax is added on top of the line totals")
+}
+
+func Test_Checkout_VATIncludedInPrice(t *testing.T) {
+	f := setupTaxRouter(true)
+
+	order := f.checkoutTo(t, "", "", repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 2})
+
+	assert.Equal(t, "GB", order.Country, "the home country is assumed")
+	assert.True(t, order.TaxIncluded)
+	assert.Equal(t, "VAT", order.Lines[0].TaxName)
+	assert.Equal(t, money.MustParse("8.66"), order.Lines[0].Tax, "the VAT in 51.98 at 20%")
+	assert.Equal(t, money.MustParse("51.98"), order.Total, "the price already includes VAT")
+}
+
+func Test_Checkout_TaxByCategoryAndDestination(t *testing.T) {
+	f := setupTaxRouter(false)
+	album, _ := f.albums.GetByID("2")
+	album.TaxCategory = "zero-rated"
+	require.NoError(t, f.albums.Update(album))
+
+	order := f.checkoutTo(t, "GB", "",
+		repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1},
+		repository.CartItem{AlbumID: "2", Format: repository.FormatCD, Quantity: 1},
+	)
+	assert.Equal(t, money.MustParse("5.20"), order.Lines[0].Tax)
+	assert.Equal(t, "zero-rated", order.Lines[1].TaxCategory)
+	assert.Equal(t, money.Amount(0), order.Lines[1].Tax)
+	assert.Equal(t, money.MustParse("73.69"), order.Total)
+
+	order = f.checkoutTo(t, "JP", "", repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+	assert.Equal(t, money.Amount(0), order.Tax, "no tax is charged where there is no rate")
+	assert.Empty(t, order.Lines[0].TaxName)
+	assert.Equal(t, money.MustParse("25.99"), order.Total)
+}
+
+func Test_Checkout_InvalidCountry(t *testing.T) {
+	f := setupTaxRouter(false)
+	cart, _ := f.carts.Create(repository.Cart{Items: []repository.CartItem{{AlbumID: "1", Format: repository.FormatLP, Quantity: 1}}})
+
+	w := doRequest(f.router, "POST", "/checkout", "user-1", `{"cartId":"`+cart.ID+`","country":"United Kingdom"}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_GetAlbum_TaxDisplay(t *testing.T) {
+	testCases := []struct {
+		name             string
+		pricesIncludeTax bool
+		query            string
+		price            string
+		tax              string
+		inclusive        bool
+	}{
+		{"tax added for display", false, "?country=DE&taxDisplay=inclusive", "30.93", "4.94", true},
+		{"tax to be added", false, "?country=us&region=ca", "25.99", "1.88", false},
+		{"tax taken out for display", true, "?taxDisplay=exclusive", "21.66", "4.33", false},
+		{"stored price includes the tax", true, "?country=GB", "25.99", "4.33", true},
+		{"destination without a rate", true, "?country=JP&taxDisplay=exclusive", "25.99", "0.00", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := setupTaxRouter(tc.pricesIncludeTax)
+
+			w := doRequest(f.router, "GET", "/albums/1"+tc.query, "", "")
+
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			var album repository.Album
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &album))
+			assert.Equal(t, money.MustParse(tc.price), album.Price)
+			require.NotNil(t, album.Tax)
+			assert.Equal(t, money.MustParse(tc.tax), *album.Tax)
+			require.NotNil(t, album.PriceIncludesTax)
+			assert.Equal(t, tc.inclusive, *album.PriceIncludesTax)
+		})
+	}
+}
+
+func Test_GetAlbum_WithoutTaxDisplay(t *testing.T) {
+	f := setupTaxRouter(false)
+
+	w := doRequest(f.router, "GET", "/albums/1", "", "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "priceIncludesTax")
+	assert.Equal(t, http.StatusBadRequest, doRequest(f.router, "GET", "/albums/1?taxDisplay=gross", "", "").Code)
+	assert.Equal(t, http.StatusBadRequest, doRequest(f.router, "GET", "/albums/1?country=GBR", "", "").Code)
+}
+
+func Test_PutTaxRates_ReplacesTable(t *testing.T) {
+	f := setupTaxRouter(false)
+
+	w := doRequest(f.router, "PUT", "/admin/tax-rates", "staff-1",
+		`{"rates":[{"country":"us","region":"ny","name":"New York sales tax","rate":8.875},{"country":"FR","category":"Books","name":"TVA","rate":5.5}]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var rates []repository.TaxRate
+	w = doRequest(f.router, "GET", "/tax-rates", "", "")
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rates))
+	require.Len(t, rates, 2)
+	assert.Equal(t, "FR", rates[0].Country)
+	assert.Equal(t, "books", rates[0].Category)
+	assert.Equal(t, "NY", rates[1].Region)
+
+	order := f.checkoutTo(t, "GB", "", repository.CartItem{AlbumID: "1", Format: repository.FormatLP, Quantity: 1})
+	assert.Equal(t, money.Amount(0), order.Tax, "GB was left out of the upload")
+}
+
+func Test_PutTaxRates_Validation(t *testing.T) {
+	f := setupTaxRouter(false)
+
+	for _, body := range []string{
+		`{}`,
+		`{"rates":[{"country":"GBR","name":"VAT","rate":20}]}`,
+		`{"rates":[{"country":"GB","rate":20}]}`,
+		`{"rates":[{"country":"GB","name":"VAT","rate":120}]}`,
+		`{"rates":[{"country":"GB","name":"VAT","rate":-1}]}`,
+		`{"rates":[{"country":"GB","name":"VAT","rate":20},{"country":"gb","name":"VAT","rate":17.5}]}`,
+	} {
+		w := doRequest(f.router, "PUT", "/admin/tax-rates", "staff-1", body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"sort"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// In-memory mock implementation of TaxRateRepository for testing
+
+type mockTaxRateRepo struct {
+	rates []repository.TaxRate
+}
+
+func newMockTaxRateRepo(rates ...repository.TaxRate) *mockTaxRateRepo {
+	m := &mockTaxRateRepo{}
+	_, _ = m.Replace(rates)
+	return m
+}
+
+func (m *mockTaxRateRepo) List() ([]repository.TaxRate, error) {
+	return append([]repository.TaxRate(nil), m.rates...), nil
+}
+
+func (m *mockTaxRateRepo) Replace(rates []repository.TaxRate) ([]repository.TaxRate, error) {
+	m.rates = nil
+	for _, rate := range rates {
+		rate.UpdatedAt = time.Now()
+		m.rates = append(m.rates, rate)
+	}
+	sort.Slice(m.rates, func(i, j int) bool { return m.rates[i].Key() < m.rates[j].Key() })
+	return m.List()
+}
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+type UsedItemHandler struct {
+	Repo   repository.UsedItemRepository
+	Albums repository.AlbumRepository
+}
+
+func NewUsedItemHandler(repo repository.UsedItemRepository, albums repository.AlbumRepository) *UsedItemHandler {
+	return &UsedItemHandler{Repo: repo, Albums: albums}
+}
+
+// UsedItemRequest is the body accepted by POST /albums/:id/used and PUT /used/:id. Format defaults
+// to LP and currency to the album's.
+type UsedItemRequest struct {
+	Format      string        `json:"format"`
+	MediaGrade  string        `json:"mediaGrade" binding:"required"`
+	SleeveGrade string        `json:"sleeveGrade" binding:"required"`
+	Notes       string        `json:"notes"`
+	Photos      []string      `json:"photos"`
+	Price       *money.Amount `json:"price" binding:"required"`
+	Currency    string        `json:"currency"`
+}
+
+func respondWithUsedItemError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrUsedItemNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "used item not found"})
+	case errors.Is(err, repository.ErrUsedItemStatusChanged):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// usedItemFilter reads the ?status=, ?mediaGrade= and ?sleeveGrade= query parameters, the grades
+// being the worst accepted. Only items still for sale are listed unless another status is asked
+// for. It writes an error response and returns false if a parameter is not valid.
+func usedItemFilter(c *gin.Context) (repository.UsedItemFilter, bool) {
+	filter := repository.UsedItemFilter{Status: repository.UsedItemAvailable}
+	var err error
+	if status := c.Query("status"); status != "" {
+		if filter.Status, err = repository.ParseUsedItemStatus(status); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return repository.UsedItemFilter{}, false
+		}
+	}
+	if grade := c.Query("mediaGrade"); grade != "" {
+		if filter.MinMediaGrade, err = repository.ParseGrade(grade); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return repository.UsedItemFilter{}, false
+		}
+	}
+	if grade := c.Query("sleeveGrade"); grade != "" {
+		if filter.MinSleeveGrade, err = repository.ParseGrade(grade); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return repository.UsedItemFilter{}, false
+		}
+	}
+	return filter, true
+}
+
+// bindUsedItem reads and validates a used copy of the album from the request body. It writes an
+// error response and returns false if the body is not a valid item.
+func bindUsedItem(c *gin.Context, album repository.Album, id string) (repository.UsedItem, bool) {
+	var req UsedItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return repository.UsedItem{}, false
+	}
+	item := repository.UsedItem{
+		ID:       id,
+		AlbumID:  album.ID,
+		Format:   repository.FormatLP,
+		Notes:    req.Notes,
+		Photos:   req.Photos,
+		Price:    *req.Price,
+		Currency: req.Currency,
+	}
+	var err error
+	if req.Format != "" {
+		if item.Format, err = repository.ParseFormat(req.Format); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return repository.UsedItem{}, false
+		}
+	}
+	if item.MediaGrade, err = repository.ParseGrade(req.MediaGrade); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mediaGrade: " + err.Error()})
+		return repository.UsedItem{}, false
+	}
+	if item.SleeveGrade, err = repository.ParseGrade(req.SleeveGrade); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sleeveGrade: " + err.Error()})
+		return repository.UsedItem{}, false
+	}
+	if item.Currency == "" {
+		item.Currency = album.Currency
+	}
+	if item.Currency, err = money.ParseCurrency(item.Currency); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return repository.UsedItem{}, false
+	}
+	if item.Photos == nil {
+		item.Photos = repository.Photos{}
+	}
+	if err := item.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return repository.UsedItem{}, false
+	}
+	return item, true
+}
+
+// listUsedItems responds with the items passing the filter read from the query.
+func (h *UsedItemHandler) listUsedItems(c *gin.Context, albumID string) {
+	filter, ok := usedItemFilter(c)
+	if !ok {
+		return
+	}
+	filter.AlbumID = albumID
+	items, err := h.Repo.List(filter)
+	if err != nil {
+		respondWithUsedItemError(c, err)
+		return
+	}
+	if items == nil {
+		items = []repository.UsedItem{}
+	}
+	c.IndentedJSON(http.StatusOK, items)
+}
+
+// GetUsedItems handles GET /used, listing used copies of every album from the best graded down.
+// ?albumId= narrows the list to one album.
+func (h *UsedItemHandler) GetUsedItems(c *gin.Context) {
+	h.listUsedItems(c, c.Query("albumId"))
+}
+
+// GetAlbumUsedItems handles GET /albums/:id/used, listing the used copies of one album.
+func (h *UsedItemHandler) GetAlbumUsedItems(c *gin.Context) {
+	album, err := h.Albums.GetByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "album not found"})
+		return
+	}
+	h.listUsedItems(c, album.ID)
+}
+
+// GetUsedItem handles GET /used/:id.
+func (h *UsedItemHandler) GetUsedItem(c *gin.Context) {
+	item, err := h.Repo.GetByID(c.Param("id"))
+	if err != nil {
+		respondWithUsedItemError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, item)
+}
+
+// PostUsedItem handles POST /albums/:id/used, putting a used copy of the album up for sale.
+func (h *UsedItemHandler) PostUsedItem(c *gin.Context) {
+	album, err := h.Albums.GetByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "album not found"})
+		return
+	}
+	item, ok := bindUsedItem(c, album, "")
+	if !ok {
+		return
+	}
+	created, err := h.Repo.Create(item)
+	if err != nil {
+		respondWithUsedItemError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusCreated, created)
+}
+
+// PutUsedItem handles PUT /used/:id, replacing the item's grades, notes, photos and price. Sold
+// items are kept as they were sold.
+func (h *UsedItemHandler) PutUsedItem(c *gin.Context) {
+	current, err := h.Repo.GetByID(c.Param("id"))
+	if err != nil {
+		respondWithUsedItemError(c, err)
+		return
+	}
+	if current.Status == repository.UsedItemSold {
+		c.JSON(http.StatusConflict, gin.H{"error": "sold items cannot be changed"})
+		return
+	}
+	album, err := h.Albums.GetByID(current.AlbumID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "album not found"})
+		return
+	}
+	item, ok := bindUsedItem(c, album, current.ID)
+	if !ok {
+		return
+	}
+	updated, err := h.Repo.Update(item)
+	if err != nil {
+		respondWithUsedItemError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, updated)
+}
+
+// DeleteUsedItem handles DELETE /used/:id.
+func (h *UsedItemHandler) DeleteUsedItem(c *gin.Context) {
+	if err := h.Repo.Delete(c.Param("id")); err != nil {
+		respondWithUsedItemError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// SellUsedItem handles POST /admin/used/:id/sell, marking the copy sold. Each copy can be sold once;
+// selling it again is a conflict.
+func (h *UsedItemHandler) SellUsedItem(c *gin.Context) {
+	h.setStatus(c, repository.UsedItemAvailable, repository.UsedItemSold)
+}
+
+// RelistUsedItem handles POST /admin/used/:id/relist, putting a sold copy back up for sale, for
+// example when the sale falls through.
+func (h *UsedItemHandler) RelistUsedItem(c *gin.Context) {
+	h.setStatus(c, repository.UsedItemSold, repository.UsedItemAvailable)
+}
+
+func (h *UsedItemHandler) setStatus(c *gin.Context, from, to repository.UsedItemStatus) {
+	updated, err := h.Repo.SetStatus(c.Param("id"), from, to)
+	if err != nil {
+		respondWithUsedItemError(c, err)
+		return
+	}
+	c.IndentedJSON(http.StatusOK, updated)
+}
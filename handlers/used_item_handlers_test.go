@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// setupUsedItemRouter serves the album and used item routes over the mock catalogue, with no used items yet.
+func setupUsedItemRouter(t *testing.T) (*gin.Engine, *mockUsedItemRepo) {
+	t.Helper()
+	items := newMockUsedItemRepo()
+	albums := newTestHandler()
+	albums.UsedItems = items
+	handler := NewUsedItemHandler(items, albums.Repo)
+	r := gin.Default()
+	r.GET("/albums", albums.GetAlbums)
+	r.GET("/albums/:id", albums.GetAlbumByID)
+	r.GET("/albums/:id/used", handler.GetAlbumUsedItems)
+	r.POST("/albums/:id/used", handler.PostUsedItem)
+	r.GET("/used", handler.GetUsedItems)
+	r.GET("/used/:id", handler.GetUsedItem)
+	r.PUT("/used/:id", handler.PutUsedItem)
+	r.DELETE("/used/:id", handler.DeleteUsedItem)
+	r.POST("/admin/used/:id/sell", handler.SellUsedItem)
+	r.POST("/admin/used/:id/relist", handler.RelistUsedItem)
+	return r, items
+}
+
+// addUsedItems puts a VG+/VG and an NM/G+ copy of Songs in the Key of Life and a G/F copy of Thriller up for sale.
+func addUsedItems(t *testing.T, r *gin.Engine) {
+	t.Helper()
+	for _, add := range []struct{ album, body string }{
+		{"2", `{"mediaGrade":"vg+","sleeveGrade":"VG","notes":"Light surface marks","photos":["https://example.com/sleeve.jpg"],"price":18}`},
+		{"2", `{"mediaGrade":"NM","sleeveGrade":"G+","price":25}`},
+		{"1", `{"format":"cassette","mediaGrade":"G","sleeveGrade":"F","price":2.5}`},
+	} {
+		w := doRequest(r, "POST", "/albums/"+add.album+"/used", "staff-1", add.body)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	}
+}
+
+func Test_PostUsedItem(t *testing.T) {
+	r, _ := setupUsedItemRouter(t)
+
+	w := doRequest(r, "POST", "/albums/2/used", "staff-1", `{"mediaGrade":"vg+","sleeveGrade":"vg","notes":"Original inner sleeve","price":18}`)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	item := decodeJSON[repository.UsedItem](t, w.Body.Bytes())
+	assert.Equal(t, "2", item.AlbumID)
+	assert.Equal(t, repository.FormatLP, item.Format, "used items are LPs unless said otherwise")
+	assert.Equal(t, repository.Grade("VG+"), item.MediaGrade)
+	assert.Equal(t, repository.Grade("VG"), item.SleeveGrade)
+	assert.Equal(t, "GBP", item.Currency, "the currency defaults to the album's")
+	assert.Equal(t, repository.UsedItemAvailable, item.Status)
+	assert.Equal(t, repository.Photos{}, item.Photos)
+
+	for _, body := range []string{
+		`{"sleeveGrade":"VG","price":18}`,
+		`{"mediaGrade":"Excellent","sleeveGrade":"VG","price":18}`,
+		`{"mediaGrade":"VG","sleeveGrade":"VG"}`,
+		`{"mediaGrade":"VG","sleeveGrade":"VG","price":-1}`,
+		`{"mediaGrade":"VG","sleeveGrade":"VG","price":18,"format":"8-track"}`,
+		`{"mediaGrade":"VG","sleeveGrade":"VG","price":18,"photos":["file:///etc/passwd"]}`,
+	} {
+		w = doRequest(r, "POST", "/albums/2/used", "staff-1", body)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+
+	w = doRequest(r, "POST", "/albums/999/used", "staff-1", `{"mediaGrade":"VG","sleeveGrade":"VG","price":18}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_GetUsedItems_FilterByGrade(t *testing.T) {
+	r, _ := setupUsedItemRouter(t)
+	addUsedItems(t, r)
+
+	w := doRequest(r, "GET", "/used", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	items := decodeJSON[[]repository.UsedItem](t, w.Body.Bytes())
+	require.Len(t, items, 3)
+	assert.Equal(t, repository.Grade("NM"), items[0].MediaGrade, "the best graded copies come first")
+
+	w = doRequest(r, "GET", "/used?mediaGrade=vg%2B", "", "")
+	assert.Len(t, decodeJSON[[]repository.UsedItem](t, w.Body.Bytes()), 2)
+
+	w = doRequest(r, "GET", "/used?mediaGrade=VG&sleeveGrade=VG", "", "")
+	items = decodeJSON[[]repository.UsedItem](t, w.Body.Bytes())
+	require.Len(t, items, 1)
+	assert.Equal(t, money.MustParse("18"), items[0].Price)
+
+	w = doRequest(r, "GET", "/albums/1/used", "", "")
+	items = decodeJSON[[]repository.UsedItem](t, w.Body.Bytes())
+	require.Len(t, items, 1)
+	assert.Equal(t, repository.FormatCassette, items[0].Format)
+
+	w = doRequest(r, "GET", "/used?mediaGrade=mint-ish", "", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doRequest(r, "GET", "/albums/999/used", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_SellUsedItem_OnlyOnce(t *testing.T) {
+	r, _ := setupUsedItemRouter(t)
+	addUsedItems(t, r)
+
+	w := doRequest(r, "POST", "/admin/used/used-1/sell", "staff-1", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, repository.UsedItemSold, decodeJSON[repository.UsedItem](t, w.Body.Bytes()).Status)
+
+	w = doRequest(r, "POST", "/admin/used/used-1/sell", "staff-1", "")
+	assert.Equal(t, http.StatusConflict, w.Code, "each copy can be sold once")
+
+	w = doRequest(r, "GET", "/albums/2/used", "", "")
+	assert.Len(t, decodeJSON[[]repository.UsedItem](t, w.Body.Bytes()), 1, "sold copies are no longer listed")
+	w = doRequest(r, "GET", "/used?status=sold", "", "")
+	assert.Len(t, decodeJSON[[]repository.UsedItem](t, w.Body.Bytes()), 1)
+
+	w = doRequest(r, "PUT", "/used/used-1", "staff-1", `{"mediaGrade":"VG","sleeveGrade":"VG","price":10}`)
+	assert.Equal(t, http.StatusConflict, w.Code, "sold items are kept as they were sold")
+
+	w = doRequest(r, "POST", "/admin/used/used-1/relist", "staff-1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, repository.UsedItemAvailable, decodeJSON[repository.UsedItem](t, w.Body.Bytes()).Status)
+
+	w = doRequest(r, "POST", "/admin/used/missing/sell", "staff-1", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_PutAndDeleteUsedItem(t *testing.T) {
+	r, _ := setupUsedItemRouter(t)
+	addUsedItems(t, r)
+
+	w := doRequest(r, "PUT", "/used/used-2", "staff-1", `{"mediaGrade":"VG+","sleeveGrade":"G+","notes":"Regraded","price":20}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	item := decodeJSON[repository.UsedItem](t, w.Body.Bytes())
+	assert.Equal(t, "2", item.AlbumID)
+	assert.Equal(t, repository.Grade("VG+"), item.MediaGrade)
+	assert.Equal(t, money.MustParse("20"), item.Price)
+
+	w = doRequest(r, "DELETE", "/used/used-2", "staff-1", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	w = doRequest(r, "GET", "/used/used-2", "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_GetAlbums_UsedCountsAndGradeFilter(t *testing.T) {
+	r, _ := setupUsedItemRouter(t)
+	addUsedItems(t, r)
+
+	w := doRequest(r, "GET", "/albums/2", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	album := decodeJSON[repository.Album](t, w.Body.Bytes())
+	require.NotNil(t, album.UsedCount)
+	assert.Equal(t, 2, *album.UsedCount)
+
+	w = doRequest(r, "GET", "/albums?mediaGrade=VG", "", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var albums []repository.Album
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &albums))
+	require.Len(t, albums, 1)
+	assert.Equal(t, "Songs in the Key of Life", albums[0].Title)
+
+	w = doRequest(r, "GET", "/albums?sleeveGrade=M", "", "")
+	assert.JSONEq(t, `[]`, w.Body.String())
+}
+
+func Test_GetAlbums_FilterByGradeNeedsUsedItems(t *testing.T) {
+	r := setupRouter(newTestHandler())
+
+	w := doRequest(r, "GET", "/albums?mediaGrade=VG", "", "")
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
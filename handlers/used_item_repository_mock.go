@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// In-memory mock implementation of UsedItemRepository for testing
+
+type mockUsedItemRepo struct {
+	items  []repository.UsedItem
+	nextID int
+
+	// failCreate, if set, is called before each Create and any error it returns is returned instead.
+	failCreate func() error
+}
+
+func newMockUsedItemRepo() *mockUsedItemRepo {
+	return &mockUsedItemRepo{}
+}
+
+func (m *mockUsedItemRepo) Create(item repository.UsedItem) (repository.UsedItem, error) {
+	if m.failCreate != nil {
+		if err := m.failCreate(); err != nil {
+			return repository.UsedItem{}, err
+		}
+	}
+	m.nextID++
+	item.ID = fmt.Sprintf("used-%d", m.nextID)
+	item.Status = repository.UsedItemAvailable
+	item.CreatedAt = time.Now()
+	item.UpdatedAt = item.CreatedAt
+	m.items = append(m.items, item)
+	return item, nil
+}
+
+func (m *mockUsedItemRepo) GetByID(id string) (repository.UsedItem, error) {
+	for _, item := range m.items {
+		if item.ID == id {
+			return item, nil
+		}
+	}
+	return repository.UsedItem{}, repository.ErrUsedItemNotFound
+}
+
+func (m *mockUsedItemRepo) List(filter repository.UsedItemFilter) ([]repository.UsedItem, error) {
+	var items []repository.UsedItem
+	for _, item := range m.items {
+		if filter.Matches(item) {
+			items = append(items, item)
+		}
+	}
+	rank := func(grade repository.Grade) int {
+		for i, g := range repository.Grades {
+			if g == grade {
+				return i
+			}
+		}
+		return len(repository.Grades)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].MediaGrade != items[j].MediaGrade {
+			return rank(items[i].MediaGrade) < rank(items[j].MediaGrade)
+		}
+		if items[i].SleeveGrade != items[j].SleeveGrade {
+			return rank(items[i].SleeveGrade) < rank(items[j].SleeveGrade)
+		}
+		return items[i].Price < items[j].Price
+	})
+	return items, nil
+}
+
+func (m *mockUsedItemRepo) Update(item repository.UsedItem) (repository.UsedItem, error) {
+	for i, existing := range m.items {
+		if existing.ID != item.ID {
+			continue
+		}
+		item.AlbumID = existing.AlbumID
+		item.Status = existing.Status
+		item.CreatedAt = existing.CreatedAt
+		item.UpdatedAt = time.Now()
+		m.items[i] = item
+		return item, nil
+	}
+	return repository.UsedItem{}, repository.ErrUsedItemNotFound
+}
+
+func (m *mockUsedItemRepo) SetStatus(id string, from, to repository.UsedItemStatus) (repository.UsedItem, error) {
+	for i, item := range m.items {
+		if item.ID != id {
+			continue
+		}
+		if item.Status != from {
+			return repository.UsedItem{}, repository.ErrUsedItemStatusChanged
+		}
+		m.items[i].Status = to
+		m.items[i].UpdatedAt = time.Now()
+		return m.items[i], nil
+	}
+	return repository.UsedItem{}, repository.ErrUsedItemNotFound
+}
+
+func (m *mockUsedItemRepo) Delete(id string) error {
+	for i, item := range m.items {
+		if item.ID == id {
+			m.items = append(m.items[:i], m.items[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrUsedItemNotFound
+}
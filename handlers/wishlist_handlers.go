@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tvergilio/motown-house-backend/promotions"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+type WishlistHandler struct {
+	Repo      repository.WishlistRepository
+	Albums    repository.AlbumRepository
+	Inventory repository.InventoryRepository
+
+	// Promotions is optional; when set, wishlist items show the sale price of albums on promotion.
+	Promotions repository.PromotionRepository
+
+	// Genres is optional; when set, promotions for a genre also apply to its sub-genres.
+	Genres repository.GenreRepository
+}
+
+func NewWishlistHandler(repo repository.WishlistRepository, albums repository.AlbumRepository, inventory repository.InventoryRepository) *WishlistHandler {
+	return &WishlistHandler{
+		Repo:      repo,
+		Albums:    albums,
+		Inventory: inventory,
+	}
+}
+
+// AddWishlistItemRequest is the body accepted by POST /wishlist/items.
+type AddWishlistItemRequest struct {
+	AlbumID string `json:"albumId" binding:"required"`
+	Note    string `json:"note"`
+}
+
+// newShareToken returns an unguessable token for a wishlist's share link.
+func newShareToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// annotate fills in each item's title, price and stock from the album's current details.
+func (h *WishlistHandler) annotate(wishlist *repository.Wishlist) error {
+	if wishlist.Items == nil {
+		wishlist.Items = []repository.WishlistItem{}
+	}
+	active, err := loadPromotions(h.Promotions)
+	if err != nil {
+		return err
+	}
+	genres, err := loadGenres(h.Genres)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for i := range wishlist.Items {
+		item := &wishlist.Items[i]
+		album, err := h.Albums.GetByID(item.AlbumID)
+		if err != nil {
+			// The album has been removed from the catalogue; it stays listed but cannot be bought.
+			continue
+		}
+		item.Title = album.Title
+		item.Artist = album.Artist
+		item.ImageUrl = album.ImageUrl
+		item.Price = album.Price
+		item.Currency = album.Currency
+		if price, promotion := promotions.SalePrice(active, genres, album, now); promotion != nil {
+			item.SalePrice = &price
+			item.Promotion = promotion.Name
+		}
+		levels, err := h.Inventory.GetStock(item.AlbumID)
+		if err != nil {
+			return err
+		}
+		item.Stock = completeStock(item.AlbumID, levels)
+		for _, level := range item.Stock {
+			if level.Available > 0 {
+				item.InStock = true
+			}
+		}
+	}
+	return nil
+}
+
+// respondWithWishlist annotates the wishlist and writes it.
+func (h *WishlistHandler) respondWithWishlist(c *gin.Context, wishlist repository.Wishlist) {
+	if err := h.annotate(&wishlist); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(http.StatusOK, wishlist)
+}
+
+// getWishlist loads the caller's wishlist, writing an error response and returning false on failure.
+func (h *WishlistHandler) getWishlist(c *gin.Context, userID string) (repository.Wishlist, bool) {
+	wishlist, err := h.Repo.Get(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return repository.Wishlist{}, false
+	}
+	return wishlist, true
+}
+
+// GetWishlist handles GET /wishlist, showing the signed-in user's saved albums with their current
+// price and stock.
+func (h *WishlistHandler) GetWishlist(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	if wishlist, ok := h.getWishlist(c, userID); ok {
+		h.respondWithWishlist(c, wishlist)
+	}
+}
+
+// PostWishlistItem handles POST /wishlist/items. Saving an album that is already in the wishlist
+// replaces its note.
+func (h *WishlistHandler) PostWishlistItem(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	var req AddWishlistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := h.Albums.GetByID(req.AlbumID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "album not found"})
+		return
+	}
+	item := repository.WishlistItem{AlbumID: req.AlbumID, Note: strings.TrimSpace(req.Note)}
+	if err := h.Repo.AddItem(userID, item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if wishlist, ok := h.getWishlist(c, userID); ok {
+		h.respondWithWishlist(c, wishlist)
+	}
+}
+
+// DeleteWishlistItem handles DELETE /wishlist/items/:albumId.
+func (h *WishlistHandler) DeleteWishlistItem(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	err := h.Repo.RemoveItem(userID, c.Param("albumId"))
+	if errors.Is(err, repository.ErrWishlistItemNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "album not in wishlist"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if wishlist, ok := h.getWishlist(c, userID); ok {
+		h.respondWithWishlist(c, wishlist)
+	}
+}
+
+// ShareWishlist handles POST /wishlist/share, returning a token anyone can use to view the wishlist
+// at GET /wishlists/shared/:token. Sharing a wishlist that is already shared keeps the same token.
+func (h *WishlistHandler) ShareWishlist(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	wishlist, ok := h.getWishlist(c, userID)
+	if !ok {
+		return
+	}
+	if wishlist.ShareToken == "" {
+		token, err := newShareToken()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if err := h.Repo.SetShareToken(userID, token); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		wishlist.ShareToken = token
+	}
+	h.respondWithWishlist(c, wishlist)
+}
+
+// UnshareWishlist handles DELETE /wishlist/share. The old link stops working; sharing again gives
+// a new one.
+func (h *WishlistHandler) UnshareWishlist(c *gin.Context) {
+	userID, ok := requireUserID(c)
+	if !ok {
+		return
+	}
+	if err := h.Repo.SetShareToken(userID, ""); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if wishlist, ok := h.getWishlist(c, userID); ok {
+		h.respondWithWishlist(c, wishlist)
+	}
+}
+
+// GetSharedWishlist handles GET /wishlists/shared/:token. It needs no X-User-ID, and does not
+// reveal whose wishlist it is.
+func (h *WishlistHandler) GetSharedWishlist(c *gin.Context) {
+	wishlist, err := h.Repo.GetByShareToken(c.Param("token"))
+	if errors.Is(err, repository.ErrWishlistNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "wishlist not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	wishlist.UserID = ""
+	h.respondWithWishlist(c, wishlist)
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+func setupWishlistRouter(wishlists *mockWishlistRepo) *gin.Engine {
+	handler := NewWishlistHandler(wishlists, newTestHandler().Repo, newCartTestInventory())
+	handler.Promotions = newMockPromotionRepo(motownWeekend)
+	r := gin.Default()
+	r.GET("/wishlist", handler.GetWishlist)
+	r.POST("/wishlist/items", handler.PostWishlistItem)
+	r.DELETE("/wishlist/items/:albumId", handler.DeleteWishlistItem)
+	r.POST("/wishlist/share", handler.ShareWishlist)
+	r.DELETE("/wishlist/share", handler.UnshareWishlist)
+	r.GET("/wishlists/shared/:token", handler.GetSharedWishlist)
+	return r
+}
+
+func Test_GetWishlist_EmptyForNewUser(t *testing.T) {
+	r := setupWishlistRouter(newMockWishlistRepo())
+
+	w := doRequest(r, "GET", "/wishlist", "user-1", "")
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"items": []`)
+}
+
+func Test_GetWishlist_RequiresUser(t *testing.T) {
+	r := setupWishlistRouter(newMockWishlistRepo())
+
+	w := doRequest(r, "GET", "/wishlist", "", "")
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func Test_PostWishlistItem_AnnotatesPriceAndStock(t *testing.T) {
+	r := setupWishlistRouter(newMockWishlistRepo())
+
+	doRequest(r, "POST", "/wishlist/items", "user-1", `{"albumId":"101"}`)
+	w := doRequest(r, "POST", "/wishlist/items", "user-1", `{"albumId":"2","note":"for my birthday"}`)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	wishlist := decodeJSON[repository.Wishlist](t, w.Body.Bytes())
+	require.Len(t, wishlist.Items, 2)
+	songs := wishlist.Items[0]
+	assert.Equal(t, "Songs in the Key of Life", songs.Title)
+	assert.Equal(t, "for my birthday", songs.Note)
+	assert.Equal(t, money.MustParse("42.50"), songs.Price)
+	require.NotNil(t, songs.SalePrice)
+	assert.Equal(t, money.MustParse("34.00"), *songs.SalePrice)
+	assert.True(t, songs.InStock)
+	assert.False(t, wishlist.Items[1].InStock, "album 101 has no stock")
+}
+
+func Test_PostWishlistItem_SavingTwiceUpdatesNote(t *testing.T) {
+	r := setupWishlistRouter(newMockWishlistRepo())
+
+	doRequest(r, "POST", "/wishlist/items", "user-1", `{"albumId":"1","note":"LP"}`)
+	w := doRequest(r, "POST", "/wishlist/items", "user-1", `{"albumId":"1","note":"LP or CD"}`)
+
+	wishlist := decodeJSON[repository.Wishlist](t, w.Body.Bytes())
+	require.Len(t, wishlist.Items, 1)
+	assert.Equal(t, "LP or CD", wishlist.Items[0].Note)
+}
+
+func Test_PostWishlistItem_UnknownAlbum(t *testing.T) {
+	r := setupWishlistRouter(newMockWishlistRepo())
+
+	w := doRequest(r, "POST", "/wishlist/items", "user-1", `{"albumId":"999"}`)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_DeleteWishlistItem(t *testing.T) {
+	r := setupWishlistRouter(newMockWishlistRepo())
+	doRequest(r, "POST", "/wishlist/items", "user-1", `{"albumId":"1"}`)
+
+	w := doRequest(r, "DELETE", "/wishlist/items/1", "user-1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, decodeJSON[repository.Wishlist](t, w.Body.Bytes()).Items)
+
+	w = doRequest(r, "DELETE", "/wishlist/items/1", "user-1", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_ShareWishlist_PublicLink(t *testing.T) {
+	r := setupWishlistRouter(newMockWishlistRepo())
+	doRequest(r, "POST", "/wishlist/items", "user-1", `{"albumId":"1"}`)
+
+	w := doRequest(r, "POST", "/wishlist/share", "user-1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	token := decodeJSON[repository.Wishlist](t, w.Body.Bytes()).ShareToken
+	require.NotEmpty(t, token)
+	again := decodeJSON[repository.Wishlist](t, doRequest(r, "POST", "/wishlist/share", "user-1", "").Body.Bytes())
+	assert.Equal(t, token, again.ShareToken, "sharing again keeps the link")
+
+	w = doRequest(r, "GET", "/wishlists/shared/"+token, "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	shared := decodeJSON[repository.Wishlist](t, w.Body.Bytes())
+	assert.Empty(t, shared.UserID)
+	require.Len(t, shared.Items, 1)
+	assert.Equal(t, "Thriller", shared.Items[0].Title)
+
+	doRequest(r, "DELETE", "/wishlist/share", "user-1", "")
+	w = doRequest(r, "GET", "/wishlists/shared/"+token, "", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// In-memory mock implementation of WishlistRepository for testing
+
+type mockWishlistRepo struct {
+	wishlists map[string]repository.Wishlist
+}
+
+func newMockWishlistRepo() *mockWishlistRepo {
+	return &mockWishlistRepo{wishlists: make(map[string]repository.Wishlist)}
+}
+
+func (m *mockWishlistRepo) Get(userID string) (repository.Wishlist, error) {
+	wishlist, ok := m.wishlists[userID]
+	if !ok {
+		return repository.Wishlist{UserID: userID}, nil
+	}
+	wishlist.Items = append([]repository.WishlistItem(nil), wishlist.Items...)
+	return wishlist, nil
+}
+
+func (m *mockWishlistRepo) GetByShareToken(token string) (repository.Wishlist, error) {
+	for userID, wishlist := range m.wishlists {
+		if token != "" && wishlist.ShareToken == token {
+			return m.Get(userID)
+		}
+	}
+	return repository.Wishlist{}, repository.ErrWishlistNotFound
+}
+
+func (m *mockWishlistRepo) AddItem(userID string, item repository.WishlistItem) error {
+	wishlist, _ := m.Get(userID)
+	wishlist.UpdatedAt = time.Now()
+	for i, existing := range wishlist.Items {
+		if existing.AlbumID == item.AlbumID {
+			wishlist.Items[i].Note = item.Note
+			m.wishlists[userID] = wishlist
+			return nil
+		}
+	}
+	item.AddedAt = wishlist.UpdatedAt
+	wishlist.Items = append([]repository.WishlistItem{item}, wishlist.Items...)
+	m.wishlists[userID] = wishlist
+	return nil
+}
+
+func (m *mockWishlistRepo) RemoveItem(userID, albumID string) error {
+	wishlist, _ := m.Get(userID)
+	for i, existing := range wishlist.Items {
+		if existing.AlbumID == albumID {
+			wishlist.Items = append(wishlist.Items[:i], wishlist.Items[i+1:]...)
+			wishlist.UpdatedAt = time.Now()
+			m.wishlists[userID] = wishlist
+			return nil
+		}
+	}
+	return repository.ErrWishlistItemNotFound
+}
+
+func (m *mockWishlistRepo) SetShareToken(userID, token string) error {
+	wishlist, _ := m.Get(userID)
+	wishlist.ShareToken = token
+	wishlist.UpdatedAt = time.Now()
+	m.wishlists[userID] = wishlist
+	return nil
+}
+
+func (m *mockWishlistRepo) AlbumsByUser() (map[string][]string, error) {
+	albums := make(map[string][]string)
+	for userID, wishlist := range m.wishlists {
+		for _, item := range wishlist.Items {
+			albums[userID] = append(albums[userID], item.AlbumID)
+		}
+	}
+	return albums, nil
+}
@@ -0,0 +1,23 @@
+package jobs
+
+import (
+	"context"
+	"log"
+
+	"github.com/tvergilio/motown-house-backend/artwork"
+)
+
+// PruneArtworkCache returns a job that deletes proxied artwork the proxy has cached for longer than
+// it serves it from the cache, so the cache only holds artwork still being asked for.
+func PruneArtworkCache(proxy *artwork.Proxy) func(context.Context) error {
+	return func(ctx context.Context) error {
+		pruned, err := proxy.Prune()
+		if err != nil {
+			return err
+		}
+		if pruned > 0 {
+			log.Printf("pruneArtworkCache: deleted %d cached images", pruned)
+		}
+		return nil
+	}
+}
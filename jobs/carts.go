@@ -0,0 +1,23 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// CleanupIdleCarts returns a job that deletes carts with no activity within ttl.
+func CleanupIdleCarts(carts repository.CartRepository, ttl time.Duration) func(context.Context) error {
+	return func(ctx context.Context) error {
+		deleted, err := carts.DeleteIdleSince(time.Now().Add(-ttl))
+		if err != nil {
+			return err
+		}
+		if deleted > 0 {
+			log.Printf("cleanupIdleCarts: deleted %d idle carts", deleted)
+		}
+		return nil
+	}
+}
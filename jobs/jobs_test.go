@@ -0,0 +1,205 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/artwork"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+func TestEvery_RunsUntilCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var runs atomic.Int32
+	done := make(chan struct{})
+
+	go func() {
+		Every(ctx, "test", time.Millisecond, func(context.Context) error {
+			if runs.Add(1) == 3 {
+				cancel()
+			}
+			return errors.New("failures do not stop the schedule")
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Every did not return after the context was cancelled")
+	}
+	assert.GreaterOrEqual(t, runs.Load(), int32(3))
+}
+
+type idleCartRepo struct {
+	repository.CartRepository
+	cutoff time.Time
+}
+
+func (r *idleCartRepo) DeleteIdleSince(cutoff time.Time) (int64, error) {
+	r.cutoff = cutoff
+	return 2, nil
+}
+
+func TestCleanupIdleCarts_UsesTTLCutoff(t *testing.T) {
+	repo := &idleCartRepo{}
+
+	err := CleanupIdleCarts(repo, time.Hour)(context.Background())
+
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), repo.cutoff, time.Minute)
+}
+
+type priceTestAlbums struct {
+	repository.AlbumRepository
+	albums map[string]repository.Album
+}
+
+func (r *priceTestAlbums) GetByID(id string) (repository.Album, error) {
+	album, ok := r.albums[id]
+	if !ok {
+		return repository.Album{}, errors.New("album not found")
+	}
+	return album, nil
+}
+
+func (r *priceTestAlbums) Update(album repository.Album) error {
+	r.albums[album.ID] = album
+	return nil
+}
+
+type priceTestRepo struct {
+	repository.PriceRepository
+	scheduled []repository.ScheduledPrice
+	changes   []repository.PriceChange
+}
+
+func (r *priceTestRepo) Due(now time.Time) ([]repository.ScheduledPrice, error) {
+	var due []repository.ScheduledPrice
+	for _, s := range r.scheduled {
+		if s.Status == repository.SchedulePending && !s.EffectiveAt.After(now) {
+			due = append(due, s)
+		}
+	}
+	return due, nil
+}
+
+func (r *priceTestRepo) SetScheduleStatus(id string, from, to repository.ScheduleStatus) error {
+	for i := range r.scheduled {
+		if r.scheduled[i].ID == id {
+			if r.scheduled[i].Status != from {
+				return repository.ErrScheduleStatusChanged
+			}
+			r.scheduled[i].Status = to
+			return nil
+		}
+	}
+	return repository.ErrScheduledPriceNotFound
+}
+
+func (r *priceTestRepo) RecordChange(change repository.PriceChange) (repository.PriceChange, error) {
+	r.changes = append(r.changes, change)
+	return change, nil
+}
+
+func TestApplyScheduledPrices_AppliesDueChanges(t *testing.T) {
+	albums := &priceTestAlbums{albums: map[string]repository.Album{"1": {ID: "1", Price: money.MustParse("40.00")}, "2": {ID: "2", Price: money.MustParse("20.00")}}}
+	prices := &priceTestRepo{scheduled: []repository.ScheduledPrice{
+		{ID: "due", AlbumID: "1", Price: money.MustParse("32.00"), EffectiveAt: time.Now().Add(-time.Minute), Status: repository.SchedulePending, Actor: "staff-1", Note: "sale"},
+		{ID: "later", AlbumID: "2", Price: money.MustParse("15.00"), EffectiveAt: time.Now().Add(time.Hour), Status: repository.SchedulePending},
+		{ID: "missing", AlbumID: "9", Price: money.MustParse("15.00"), EffectiveAt: time.Now().Add(-time.Minute), Status: repository.SchedulePending},
+	}}
+
+	err := ApplyScheduledPrices(albums, prices)(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, money.MustParse("32.00"), albums.albums["1"].Price)
+	assert.Equal(t, money.MustParse("20.00"), albums.albums["2"].Price)
+	assert.Equal(t, repository.ScheduleApplied, prices.scheduled[0].Status)
+	assert.Equal(t, repository.SchedulePending, prices.scheduled[1].Status)
+	assert.Equal(t, repository.ScheduleFailed, prices.scheduled[2].Status)
+	assert.Equal(t, []repository.PriceChange{{
+		AlbumID: "1", OldPrice: money.MustParse("40.00"), NewPrice: money.MustParse("32.00"), Source: repository.PriceSourceScheduled, Actor: "staff-1", Note: "sale",
+	}}, prices.changes)
+
+	err = ApplyScheduledPrices(albums, prices)(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, prices.changes, 1, "applied changes are not applied again")
+}
+
+type trashTestAlbums struct {
+	repository.AlbumRepository
+	trash   []repository.Album
+	deleted []string
+}
+
+func (r *trashTestAlbums) GetTrash() ([]repository.Album, error) {
+	return r.trash, nil
+}
+
+func (r *trashTestAlbums) Delete(id string) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+type trashTestReleases struct {
+	repository.ReleaseRepository
+	releases map[string][]repository.Release
+}
+
+func (r *trashTestReleases) ListByAlbum(albumID string) ([]repository.Release, error) {
+	return r.releases[albumID], nil
+}
+
+func (r *trashTestReleases) Delete(albumID, id string) error {
+	var kept []repository.Release
+	for _, release := range r.releases[albumID] {
+		if release.ID != id {
+			kept = append(kept, release)
+		}
+	}
+	r.releases[albumID] = kept
+	return nil
+}
+
+func TestPurgeTrash_DeletesAlbumsPastRetention(t *testing.T) {
+	old, recent := time.Now().Add(-48*time.Hour), time.Now().Add(-time.Hour)
+	albums := &trashTestAlbums{trash: []repository.Album{{ID: "recent", DeletedAt: &recent}, {ID: "old", DeletedAt: &old}}}
+	releases := &trashTestReleases{releases: map[string][]repository.Release{
+		"old":    {{ID: "r1", AlbumID: "old"}, {ID: "r2", AlbumID: "old"}},
+		"recent": {{ID: "r3", AlbumID: "recent"}},
+	}}
+
+	err := PurgeTrash(albums, releases, 24*time.Hour)(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"old"}, albums.deleted)
+	assert.Empty(t, releases.releases["old"])
+	assert.Len(t, releases.releases["recent"], 1, "albums still within the retention period can be restored")
+}
+
+func TestPruneArtworkCache_DeletesExpiredArtwork(t *testing.T) {
+	dir := t.TempDir()
+	proxy := artwork.NewProxy(dir, []string{"mzstatic.com"}, time.Hour, 1<<20)
+	old, recent := filepath.Join(dir, "ab", "old"), filepath.Join(dir, "cd", "recent")
+	for _, path := range []string{old, recent} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte("image"), 0o644))
+	}
+	twoHoursAgo := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(old, twoHoursAgo, twoHoursAgo))
+
+	err := PruneArtworkCache(proxy)(context.Background())
+
+	assert.NoError(t, err)
+	assert.NoFileExists(t, old)
+	assert.FileExists(t, recent)
+}
@@ -0,0 +1,64 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// ApplyScheduledPrices returns a job that applies every scheduled price change whose effective time
+// has passed, recording each in the album's price history.
+func ApplyScheduledPrices(albums repository.AlbumRepository, prices repository.PriceRepository) func(context.Context) error {
+	return func(ctx context.Context) error {
+		due, err := prices.Due(time.Now())
+		if err != nil {
+			return err
+		}
+		for _, scheduled := range due {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := applyScheduledPrice(albums, prices, scheduled); err != nil {
+				log.Printf("applyScheduledPrices: change %s for album %s failed: %v", scheduled.ID, scheduled.AlbumID, err)
+				continue
+			}
+			log.Printf("applyScheduledPrices: album %s now costs %s", scheduled.AlbumID, scheduled.Price)
+		}
+		return nil
+	}
+}
+
+func applyScheduledPrice(albums repository.AlbumRepository, prices repository.PriceRepository, scheduled repository.ScheduledPrice) error {
+	// Claim the change first, so that when several instances run this job only one applies it.
+	err := prices.SetScheduleStatus(scheduled.ID, repository.SchedulePending, repository.ScheduleApplied)
+	if errors.Is(err, repository.ErrScheduleStatusChanged) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	album, err := albums.GetByID(scheduled.AlbumID)
+	if err == nil {
+		oldPrice := album.Price
+		album.Price = scheduled.Price
+		if err = albums.Update(album); err == nil {
+			_, err = prices.RecordChange(repository.PriceChange{
+				AlbumID:  scheduled.AlbumID,
+				OldPrice: oldPrice,
+				NewPrice: scheduled.Price,
+				Source:   repository.PriceSourceScheduled,
+				Actor:    scheduled.Actor,
+				Note:     scheduled.Note,
+			})
+			return err
+		}
+	}
+	if statusErr := prices.SetScheduleStatus(scheduled.ID, repository.ScheduleApplied, repository.ScheduleFailed); statusErr != nil {
+		log.Printf("applyScheduledPrices: could not mark change %s as failed: %v", scheduled.ID, statusErr)
+	}
+	return err
+}
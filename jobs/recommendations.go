@@ -0,0 +1,15 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/tvergilio/motown-house-backend/recommend"
+)
+
+// RefreshRecommendations returns a job that recomputes the recommendations for every album in the
+// catalogue. Until it first succeeds, albums have no recommendations.
+func RefreshRecommendations(recommender *recommend.Recommender) func(context.Context) error {
+	return func(ctx context.Context) error {
+		return recommender.Refresh()
+	}
+}
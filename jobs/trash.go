@@ -0,0 +1,61 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// PurgeTrash returns a job that permanently deletes albums that have been in the trash for longer
+// than retention. Releases are deleted with them when a release repository is given; Postgres also
+// removes them, with the album's stock, prices and reviews, through its foreign keys.
+func PurgeTrash(albums repository.AlbumRepository, releases repository.ReleaseRepository, retention time.Duration) func(context.Context) error {
+	return func(ctx context.Context) error {
+		trash, err := albums.GetTrash()
+		if err != nil {
+			return err
+		}
+		cutoff := time.Now().Add(-retention)
+		purged := 0
+		for _, album := range trash {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if album.DeletedAt == nil || album.DeletedAt.After(cutoff) {
+				continue
+			}
+			if err := albums.Delete(album.ID); err != nil {
+				log.Printf("purgeTrash: album %s could not be deleted: %v", album.ID, err)
+				continue
+			}
+			if err := deleteReleases(releases, album.ID); err != nil {
+				log.Printf("purgeTrash: releases of album %s could not be deleted: %v", album.ID, err)
+			}
+			purged++
+		}
+		if purged > 0 {
+			log.Printf("purgeTrash: deleted %d albums from the trash", purged)
+		}
+		return nil
+	}
+}
+
+// deleteReleases deletes the releases of a purged album, if releases are configured.
+func deleteReleases(releases repository.ReleaseRepository, albumID string) error {
+	if releases == nil {
+		return nil
+	}
+	list, err := releases.ListByAlbum(albumID)
+	if err != nil {
+		return err
+	}
+	for _, release := range list {
+		if err := releases.Delete(albumID, release.ID); err != nil && !errors.Is(err, repository.ErrReleaseNotFound) {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,15 +1,31 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 
+	"github.com/tvergilio/motown-house-backend/alerts"
+	"github.com/tvergilio/motown-house-backend/artwork"
+	"github.com/tvergilio/motown-house-backend/config"
 	"github.com/tvergilio/motown-house-backend/db"
 	"github.com/tvergilio/motown-house-backend/handlers"
+	"github.com/tvergilio/motown-house-backend/jobs"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/notify"
+	"github.com/tvergilio/motown-house-backend/payments"
+	"github.com/tvergilio/motown-house-backend/recommend"
 	"github.com/tvergilio/motown-house-backend/repository"
+	"github.com/tvergilio/motown-house-backend/storage"
+	"github.com/tvergilio/motown-house-backend/tax"
 )
 
 func seedAlbums(repo repository.AlbumRepository) {
@@ -23,10 +39,10 @@ func seedAlbums(repo repository.AlbumRepository) {
 		return
 	}
 	initialAlbums := []repository.Album{
-		{Title: "What's Going On", Artist: "Marvin Gaye", Price: 39.99, Year: 1971, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music112/v4/76/36/2d/76362d74-cb7a-8ef9-104e-cde1d858e9a9/20UMGIM95279.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"},
-		{Title: "Songs in the Key of Life", Artist: "Stevie Wonder", Price: 42.50, Year: 1976, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music118/v4/eb/1f/12/eb1f12ec-474c-63aa-43af-09282f423b9d/00602537004737.rgb.jpg/100x100bb.jpg", Genre: "Motown"},
-		{Title: "Diana", Artist: "Diana Ross", Price: 28.75, Year: 1980, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music115/v4/aa/87/1c/aa871c20-95be-38bd-97e3-ecfeb8ec404b/15UMGIM06551.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"},
-		{Title: "Sex Machine", Artist: "James Brown", Price: 3.0, Year: 1970, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music128/v4/17/8b/05/178b05de-5855-0136-9827-a0e8a6ccf3db/00602547021656.rgb.jpg/100x100bb.jpg", Genre: "Soul"},
+		{Title: "What's Going On", Artist: "Marvin Gaye", Price: money.MustParse("39.99"), Year: 1971, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music112/v4/76/36/2d/76362d74-cb7a-8ef9-104e-cde1d858e9a9/20UMGIM95279.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"},
+		{Title: "Songs in the Key of Life", Artist: "Stevie Wonder", Price: money.MustParse("42.50"), Year: 1976, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music118/v4/eb/1f/12/eb1f12ec-474c-63aa-43af-09282f423b9d/00602537004737.rgb.jpg/100x100bb.jpg", Genre: "Motown"},
+		{Title: "Diana", Artist: "Diana Ross", Price: money.MustParse("28.75"), Year: 1980, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music115/v4/aa/87/1c/aa871c20-95be-38bd-97e3-ecfeb8ec404b/15UMGIM06551.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"},
+		{Title: "Sex Machine", Artist: "James Brown", Price: money.MustParse("3.00"), Year: 1970, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music128/v4/17/8b/05/178b05de-5855-0136-9827-a0e8a6ccf3db/00602547021656.rgb.jpg/100x100bb.jpg", Genre: "Soul"},
 	}
 	for _, album := range initialAlbums {
 		if err := repo.Create(album); err != nil {
@@ -36,10 +52,37 @@ func seedAlbums(repo repository.AlbumRepository) {
 	log.Printf("seedAlbums: seeded %d albums", len(initialAlbums))
 }
 
+// newNotifier builds the notifier chosen by NOTIFIER.
+func newNotifier(cfg *config.Config) (notify.Notifier, error) {
+	if cfg.Notifier == "smtp" {
+		return notify.NewSMTPNotifier(cfg.SMTPAddr, cfg.SMTPFrom, cfg.SMTPUsername, cfg.SMTPPassword), nil
+	}
+	if cfg.NotifyLogFile == "" {
+		return notify.NewLogNotifier(log.Writer()), nil
+	}
+	f, err := os.OpenFile(cfg.NotifyLogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return notify.NewLogNotifier(f), nil
+}
+
+// newArtworkStore builds the artwork storage chosen by ARTWORK_STORAGE.
+func newArtworkStore(cfg *config.Config) storage.Store {
+	if cfg.ArtworkStorage == "s3" {
+		return storage.NewS3Store(cfg.S3Endpoint, cfg.S3Region, cfg.S3Bucket, cfg.S3AccessKeyID, cfg.S3SecretAccessKey, cfg.S3PublicURL)
+	}
+	return storage.NewLocalStore(cfg.ArtworkDir, cfg.ArtworkBaseURL)
+}
+
 func main() {
 	_ = godotenv.Load()
 
-	dbConn, err := db.ConnectFromEnv()
+	cfg, err := config.LoadFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	dbConn, err := db.Connect(cfg)
 	if err != nil {
 		log.Fatalf("Failed to connect to DB: %v", err)
 	}
@@ -52,20 +95,183 @@ func main() {
 
 	// Select repository implementation based on database backend
 	var repo repository.AlbumRepository
+	var inventoryRepo repository.InventoryRepository
+	var cartRepo repository.CartRepository
+	var orderRepo repository.OrderRepository
+	var paymentRepo repository.PaymentRepository
+	var promotionRepo repository.PromotionRepository
+	var priceRepo repository.PriceRepository
+	var exchangeRateRepo repository.ExchangeRateRepository
+	var taxRateRepo repository.TaxRateRepository
+	var returnRepo repository.ReturnRepository
+	var wishlistRepo repository.WishlistRepository
+	var alertRepo repository.AlertRepository
+	var reviewRepo repository.ReviewRepository
+	var giftCardRepo repository.GiftCardRepository
+	var artistRepo repository.ArtistRepository
+	var genreRepo repository.GenreRepository
+	var labelRepo repository.LabelRepository
+	var releaseRepo repository.ReleaseRepository
+	var usedItemRepo repository.UsedItemRepository
+	var collectionRepo repository.CollectionRepository
+	var revisionRepo repository.RevisionRepository
+	var artworkRepo repository.ArtworkRepository
 	switch dbConn.Backend {
 	case "postgres":
 		log.Printf("Using Postgres backend")
 		repo = repository.NewPostgresAlbumRepository(dbConn.PostgresDB)
+		inventoryRepo = repository.NewPostgresInventoryRepository(dbConn.PostgresDB)
+		cartRepo = repository.NewPostgresCartRepository(dbConn.PostgresDB)
+		orderRepo = repository.NewPostgresOrderRepository(dbConn.PostgresDB)
+		paymentRepo = repository.NewPostgresPaymentRepository(dbConn.PostgresDB)
+		promotionRepo = repository.NewPostgresPromotionRepository(dbConn.PostgresDB)
+		priceRepo = repository.NewPostgresPriceRepository(dbConn.PostgresDB)
+		exchangeRateRepo = repository.NewPostgresExchangeRateRepository(dbConn.PostgresDB)
+		taxRateRepo = repository.NewPostgresTaxRateRepository(dbConn.PostgresDB)
+		returnRepo = repository.NewPostgresReturnRepository(dbConn.PostgresDB)
+		wishlistRepo = repository.NewPostgresWishlistRepository(dbConn.PostgresDB)
+		alertRepo = repository.NewPostgresAlertRepository(dbConn.PostgresDB)
+		reviewRepo = repository.NewPostgresReviewRepository(dbConn.PostgresDB)
+		giftCardRepo = repository.NewPostgresGiftCardRepository(dbConn.PostgresDB)
+		artistRepo = repository.NewPostgresArtistRepository(dbConn.PostgresDB)
+		genreRepo = repository.NewPostgresGenreRepository(dbConn.PostgresDB)
+		labelRepo = repository.NewPostgresLabelRepository(dbConn.PostgresDB)
+		releaseRepo = repository.NewPostgresReleaseRepository(dbConn.PostgresDB)
+		usedItemRepo = repository.NewPostgresUsedItemRepository(dbConn.PostgresDB)
+		collectionRepo = repository.NewPostgresCollectionRepository(dbConn.PostgresDB)
+		revisionRepo = repository.NewPostgresRevisionRepository(dbConn.PostgresDB)
+		artworkRepo = repository.NewPostgresArtworkRepository(dbConn.PostgresDB)
 	case "cassandra":
 		log.Printf("Using Cassandra backend")
 		repo = repository.NewCassandraAlbumRepository(dbConn.CassandraDB)
+		cassandraInventory := repository.NewCassandraInventoryRepository(dbConn.CassandraDB)
+		inventoryRepo = cassandraInventory
+		cartRepo = repository.NewCassandraCartRepository(dbConn.CassandraDB, cfg.CartTTL)
+		orderRepo = repository.NewCassandraOrderRepository(dbConn.CassandraDB, cassandraInventory)
+		paymentRepo = repository.NewCassandraPaymentRepository(dbConn.CassandraDB)
+		promotionRepo = repository.NewCassandraPromotionRepository(dbConn.CassandraDB)
+		priceRepo = repository.NewCassandraPriceRepository(dbConn.CassandraDB)
+		exchangeRateRepo = repository.NewCassandraExchangeRateRepository(dbConn.CassandraDB)
+		taxRateRepo = repository.NewCassandraTaxRateRepository(dbConn.CassandraDB)
+		returnRepo = repository.NewCassandraReturnRepository(dbConn.CassandraDB)
+		wishlistRepo = repository.NewCassandraWishlistRepository(dbConn.CassandraDB)
+		alertRepo = repository.NewCassandraAlertRepository(dbConn.CassandraDB)
+		reviewRepo = repository.NewCassandraReviewRepository(dbConn.CassandraDB)
+		giftCardRepo = repository.NewCassandraGiftCardRepository(dbConn.CassandraDB)
+		artistRepo = repository.NewCassandraArtistRepository(dbConn.CassandraDB)
+		genreRepo = repository.NewCassandraGenreRepository(dbConn.CassandraDB)
+		labelRepo = repository.NewCassandraLabelRepository(dbConn.CassandraDB)
+		releaseRepo = repository.NewCassandraReleaseRepository(dbConn.CassandraDB)
+		usedItemRepo = repository.NewCassandraUsedItemRepository(dbConn.CassandraDB)
+		collectionRepo = repository.NewCassandraCollectionRepository(dbConn.CassandraDB)
+		revisionRepo = repository.NewCassandraRevisionRepository(dbConn.CassandraDB)
+		artworkRepo = repository.NewCassandraArtworkRepository(dbConn.CassandraDB)
+		// Amounts saved before the decimal columns existed are copied into them
+		if filled, err := repository.BackfillExactAmounts(dbConn.CassandraDB); err != nil {
+			log.Printf("Failed to backfill exact amounts: %v", err)
+		} else if filled > 0 {
+			log.Printf("Backfilled exact amounts in %d rows", filled)
+		}
 	default:
 		log.Fatalf("Unsupported database backend: %s", dbConn.Backend)
 	}
 
+	// Price and stock changes made through the repositories are checked against customers' alerts
+	log.Printf("Using %s notifier", cfg.Notifier)
+	notifier, err := newNotifier(cfg)
+	if err != nil {
+		log.Fatalf("Failed to set up notifier: %v", err)
+	}
+	watcher := alerts.NewWatcher(alertRepo, repo, inventoryRepo, notifier)
+	repo = alerts.WatchAlbums(repo, watcher)
+	inventoryRepo = alerts.WatchInventory(inventoryRepo, watcher)
+	orderRepo = alerts.WatchOrders(orderRepo, watcher)
+
+	log.Printf("Using %s artwork storage", cfg.ArtworkStorage)
+	artworkHandler := handlers.NewArtworkHandler(artworkRepo, repo, newArtworkStore(cfg))
+	artworkHandler.MaxBytes = cfg.ArtworkMaxBytes
+	artworkProxy := artwork.NewProxy(cfg.ArtworkCacheDir, cfg.ArtworkProxyHosts, cfg.ArtworkCacheTTL, cfg.ArtworkMaxBytes)
+	artworkProxyHandler := handlers.NewArtworkProxyHandler(artworkProxy)
+
 	itunesRepo := repository.NewITunesRepository()
 	seedAlbums(repo)
+	// Albums saved before artists existed (or seeded above) are linked to their artists by name
+	if linked, err := repository.LinkAlbumArtists(repo, artistRepo); err != nil {
+		log.Printf("Failed to link albums to artists: %v", err)
+	} else if linked > 0 {
+		log.Printf("Linked %d albums to their artists", linked)
+	}
 	handler := handlers.NewAlbumHandler(repo, itunesRepo)
+	handler.Inventory = inventoryRepo
+	handler.Promotions = promotionRepo
+	handler.Prices = priceRepo
+	handler.ExchangeRates = exchangeRateRepo
+	taxPolicy := tax.Policy{PricesIncludeTax: cfg.PricesIncludeTax, Home: tax.Destination{Country: cfg.TaxHomeCountry}}
+	handler.TaxRates = taxRateRepo
+	handler.Tax = taxPolicy
+	handler.Reviews = reviewRepo
+	handler.Artists = artistRepo
+	artistHandler := handlers.NewArtistHandler(artistRepo, repo)
+	handler.Genres = genreRepo
+	genreHandler := handlers.NewGenreHandler(genreRepo, repo)
+	handler.Labels = labelRepo
+	labelHandler := handlers.NewLabelHandler(labelRepo, repo)
+	labelHandler.Releases = releaseRepo
+	handler.Releases = releaseRepo
+	releaseHandler := handlers.NewReleaseHandler(releaseRepo, repo)
+	releaseHandler.Labels = labelRepo
+	handler.UsedItems = usedItemRepo
+	usedItemHandler := handlers.NewUsedItemHandler(usedItemRepo, repo)
+	handler.Collections = collectionRepo
+	collectionHandler := handlers.NewCollectionHandler(collectionRepo, repo)
+	handler.Revisions = revisionRepo
+	revisionHandler := handlers.NewRevisionHandler(revisionRepo, repo)
+	revisionHandler.Prices = priceRepo
+	priceHandler := handlers.NewPriceHandler(priceRepo, repo)
+	inventoryHandler := handlers.NewInventoryHandler(inventoryRepo, repo)
+	cartHandler := handlers.NewCartHandler(cartRepo, repo, inventoryRepo)
+	cartHandler.Promotions = promotionRepo
+	cartHandler.Genres = genreRepo
+	cartHandler.Releases = releaseRepo
+	orderHandler := handlers.NewOrderHandler(orderRepo, cartRepo, repo)
+	orderHandler.Promotions = promotionRepo
+	orderHandler.Genres = genreRepo
+	orderHandler.Releases = releaseRepo
+	orderHandler.TaxRates = taxRateRepo
+	orderHandler.Tax = taxPolicy
+	promotionHandler := handlers.NewPromotionHandler(promotionRepo)
+	exchangeRateHandler := handlers.NewExchangeRateHandler(exchangeRateRepo)
+	taxHandler := handlers.NewTaxHandler(taxRateRepo)
+	wishlistHandler := handlers.NewWishlistHandler(wishlistRepo, repo, inventoryRepo)
+	wishlistHandler.Promotions = promotionRepo
+	wishlistHandler.Genres = genreRepo
+	alertHandler := handlers.NewAlertHandler(alertRepo, repo)
+	reviewHandler := handlers.NewReviewHandler(reviewRepo, repo)
+	recommender := recommend.NewRecommender(repo, recommend.Content{})
+	recommender.AlsoBought = recommend.AlsoBought{Sources: []recommend.BasketSource{
+		recommend.OrderBaskets{Orders: orderRepo},
+		recommend.WishlistBaskets{Wishlists: wishlistRepo},
+	}}
+	recommendationHandler := handlers.NewRecommendationHandler(recommender, repo)
+
+	log.Printf("Using %s payment provider", cfg.PaymentProvider)
+	paymentProvider := payments.NewFakeProvider(cfg.PaymentWebhookSecret)
+	paymentHandler := handlers.NewPaymentHandler(paymentRepo, orderRepo, paymentProvider)
+	paymentHandler.GiftCards = giftCardRepo
+	returnHandler := handlers.NewReturnHandler(returnRepo, orderRepo, inventoryRepo, paymentRepo, paymentProvider)
+	returnHandler.GiftCards = giftCardRepo
+	returnHandler.UsedItems = usedItemRepo
+	giftCardHandler := handlers.NewGiftCardHandler(giftCardRepo)
+
+	// Background jobs stop when the process receives an interrupt or termination signal
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go watcher.Run(ctx)
+	go jobs.Every(ctx, "cleanupIdleCarts", cfg.CartCleanupInterval, jobs.CleanupIdleCarts(cartRepo, cfg.CartTTL))
+	go jobs.Every(ctx, "applyScheduledPrices", cfg.PriceScheduleInterval, jobs.ApplyScheduledPrices(repo, priceRepo))
+	go jobs.Every(ctx, "refreshRecommendations", cfg.RecommendationInterval, jobs.RefreshRecommendations(recommender))
+	go jobs.Every(ctx, "purgeTrash", cfg.TrashPurgeInterval, jobs.PurgeTrash(repo, releaseRepo, cfg.TrashRetention))
+	go jobs.Every(ctx, "pruneArtworkCache", cfg.ArtworkCachePruneInterval, jobs.PruneArtworkCache(artworkProxy))
 
 	r := gin.Default()
 
@@ -77,7 +283,7 @@ func main() {
 			"http://127.0.0.1:3000", // Alternative localhost
 		},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-User-ID", "Idempotency-Key", "Accept-Currency"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 	}))
@@ -87,7 +293,139 @@ func main() {
 	r.POST("/albums", handler.PostAlbums)
 	r.DELETE("/albums/:id", handler.DeleteAlbum)
 	r.PUT("/albums/:id", handler.PutAlbum)
+	r.GET("/albums/trash", handler.GetTrash)
+	r.POST("/albums/:id/restore", handler.RestoreAlbum)
+	r.GET("/albums/:id/artwork", artworkHandler.GetArtwork)
+	r.POST("/albums/:id/artwork", artworkHandler.PostArtwork)
+	r.GET("/artwork", artworkProxyHandler.GetProxiedArtwork)
+	if cfg.ArtworkStorage == "local" {
+		if u, err := url.Parse(cfg.ArtworkBaseURL); err == nil && strings.HasPrefix(u.Path, "/") {
+			r.Static(u.Path, cfg.ArtworkDir)
+		}
+	}
 	r.GET("/api/search", handler.SearchAlbums)
+	r.GET("/artists", artistHandler.GetArtists)
+	r.GET("/artists/:id", artistHandler.GetArtist)
+	r.POST("/artists", artistHandler.PostArtist)
+	r.PUT("/artists/:id", artistHandler.PutArtist)
+	r.DELETE("/artists/:id", artistHandler.DeleteArtist)
+	r.GET("/artists/:id/albums", handler.GetArtistAlbums)
+	r.GET("/genres", genreHandler.GetGenres)
+	r.GET("/genres/:id", genreHandler.GetGenre)
+	r.POST("/genres", genreHandler.PostGenre)
+	r.PUT("/genres/:id", genreHandler.PutGenre)
+	r.DELETE("/genres/:id", genreHandler.DeleteGenre)
+	r.GET("/labels", labelHandler.GetLabels)
+	r.GET("/labels/:id", labelHandler.GetLabel)
+	r.POST("/labels", labelHandler.PostLabel)
+	r.PUT("/labels/:id", labelHandler.PutLabel)
+	r.DELETE("/labels/:id", labelHandler.DeleteLabel)
+	r.GET("/labels/:id/albums", handler.GetLabelAlbums)
+	r.GET("/labels/:id/catalogue/:number", handler.GetAlbumByCatalogueNumber)
+	r.GET("/albums/barcode/:barcode", handler.GetAlbumByBarcode)
+	r.GET("/albums/:id/releases", releaseHandler.GetReleases)
+	r.GET("/albums/:id/releases/:releaseId", releaseHandler.GetRelease)
+	r.POST("/albums/:id/releases", releaseHandler.PostRelease)
+	r.PUT("/albums/:id/releases/:releaseId", releaseHandler.PutRelease)
+	r.DELETE("/albums/:id/releases/:releaseId", releaseHandler.DeleteRelease)
+	r.GET("/used", usedItemHandler.GetUsedItems)
+	r.GET("/used/:id", usedItemHandler.GetUsedItem)
+	r.PUT("/used/:id", usedItemHandler.PutUsedItem)
+	r.DELETE("/used/:id", usedItemHandler.DeleteUsedItem)
+	r.GET("/albums/:id/used", usedItemHandler.GetAlbumUsedItems)
+	r.POST("/albums/:id/used", usedItemHandler.PostUsedItem)
+	r.GET("/tags", handler.GetTags)
+	r.GET("/collections", collectionHandler.GetCollections)
+	r.GET("/collections/:id", collectionHandler.GetCollection)
+	r.POST("/collections", collectionHandler.PostCollection)
+	r.PUT("/collections/:id", collectionHandler.PutCollection)
+	r.DELETE("/collections/:id", collectionHandler.DeleteCollection)
+	r.GET("/collections/:id/albums", handler.GetCollectionAlbums)
+	r.POST("/collections/:id/albums", collectionHandler.PostCollectionAlbum)
+	r.DELETE("/collections/:id/albums/:albumId", collectionHandler.DeleteCollectionAlbum)
+
+	r.GET("/albums/:id/stock", inventoryHandler.GetStock)
+	r.GET("/albums/:id/stock/adjustments", inventoryHandler.GetAdjustments)
+	r.POST("/albums/:id/stock/adjustments", inventoryHandler.PostAdjustment)
+
+	r.POST("/albums/:id/alerts", alertHandler.PostAlert)
+	r.GET("/alerts", alertHandler.GetMyAlerts)
+	r.DELETE("/alerts/:id", alertHandler.DeleteMyAlert)
+
+	r.GET("/albums/:id/reviews", reviewHandler.GetAlbumReviews)
+	r.POST("/albums/:id/reviews", reviewHandler.PostReview)
+	r.DELETE("/reviews/:id", reviewHandler.DeleteMyReview)
+	r.GET("/albums/:id/recommendations", recommendationHandler.GetRecommendations)
+
+	r.GET("/albums/:id/prices", priceHandler.GetPrices)
+	r.POST("/albums/:id/prices/scheduled", priceHandler.PostScheduledPrice)
+	r.DELETE("/albums/:id/prices/scheduled/:scheduleId", priceHandler.DeleteScheduledPrice)
+	r.GET("/albums/:id/revisions", revisionHandler.GetRevisions)
+	r.GET("/albums/:id/revisions/diff", revisionHandler.GetRevisionDiff)
+	r.GET("/albums/:id/revisions/:number", revisionHandler.GetRevision)
+	r.POST("/albums/:id/revisions/:number/rollback", revisionHandler.PostRollback)
+	r.GET("/exchange-rates", exchangeRateHandler.GetExchangeRates)
+	r.GET("/tax-rates", taxHandler.GetTaxRates)
+
+	r.POST("/carts", cartHandler.PostCart)
+	r.GET("/carts/:id", cartHandler.GetCart)
+	r.POST("/carts/:id/items", cartHandler.PostCartItem)
+	r.PUT("/carts/:id/items/:albumId/:format", cartHandler.PutCartItem)
+	r.DELETE("/carts/:id/items/:albumId/:format", cartHandler.DeleteCartItem)
+	r.POST("/carts/:id/merge", cartHandler.MergeCart)
+	r.PUT("/carts/:id/coupon", cartHandler.PutCoupon)
+	r.DELETE("/carts/:id/coupon", cartHandler.DeleteCoupon)
+
+	r.POST("/checkout", orderHandler.Checkout)
+	r.GET("/orders", orderHandler.GetMyOrders)
+	r.GET("/orders/:id", orderHandler.GetMyOrder)
+	r.POST("/orders/:id/cancel", orderHandler.CancelMyOrder)
+	r.POST("/orders/:id/payments", paymentHandler.PostPayment)
+	r.GET("/orders/:id/payments", paymentHandler.GetOrderPayments)
+	r.POST("/payments/webhook", paymentHandler.Webhook)
+	r.POST("/orders/:id/returns", returnHandler.PostReturn)
+	r.GET("/orders/:id/returns", returnHandler.GetOrderReturns)
+	r.GET("/returns/:id", returnHandler.GetMyReturn)
+	r.POST("/returns/:id/cancel", returnHandler.CancelMyReturn)
+	r.POST("/gift-cards/balance", giftCardHandler.CheckGiftCardBalance)
+	r.GET("/store-credit", giftCardHandler.GetMyStoreCredit)
+	r.GET("/wishlist", wishlistHandler.GetWishlist)
+	r.POST("/wishlist/items", wishlistHandler.PostWishlistItem)
+	r.DELETE("/wishlist/items/:albumId", wishlistHandler.DeleteWishlistItem)
+	r.POST("/wishlist/share", wishlistHandler.ShareWishlist)
+	r.DELETE("/wishlist/share", wishlistHandler.UnshareWishlist)
+	r.GET("/wishlists/shared/:token", wishlistHandler.GetSharedWishlist)
+
+	// Staff-only routes; access control is expected to be enforced in front of this service, except
+	// for the routes that move money, which also need the staff token
+	staff := handlers.RequireStaff(cfg.StaffToken)
+	r.GET("/admin/orders", orderHandler.ListOrders)
+	r.GET("/admin/orders/:id", orderHandler.GetOrder)
+	r.POST("/admin/orders/:id/status", orderHandler.PostOrderStatus)
+	r.POST("/admin/payments/:id/refund", staff, paymentHandler.PostRefund)
+	r.GET("/admin/returns", returnHandler.ListReturns)
+	r.GET("/admin/returns/:id", returnHandler.GetReturn)
+	r.POST("/admin/returns/:id/approve", returnHandler.ApproveReturn)
+	r.POST("/admin/returns/:id/reject", returnHandler.RejectReturn)
+	r.POST("/admin/returns/:id/receive", returnHandler.ReceiveReturn)
+	r.POST("/admin/returns/:id/refund", staff, returnHandler.RefundReturn)
+	r.POST("/admin/used/:id/sell", usedItemHandler.SellUsedItem)
+	r.POST("/admin/used/:id/relist", usedItemHandler.RelistUsedItem)
+	r.GET("/admin/gift-cards", giftCardHandler.ListGiftCards)
+	r.POST("/admin/gift-cards", staff, giftCardHandler.IssueGiftCard)
+	r.GET("/admin/gift-cards/:id", giftCardHandler.GetGiftCard)
+	r.POST("/admin/gift-cards/:id/adjust", staff, giftCardHandler.AdjustGiftCard)
+	r.GET("/admin/reviews", reviewHandler.ListReviews)
+	r.POST("/admin/reviews/:id/approve", reviewHandler.ApproveReview)
+	r.POST("/admin/reviews/:id/reject", reviewHandler.RejectReview)
+	r.DELETE("/admin/reviews/:id", reviewHandler.DeleteReview)
+	r.GET("/admin/promotions", promotionHandler.ListPromotions)
+	r.POST("/admin/promotions", promotionHandler.PostPromotion)
+	r.GET("/admin/promotions/:id", promotionHandler.GetPromotion)
+	r.PUT("/admin/promotions/:id", promotionHandler.PutPromotion)
+	r.DELETE("/admin/promotions/:id", promotionHandler.DeletePromotion)
+	r.PUT("/admin/exchange-rates", exchangeRateHandler.PutExchangeRates)
+	r.PUT("/admin/tax-rates", taxHandler.PutTaxRates)
 
 	if err := r.Run(":8080"); err != nil {
 		log.Fatalf("Failed to run server: %v", err)
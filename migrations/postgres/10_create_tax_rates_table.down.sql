@@ -0,0 +1,10 @@
+ALTER TABLE order_lines DROP COLUMN IF EXISTS tax;
+ALTER TABLE order_lines DROP COLUMN IF EXISTS tax_rate;
+ALTER TABLE order_lines DROP COLUMN IF EXISTS tax_name;
+ALTER TABLE order_lines DROP COLUMN IF EXISTS tax_category;
+ALTER TABLE orders DROP COLUMN IF EXISTS tax_included;
+ALTER TABLE orders DROP COLUMN IF EXISTS tax;
+ALTER TABLE orders DROP COLUMN IF EXISTS region;
+ALTER TABLE orders DROP COLUMN IF EXISTS country;
+ALTER TABLE albums DROP COLUMN IF EXISTS tax_category;
+DROP TABLE IF EXISTS tax_rates;
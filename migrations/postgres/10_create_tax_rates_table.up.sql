@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS tax_rates (
+    country CHAR(2) NOT NULL,
+    region TEXT NOT NULL DEFAULT '',
+    category TEXT NOT NULL DEFAULT '',
+    name TEXT NOT NULL,
+    rate NUMERIC NOT NULL CHECK (rate >= 0 AND rate <= 100),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (country, region, category)
+);
+
+ALTER TABLE albums ADD COLUMN IF NOT EXISTS tax_category TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS country TEXT NOT NULL DEFAULT '';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS region TEXT NOT NULL DEFAULT '';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS tax NUMERIC(12, 2) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS tax_included BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE order_lines ADD COLUMN IF NOT EXISTS tax_category TEXT NOT NULL DEFAULT '';
+ALTER TABLE order_lines ADD COLUMN IF NOT EXISTS tax_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE order_lines ADD COLUMN IF NOT EXISTS tax_rate NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE order_lines ADD COLUMN IF NOT EXISTS tax NUMERIC(12, 2) NOT NULL DEFAULT 0;
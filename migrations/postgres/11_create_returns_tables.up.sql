@@ -0,0 +1,41 @@
+CREATE TABLE IF NOT EXISTS returns (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL,
+    status TEXT NOT NULL,
+    refund_amount NUMERIC(12, 2) NOT NULL,
+    refunded_amount NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS returns_order_id_idx ON returns (order_id);
+CREATE INDEX IF NOT EXISTS returns_user_id_idx ON returns (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS returns_status_idx ON returns (status);
+
+CREATE TABLE IF NOT EXISTS return_lines (
+    return_id UUID NOT NULL REFERENCES returns(id) ON DELETE CASCADE,
+    line_no INTEGER NOT NULL,
+    album_id INTEGER NOT NULL,
+    format TEXT NOT NULL,
+    title TEXT NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    reason TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    refund NUMERIC(12, 2) NOT NULL,
+    restock TEXT NOT NULL DEFAULT '',
+    media_grade TEXT NOT NULL DEFAULT '',
+    sleeve_grade TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (return_id, line_no)
+);
+
+CREATE TABLE IF NOT EXISTS return_events (
+    id SERIAL PRIMARY KEY,
+    return_id UUID NOT NULL REFERENCES returns(id) ON DELETE CASCADE,
+    status TEXT NOT NULL,
+    actor TEXT NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS return_events_return_id_idx ON return_events (return_id);
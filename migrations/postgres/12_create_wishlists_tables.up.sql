@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS wishlists (
+    user_id TEXT PRIMARY KEY,
+    share_token TEXT UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS wishlist_items (
+    user_id TEXT NOT NULL REFERENCES wishlists(user_id) ON DELETE CASCADE,
+    album_id INTEGER NOT NULL REFERENCES albums(id) ON DELETE CASCADE,
+    note TEXT NOT NULL DEFAULT '',
+    added_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, album_id)
+);
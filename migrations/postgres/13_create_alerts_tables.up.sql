@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS alerts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id TEXT NOT NULL,
+    email TEXT NOT NULL,
+    album_id INTEGER NOT NULL REFERENCES albums(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    format TEXT NOT NULL DEFAULT '',
+    target_price NUMERIC(12, 2),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    triggered_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS alerts_user_id_idx ON alerts (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS alerts_active_album_id_idx ON alerts (album_id) WHERE triggered_at IS NULL;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS reviews (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    album_id INTEGER NOT NULL REFERENCES albums(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL,
+    author TEXT NOT NULL,
+    rating SMALLINT NOT NULL CHECK (rating BETWEEN 1 AND 5),
+    title TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (album_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS reviews_status_idx ON reviews (status, created_at DESC);
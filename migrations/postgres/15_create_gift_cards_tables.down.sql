@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS gift_card_entries;
+DROP TABLE IF EXISTS gift_cards;
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS gift_cards (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind TEXT NOT NULL,
+    code_hash TEXT UNIQUE,
+    last4 TEXT NOT NULL DEFAULT '',
+    user_id TEXT,
+    initial_balance NUMERIC(12, 2) NOT NULL,
+    balance NUMERIC(12, 2) NOT NULL CHECK (balance >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Each customer has at most one store credit account.
+CREATE UNIQUE INDEX IF NOT EXISTS gift_cards_store_credit_user_id_idx ON gift_cards (user_id) WHERE kind = 'store-credit';
+
+CREATE TABLE IF NOT EXISTS gift_card_entries (
+    id SERIAL PRIMARY KEY,
+    gift_card_id UUID NOT NULL REFERENCES gift_cards(id) ON DELETE CASCADE,
+    amount NUMERIC(12, 2) NOT NULL,
+    balance NUMERIC(12, 2) NOT NULL,
+    reason TEXT NOT NULL,
+    order_id TEXT,
+    actor TEXT NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS gift_card_entries_gift_card_id_idx ON gift_card_entries (gift_card_id, created_at);
@@ -0,0 +1,4 @@
+-- Album artist names keep the spelling the backfill chose.
+ALTER TABLE albums DROP COLUMN IF EXISTS artist_id;
+DROP TABLE IF EXISTS artist_names;
+DROP TABLE IF EXISTS artists;
@@ -0,0 +1,54 @@
+CREATE TABLE IF NOT EXISTS artists (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    sort_name TEXT NOT NULL,
+    aliases JSONB NOT NULL DEFAULT '[]',
+    bio TEXT NOT NULL DEFAULT '',
+    image_url TEXT NOT NULL DEFAULT '',
+    itunes_artist_id BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Every name and alias an artist goes by, in the form names are matched in (see ArtistNameKey).
+CREATE TABLE IF NOT EXISTS artist_names (
+    name_key TEXT PRIMARY KEY,
+    artist_id UUID NOT NULL REFERENCES artists(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS artist_names_artist_id_idx ON artist_names (artist_id);
+
+ALTER TABLE albums ADD COLUMN IF NOT EXISTS artist_id UUID REFERENCES artists(id);
+
+CREATE INDEX IF NOT EXISTS albums_artist_id_idx ON albums (artist_id);
+
+-- Backfill: one artist for each distinct name key among the existing albums, named with the most
+-- common spelling, and every album linked to it and renamed to that spelling.
+CREATE TEMPORARY TABLE album_artist_names AS
+SELECT id AS album_id, name, COALESCE(NULLIF(regexp_replace(trim(regexp_replace(regexp_replace(
+           replace(lower(name), '&', ' and '), '[^[:alnum:][:space:]]', '', 'g'), '\s+', ' ', 'g')), '^the ', ''), ''),
+       lower(name)) AS name_key
+FROM (SELECT id, regexp_replace(trim(artist), '\s+', ' ', 'g') AS name FROM albums) spelled
+WHERE name <> '';
+
+WITH chosen AS (
+    SELECT DISTINCT ON (name_key) name_key, name
+    FROM album_artist_names
+    GROUP BY name_key, name
+    ORDER BY name_key, COUNT(*) DESC, name
+), inserted AS (
+    INSERT INTO artists (name, sort_name)
+    SELECT name, CASE WHEN name ILIKE 'the _%' THEN substr(name, 5) || ', ' || substr(name, 1, 3) ELSE name END
+    FROM chosen
+    RETURNING id, name
+)
+INSERT INTO artist_names (name_key, artist_id)
+SELECT chosen.name_key, inserted.id FROM chosen JOIN inserted ON inserted.name = chosen.name;
+
+UPDATE albums SET artist_id = artists.id, artist = artists.name
+FROM album_artist_names
+JOIN artist_names ON artist_names.name_key = album_artist_names.name_key
+JOIN artists ON artists.id = artist_names.artist_id
+WHERE albums.id = album_artist_names.album_id;
+
+DROP TABLE album_artist_names;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS genre_names;
+DROP TABLE IF EXISTS genres;
@@ -0,0 +1,68 @@
+CREATE TABLE IF NOT EXISTS genres (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    parent_id TEXT REFERENCES genres(id),
+    aliases JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS genres_parent_id_idx ON genres (parent_id);
+
+-- Every name and alias a genre goes by, in the form names are matched in (see GenreKey).
+CREATE TABLE IF NOT EXISTS genre_names (
+    name_key TEXT PRIMARY KEY,
+    genre_id TEXT NOT NULL REFERENCES genres(id) ON DELETE CASCADE
+);
+
+-- A starting taxonomy covering the genres iTunes files most of the catalogue under.
+INSERT INTO genres (id, name, parent_id, aliases) VALUES
+    ('soul', 'Soul', NULL, '["R&B/Soul"]'),
+    ('motown', 'Motown', 'soul', '[]'),
+    ('northern-soul', 'Northern Soul', 'motown', '[]'),
+    ('funk', 'Funk', 'soul', '[]'),
+    ('neo-soul', 'Neo-Soul', 'soul', '[]'),
+    ('r-and-b', 'R&B', NULL, '["Rhythm and Blues"]'),
+    ('pop', 'Pop', NULL, '[]'),
+    ('rock', 'Rock', NULL, '[]'),
+    ('alternative', 'Alternative', 'rock', '[]'),
+    ('jazz', 'Jazz', NULL, '[]'),
+    ('blues', 'Blues', NULL, '[]'),
+    ('hip-hop', 'Hip-Hop', NULL, '["Hip-Hop/Rap", "Rap"]'),
+    ('dance', 'Dance', NULL, '[]'),
+    ('disco', 'Disco', 'dance', '[]'),
+    ('electronic', 'Electronic', NULL, '["Electronica"]'),
+    ('reggae', 'Reggae', NULL, '[]'),
+    ('country', 'Country', NULL, '[]'),
+    ('singer-songwriter', 'Singer/Songwriter', NULL, '[]'),
+    ('classical', 'Classical', NULL, '[]'),
+    ('soundtrack', 'Soundtrack', NULL, '[]')
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO genre_names (name_key, genre_id) VALUES
+    ('soul', 'soul'),
+    ('r and b soul', 'soul'),
+    ('motown', 'motown'),
+    ('northern soul', 'northern-soul'),
+    ('funk', 'funk'),
+    ('neo soul', 'neo-soul'),
+    ('r and b', 'r-and-b'),
+    ('rhythm and blues', 'r-and-b'),
+    ('pop', 'pop'),
+    ('rock', 'rock'),
+    ('alternative', 'alternative'),
+    ('jazz', 'jazz'),
+    ('blues', 'blues'),
+    ('hip hop', 'hip-hop'),
+    ('hip hop rap', 'hip-hop'),
+    ('rap', 'hip-hop'),
+    ('dance', 'dance'),
+    ('disco', 'disco'),
+    ('electronic', 'electronic'),
+    ('electronica', 'electronic'),
+    ('reggae', 'reggae'),
+    ('country', 'country'),
+    ('singer songwriter', 'singer-songwriter'),
+    ('classical', 'classical'),
+    ('soundtrack', 'soundtrack')
+ON CONFLICT (name_key) DO NOTHING;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS albums_barcode_idx;
+DROP INDEX IF EXISTS albums_label_catalogue_number_idx;
+ALTER TABLE albums DROP COLUMN IF EXISTS barcode;
+ALTER TABLE albums DROP COLUMN IF EXISTS catalogue_number;
+ALTER TABLE albums DROP COLUMN IF EXISTS label;
+ALTER TABLE albums DROP COLUMN IF EXISTS label_id;
+DROP TABLE IF EXISTS labels;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS labels (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    -- The name in the form labels are matched in (see LabelNameKey).
+    name_key TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO labels (name, name_key) VALUES
+    ('Motown', 'motown'),
+    ('Tamla', 'tamla'),
+    ('Gordy', 'gordy'),
+    ('Soul', 'soul')
+ON CONFLICT (name_key) DO NOTHING;
+
+ALTER TABLE albums ADD COLUMN IF NOT EXISTS label_id UUID REFERENCES labels(id);
+ALTER TABLE albums ADD COLUMN IF NOT EXISTS label TEXT NOT NULL DEFAULT '';
+ALTER TABLE albums ADD COLUMN IF NOT EXISTS catalogue_number TEXT;
+ALTER TABLE albums ADD COLUMN IF NOT EXISTS barcode TEXT;
+
+-- Catalogue numbers are unique within a label, and barcodes (stored as EAN-13) across the catalogue.
+-- The repository maps violations of these indexes by name.
+CREATE UNIQUE INDEX IF NOT EXISTS albums_label_catalogue_number_idx ON albums (label_id, catalogue_number) WHERE catalogue_number IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS albums_barcode_idx ON albums (barcode) WHERE barcode IS NOT NULL;
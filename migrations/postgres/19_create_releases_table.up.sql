@@ -0,0 +1,20 @@
+-- Releases are the editions of an album in each format. Albums without any are sold as they are.
+CREATE TABLE IF NOT EXISTS releases (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    album_id INTEGER NOT NULL REFERENCES albums(id) ON DELETE CASCADE,
+    format TEXT NOT NULL,
+    edition TEXT NOT NULL DEFAULT '',
+    year INTEGER NOT NULL,
+    label_id UUID REFERENCES labels(id),
+    label TEXT NOT NULL DEFAULT '',
+    catalogue_number TEXT NOT NULL DEFAULT '',
+    barcode TEXT,
+    price NUMERIC(12, 2) NOT NULL CHECK (price >= 0),
+    currency TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS releases_album_id_idx ON releases (album_id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS releases_barcode_idx ON releases (barcode) WHERE barcode IS NOT NULL;
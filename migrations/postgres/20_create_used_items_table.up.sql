@@ -0,0 +1,17 @@
+-- Used items are single second-hand copies of an album, graded on the Goldmine scale.
+CREATE TABLE IF NOT EXISTS used_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    album_id INTEGER NOT NULL REFERENCES albums(id) ON DELETE CASCADE,
+    format TEXT NOT NULL,
+    media_grade TEXT NOT NULL,
+    sleeve_grade TEXT NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    photos JSONB NOT NULL DEFAULT '[]',
+    price NUMERIC(12, 2) NOT NULL CHECK (price >= 0),
+    currency TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'available',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS used_items_album_id_idx ON used_items (album_id, status);
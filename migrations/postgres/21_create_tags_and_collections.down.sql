@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS collections;
+DROP INDEX IF EXISTS albums_tags_idx;
+ALTER TABLE albums DROP COLUMN IF EXISTS tags;
@@ -0,0 +1,18 @@
+-- Free-form tags such as "summer" or "staff-pick", lower-cased and stored as a JSON array.
+ALTER TABLE albums ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '[]';
+
+CREATE INDEX IF NOT EXISTS albums_tags_idx ON albums USING GIN (tags);
+
+-- Collections group albums for display. Curated collections list their albums in album_ids, in
+-- order; smart collections choose them with rules such as "genre = Soul AND year < 1975".
+CREATE TABLE IF NOT EXISTS collections (
+    id TEXT PRIMARY KEY,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    cover_url TEXT NOT NULL DEFAULT '',
+    kind TEXT NOT NULL,
+    album_ids JSONB NOT NULL DEFAULT '[]',
+    rules TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,4 @@
+DELETE FROM albums WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS albums_deleted_at_idx;
+ALTER TABLE albums DROP COLUMN IF EXISTS deleted_by;
+ALTER TABLE albums DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,6 @@
+-- Deleted albums go into the trash first: deleted_at and deleted_by record when and by whom, and the
+-- purge job removes them for good once they have been there longer than the retention period.
+ALTER TABLE albums ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE albums ADD COLUMN IF NOT EXISTS deleted_by TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS albums_deleted_at_idx ON albums (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,12 @@
+-- Every edit to an album is kept as a numbered snapshot of its fields, so it can be compared with
+-- other revisions or rolled back to.
+CREATE TABLE IF NOT EXISTS album_revisions (
+    album_id INTEGER NOT NULL REFERENCES albums(id) ON DELETE CASCADE,
+    number INTEGER NOT NULL,
+    snapshot JSONB NOT NULL,
+    actor TEXT NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    rolled_back_to INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (album_id, number)
+);
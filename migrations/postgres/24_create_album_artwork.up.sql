@@ -0,0 +1,14 @@
+-- Sleeve images uploaded for albums. The files themselves are kept in artwork storage; this records
+-- where, and the thumbnails made from each.
+CREATE TABLE IF NOT EXISTS album_artwork (
+    album_id INTEGER PRIMARY KEY REFERENCES albums(id) ON DELETE CASCADE,
+    storage_key TEXT NOT NULL,
+    url TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    bytes INTEGER NOT NULL,
+    thumbnails JSONB NOT NULL DEFAULT '[]',
+    uploaded_by TEXT NOT NULL DEFAULT '',
+    uploaded_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
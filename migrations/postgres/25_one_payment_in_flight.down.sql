@@ -0,0 +1 @@
+DROP INDEX IF EXISTS payment_intents_in_flight_idx;
@@ -0,0 +1,4 @@
+-- An order may have only one payment pending or authorised at a time, so two concurrent requests
+-- cannot both charge its outstanding balance.
+CREATE UNIQUE INDEX IF NOT EXISTS payment_intents_in_flight_idx ON payment_intents (order_id)
+    WHERE status IN ('pending', 'authorized');
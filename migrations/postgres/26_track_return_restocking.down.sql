@@ -0,0 +1 @@
+ALTER TABLE return_lines DROP COLUMN IF EXISTS restocked;
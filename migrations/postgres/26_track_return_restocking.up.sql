@@ -0,0 +1,8 @@
+-- Count the units of each returned line that have been restocked, so receiving a return that failed
+-- part way can be finished without restocking anything twice. Returns already received under the old
+-- code restocked every unit.
+ALTER TABLE return_lines ADD COLUMN IF NOT EXISTS restocked INTEGER NOT NULL DEFAULT 0;
+
+UPDATE return_lines SET restocked = quantity
+WHERE restock IN ('new', 'used')
+  AND return_id IN (SELECT id FROM returns WHERE status IN ('received', 'refunded'));
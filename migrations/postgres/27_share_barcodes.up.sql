@@ -0,0 +1,16 @@
+-- Albums and releases share one namespace of barcodes. Each barcode in use is recorded here against
+-- the album or release that has it, and the primary key stops any two from having the same one.
+CREATE TABLE IF NOT EXISTS barcodes (
+    barcode TEXT PRIMARY KEY,
+    album_id INTEGER REFERENCES albums(id) ON DELETE CASCADE,
+    release_id UUID REFERENCES releases(id) ON DELETE CASCADE,
+    CHECK ((album_id IS NULL) <> (release_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS barcodes_album_id_idx ON barcodes (album_id);
+CREATE INDEX IF NOT EXISTS barcodes_release_id_idx ON barcodes (release_id);
+
+-- A release that already shares an album's barcode keeps it, but cannot be saved with it again.
+INSERT INTO barcodes (barcode, album_id) SELECT barcode, id FROM albums WHERE barcode IS NOT NULL;
+INSERT INTO barcodes (barcode, release_id) SELECT barcode, id FROM releases WHERE barcode IS NOT NULL
+ON CONFLICT (barcode) DO NOTHING;
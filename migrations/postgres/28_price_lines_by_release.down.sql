@@ -0,0 +1,7 @@
+ALTER TABLE return_lines DROP COLUMN IF EXISTS release_id;
+ALTER TABLE order_lines DROP COLUMN IF EXISTS release_id;
+
+DELETE FROM cart_items WHERE release_id <> '';
+ALTER TABLE cart_items DROP CONSTRAINT IF EXISTS cart_items_pkey;
+ALTER TABLE cart_items ADD PRIMARY KEY (cart_id, album_id, format);
+ALTER TABLE cart_items DROP COLUMN IF EXISTS release_id;
@@ -0,0 +1,8 @@
+-- Cart, order and return lines can be for one release of an album, priced as that release. Lines for
+-- the album itself have no release; '' rather than NULL lets the release be part of a cart item's key.
+ALTER TABLE cart_items ADD COLUMN IF NOT EXISTS release_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE cart_items DROP CONSTRAINT IF EXISTS cart_items_pkey;
+ALTER TABLE cart_items ADD PRIMARY KEY (cart_id, album_id, format, release_id);
+
+ALTER TABLE order_lines ADD COLUMN IF NOT EXISTS release_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE return_lines ADD COLUMN IF NOT EXISTS release_id TEXT NOT NULL DEFAULT '';
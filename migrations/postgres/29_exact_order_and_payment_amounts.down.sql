@@ -0,0 +1,15 @@
+ALTER TABLE scheduled_prices ALTER COLUMN price TYPE NUMERIC;
+ALTER TABLE price_history ALTER COLUMN new_price TYPE NUMERIC;
+ALTER TABLE price_history ALTER COLUMN old_price TYPE NUMERIC;
+
+ALTER TABLE promotions ALTER COLUMN value TYPE NUMERIC;
+
+ALTER TABLE payment_intents ALTER COLUMN refunded_amount TYPE NUMERIC;
+ALTER TABLE payment_intents ALTER COLUMN amount TYPE NUMERIC;
+
+ALTER TABLE order_lines ALTER COLUMN discount TYPE NUMERIC;
+ALTER TABLE order_lines ALTER COLUMN line_total TYPE NUMERIC;
+ALTER TABLE order_lines ALTER COLUMN unit_price TYPE NUMERIC;
+
+ALTER TABLE orders ALTER COLUMN discount TYPE NUMERIC;
+ALTER TABLE orders ALTER COLUMN total TYPE NUMERIC;
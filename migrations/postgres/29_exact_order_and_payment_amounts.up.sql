@@ -0,0 +1,17 @@
+-- Order, payment, promotion and price amounts were unbounded NUMERIC; hold them to the two decimal
+-- places money.Amount keeps, as album prices already are.
+ALTER TABLE orders ALTER COLUMN total TYPE NUMERIC(12, 2);
+ALTER TABLE orders ALTER COLUMN discount TYPE NUMERIC(12, 2);
+
+ALTER TABLE order_lines ALTER COLUMN unit_price TYPE NUMERIC(12, 2);
+ALTER TABLE order_lines ALTER COLUMN line_total TYPE NUMERIC(12, 2);
+ALTER TABLE order_lines ALTER COLUMN discount TYPE NUMERIC(12, 2);
+
+ALTER TABLE payment_intents ALTER COLUMN amount TYPE NUMERIC(12, 2);
+ALTER TABLE payment_intents ALTER COLUMN refunded_amount TYPE NUMERIC(12, 2);
+
+ALTER TABLE promotions ALTER COLUMN value TYPE NUMERIC(12, 2);
+
+ALTER TABLE price_history ALTER COLUMN old_price TYPE NUMERIC(12, 2);
+ALTER TABLE price_history ALTER COLUMN new_price TYPE NUMERIC(12, 2);
+ALTER TABLE scheduled_prices ALTER COLUMN price TYPE NUMERIC(12, 2);
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS inventory (
+    album_id INTEGER NOT NULL REFERENCES albums(id) ON DELETE CASCADE,
+    format TEXT NOT NULL,
+    on_hand INTEGER NOT NULL DEFAULT 0,
+    reserved INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (album_id, format),
+    CHECK (reserved >= 0),
+    CHECK (reserved <= on_hand)
+);
+
+CREATE TABLE IF NOT EXISTS stock_adjustments (
+    id SERIAL PRIMARY KEY,
+    album_id INTEGER NOT NULL REFERENCES albums(id) ON DELETE CASCADE,
+    format TEXT NOT NULL,
+    delta INTEGER NOT NULL,
+    reason TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    actor TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS stock_adjustments_album_id_idx ON stock_adjustments (album_id);
@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS orders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id TEXT NOT NULL,
+    status TEXT NOT NULL,
+    total NUMERIC NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS orders_user_id_idx ON orders (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS orders_status_idx ON orders (status);
+
+-- Lines snapshot the album at purchase time, so they deliberately do not reference albums.
+CREATE TABLE IF NOT EXISTS order_lines (
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    line_no INTEGER NOT NULL,
+    album_id INTEGER NOT NULL,
+    format TEXT NOT NULL,
+    title TEXT NOT NULL,
+    artist TEXT NOT NULL,
+    unit_price NUMERIC NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    line_total NUMERIC NOT NULL,
+    PRIMARY KEY (order_id, line_no)
+);
+
+CREATE TABLE IF NOT EXISTS order_events (
+    id SERIAL PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    status TEXT NOT NULL,
+    actor TEXT NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS order_events_order_id_idx ON order_events (order_id);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS payment_intents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    idempotency_key TEXT NOT NULL UNIQUE,
+    provider TEXT NOT NULL,
+    provider_ref TEXT NOT NULL DEFAULT '',
+    amount NUMERIC NOT NULL,
+    refunded_amount NUMERIC NOT NULL DEFAULT 0,
+    status TEXT NOT NULL,
+    failure_reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS payment_intents_order_id_idx ON payment_intents (order_id);
+CREATE INDEX IF NOT EXISTS payment_intents_provider_ref_idx ON payment_intents (provider, provider_ref);
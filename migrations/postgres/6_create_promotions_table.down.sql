@@ -0,0 +1,6 @@
+ALTER TABLE order_lines DROP COLUMN IF EXISTS promotion;
+ALTER TABLE order_lines DROP COLUMN IF EXISTS discount;
+ALTER TABLE orders DROP COLUMN IF EXISTS discount;
+ALTER TABLE orders DROP COLUMN IF EXISTS coupon_code;
+ALTER TABLE carts DROP COLUMN IF EXISTS coupon_code;
+DROP TABLE IF EXISTS promotions;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS promotions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    code TEXT UNIQUE,
+    kind TEXT NOT NULL,
+    value NUMERIC NOT NULL DEFAULT 0,
+    scope JSONB NOT NULL DEFAULT '{}',
+    starts_at TIMESTAMPTZ,
+    ends_at TIMESTAMPTZ,
+    usage_limit INTEGER NOT NULL DEFAULT 0 CHECK (usage_limit >= 0),
+    usage_count INTEGER NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE carts ADD COLUMN IF NOT EXISTS coupon_code TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS coupon_code TEXT NOT NULL DEFAULT '';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS discount NUMERIC NOT NULL DEFAULT 0;
+
+ALTER TABLE order_lines ADD COLUMN IF NOT EXISTS discount NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE order_lines ADD COLUMN IF NOT EXISTS promotion TEXT NOT NULL DEFAULT '';
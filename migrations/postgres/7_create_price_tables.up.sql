@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS price_history (
+    id SERIAL PRIMARY KEY,
+    album_id INTEGER NOT NULL REFERENCES albums(id) ON DELETE CASCADE,
+    old_price NUMERIC NOT NULL,
+    new_price NUMERIC NOT NULL,
+    source TEXT NOT NULL,
+    actor TEXT NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS price_history_album_id_idx ON price_history (album_id, changed_at DESC);
+
+CREATE TABLE IF NOT EXISTS scheduled_prices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    album_id INTEGER NOT NULL REFERENCES albums(id) ON DELETE CASCADE,
+    price NUMERIC NOT NULL CHECK (price >= 0),
+    effective_at TIMESTAMPTZ NOT NULL,
+    status TEXT NOT NULL,
+    actor TEXT NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS scheduled_prices_album_id_idx ON scheduled_prices (album_id);
+CREATE INDEX IF NOT EXISTS scheduled_prices_due_idx ON scheduled_prices (status, effective_at);
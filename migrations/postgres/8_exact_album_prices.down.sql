@@ -0,0 +1,2 @@
+ALTER TABLE albums DROP COLUMN IF EXISTS currency;
+ALTER TABLE albums ALTER COLUMN price TYPE NUMERIC;
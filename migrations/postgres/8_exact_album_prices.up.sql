@@ -0,0 +1,2 @@
+ALTER TABLE albums ALTER COLUMN price TYPE NUMERIC(12, 2);
+ALTER TABLE albums ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'GBP';
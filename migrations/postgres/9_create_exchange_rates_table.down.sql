@@ -0,0 +1,2 @@
+ALTER TABLE albums DROP COLUMN IF EXISTS currency_prices;
+DROP TABLE IF EXISTS exchange_rates;
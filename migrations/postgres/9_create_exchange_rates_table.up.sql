@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    currency CHAR(3) PRIMARY KEY,
+    rate NUMERIC NOT NULL CHECK (rate > 0),
+    round_to NUMERIC(12, 2) NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE albums ADD COLUMN IF NOT EXISTS currency_prices JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,45 @@
+package money
+
+import (
+	"fmt"
+
+	"github.com/gocql/gocql"
+	"gopkg.in/inf.v0"
+)
+
+// MarshalCQL writes the amount to a Cassandra decimal column, or to a double column in tables
+// created before amounts were exact.
+func (a Amount) MarshalCQL(info gocql.TypeInfo) ([]byte, error) {
+	switch info.Type() {
+	case gocql.TypeDecimal:
+		return gocql.Marshal(info, inf.NewDec(int64(a), minorDigits))
+	case gocql.TypeDouble:
+		return gocql.Marshal(info, a.Float64())
+	}
+	return nil, fmt.Errorf("cannot marshal Amount into %s", info.Type())
+}
+
+// UnmarshalCQL reads a Cassandra decimal or double column. Doubles are rounded to the nearest minor
+// unit, which recovers the exact amount that was written.
+func (a *Amount) UnmarshalCQL(info gocql.TypeInfo, data []byte) error {
+	if len(data) == 0 {
+		*a = 0
+		return nil
+	}
+	switch info.Type() {
+	case gocql.TypeDecimal:
+		var dec inf.Dec
+		if err := gocql.Unmarshal(info, data, &dec); err != nil {
+			return err
+		}
+		return a.scanText(dec.String())
+	case gocql.TypeDouble:
+		var f float64
+		if err := gocql.Unmarshal(info, data, &f); err != nil {
+			return err
+		}
+		*a = FromFloat(f)
+		return nil
+	}
+	return fmt.Errorf("cannot unmarshal %s into Amount", info.Type())
+}
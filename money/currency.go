@@ -0,0 +1,24 @@
+package money
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseCurrency normalises an ISO 4217 currency code, such as "gbp" to "GBP". An empty code means
+// DefaultCurrency.
+func ParseCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return DefaultCurrency, nil
+	}
+	if len(code) != 3 {
+		return "", fmt.Errorf("invalid currency %q: must be a three-letter ISO 4217 code", code)
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return "", fmt.Errorf("invalid currency %q: must be a three-letter ISO 4217 code", code)
+		}
+	}
+	return code, nil
+}
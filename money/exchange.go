@@ -0,0 +1,70 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrNoRate is returned when converting to or from a currency with no exchange rate.
+var ErrNoRate = errors.New("no exchange rate")
+
+// Rates holds how many units of each currency one unit of DefaultCurrency buys, e.g. {"EUR": 1.17}.
+// DefaultCurrency itself always has a rate of 1.
+type Rates map[string]float64
+
+// Convert changes an amount from one currency to another, going through DefaultCurrency, and
+// rounds the result to the nearest minor unit.
+func (r Rates) Convert(a Amount, from, to string) (Amount, error) {
+	if from == to {
+		return a, nil
+	}
+	fromRate, err := r.rate(from)
+	if err != nil {
+		return 0, err
+	}
+	toRate, err := r.rate(to)
+	if err != nil {
+		return 0, err
+	}
+	return Amount(math.Round(float64(a) / fromRate * toRate)), nil
+}
+
+func (r Rates) rate(currency string) (float64, error) {
+	if currency == DefaultCurrency {
+		return 1, nil
+	}
+	rate, ok := r[currency]
+	if !ok || rate <= 0 {
+		return 0, fmt.Errorf("%w for %s", ErrNoRate, currency)
+	}
+	return rate, nil
+}
+
+// roundingIncrements lists currencies whose prices are not shown to the penny: those without minor
+// units, and those whose smallest coin is bigger than one minor unit.
+var roundingIncrements = map[string]Amount{
+	"JPY": 100,
+	"KRW": 100,
+	"ISK": 100,
+	"HUF": 100,
+	"CHF": 5,
+}
+
+// DefaultRounding returns the step prices in the currency are rounded to, e.g. 0.05 for Swiss francs
+// and 1.00 for yen. It is one minor unit for most currencies.
+func DefaultRounding(currency string) Amount {
+	if increment, ok := roundingIncrements[currency]; ok {
+		return increment
+	}
+	return 1
+}
+
+// RoundTo rounds the amount to the nearest multiple of increment, halves rounding away from zero.
+// Increments of one minor unit or less leave the amount unchanged.
+func (a Amount) RoundTo(increment Amount) Amount {
+	if increment <= 1 {
+		return a
+	}
+	return Amount(math.Round(float64(a)/float64(increment))) * increment
+}
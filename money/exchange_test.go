@@ -0,0 +1,39 @@
+package money
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRates_Convert(t *testing.T) {
+	rates := Rates{"EUR": 1.17, "USD": 1.25}
+
+	eur, err := rates.Convert(MustParse("42.50"), "GBP", "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, MustParse("49.73"), eur)
+
+	gbp, err := rates.Convert(MustParse("11.70"), "EUR", "GBP")
+	require.NoError(t, err)
+	assert.Equal(t, MustParse("10.00"), gbp)
+
+	usd, err := rates.Convert(MustParse("11.70"), "EUR", "USD")
+	require.NoError(t, err)
+	assert.Equal(t, MustParse("12.50"), usd, "converts through the base currency")
+
+	same, err := rates.Convert(MustParse("1.00"), "JPY", "JPY")
+	require.NoError(t, err)
+	assert.Equal(t, MustParse("1.00"), same)
+
+	_, err = rates.Convert(MustParse("1.00"), "GBP", "JPY")
+	assert.ErrorIs(t, err, ErrNoRate)
+}
+
+func TestAmount_RoundTo(t *testing.T) {
+	assert.Equal(t, MustParse("48.05"), MustParse("48.03").RoundTo(DefaultRounding("CHF")))
+	assert.Equal(t, MustParse("48.00"), MustParse("48.02").RoundTo(DefaultRounding("CHF")))
+	assert.Equal(t, MustParse("8096.00"), MustParse("8096.25").RoundTo(DefaultRounding("JPY")))
+	assert.Equal(t, MustParse("49.73"), MustParse("49.73").RoundTo(DefaultRounding("EUR")))
+	assert.Equal(t, MustParse("50.00"), MustParse("49.73").RoundTo(MustParse("1.00")))
+}
@@ -0,0 +1,174 @@
+// Package money represents prices and totals exactly. Amounts are held as a whole number of minor
+// units (pence, cents) so that adding up prices never drifts the way float64 arithmetic does.
+package money
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the ISO 4217 code prices are in when none is given.
+const DefaultCurrency = "GBP"
+
+// minorDigits is the number of decimal places an Amount keeps. Every currency the shop sells in has
+// two; currencies with fewer are still held in hundredths.
+const minorDigits = 2
+
+const minorPerUnit = 100
+
+// ErrInvalidAmount is returned when text cannot be read as an exact amount.
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// Amount is an exact amount of money in minor units. It encodes to JSON as a decimal number with
+// two decimal places, so 42.50 stays 42.50 rather than becoming 42.499999....
+type Amount int64
+
+// FromMinor returns the amount of the given number of minor units.
+func FromMinor(minor int64) Amount {
+	return Amount(minor)
+}
+
+// FromFloat converts a float to the nearest minor unit. It is for values that only exist as floats,
+// such as percentages worked out from a price or columns written before amounts were exact.
+func FromFloat(f float64) Amount {
+	return Amount(math.Round(f * minorPerUnit))
+}
+
+// Parse reads a decimal such as "42.5", "42.50" or "-3". It accepts at most two decimal places,
+// ignoring trailing zeros, and never goes through a float.
+func Parse(s string) (Amount, error) {
+	text := strings.TrimSpace(s)
+	negative := strings.HasPrefix(text, "-")
+	if negative || strings.HasPrefix(text, "+") {
+		text = text[1:]
+	}
+	units, fraction, _ := strings.Cut(text, ".")
+	fraction = strings.TrimRight(fraction, "0")
+	if units == "" || len(fraction) > minorDigits || !isDigits(units) || !isDigits(fraction) {
+		return 0, fmt.Errorf("%w: %q must be a decimal with at most %d decimal places", ErrInvalidAmount, s, minorDigits)
+	}
+	fraction += strings.Repeat("0", minorDigits-len(fraction))
+	minor, err := strconv.ParseInt(units+fraction, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q is out of range", ErrInvalidAmount, s)
+	}
+	if negative {
+		minor = -minor
+	}
+	return Amount(minor), nil
+}
+
+// MustParse is like Parse but panics if s is not a valid amount. It is meant for constants and tests.
+func MustParse(s string) Amount {
+	a, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Minor returns the amount as a whole number of minor units.
+func (a Amount) Minor() int64 {
+	return int64(a)
+}
+
+// Float64 returns the amount in major units. It is only for places that need a float, such as
+// display or legacy storage; do arithmetic on the Amount itself.
+func (a Amount) Float64() float64 {
+	return float64(a) / minorPerUnit
+}
+
+// String formats the amount with two decimal places, e.g. "42.50".
+func (a Amount) String() string {
+	minor := int64(a)
+	sign := ""
+	if minor < 0 {
+		sign, minor = "-", -minor
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, minor/minorPerUnit, minor%minorPerUnit)
+}
+
+// Mul returns the amount multiplied by a quantity.
+func (a Amount) Mul(quantity int) Amount {
+	return a * Amount(quantity)
+}
+
+// Percent returns percent per cent of the amount, rounded to the nearest minor unit.
+func (a Amount) Percent(percent float64) Amount {
+	return Amount(math.Round(float64(a) * percent / 100))
+}
+
+// Min returns the smaller of two amounts.
+func Min(a, b Amount) Amount {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// MarshalJSON writes the amount as a JSON number with two decimal places.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON reads a JSON number or a string holding a decimal. The digits are read exactly.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	text := string(data)
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+	parsed, err := Parse(text)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// Value stores the amount as a decimal string, which Postgres reads into a NUMERIC column exactly.
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// Scan reads a NUMERIC column.
+func (a *Amount) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return a.scanText(string(v))
+	case string:
+		return a.scanText(v)
+	case int64:
+		*a = Amount(v * minorPerUnit)
+		return nil
+	case float64:
+		*a = FromFloat(v)
+		return nil
+	}
+	return fmt.Errorf("cannot scan %T into Amount", src)
+}
+
+func (a *Amount) scanText(text string) error {
+	parsed, err := Parse(text)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
@@ -0,0 +1,118 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		text     string
+		expected Amount
+	}{
+		{"42.50", 4250},
+		{"42.5", 4250},
+		{"39.99", 3999},
+		{"3", 300},
+		{"0.07", 7},
+		{"-2.10", -210},
+		{"1.500", 150},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.text, func(t *testing.T) {
+			amount, err := Parse(tc.text)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, amount)
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, text := range []string{"", "abc", "1.999", "1e3", ".5", "1.2.3", "-+1", "99999999999999999999"} {
+		t.Run(text, func(t *testing.T) {
+			_, err := Parse(text)
+			assert.ErrorIs(t, err, ErrInvalidAmount)
+		})
+	}
+}
+
+func TestAmount_String(t *testing.T) {
+	assert.Equal(t, "42.50", MustParse("42.5").String())
+	assert.Equal(t, "0.07", FromMinor(7).String())
+	assert.Equal(t, "-0.50", FromMinor(-50).String())
+}
+
+func TestAmount_AddsUpExactly(t *testing.T) {
+	var total Amount
+	for range 10 {
+		total += MustParse("0.10")
+	}
+	assert.Equal(t, MustParse("1.00"), total)
+	assert.Equal(t, MustParse("119.97"), MustParse("39.99").Mul(3))
+}
+
+func TestAmount_Percent(t *testing.T) {
+	assert.Equal(t, MustParse("10.40"), MustParse("51.98").Percent(20))
+	assert.Equal(t, MustParse("0.01"), MustParse("0.05").Percent(10), "rounds to the nearest penny")
+}
+
+func TestAmount_JSON(t *testing.T) {
+	var album struct {
+		Price Amount `json:"price"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"price": 42.50}`), &album))
+	assert.Equal(t, FromMinor(4250), album.Price)
+
+	encoded, err := json.Marshal(album)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"price": 42.50}`, string(encoded))
+	assert.Contains(t, string(encoded), "42.50")
+
+	require.NoError(t, json.Unmarshal([]byte(`{"price": "39.99"}`), &album))
+	assert.Equal(t, FromMinor(3999), album.Price)
+	assert.Error(t, json.Unmarshal([]byte(`{"price": 39.999}`), &album))
+}
+
+func TestAmount_Scan(t *testing.T) {
+	var a Amount
+	require.NoError(t, a.Scan([]byte("42.50")))
+	assert.Equal(t, FromMinor(4250), a)
+	require.NoError(t, a.Scan(9.99))
+	assert.Equal(t, FromMinor(999), a)
+	assert.Error(t, a.Scan(true))
+
+	value, err := MustParse("39.99").Value()
+	require.NoError(t, err)
+	assert.Equal(t, "39.99", value)
+}
+
+func TestParseCurrency(t *testing.T) {
+	currency, err := ParseCurrency(" usd ")
+	require.NoError(t, err)
+	assert.Equal(t, "USD", currency)
+
+	currency, err = ParseCurrency("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultCurrency, currency)
+
+	for _, code := range []string{"US", "EURO", "GB1"} {
+		_, err := ParseCurrency(code)
+		assert.Error(t, err, code)
+	}
+}
+
+func TestAmount_CQL(t *testing.T) {
+	for _, typ := range []gocql.Type{gocql.TypeDecimal, gocql.TypeDouble} {
+		info := gocql.NewNativeType(4, typ, "")
+		data, err := MustParse("42.50").MarshalCQL(info)
+		require.NoError(t, err)
+
+		var a Amount
+		require.NoError(t, a.UnmarshalCQL(info, data))
+		assert.Equal(t, MustParse("42.50"), a, typ.String())
+	}
+}
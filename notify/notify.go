@@ -0,0 +1,50 @@
+// Package notify delivers messages to customers so the rest of the shop never depends on a specific
+// delivery channel.
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoRecipient is returned when a message has no address to deliver it to.
+var ErrNoRecipient = errors.New("message has no recipient")
+
+// Message is a notification for one customer.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Notifier is implemented by each delivery channel.
+type Notifier interface {
+	Send(msg Message) error
+}
+
+// LogNotifier writes messages to a writer instead of delivering them, for local development.
+// Pointed at a file it keeps a record of everything that would have been sent.
+type LogNotifier struct {
+	mu  sync.Mutex
+	out io.Writer
+	now func() time.Time
+}
+
+func NewLogNotifier(out io.Writer) *LogNotifier {
+	return &LogNotifier{out: out, now: time.Now}
+}
+
+func (n *LogNotifier) Send(msg Message) error {
+	if strings.TrimSpace(msg.To) == "" {
+		return ErrNoRecipient
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	_, err := fmt.Fprintf(n.out, "--- %s\nTo: %s\nSubject: %s\n\n%s\n\n",
+		n.now().UTC().Format(time.RFC3339), msg.To, msg.Subject, msg.Body)
+	return err
+}
@@ -0,0 +1,55 @@
+package notify
+
+import (
+	"bytes"
+	"errors"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogNotifier_Send(t *testing.T) {
+	var out bytes.Buffer
+	n := NewLogNotifier(&out)
+	n.now = func() time.Time { return time.Date(2025, 6, 13, 9, 0, 0, 0, time.UTC) }
+
+	require.NoError(t, n.Send(Message{To: "ann@example.com", Subject: "Thriller is back in stock", Body: "Get it while you can."}))
+
+	assert.Equal(t, "--- 2025-06-13T09:00:00Z\nTo: ann@example.com\nSubject: Thriller is back in stock\n\nGet it while you can.\n\n", out.String())
+	assert.ErrorIs(t, n.Send(Message{Subject: "nobody"}), ErrNoRecipient)
+}
+
+func TestSMTPNotifier_Send(t *testing.T) {
+	n := NewSMTPNotifier("smtp.example.com:587", "shop@example.com", "shop", "secret")
+	var gotAddr, gotFrom string
+	var gotTo []string
+	var gotMsg []byte
+	var gotAuth smtp.Auth
+	n.send = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotAuth, gotFrom, gotTo, gotMsg = addr, a, from, to, msg
+		return nil
+	}
+
+	require.NoError(t, n.Send(Message{To: "ann@example.com", Subject: "Thriller is now £20.00", Body: "Line one\nLine two"}))
+
+	assert.Equal(t, "smtp.example.com:587", gotAddr)
+	assert.NotNil(t, gotAuth)
+	assert.Equal(t, "shop@example.com", gotFrom)
+	assert.Equal(t, []string{"ann@example.com"}, gotTo)
+	msg := string(gotMsg)
+	assert.Contains(t, msg, "To: ann@example.com\r\n")
+	assert.Contains(t, msg, "Subject: =?utf-8?q?")
+	assert.True(t, strings.HasSuffix(msg, "\r\n\r\nLine one\r\nLine two\r\n"), msg)
+}
+
+func TestSMTPNotifier_RejectsHeaderInjection(t *testing.T) {
+	n := NewSMTPNotifier("localhost:25", "shop@example.com", "", "")
+	n.send = func(string, smtp.Auth, string, []string, []byte) error { return errors.New("should not send") }
+
+	assert.Error(t, n.Send(Message{To: "ann@example.com\r\nBcc: everyone@example.com"}))
+	assert.ErrorIs(t, n.Send(Message{To: " "}), ErrNoRecipient)
+}
@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPNotifier sends messages as plain-text email through an SMTP server. Username and password
+// are optional; when set, PLAIN authentication is used, which net/smtp only allows over TLS or to
+// localhost.
+type SMTPNotifier struct {
+	Addr     string
+	From     string
+	Username string
+	Password string
+
+	// send is smtp.SendMail, replaced in tests.
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func NewSMTPNotifier(addr, from, username, password string) *SMTPNotifier {
+	return &SMTPNotifier{Addr: addr, From: from, Username: username, Password: password, send: smtp.SendMail}
+}
+
+func (n *SMTPNotifier) Send(msg Message) error {
+	to := strings.TrimSpace(msg.To)
+	if to == "" {
+		return ErrNoRecipient
+	}
+	if strings.ContainsAny(to, "\r\n") {
+		return fmt.Errorf("invalid recipient %q", to)
+	}
+	var auth smtp.Auth
+	if n.Username != "" {
+		host, _, err := net.SplitHostPort(n.Addr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", n.Username, n.Password, host)
+	}
+	return n.send(n.Addr, auth, n.From, []string{to}, n.format(to, msg))
+}
+
+// format builds the email, encoding the subject so it can carry any characters.
+func (n *SMTPNotifier) format(to string, msg Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", n.From)
+	fmt.Fprintf(&b, "To: %s\r\n", to)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
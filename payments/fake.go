@@ -0,0 +1,133 @@
+package payments
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// Test payment methods understood by FakeProvider. Any other non-empty method is authorised.
+const (
+	FakeMethodSuccess  = "tok_visa"
+	FakeMethodDeclined = "tok_declined"
+)
+
+// FakeProvider is an in-memory payment processor for development and tests. It never talks to the
+// network; webhooks it would send can be produced with SignedEvent and posted to the webhook endpoint.
+type FakeProvider struct {
+	secret []byte
+
+	mu       sync.Mutex
+	payments map[string]*fakePayment
+	byKey    map[string]string
+}
+
+type fakePayment struct {
+	authorized money.Amount
+	captured   money.Amount
+	refunded   money.Amount
+}
+
+func NewFakeProvider(webhookSecret string) *FakeProvider {
+	return &FakeProvider{
+		secret:   []byte(webhookSecret),
+		payments: make(map[string]*fakePayment),
+		byKey:    make(map[string]string),
+	}
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) Authorize(req AuthorizeRequest) (Authorization, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if reference, ok := p.byKey[req.IdempotencyKey]; ok && req.IdempotencyKey != "" {
+		return Authorization{Reference: reference, Amount: p.payments[reference].authorized}, nil
+	}
+	if req.PaymentMethod == "" || req.PaymentMethod == FakeMethodDeclined {
+		return Authorization{}, ErrDeclined
+	}
+	if req.Amount <= 0 {
+		return Authorization{}, ErrInvalidAmount
+	}
+	reference := "fake_" + randomHex(12)
+	p.payments[reference] = &fakePayment{authorized: req.Amount}
+	if req.IdempotencyKey != "" {
+		p.byKey[req.IdempotencyKey] = reference
+	}
+	return Authorization{Reference: reference, Amount: req.Amount}, nil
+}
+
+func (p *FakeProvider) Capture(reference string, amount money.Amount) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payment, ok := p.payments[reference]
+	if !ok {
+		return ErrUnknownPayment
+	}
+	if payment.captured > 0 {
+		return nil // already captured; capturing is idempotent
+	}
+	if amount <= 0 || amount > payment.authorized {
+		return ErrInvalidAmount
+	}
+	payment.captured = amount
+	return nil
+}
+
+func (p *FakeProvider) Refund(reference string, amount money.Amount) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payment, ok := p.payments[reference]
+	if !ok {
+		return "", ErrUnknownPayment
+	}
+	if amount <= 0 || payment.refunded+amount > payment.captured {
+		return "", fmt.Errorf("%w: cannot refund %s of %s captured (%s already refunded)", ErrInvalidAmount, amount, payment.captured, payment.refunded)
+	}
+	payment.refunded += amount
+	return "fake_re_" + randomHex(12), nil
+}
+
+func (p *FakeProvider) VerifyWebhook(payload []byte, signature string) (Event, error) {
+	if err := VerifySignature(p.secret, payload, signature, time.Now()); err != nil {
+		return Event{}, err
+	}
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return Event{}, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+	return event, nil
+}
+
+// SignedEvent encodes event as a webhook payload and signs it with the provider's secret,
+// returning the body and the signature header a real provider would send.
+func (p *FakeProvider) SignedEvent(event Event) ([]byte, string, error) {
+	if event.ID == "" {
+		event.ID = "evt_" + randomHex(12)
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now().UTC()
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, "", err
+	}
+	return payload, SignPayload(p.secret, payload, time.Now()), nil
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,74 @@
+package payments
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+func TestVerifySignature(t *testing.T) {
+	secret := []byte("secret")
+	payload := []byte(`{"id":"evt_1"}`)
+	now := time.Now()
+	header := SignPayload(secret, payload, now)
+
+	assert.NoError(t, VerifySignature(secret, payload, header, now))
+	assert.ErrorIs(t, VerifySignature([]byte("other"), payload, header, now), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySignature(secret, []byte(`{"id":"evt_2"}`), header, now), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySignature(secret, payload, header, now.Add(SignatureTolerance+time.Second)), ErrInvalidSignature, "stale signatures are replays")
+	assert.ErrorIs(t, VerifySignature(secret, payload, "v1=abc", now), ErrInvalidSignature)
+}
+
+func TestFakeProvider_AuthorizeIsIdempotent(t *testing.T) {
+	p := NewFakeProvider("secret")
+
+	first, err := p.Authorize(AuthorizeRequest{IdempotencyKey: "k", Amount: money.MustParse("10.00"), PaymentMethod: FakeMethodSuccess})
+	require.NoError(t, err)
+	second, err := p.Authorize(AuthorizeRequest{IdempotencyKey: "k", Amount: money.MustParse("10.00"), PaymentMethod: FakeMethodSuccess})
+	require.NoError(t, err)
+
+	assert.Equal(t, first.Reference, second.Reference)
+}
+
+func TestFakeProvider_Declined(t *testing.T) {
+	_, err := NewFakeProvider("secret").Authorize(AuthorizeRequest{Amount: money.MustParse("10.00"), PaymentMethod: FakeMethodDeclined})
+
+	assert.True(t, errors.Is(err, ErrDeclined))
+}
+
+func TestFakeProvider_CaptureAndRefund(t *testing.T) {
+	p := NewFakeProvider("secret")
+	auth, err := p.Authorize(AuthorizeRequest{Amount: money.MustParse("10.00"), PaymentMethod: FakeMethodSuccess})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, p.Capture(auth.Reference, money.MustParse("11.00")), ErrInvalidAmount)
+	require.NoError(t, p.Capture(auth.Reference, money.MustParse("10.00")))
+	_, err = p.Refund(auth.Reference, money.MustParse("7.50"))
+	require.NoError(t, err)
+	_, err = p.Refund(auth.Reference, money.MustParse("2.51"))
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+	_, err = p.Refund(auth.Reference, money.MustParse("2.50"))
+	assert.NoError(t, err)
+	_, err = p.Refund("fake_missing", money.MustParse("1.00"))
+	assert.ErrorIs(t, err, ErrUnknownPayment)
+}
+
+func TestFakeProvider_VerifyWebhook(t *testing.T) {
+	p := NewFakeProvider("secret")
+	payload, signature, err := p.SignedEvent(Event{Type: EventCaptured, Reference: "fake_1", Amount: money.MustParse("5.00")})
+	require.NoError(t, err)
+
+	event, err := p.VerifyWebhook(payload, signature)
+
+	require.NoError(t, err)
+	assert.Equal(t, EventCaptured, event.Type)
+	assert.Equal(t, "fake_1", event.Reference)
+	assert.NotEmpty(t, event.ID)
+
+	_, err = NewFakeProvider("other").VerifyWebhook(payload, signature)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
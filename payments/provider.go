@@ -0,0 +1,62 @@
+// Package payments abstracts the payment processor so handlers never depend on a specific provider.
+package payments
+
+import (
+	"errors"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+var (
+	// ErrDeclined is returned when the provider refuses to authorise a payment.
+	ErrDeclined = errors.New("payment declined")
+	// ErrInvalidSignature is returned when a webhook's signature does not match its payload.
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrUnknownPayment is returned when the provider has no payment with the given reference.
+	ErrUnknownPayment = errors.New("unknown payment")
+	// ErrInvalidAmount is returned when capturing or refunding more than the provider holds.
+	ErrInvalidAmount = errors.New("invalid amount")
+)
+
+// AuthorizeRequest asks the provider to hold funds. Providers treat IdempotencyKey as unique:
+// repeating a request with the same key returns the original authorisation instead of charging twice.
+type AuthorizeRequest struct {
+	IdempotencyKey string
+	Amount         money.Amount
+	PaymentMethod  string
+	Description    string
+}
+
+// Authorization is the provider's record of held funds.
+type Authorization struct {
+	Reference string
+	Amount    money.Amount
+}
+
+// Event types delivered by provider webhooks.
+const (
+	EventCaptured = "payment.captured"
+	EventFailed   = "payment.failed"
+	EventRefunded = "payment.refunded"
+)
+
+// Event is a verified webhook notification. For refund events Amount is the total refunded so far,
+// so replaying an event is harmless.
+type Event struct {
+	ID        string       `json:"id"`
+	Type      string       `json:"type"`
+	Reference string       `json:"reference"`
+	Amount    money.Amount `json:"amount"`
+	Reason    string       `json:"reason,omitempty"`
+	CreatedAt time.Time    `json:"createdAt"`
+}
+
+// Provider is implemented by each payment processor.
+type Provider interface {
+	Name() string
+	Authorize(req AuthorizeRequest) (Authorization, error)
+	Capture(reference string, amount money.Amount) error
+	Refund(reference string, amount money.Amount) (string, error)
+	VerifyWebhook(payload []byte, signature string) (Event, error)
+}
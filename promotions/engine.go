@@ -0,0 +1,93 @@
+// Package promotions works out what customers pay once discount rules are applied. It is pure
+// pricing logic: callers load the promotions and albums, and record redemptions themselves.
+package promotions
+
+import (
+	"math"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// Line is a quantity of one album to be priced.
+type Line struct {
+	Album    repository.Album
+	Quantity int
+}
+
+// Price is the outcome of pricing a line. Promotion is nil when no promotion applies.
+type Price struct {
+	UnitPrice money.Amount
+	Subtotal  money.Amount
+	Discount  money.Amount
+	Total     money.Amount
+	Promotion *repository.Promotion
+}
+
+// Discount returns how much the promotion takes off quantity units at unitPrice,
+// ignoring whether the promotion is in scope or currently available.
+func Discount(promotion repository.Promotion, unitPrice money.Amount, quantity int) money.Amount {
+	if quantity <= 0 || unitPrice <= 0 {
+		return 0
+	}
+	switch promotion.Kind {
+	case repository.DiscountPercentage:
+		return unitPrice.Mul(quantity).Percent(math.Min(promotion.Value.Float64(), 100))
+	case repository.DiscountFixed:
+		return money.Min(promotion.Value, unitPrice).Mul(quantity)
+	case repository.DiscountBOGO:
+		return unitPrice.Mul(quantity / 2)
+	}
+	return 0
+}
+
+// applies reports whether the promotion can be used on the album at the given time. Coupon
+// promotions only apply when their code has been entered.
+func applies(promotion repository.Promotion, genres *repository.GenreTree, code string, album repository.Album, now time.Time) bool {
+	if promotion.Code != "" && promotion.Code != repository.NormalizeCode(code) {
+		return false
+	}
+	return promotion.AvailableAt(now) && promotion.Scope.Matches(album, genres)
+}
+
+// PriceLine applies whichever promotion gives the line the biggest discount. Promotions do not stack.
+// Promotions for a genre also apply to its sub-genres in genres, which may be nil.
+func PriceLine(promotions []repository.Promotion, genres *repository.GenreTree, code string, line Line, now time.Time) Price {
+	price := Price{
+		UnitPrice: line.Album.Price,
+		Subtotal:  line.Album.Price.Mul(line.Quantity),
+	}
+	for i := range promotions {
+		if !applies(promotions[i], genres, code, line.Album, now) {
+			continue
+		}
+		if discount := Discount(promotions[i], line.Album.Price, line.Quantity); discount > price.Discount {
+			price.Discount = discount
+			price.Promotion = &promotions[i]
+		}
+	}
+	price.Total = price.Subtotal - price.Discount
+	return price
+}
+
+// SalePrice returns the album's price per unit after the best automatic promotion, for showing in
+// the catalogue. Buy-one-get-one-free offers do not change the unit price, so they are reported
+// with the list price.
+func SalePrice(promotions []repository.Promotion, genres *repository.GenreTree, album repository.Album, now time.Time) (money.Amount, *repository.Promotion) {
+	var automatic []repository.Promotion
+	for _, promotion := range promotions {
+		if promotion.Code == "" {
+			automatic = append(automatic, promotion)
+		}
+	}
+	price := PriceLine(automatic, genres, "", Line{Album: album, Quantity: 1}, now)
+	if price.Promotion == nil {
+		for i := range automatic {
+			if automatic[i].Kind == repository.DiscountBOGO && applies(automatic[i], genres, "", album, now) {
+				return album.Price, &automatic[i]
+			}
+		}
+	}
+	return price.Total, price.Promotion
+}
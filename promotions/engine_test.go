@@ -0,0 +1,101 @@
+package promotions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+var (
+	now        = time.Date(2025, 6, 14, 12, 0, 0, 0, time.UTC)
+	whatsGoing = repository.Album{ID: "1", Title: "What's Going On", Artist: "Marvin Gaye", Price: money.MustParse("40.00"), Year: 1971, Genre: "Motown"}
+	thriller   = repository.Album{ID: "2", Title: "Thriller", Artist: "Michael Jackson", Price: money.MustParse("25.99"), Year: 1982, Genre: "Pop"}
+)
+
+func TestDiscount(t *testing.T) {
+	testCases := []struct {
+		name      string
+		promotion repository.Promotion
+		quantity  int
+		expected  money.Amount
+	}{
+		{"percentage", repository.Promotion{Kind: repository.DiscountPercentage, Value: money.MustParse("20")}, 2, money.MustParse("10.40")},
+		{"fixed per unit", repository.Promotion{Kind: repository.DiscountFixed, Value: money.MustParse("5")}, 3, money.MustParse("15.00")},
+		{"fixed capped at price", repository.Promotion{Kind: repository.DiscountFixed, Value: money.MustParse("100")}, 1, money.MustParse("25.99")},
+		{"bogo one unit", repository.Promotion{Kind: repository.DiscountBOGO}, 1, 0},
+		{"bogo three units", repository.Promotion{Kind: repository.DiscountBOGO}, 3, money.MustParse("25.99")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Discount(tc.promotion, thriller.Price, tc.quantity))
+		})
+	}
+}
+
+func TestPriceLine_BestPromotionWins(t *testing.T) {
+	promotions := []repository.Promotion{
+		{ID: "a", Kind: repository.DiscountPercentage, Value: money.MustParse("10"), Active: true},
+		{ID: "b", Kind: repository.DiscountPercentage, Value: money.MustParse("20"), Active: true, Scope: repository.PromotionScope{Genres: []string{"motown"}}},
+		{ID: "c", Kind: repository.DiscountPercentage, Value: money.MustParse("50"), Active: true, Code: "HALF"},
+	}
+
+	price := PriceLine(promotions, nil, "", Line{Album: whatsGoing, Quantity: 1}, now)
+	require.NotNil(t, price.Promotion)
+	assert.Equal(t, "b", price.Promotion.ID)
+	assert.Equal(t, money.MustParse("32.00"), price.Total)
+
+	price = PriceLine(promotions, nil, "half", Line{Album: whatsGoing, Quantity: 1}, now)
+	assert.Equal(t, "c", price.Promotion.ID, "an entered coupon competes with automatic promotions")
+
+	price = PriceLine(promotions, nil, "", Line{Album: thriller, Quantity: 1}, now)
+	assert.Equal(t, "a", price.Promotion.ID, "out-of-scope promotions are skipped")
+}
+
+func TestPriceLine_RespectsWindowAndLimits(t *testing.T) {
+	later := now.Add(time.Hour)
+	promotions := []repository.Promotion{
+		{ID: "future", Kind: repository.DiscountFixed, Value: money.MustParse("5"), Active: true, StartsAt: &later},
+		{ID: "ended", Kind: repository.DiscountFixed, Value: money.MustParse("5"), Active: true, EndsAt: &now},
+		{ID: "used", Kind: repository.DiscountFixed, Value: money.MustParse("5"), Active: true, UsageLimit: 10, UsageCount: 10},
+		{ID: "off", Kind: repository.DiscountFixed, Value: money.MustParse("5")},
+	}
+
+	price := PriceLine(promotions, nil, "", Line{Album: thriller, Quantity: 1}, now)
+
+	assert.Nil(t, price.Promotion)
+	assert.Equal(t, thriller.Price, price.Total)
+}
+
+func TestSalePrice(t *testing.T) {
+	promotions := []repository.Promotion{
+		{Name: "Motown weekend", Kind: repository.DiscountPercentage, Value: money.MustParse("20"), Active: true, Scope: repository.PromotionScope{Genres: []string{"Motown"}}},
+		{Name: "Coupon", Kind: repository.DiscountPercentage, Value: money.MustParse("90"), Active: true, Code: "SECRET"},
+		{Name: "Pop BOGO", Kind: repository.DiscountBOGO, Active: true, Scope: repository.PromotionScope{Genres: []string{"Pop"}}},
+	}
+
+	price, promotion := SalePrice(promotions, nil, whatsGoing, now)
+	assert.Equal(t, money.MustParse("32.00"), price)
+	assert.Equal(t, "Motown weekend", promotion.Name)
+
+	price, promotion = SalePrice(promotions, nil, thriller, now)
+	assert.Equal(t, thriller.Price, price)
+	assert.Equal(t, "Pop BOGO", promotion.Name)
+}
+
+func TestSalePrice_SubGenres(t *testing.T) {
+	promotions := []repository.Promotion{
+		{Name: "Soul sale", Kind: repository.DiscountPercentage, Value: money.MustParse("10"), Active: true, Scope: repository.PromotionScope{Genres: []string{"Soul"}}},
+	}
+	genres := repository.NewGenreTree([]repository.Genre{{ID: "soul", Name: "Soul"}, {ID: "motown", Name: "Motown", ParentID: "soul"}})
+
+	price, promotion := SalePrice(promotions, genres, whatsGoing, now)
+	assert.Equal(t, money.MustParse("36.00"), price)
+	require.NotNil(t, promotion, "Motown is filed under Soul")
+
+	_, promotion = SalePrice(promotions, nil, whatsGoing, now)
+	assert.Nil(t, promotion)
+}
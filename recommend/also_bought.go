@@ -0,0 +1,105 @@
+package recommend
+
+import (
+	"fmt"
+
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// BasketSource supplies the albums each customer has shown an interest in, keyed by customer.
+type BasketSource interface {
+	Baskets() (map[string][]string, error)
+}
+
+// AlsoBought recommends the albums most often found alongside the album in customers' baskets, from
+// every source combined. An album only counts once per customer however many sources it appears in.
+type AlsoBought struct {
+	Sources []BasketSource
+}
+
+func (e AlsoBought) Recommend(albums []repository.Album) (map[string][]Recommendation, error) {
+	inCatalogue := make(map[string]bool, len(albums))
+	for _, album := range albums {
+		inCatalogue[album.ID] = true
+	}
+	customers := make(map[string]map[string]bool)
+	for _, source := range e.Sources {
+		baskets, err := source.Baskets()
+		if err != nil {
+			return nil, err
+		}
+		for customer, albumIDs := range baskets {
+			if customers[customer] == nil {
+				customers[customer] = make(map[string]bool)
+			}
+			for _, albumID := range albumIDs {
+				if inCatalogue[albumID] {
+					customers[customer][albumID] = true
+				}
+			}
+		}
+	}
+
+	together := make(map[string]map[string]int)
+	for _, basket := range customers {
+		for a := range basket {
+			for b := range basket {
+				if a == b {
+					continue
+				}
+				if together[a] == nil {
+					together[a] = make(map[string]int)
+				}
+				together[a][b]++
+			}
+		}
+	}
+	results := make(map[string][]Recommendation, len(together))
+	for albumID, others := range together {
+		for other, count := range others {
+			reason := "1 customer also bought or saved this"
+			if count > 1 {
+				reason = fmt.Sprintf("%d customers also bought or saved this", count)
+			}
+			results[albumID] = append(results[albumID], Recommendation{AlbumID: other, Score: float64(count), Reasons: []string{reason}})
+		}
+	}
+	return results, nil
+}
+
+// OrderBaskets is a BasketSource of the albums each customer has paid for. Pending and cancelled
+// orders are left out.
+type OrderBaskets struct {
+	Orders repository.OrderRepository
+}
+
+func (s OrderBaskets) Baskets() (map[string][]string, error) {
+	orders, err := s.Orders.List("")
+	if err != nil {
+		return nil, err
+	}
+	baskets := make(map[string][]string)
+	for _, order := range orders {
+		if order.Status == repository.OrderPending || order.Status == repository.OrderCancelled {
+			continue
+		}
+		// Guests' orders are only linked to each other by the order itself.
+		customer := order.UserID
+		if customer == "" {
+			customer = "order:" + order.ID
+		}
+		for _, line := range order.Lines {
+			baskets[customer] = append(baskets[customer], line.AlbumID)
+		}
+	}
+	return baskets, nil
+}
+
+// WishlistBaskets is a BasketSource of the albums each customer has saved to their wishlist.
+type WishlistBaskets struct {
+	Wishlists repository.WishlistRepository
+}
+
+func (s WishlistBaskets) Baskets() (map[string][]string, error) {
+	return s.Wishlists.AlbumsByUser()
+}
@@ -0,0 +1,97 @@
+package recommend
+
+import (
+	"strings"
+
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// Weights of each detail two albums can share. Artist counts for most: someone looking at one
+// Stevie Wonder record is most likely to want another.
+const (
+	sameArtistWeight = 3
+	sameGenreWeight  = 2
+	sameDecadeWeight = 1
+	priceBandWeight  = 1
+)
+
+// Content recommends albums by the same artist or in the same genre, ranked higher if they are also
+// from the same decade or sell at a similar price. Sharing only a decade or a price says little about
+// whether someone would like an album, so that alone is not enough. It needs nothing but the catalogue.
+type Content struct{}
+
+func (Content) Recommend(albums []repository.Album) (map[string][]Recommendation, error) {
+	results := make(map[string][]Recommendation)
+	for _, album := range albums {
+		for _, other := range albums {
+			if other.ID == album.ID || !(sameArtist(album, other) || sameGenre(album, other)) {
+				continue
+			}
+			score, reasons := similarity(album, other)
+			results[album.ID] = append(results[album.ID], Recommendation{AlbumID: other.ID, Score: score, Reasons: reasons})
+		}
+	}
+	return results, nil
+}
+
+// similarity scores how alike two albums are and says which details they share.
+func similarity(a, b repository.Album) (float64, []string) {
+	var score float64
+	var reasons []string
+	if sameArtist(a, b) {
+		score += sameArtistWeight
+		reasons = append(reasons, "same artist")
+	}
+	if sameGenre(a, b) {
+		score += sameGenreWeight
+		reasons = append(reasons, "same genre")
+	}
+	if a.Year > 0 && a.Year/10 == b.Year/10 {
+		score += sameDecadeWeight
+		reasons = append(reasons, "same decade")
+	}
+	if samePriceBand(a, b) {
+		score += priceBandWeight
+		reasons = append(reasons, "similar price")
+	}
+	return score, reasons
+}
+
+// sameArtist compares the albums' artists by ID where both are linked to one, and by name otherwise.
+func sameArtist(a, b repository.Album) bool {
+	if a.ArtistID != "" && b.ArtistID != "" {
+		return a.ArtistID == b.ArtistID
+	}
+	return sameText(a.Artist, b.Artist)
+}
+
+func sameGenre(a, b repository.Album) bool {
+	return sameText(a.Genre, b.Genre)
+}
+
+// sameText compares catalogue details ignoring case and surrounding spaces; blanks never match.
+func sameText(a, b string) bool {
+	a = strings.TrimSpace(a)
+	return a != "" && strings.EqualFold(a, strings.TrimSpace(b))
+}
+
+// samePriceBand reports whether two albums in the same currency cost within a quarter of the dearer
+// one's price of each other.
+func samePriceBand(a, b repository.Album) bool {
+	if a.Price <= 0 || b.Price <= 0 || currency(a) != currency(b) {
+		return false
+	}
+	diff, dearer := a.Price-b.Price, a.Price
+	if diff < 0 {
+		diff, dearer = -diff, b.Price
+	}
+	return diff*4 <= dearer
+}
+
+func currency(album repository.Album) string {
+	if album.Currency == "" {
+		return money.DefaultCurrency
+	}
+	return album.Currency
+}
@@ -0,0 +1,109 @@
+// Package recommend suggests albums related to the one a customer is looking at. Engines score the
+// whole catalogue at once, so a Recommender can precompute every album's recommendations in the
+// background and serve them from memory, whichever repository backend holds the albums.
+package recommend
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// DefaultLimit is how many recommendations of each kind are kept per album when no limit is set.
+const DefaultLimit = 10
+
+// Recommendation is an album suggested alongside another, with a score that only means something
+// relative to other recommendations from the same engine, and why it was suggested.
+type Recommendation struct {
+	AlbumID string   `json:"albumId"`
+	Score   float64  `json:"score"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// Engine works out recommendations for every album in the catalogue, keyed by album ID. Albums
+// with nothing to recommend can be left out.
+type Engine interface {
+	Recommend(albums []repository.Album) (map[string][]Recommendation, error)
+}
+
+// Recommendations are the precomputed suggestions for one album: Similar from the album's own
+// details, and AlsoBought from what other customers bought or saved alongside it.
+type Recommendations struct {
+	Similar    []Recommendation `json:"similar"`
+	AlsoBought []Recommendation `json:"alsoBought"`
+}
+
+// Recommender precomputes recommendations for the whole catalogue with its engines. Similar is
+// required; AlsoBought is optional. Refresh is safe to call while For is being served.
+type Recommender struct {
+	Albums     repository.AlbumRepository
+	Similar    Engine
+	AlsoBought Engine
+	Limit      int
+
+	mu          sync.RWMutex
+	results     map[string]Recommendations
+	generatedAt time.Time
+}
+
+func NewRecommender(albums repository.AlbumRepository, similar Engine) *Recommender {
+	return &Recommender{Albums: albums, Similar: similar, Limit: DefaultLimit}
+}
+
+// Refresh recomputes recommendations for every album and replaces the previous results.
+func (r *Recommender) Refresh() error {
+	albums, err := r.Albums.GetAll()
+	if err != nil {
+		return err
+	}
+	similar, err := r.Similar.Recommend(albums)
+	if err != nil {
+		return err
+	}
+	var alsoBought map[string][]Recommendation
+	if r.AlsoBought != nil {
+		if alsoBought, err = r.AlsoBought.Recommend(albums); err != nil {
+			return err
+		}
+	}
+
+	results := make(map[string]Recommendations, len(albums))
+	for _, album := range albums {
+		results[album.ID] = Recommendations{
+			Similar:    top(similar[album.ID], r.Limit),
+			AlsoBought: top(alsoBought[album.ID], r.Limit),
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results = results
+	r.generatedAt = time.Now()
+	return nil
+}
+
+// For returns the recommendations for an album and when they were worked out. ok is false if the
+// album was not in the catalogue at the last refresh.
+func (r *Recommender) For(albumID string) (recommendations Recommendations, generatedAt time.Time, ok bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	recommendations, ok = r.results[albumID]
+	return recommendations, r.generatedAt, ok
+}
+
+// top returns the limit highest-scoring recommendations, breaking ties by album ID so results are
+// stable between refreshes. It never returns nil.
+func top(recommendations []Recommendation, limit int) []Recommendation {
+	sorted := append([]Recommendation{}, recommendations...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Score != sorted[j].Score {
+			return sorted[i].Score > sorted[j].Score
+		}
+		return sorted[i].AlbumID < sorted[j].AlbumID
+	})
+	if limit > 0 && len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+	return sorted
+}
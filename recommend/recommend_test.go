@@ -0,0 +1,146 @@
+package recommend
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+var catalogue = []repository.Album{
+	{ID: "1", Title: "Songs in the Key of Life", Artist: "Stevie Wonder", Genre: "Motown", Year: 1976, Price: money.MustParse("42.50")},
+	{ID: "2", Title: "Innervisions", Artist: "Stevie Wonder", Genre: "Soul", Year: 1973, Price: money.MustParse("34.99")},
+	{ID: "3", Title: "What's Going On", Artist: "Marvin Gaye", Genre: "Motown", Year: 1971, Price: money.MustParse("39.99")},
+	{ID: "4", Title: "Thriller", Artist: "Michael Jackson", Genre: "Pop", Year: 1982, Price: money.MustParse("25.99")},
+	{ID: "5", Title: "Rumours", Artist: "Fleetwood Mac", Genre: "Rock", Year: 1977, Price: money.MustParse("41.00")},
+}
+
+type fakeAlbums struct {
+	repository.AlbumRepository
+	albums []repository.Album
+	err    error
+}
+
+func (f *fakeAlbums) GetAll() ([]repository.Album, error) {
+	return f.albums, f.err
+}
+
+type fakeBaskets map[string][]string
+
+func (f fakeBaskets) Baskets() (map[string][]string, error) {
+	return f, nil
+}
+
+func ids(recommendations []Recommendation) []string {
+	var ids []string
+	for _, r := range recommendations {
+		ids = append(ids, r.AlbumID)
+	}
+	return ids
+}
+
+// TestContent_Recommend tests that albums are ranked by the details they share, and albums sharing neither artist nor genre are left out
+func TestContent_Recommend(t *testing.T) {
+	results, err := Content{}.Recommend(catalogue)
+	require.NoError(t, err)
+
+	similar := top(results["1"], 0)
+	assert.Equal(t, []string{"2", "3"}, ids(similar))
+	assert.Equal(t, []string{"same artist", "same decade", "similar price"}, similar[0].Reasons)
+	assert.Equal(t, []string{"same genre", "same decade", "similar price"}, similar[1].Reasons)
+	assert.Empty(t, results["5"], "Rumours only shares a decade and price with Songs in the Key of Life")
+}
+
+// TestSameArtist tests that linked albums are matched by artist ID rather than by name
+func TestSameArtist(t *testing.T) {
+	assert.True(t, sameArtist(repository.Album{Artist: "Stevie Wonder"}, repository.Album{Artist: " stevie wonder"}))
+	assert.True(t, sameArtist(
+		repository.Album{Artist: "Stevie Wonder", ArtistID: "a1"},
+		repository.Album{Artist: "Little Stevie Wonder", ArtistID: "a1"},
+	))
+	assert.False(t, sameArtist(
+		repository.Album{Artist: "Prince", ArtistID: "a1"},
+		repository.Album{Artist: "Prince", ArtistID: "a2"},
+	))
+}
+
+// TestSamePriceBand tests that prices within a quarter of each other in the same currency are similar
+func TestSamePriceBand(t *testing.T) {
+	a := repository.Album{Price: money.MustParse("40.00")}
+	assert.True(t, samePriceBand(a, repository.Album{Price: money.MustParse("30.00")}))
+	assert.False(t, samePriceBand(a, repository.Album{Price: money.MustParse("29.99")}))
+	assert.False(t, samePriceBand(a, repository.Album{Price: money.MustParse("40.00"), Currency: "USD"}))
+	assert.True(t, samePriceBand(a, repository.Album{Price: money.MustParse("40.00"), Currency: "GBP"}))
+}
+
+// TestAlsoBought_Recommend tests that each customer counts once however many sources mention an album
+func TestAlsoBought_Recommend(t *testing.T) {
+	engine := AlsoBought{Sources: []BasketSource{
+		fakeBaskets{"ann": {"1", "4"}, "bob": {"1", "4", "5"}, "cat": {"1", "999"}},
+		fakeBaskets{"ann": {"4"}, "dan": {"1", "5"}},
+	}}
+
+	results, err := engine.Recommend(catalogue)
+
+	require.NoError(t, err)
+	alsoBought := top(results["1"], 0)
+	assert.Equal(t, []string{"4", "5"}, ids(alsoBought), "albums no longer in the catalogue are left out")
+	assert.Equal(t, 2.0, alsoBought[0].Score)
+	assert.Equal(t, []string{"2 customers also bought or saved this"}, alsoBought[0].Reasons)
+}
+
+// TestOrderBaskets tests that only paid orders count, and guests' orders are kept apart
+func TestOrderBaskets(t *testing.T) {
+	orders := &fakeOrders{orders: []repository.Order{
+		{ID: "o1", UserID: "ann", Status: repository.OrderDelivered, Lines: []repository.OrderLine{{AlbumID: "1"}}},
+		{ID: "o2", UserID: "ann", Status: repository.OrderPaid, Lines: []repository.OrderLine{{AlbumID: "2"}}},
+		{ID: "o3", UserID: "ann", Status: repository.OrderCancelled, Lines: []repository.OrderLine{{AlbumID: "3"}}},
+		{ID: "o4", Status: repository.OrderShipped, Lines: []repository.OrderLine{{AlbumID: "4"}}},
+		{ID: "o5", UserID: "bob", Status: repository.OrderPending, Lines: []repository.OrderLine{{AlbumID: "5"}}},
+	}}
+
+	baskets, err := OrderBaskets{Orders: orders}.Baskets()
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"ann": {"1", "2"}, "order:o4": {"4"}}, baskets)
+}
+
+type fakeOrders struct {
+	repository.OrderRepository
+	orders []repository.Order
+}
+
+func (f *fakeOrders) List(status repository.OrderStatus) ([]repository.Order, error) {
+	return f.orders, nil
+}
+
+// TestRecommender_Refresh tests that results are trimmed to the limit and kept for every album in the catalogue
+func TestRecommender_Refresh(t *testing.T) {
+	albums := &fakeAlbums{albums: catalogue}
+	recommender := NewRecommender(albums, Content{})
+	recommender.AlsoBought = AlsoBought{Sources: []BasketSource{fakeBaskets{"ann": {"1", "4"}}}}
+	recommender.Limit = 1
+
+	_, _, ok := recommender.For("1")
+	assert.False(t, ok, "nothing is known before the first refresh")
+
+	require.NoError(t, recommender.Refresh())
+	recommendations, generatedAt, ok := recommender.For("1")
+	require.True(t, ok)
+	assert.False(t, generatedAt.IsZero())
+	assert.Equal(t, []string{"2"}, ids(recommendations.Similar))
+	assert.Equal(t, []string{"4"}, ids(recommendations.AlsoBought))
+
+	recommendations, _, ok = recommender.For("5")
+	require.True(t, ok)
+	assert.NotNil(t, recommendations.Similar)
+	assert.Empty(t, recommendations.AlsoBought)
+
+	albums.err = errors.New("database down")
+	assert.Error(t, recommender.Refresh())
+	_, _, ok = recommender.For("1")
+	assert.True(t, ok, "a failed refresh keeps the previous results")
+}
@@ -1,19 +1,267 @@
 package repository
 
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+var (
+	// ErrAlbumNotFound is returned when no album has the barcode or catalogue number looked up.
+	ErrAlbumNotFound = errors.New("album not found")
+	// ErrDuplicateBarcode is returned when another album or release already has an album's or
+	// release's barcode. Albums and releases share one namespace of barcodes.
+	ErrDuplicateBarcode = errors.New("another album or release already has that barcode")
+	// ErrDuplicateCatalogueNumber is returned when another album on the same label already has an
+	// album's catalogue number.
+	ErrDuplicateCatalogueNumber = errors.New("another album on that label already has that catalogue number")
+)
+
 type Album struct {
-	ID       string  `db:"id" json:"id"`
-	Title    string  `db:"title" json:"title"`
-	Artist   string  `db:"artist" json:"artist"`
-	Price    float64 `db:"price" json:"price"`
-	Year     int     `db:"year" json:"year"`
-	ImageUrl string  `db:"image_url" json:"imageUrl"`
-	Genre    string  `db:"genre" json:"genre"`
+	ID       string       `db:"id" json:"id"`
+	Title    string       `db:"title" json:"title"`
+	Artist   string       `db:"artist" json:"artist"`
+	Price    money.Amount `db:"price" json:"price"`
+	Currency string       `db:"currency" json:"currency"`
+	Year     int          `db:"year" json:"year"`
+	ImageUrl string       `db:"image_url" json:"imageUrl"`
+	Genre    string       `db:"genre" json:"genre"`
+
+	// ArtistID links the album to its artist. Artist still holds the artist's name, so albums can be
+	// shown without looking the artist up.
+	ArtistID string `db:"artist_id" json:"artistId,omitempty"`
+
+	// LabelID links the album to the label it was released on, and Label holds the label's name.
+	// CatalogueNumber is the label's number for the release, unique within the label, and Barcode
+	// is its EAN-13, unique across the catalogue.
+	LabelID         string `db:"label_id" json:"labelId,omitempty"`
+	Label           string `db:"label" json:"label,omitempty"`
+	CatalogueNumber string `db:"catalogue_number" json:"catalogueNumber,omitempty"`
+	Barcode         string `db:"barcode" json:"barcode,omitempty"`
+
+	// CurrencyPrices are prices set by hand for other currencies. Currencies without one are
+	// converted from Price using the exchange rates.
+	CurrencyPrices CurrencyPrices `db:"currency_prices" json:"currencyPrices,omitempty"`
+
+	// TaxCategory picks the tax rate charged on the album where a category has its own rate.
+	// Albums without one are in the standard category.
+	TaxCategory string `db:"tax_category" json:"taxCategory,omitempty"`
+
+	// Tags are free-form labels staff give albums, such as "staff picks", stored as NormalizeTags leaves them.
+	Tags Tags `db:"tags" json:"tags,omitempty"`
+
+	// DeletedAt and DeletedBy are set while the album is in the trash: when it was deleted and by whom.
+	// Albums in the trash are left out of the catalogue until they are restored or purged.
+	DeletedAt *time.Time `db:"deleted_at" json:"deletedAt,omitempty"`
+	DeletedBy string     `db:"deleted_by" json:"deletedBy,omitempty"`
+
+	// ReleaseID, Format and Edition are filled in by the API layer when albums are listed one entry
+	// per release; Releases is filled in with the album's releases when they are listed grouped.
+	ReleaseID string    `db:"-" json:"releaseId,omitempty"`
+	Format    Format    `db:"-" json:"format,omitempty"`
+	Edition   string    `db:"-" json:"edition,omitempty"`
+	Releases  []Release `db:"-" json:"releases,omitempty"`
+
+	// Stock and InStock are filled in by the API layer from the inventory; they are not stored with the album.
+	Stock   []StockLevel `db:"-" json:"stock,omitempty"`
+	InStock *bool        `db:"-" json:"inStock,omitempty"`
+
+	// SalePrice and Promotion are filled in by the API layer when an automatic promotion applies.
+	SalePrice *money.Amount `db:"-" json:"salePrice,omitempty"`
+	Promotion string        `db:"-" json:"promotion,omitempty"`
+
+	// Tax and PriceIncludesTax are filled in by the API layer when prices are shown for a
+	// destination: the tax on the price, and whether the price shown already includes it.
+	Tax              *money.Amount `db:"-" json:"tax,omitempty"`
+	PriceIncludesTax *bool         `db:"-" json:"priceIncludesTax,omitempty"`
+
+	// Rating and ReviewCount are filled in by the API layer from the album's approved reviews.
+	// Rating is left out for albums nobody has reviewed yet.
+	Rating      *float64 `db:"-" json:"rating,omitempty"`
+	ReviewCount *int     `db:"-" json:"reviewCount,omitempty"`
+
+	// UsedCount is filled in by the API layer with the number of used copies of the album for sale.
+	UsedCount *int `db:"-" json:"usedCount,omitempty"`
+}
+
+// CurrencyPrices maps ISO 4217 currency codes to an album's price in that currency.
+type CurrencyPrices map[string]money.Amount
+
+// Value stores the prices as a JSON object.
+func (p CurrencyPrices) Value() (driver.Value, error) {
+	if p == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(p)
+	return string(b), err
+}
+
+// Scan reads prices stored as a JSON object.
+func (p *CurrencyPrices) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, p)
+	case string:
+		return json.Unmarshal([]byte(v), p)
+	case nil:
+		*p = nil
+		return nil
+	}
+	return fmt.Errorf("cannot scan %T into CurrencyPrices", src)
+}
+
+// currencyOrDefault returns the currency to store for an album, filling in the default for
+// albums created without one.
+func currencyOrDefault(currency string) string {
+	if currency == "" {
+		return money.DefaultCurrency
+	}
+	return currency
+}
+
+// MaxTags is the most tags an album can have, and MaxTagLength the longest tag in characters.
+const (
+	MaxTags      = 20
+	MaxTagLength = 40
+)
+
+// Tags are an album's tags, stored as a JSON array.
+type Tags []string
+
+// Value stores the tags as a JSON array.
+func (t Tags) Value() (driver.Value, error) {
+	return Aliases(t).Value()
+}
+
+// Scan reads tags stored as a JSON array.
+func (t *Tags) Scan(src interface{}) error {
+	return (*Aliases)(t).Scan(src)
+}
+
+// NormalizeTag puts a tag into the form it is stored and looked up in: lower case, with runs of
+// whitespace collapsed to a single space, so "Staff  Picks" is "staff picks".
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.Join(strings.Fields(tag), " "))
+}
+
+// NormalizeTags normalizes each tag, dropping blanks and repeats, and checks there are not too many
+// and none is too long.
+func NormalizeTags(tags []string) (Tags, error) {
+	normalized := Tags{}
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > MaxTagLength {
+			return nil, fmt.Errorf("tag %q is longer than %d characters", tag, MaxTagLength)
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > MaxTags {
+		return nil, fmt.Errorf("an album can have at most %d tags", MaxTags)
+	}
+	return normalized, nil
+}
+
+// TagCount is how many albums have a tag.
+type TagCount struct {
+	Tag   string `db:"tag" json:"tag"`
+	Count int    `db:"count" json:"count"`
+}
+
+// sortTagCounts orders tags from the most used down, then alphabetically.
+func sortTagCounts(counts []TagCount) {
+	sort.SliceStable(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Tag < counts[j].Tag
+	})
+}
+
+// NormalizeBarcode checks the check digit of a 12-digit UPC-A or 13-digit EAN-13 barcode and
+// returns it as an EAN-13, so a record's UPC and EAN are the same barcode. Spaces and dashes are
+// ignored.
+func NormalizeBarcode(barcode string) (string, error) {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(barcode)
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", errors.New("barcode must only contain digits")
+		}
+	}
+	switch len(digits) {
+	case 12:
+		digits = "0" + digits
+	case 13:
+	default:
+		return "", errors.New("barcode must be a 12-digit UPC or a 13-digit EAN")
+	}
+	// Digits are weighted 1 and 3 alternately from the left; the check digit brings the sum to a
+	// multiple of 10.
+	sum := 0
+	for i, r := range digits[:12] {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(r-'0') * weight
+	}
+	if check := (10 - sum%10) % 10; int(digits[12]-'0') != check {
+		return "", fmt.Errorf("barcode %s has the wrong check digit", barcode)
+	}
+	return digits, nil
+}
+
+// NormalizeCatalogueNumber puts a catalogue number into the form it is stored and looked up in:
+// upper case, with runs of whitespace collapsed to a single space, so "ts 277" is "TS 277".
+func NormalizeCatalogueNumber(number string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(number), " "))
+}
+
+// sortTrash orders albums in the trash from the most recently deleted.
+func sortTrash(albums []Album) {
+	sort.SliceStable(albums, func(i, j int) bool {
+		return albums[i].DeletedAt.After(*albums[j].DeletedAt)
+	})
 }
 
+// AlbumRepository stores albums. Albums in the trash are left out of every lookup and listing except
+// GetTrash; Delete removes an album for good, whether it is in the trash or not.
 type AlbumRepository interface {
 	GetAll() ([]Album, error)
 	GetByID(id string) (Album, error)
+	// GetByBarcode returns the album with the given EAN-13, or ErrAlbumNotFound.
+	GetByBarcode(barcode string) (Album, error)
+	// GetByCatalogueNumber returns the album the label released under the given normalized
+	// catalogue number, or ErrAlbumNotFound.
+	GetByCatalogueNumber(labelID, catalogueNumber string) (Album, error)
+	// GetByTag returns the albums with the given normalized tag.
+	GetByTag(tag string) ([]Album, error)
+	// TagCounts returns every tag in use with the number of albums that have it, most used first.
+	TagCounts() ([]TagCount, error)
+	// Create and Update fail with ErrDuplicateBarcode or ErrDuplicateCatalogueNumber if another
+	// album or a release already has the album's barcode, or another album its catalogue number on
+	// the same label. Update fails
+	// with ErrAlbumNotFound if the album is not in the catalogue.
 	Create(album Album) error
 	Delete(id string) error
 	Update(album Album) error
+	// Trash moves the album to the trash, recording who deleted it, or fails with ErrAlbumNotFound
+	// if it is not in the catalogue.
+	Trash(id, actor string) error
+	// GetTrash returns the albums in the trash, most recently deleted first.
+	GetTrash() ([]Album, error)
+	// Restore puts an album in the trash back into the catalogue, or fails with ErrAlbumNotFound if
+	// it is not in the trash.
+	Restore(id string) error
 }
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNormalizeBarcode tests that UPC-A and EAN-13 barcodes are checked and stored as EAN-13
+func TestNormalizeBarcode(t *testing.T) {
+	barcode, err := NormalizeBarcode("4006381333931")
+	assert.NoError(t, err)
+	assert.Equal(t, "4006381333931", barcode)
+
+	barcode, err = NormalizeBarcode("0 36000-29145 2")
+	assert.NoError(t, err)
+	assert.Equal(t, "0036000291452", barcode, "a UPC is the EAN with a leading 0")
+
+	_, err = NormalizeBarcode("4006381333932")
+	assert.ErrorContains(t, err, "check digit")
+	_, err = NormalizeBarcode("40063813339")
+	assert.Error(t, err)
+	_, err = NormalizeBarcode("40063813339X1")
+	assert.Error(t, err)
+}
+
+// TestNormalizeCatalogueNumber tests that catalogue numbers are upper-cased with spacing tidied
+func TestNormalizeCatalogueNumber(t *testing.T) {
+	assert.Equal(t, "TS 310", NormalizeCatalogueNumber("  ts   310 "))
+	assert.Equal(t, "M5-123V1", NormalizeCatalogueNumber("m5-123v1"))
+	assert.Equal(t, "", NormalizeCatalogueNumber(" "))
+}
+
+// TestNormalizeTags tests that tags are lower-cased, tidied and deduplicated, and that there cannot be too many or too long ones
+func TestNormalizeTags(t *testing.T) {
+	tags, err := NormalizeTags([]string{" Staff  Pick ", "staff pick", "", "Summer"})
+	assert.NoError(t, err)
+	assert.Equal(t, Tags{"staff pick", "summer"}, tags)
+
+	tags, err = NormalizeTags(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Tags{}, tags)
+
+	_, err = NormalizeTags([]string{strings.Repeat("x", MaxTagLength+1)})
+	assert.Error(t, err)
+	many := make([]string, MaxTags+1)
+	for i := range many {
+		many[i] = fmt.Sprintf("tag %d", i)
+	}
+	_, err = NormalizeTags(many)
+	assert.Error(t, err)
+}
+
+func TestValidIDs(t *testing.T) {
+	assert.True(t, validAlbumID("42"))
+	assert.False(t, validAlbumID(""))
+	assert.False(t, validAlbumID("42abc"))
+	assert.False(t, validAlbumID("99999999999"), "too large for a serial")
+	assert.True(t, validUUID("0b8f3c4e-6f0a-4b3e-9d2a-1c2b3d4e5f60"))
+	assert.False(t, validUUID("not-a-uuid"))
+	assert.False(t, validUUID("42"))
+}
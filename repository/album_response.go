@@ -1,11 +1,17 @@
 package repository
 
+import "github.com/tvergilio/motown-house-backend/money"
+
 // AlbumResponse is what the /api/search endpoint will return to the frontend.
 type AlbumResponse struct {
-	Title    string  `json:"title"`
-	Artist   string  `json:"artist"`
-	Price    float64 `json:"price"`
-	Year     int     `json:"year"`
-	Genre    string  `json:"genre"`
-	ImageURL string  `json:"image_url"`
+	Title          string       `json:"title"`
+	Artist         string       `json:"artist"`
+	ITunesArtistID int64        `json:"itunesArtistId,omitempty"`
+	Price          money.Amount `json:"price"`
+	Currency       string       `json:"currency"`
+	Year           int          `json:"year"`
+	Genre          string       `json:"genre"`
+	ImageURL       string       `json:"image_url"`
+	// Label is taken from the album's copyright line. iTunes search results have no barcode.
+	Label string `json:"label,omitempty"`
 }
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// ErrAlertNotFound is returned when an alert does not exist.
+var ErrAlertNotFound = errors.New("alert not found")
+
+// AlertKind says what change an alert is waiting for.
+type AlertKind string
+
+const (
+	// AlertPriceDrop fires when the album's price goes down, or down to TargetPrice if one is set.
+	AlertPriceDrop AlertKind = "price-drop"
+	// AlertBackInStock fires when the album, or the alert's format of it, can be bought again.
+	AlertBackInStock AlertKind = "back-in-stock"
+)
+
+// ParseAlertKind validates an alert kind supplied by a client.
+func ParseAlertKind(s string) (AlertKind, error) {
+	kind := AlertKind(strings.ToLower(strings.TrimSpace(s)))
+	switch kind {
+	case AlertPriceDrop, AlertBackInStock:
+		return kind, nil
+	}
+	return "", fmt.Errorf("invalid alert kind %q: must be price-drop or back-in-stock", s)
+}
+
+// Alert is a customer's request to be told when an album gets cheaper or comes back into stock.
+// Alerts fire once; TriggeredAt records when, and the customer can subscribe again afterwards.
+type Alert struct {
+	ID          string        `db:"id" json:"id"`
+	UserID      string        `db:"user_id" json:"userId"`
+	Email       string        `db:"email" json:"email"`
+	AlbumID     string        `db:"album_id" json:"albumId"`
+	Kind        AlertKind     `db:"kind" json:"kind"`
+	Format      Format        `db:"format" json:"format,omitempty"`
+	TargetPrice *money.Amount `db:"target_price" json:"targetPrice,omitempty"`
+	CreatedAt   time.Time     `db:"created_at" json:"createdAt"`
+	TriggeredAt *time.Time    `db:"triggered_at" json:"triggeredAt,omitempty"`
+}
+
+// Active reports whether the alert is still waiting to fire.
+func (a Alert) Active() bool {
+	return a.TriggeredAt == nil
+}
+
+// Validate checks the alert's email address and that its options fit its kind.
+func (a Alert) Validate() error {
+	if _, err := mail.ParseAddress(a.Email); err != nil || strings.ContainsAny(a.Email, "<>\r\n") {
+		return fmt.Errorf("invalid email %q", a.Email)
+	}
+	if a.TargetPrice != nil && (a.Kind != AlertPriceDrop || *a.TargetPrice <= 0) {
+		return errors.New("targetPrice must be positive and is only used for price-drop alerts")
+	}
+	if a.Format != "" && a.Kind != AlertBackInStock {
+		return errors.New("format is only used for back-in-stock alerts")
+	}
+	return nil
+}
+
+// Matches reports whether the alert is the same subscription as other, so a customer who
+// subscribes twice keeps a single alert.
+func (a Alert) Matches(other Alert) bool {
+	return a.UserID == other.UserID && a.AlbumID == other.AlbumID && a.Kind == other.Kind && a.Format == other.Format
+}
+
+// AlertRepository stores alerts. ListActiveByAlbum returns the alerts on an album that have not
+// fired yet; MarkTriggered records that an alert has fired.
+type AlertRepository interface {
+	Create(alert Alert) (Alert, error)
+	GetByID(id string) (Alert, error)
+	ListByUser(userID string) ([]Alert, error)
+	ListActiveByAlbum(albumID string) ([]Alert, error)
+	MarkTriggered(id string, at time.Time) error
+	Delete(id string) error
+}
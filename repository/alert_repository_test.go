@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestParseAlertKind tests kind parsing
+func TestParseAlertKind(t *testing.T) {
+	kind, err := ParseAlertKind(" Back-In-Stock ")
+	require.NoError(t, err)
+	assert.Equal(t, AlertBackInStock, kind)
+
+	_, err = ParseAlertKind("sold-out")
+	assert.Error(t, err)
+}
+
+// TestAlert_Validate tests that alerts need a usable email and options that fit their kind
+func TestAlert_Validate(t *testing.T) {
+	target := money.MustParse("20.00")
+	zero := money.Amount(0)
+	testCases := []struct {
+		name  string
+		alert Alert
+		valid bool
+	}{
+		{"price drop", Alert{Kind: AlertPriceDrop, Email: "ann@example.com"}, true},
+		{"price drop with target", Alert{Kind: AlertPriceDrop, Email: "ann@example.com", TargetPrice: &target}, true},
+		{"back in stock for a format", Alert{Kind: AlertBackInStock, Email: "ann@example.com", Format: FormatLP}, true},
+		{"display name", Alert{Kind: AlertPriceDrop, Email: "Ann <ann@example.com>"}, false},
+		{"no email", Alert{Kind: AlertPriceDrop}, false},
+		{"zero target", Alert{Kind: AlertPriceDrop, Email: "ann@example.com", TargetPrice: &zero}, false},
+		{"target on stock alert", Alert{Kind: AlertBackInStock, Email: "ann@example.com", TargetPrice: &target}, false},
+		{"format on price alert", Alert{Kind: AlertPriceDrop, Email: "ann@example.com", Format: FormatCD}, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.valid, tc.alert.Validate() == nil)
+		})
+	}
+}
+
+// TestAlert_Matches tests that the same user, album, kind and format count as one subscription
+func TestAlert_Matches(t *testing.T) {
+	alert := Alert{UserID: "user-1", AlbumID: "1", Kind: AlertBackInStock, Format: FormatLP, Email: "ann@example.com"}
+
+	assert.True(t, alert.Matches(Alert{UserID: "user-1", AlbumID: "1", Kind: AlertBackInStock, Format: FormatLP, Email: "other@example.com"}))
+	assert.False(t, alert.Matches(Alert{UserID: "user-1", AlbumID: "1", Kind: AlertBackInStock}))
+	assert.False(t, alert.Matches(Alert{UserID: "user-2", AlbumID: "1", Kind: AlertBackInStock, Format: FormatLP}))
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	// ErrArtistNotFound is returned when an artist does not exist.
+	ErrArtistNotFound = errors.New("artist not found")
+	// ErrDuplicateArtist is returned when an artist's name or one of its aliases is already used by another artist.
+	ErrDuplicateArtist = errors.New("another artist already goes by that name")
+	// ErrArtistHasAlbums is returned when deleting an artist that albums still belong to.
+	ErrArtistHasAlbums = errors.New("artist still has albums")
+)
+
+// Artist is a performer or group that albums belong to. Albums keep the artist's name as well as
+// its ID, so they can be shown without looking the artist up.
+type Artist struct {
+	ID       string `db:"id" json:"id"`
+	Name     string `db:"name" json:"name"`
+	SortName string `db:"sort_name" json:"sortName"`
+
+	// Aliases are other names the artist is known by, such as earlier names or common misspellings.
+	// Albums created under an alias are linked to the artist and shown under its name.
+	Aliases Aliases `db:"aliases" json:"aliases"`
+
+	Bio            string    `db:"bio" json:"bio,omitempty"`
+	ImageURL       string    `db:"image_url" json:"imageUrl,omitempty"`
+	ITunesArtistID int64     `db:"itunes_artist_id" json:"itunesArtistId,omitempty"`
+	CreatedAt      time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// Aliases is a list of names, stored as a JSON array.
+type Aliases []string
+
+// Value stores the aliases as a JSON array.
+func (a Aliases) Value() (driver.Value, error) {
+	if a == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(a)
+	return string(b), err
+}
+
+// Scan reads aliases stored as a JSON array.
+func (a *Aliases) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, a)
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	case nil:
+		*a = nil
+		return nil
+	}
+	return fmt.Errorf("cannot scan %T into Aliases", src)
+}
+
+// NormalizeArtistName trims an artist's name and collapses runs of whitespace to a single space.
+func NormalizeArtistName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// ArtistNameKey is the form names are matched in, so that "Stevie Wonder", "stevie  wonder" and
+// "Stevie Wonder." are the same artist, as are "Earth, Wind & Fire" and "Earth Wind and Fire" or
+// "The Supremes" and "Supremes". The backfill in the artists migration works keys out the same way.
+func ArtistNameKey(name string) string {
+	folded := strings.ReplaceAll(strings.ToLower(name), "&", " and ")
+	folded = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r) {
+			return r
+		}
+		return -1
+	}, folded)
+	key := strings.TrimPrefix(NormalizeArtistName(folded), "the ")
+	if key == "" {
+		// Names made only of punctuation, such as "!!!", are matched as they are.
+		return strings.ToLower(NormalizeArtistName(name))
+	}
+	return key
+}
+
+// DefaultSortName is the name an artist is sorted by unless one is given: its name, with a leading
+// "The" moved to the end so that "The Temptations" is sorted as "Temptations, The".
+func DefaultSortName(name string) string {
+	if len(name) > 4 && strings.EqualFold(name[:4], "the ") {
+		return name[4:] + ", " + name[:3]
+	}
+	return name
+}
+
+// Normalize tidies the artist's name, sort name and aliases, filling in the default sort name and
+// dropping aliases that are blank or match the name or another alias.
+func (a *Artist) Normalize() {
+	a.Name = NormalizeArtistName(a.Name)
+	a.SortName = NormalizeArtistName(a.SortName)
+	if a.SortName == "" {
+		a.SortName = DefaultSortName(a.Name)
+	}
+	a.Bio = strings.TrimSpace(a.Bio)
+	a.ImageURL = strings.TrimSpace(a.ImageURL)
+	seen := map[string]bool{ArtistNameKey(a.Name): true}
+	aliases := Aliases{}
+	for _, alias := range a.Aliases {
+		alias = NormalizeArtistName(alias)
+		key := ArtistNameKey(alias)
+		if alias == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		aliases = append(aliases, alias)
+	}
+	a.Aliases = aliases
+}
+
+// Validate checks the artist has a name.
+func (a Artist) Validate() error {
+	if NormalizeArtistName(a.Name) == "" {
+		return errors.New("name is required")
+	}
+	if a.ITunesArtistID < 0 {
+		return errors.New("itunesArtistId cannot be negative")
+	}
+	return nil
+}
+
+// nameKeys returns the keys of the artist's name and aliases, which no other artist may use.
+func (a Artist) nameKeys() []string {
+	keys := []string{ArtistNameKey(a.Name)}
+	for _, alias := range a.Aliases {
+		keys = append(keys, ArtistNameKey(alias))
+	}
+	return keys
+}
+
+// sortArtists orders artists by sort name, ignoring case.
+func sortArtists(artists []Artist) {
+	sort.SliceStable(artists, func(i, j int) bool {
+		si, sj := strings.ToLower(artists[i].SortName), strings.ToLower(artists[j].SortName)
+		if si != sj {
+			return si < sj
+		}
+		return artists[i].Name < artists[j].Name
+	})
+}
+
+type ArtistRepository interface {
+	// Create adds an artist, failing with ErrDuplicateArtist if its name or an alias is taken.
+	Create(artist Artist) (Artist, error)
+	GetByID(id string) (Artist, error)
+	// GetByName finds the artist that goes by name, either as its name or an alias, compared
+	// with ArtistNameKey.
+	GetByName(name string) (Artist, error)
+	// List returns every artist ordered by sort name.
+	List() ([]Artist, error)
+	Update(artist Artist) (Artist, error)
+	Delete(id string) error
+}
+
+// ResolveArtist returns the artist that goes by name, creating one if there is none yet.
+func ResolveArtist(artists ArtistRepository, name string) (Artist, error) {
+	artist, err := artists.GetByName(name)
+	if !errors.Is(err, ErrArtistNotFound) {
+		return artist, err
+	}
+	artist = Artist{Name: name}
+	artist.Normalize()
+	created, err := artists.Create(artist)
+	if errors.Is(err, ErrDuplicateArtist) {
+		// Someone else created the artist in the meantime.
+		return artists.GetByName(name)
+	}
+	return created, err
+}
+
+// LinkAlbumArtists links every album that has an artist name but no artist ID to the artist that
+// goes by that name, creating artists as needed, and puts the album's artist name into the form
+// the artist uses. It returns how many albums were linked; albums already linked are left alone,
+// so it is safe to run repeatedly.
+func LinkAlbumArtists(albums AlbumRepository, artists ArtistRepository) (int, error) {
+	all, err := albums.GetAll()
+	if err != nil {
+		return 0, err
+	}
+	linked := 0
+	for _, album := range all {
+		if album.ArtistID != "" || NormalizeArtistName(album.Artist) == "" {
+			continue
+		}
+		artist, err := ResolveArtist(artists, album.Artist)
+		if err != nil {
+			return linked, fmt.Errorf("linking album %s to %q: %w", album.ID, album.Artist, err)
+		}
+		album.ArtistID = artist.ID
+		album.Artist = artist.Name
+		if err := albums.Update(album); err != nil {
+			return linked, err
+		}
+		linked++
+	}
+	return linked, nil
+}
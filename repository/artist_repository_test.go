@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestArtistNameKey tests that names differing only in case, spacing, punctuation, "&" or a leading "The" match
+func TestArtistNameKey(t *testing.T) {
+	assert.Equal(t, "stevie wonder", ArtistNameKey("Stevie Wonder"))
+	assert.Equal(t, ArtistNameKey("Stevie Wonder"), ArtistNameKey("  stevie   WONDER. "))
+	assert.Equal(t, ArtistNameKey("Earth, Wind & Fire"), ArtistNameKey("Earth Wind and Fire"))
+	assert.Equal(t, ArtistNameKey("The Supremes"), ArtistNameKey("Supremes"))
+	assert.Equal(t, "beyoncé", ArtistNameKey("Beyoncé"))
+	assert.NotEqual(t, ArtistNameKey("Stevie Wonder"), ArtistNameKey("Little Stevie Wonder"))
+	assert.Equal(t, "the", ArtistNameKey("The"))
+	assert.Equal(t, "!!!", ArtistNameKey("!!!"))
+}
+
+// TestDefaultSortName tests that a leading "The" is moved to the end
+func TestDefaultSortName(t *testing.T) {
+	assert.Equal(t, "Temptations, The", DefaultSortName("The Temptations"))
+	assert.Equal(t, "Marvin Gaye", DefaultSortName("Marvin Gaye"))
+	assert.Equal(t, "Theo Parrish", DefaultSortName("Theo Parrish"))
+	assert.Equal(t, "The", DefaultSortName("The"))
+}
+
+// TestArtist_Normalize tests tidying names and dropping blank or repeated aliases
+func TestArtist_Normalize(t *testing.T) {
+	artist := Artist{
+		Name:    "  Stevie   Wonder ",
+		Aliases: Aliases{"Little Stevie Wonder", " ", "stevie wonder", "little stevie  wonder", "Stevland Morris"},
+	}
+	artist.Normalize()
+
+	assert.Equal(t, "Stevie Wonder", artist.Name)
+	assert.Equal(t, "Stevie Wonder", artist.SortName)
+	assert.Equal(t, Aliases{"Little Stevie Wonder", "Stevland Morris"}, artist.Aliases)
+	assert.Equal(t, []string{"stevie wonder", "little stevie wonder", "stevland morris"}, artist.nameKeys())
+
+	artist = Artist{Name: "The Four Tops", SortName: "Tops"}
+	artist.Normalize()
+	assert.Equal(t, "Tops", artist.SortName, "a sort name that is given is kept")
+	assert.Equal(t, Aliases{}, artist.Aliases)
+}
+
+// TestArtist_Validate tests that artists need a name
+func TestArtist_Validate(t *testing.T) {
+	assert.NoError(t, Artist{Name: "Marvin Gaye"}.Validate())
+	assert.Error(t, Artist{Name: "  "}.Validate())
+	assert.Error(t, Artist{Name: "Marvin Gaye", ITunesArtistID: -1}.Validate())
+}
+
+// TestAliases_Scan tests reading aliases stored as JSON
+func TestAliases_Scan(t *testing.T) {
+	var aliases Aliases
+	assert.NoError(t, aliases.Scan([]byte(`["Little Stevie Wonder"]`)))
+	assert.Equal(t, Aliases{"Little Stevie Wonder"}, aliases)
+	assert.Error(t, aliases.Scan(42))
+
+	value, err := Aliases(nil).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "[]", value)
+}
+
+// TestSortArtists tests ordering by sort name, ignoring case
+func TestSortArtists(t *testing.T) {
+	artists := []Artist{
+		{Name: "The Temptations", SortName: "Temptations, The"},
+		{Name: "marvin gaye", SortName: "marvin gaye"},
+		{Name: "Diana Ross", SortName: "Ross, Diana"},
+	}
+	sortArtists(artists)
+	assert.Equal(t, "marvin gaye", artists[0].Name)
+	assert.Equal(t, "Diana Ross", artists[1].Name)
+	assert.Equal(t, "The Temptations", artists[2].Name)
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrArtworkNotFound is returned when an album has no uploaded artwork.
+var ErrArtworkNotFound = errors.New("artwork not found")
+
+// Artwork is a sleeve image uploaded for an album, with the thumbnails made from it. Key is where the
+// image is kept in storage and URL where it is served from; the album's ImageUrl is set to URL.
+type Artwork struct {
+	AlbumID     string     `db:"album_id" json:"albumId"`
+	Key         string     `db:"storage_key" json:"key"`
+	URL         string     `db:"url" json:"url"`
+	ContentType string     `db:"content_type" json:"contentType"`
+	Width       int        `db:"width" json:"width"`
+	Height      int        `db:"height" json:"height"`
+	Bytes       int        `db:"bytes" json:"bytes"`
+	Thumbnails  Thumbnails `db:"thumbnails" json:"thumbnails"`
+	UploadedBy  string     `db:"uploaded_by" json:"uploadedBy"`
+	UploadedAt  time.Time  `db:"uploaded_at" json:"uploadedAt"`
+}
+
+// Keys returns the storage keys of the image and its thumbnails.
+func (a Artwork) Keys() []string {
+	keys := []string{a.Key}
+	for _, thumbnail := range a.Thumbnails {
+		keys = append(keys, thumbnail.Key)
+	}
+	return keys
+}
+
+// Thumbnail is a smaller copy of an album's artwork whose longest side is at most Size pixels.
+type Thumbnail struct {
+	Size        int    `json:"size"`
+	Key         string `json:"key"`
+	URL         string `json:"url"`
+	ContentType string `json:"contentType"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+}
+
+// Thumbnails are an album's artwork thumbnails, smallest first, stored as a JSON array.
+type Thumbnails []Thumbnail
+
+// Value stores the thumbnails as a JSON array.
+func (t Thumbnails) Value() (driver.Value, error) {
+	if t == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(t)
+	return string(b), err
+}
+
+// Scan reads thumbnails stored as a JSON array.
+func (t *Thumbnails) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, t)
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	case nil:
+		*t = nil
+		return nil
+	}
+	return fmt.Errorf("cannot scan %T into Thumbnails", src)
+}
+
+// ArtworkRepository stores the artwork uploaded for each album, one image per album. Save replaces
+// any artwork the album already has.
+type ArtworkRepository interface {
+	Save(artwork Artwork) (Artwork, error)
+	Get(albumID string) (Artwork, error)
+	Delete(albumID string) error
+}
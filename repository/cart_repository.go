@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// ErrCartNotFound is returned when a cart does not exist or has expired.
+var ErrCartNotFound = errors.New("cart not found")
+
+// Cart holds a customer's selections server-side. Anonymous carts have an empty UserID
+// and are addressed only by their unguessable ID.
+type Cart struct {
+	ID         string     `db:"id" json:"id"`
+	UserID     string     `db:"user_id" json:"userId,omitempty"`
+	Items      []CartItem `db:"-" json:"items"`
+	CouponCode string     `db:"coupon_code" json:"couponCode,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt  time.Time  `db:"updated_at" json:"updatedAt"`
+
+	// The totals are calculated by the API layer from current album prices and promotions; they are never stored.
+	Subtotal money.Amount `db:"-" json:"subtotal"`
+	Discount money.Amount `db:"-" json:"discount"`
+	Total    money.Amount `db:"-" json:"total"`
+}
+
+// CartItem is one album and format in a cart, or one release of the album when ReleaseID is set.
+// Only the album, release, format and quantity are stored; the descriptive and price fields are
+// recalculated from the album or release every time the cart is viewed.
+type CartItem struct {
+	AlbumID   string `db:"album_id" json:"albumId"`
+	ReleaseID string `db:"release_id" json:"releaseId,omitempty"`
+	Format    Format `db:"format" json:"format"`
+	Quantity  int    `db:"quantity" json:"quantity"`
+
+	Title     string       `db:"-" json:"title,omitempty"`
+	Artist    string       `db:"-" json:"artist,omitempty"`
+	UnitPrice money.Amount `db:"-" json:"unitPrice"`
+	Discount  money.Amount `db:"-" json:"discount,omitempty"`
+	Promotion string       `db:"-" json:"promotion,omitempty"`
+	LineTotal money.Amount `db:"-" json:"lineTotal"`
+	Available int          `db:"-" json:"available"`
+}
+
+// FindItem returns the index of the item for albumID, releaseID and format, or -1 if it is not in
+// the cart. An empty releaseID finds the album itself.
+func (c *Cart) FindItem(albumID, releaseID string, format Format) int {
+	for i, item := range c.Items {
+		if item.AlbumID == albumID && item.ReleaseID == releaseID && item.Format == format {
+			return i
+		}
+	}
+	return -1
+}
+
+// CartRepository stores carts. Save replaces the cart's owner and items and counts as activity,
+// postponing expiry; DeleteIdleSince removes carts with no activity since cutoff.
+type CartRepository interface {
+	Create(cart Cart) (Cart, error)
+	GetByID(id string) (Cart, error)
+	GetByUser(userID string) (Cart, error)
+	Save(cart Cart) error
+	Delete(id string) error
+	DeleteIdleSince(cutoff time.Time) (int64, error)
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCart_FindItem tests looking up items by album, release and format
+func TestCart_FindItem(t *testing.T) {
+	cart := Cart{Items: []CartItem{{AlbumID: "1", Format: FormatLP}, {AlbumID: "1", Format: FormatCD}, {AlbumID: "1", ReleaseID: "r-1", Format: FormatLP}}}
+
+	assert.Equal(t, 1, cart.FindItem("1", "", FormatCD))
+	assert.Equal(t, 2, cart.FindItem("1", "r-1", FormatLP))
+	assert.Equal(t, -1, cart.FindItem("1", "r-1", FormatCD))
+	assert.Equal(t, -1, cart.FindItem("1", "", FormatCassette))
+}
+
+// TestCartItemKey tests the round trip of the Cassandra item map key
+func TestCartItemKey(t *testing.T) {
+	for _, item := range []CartItem{
+		{AlbumID: "550e8400-e29b-41d4-a716-446655440000", Format: FormatCassette},
+		{AlbumID: "550e8400-e29b-41d4-a716-446655440000", ReleaseID: "6ba7b810-9dad-11d1-80b4-00c04fd430c8", Format: FormatLP},
+	} {
+		parsed, err := parseCartItemKey(cartItemKey(item))
+		require.NoError(t, err)
+		assert.Equal(t, item, parsed)
+	}
+
+	_, err := parseCartItemKey("no-separator")
+	assert.Error(t, err)
+}
+
+// TestCassandraCartRepository_InvalidUUID tests that malformed cart IDs are reported as not found
+func TestCassandraCartRepository_InvalidUUID(t *testing.T) {
+	repo := NewCassandraCartRepository(nil, time.Hour)
+
+	_, err := repo.GetByID("invalid-uuid")
+
+	assert.True(t, errors.Is(err, ErrCartNotFound))
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// CassandraAlertRepository keeps lookup tables of alerts by user and of alerts still waiting to fire
+// by album, so checking an album after a change only reads the alerts that can fire.
+type CassandraAlertRepository struct {
+	session *gocql.Session
+}
+
+func NewCassandraAlertRepository(session *gocql.Session) *CassandraAlertRepository {
+	return &CassandraAlertRepository{session: session}
+}
+
+const cassandraAlertColumns = "id, user_id, email, album_id, kind, format, target_price, created_at, triggered_at"
+
+// optionalAmount converts a nullable amount to a value gocql writes as null when unset.
+func optionalAmount(a *money.Amount) interface{} {
+	if a == nil {
+		return nil
+	}
+	return *a
+}
+
+func (r *CassandraAlertRepository) Create(alert Alert) (Alert, error) {
+	id := gocql.TimeUUID()
+	alert.ID = id.String()
+	alert.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	alert.TriggeredAt = nil
+
+	batch := r.session.NewBatch(gocql.LoggedBatch)
+	batch.Query(
+		"INSERT INTO alerts (id, user_id, email, album_id, kind, format, target_price, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		id, alert.UserID, alert.Email, alert.AlbumID, alert.Kind, alert.Format, optionalAmount(alert.TargetPrice), alert.CreatedAt,
+	)
+	batch.Query("INSERT INTO alerts_by_user (user_id, id) VALUES (?, ?)", alert.UserID, id)
+	batch.Query("INSERT INTO active_alerts_by_album (album_id, id) VALUES (?, ?)", alert.AlbumID, id)
+	if err := r.session.ExecuteBatch(batch); err != nil {
+		return Alert{}, err
+	}
+	return alert, nil
+}
+
+func (r *CassandraAlertRepository) GetByID(id string) (Alert, error) {
+	parsedUUID, err := gocql.ParseUUID(id)
+	if err != nil {
+		return Alert{}, ErrAlertNotFound
+	}
+	alert, err := scanAlert(r.session.Query("SELECT "+cassandraAlertColumns+" FROM alerts WHERE id = ?", parsedUUID).Scan)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return Alert{}, ErrAlertNotFound
+	}
+	return alert, err
+}
+
+// scanAlert reads one row selected with cassandraAlertColumns using the given scan function.
+func scanAlert(scan func(dest ...interface{}) error) (Alert, error) {
+	var alert Alert
+	var id gocql.UUID
+	var triggeredAt time.Time
+	if err := scan(&id, &alert.UserID, &alert.Email, &alert.AlbumID, &alert.Kind, &alert.Format,
+		&alert.TargetPrice, &alert.CreatedAt, &triggeredAt); err != nil {
+		return Alert{}, err
+	}
+	alert.ID = id.String()
+	if !triggeredAt.IsZero() {
+		alert.TriggeredAt = &triggeredAt
+	}
+	return alert, nil
+}
+
+func (r *CassandraAlertRepository) ListByUser(userID string) ([]Alert, error) {
+	return r.list(r.session.Query("SELECT id FROM alerts_by_user WHERE user_id = ?", userID).Iter())
+}
+
+func (r *CassandraAlertRepository) ListActiveByAlbum(albumID string) ([]Alert, error) {
+	alerts, err := r.list(r.session.Query("SELECT id FROM active_alerts_by_album WHERE album_id = ?", albumID).Iter())
+	if err != nil {
+		return nil, err
+	}
+	active := alerts[:0]
+	for _, alert := range alerts {
+		if alert.Active() {
+			active = append(active, alert)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].CreatedAt.Before(active[j].CreatedAt) })
+	return active, nil
+}
+
+// list loads every alert whose ID the iterator yields, skipping any deleted since the lookup row was read.
+func (r *CassandraAlertRepository) list(iter *gocql.Iter) ([]Alert, error) {
+	var ids []gocql.UUID
+	var id gocql.UUID
+	for iter.Scan(&id) {
+		ids = append(ids, id)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	var alerts []Alert
+	for _, id := range ids {
+		alert, err := r.GetByID(id.String())
+		if errors.Is(err, ErrAlertNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, nil
+}
+
+func (r *CassandraAlertRepository) MarkTriggered(id string, at time.Time) error {
+	alert, err := r.GetByID(id)
+	if err != nil {
+		return err
+	}
+	parsedUUID, _ := gocql.ParseUUID(id)
+	batch := r.session.NewBatch(gocql.LoggedBatch)
+	batch.Query("UPDATE alerts SET triggered_at = ? WHERE id = ?", at, parsedUUID)
+	batch.Query("DELETE FROM active_alerts_by_album WHERE album_id = ? AND id = ?", alert.AlbumID, parsedUUID)
+	return r.session.ExecuteBatch(batch)
+}
+
+func (r *CassandraAlertRepository) Delete(id string) error {
+	alert, err := r.GetByID(id)
+	if err != nil {
+		return err
+	}
+	parsedUUID, _ := gocql.ParseUUID(id)
+	batch := r.session.NewBatch(gocql.LoggedBatch)
+	batch.Query("DELETE FROM alerts WHERE id = ?", parsedUUID)
+	batch.Query("DELETE FROM alerts_by_user WHERE user_id = ? AND id = ?", alert.UserID, parsedUUID)
+	batch.Query("DELETE FROM active_alerts_by_album WHERE album_id = ? AND id = ?", alert.AlbumID, parsedUUID)
+	return r.session.ExecuteBatch(batch)
+}
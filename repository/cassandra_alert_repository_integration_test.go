@@ -0,0 +1,63 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestCassandraAlertRepository tests that triggered alerts drop out of the album's active alerts but stay listed for the user.
+func TestCassandraAlertRepository(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	albumID := gocql.TimeUUID().String()
+	repo := NewCassandraAlertRepository(session)
+	target := money.MustParse("0.50")
+
+	priceDrop, err := repo.Create(Alert{UserID: "user-1", Email: "ann@example.com", AlbumID: albumID, Kind: AlertPriceDrop, TargetPrice: &target})
+	require.NoError(t, err)
+	backInStock, err := repo.Create(Alert{UserID: "user-1", Email: "ann@example.com", AlbumID: albumID, Kind: AlertBackInStock, Format: FormatLP})
+	require.NoError(t, err)
+
+	found, err := repo.GetByID(priceDrop.ID)
+	require.NoError(t, err)
+	require.Equal(t, money.MustParse("0.50"), *found.TargetPrice)
+	require.Nil(t, found.TriggeredAt)
+	found, err = repo.GetByID(backInStock.ID)
+	require.NoError(t, err)
+	require.Nil(t, found.TargetPrice)
+
+	active, err := repo.ListActiveByAlbum(albumID)
+	require.NoError(t, err)
+	require.Len(t, active, 2)
+	require.Equal(t, priceDrop.ID, active[0].ID, "oldest first")
+
+	triggeredAt := time.Now().UTC().Truncate(time.Millisecond)
+	require.NoError(t, repo.MarkTriggered(priceDrop.ID, triggeredAt))
+	active, err = repo.ListActiveByAlbum(albumID)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	require.Equal(t, backInStock.ID, active[0].ID)
+
+	mine, err := repo.ListByUser("user-1")
+	require.NoError(t, err)
+	require.Len(t, mine, 2)
+	found, err = repo.GetByID(priceDrop.ID)
+	require.NoError(t, err)
+	require.True(t, triggeredAt.Equal(*found.TriggeredAt))
+
+	require.NoError(t, repo.Delete(backInStock.ID))
+	_, err = repo.GetByID(backInStock.ID)
+	require.True(t, errors.Is(err, ErrAlertNotFound))
+	require.True(t, errors.Is(repo.Delete(backInStock.ID), ErrAlertNotFound))
+	active, err = repo.ListActiveByAlbum(albumID)
+	require.NoError(t, err)
+	require.Empty(t, active)
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// CassandraArtistRepository claims the key of every name and alias an artist goes by in
+// artists_by_name with a lightweight transaction, so two artists can never share a name. Cassandra
+// cannot check that no albums belong to an artist before it is deleted; callers must.
+type CassandraArtistRepository struct {
+	session *gocql.Session
+}
+
+func NewCassandraArtistRepository(session *gocql.Session) *CassandraArtistRepository {
+	return &CassandraArtistRepository{session: session}
+}
+
+const cassandraArtistColumns = "id, name, sort_name, aliases, bio, image_url, itunes_artist_id, created_at, updated_at"
+
+func (r *CassandraArtistRepository) Create(artist Artist) (Artist, error) {
+	artist.Normalize()
+	id := gocql.TimeUUID()
+	artist.ID = id.String()
+	artist.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	artist.UpdatedAt = artist.CreatedAt
+	if _, err := r.claimNames(id, artist.nameKeys()); err != nil {
+		return Artist{}, err
+	}
+	if err := r.save(id, artist); err != nil {
+		return Artist{}, err
+	}
+	return artist, nil
+}
+
+func (r *CassandraArtistRepository) save(id gocql.UUID, artist Artist) error {
+	return r.session.Query(
+		"INSERT INTO artists ("+cassandraArtistColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		id, artist.Name, artist.SortName, []string(artist.Aliases), artist.Bio, artist.ImageURL,
+		artist.ITunesArtistID, artist.CreatedAt, artist.UpdatedAt,
+	).Exec()
+}
+
+// claimNames records that the artist goes by each of keys, returning the keys it did not already
+// have. If another artist has any of them, the new claims are given up and ErrDuplicateArtist is returned.
+func (r *CassandraArtistRepository) claimNames(id gocql.UUID, keys []string) ([]string, error) {
+	var claimed []string
+	for _, key := range keys {
+		var existingKey string
+		var owner gocql.UUID
+		applied, err := r.session.Query(
+			"INSERT INTO artists_by_name (name_key, artist_id) VALUES (?, ?) IF NOT EXISTS",
+			key, id,
+		).ScanCAS(&existingKey, &owner)
+		if err == nil && !applied && owner != id {
+			err = ErrDuplicateArtist
+		}
+		if err != nil {
+			r.releaseNames(id, claimed)
+			return nil, err
+		}
+		if applied {
+			claimed = append(claimed, key)
+		}
+	}
+	return claimed, nil
+}
+
+// releaseNames gives up the artist's claim to each of keys, leaving any that another artist holds.
+func (r *CassandraArtistRepository) releaseNames(id gocql.UUID, keys []string) {
+	for _, key := range keys {
+		_ = r.session.Query("DELETE FROM artists_by_name WHERE name_key = ? IF artist_id = ?", key, id).Exec()
+	}
+}
+
+func (r *CassandraArtistRepository) GetByID(id string) (Artist, error) {
+	parsedUUID, err := gocql.ParseUUID(id)
+	if err != nil {
+		return Artist{}, ErrArtistNotFound
+	}
+	artist, err := scanArtist(r.session.Query("SELECT "+cassandraArtistColumns+" FROM artists WHERE id = ?", parsedUUID).Scan)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return Artist{}, ErrArtistNotFound
+	}
+	return artist, err
+}
+
+// scanArtist reads one row selected with cassandraArtistColumns using the given scan function.
+func scanArtist(scan func(dest ...interface{}) error) (Artist, error) {
+	var artist Artist
+	var id gocql.UUID
+	var aliases []string
+	if err := scan(&id, &artist.Name, &artist.SortName, &aliases, &artist.Bio, &artist.ImageURL,
+		&artist.ITunesArtistID, &artist.CreatedAt, &artist.UpdatedAt); err != nil {
+		return Artist{}, err
+	}
+	artist.ID = id.String()
+	artist.Aliases = append(Aliases{}, aliases...)
+	return artist, nil
+}
+
+func (r *CassandraArtistRepository) GetByName(name string) (Artist, error) {
+	var id gocql.UUID
+	err := r.session.Query("SELECT artist_id FROM artists_by_name WHERE name_key = ?", ArtistNameKey(name)).Scan(&id)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return Artist{}, ErrArtistNotFound
+	}
+	if err != nil {
+		return Artist{}, err
+	}
+	return r.GetByID(id.String())
+}
+
+func (r *CassandraArtistRepository) List() ([]Artist, error) {
+	var artists []Artist
+	iter := r.session.Query("SELECT " + cassandraArtistColumns + " FROM artists").Iter()
+	scanner := iter.Scanner()
+	for scanner.Next() {
+		artist, err := scanArtist(scanner.Scan)
+		if err != nil {
+			return nil, err
+		}
+		artists = append(artists, artist)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sortArtists(artists)
+	return artists, nil
+}
+
+func (r *CassandraArtistRepository) Update(artist Artist) (Artist, error) {
+	current, err := r.GetByID(artist.ID)
+	if err != nil {
+		return Artist{}, err
+	}
+	artist.Normalize()
+	id, _ := gocql.ParseUUID(artist.ID)
+	claimed, err := r.claimNames(id, artist.nameKeys())
+	if err != nil {
+		return Artist{}, err
+	}
+	artist.CreatedAt = current.CreatedAt
+	artist.UpdatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	if err := r.save(id, artist); err != nil {
+		r.releaseNames(id, claimed)
+		return Artist{}, err
+	}
+
+	keep := make(map[string]bool)
+	for _, key := range artist.nameKeys() {
+		keep[key] = true
+	}
+	var dropped []string
+	for _, key := range current.nameKeys() {
+		if !keep[key] {
+			dropped = append(dropped, key)
+		}
+	}
+	r.releaseNames(id, dropped)
+	return artist, nil
+}
+
+func (r *CassandraArtistRepository) Delete(id string) error {
+	artist, err := r.GetByID(id)
+	if err != nil {
+		return err
+	}
+	parsedUUID, _ := gocql.ParseUUID(id)
+	if err := r.session.Query("DELETE FROM artists WHERE id = ?", parsedUUID).Exec(); err != nil {
+		return err
+	}
+	r.releaseNames(parsedUUID, artist.nameKeys())
+	return nil
+}
@@ -0,0 +1,111 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestCassandraArtistRepository_Names tests matching artists by name or alias, keeping names unique and linking albums.
+func TestCassandraArtistRepository_Names(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraArtistRepository(session)
+	albums := NewCassandraAlbumRepository(session)
+
+	stevie, err := repo.Create(Artist{Name: "Stevie Wonder", Aliases: Aliases{"Little Stevie Wonder"}, ITunesArtistID: 34584})
+	require.NoError(t, err)
+	require.Equal(t, int64(34584), stevie.ITunesArtistID)
+
+	found, err := repo.GetByName("little stevie wonder")
+	require.NoError(t, err)
+	require.Equal(t, stevie.ID, found.ID)
+	_, err = repo.Create(Artist{Name: "Little Stevie Wonder"})
+	require.True(t, errors.Is(err, ErrDuplicateArtist))
+
+	temptations, err := repo.Create(Artist{Name: "The Temptations"})
+	require.NoError(t, err)
+	require.Equal(t, "Temptations, The", temptations.SortName)
+	temptations.Aliases = Aliases{"Stevie Wonder"}
+	_, err = repo.Update(temptations)
+	require.True(t, errors.Is(err, ErrDuplicateArtist))
+	found, err = repo.GetByName("Temptations")
+	require.NoError(t, err, "a failed update keeps the names the artist already had")
+	require.Equal(t, temptations.ID, found.ID)
+
+	stevie.Aliases = nil
+	_, err = repo.Update(stevie)
+	require.NoError(t, err)
+	_, err = repo.GetByName("Little Stevie Wonder")
+	require.True(t, errors.Is(err, ErrArtistNotFound), "dropped aliases no longer match")
+
+	require.NoError(t, albums.Create(Album{Title: "Innervisions", Artist: "stevie  wonder", Price: money.MustParse("9.99"), Year: 1973, ImageUrl: "x", Genre: "Soul"}))
+	require.NoError(t, albums.Create(Album{Title: "What's Going On", Artist: "Marvin Gaye", Price: money.MustParse("9.99"), Year: 1971, ImageUrl: "x", Genre: "Soul"}))
+	linked, err := LinkAlbumArtists(albums, repo)
+	require.NoError(t, err)
+	require.Equal(t, 2, linked)
+	linked, err = LinkAlbumArtists(albums, repo)
+	require.NoError(t, err)
+	require.Zero(t, linked)
+
+	all, err := albums.GetAll()
+	require.NoError(t, err)
+	for _, album := range all {
+		require.NotEmpty(t, album.ArtistID)
+		if album.Title == "Innervisions" {
+			require.Equal(t, stevie.ID, album.ArtistID)
+			require.Equal(t, "Stevie Wonder", album.Artist)
+		}
+	}
+
+	artists, err := repo.List()
+	require.NoError(t, err)
+	require.Len(t, artists, 3)
+	require.Equal(t, "Marvin Gaye", artists[0].Name)
+
+	require.NoError(t, repo.Delete(temptations.ID))
+	require.True(t, errors.Is(repo.Delete(temptations.ID), ErrArtistNotFound))
+	_, err = repo.Create(Artist{Name: "The Temptations"})
+	require.NoError(t, err, "deleting an artist frees its names")
+}
+
+// TestCassandraArtistRepository_ConcurrentCreate tests that the lightweight transactions on artists_by_name
+// let only one of several artists created at once with the same name exist.
+func TestCassandraArtistRepository_ConcurrentCreate(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraArtistRepository(session)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = repo.Create(Artist{Name: "Martha and the Vandellas"})
+		}()
+	}
+	wg.Wait()
+
+	created := 0
+	for _, err := range errs {
+		if err == nil {
+			created++
+		} else {
+			require.True(t, errors.Is(err, ErrDuplicateArtist), err)
+		}
+	}
+	require.Equal(t, 1, created)
+	found, err := repo.GetByName("Martha & The Vandellas")
+	require.NoError(t, err)
+	artists, err := repo.List()
+	require.NoError(t, err)
+	require.Len(t, artists, 1)
+	require.Equal(t, artists[0].ID, found.ID)
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// CassandraArtworkRepository keeps each album's artwork in one row, with its thumbnails as JSON.
+type CassandraArtworkRepository struct {
+	session *gocql.Session
+}
+
+func NewCassandraArtworkRepository(session *gocql.Session) *CassandraArtworkRepository {
+	return &CassandraArtworkRepository{session: session}
+}
+
+const cassandraArtworkColumns = "storage_key, url, content_type, width, height, bytes, thumbnails, uploaded_by, uploaded_at"
+
+func (r *CassandraArtworkRepository) Save(artwork Artwork) (Artwork, error) {
+	albumID, err := gocql.ParseUUID(artwork.AlbumID)
+	if err != nil {
+		return Artwork{}, err
+	}
+	thumbnails, err := artwork.Thumbnails.Value()
+	if err != nil {
+		return Artwork{}, err
+	}
+	artwork.AlbumID = albumID.String()
+	artwork.UploadedAt = time.Now().UTC().Truncate(time.Millisecond)
+	err = r.session.Query(
+		"INSERT INTO album_artwork (album_id, "+cassandraArtworkColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		albumID, artwork.Key, artwork.URL, artwork.ContentType, artwork.Width, artwork.Height, artwork.Bytes,
+		thumbnails, artwork.UploadedBy, artwork.UploadedAt,
+	).Exec()
+	if err != nil {
+		return Artwork{}, err
+	}
+	return artwork, nil
+}
+
+func (r *CassandraArtworkRepository) Get(albumID string) (Artwork, error) {
+	parsedUUID, err := gocql.ParseUUID(albumID)
+	if err != nil {
+		return Artwork{}, ErrArtworkNotFound
+	}
+	artwork := Artwork{AlbumID: parsedUUID.String()}
+	var thumbnails string
+	err = r.session.Query(
+		"SELECT "+cassandraArtworkColumns+" FROM album_artwork WHERE album_id = ?", parsedUUID,
+	).Scan(&artwork.Key, &artwork.URL, &artwork.ContentType, &artwork.Width, &artwork.Height, &artwork.Bytes,
+		&thumbnails, &artwork.UploadedBy, &artwork.UploadedAt)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return Artwork{}, ErrArtworkNotFound
+	}
+	if err != nil {
+		return Artwork{}, err
+	}
+	return artwork, artwork.Thumbnails.Scan(thumbnails)
+}
+
+func (r *CassandraArtworkRepository) Delete(albumID string) error {
+	if _, err := r.Get(albumID); err != nil {
+		return err
+	}
+	parsedUUID, _ := gocql.ParseUUID(albumID)
+	return r.session.Query("DELETE FROM album_artwork WHERE album_id = ?", parsedUUID).Exec()
+}
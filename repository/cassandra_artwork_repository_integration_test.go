@@ -0,0 +1,46 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCassandraArtworkRepository tests saving, replacing, reading and deleting an album's artwork.
+func TestCassandraArtworkRepository(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraArtworkRepository(session)
+	albumID := gocql.TimeUUID().String()
+
+	_, err := repo.Get(albumID)
+	require.True(t, errors.Is(err, ErrArtworkNotFound))
+	_, err = repo.Get("not-a-uuid")
+	require.True(t, errors.Is(err, ErrArtworkNotFound))
+
+	thumbnails := Thumbnails{{Size: 100, Key: "albums/1/a/100.png", URL: "/artwork/albums/1/a/100.png", ContentType: "image/png", Width: 100, Height: 100}}
+	saved, err := repo.Save(Artwork{AlbumID: albumID, Key: "albums/1/a/original.png", URL: "/artwork/albums/1/a/original.png",
+		ContentType: "image/png", Width: 800, Height: 800, Bytes: 1234, UploadedBy: "staff-1", Thumbnails: thumbnails})
+	require.NoError(t, err)
+	require.False(t, saved.UploadedAt.IsZero())
+	got, err := repo.Get(albumID)
+	require.NoError(t, err)
+	require.Equal(t, thumbnails, got.Thumbnails)
+	require.Equal(t, "staff-1", got.UploadedBy)
+
+	_, err = repo.Save(Artwork{AlbumID: albumID, Key: "albums/1/b/original.jpg", URL: "/artwork/albums/1/b/original.jpg",
+		ContentType: "image/jpeg", Width: 600, Height: 600, Bytes: 999, UploadedBy: "staff-2"})
+	require.NoError(t, err)
+	got, err = repo.Get(albumID)
+	require.NoError(t, err)
+	require.Equal(t, "albums/1/b/original.jpg", got.Key, "saving again replaces the artwork")
+	require.Empty(t, got.Thumbnails)
+
+	require.NoError(t, repo.Delete(albumID))
+	require.True(t, errors.Is(repo.Delete(albumID), ErrArtworkNotFound))
+}
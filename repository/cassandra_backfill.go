@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gocql/gocql"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// exactAmountColumns lists, for each table, its primary key and the double columns that each have a
+// decimal twin named with a _decimal suffix.
+var exactAmountColumns = []struct {
+	table   string
+	key     []string
+	amounts []string
+}{
+	{"albums", []string{"id"}, []string{"price"}},
+	{"orders", []string{"id"}, []string{"total", "discount"}},
+	{"order_lines", []string{"order_id", "line_no"}, []string{"unit_price", "line_total", "discount"}},
+	{"payment_intents", []string{"id"}, []string{"amount", "refunded_amount"}},
+	{"promotions", []string{"id"}, []string{"value"}},
+	{"price_history", []string{"album_id", "id"}, []string{"old_price", "new_price"}},
+	{"scheduled_prices", []string{"id"}, []string{"price"}},
+}
+
+// BackfillExactAmounts copies amounts written before the decimal columns existed from their double
+// columns, rounded to the nearest penny, and returns how many rows it filled in. Each row is filled
+// with a lightweight transaction on its decimal columns still being empty and its double columns
+// still holding what was read, so a row saved or deleted while the backfill runs is left alone, and
+// the backfill can safely be run again.
+func BackfillExactAmounts(session *gocql.Session) (int, error) {
+	filled := 0
+	for _, t := range exactAmountColumns {
+		decimals := make([]string, len(t.amounts))
+		for i, column := range t.amounts {
+			decimals[i] = column + "_decimal"
+		}
+		iter := session.Query(fmt.Sprintf("SELECT %s, %s, %s FROM %s",
+			strings.Join(t.key, ", "), strings.Join(t.amounts, ", "), strings.Join(decimals, ", "), t.table)).Iter()
+		for {
+			row := map[string]interface{}{}
+			for i, column := range t.amounts {
+				row[column] = new(*float64)
+				row[decimals[i]] = new(*money.Amount)
+			}
+			if !iter.MapScan(row) {
+				break
+			}
+			var set, where, conditions []string
+			var values []interface{}
+			var conditionValues []interface{}
+			for i, column := range t.amounts {
+				double := row[column].(*float64)
+				if double == nil || row[decimals[i]].(*money.Amount) != nil {
+					continue
+				}
+				set = append(set, decimals[i]+" = ?")
+				values = append(values, money.FromFloat(*double))
+				conditions = append(conditions, decimals[i]+" = null", column+" = ?")
+				conditionValues = append(conditionValues, *double)
+			}
+			if len(set) == 0 {
+				continue
+			}
+			for _, column := range t.key {
+				where = append(where, column+" = ?")
+				values = append(values, row[column])
+			}
+			values = append(values, conditionValues...)
+			applied, err := session.Query(fmt.Sprintf("UPDATE %s SET %s WHERE %s IF %s", t.table,
+				strings.Join(set, ", "), strings.Join(where, " AND "), strings.Join(conditions, " AND ")), values...,
+			).MapScanCAS(map[string]interface{}{})
+			if err != nil {
+				iter.Close()
+				return filled, fmt.Errorf("backfilling %s: %w", t.table, err)
+			}
+			if applied {
+				filled++
+			}
+		}
+		if err := iter.Close(); err != nil {
+			return filled, fmt.Errorf("backfilling %s: %w", t.table, err)
+		}
+	}
+	return filled, nil
+}
@@ -0,0 +1,65 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestCassandraBackfillExactAmounts tests that amounts saved only in the double columns are copied into
+// the decimal columns exactly, and that amounts already in the decimal columns are left alone.
+func TestCassandraBackfillExactAmounts(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	orders := NewCassandraOrderRepository(session, NewCassandraInventoryRepository(session))
+	payments := NewCassandraPaymentRepository(session)
+
+	legacyID := gocql.TimeUUID()
+	require.NoError(t, session.Query(
+		"INSERT INTO orders (id, user_id, status, total, discount, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		legacyID, "user-1", OrderPaid, 39.99, 0.1, time.Now().UTC(), time.Now().UTC(),
+	).Exec())
+	require.NoError(t, session.Query(
+		"INSERT INTO order_lines (order_id, line_no, album_id, format, title, artist, unit_price, quantity, line_total) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		legacyID, 0, gocql.TimeUUID(), FormatLP, "What's Going On", "Marvin Gaye", 13.33, 3, 39.99,
+	).Exec())
+	intentID := gocql.TimeUUID()
+	require.NoError(t, session.Query(
+		"INSERT INTO payment_intents (id, order_id, idempotency_key, provider, amount, refunded_amount, status) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		intentID, legacyID, "key-1", "mock", 42.5, 42.5, PaymentRefunded,
+	).Exec())
+
+	current, err := payments.Create(PaymentIntent{OrderID: legacyID.String(), IdempotencyKey: "key-2", Provider: "mock",
+		Amount: money.MustParse("12.34"), Status: PaymentCaptured})
+	require.NoError(t, err)
+
+	filled, err := BackfillExactAmounts(session)
+	require.NoError(t, err)
+	require.Equal(t, 3, filled, "the legacy order, its line and its payment")
+
+	var total *money.Amount
+	require.NoError(t, session.Query("SELECT total_decimal FROM orders WHERE id = ?", legacyID).Scan(&total))
+	require.NotNil(t, total)
+	require.Equal(t, money.MustParse("39.99"), *total)
+	order, err := orders.GetByID(legacyID.String())
+	require.NoError(t, err)
+	require.Equal(t, money.MustParse("0.10"), order.Discount)
+	require.Equal(t, money.MustParse("13.33"), order.Lines[0].UnitPrice)
+	require.Equal(t, money.MustParse("39.99"), order.Lines[0].LineTotal)
+	intent, err := payments.GetByID(intentID.String())
+	require.NoError(t, err)
+	require.Equal(t, money.MustParse("42.50"), intent.RefundedAmount)
+	intent, err = payments.GetByID(current.ID)
+	require.NoError(t, err)
+	require.Equal(t, money.MustParse("12.34"), intent.Amount)
+
+	filled, err = BackfillExactAmounts(session)
+	require.NoError(t, err)
+	require.Zero(t, filled, "running the backfill again changes nothing")
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// CassandraCartRepository stores each cart as a single row whose items live in a map keyed by
+// "albumID/format", or "albumID/format/releaseID" for releases. Every write re-inserts the whole row with a TTL, so idle carts expire on their own.
+type CassandraCartRepository struct {
+	session *gocql.Session
+	ttl     time.Duration
+}
+
+func NewCassandraCartRepository(session *gocql.Session, ttl time.Duration) *CassandraCartRepository {
+	return &CassandraCartRepository{session: session, ttl: ttl}
+}
+
+func cartItemKey(item CartItem) string {
+	key := item.AlbumID + "/" + string(item.Format)
+	if item.ReleaseID != "" {
+		key += "/" + item.ReleaseID
+	}
+	return key
+}
+
+// parseCartItemKey returns the item named by a key made with cartItemKey, without its quantity.
+func parseCartItemKey(key string) (CartItem, error) {
+	albumID, rest, ok := strings.Cut(key, "/")
+	if !ok {
+		return CartItem{}, fmt.Errorf("malformed cart item key %q", key)
+	}
+	format, releaseID, _ := strings.Cut(rest, "/")
+	return CartItem{AlbumID: albumID, ReleaseID: releaseID, Format: Format(format)}, nil
+}
+
+func (r *CassandraCartRepository) Create(cart Cart) (Cart, error) {
+	now := time.Now().UTC()
+	cart.ID = gocql.TimeUUID().String()
+	cart.CreatedAt = now
+	cart.UpdatedAt = now
+	if err := r.write(cart); err != nil {
+		return Cart{}, err
+	}
+	return cart, nil
+}
+
+func (r *CassandraCartRepository) GetByID(id string) (Cart, error) {
+	parsedUUID, err := gocql.ParseUUID(id)
+	if err != nil {
+		return Cart{}, ErrCartNotFound
+	}
+
+	var cart Cart
+	var cassandraID gocql.UUID
+	var items map[string]int
+	err = r.session.Query(
+		"SELECT id, user_id, items, coupon_code, created_at, updated_at FROM carts WHERE id = ?",
+		parsedUUID,
+	).Scan(&cassandraID, &cart.UserID, &items, &cart.CouponCode, &cart.CreatedAt, &cart.UpdatedAt)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return Cart{}, ErrCartNotFound
+	}
+	if err != nil {
+		return Cart{}, err
+	}
+
+	cart.ID = cassandraID.String()
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		item, err := parseCartItemKey(key)
+		if err != nil {
+			return Cart{}, err
+		}
+		item.Quantity = items[key]
+		cart.Items = append(cart.Items, item)
+	}
+	return cart, nil
+}
+
+func (r *CassandraCartRepository) GetByUser(userID string) (Cart, error) {
+	var cartID gocql.UUID
+	err := r.session.Query("SELECT cart_id FROM carts_by_user WHERE user_id = ?", userID).Scan(&cartID)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return Cart{}, ErrCartNotFound
+	}
+	if err != nil {
+		return Cart{}, err
+	}
+	return r.GetByID(cartID.String())
+}
+
+func (r *CassandraCartRepository) Save(cart Cart) error {
+	previous, err := r.GetByID(cart.ID)
+	if err != nil {
+		return err
+	}
+	if previous.UserID != "" && previous.UserID != cart.UserID {
+		if err := r.session.Query("DELETE FROM carts_by_user WHERE user_id = ?", previous.UserID).Exec(); err != nil {
+			return err
+		}
+	}
+	cart.CreatedAt = previous.CreatedAt
+	cart.UpdatedAt = time.Now().UTC()
+	return r.write(cart)
+}
+
+// write inserts the full cart row, refreshing the TTL of the cart and its owner lookup.
+func (r *CassandraCartRepository) write(cart Cart) error {
+	parsedUUID, err := gocql.ParseUUID(cart.ID)
+	if err != nil {
+		return err
+	}
+	items := make(map[string]int, len(cart.Items))
+	for _, item := range cart.Items {
+		items[cartItemKey(item)] = item.Quantity
+	}
+	ttl := int(r.ttl.Seconds())
+
+	if err := r.session.Query(
+		"INSERT INTO carts (id, user_id, items, coupon_code, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?) USING TTL ?",
+		parsedUUID, cart.UserID, items, cart.CouponCode, cart.CreatedAt, cart.UpdatedAt, ttl,
+	).Exec(); err != nil {
+		return err
+	}
+	if cart.UserID == "" {
+		return nil
+	}
+	return r.session.Query(
+		"INSERT INTO carts_by_user (user_id, cart_id) VALUES (?, ?) USING TTL ?",
+		cart.UserID, parsedUUID, ttl,
+	).Exec()
+}
+
+func (r *CassandraCartRepository) Delete(id string) error {
+	cart, err := r.GetByID(id)
+	if errors.Is(err, ErrCartNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if cart.UserID != "" {
+		if err := r.session.Query("DELETE FROM carts_by_user WHERE user_id = ?", cart.UserID).Exec(); err != nil {
+			return err
+		}
+	}
+	parsedUUID, _ := gocql.ParseUUID(cart.ID)
+	return r.session.Query("DELETE FROM carts WHERE id = ?", parsedUUID).Exec()
+}
+
+// DeleteIdleSince is a no-op for Cassandra: idle carts are removed by their TTL.
+func (r *CassandraCartRepository) DeleteIdleSince(cutoff time.Time) (int64, error) {
+	return 0, nil
+}
@@ -0,0 +1,75 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCassandraCartRepository_SaveAndGet tests creating a cart, replacing its items, claiming it for a user and deleting it.
+func TestCassandraCartRepository_SaveAndGet(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraCartRepository(session, time.Hour)
+	albumID := gocql.TimeUUID().String()
+
+	cart, err := repo.Create(Cart{Items: []CartItem{{AlbumID: albumID, Format: FormatLP, Quantity: 1}}})
+	require.NoError(t, err)
+	require.NotEmpty(t, cart.ID)
+
+	cart.UserID = "user-1"
+	cart.CouponCode = "SOUL10"
+	cart.Items = []CartItem{{AlbumID: albumID, Format: FormatCD, Quantity: 2}, {AlbumID: albumID, Format: FormatLP, Quantity: 1},
+		{AlbumID: albumID, ReleaseID: "6ba7b810-9dad-11d1-80b4-00c04fd430c8", Format: FormatLP, Quantity: 1}}
+	require.NoError(t, repo.Save(cart))
+
+	got, err := repo.GetByUser("user-1")
+	require.NoError(t, err)
+	require.Equal(t, cart.ID, got.ID)
+	require.Equal(t, "SOUL10", got.CouponCode)
+	require.Len(t, got.Items, 3, "a release is an item of its own")
+	require.Equal(t, FormatCD, got.Items[0].Format)
+	require.Equal(t, 2, got.Items[0].Quantity)
+	require.Equal(t, "6ba7b810-9dad-11d1-80b4-00c04fd430c8", got.Items[2].ReleaseID)
+
+	// Handing the cart to another user removes the first user's lookup.
+	got.UserID = "user-2"
+	require.NoError(t, repo.Save(got))
+	_, err = repo.GetByUser("user-1")
+	require.True(t, errors.Is(err, ErrCartNotFound))
+
+	require.NoError(t, repo.Delete(cart.ID))
+	_, err = repo.GetByID(cart.ID)
+	require.True(t, errors.Is(err, ErrCartNotFound))
+	_, err = repo.GetByUser("user-2")
+	require.True(t, errors.Is(err, ErrCartNotFound))
+	require.NoError(t, repo.Delete(cart.ID), "deleting a missing cart is not an error")
+
+	_, err = repo.GetByID("not-a-uuid")
+	require.True(t, errors.Is(err, ErrCartNotFound))
+}
+
+// TestCassandraCartRepository_Expires tests that carts and their owner lookups expire with their TTL.
+func TestCassandraCartRepository_Expires(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraCartRepository(session, 2*time.Second)
+
+	cart, err := repo.Create(Cart{UserID: "user-1"})
+	require.NoError(t, err)
+	_, err = repo.GetByUser("user-1")
+	require.NoError(t, err)
+
+	time.Sleep(3 * time.Second)
+
+	_, err = repo.GetByID(cart.ID)
+	require.True(t, errors.Is(err, ErrCartNotFound))
+	_, err = repo.GetByUser("user-1")
+	require.True(t, errors.Is(err, ErrCartNotFound))
+}
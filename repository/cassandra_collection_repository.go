@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// CassandraCollectionRepository creates collections with a lightweight transaction, so two
+// collections can never be given the same ID.
+type CassandraCollectionRepository struct {
+	session *gocql.Session
+}
+
+func NewCassandraCollectionRepository(session *gocql.Session) *CassandraCollectionRepository {
+	return &CassandraCollectionRepository{session: session}
+}
+
+const cassandraCollectionColumns = "id, title, description, cover_url, kind, album_ids, rules, created_at, updated_at"
+
+func (r *CassandraCollectionRepository) Create(collection Collection) (Collection, error) {
+	collection.Normalize()
+	collection.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	collection.UpdatedAt = collection.CreatedAt
+	applied, err := r.session.Query(
+		"INSERT INTO collections ("+cassandraCollectionColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) IF NOT EXISTS",
+		collection.ID, collection.Title, collection.Description, collection.CoverURL, string(collection.Kind),
+		[]string(collection.AlbumIDs), collection.Rules.String(), collection.CreatedAt, collection.UpdatedAt,
+	).MapScanCAS(map[string]interface{}{})
+	if err != nil {
+		return Collection{}, err
+	}
+	if !applied {
+		return Collection{}, ErrDuplicateCollection
+	}
+	return collection, nil
+}
+
+func (r *CassandraCollectionRepository) GetByID(id string) (Collection, error) {
+	collection, err := scanCollection(r.session.Query("SELECT "+cassandraCollectionColumns+" FROM collections WHERE id = ?", id).Scan)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return Collection{}, ErrCollectionNotFound
+	}
+	return collection, err
+}
+
+// scanCollection reads one row selected with cassandraCollectionColumns using the given scan function.
+func scanCollection(scan func(dest ...interface{}) error) (Collection, error) {
+	var collection Collection
+	var kind, rules string
+	var albumIDs []string
+	if err := scan(&collection.ID, &collection.Title, &collection.Description, &collection.CoverURL, &kind,
+		&albumIDs, &rules, &collection.CreatedAt, &collection.UpdatedAt); err != nil {
+		return Collection{}, err
+	}
+	collection.Kind = CollectionKind(kind)
+	collection.AlbumIDs = append(AlbumIDs{}, albumIDs...)
+	if err := collection.Rules.Scan(rules); err != nil {
+		return Collection{}, err
+	}
+	return collection, nil
+}
+
+func (r *CassandraCollectionRepository) List() ([]Collection, error) {
+	var collections []Collection
+	iter := r.session.Query("SELECT " + cassandraCollectionColumns + " FROM collections").Iter()
+	scanner := iter.Scanner()
+	for scanner.Next() {
+		collection, err := scanCollection(scanner.Scan)
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, collection)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sortCollections(collections)
+	return collections, nil
+}
+
+func (r *CassandraCollectionRepository) Update(collection Collection) (Collection, error) {
+	current, err := r.GetByID(collection.ID)
+	if err != nil {
+		return Collection{}, err
+	}
+	collection.Normalize()
+	collection.CreatedAt = current.CreatedAt
+	collection.UpdatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	if err := r.session.Query(
+		`UPDATE collections SET title = ?, description = ?, cover_url = ?, kind = ?, album_ids = ?, rules = ?,
+		 updated_at = ? WHERE id = ?`,
+		collection.Title, collection.Description, collection.CoverURL, string(collection.Kind),
+		[]string(collection.AlbumIDs), collection.Rules.String(), collection.UpdatedAt, collection.ID,
+	).Exec(); err != nil {
+		return Collection{}, err
+	}
+	return collection, nil
+}
+
+func (r *CassandraCollectionRepository) Delete(id string) error {
+	if _, err := r.GetByID(id); err != nil {
+		return err
+	}
+	return r.session.Query("DELETE FROM collections WHERE id = ?", id).Exec()
+}
@@ -0,0 +1,126 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestCassandraCollectionRepository tests storing curated and smart collections, keeping album order and rules.
+func TestCassandraCollectionRepository(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraCollectionRepository(session)
+
+	picks, err := repo.Create(Collection{Title: "Staff Picks", Description: "Chosen by the shop", AlbumIDs: AlbumIDs{"3", "1", "2"}})
+	require.NoError(t, err)
+	require.Equal(t, "staff-picks", picks.ID)
+	require.Equal(t, CollectionCurated, picks.Kind)
+	_, err = repo.Create(Collection{Title: "Staff picks!"})
+	require.True(t, errors.Is(err, ErrDuplicateCollection))
+
+	rules, err := ParseCollectionRules("genre=Soul AND year<1975")
+	require.NoError(t, err)
+	soul, err := repo.Create(Collection{Title: "Early Soul", Rules: rules})
+	require.NoError(t, err)
+	require.Equal(t, CollectionSmart, soul.Kind)
+
+	found, err := repo.GetByID(soul.ID)
+	require.NoError(t, err)
+	require.Equal(t, rules, found.Rules)
+	found, err = repo.GetByID(picks.ID)
+	require.NoError(t, err)
+	require.Equal(t, AlbumIDs{"3", "1", "2"}, found.AlbumIDs, "curated albums keep their order")
+
+	picks.AlbumIDs = AlbumIDs{"2"}
+	picks.Title = "Shop Favourites"
+	updated, err := repo.Update(picks)
+	require.NoError(t, err)
+	require.Equal(t, "staff-picks", updated.ID)
+	require.Equal(t, AlbumIDs{"2"}, updated.AlbumIDs)
+	_, err = repo.Update(Collection{ID: "missing", Title: "Missing"})
+	require.True(t, errors.Is(err, ErrCollectionNotFound))
+
+	collections, err := repo.List()
+	require.NoError(t, err)
+	require.Len(t, collections, 2)
+	require.Equal(t, "Early Soul", collections[0].Title)
+
+	require.NoError(t, repo.Delete(picks.ID))
+	_, err = repo.GetByID(picks.ID)
+	require.True(t, errors.Is(err, ErrCollectionNotFound))
+	require.True(t, errors.Is(repo.Delete(picks.ID), ErrCollectionNotFound))
+}
+
+// TestCassandraCollectionRepository_ConcurrentCreate tests that the lightweight transaction on collections
+// lets only one of several collections created at once with the same ID exist.
+func TestCassandraCollectionRepository_ConcurrentCreate(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraCollectionRepository(session)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = repo.Create(Collection{Title: "Northern Soul Floorfillers"})
+		}()
+	}
+	wg.Wait()
+
+	created := 0
+	for _, err := range errs {
+		if err == nil {
+			created++
+		} else {
+			require.True(t, errors.Is(err, ErrDuplicateCollection), err)
+		}
+	}
+	require.Equal(t, 1, created)
+	collections, err := repo.List()
+	require.NoError(t, err)
+	require.Len(t, collections, 1)
+}
+
+// TestCassandraAlbumRepository_Tags tests finding albums by tag, counting tags and keeping the tag index
+// in step as albums are retagged.
+func TestCassandraAlbumRepository_Tags(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraAlbumRepository(session)
+
+	require.NoError(t, repo.Create(Album{Title: "What's Going On", Artist: "Marvin Gaye", Price: money.MustParse("9.99"), Year: 1971, ImageUrl: "x", Genre: "Soul", Tags: Tags{"staff pick", "protest"}}))
+	require.NoError(t, repo.Create(Album{Title: "Superfly", Artist: "Curtis Mayfield", Price: money.MustParse("8.99"), Year: 1972, ImageUrl: "x", Genre: "Soul", Tags: Tags{"staff pick"}}))
+	require.NoError(t, repo.Create(Album{Title: "Untagged", Artist: "Nobody", Price: money.MustParse("1.00"), Year: 2000, ImageUrl: "x", Genre: "Pop"}))
+
+	albums, err := repo.GetByTag("staff pick")
+	require.NoError(t, err)
+	require.Len(t, albums, 2)
+	albums, err = repo.GetByTag("protest")
+	require.NoError(t, err)
+	require.Len(t, albums, 1)
+	require.Equal(t, Tags{"staff pick", "protest"}, albums[0].Tags)
+
+	counts, err := repo.TagCounts()
+	require.NoError(t, err)
+	require.Equal(t, []TagCount{{Tag: "staff pick", Count: 2}, {Tag: "protest", Count: 1}}, counts)
+
+	album := albums[0]
+	album.Tags = Tags{"protest"}
+	require.NoError(t, repo.Update(album))
+	albums, err = repo.GetByTag("staff pick")
+	require.NoError(t, err)
+	require.Len(t, albums, 1, "dropped tags leave the index")
+	require.NoError(t, repo.Delete(album.ID))
+	albums, err = repo.GetByTag("protest")
+	require.NoError(t, err)
+	require.Empty(t, albums)
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+type CassandraExchangeRateRepository struct {
+	session *gocql.Session
+}
+
+func NewCassandraExchangeRateRepository(session *gocql.Session) *CassandraExchangeRateRepository {
+	return &CassandraExchangeRateRepository{session: session}
+}
+
+func (r *CassandraExchangeRateRepository) List() ([]ExchangeRate, error) {
+	iter := r.session.Query("SELECT currency, rate, round_to, updated_at FROM exchange_rates").Iter()
+	var rates []ExchangeRate
+	var rate ExchangeRate
+	for iter.Scan(&rate.Currency, &rate.Rate, &rate.RoundTo, &rate.UpdatedAt) {
+		rates = append(rates, rate)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	sortExchangeRates(rates)
+	return rates, nil
+}
+
+// Replace writes the new table in a single logged batch, so readers see either the old rates or the new ones.
+func (r *CassandraExchangeRateRepository) Replace(rates []ExchangeRate) ([]ExchangeRate, error) {
+	existing, err := r.List()
+	if err != nil {
+		return nil, err
+	}
+	kept := make(map[string]bool, len(rates))
+	for _, rate := range rates {
+		kept[rate.Currency] = true
+	}
+	batch := r.session.NewBatch(gocql.LoggedBatch)
+	for _, rate := range existing {
+		// Statements in a batch share a timestamp and a delete beats an insert at the same timestamp,
+		// so only currencies that are being dropped are deleted.
+		if !kept[rate.Currency] {
+			batch.Query("DELETE FROM exchange_rates WHERE currency = ?", rate.Currency)
+		}
+	}
+	now := time.Now().UTC()
+	for _, rate := range rates {
+		batch.Query("INSERT INTO exchange_rates (currency, rate, round_to, updated_at) VALUES (?, ?, ?, ?)",
+			rate.Currency, rate.Rate, rate.RoundTo, now)
+	}
+	if err := r.session.ExecuteBatch(batch); err != nil {
+		return nil, err
+	}
+	return r.List()
+}
@@ -0,0 +1,37 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestCassandraExchangeRateRepository_Replace tests that each upload replaces the whole rate table.
+func TestCassandraExchangeRateRepository_Replace(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraExchangeRateRepository(session)
+
+	rates, err := repo.Replace([]ExchangeRate{{Currency: "USD", Rate: 1.27}, {Currency: "EUR", Rate: 1.17, RoundTo: money.MustParse("0.05")}})
+	require.NoError(t, err)
+	require.Len(t, rates, 2)
+	require.Equal(t, "EUR", rates[0].Currency)
+	require.Equal(t, 1.17, rates[0].Rate)
+	require.Equal(t, money.MustParse("0.05"), rates[0].RoundTo)
+
+	// A currency kept in the next upload takes its new rate rather than being deleted in the same batch.
+	rates, err = repo.Replace([]ExchangeRate{{Currency: "USD", Rate: 1.30}, {Currency: "JPY", Rate: 190.5}})
+	require.NoError(t, err)
+	require.Len(t, rates, 2, "currencies left out of the upload are removed")
+	require.Equal(t, "JPY", rates[0].Currency)
+	require.Equal(t, "USD", rates[1].Currency)
+	require.Equal(t, 1.30, rates[1].Rate)
+
+	rates, err = repo.Replace(nil)
+	require.NoError(t, err)
+	require.Empty(t, rates)
+}
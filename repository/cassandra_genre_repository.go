@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// CassandraGenreRepository claims the key of every name and alias a genre goes by in genres_by_name
+// with a lightweight transaction, so two genres can never share a name. Cassandra cannot check that
+// a genre has no sub-genres or albums before it is deleted; callers must.
+type CassandraGenreRepository struct {
+	session *gocql.Session
+}
+
+func NewCassandraGenreRepository(session *gocql.Session) *CassandraGenreRepository {
+	return &CassandraGenreRepository{session: session}
+}
+
+const cassandraGenreColumns = "id, name, parent_id, aliases, created_at, updated_at"
+
+func (r *CassandraGenreRepository) Create(genre Genre) (Genre, error) {
+	genre.Normalize()
+	genre.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	genre.UpdatedAt = genre.CreatedAt
+	claimed, err := r.claimNames(genre.ID, genre.nameKeys())
+	if err != nil {
+		return Genre{}, err
+	}
+	applied, err := r.session.Query(
+		"INSERT INTO genres ("+cassandraGenreColumns+") VALUES (?, ?, ?, ?, ?, ?) IF NOT EXISTS",
+		genre.ID, genre.Name, genre.ParentID, []string(genre.Aliases), genre.CreatedAt, genre.UpdatedAt,
+	).MapScanCAS(map[string]interface{}{})
+	if err == nil && !applied {
+		// A renamed genre still has the ID this one would be given.
+		err = ErrDuplicateGenre
+	}
+	if err != nil {
+		r.releaseNames(genre.ID, claimed)
+		return Genre{}, err
+	}
+	return genre, nil
+}
+
+// claimNames records that the genre goes by each of keys, returning the keys it did not already
+// have. If another genre has any of them, the new claims are given up and ErrDuplicateGenre is returned.
+func (r *CassandraGenreRepository) claimNames(id string, keys []string) ([]string, error) {
+	var claimed []string
+	for _, key := range keys {
+		var existingKey, owner string
+		applied, err := r.session.Query(
+			"INSERT INTO genres_by_name (name_key, genre_id) VALUES (?, ?) IF NOT EXISTS",
+			key, id,
+		).ScanCAS(&existingKey, &owner)
+		if err == nil && !applied && owner != id {
+			err = ErrDuplicateGenre
+		}
+		if err != nil {
+			r.releaseNames(id, claimed)
+			return nil, err
+		}
+		if applied {
+			claimed = append(claimed, key)
+		}
+	}
+	return claimed, nil
+}
+
+// releaseNames gives up the genre's claim to each of keys, leaving any that another genre holds.
+func (r *CassandraGenreRepository) releaseNames(id string, keys []string) {
+	for _, key := range keys {
+		_ = r.session.Query("DELETE FROM genres_by_name WHERE name_key = ? IF genre_id = ?", key, id).Exec()
+	}
+}
+
+func (r *CassandraGenreRepository) GetByID(id string) (Genre, error) {
+	genre, err := scanGenre(r.session.Query("SELECT "+cassandraGenreColumns+" FROM genres WHERE id = ?", id).Scan)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return Genre{}, ErrGenreNotFound
+	}
+	return genre, err
+}
+
+// scanGenre reads one row selected with cassandraGenreColumns using the given scan function.
+func scanGenre(scan func(dest ...interface{}) error) (Genre, error) {
+	var genre Genre
+	var aliases []string
+	if err := scan(&genre.ID, &genre.Name, &genre.ParentID, &aliases, &genre.CreatedAt, &genre.UpdatedAt); err != nil {
+		return Genre{}, err
+	}
+	genre.Aliases = append(Aliases{}, aliases...)
+	return genre, nil
+}
+
+func (r *CassandraGenreRepository) List() ([]Genre, error) {
+	var genres []Genre
+	iter := r.session.Query("SELECT " + cassandraGenreColumns + " FROM genres").Iter()
+	scanner := iter.Scanner()
+	for scanner.Next() {
+		genre, err := scanGenre(scanner.Scan)
+		if err != nil {
+			return nil, err
+		}
+		genres = append(genres, genre)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sortGenres(genres)
+	return genres, nil
+}
+
+func (r *CassandraGenreRepository) Update(genre Genre) (Genre, error) {
+	current, err := r.GetByID(genre.ID)
+	if err != nil {
+		return Genre{}, err
+	}
+	genre.Normalize()
+	claimed, err := r.claimNames(genre.ID, genre.nameKeys())
+	if err != nil {
+		return Genre{}, err
+	}
+	genre.CreatedAt = current.CreatedAt
+	genre.UpdatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	if err := r.session.Query(
+		"UPDATE genres SET name = ?, parent_id = ?, aliases = ?, updated_at = ? WHERE id = ?",
+		genre.Name, genre.ParentID, []string(genre.Aliases), genre.UpdatedAt, genre.ID,
+	).Exec(); err != nil {
+		r.releaseNames(genre.ID, claimed)
+		return Genre{}, err
+	}
+
+	keep := make(map[string]bool)
+	for _, key := range genre.nameKeys() {
+		keep[key] = true
+	}
+	var dropped []string
+	for _, key := range current.nameKeys() {
+		if !keep[key] {
+			dropped = append(dropped, key)
+		}
+	}
+	r.releaseNames(genre.ID, dropped)
+	return genre, nil
+}
+
+func (r *CassandraGenreRepository) Delete(id string) error {
+	genre, err := r.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := r.session.Query("DELETE FROM genres WHERE id = ?", id).Exec(); err != nil {
+		return err
+	}
+	r.releaseNames(id, genre.nameKeys())
+	return nil
+}
@@ -0,0 +1,83 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestCassandraGenreRepository_Taxonomy tests the seeded taxonomy and keeping names unique.
+func TestCassandraGenreRepository_Taxonomy(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraGenreRepository(session)
+
+	genres, err := repo.List()
+	require.NoError(t, err)
+	tree := NewGenreTree(genres)
+	soul, ok := tree.Lookup("R&B/Soul")
+	require.True(t, ok, "the migration seeds the taxonomy")
+	require.True(t, tree.Includes(soul.ID, "Northern Soul"))
+
+	modern, err := repo.Create(Genre{Name: "Modern Soul", ParentID: "northern-soul"})
+	require.NoError(t, err)
+	require.Equal(t, "modern-soul", modern.ID)
+	_, err = repo.Create(Genre{Name: "modern  soul"})
+	require.True(t, errors.Is(err, ErrDuplicateGenre))
+	_, err = repo.Create(Genre{Name: "Crossover", Aliases: Aliases{"Motown"}})
+	require.True(t, errors.Is(err, ErrDuplicateGenre))
+	_, err = repo.Create(Genre{Name: "Crossover"})
+	require.NoError(t, err, "a failed create gives up the names it claimed")
+
+	modern.Aliases = Aliases{"Rare Soul"}
+	modern.ParentID = "soul"
+	updated, err := repo.Update(modern)
+	require.NoError(t, err)
+	require.Equal(t, "soul", updated.ParentID)
+	_, err = repo.Update(Genre{ID: "missing", Name: "Missing"})
+	require.True(t, errors.Is(err, ErrGenreNotFound))
+
+	require.NoError(t, repo.Delete(modern.ID))
+	_, err = repo.GetByID(modern.ID)
+	require.True(t, errors.Is(err, ErrGenreNotFound))
+	require.True(t, errors.Is(repo.Delete(modern.ID), ErrGenreNotFound))
+	_, err = repo.Create(Genre{Name: "Rare Soul"})
+	require.NoError(t, err, "a deleted genre's names are free again")
+}
+
+// TestCassandraGenreRepository_ConcurrentCreate tests that the lightweight transactions on genres_by_name
+// let only one of several genres created at once with the same name exist.
+func TestCassandraGenreRepository_ConcurrentCreate(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraGenreRepository(session)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = repo.Create(Genre{Name: "Philly Soul", ParentID: "soul"})
+		}()
+	}
+	wg.Wait()
+
+	created := 0
+	for _, err := range errs {
+		if err == nil {
+			created++
+		} else {
+			require.True(t, errors.Is(err, ErrDuplicateGenre), err)
+		}
+	}
+	require.Equal(t, 1, created)
+	genre, err := repo.GetByID("philly-soul")
+	require.NoError(t, err)
+	require.Equal(t, "Philly Soul", genre.Name)
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// CassandraGiftCardRepository keeps lookup tables of gift cards by code hash and of store credit by
+// customer, each claimed with a lightweight transaction. Balances change by compare-and-set, so two
+// redemptions racing for the last of a balance cannot both succeed; each change is then written to
+// the card's ledger.
+type CassandraGiftCardRepository struct {
+	session *gocql.Session
+}
+
+func NewCassandraGiftCardRepository(session *gocql.Session) *CassandraGiftCardRepository {
+	return &CassandraGiftCardRepository{session: session}
+}
+
+const cassandraGiftCardColumns = "id, kind, code_hash, last4, user_id, initial_balance, balance, created_at, updated_at"
+
+func (r *CassandraGiftCardRepository) Create(code string, initialBalance money.Amount, actor, note string) (GiftCard, error) {
+	card := r.newCard(GiftCardVoucher, initialBalance)
+	card.CodeHash = HashGiftCardCode(code)
+	card.Last4 = lastFour(code)
+	id, _ := gocql.ParseUUID(card.ID)
+
+	var existingHash string
+	var existingID gocql.UUID
+	applied, err := r.session.Query(
+		"INSERT INTO gift_cards_by_code (code_hash, id) VALUES (?, ?) IF NOT EXISTS", card.CodeHash, id,
+	).ScanCAS(&existingHash, &existingID)
+	if err != nil {
+		return GiftCard{}, err
+	}
+	if !applied {
+		return GiftCard{}, ErrDuplicateGiftCardCode
+	}
+	return card, r.insert(card, GiftCardEntry{Amount: initialBalance, Reason: EntryIssued, Actor: actor, Note: note})
+}
+
+func (r *CassandraGiftCardRepository) newCard(kind GiftCardKind, balance money.Amount) GiftCard {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	return GiftCard{
+		ID: gocql.TimeUUID().String(), Kind: kind, InitialBalance: balance, Balance: balance,
+		CreatedAt: now, UpdatedAt: now,
+	}
+}
+
+// insert writes a new card and the entry that opened it.
+func (r *CassandraGiftCardRepository) insert(card GiftCard, entry GiftCardEntry) error {
+	id, _ := gocql.ParseUUID(card.ID)
+	if err := r.session.Query(
+		"INSERT INTO gift_cards ("+cassandraGiftCardColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		id, card.Kind, card.CodeHash, card.Last4, card.UserID, card.InitialBalance, card.Balance, card.CreatedAt, card.UpdatedAt,
+	).Exec(); err != nil {
+		return err
+	}
+	return r.insertEntry(id, card.Balance, entry)
+}
+
+func (r *CassandraGiftCardRepository) insertEntry(cardID gocql.UUID, balance money.Amount, entry GiftCardEntry) error {
+	return r.session.Query(
+		`INSERT INTO gift_card_entries (gift_card_id, id, amount, balance, reason, order_id, actor, note, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		cardID, gocql.TimeUUID(), entry.Amount, balance, entry.Reason, entry.OrderID, entry.Actor, entry.Note,
+		time.Now().UTC(),
+	).Exec()
+}
+
+func (r *CassandraGiftCardRepository) GetByID(id string) (GiftCard, error) {
+	parsedUUID, err := gocql.ParseUUID(id)
+	if err != nil {
+		return GiftCard{}, ErrGiftCardNotFound
+	}
+	card, err := scanGiftCard(r.session.Query("SELECT "+cassandraGiftCardColumns+" FROM gift_cards WHERE id = ?", parsedUUID).Scan)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return GiftCard{}, ErrGiftCardNotFound
+	}
+	return card, err
+}
+
+// scanGiftCard reads one row selected with cassandraGiftCardColumns using the given scan function.
+func scanGiftCard(scan func(dest ...interface{}) error) (GiftCard, error) {
+	var card GiftCard
+	var id gocql.UUID
+	if err := scan(&id, &card.Kind, &card.CodeHash, &card.Last4, &card.UserID, &card.InitialBalance,
+		&card.Balance, &card.CreatedAt, &card.UpdatedAt); err != nil {
+		return GiftCard{}, err
+	}
+	card.ID = id.String()
+	return card, nil
+}
+
+func (r *CassandraGiftCardRepository) GetByCode(code string) (GiftCard, error) {
+	return r.lookup("SELECT id FROM gift_cards_by_code WHERE code_hash = ?", HashGiftCardCode(code))
+}
+
+func (r *CassandraGiftCardRepository) GetStoreCredit(userID string) (GiftCard, error) {
+	return r.lookup("SELECT id FROM store_credit_by_user WHERE user_id = ?", userID)
+}
+
+func (r *CassandraGiftCardRepository) lookup(query, arg string) (GiftCard, error) {
+	var id gocql.UUID
+	err := r.session.Query(query, arg).Scan(&id)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return GiftCard{}, ErrGiftCardNotFound
+	}
+	if err != nil {
+		return GiftCard{}, err
+	}
+	return r.GetByID(id.String())
+}
+
+func (r *CassandraGiftCardRepository) List(kind GiftCardKind) ([]GiftCard, error) {
+	var cards []GiftCard
+	scanner := r.session.Query("SELECT " + cassandraGiftCardColumns + " FROM gift_cards").Iter().Scanner()
+	for scanner.Next() {
+		card, err := scanGiftCard(scanner.Scan)
+		if err != nil {
+			return nil, err
+		}
+		if kind == "" || card.Kind == kind {
+			cards = append(cards, card)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(cards, func(i, j int) bool { return cards[i].CreatedAt.After(cards[j].CreatedAt) })
+	return cards, nil
+}
+
+func (r *CassandraGiftCardRepository) Apply(id string, entry GiftCardEntry) (GiftCard, error) {
+	card, err := r.GetByID(id)
+	if err != nil {
+		return GiftCard{}, err
+	}
+	parsedUUID, _ := gocql.ParseUUID(id)
+	for range maxCASAttempts {
+		next := card.Balance + entry.Amount
+		if next < 0 {
+			return GiftCard{}, ErrInsufficientBalance
+		}
+		now := time.Now().UTC().Truncate(time.Millisecond)
+		applied, err := r.session.Query(
+			"UPDATE gift_cards SET balance = ?, updated_at = ? WHERE id = ? IF balance = ?",
+			next, now, parsedUUID, card.Balance,
+		).ScanCAS(&card.Balance)
+		if err != nil {
+			return GiftCard{}, err
+		}
+		if applied {
+			card.Balance = next
+			card.UpdatedAt = now
+			return card, r.insertEntry(parsedUUID, next, entry)
+		}
+		// Not applied: ScanCAS has loaded the winning balance into card, so try again from there.
+	}
+	return GiftCard{}, fmt.Errorf("gift card %s is being updated concurrently, please retry", id)
+}
+
+func (r *CassandraGiftCardRepository) AddStoreCredit(userID string, entry GiftCardEntry) (GiftCard, error) {
+	card := r.newCard(StoreCredit, entry.Amount)
+	card.UserID = userID
+	id, _ := gocql.ParseUUID(card.ID)
+
+	var existingUserID string
+	var existingID gocql.UUID
+	applied, err := r.session.Query(
+		"INSERT INTO store_credit_by_user (user_id, id) VALUES (?, ?) IF NOT EXISTS", userID, id,
+	).ScanCAS(&existingUserID, &existingID)
+	if err != nil {
+		return GiftCard{}, err
+	}
+	if !applied {
+		return r.Apply(existingID.String(), entry)
+	}
+	return card, r.insert(card, entry)
+}
+
+func (r *CassandraGiftCardRepository) Entries(id string) ([]GiftCardEntry, error) {
+	parsedUUID, err := gocql.ParseUUID(id)
+	if err != nil {
+		return nil, ErrGiftCardNotFound
+	}
+	var entries []GiftCardEntry
+	var entry GiftCardEntry
+	var entryID gocql.UUID
+	iter := r.session.Query(
+		"SELECT id, amount, balance, reason, order_id, actor, note, created_at FROM gift_card_entries WHERE gift_card_id = ?",
+		parsedUUID,
+	).Iter()
+	for iter.Scan(&entryID, &entry.Amount, &entry.Balance, &entry.Reason, &entry.OrderID, &entry.Actor, &entry.Note, &entry.CreatedAt) {
+		entry.ID = entryID.String()
+		entry.GiftCardID = id
+		entries = append(entries, entry)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
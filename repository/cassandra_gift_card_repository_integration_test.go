@@ -0,0 +1,92 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestCassandraGiftCardRepository_Ledger tests that balances never go below zero and every change is in the ledger.
+func TestCassandraGiftCardRepository_Ledger(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraGiftCardRepository(session)
+
+	card, err := repo.Create("ABCD-2345-EFGH-6789", money.MustParse("25.00"), "staff-1", "Christmas")
+	require.NoError(t, err)
+	require.Equal(t, "6789", card.Last4)
+	_, err = repo.Create("abcd2345efgh6789", money.MustParse("10.00"), "staff-1", "")
+	require.True(t, errors.Is(err, ErrDuplicateGiftCardCode))
+
+	found, err := repo.GetByCode("abcd 2345 efgh 6789")
+	require.NoError(t, err)
+	require.Equal(t, card.ID, found.ID)
+
+	card, err = repo.Apply(card.ID, GiftCardEntry{Amount: -money.MustParse("20.00"), Reason: EntryRedeemed, OrderID: "order-1"})
+	require.NoError(t, err)
+	require.Equal(t, money.MustParse("5.00"), card.Balance)
+	_, err = repo.Apply(card.ID, GiftCardEntry{Amount: -money.MustParse("5.01"), Reason: EntryRedeemed})
+	require.True(t, errors.Is(err, ErrInsufficientBalance))
+
+	entries, err := repo.Entries(card.ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, money.MustParse("25.00"), entries[0].Balance)
+	require.Equal(t, "order-1", entries[1].OrderID)
+
+	credit, err := repo.AddStoreCredit("user-1", GiftCardEntry{Amount: money.MustParse("12.00"), Reason: EntryRefunded})
+	require.NoError(t, err)
+	credit, err = repo.AddStoreCredit("user-1", GiftCardEntry{Amount: money.MustParse("3.00"), Reason: EntryRefunded})
+	require.NoError(t, err)
+	require.Equal(t, money.MustParse("15.00"), credit.Balance)
+	found, err = repo.GetStoreCredit("user-1")
+	require.NoError(t, err)
+	require.Equal(t, credit.ID, found.ID)
+
+	vouchers, err := repo.List(GiftCardVoucher)
+	require.NoError(t, err)
+	require.Len(t, vouchers, 1)
+
+	_, err = repo.GetByID("not-a-uuid")
+	require.True(t, errors.Is(err, ErrGiftCardNotFound))
+}
+
+// TestCassandraGiftCardRepository_ConcurrentApply tests that the compare-and-set on the balance never lets
+// racing redemptions spend more than the card holds, and that every one that succeeds is in the ledger.
+func TestCassandraGiftCardRepository_ConcurrentApply(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraGiftCardRepository(session)
+	card, err := repo.Create("WXYZ-2345", money.MustParse("30.00"), "staff-1", "")
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	redeemed := 0
+	for range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := repo.Apply(card.ID, GiftCardEntry{Amount: -money.MustParse("10.00"), Reason: EntryRedeemed}); err == nil {
+				mu.Lock()
+				redeemed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	found, err := repo.GetByID(card.ID)
+	require.NoError(t, err)
+	require.LessOrEqual(t, redeemed, 3)
+	require.Equal(t, money.MustParse("30.00")-money.MustParse("10.00").Mul(redeemed), found.Balance)
+	entries, err := repo.Entries(card.ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 1+redeemed, "every successful redemption is in the ledger once")
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/gocql/gocql"
+)
+
+// maxCASAttempts bounds how many times a lightweight transaction is retried when another writer wins the race.
+const maxCASAttempts = 10
+
+type CassandraInventoryRepository struct {
+	session *gocql.Session
+}
+
+func NewCassandraInventoryRepository(session *gocql.Session) *CassandraInventoryRepository {
+	return &CassandraInventoryRepository{session: session}
+}
+
+func (r *CassandraInventoryRepository) GetStock(albumID string) ([]StockLevel, error) {
+	parsedUUID, err := gocql.ParseUUID(albumID)
+	if err != nil {
+		return nil, err
+	}
+	return r.scanStock(r.session.Query("SELECT album_id, format, on_hand, reserved FROM inventory WHERE album_id = ?", parsedUUID).Iter())
+}
+
+func (r *CassandraInventoryRepository) GetAllStock() ([]StockLevel, error) {
+	return r.scanStock(r.session.Query("SELECT album_id, format, on_hand, reserved FROM inventory").Iter())
+}
+
+func (r *CassandraInventoryRepository) scanStock(iter *gocql.Iter) ([]StockLevel, error) {
+	var levels []StockLevel
+	var cassandraID gocql.UUID
+	for {
+		var level StockLevel
+		if !iter.Scan(&cassandraID, &level.Format, &level.OnHand, &level.Reserved) {
+			break
+		}
+		level.AlbumID = cassandraID.String()
+		level.Available = level.OnHand - level.Reserved
+		levels = append(levels, level)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	return levels, nil
+}
+
+// compareAndSet applies change to the current stock row using a lightweight transaction,
+// retrying with the fresh values whenever a concurrent writer got there first.
+func (r *CassandraInventoryRepository) compareAndSet(albumID gocql.UUID, format Format, change func(StockLevel) (StockLevel, error)) (StockLevel, error) {
+	// Make sure the row exists so the conditional update below has something to compare against.
+	if _, err := r.session.Query(
+		"INSERT INTO inventory (album_id, format, on_hand, reserved) VALUES (?, ?, 0, 0) IF NOT EXISTS",
+		albumID, format,
+	).ScanCAS(new(gocql.UUID), new(string), new(int), new(int)); err != nil {
+		return StockLevel{}, err
+	}
+
+	current := StockLevel{AlbumID: albumID.String(), Format: format}
+	if err := r.session.Query(
+		"SELECT on_hand, reserved FROM inventory WHERE album_id = ? AND format = ?",
+		albumID, format,
+	).Scan(&current.OnHand, &current.Reserved); err != nil {
+		return StockLevel{}, err
+	}
+
+	for range maxCASAttempts {
+		next, err := change(current)
+		if err != nil {
+			current.Available = current.OnHand - current.Reserved
+			return current, err
+		}
+		applied, err := r.session.Query(
+			"UPDATE inventory SET on_hand = ?, reserved = ? WHERE album_id = ? AND format = ? IF on_hand = ? AND reserved = ?",
+			next.OnHand, next.Reserved, albumID, format, current.OnHand, current.Reserved,
+		).ScanCAS(&current.OnHand, &current.Reserved)
+		if err != nil {
+			return StockLevel{}, err
+		}
+		if applied {
+			return next, nil
+		}
+		// Not applied: ScanCAS has loaded the winning values into current, so try again from there.
+	}
+	return StockLevel{}, fmt.Errorf("stock for album %s (%s) is being updated concurrently, please retry", albumID, format)
+}
+
+func (r *CassandraInventoryRepository) Adjust(adjustment StockAdjustment) (StockLevel, error) {
+	parsedUUID, err := gocql.ParseUUID(adjustment.AlbumID)
+	if err != nil {
+		return StockLevel{}, err
+	}
+	level, err := r.compareAndSet(parsedUUID, adjustment.Format, func(level StockLevel) (StockLevel, error) {
+		return applyAdjustment(level, adjustment.Delta)
+	})
+	if err != nil {
+		return level, err
+	}
+	err = r.session.Query(
+		"INSERT INTO stock_adjustments (album_id, id, format, delta, reason, note, actor) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		parsedUUID, gocql.TimeUUID(), adjustment.Format, adjustment.Delta, adjustment.Reason, adjustment.Note, adjustment.Actor,
+	).Exec()
+	return level, err
+}
+
+func (r *CassandraInventoryRepository) Reserve(albumID string, format Format, quantity int) (StockLevel, error) {
+	if quantity <= 0 {
+		return StockLevel{}, fmt.Errorf("quantity must be positive")
+	}
+	return r.reserve(albumID, format, quantity)
+}
+
+func (r *CassandraInventoryRepository) Release(albumID string, format Format, quantity int) (StockLevel, error) {
+	if quantity <= 0 {
+		return StockLevel{}, fmt.Errorf("quantity must be positive")
+	}
+	return r.reserve(albumID, format, -quantity)
+}
+
+func (r *CassandraInventoryRepository) Fulfil(albumID string, format Format, quantity int) (StockLevel, error) {
+	if quantity <= 0 {
+		return StockLevel{}, fmt.Errorf("quantity must be positive")
+	}
+	parsedUUID, err := gocql.ParseUUID(albumID)
+	if err != nil {
+		return StockLevel{}, err
+	}
+	return r.compareAndSet(parsedUUID, format, func(level StockLevel) (StockLevel, error) {
+		return applyFulfilment(level, quantity)
+	})
+}
+
+func (r *CassandraInventoryRepository) reserve(albumID string, format Format, quantity int) (StockLevel, error) {
+	parsedUUID, err := gocql.ParseUUID(albumID)
+	if err != nil {
+		return StockLevel{}, err
+	}
+	return r.compareAndSet(parsedUUID, format, func(level StockLevel) (StockLevel, error) {
+		return applyReservation(level, quantity)
+	})
+}
+
+func (r *CassandraInventoryRepository) ListAdjustments(albumID string) ([]StockAdjustment, error) {
+	parsedUUID, err := gocql.ParseUUID(albumID)
+	if err != nil {
+		return nil, err
+	}
+	iter := r.session.Query(
+		"SELECT id, format, delta, reason, note, actor FROM stock_adjustments WHERE album_id = ?",
+		parsedUUID,
+	).Iter()
+
+	var adjustments []StockAdjustment
+	var id gocql.UUID
+	for {
+		adjustment := StockAdjustment{AlbumID: albumID}
+		if !iter.Scan(&id, &adjustment.Format, &adjustment.Delta, &adjustment.Reason, &adjustment.Note, &adjustment.Actor) {
+			break
+		}
+		adjustment.ID = id.String()
+		adjustment.CreatedAt = id.Time()
+		adjustments = append(adjustments, adjustment)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	return adjustments, nil
+}
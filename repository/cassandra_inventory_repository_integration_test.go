@@ -0,0 +1,89 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCassandraInventoryRepository_AdjustAndReserve tests adjusting, reserving, releasing and fulfilling stock.
+func TestCassandraInventoryRepository_AdjustAndReserve(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraInventoryRepository(session)
+	albumID := gocql.TimeUUID().String()
+
+	level, err := repo.Adjust(StockAdjustment{AlbumID: albumID, Format: FormatLP, Delta: 3, Reason: ReasonReceived, Actor: "staff"})
+	require.NoError(t, err)
+	require.Equal(t, 3, level.OnHand)
+
+	level, err = repo.Reserve(albumID, FormatLP, 2)
+	require.NoError(t, err)
+	require.Equal(t, 2, level.Reserved)
+
+	_, err = repo.Reserve(albumID, FormatLP, 2)
+	require.True(t, errors.Is(err, ErrInsufficientStock))
+
+	_, err = repo.Adjust(StockAdjustment{AlbumID: albumID, Format: FormatLP, Delta: -2, Reason: ReasonDamaged})
+	require.True(t, errors.Is(err, ErrInsufficientStock))
+
+	level, err = repo.Release(albumID, FormatLP, 1)
+	require.NoError(t, err)
+	require.Equal(t, 1, level.Reserved)
+
+	level, err = repo.Fulfil(albumID, FormatLP, 1)
+	require.NoError(t, err)
+	require.Equal(t, 2, level.OnHand)
+	require.Equal(t, 0, level.Reserved)
+
+	levels, err := repo.GetStock(albumID)
+	require.NoError(t, err)
+	require.Len(t, levels, 1)
+	require.Equal(t, 2, levels[0].Available)
+
+	adjustments, err := repo.ListAdjustments(albumID)
+	require.NoError(t, err)
+	require.Len(t, adjustments, 1, "the refused adjustment is not recorded")
+	require.Equal(t, ReasonReceived, adjustments[0].Reason)
+	require.Equal(t, "staff", adjustments[0].Actor)
+}
+
+// TestCassandraInventoryRepository_ConcurrentReserve tests that the lightweight transactions
+// prevent overselling when reservations race.
+func TestCassandraInventoryRepository_ConcurrentReserve(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraInventoryRepository(session)
+	albumID := gocql.TimeUUID().String()
+	_, err := repo.Adjust(StockAdjustment{AlbumID: albumID, Format: FormatCD, Delta: 5, Reason: ReasonReceived})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := repo.Reserve(albumID, FormatCD, 1); err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	levels, err := repo.GetStock(albumID)
+	require.NoError(t, err)
+	require.Len(t, levels, 1)
+	require.LessOrEqual(t, succeeded, 5)
+	require.Equal(t, succeeded, levels[0].Reserved, "every successful reservation is counted once")
+	require.Equal(t, 5, levels[0].OnHand)
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// CassandraLabelRepository claims each label's name key in labels_by_name with a lightweight
+// transaction, so two labels can never go by the same name. Cassandra cannot check that no albums
+// were released on a label before it is deleted; callers must.
+type CassandraLabelRepository struct {
+	session *gocql.Session
+}
+
+func NewCassandraLabelRepository(session *gocql.Session) *CassandraLabelRepository {
+	return &CassandraLabelRepository{session: session}
+}
+
+const cassandraLabelColumns = "id, name, created_at, updated_at"
+
+func (r *CassandraLabelRepository) Create(label Label) (Label, error) {
+	label.Normalize()
+	id := gocql.TimeUUID()
+	label.ID = id.String()
+	label.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	label.UpdatedAt = label.CreatedAt
+	key := LabelNameKey(label.Name)
+	if err := r.claimName(id, key); err != nil {
+		return Label{}, err
+	}
+	if err := r.session.Query(
+		"INSERT INTO labels ("+cassandraLabelColumns+") VALUES (?, ?, ?, ?)",
+		id, label.Name, label.CreatedAt, label.UpdatedAt,
+	).Exec(); err != nil {
+		r.releaseName(id, key)
+		return Label{}, err
+	}
+	return label, nil
+}
+
+// claimName records that the label goes by the name key, failing with ErrDuplicateLabel if
+// another label already does.
+func (r *CassandraLabelRepository) claimName(id gocql.UUID, key string) error {
+	var existingKey string
+	var owner gocql.UUID
+	applied, err := r.session.Query(
+		"INSERT INTO labels_by_name (name_key, label_id) VALUES (?, ?) IF NOT EXISTS",
+		key, id,
+	).ScanCAS(&existingKey, &owner)
+	if err == nil && !applied && owner != id {
+		err = ErrDuplicateLabel
+	}
+	return err
+}
+
+// releaseName gives up the label's claim to the name key, unless another label holds it.
+func (r *CassandraLabelRepository) releaseName(id gocql.UUID, key string) {
+	_ = r.session.Query("DELETE FROM labels_by_name WHERE name_key = ? IF label_id = ?", key, id).Exec()
+}
+
+func (r *CassandraLabelRepository) GetByID(id string) (Label, error) {
+	parsedUUID, err := gocql.ParseUUID(id)
+	if err != nil {
+		return Label{}, ErrLabelNotFound
+	}
+	label, err := scanLabel(r.session.Query("SELECT "+cassandraLabelColumns+" FROM labels WHERE id = ?", parsedUUID).Scan)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return Label{}, ErrLabelNotFound
+	}
+	return label, err
+}
+
+// scanLabel reads one row selected with cassandraLabelColumns using the given scan function.
+func scanLabel(scan func(dest ...interface{}) error) (Label, error) {
+	var label Label
+	var id gocql.UUID
+	if err := scan(&id, &label.Name, &label.CreatedAt, &label.UpdatedAt); err != nil {
+		return Label{}, err
+	}
+	label.ID = id.String()
+	return label, nil
+}
+
+func (r *CassandraLabelRepository) GetByName(name string) (Label, error) {
+	var id gocql.UUID
+	err := r.session.Query("SELECT label_id FROM labels_by_name WHERE name_key = ?", LabelNameKey(name)).Scan(&id)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return Label{}, ErrLabelNotFound
+	}
+	if err != nil {
+		return Label{}, err
+	}
+	return r.GetByID(id.String())
+}
+
+func (r *CassandraLabelRepository) List() ([]Label, error) {
+	var labels []Label
+	iter := r.session.Query("SELECT " + cassandraLabelColumns + " FROM labels").Iter()
+	scanner := iter.Scanner()
+	for scanner.Next() {
+		label, err := scanLabel(scanner.Scan)
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, label)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sortLabels(labels)
+	return labels, nil
+}
+
+func (r *CassandraLabelRepository) Update(label Label) (Label, error) {
+	current, err := r.GetByID(label.ID)
+	if err != nil {
+		return Label{}, err
+	}
+	label.Normalize()
+	id, _ := gocql.ParseUUID(label.ID)
+	oldKey, newKey := LabelNameKey(current.Name), LabelNameKey(label.Name)
+	if newKey != oldKey {
+		if err := r.claimName(id, newKey); err != nil {
+			return Label{}, err
+		}
+	}
+	label.CreatedAt = current.CreatedAt
+	label.UpdatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	if err := r.session.Query(
+		"UPDATE labels SET name = ?, updated_at = ? WHERE id = ?",
+		label.Name, label.UpdatedAt, id,
+	).Exec(); err != nil {
+		if newKey != oldKey {
+			r.releaseName(id, newKey)
+		}
+		return Label{}, err
+	}
+	if newKey != oldKey {
+		r.releaseName(id, oldKey)
+	}
+	return label, nil
+}
+
+func (r *CassandraLabelRepository) Delete(id string) error {
+	label, err := r.GetByID(id)
+	if err != nil {
+		return err
+	}
+	parsedUUID, _ := gocql.ParseUUID(id)
+	if err := r.session.Query("DELETE FROM labels WHERE id = ?", parsedUUID).Exec(); err != nil {
+		return err
+	}
+	r.releaseName(parsedUUID, LabelNameKey(label.Name))
+	return nil
+}
@@ -0,0 +1,146 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestCassandraLabelRepository tests keeping label names unique and freeing them when labels are renamed or deleted.
+func TestCassandraLabelRepository(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraLabelRepository(session)
+
+	tamla, err := repo.GetByName("Tamla Records")
+	require.NoError(t, err, "the migration seeds the Motown labels")
+	require.Equal(t, "Tamla", tamla.Name)
+
+	rareEarth, err := repo.Create(Label{Name: "Rare  Earth"})
+	require.NoError(t, err)
+	require.Equal(t, "Rare Earth", rareEarth.Name)
+	_, err = repo.Create(Label{Name: "Rare Earth Records"})
+	require.True(t, errors.Is(err, ErrDuplicateLabel))
+
+	rareEarth.Name = "Gordy"
+	_, err = repo.Update(rareEarth)
+	require.True(t, errors.Is(err, ErrDuplicateLabel))
+	rareEarth.Name = "Rare Earth Sound"
+	_, err = repo.Update(rareEarth)
+	require.NoError(t, err)
+	_, err = repo.Create(Label{Name: "Rare Earth"})
+	require.NoError(t, err, "a renamed label gives up its old name")
+
+	labels, err := repo.List()
+	require.NoError(t, err)
+	require.Len(t, labels, 6)
+	require.Equal(t, "Gordy", labels[0].Name)
+
+	require.NoError(t, repo.Delete(rareEarth.ID))
+	_, err = repo.GetByID(rareEarth.ID)
+	require.True(t, errors.Is(err, ErrLabelNotFound))
+	require.True(t, errors.Is(repo.Delete(rareEarth.ID), ErrLabelNotFound))
+	_, err = repo.Create(Label{Name: "Rare Earth Sound"})
+	require.NoError(t, err, "a deleted label's name is free again")
+}
+
+// TestCassandraAlbumRepository_Identifiers tests looking albums up by barcode and catalogue number, and keeping
+// both unique, with barcodes shared with releases.
+func TestCassandraAlbumRepository_Identifiers(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	labels := NewCassandraLabelRepository(session)
+	repo := NewCassandraAlbumRepository(session)
+	releases := NewCassandraReleaseRepository(session)
+	tamla, err := labels.GetByName("Tamla")
+	require.NoError(t, err)
+	gordy, err := labels.GetByName("Gordy")
+	require.NoError(t, err)
+
+	album := Album{Title: "What's Going On", Artist: "Marvin Gaye", LabelID: tamla.ID, Label: tamla.Name, CatalogueNumber: "TS 310",
+		Barcode: "0036000291452", Price: money.MustParse("9.99"), Year: 1971, ImageUrl: "x", Genre: "Soul"}
+	require.NoError(t, repo.Create(album))
+
+	found, err := repo.GetByBarcode("0036000291452")
+	require.NoError(t, err)
+	require.Equal(t, "What's Going On", found.Title)
+	found, err = repo.GetByCatalogueNumber(tamla.ID, "TS 310")
+	require.NoError(t, err)
+	require.Equal(t, "TS 310", found.CatalogueNumber)
+	_, err = repo.GetByCatalogueNumber(gordy.ID, "TS 310")
+	require.True(t, errors.Is(err, ErrAlbumNotFound))
+
+	other := album
+	other.Title, other.CatalogueNumber = "Let's Get It On", "T 329"
+	require.True(t, errors.Is(repo.Create(other), ErrDuplicateBarcode))
+	other.Barcode, other.CatalogueNumber = "", "TS 310"
+	require.True(t, errors.Is(repo.Create(other), ErrDuplicateCatalogueNumber))
+	other.LabelID, other.Label = gordy.ID, gordy.Name
+	require.NoError(t, repo.Create(other), "catalogue numbers are only unique within a label")
+
+	_, err = releases.Create(Release{AlbumID: found.ID, Format: FormatCD, Year: 2011, Barcode: "0036000291452", Price: money.MustParse("9.99"), Currency: "GBP"})
+	require.True(t, errors.Is(err, ErrDuplicateBarcode), "releases cannot take an album's barcode")
+	anniversary, err := releases.Create(Release{AlbumID: found.ID, Format: FormatCD, Year: 2011, Barcode: "0602527736211", Price: money.MustParse("14.99"), Currency: "GBP"})
+	require.NoError(t, err)
+	release, err := releases.GetByBarcode("0602527736211")
+	require.NoError(t, err)
+	require.Equal(t, anniversary.ID, release.ID)
+
+	found.Barcode = "0602527736211"
+	require.True(t, errors.Is(repo.Update(found), ErrDuplicateBarcode), "albums cannot take a release's barcode")
+	_, err = repo.GetByBarcode("0036000291452")
+	require.NoError(t, err, "a failed update keeps the barcode the album already had")
+	found.Barcode = "4006381333931"
+	require.NoError(t, repo.Update(found))
+	_, err = repo.GetByBarcode("0036000291452")
+	require.True(t, errors.Is(err, ErrAlbumNotFound))
+	_, err = releases.Create(Release{AlbumID: found.ID, Format: FormatLP, Year: 1971, Barcode: "0036000291452", Price: money.MustParse("60.00"), Currency: "GBP"})
+	require.NoError(t, err, "an album gives up the barcode it changed from")
+}
+
+// TestCassandraAlbumRepository_ConcurrentBarcodeClaims tests that the lightweight transactions on albums_by_barcode
+// and releases_by_barcode let at most one album or release created at once with the same barcode have it.
+func TestCassandraAlbumRepository_ConcurrentBarcodeClaims(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraAlbumRepository(session)
+	releases := NewCassandraReleaseRepository(session)
+	albumID := gocql.TimeUUID().String()
+
+	var wg sync.WaitGroup
+	errs := make([]error, 6)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i%2 == 0 {
+				errs[i] = repo.Create(Album{Title: "Talking Book", Artist: "Stevie Wonder", Barcode: "0036000291452",
+					Price: money.MustParse("9.99"), Year: 1972, ImageUrl: "x", Genre: "Soul"})
+				return
+			}
+			_, errs[i] = releases.Create(Release{AlbumID: albumID, Format: FormatCD, Year: 2000, Barcode: "0036000291452",
+				Price: money.MustParse("9.99"), Currency: "GBP"})
+		}()
+	}
+	wg.Wait()
+
+	created := 0
+	for _, err := range errs {
+		if err == nil {
+			created++
+		} else {
+			require.True(t, errors.Is(err, ErrDuplicateBarcode), err)
+		}
+	}
+	require.LessOrEqual(t, created, 1)
+	_, albumErr := repo.GetByBarcode("0036000291452")
+	_, releaseErr := releases.GetByBarcode("0036000291452")
+	require.False(t, albumErr == nil && releaseErr == nil, "an album and a release never share a barcode")
+}
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// CassandraOrderRepository has no multi-partition transactions to lean on, so it reserves stock line
+// by line through the inventory's lightweight transactions and releases what it already reserved if a
+// later line fails. Status changes are guarded by a lightweight transaction on the current status.
+type CassandraOrderRepository struct {
+	session   *gocql.Session
+	inventory *CassandraInventoryRepository
+}
+
+func NewCassandraOrderRepository(session *gocql.Session, inventory *CassandraInventoryRepository) *CassandraOrderRepository {
+	return &CassandraOrderRepository{session: session, inventory: inventory}
+}
+
+func (r *CassandraOrderRepository) Create(order Order) (Order, error) {
+	var reserved []OrderLine
+	for _, line := range sortedLines(order.Lines) {
+		if _, err := r.inventory.Reserve(line.AlbumID, line.Format, line.Quantity); err != nil {
+			r.releaseAll(reserved)
+			return Order{}, err
+		}
+		reserved = append(reserved, line)
+	}
+
+	orderID := gocql.TimeUUID()
+	now := time.Now().UTC()
+	order.ID = orderID.String()
+	order.Status = OrderPending
+	order.CreatedAt = now
+	order.UpdatedAt = now
+
+	batch := r.session.NewBatch(gocql.LoggedBatch)
+	batch.Query(
+		"INSERT INTO orders (id, user_id, status, coupon_code, discount, discount_decimal, country, region, tax, tax_included, total, total_decimal, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		orderID, order.UserID, order.Status, order.CouponCode, order.Discount, order.Discount, order.Country, order.Region, order.Tax, order.TaxIncluded, order.Total, order.Total, order.CreatedAt, order.UpdatedAt,
+	)
+	batch.Query(
+		"INSERT INTO orders_by_user (user_id, created_at, order_id) VALUES (?, ?, ?)",
+		order.UserID, order.CreatedAt, orderID,
+	)
+	for i, line := range order.Lines {
+		albumID, err := gocql.ParseUUID(line.AlbumID)
+		if err != nil {
+			r.releaseAll(reserved)
+			return Order{}, err
+		}
+		batch.Query(
+			"INSERT INTO order_lines (order_id, line_no, album_id, release_id, format, title, artist, unit_price, unit_price_decimal, quantity, discount, discount_decimal, promotion, line_total, line_total_decimal, tax_category, tax_name, tax_rate, tax) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			orderID, i, albumID, line.ReleaseID, line.Format, line.Title, line.Artist, line.UnitPrice, line.UnitPrice, line.Quantity, line.Discount, line.Discount, line.Promotion, line.LineTotal, line.LineTotal,
+			line.TaxCategory, line.TaxName, line.TaxRate, line.Tax,
+		)
+	}
+	batch.Query(
+		"INSERT INTO order_events (order_id, id, status, actor, note) VALUES (?, ?, ?, ?, ?)",
+		orderID, gocql.TimeUUID(), OrderPending, order.UserID, "",
+	)
+	if err := r.session.ExecuteBatch(batch); err != nil {
+		r.releaseAll(reserved)
+		return Order{}, err
+	}
+	return r.GetByID(order.ID)
+}
+
+// releaseAll undoes reservations made for an order that could not be created.
+func (r *CassandraOrderRepository) releaseAll(lines []OrderLine) {
+	for _, line := range lines {
+		if _, err := r.inventory.Release(line.AlbumID, line.Format, line.Quantity); err != nil {
+			log.Printf("CassandraOrderRepository: failed to release %d x %s (%s): %v", line.Quantity, line.AlbumID, line.Format, err)
+		}
+	}
+}
+
+func (r *CassandraOrderRepository) GetByID(id string) (Order, error) {
+	parsedUUID, err := gocql.ParseUUID(id)
+	if err != nil {
+		return Order{}, ErrOrderNotFound
+	}
+
+	var order Order
+	var cassandraID gocql.UUID
+	var exactDiscount, exactTotal *money.Amount
+	err = r.session.Query(
+		"SELECT id, user_id, status, coupon_code, discount, discount_decimal, country, region, tax, tax_included, total, total_decimal, created_at, updated_at FROM orders WHERE id = ?",
+		parsedUUID,
+	).Scan(&cassandraID, &order.UserID, &order.Status, &order.CouponCode, &order.Discount, &exactDiscount, &order.Country, &order.Region, &order.Tax, &order.TaxIncluded,
+		&order.Total, &exactTotal, &order.CreatedAt, &order.UpdatedAt)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return Order{}, ErrOrderNotFound
+	}
+	if err != nil {
+		return Order{}, err
+	}
+	order.ID = cassandraID.String()
+	order.Discount = exactOr(exactDiscount, order.Discount)
+	order.Total = exactOr(exactTotal, order.Total)
+
+	iter := r.session.Query(
+		"SELECT album_id, release_id, format, title, artist, unit_price, unit_price_decimal, quantity, discount, discount_decimal, promotion, line_total, line_total_decimal, tax_category, tax_name, tax_rate, tax FROM order_lines WHERE order_id = ?",
+		parsedUUID,
+	).Iter()
+	var albumID gocql.UUID
+	for {
+		var line OrderLine
+		var exactUnitPrice, exactDiscount, exactLineTotal *money.Amount
+		if !iter.Scan(&albumID, &line.ReleaseID, &line.Format, &line.Title, &line.Artist, &line.UnitPrice, &exactUnitPrice, &line.Quantity, &line.Discount, &exactDiscount,
+			&line.Promotion, &line.LineTotal, &exactLineTotal, &line.TaxCategory, &line.TaxName, &line.TaxRate, &line.Tax) {
+			break
+		}
+		line.AlbumID = albumID.String()
+		line.UnitPrice = exactOr(exactUnitPrice, line.UnitPrice)
+		line.Discount = exactOr(exactDiscount, line.Discount)
+		line.LineTotal = exactOr(exactLineTotal, line.LineTotal)
+		order.Lines = append(order.Lines, line)
+	}
+	if err := iter.Close(); err != nil {
+		return Order{}, err
+	}
+
+	iter = r.session.Query("SELECT id, status, actor, note FROM order_events WHERE order_id = ?", parsedUUID).Iter()
+	var eventID gocql.UUID
+	for {
+		var event OrderEvent
+		if !iter.Scan(&eventID, &event.Status, &event.Actor, &event.Note) {
+			break
+		}
+		event.CreatedAt = eventID.Time()
+		order.History = append(order.History, event)
+	}
+	if err := iter.Close(); err != nil {
+		return Order{}, err
+	}
+	return order, nil
+}
+
+func (r *CassandraOrderRepository) ListByUser(userID string) ([]Order, error) {
+	return r.list(r.session.Query("SELECT order_id FROM orders_by_user WHERE user_id = ?", userID).Iter(), "")
+}
+
+func (r *CassandraOrderRepository) List(status OrderStatus) ([]Order, error) {
+	return r.list(r.session.Query("SELECT id FROM orders").Iter(), status)
+}
+
+// list loads every order whose ID the iterator yields, keeping those in status (or all if status is empty).
+func (r *CassandraOrderRepository) list(iter *gocql.Iter, status OrderStatus) ([]Order, error) {
+	var ids []gocql.UUID
+	var id gocql.UUID
+	for iter.Scan(&id) {
+		ids = append(ids, id)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+
+	var orders []Order
+	for _, id := range ids {
+		order, err := r.GetByID(id.String())
+		if err != nil {
+			return nil, err
+		}
+		if status == "" || order.Status == status {
+			orders = append(orders, order)
+		}
+	}
+	return orders, nil
+}
+
+func (r *CassandraOrderRepository) UpdateStatus(id string, status OrderStatus, actor, note string) (Order, error) {
+	order, err := r.GetByID(id)
+	if err != nil {
+		return Order{}, err
+	}
+	if !order.Status.CanTransitionTo(status) {
+		return Order{}, ErrInvalidTransition
+	}
+	orderID, _ := gocql.ParseUUID(order.ID)
+
+	// Claim the transition first so two concurrent requests cannot both release or fulfil the stock.
+	var currentStatus OrderStatus
+	applied, err := r.session.Query(
+		"UPDATE orders SET status = ?, updated_at = ? WHERE id = ? IF status = ?",
+		status, time.Now().UTC(), orderID, order.Status,
+	).ScanCAS(&currentStatus)
+	if err != nil {
+		return Order{}, err
+	}
+	if !applied {
+		return Order{}, fmt.Errorf("%w: order is now %s", ErrInvalidTransition, currentStatus)
+	}
+
+	for _, line := range sortedLines(order.Lines) {
+		switch status {
+		case OrderCancelled:
+			_, err = r.inventory.Release(line.AlbumID, line.Format, line.Quantity)
+		case OrderShipped:
+			_, err = r.inventory.Fulfil(line.AlbumID, line.Format, line.Quantity)
+		}
+		if err != nil {
+			return Order{}, fmt.Errorf("order %s is %s but stock for %s (%s) could not be updated: %w", order.ID, status, line.AlbumID, line.Format, err)
+		}
+	}
+
+	if err := r.session.Query(
+		"INSERT INTO order_events (order_id, id, status, actor, note) VALUES (?, ?, ?, ?, ?)",
+		orderID, gocql.TimeUUID(), status, actor, note,
+	).Exec(); err != nil {
+		return Order{}, err
+	}
+	return r.GetByID(order.ID)
+}
@@ -0,0 +1,116 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestCassandraOrderRepository_Lifecycle tests that orders reserve, fulfil and release stock as they change status.
+func TestCassandraOrderRepository_Lifecycle(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	albumID := gocql.TimeUUID().String()
+	inventory := NewCassandraInventoryRepository(session)
+	_, err := inventory.Adjust(StockAdjustment{AlbumID: albumID, Format: FormatLP, Delta: 3, Reason: ReasonReceived})
+	require.NoError(t, err)
+	repo := NewCassandraOrderRepository(session, inventory)
+
+	order, err := repo.Create(Order{UserID: "user-1", Country: "GB", Tax: money.MustParse("0.40"), Total: money.MustParse("2.40"), Lines: []OrderLine{
+		{AlbumID: albumID, ReleaseID: "6ba7b810-9dad-11d1-80b4-00c04fd430c8", Format: FormatLP, Title: "ABC", Artist: "Jackson 5", UnitPrice: money.MustParse("1.00"), Quantity: 2, LineTotal: money.MustParse("2.00"),
+			TaxCategory: "standard", TaxName: "VAT", TaxRate: 20, Tax: money.MustParse("0.40")},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, OrderPending, order.Status)
+	require.Equal(t, money.MustParse("2.40"), order.Total)
+	require.Len(t, order.Lines, 1)
+	require.Equal(t, money.MustParse("1.00"), order.Lines[0].UnitPrice)
+	require.Equal(t, "6ba7b810-9dad-11d1-80b4-00c04fd430c8", order.Lines[0].ReleaseID)
+	require.Len(t, order.History, 1)
+
+	levels, err := inventory.GetStock(albumID)
+	require.NoError(t, err)
+	require.Equal(t, 2, levels[0].Reserved)
+
+	// A second order that cannot be filled reserves nothing.
+	otherAlbumID := gocql.TimeUUID().String()
+	_, err = repo.Create(Order{UserID: "user-2", Lines: []OrderLine{
+		{AlbumID: albumID, Format: FormatLP, Quantity: 1},
+		{AlbumID: otherAlbumID, Format: FormatCD, Quantity: 1},
+	}})
+	require.True(t, errors.Is(err, ErrInsufficientStock))
+	levels, err = inventory.GetStock(albumID)
+	require.NoError(t, err)
+	require.Equal(t, 2, levels[0].Reserved, "the reservation made before the failing line is released")
+
+	_, err = repo.UpdateStatus(order.ID, OrderShipped, "staff", "")
+	require.True(t, errors.Is(err, ErrInvalidTransition))
+	_, err = repo.UpdateStatus(order.ID, OrderPaid, "staff", "")
+	require.NoError(t, err)
+	shipped, err := repo.UpdateStatus(order.ID, OrderShipped, "staff", "tracking 123")
+	require.NoError(t, err)
+	require.Equal(t, OrderShipped, shipped.Status)
+	require.Len(t, shipped.History, 3)
+
+	levels, err = inventory.GetStock(albumID)
+	require.NoError(t, err)
+	require.Equal(t, 1, levels[0].OnHand)
+	require.Equal(t, 0, levels[0].Reserved)
+
+	orders, err := repo.ListByUser("user-1")
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	orders, err = repo.List(OrderPaid)
+	require.NoError(t, err)
+	require.Empty(t, orders)
+
+	_, err = repo.GetByID("not-a-uuid")
+	require.True(t, errors.Is(err, ErrOrderNotFound))
+}
+
+// TestCassandraOrderRepository_ConcurrentCancel tests that the lightweight transaction on the status
+// lets only one of two racing cancellations release the order's stock.
+func TestCassandraOrderRepository_ConcurrentCancel(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	albumID := gocql.TimeUUID().String()
+	inventory := NewCassandraInventoryRepository(session)
+	_, err := inventory.Adjust(StockAdjustment{AlbumID: albumID, Format: FormatCD, Delta: 4, Reason: ReasonReceived})
+	require.NoError(t, err)
+	repo := NewCassandraOrderRepository(session, inventory)
+	order, err := repo.Create(Order{UserID: "user-1", Lines: []OrderLine{{AlbumID: albumID, Format: FormatCD, Quantity: 2}}})
+	require.NoError(t, err)
+	_, err = inventory.Reserve(albumID, FormatCD, 1)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = repo.UpdateStatus(order.ID, OrderCancelled, "staff", "")
+		}()
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+		} else {
+			require.True(t, errors.Is(err, ErrInvalidTransition), err)
+		}
+	}
+	require.Equal(t, 1, succeeded)
+	levels, err := inventory.GetStock(albumID)
+	require.NoError(t, err)
+	require.Equal(t, 1, levels[0].Reserved, "the order's two units are released once, leaving the other reservation")
+}
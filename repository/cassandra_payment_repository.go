@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// CassandraPaymentRepository claims each idempotency key with a lightweight transaction on
+// payment_intents_by_key before writing the intent, and keeps lookup tables by provider reference and order.
+// The order's slot in payment_intents_in_flight is claimed the same way, and released when the intent
+// stops being pending or authorised.
+type CassandraPaymentRepository struct {
+	session *gocql.Session
+}
+
+func NewCassandraPaymentRepository(session *gocql.Session) *CassandraPaymentRepository {
+	return &CassandraPaymentRepository{session: session}
+}
+
+// cassandraPaymentColumns lists both columns of each amount: the decimal columns hold the exact
+// amounts, and the older double columns are still written so the decimal migration can be rolled back.
+const cassandraPaymentColumns = "id, order_id, idempotency_key, provider, provider_ref, amount, amount_decimal, refunded_amount, refunded_amount_decimal, status, failure_reason, created_at, updated_at"
+
+func (r *CassandraPaymentRepository) Create(intent PaymentIntent) (PaymentIntent, error) {
+	orderID, err := gocql.ParseUUID(intent.OrderID)
+	if err != nil {
+		return PaymentIntent{}, ErrOrderNotFound
+	}
+	id := gocql.TimeUUID()
+
+	var claimedOrder, existing gocql.UUID
+	if intent.Status.inFlight() {
+		applied, err := r.session.Query(
+			"INSERT INTO payment_intents_in_flight (order_id, intent_id) VALUES (?, ?) IF NOT EXISTS",
+			orderID, id,
+		).ScanCAS(&claimedOrder, &existing)
+		if err != nil {
+			return PaymentIntent{}, err
+		}
+		if !applied {
+			return PaymentIntent{}, ErrPaymentInProgress
+		}
+	}
+	var key string
+	applied, err := r.session.Query(
+		"INSERT INTO payment_intents_by_key (idempotency_key, intent_id) VALUES (?, ?) IF NOT EXISTS",
+		intent.IdempotencyKey, id,
+	).ScanCAS(&key, &existing)
+	if err == nil && !applied {
+		err = ErrDuplicateIdempotencyKey
+	}
+	if err != nil {
+		if releaseErr := r.releaseInFlight(orderID, id); releaseErr != nil {
+			return PaymentIntent{}, errors.Join(err, releaseErr)
+		}
+		return PaymentIntent{}, err
+	}
+
+	now := time.Now().UTC()
+	intent.ID = id.String()
+	intent.CreatedAt = now
+	intent.UpdatedAt = now
+	batch := r.session.NewBatch(gocql.LoggedBatch)
+	batch.Query(
+		"INSERT INTO payment_intents ("+cassandraPaymentColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		id, orderID, intent.IdempotencyKey, intent.Provider, intent.ProviderRef, intent.Amount, intent.Amount, intent.RefundedAmount, intent.RefundedAmount,
+		intent.Status, intent.FailureReason, intent.CreatedAt, intent.UpdatedAt,
+	)
+	batch.Query("INSERT INTO payment_intents_by_order (order_id, intent_id) VALUES (?, ?)", orderID, id)
+	if intent.ProviderRef != "" {
+		batch.Query("INSERT INTO payment_intents_by_ref (provider, provider_ref, intent_id) VALUES (?, ?, ?)", intent.Provider, intent.ProviderRef, id)
+	}
+	if err := r.session.ExecuteBatch(batch); err != nil {
+		return PaymentIntent{}, err
+	}
+	return intent, nil
+}
+
+func (r *CassandraPaymentRepository) GetByID(id string) (PaymentIntent, error) {
+	parsedUUID, err := gocql.ParseUUID(id)
+	if err != nil {
+		return PaymentIntent{}, ErrPaymentNotFound
+	}
+	return r.get(parsedUUID)
+}
+
+func (r *CassandraPaymentRepository) get(id gocql.UUID) (PaymentIntent, error) {
+	var intent PaymentIntent
+	var cassandraID, orderID gocql.UUID
+	var exactAmount, exactRefunded *money.Amount
+	err := r.session.Query(
+		"SELECT "+cassandraPaymentColumns+" FROM payment_intents WHERE id = ?", id,
+	).Scan(&cassandraID, &orderID, &intent.IdempotencyKey, &intent.Provider, &intent.ProviderRef, &intent.Amount, &exactAmount,
+		&intent.RefundedAmount, &exactRefunded, &intent.Status, &intent.FailureReason, &intent.CreatedAt, &intent.UpdatedAt)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return PaymentIntent{}, ErrPaymentNotFound
+	}
+	if err != nil {
+		return PaymentIntent{}, err
+	}
+	intent.ID = cassandraID.String()
+	intent.OrderID = orderID.String()
+	intent.Amount = exactOr(exactAmount, intent.Amount)
+	intent.RefundedAmount = exactOr(exactRefunded, intent.RefundedAmount)
+	return intent, nil
+}
+
+// getVia looks up an intent ID in one of the lookup tables and loads the intent.
+func (r *CassandraPaymentRepository) getVia(query string, args ...interface{}) (PaymentIntent, error) {
+	var id gocql.UUID
+	err := r.session.Query(query, args...).Scan(&id)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return PaymentIntent{}, ErrPaymentNotFound
+	}
+	if err != nil {
+		return PaymentIntent{}, err
+	}
+	return r.get(id)
+}
+
+func (r *CassandraPaymentRepository) GetByIdempotencyKey(key string) (PaymentIntent, error) {
+	return r.getVia("SELECT intent_id FROM payment_intents_by_key WHERE idempotency_key = ?", key)
+}
+
+func (r *CassandraPaymentRepository) GetByProviderRef(provider, ref string) (PaymentIntent, error) {
+	return r.getVia("SELECT intent_id FROM payment_intents_by_ref WHERE provider = ? AND provider_ref = ?", provider, ref)
+}
+
+func (r *CassandraPaymentRepository) ListByOrder(orderID string) ([]PaymentIntent, error) {
+	parsedUUID, err := gocql.ParseUUID(orderID)
+	if err != nil {
+		return nil, nil
+	}
+	iter := r.session.Query("SELECT intent_id FROM payment_intents_by_order WHERE order_id = ?", parsedUUID).Iter()
+	var ids []gocql.UUID
+	var id gocql.UUID
+	for iter.Scan(&id) {
+		ids = append(ids, id)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	var intents []PaymentIntent
+	for _, id := range ids {
+		intent, err := r.get(id)
+		if err != nil {
+			return nil, err
+		}
+		intents = append(intents, intent)
+	}
+	return intents, nil
+}
+
+func (r *CassandraPaymentRepository) Update(intent PaymentIntent) (PaymentIntent, error) {
+	existing, err := r.GetByID(intent.ID)
+	if err != nil {
+		return PaymentIntent{}, err
+	}
+	id, _ := gocql.ParseUUID(existing.ID)
+
+	batch := r.session.NewBatch(gocql.LoggedBatch)
+	batch.Query(
+		"UPDATE payment_intents SET provider_ref = ?, amount = ?, amount_decimal = ?, refunded_amount = ?, refunded_amount_decimal = ?, status = ?, failure_reason = ?, updated_at = ? WHERE id = ?",
+		intent.ProviderRef, intent.Amount, intent.Amount, intent.RefundedAmount, intent.RefundedAmount, intent.Status, intent.FailureReason, time.Now().UTC(), id,
+	)
+	if intent.ProviderRef != "" && intent.ProviderRef != existing.ProviderRef {
+		batch.Query("INSERT INTO payment_intents_by_ref (provider, provider_ref, intent_id) VALUES (?, ?, ?)", existing.Provider, intent.ProviderRef, id)
+	}
+	if err := r.session.ExecuteBatch(batch); err != nil {
+		return PaymentIntent{}, err
+	}
+	if !intent.Status.inFlight() {
+		orderID, _ := gocql.ParseUUID(existing.OrderID)
+		if err := r.releaseInFlight(orderID, id); err != nil {
+			return PaymentIntent{}, err
+		}
+	}
+	return r.get(id)
+}
+
+// releaseInFlight frees the order's in-flight slot if the intent holds it, so another payment can be made.
+func (r *CassandraPaymentRepository) releaseInFlight(orderID, intentID gocql.UUID) error {
+	return r.session.Query("DELETE FROM payment_intents_in_flight WHERE order_id = ? IF intent_id = ?", orderID, intentID).Exec()
+}
@@ -0,0 +1,108 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestCassandraPaymentRepository_Idempotency tests that idempotency keys are unique, that an order has one payment
+// in flight at a time, and that intents can be found by each key.
+func TestCassandraPaymentRepository_Idempotency(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraPaymentRepository(session)
+	orderID := gocql.TimeUUID().String()
+
+	intent, err := repo.Create(PaymentIntent{OrderID: orderID, IdempotencyKey: "user-1:k", Provider: "fake", Amount: money.MustParse("9.99"), Status: PaymentPending})
+	require.NoError(t, err)
+	require.NotEmpty(t, intent.ID)
+
+	_, err = repo.Create(PaymentIntent{OrderID: orderID, IdempotencyKey: "user-1:other", Provider: "fake", Amount: money.MustParse("9.99"), Status: PaymentPending})
+	require.True(t, errors.Is(err, ErrPaymentInProgress), "only one payment per order can be in flight")
+
+	intent.ProviderRef = "fake_123"
+	intent.Status = PaymentCaptured
+	updated, err := repo.Update(intent)
+	require.NoError(t, err)
+	require.Equal(t, money.MustParse("9.99"), updated.Amount)
+
+	_, err = repo.Create(PaymentIntent{OrderID: orderID, IdempotencyKey: "user-1:k", Provider: "fake", Amount: money.MustParse("9.99"), Status: PaymentPending})
+	require.True(t, errors.Is(err, ErrDuplicateIdempotencyKey))
+
+	found, err := repo.GetByProviderRef("fake", "fake_123")
+	require.NoError(t, err)
+	require.Equal(t, intent.ID, found.ID)
+	require.Equal(t, PaymentCaptured, found.Status)
+
+	found, err = repo.GetByIdempotencyKey("user-1:k")
+	require.NoError(t, err)
+	require.Equal(t, intent.ID, found.ID)
+
+	// Capturing the first payment freed the order for another.
+	second, err := repo.Create(PaymentIntent{OrderID: orderID, IdempotencyKey: "user-1:other", Provider: "fake", Amount: money.MustParse("1.00"), Status: PaymentPending})
+	require.NoError(t, err)
+
+	intents, err := repo.ListByOrder(orderID)
+	require.NoError(t, err)
+	require.Len(t, intents, 2)
+	require.Equal(t, intent.ID, intents[0].ID)
+	require.Equal(t, second.ID, intents[1].ID)
+
+	_, err = repo.GetByID("not-a-uuid")
+	require.True(t, errors.Is(err, ErrPaymentNotFound))
+}
+
+// TestCassandraPaymentRepository_ConcurrentCreate tests that when payments for one order race, only one
+// is created and the losers' idempotency keys stay free to retry.
+func TestCassandraPaymentRepository_ConcurrentCreate(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraPaymentRepository(session)
+	orderID := gocql.TimeUUID().String()
+
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	keys := []string{"user-1:a", "user-1:b", "user-1:c", "user-1:d"}
+	for i := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = repo.Create(PaymentIntent{OrderID: orderID, IdempotencyKey: keys[i], Provider: "fake", Amount: money.MustParse("9.99"), Status: PaymentPending})
+		}()
+	}
+	wg.Wait()
+
+	winner := ""
+	for i, err := range errs {
+		if err == nil {
+			require.Empty(t, winner, "only one payment is created")
+			winner = keys[i]
+		} else {
+			require.True(t, errors.Is(err, ErrPaymentInProgress), err)
+		}
+	}
+	require.NotEmpty(t, winner)
+	intents, err := repo.ListByOrder(orderID)
+	require.NoError(t, err)
+	require.Len(t, intents, 1)
+
+	intent := intents[0]
+	intent.Status = PaymentFailed
+	_, err = repo.Update(intent)
+	require.NoError(t, err)
+	for _, key := range keys {
+		if key != winner {
+			_, err = repo.Create(PaymentIntent{OrderID: orderID, IdempotencyKey: key, Provider: "fake", Amount: money.MustParse("9.99"), Status: PaymentPending})
+			require.NoError(t, err, "a failed payment frees the order, and the losing keys were never used")
+			break
+		}
+	}
+}
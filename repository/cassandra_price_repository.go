@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// CassandraPriceRepository keeps each album's history in one partition, newest first. Scheduled
+// changes are few, so Due scans them all; status changes use a lightweight transaction.
+type CassandraPriceRepository struct {
+	session *gocql.Session
+}
+
+func NewCassandraPriceRepository(session *gocql.Session) *CassandraPriceRepository {
+	return &CassandraPriceRepository{session: session}
+}
+
+// cassandraScheduledPriceColumns lists both price columns: price_decimal holds the exact price, and the
+// older double price column is still written so the decimal migration can be rolled back.
+const cassandraScheduledPriceColumns = "id, album_id, price, price_decimal, effective_at, status, actor, note, created_at, updated_at"
+
+func (r *CassandraPriceRepository) RecordChange(change PriceChange) (PriceChange, error) {
+	albumID, err := gocql.ParseUUID(change.AlbumID)
+	if err != nil {
+		return PriceChange{}, err
+	}
+	id := gocql.TimeUUID()
+	if err := r.session.Query(
+		"INSERT INTO price_history (album_id, id, old_price, old_price_decimal, new_price, new_price_decimal, source, actor, note) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		albumID, id, change.OldPrice, change.OldPrice, change.NewPrice, change.NewPrice, change.Source, change.Actor, change.Note,
+	).Exec(); err != nil {
+		return PriceChange{}, err
+	}
+	change.ID = id.String()
+	change.ChangedAt = id.Time()
+	return change, nil
+}
+
+func (r *CassandraPriceRepository) ListChanges(albumID string) ([]PriceChange, error) {
+	parsedUUID, err := gocql.ParseUUID(albumID)
+	if err != nil {
+		return nil, nil
+	}
+	iter := r.session.Query(
+		"SELECT id, old_price, old_price_decimal, new_price, new_price_decimal, source, actor, note FROM price_history WHERE album_id = ?",
+		parsedUUID,
+	).Iter()
+	var changes []PriceChange
+	var id gocql.UUID
+	for {
+		change := PriceChange{AlbumID: parsedUUID.String()}
+		var exactOld, exactNew *money.Amount
+		if !iter.Scan(&id, &change.OldPrice, &exactOld, &change.NewPrice, &exactNew, &change.Source, &change.Actor, &change.Note) {
+			break
+		}
+		change.OldPrice = exactOr(exactOld, change.OldPrice)
+		change.NewPrice = exactOr(exactNew, change.NewPrice)
+		change.ID = id.String()
+		change.ChangedAt = id.Time()
+		changes = append(changes, change)
+	}
+	return changes, iter.Close()
+}
+
+func (r *CassandraPriceRepository) Schedule(scheduled ScheduledPrice) (ScheduledPrice, error) {
+	albumID, err := gocql.ParseUUID(scheduled.AlbumID)
+	if err != nil {
+		return ScheduledPrice{}, err
+	}
+	id := gocql.TimeUUID()
+	now := time.Now().UTC()
+	scheduled.ID = id.String()
+	scheduled.Status = SchedulePending
+	scheduled.CreatedAt = now
+	scheduled.UpdatedAt = now
+
+	batch := r.session.NewBatch(gocql.LoggedBatch)
+	batch.Query(
+		"INSERT INTO scheduled_prices ("+cassandraScheduledPriceColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		id, albumID, scheduled.Price, scheduled.Price, scheduled.EffectiveAt, scheduled.Status, scheduled.Actor, scheduled.Note, now, now,
+	)
+	batch.Query("INSERT INTO scheduled_prices_by_album (album_id, id) VALUES (?, ?)", albumID, id)
+	if err := r.session.ExecuteBatch(batch); err != nil {
+		return ScheduledPrice{}, err
+	}
+	return scheduled, nil
+}
+
+// scanScheduledPrice reads one scheduled_prices row using the given scan function.
+func scanScheduledPrice(scan func(dest ...interface{}) error) (ScheduledPrice, error) {
+	var scheduled ScheduledPrice
+	var id, albumID gocql.UUID
+	var exact *money.Amount
+	if err := scan(&id, &albumID, &scheduled.Price, &exact, &scheduled.EffectiveAt, &scheduled.Status,
+		&scheduled.Actor, &scheduled.Note, &scheduled.CreatedAt, &scheduled.UpdatedAt); err != nil {
+		return ScheduledPrice{}, err
+	}
+	scheduled.Price = exactOr(exact, scheduled.Price)
+	scheduled.ID = id.String()
+	scheduled.AlbumID = albumID.String()
+	return scheduled, nil
+}
+
+func (r *CassandraPriceRepository) GetScheduled(id string) (ScheduledPrice, error) {
+	parsedUUID, err := gocql.ParseUUID(id)
+	if err != nil {
+		return ScheduledPrice{}, ErrScheduledPriceNotFound
+	}
+	scheduled, err := scanScheduledPrice(r.session.Query(
+		"SELECT "+cassandraScheduledPriceColumns+" FROM scheduled_prices WHERE id = ?", parsedUUID,
+	).Scan)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return ScheduledPrice{}, ErrScheduledPriceNotFound
+	}
+	return scheduled, err
+}
+
+func (r *CassandraPriceRepository) ListScheduled(albumID string) ([]ScheduledPrice, error) {
+	parsedUUID, err := gocql.ParseUUID(albumID)
+	if err != nil {
+		return nil, nil
+	}
+	iter := r.session.Query("SELECT id FROM scheduled_prices_by_album WHERE album_id = ?", parsedUUID).Iter()
+	var ids []gocql.UUID
+	var id gocql.UUID
+	for iter.Scan(&id) {
+		ids = append(ids, id)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	var scheduled []ScheduledPrice
+	for _, id := range ids {
+		s, err := r.GetScheduled(id.String())
+		if err != nil {
+			return nil, err
+		}
+		scheduled = append(scheduled, s)
+	}
+	sortScheduledPrices(scheduled)
+	return scheduled, nil
+}
+
+func (r *CassandraPriceRepository) Due(now time.Time) ([]ScheduledPrice, error) {
+	scanner := r.session.Query("SELECT " + cassandraScheduledPriceColumns + " FROM scheduled_prices").Iter().Scanner()
+	var due []ScheduledPrice
+	for scanner.Next() {
+		scheduled, err := scanScheduledPrice(scanner.Scan)
+		if err != nil {
+			return nil, err
+		}
+		if scheduled.Status == SchedulePending && !scheduled.EffectiveAt.After(now) {
+			due = append(due, scheduled)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sortScheduledPrices(due)
+	return due, nil
+}
+
+func (r *CassandraPriceRepository) SetScheduleStatus(id string, from, to ScheduleStatus) error {
+	scheduled, err := r.GetScheduled(id)
+	if err != nil {
+		return err
+	}
+	parsedUUID, _ := gocql.ParseUUID(scheduled.ID)
+	var current ScheduleStatus
+	applied, err := r.session.Query(
+		"UPDATE scheduled_prices SET status = ?, updated_at = ? WHERE id = ? IF status = ?",
+		to, time.Now().UTC(), parsedUUID, from,
+	).ScanCAS(&current)
+	if err != nil {
+		return err
+	}
+	if !applied {
+		return ErrScheduleStatusChanged
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestCassandraPriceRepository_HistoryAndSchedule tests recording price changes and applying scheduled prices exactly once.
+func TestCassandraPriceRepository_HistoryAndSchedule(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	albumID := gocql.TimeUUID().String()
+	repo := NewCassandraPriceRepository(session)
+
+	_, err := repo.RecordChange(PriceChange{AlbumID: albumID, OldPrice: money.MustParse("10.00"), NewPrice: money.MustParse("12.00"), Source: PriceSourceManual, Actor: "staff-1"})
+	require.NoError(t, err)
+	_, err = repo.RecordChange(PriceChange{AlbumID: albumID, OldPrice: money.MustParse("12.00"), NewPrice: money.MustParse("9.00"), Source: PriceSourceScheduled})
+	require.NoError(t, err)
+	changes, err := repo.ListChanges(albumID)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Equal(t, money.MustParse("9.00"), changes[0].NewPrice, "newest first")
+	require.Equal(t, money.MustParse("12.00"), changes[0].OldPrice)
+	require.Equal(t, "staff-1", changes[1].Actor)
+
+	due, err := repo.Schedule(ScheduledPrice{AlbumID: albumID, Price: money.MustParse("8.00"), EffectiveAt: time.Now().Add(-time.Minute), Actor: "staff-1"})
+	require.NoError(t, err)
+	_, err = repo.Schedule(ScheduledPrice{AlbumID: albumID, Price: money.MustParse("7.00"), EffectiveAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+
+	dueNow, err := repo.Due(time.Now())
+	require.NoError(t, err)
+	require.Len(t, dueNow, 1)
+	require.Equal(t, due.ID, dueNow[0].ID)
+	require.Equal(t, money.MustParse("8.00"), dueNow[0].Price)
+
+	require.NoError(t, repo.SetScheduleStatus(due.ID, SchedulePending, ScheduleApplied))
+	require.True(t, errors.Is(repo.SetScheduleStatus(due.ID, SchedulePending, ScheduleApplied), ErrScheduleStatusChanged))
+	require.True(t, errors.Is(repo.SetScheduleStatus("not-a-uuid", SchedulePending, ScheduleApplied), ErrScheduledPriceNotFound))
+
+	dueNow, err = repo.Due(time.Now())
+	require.NoError(t, err)
+	require.Empty(t, dueNow, "an applied price is no longer due")
+
+	scheduled, err := repo.ListScheduled(albumID)
+	require.NoError(t, err)
+	require.Len(t, scheduled, 2)
+	require.Equal(t, ScheduleApplied, scheduled[0].Status)
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// CassandraPromotionRepository claims coupon codes in promotions_by_code with a lightweight
+// transaction, and guards the usage count with compare-and-set updates.
+type CassandraPromotionRepository struct {
+	session *gocql.Session
+}
+
+func NewCassandraPromotionRepository(session *gocql.Session) *CassandraPromotionRepository {
+	return &CassandraPromotionRepository{session: session}
+}
+
+// cassandraPromotionColumns lists both value columns: value_decimal holds the exact value, and the older
+// double value column is still written so the decimal migration can be rolled back.
+const cassandraPromotionColumns = "id, name, code, kind, value, value_decimal, scope, starts_at, ends_at, usage_limit, usage_count, active, created_at"
+
+// optionalTime converts a nullable time to a value gocql writes as null when unset.
+func optionalTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+func (r *CassandraPromotionRepository) Create(promotion Promotion) (Promotion, error) {
+	id := gocql.TimeUUID()
+	promotion.Code = NormalizeCode(promotion.Code)
+	if promotion.Code != "" {
+		var existingCode string
+		var existingID gocql.UUID
+		applied, err := r.session.Query(
+			"INSERT INTO promotions_by_code (code, promotion_id) VALUES (?, ?) IF NOT EXISTS",
+			promotion.Code, id,
+		).ScanCAS(&existingCode, &existingID)
+		if err != nil {
+			return Promotion{}, err
+		}
+		if !applied {
+			return Promotion{}, ErrDuplicateCode
+		}
+	}
+
+	scope, err := json.Marshal(promotion.Scope)
+	if err != nil {
+		return Promotion{}, err
+	}
+	promotion.ID = id.String()
+	promotion.UsageCount = 0
+	promotion.CreatedAt = time.Now().UTC()
+	if err := r.session.Query(
+		"INSERT INTO promotions ("+cassandraPromotionColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		id, promotion.Name, promotion.Code, promotion.Kind, promotion.Value, promotion.Value, string(scope),
+		optionalTime(promotion.StartsAt), optionalTime(promotion.EndsAt), promotion.UsageLimit, 0, promotion.Active, promotion.CreatedAt,
+	).Exec(); err != nil {
+		return Promotion{}, err
+	}
+	return promotion, nil
+}
+
+func (r *CassandraPromotionRepository) GetByID(id string) (Promotion, error) {
+	parsedUUID, err := gocql.ParseUUID(id)
+	if err != nil {
+		return Promotion{}, ErrPromotionNotFound
+	}
+	promotion, err := scanPromotion(r.session.Query("SELECT "+cassandraPromotionColumns+" FROM promotions WHERE id = ?", parsedUUID).Scan)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return Promotion{}, ErrPromotionNotFound
+	}
+	return promotion, err
+}
+
+// scanPromotion reads one promotion row using the given scan function.
+func scanPromotion(scan func(dest ...interface{}) error) (Promotion, error) {
+	var promotion Promotion
+	var id gocql.UUID
+	var scope string
+	var startsAt, endsAt time.Time
+	var exact *money.Amount
+	if err := scan(&id, &promotion.Name, &promotion.Code, &promotion.Kind, &promotion.Value, &exact, &scope,
+		&startsAt, &endsAt, &promotion.UsageLimit, &promotion.UsageCount, &promotion.Active, &promotion.CreatedAt); err != nil {
+		return Promotion{}, err
+	}
+	promotion.ID = id.String()
+	promotion.Value = exactOr(exact, promotion.Value)
+	if !startsAt.IsZero() {
+		promotion.StartsAt = &startsAt
+	}
+	if !endsAt.IsZero() {
+		promotion.EndsAt = &endsAt
+	}
+	if err := promotion.Scope.Scan(scope); err != nil && scope != "" {
+		return Promotion{}, err
+	}
+	return promotion, nil
+}
+
+func (r *CassandraPromotionRepository) GetByCode(code string) (Promotion, error) {
+	var id gocql.UUID
+	err := r.session.Query("SELECT promotion_id FROM promotions_by_code WHERE code = ?", NormalizeCode(code)).Scan(&id)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return Promotion{}, ErrPromotionNotFound
+	}
+	if err != nil {
+		return Promotion{}, err
+	}
+	return r.GetByID(id.String())
+}
+
+func (r *CassandraPromotionRepository) List() ([]Promotion, error) {
+	scanner := r.session.Query("SELECT " + cassandraPromotionColumns + " FROM promotions").Iter().Scanner()
+	var promotions []Promotion
+	for scanner.Next() {
+		promotion, err := scanPromotion(scanner.Scan)
+		if err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, promotion)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sortPromotions(promotions)
+	return promotions, nil
+}
+
+func (r *CassandraPromotionRepository) Update(promotion Promotion) (Promotion, error) {
+	existing, err := r.GetByID(promotion.ID)
+	if err != nil {
+		return Promotion{}, err
+	}
+	scope, err := json.Marshal(promotion.Scope)
+	if err != nil {
+		return Promotion{}, err
+	}
+	id, _ := gocql.ParseUUID(existing.ID)
+	if err := r.session.Query(
+		"UPDATE promotions SET name = ?, kind = ?, value = ?, value_decimal = ?, scope = ?, starts_at = ?, ends_at = ?, usage_limit = ?, active = ? WHERE id = ?",
+		promotion.Name, promotion.Kind, promotion.Value, promotion.Value, string(scope),
+		optionalTime(promotion.StartsAt), optionalTime(promotion.EndsAt), promotion.UsageLimit, promotion.Active, id,
+	).Exec(); err != nil {
+		return Promotion{}, err
+	}
+	return r.GetByID(existing.ID)
+}
+
+func (r *CassandraPromotionRepository) Delete(id string) error {
+	promotion, err := r.GetByID(id)
+	if err != nil {
+		return err
+	}
+	parsedUUID, _ := gocql.ParseUUID(promotion.ID)
+	if promotion.Code != "" {
+		if err := r.session.Query("DELETE FROM promotions_by_code WHERE code = ?", promotion.Code).Exec(); err != nil {
+			return err
+		}
+	}
+	return r.session.Query("DELETE FROM promotions WHERE id = ?", parsedUUID).Exec()
+}
+
+func (r *CassandraPromotionRepository) Redeem(id string) error {
+	return r.changeUsage(id, 1)
+}
+
+func (r *CassandraPromotionRepository) Unredeem(id string) error {
+	return r.changeUsage(id, -1)
+}
+
+// changeUsage moves the usage count by delta with compare-and-set, retrying if another checkout got there first.
+func (r *CassandraPromotionRepository) changeUsage(id string, delta int) error {
+	promotion, err := r.GetByID(id)
+	if err != nil {
+		return err
+	}
+	parsedUUID, _ := gocql.ParseUUID(promotion.ID)
+	count := promotion.UsageCount
+	for range maxCASAttempts {
+		next := count + delta
+		if next < 0 {
+			return nil
+		}
+		if delta > 0 && promotion.UsageLimit > 0 && next > promotion.UsageLimit {
+			return ErrPromotionExhausted
+		}
+		var current int
+		applied, err := r.session.Query(
+			"UPDATE promotions SET usage_count = ? WHERE id = ? IF usage_count = ?",
+			next, parsedUUID, count,
+		).ScanCAS(&current)
+		if err != nil {
+			return err
+		}
+		if applied {
+			return nil
+		}
+		count = current
+	}
+	return fmt.Errorf("promotion %s is being redeemed concurrently, please retry", promotion.ID)
+}
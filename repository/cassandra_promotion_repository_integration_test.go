@@ -0,0 +1,92 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestCassandraPromotionRepository_CodesAndLimits tests unique codes and that redemptions stop at the usage limit.
+func TestCassandraPromotionRepository_CodesAndLimits(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraPromotionRepository(session)
+
+	created, err := repo.Create(Promotion{
+		Name: "Motown weekend", Code: "motown20", Kind: DiscountPercentage, Value: money.MustParse("20"),
+		Scope: PromotionScope{Genres: []string{"Motown"}}, UsageLimit: 2, Active: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "MOTOWN20", created.Code)
+
+	_, err = repo.Create(Promotion{Name: "Copy", Code: "MOTOWN20", Kind: DiscountBOGO, Active: true})
+	require.True(t, errors.Is(err, ErrDuplicateCode))
+	_, err = repo.Create(Promotion{Name: "Automatic", Kind: DiscountBOGO, Active: true})
+	require.NoError(t, err, "promotions without a code do not clash")
+
+	found, err := repo.GetByCode(" motown20 ")
+	require.NoError(t, err)
+	require.Equal(t, created.ID, found.ID)
+	require.Equal(t, money.MustParse("20"), found.Value)
+	require.Equal(t, []string{"Motown"}, found.Scope.Genres)
+	require.Nil(t, found.StartsAt)
+
+	require.NoError(t, repo.Redeem(created.ID))
+	require.NoError(t, repo.Redeem(created.ID))
+	require.True(t, errors.Is(repo.Redeem(created.ID), ErrPromotionExhausted))
+	require.NoError(t, repo.Unredeem(created.ID))
+	require.NoError(t, repo.Redeem(created.ID))
+
+	created.Active = false
+	updated, err := repo.Update(created)
+	require.NoError(t, err)
+	require.False(t, updated.Active)
+	require.Equal(t, 2, updated.UsageCount)
+
+	promotions, err := repo.List()
+	require.NoError(t, err)
+	require.Len(t, promotions, 2)
+	require.Equal(t, created.ID, promotions[0].ID)
+
+	require.NoError(t, repo.Delete(created.ID))
+	require.True(t, errors.Is(repo.Delete(created.ID), ErrPromotionNotFound))
+	_, err = repo.GetByCode("MOTOWN20")
+	require.True(t, errors.Is(err, ErrPromotionNotFound), "deleting a promotion frees its code")
+}
+
+// TestCassandraPromotionRepository_ConcurrentRedeem tests that the compare-and-set on the usage count
+// never lets racing checkouts redeem a promotion past its limit.
+func TestCassandraPromotionRepository_ConcurrentRedeem(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraPromotionRepository(session)
+	promotion, err := repo.Create(Promotion{Name: "First three", Code: "EARLY", Kind: DiscountFixed, Value: money.MustParse("5"), UsageLimit: 3, Active: true})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	redeemed := 0
+	for range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := repo.Redeem(promotion.ID); err == nil {
+				mu.Lock()
+				redeemed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	found, err := repo.GetByID(promotion.ID)
+	require.NoError(t, err)
+	require.LessOrEqual(t, redeemed, 3)
+	require.Equal(t, redeemed, found.UsageCount, "every successful redemption is counted once")
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// CassandraReleaseRepository keeps each album's releases in one partition of releases, and claims
+// their barcodes in releases_by_barcode with lightweight transactions so no two releases, or a
+// release and an album, share one.
+type CassandraReleaseRepository struct {
+	session *gocql.Session
+}
+
+func NewCassandraReleaseRepository(session *gocql.Session) *CassandraReleaseRepository {
+	return &CassandraReleaseRepository{session: session}
+}
+
+const cassandraReleaseColumns = "album_id, id, format, edition, year, label_id, label, catalogue_number, barcode, price, currency, created_at, updated_at"
+
+func (r *CassandraReleaseRepository) Create(release Release) (Release, error) {
+	id := gocql.TimeUUID()
+	release.ID = id.String()
+	release.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	release.UpdatedAt = release.CreatedAt
+	if err := r.claimBarcode(id, release.AlbumID, release.Barcode); err != nil {
+		return Release{}, err
+	}
+	if err := r.save(id, release); err != nil {
+		r.releaseBarcode(id, release.Barcode)
+		return Release{}, err
+	}
+	return release, nil
+}
+
+func (r *CassandraReleaseRepository) save(id gocql.UUID, release Release) error {
+	return r.session.Query(
+		"INSERT INTO releases ("+cassandraReleaseColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		release.AlbumID, id, string(release.Format), release.Edition, release.Year, release.LabelID, release.Label,
+		release.CatalogueNumber, release.Barcode, release.Price, release.Currency, release.CreatedAt, release.UpdatedAt,
+	).Exec()
+}
+
+// claimBarcode records that the release of the album has the barcode, failing with
+// ErrDuplicateBarcode if another release or an album already does. Releases without a barcode
+// claim nothing.
+func (r *CassandraReleaseRepository) claimBarcode(id gocql.UUID, albumID, barcode string) error {
+	if barcode == "" {
+		return nil
+	}
+	existing := map[string]interface{}{}
+	applied, err := r.session.Query(
+		"INSERT INTO releases_by_barcode (barcode, release_id, album_id) VALUES (?, ?, ?) IF NOT EXISTS",
+		barcode, id, albumID,
+	).MapScanCAS(existing)
+	if err == nil && !applied && existing["release_id"] != id {
+		err = ErrDuplicateBarcode
+	}
+	if err == nil && applied {
+		if err = checkBarcodeFree(r.session, "SELECT album_id FROM albums_by_barcode WHERE barcode = ?", barcode); err != nil {
+			r.releaseBarcode(id, barcode)
+		}
+	}
+	return err
+}
+
+// releaseBarcode gives up the release's claim to the barcode, unless another release holds it.
+func (r *CassandraReleaseRepository) releaseBarcode(id gocql.UUID, barcode string) {
+	if barcode == "" {
+		return
+	}
+	_ = r.session.Query("DELETE FROM releases_by_barcode WHERE barcode = ? IF release_id = ?", barcode, id).Exec()
+}
+
+func (r *CassandraReleaseRepository) GetByID(albumID, id string) (Release, error) {
+	parsedUUID, err := gocql.ParseUUID(id)
+	if err != nil {
+		return Release{}, ErrReleaseNotFound
+	}
+	release, err := scanRelease(r.session.Query(
+		"SELECT "+cassandraReleaseColumns+" FROM releases WHERE album_id = ? AND id = ?", albumID, parsedUUID,
+	).Scan)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return Release{}, ErrReleaseNotFound
+	}
+	return release, err
+}
+
+func (r *CassandraReleaseRepository) GetByBarcode(barcode string) (Release, error) {
+	var id gocql.UUID
+	var albumID string
+	err := r.session.Query("SELECT release_id, album_id FROM releases_by_barcode WHERE barcode = ?", barcode).Scan(&id, &albumID)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return Release{}, ErrReleaseNotFound
+	}
+	if err != nil {
+		return Release{}, err
+	}
+	if albumID != "" {
+		return r.GetByID(albumID, id.String())
+	}
+	// Barcodes claimed before the album was recorded with them: look for the release among them all.
+	releases, err := r.List()
+	if err != nil {
+		return Release{}, err
+	}
+	for _, release := range releases {
+		if release.ID == id.String() {
+			return release, nil
+		}
+	}
+	return Release{}, ErrReleaseNotFound
+}
+
+// scanRelease reads one row selected with cassandraReleaseColumns using the given scan function.
+func scanRelease(scan func(dest ...interface{}) error) (Release, error) {
+	var release Release
+	var id gocql.UUID
+	var format string
+	if err := scan(&release.AlbumID, &id, &format, &release.Edition, &release.Year, &release.LabelID, &release.Label,
+		&release.CatalogueNumber, &release.Barcode, &release.Price, &release.Currency, &release.CreatedAt, &release.UpdatedAt); err != nil {
+		return Release{}, err
+	}
+	release.ID = id.String()
+	release.Format = Format(format)
+	return release, nil
+}
+
+func (r *CassandraReleaseRepository) ListByAlbum(albumID string) ([]Release, error) {
+	return r.list(r.session.Query("SELECT "+cassandraReleaseColumns+" FROM releases WHERE album_id = ?", albumID))
+}
+
+func (r *CassandraReleaseRepository) List() ([]Release, error) {
+	return r.list(r.session.Query("SELECT " + cassandraReleaseColumns + " FROM releases"))
+}
+
+func (r *CassandraReleaseRepository) list(query *gocql.Query) ([]Release, error) {
+	var releases []Release
+	scanner := query.Iter().Scanner()
+	for scanner.Next() {
+		release, err := scanRelease(scanner.Scan)
+		if err != nil {
+			return nil, err
+		}
+		releases = append(releases, release)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sortReleases(releases)
+	return releases, nil
+}
+
+func (r *CassandraReleaseRepository) Update(release Release) (Release, error) {
+	current, err := r.GetByID(release.AlbumID, release.ID)
+	if err != nil {
+		return Release{}, err
+	}
+	id, _ := gocql.ParseUUID(release.ID)
+	changed := release.Barcode != current.Barcode
+	if changed {
+		if err := r.claimBarcode(id, release.AlbumID, release.Barcode); err != nil {
+			return Release{}, err
+		}
+	}
+	release.CreatedAt = current.CreatedAt
+	release.UpdatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	if err := r.save(id, release); err != nil {
+		if changed {
+			r.releaseBarcode(id, release.Barcode)
+		}
+		return Release{}, err
+	}
+	if changed {
+		r.releaseBarcode(id, current.Barcode)
+	}
+	return release, nil
+}
+
+func (r *CassandraReleaseRepository) Delete(albumID, id string) error {
+	release, err := r.GetByID(albumID, id)
+	if err != nil {
+		return err
+	}
+	parsedUUID, _ := gocql.ParseUUID(id)
+	if err := r.session.Query("DELETE FROM releases WHERE album_id = ? AND id = ?", albumID, parsedUUID).Exec(); err != nil {
+		return err
+	}
+	r.releaseBarcode(parsedUUID, release.Barcode)
+	return nil
+}
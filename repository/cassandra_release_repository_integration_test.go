@@ -0,0 +1,94 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestCassandraReleaseRepository tests keeping an album's releases in order and their barcodes unique.
+func TestCassandraReleaseRepository(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraReleaseRepository(session)
+	albumID := gocql.TimeUUID().String()
+
+	reissue, err := repo.Create(Release{AlbumID: albumID, Format: FormatLP, Edition: "2021 Reissue", Year: 2021, Price: money.MustParse("27.99"), Currency: "GBP"})
+	require.NoError(t, err)
+	anniversary, err := repo.Create(Release{AlbumID: albumID, Format: FormatCD, Edition: "40th Anniversary", Year: 2011, Barcode: "0602527736211", Price: money.MustParse("14.99"), Currency: "GBP"})
+	require.NoError(t, err)
+	original, err := repo.Create(Release{AlbumID: albumID, Format: FormatLP, Edition: "Original", Year: 1971, CatalogueNumber: "TS 310", Price: money.MustParse("60.00"), Currency: "GBP"})
+	require.NoError(t, err)
+
+	_, err = repo.Create(Release{AlbumID: gocql.TimeUUID().String(), Format: FormatCD, Year: 2011, Barcode: "0602527736211", Price: money.MustParse("9.99"), Currency: "GBP"})
+	require.True(t, errors.Is(err, ErrDuplicateBarcode))
+	found, err := repo.GetByBarcode("0602527736211")
+	require.NoError(t, err)
+	require.Equal(t, anniversary.ID, found.ID)
+	require.Equal(t, money.MustParse("14.99"), found.Price)
+
+	releases, err := repo.ListByAlbum(albumID)
+	require.NoError(t, err)
+	require.Len(t, releases, 3)
+	require.Equal(t, []string{original.ID, anniversary.ID, reissue.ID}, []string{releases[0].ID, releases[1].ID, releases[2].ID})
+
+	anniversary.Barcode = "4006381333931"
+	updated, err := repo.Update(anniversary)
+	require.NoError(t, err)
+	require.Equal(t, "4006381333931", updated.Barcode)
+	_, err = repo.GetByBarcode("0602527736211")
+	require.True(t, errors.Is(err, ErrReleaseNotFound), "a release gives up the barcode it changed from")
+	reissue.Barcode = "4006381333931"
+	_, err = repo.Update(reissue)
+	require.True(t, errors.Is(err, ErrDuplicateBarcode))
+
+	_, err = repo.GetByID(gocql.TimeUUID().String(), reissue.ID)
+	require.True(t, errors.Is(err, ErrReleaseNotFound), "releases are found under their own album only")
+	_, err = repo.Update(Release{AlbumID: albumID, ID: gocql.TimeUUID().String(), Format: FormatCD})
+	require.True(t, errors.Is(err, ErrReleaseNotFound))
+
+	require.NoError(t, repo.Delete(albumID, anniversary.ID))
+	require.True(t, errors.Is(repo.Delete(albumID, anniversary.ID), ErrReleaseNotFound))
+	_, err = repo.Create(Release{AlbumID: albumID, Format: FormatCD, Year: 2011, Barcode: "4006381333931", Price: money.MustParse("9.99"), Currency: "GBP"})
+	require.NoError(t, err, "a deleted release's barcode is free again")
+}
+
+// TestCassandraReleaseRepository_ConcurrentCreate tests that the lightweight transactions on releases_by_barcode
+// let only one of several releases created at once with the same barcode exist.
+func TestCassandraReleaseRepository_ConcurrentCreate(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraReleaseRepository(session)
+	albumID := gocql.TimeUUID().String()
+
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = repo.Create(Release{AlbumID: albumID, Format: FormatCD, Year: 2011, Barcode: "0602527736211", Price: money.MustParse("14.99"), Currency: "GBP"})
+		}()
+	}
+	wg.Wait()
+
+	created := 0
+	for _, err := range errs {
+		if err == nil {
+			created++
+		} else {
+			require.True(t, errors.Is(err, ErrDuplicateBarcode), err)
+		}
+	}
+	require.Equal(t, 1, created)
+	releases, err := repo.ListByAlbum(albumID)
+	require.NoError(t, err)
+	require.Len(t, releases, 1)
+}
@@ -1,9 +1,17 @@
 package repository
 
 import (
+	"errors"
+	"time"
+
 	"github.com/gocql/gocql"
+	"github.com/tvergilio/motown-house-backend/money"
 )
 
+// CassandraAlbumRepository claims each album's barcode and catalogue number in albums_by_barcode and
+// albums_by_catalogue_number with lightweight transactions, so no two albums can share them, and
+// indexes albums by tag in albums_by_tag. Albums in the trash keep their barcode and catalogue number,
+// so they can always be restored, but are taken out of albums_by_tag.
 type CassandraAlbumRepository struct {
 	session *gocql.Session
 }
@@ -12,20 +20,58 @@ func NewCassandraAlbumRepository(session *gocql.Session) *CassandraAlbumReposito
 	return &CassandraAlbumRepository{session: session}
 }
 
+// cassandraAlbumColumns lists both price columns: price_decimal holds the exact price, and the older
+// double price column is still written so the decimal migration can be rolled back.
+const cassandraAlbumColumns = "id, title, artist, artist_id, label_id, label, catalogue_number, barcode, price, price_decimal, currency, currency_prices, tax_category, year, image_url, genre, tags"
+
+// cassandraAlbumSelect adds the trash columns, which are only written when an album is deleted.
+const cassandraAlbumSelect = cassandraAlbumColumns + ", deleted_at, deleted_by"
+
+// scanAlbum reads a row selected with cassandraAlbumSelect. Albums saved before the decimal column
+// existed fall back to the double price, rounded to the nearest penny.
+func scanAlbum(scan func(dest ...interface{}) error) (Album, error) {
+	var album Album
+	var cassandraID gocql.UUID
+	var exact *money.Amount
+	var tags []string
+	if err := scan(&cassandraID, &album.Title, &album.Artist, &album.ArtistID, &album.LabelID, &album.Label, &album.CatalogueNumber, &album.Barcode,
+		&album.Price, &exact, &album.Currency, &album.CurrencyPrices, &album.TaxCategory, &album.Year, &album.ImageUrl, &album.Genre, &tags,
+		&album.DeletedAt, &album.DeletedBy); err != nil {
+		return Album{}, err
+	}
+	if len(tags) > 0 {
+		album.Tags = tags
+	}
+	album.ID = cassandraID.String() // Convert UUID to string
+	album.Price = exactOr(exact, album.Price)
+	album.Currency = currencyOrDefault(album.Currency)
+	return album, nil
+}
+
+// exactOr returns the amount read from a decimal column, or the one read from the double column it
+// replaced if the row was written before the decimal column existed and has not been backfilled.
+func exactOr(exact *money.Amount, double money.Amount) money.Amount {
+	if exact != nil {
+		return *exact
+	}
+	return double
+}
+
 func (r *CassandraAlbumRepository) GetAll() ([]Album, error) {
 	var albums []Album
 
-	iter := r.session.Query("SELECT id, title, artist, price, year, image_url, genre FROM albums").Iter()
+	iter := r.session.Query("SELECT " + cassandraAlbumSelect + " FROM albums").Iter()
 	defer iter.Close()
 
-	var cassandraID gocql.UUID
-	for {
-		var album Album // Create a new Album instance for each iteration
-		if !iter.Scan(&cassandraID, &album.Title, &album.Artist, &album.Price, &album.Year, &album.ImageUrl, &album.Genre) {
-			break
+	scanner := iter.Scanner()
+	for scanner.Next() {
+		album, err := scanAlbum(scanner.Scan)
+		if err != nil {
+			return nil, err
+		}
+		if album.DeletedAt == nil {
+			albums = append(albums, album)
 		}
-		album.ID = cassandraID.String() // Convert UUID to string
-		albums = append(albums, album)
 	}
 
 	if err := iter.Close(); err != nil {
@@ -35,39 +81,228 @@ func (r *CassandraAlbumRepository) GetAll() ([]Album, error) {
 	return albums, nil
 }
 
+// GetByID returns gocql.ErrNotFound for albums in the trash, as for albums that do not exist.
 func (r *CassandraAlbumRepository) GetByID(id string) (Album, error) {
-	var album Album
-	var cassandraID gocql.UUID
+	album, err := r.get(id)
+	if err == nil && album.DeletedAt != nil {
+		return Album{}, gocql.ErrNotFound
+	}
+	return album, err
+}
 
+// get returns the album whether or not it is in the trash.
+func (r *CassandraAlbumRepository) get(id string) (Album, error) {
 	// Parse the string ID back to UUID
 	parsedUUID, err := gocql.ParseUUID(id)
 	if err != nil {
 		return Album{}, err
 	}
 
-	err = r.session.Query(
-		"SELECT id, title, artist, price, year, image_url, genre FROM albums WHERE id = ? LIMIT 1",
+	return scanAlbum(r.session.Query(
+		"SELECT "+cassandraAlbumSelect+" FROM albums WHERE id = ? LIMIT 1",
 		parsedUUID,
-	).Scan(&cassandraID, &album.Title, &album.Artist, &album.Price, &album.Year, &album.ImageUrl, &album.Genre)
+	).Scan)
+}
 
-	if err != nil {
-		return Album{}, err
+func (r *CassandraAlbumRepository) GetByBarcode(barcode string) (Album, error) {
+	return r.lookup("SELECT album_id FROM albums_by_barcode WHERE barcode = ?", barcode)
+}
+
+func (r *CassandraAlbumRepository) GetByCatalogueNumber(labelID, catalogueNumber string) (Album, error) {
+	return r.lookup("SELECT album_id FROM albums_by_catalogue_number WHERE label_id = ? AND catalogue_number = ?", labelID, catalogueNumber)
+}
+
+func (r *CassandraAlbumRepository) GetByTag(tag string) ([]Album, error) {
+	var ids []gocql.UUID
+	var id gocql.UUID
+	iter := r.session.Query("SELECT album_id FROM albums_by_tag WHERE tag = ?", tag).Iter()
+	for iter.Scan(&id) {
+		ids = append(ids, id)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
 	}
+	var albums []Album
+	for _, id := range ids {
+		album, err := r.GetByID(id.String())
+		if errors.Is(err, gocql.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		albums = append(albums, album)
+	}
+	return albums, nil
+}
 
-	album.ID = cassandraID.String()
-	return album, nil
+// TagCounts counts the rows of albums_by_tag, which has one per album and tag.
+func (r *CassandraAlbumRepository) TagCounts() ([]TagCount, error) {
+	byTag := map[string]int{}
+	var tag string
+	iter := r.session.Query("SELECT tag FROM albums_by_tag").Iter()
+	for iter.Scan(&tag) {
+		byTag[tag]++
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	counts := make([]TagCount, 0, len(byTag))
+	for tag, count := range byTag {
+		counts = append(counts, TagCount{Tag: tag, Count: count})
+	}
+	sortTagCounts(counts)
+	return counts, nil
+}
+
+// indexTags adds the album to albums_by_tag under the tags it has gained and removes it from those
+// it has lost.
+func (r *CassandraAlbumRepository) indexTags(id gocql.UUID, before, after Tags) error {
+	kept := map[string]bool{}
+	for _, tag := range after {
+		kept[tag] = true
+	}
+	had := map[string]bool{}
+	batch := r.session.NewBatch(gocql.LoggedBatch)
+	for _, tag := range before {
+		had[tag] = true
+		if !kept[tag] {
+			batch.Query("DELETE FROM albums_by_tag WHERE tag = ? AND album_id = ?", tag, id)
+		}
+	}
+	for _, tag := range after {
+		if !had[tag] {
+			batch.Query("INSERT INTO albums_by_tag (tag, album_id) VALUES (?, ?)", tag, id)
+		}
+	}
+	if batch.Size() == 0 {
+		return nil
+	}
+	return r.session.ExecuteBatch(batch)
+}
+
+// lookup finds the album whose ID the query selects from one of the lookup tables.
+func (r *CassandraAlbumRepository) lookup(query string, values ...interface{}) (Album, error) {
+	var albumID gocql.UUID
+	err := r.session.Query(query, values...).Scan(&albumID)
+	if err == nil {
+		var album Album
+		album, err = r.GetByID(albumID.String())
+		if err == nil {
+			return album, nil
+		}
+	}
+	if errors.Is(err, gocql.ErrNotFound) {
+		return Album{}, ErrAlbumNotFound
+	}
+	return Album{}, err
+}
+
+// claimIdentifiers records that the album has its barcode and catalogue number. Identifiers the
+// album already holds are kept. If another album (or, for the barcode, a release) has either, whatever was claimed here is given up
+// and ErrDuplicateBarcode or ErrDuplicateCatalogueNumber is returned.
+func (r *CassandraAlbumRepository) claimIdentifiers(id gocql.UUID, album Album) error {
+	claimedBarcode := false
+	if album.Barcode != "" {
+		existing := map[string]interface{}{}
+		applied, err := r.session.Query(
+			"INSERT INTO albums_by_barcode (barcode, album_id) VALUES (?, ?) IF NOT EXISTS",
+			album.Barcode, id,
+		).MapScanCAS(existing)
+		if err != nil {
+			return err
+		}
+		if !applied && existing["album_id"] != id {
+			return ErrDuplicateBarcode
+		}
+		claimedBarcode = applied
+		if applied {
+			if err := checkBarcodeFree(r.session, "SELECT release_id FROM releases_by_barcode WHERE barcode = ?", album.Barcode); err != nil {
+				r.releaseIdentifiers(id, Album{Barcode: album.Barcode})
+				return err
+			}
+		}
+	}
+	if album.CatalogueNumber != "" {
+		existing := map[string]interface{}{}
+		applied, err := r.session.Query(
+			"INSERT INTO albums_by_catalogue_number (label_id, catalogue_number, album_id) VALUES (?, ?, ?) IF NOT EXISTS",
+			album.LabelID, album.CatalogueNumber, id,
+		).MapScanCAS(existing)
+		if err == nil && !applied && existing["album_id"] != id {
+			err = ErrDuplicateCatalogueNumber
+		}
+		if err != nil {
+			if claimedBarcode {
+				r.releaseIdentifiers(id, Album{Barcode: album.Barcode})
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// checkBarcodeFree returns ErrDuplicateBarcode if the query, which looks the barcode up in the other
+// of albums_by_barcode and releases_by_barcode, finds it. Albums and releases share one namespace of
+// barcodes: each claims a barcode in its own table and then checks the other's, so of an album and a
+// release claiming the same barcode at once at least one sees the other and gives up.
+func checkBarcodeFree(session *gocql.Session, query, barcode string) error {
+	var owner string
+	err := session.Query(query, barcode).Scan(&owner)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return nil
+	}
+	if err == nil {
+		err = ErrDuplicateBarcode
+	}
+	return err
+}
+
+// releaseIdentifiers gives up the album's claim to its barcode and catalogue number, leaving any
+// that another album holds.
+func (r *CassandraAlbumRepository) releaseIdentifiers(id gocql.UUID, album Album) {
+	if album.Barcode != "" {
+		_ = r.session.Query("DELETE FROM albums_by_barcode WHERE barcode = ? IF album_id = ?", album.Barcode, id).Exec()
+	}
+	if album.CatalogueNumber != "" {
+		_ = r.session.Query(
+			"DELETE FROM albums_by_catalogue_number WHERE label_id = ? AND catalogue_number = ? IF album_id = ?",
+			album.LabelID, album.CatalogueNumber, id,
+		).Exec()
+	}
+}
+
+// changedIdentifiers returns the identifiers before holds that after does not, for releasing.
+func changedIdentifiers(before, after Album) Album {
+	var changed Album
+	if before.Barcode != after.Barcode {
+		changed.Barcode = before.Barcode
+	}
+	if before.LabelID != after.LabelID || before.CatalogueNumber != after.CatalogueNumber {
+		changed.LabelID, changed.CatalogueNumber = before.LabelID, before.CatalogueNumber
+	}
+	return changed
 }
 
 func (r *CassandraAlbumRepository) Create(album Album) error {
 	// Generate a new UUID for the album
 	albumID := gocql.TimeUUID()
 
+	if err := r.claimIdentifiers(albumID, album); err != nil {
+		return err
+	}
 	err := r.session.Query(
-		"INSERT INTO albums (id, title, artist, price, year, image_url, genre) VALUES (?, ?, ?, ?, ?, ?, ?)",
-		albumID, album.Title, album.Artist, album.Price, album.Year, album.ImageUrl, album.Genre,
+		"INSERT INTO albums ("+cassandraAlbumColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		albumID, album.Title, album.Artist, album.ArtistID, album.LabelID, album.Label, album.CatalogueNumber, album.Barcode,
+		album.Price, album.Price, currencyOrDefault(album.Currency), album.CurrencyPrices, album.TaxCategory, album.Year, album.ImageUrl, album.Genre,
+		[]string(album.Tags),
 	).Exec()
+	if err != nil {
+		r.releaseIdentifiers(albumID, album)
+		return err
+	}
 
-	return err
+	return r.indexTags(albumID, nil, album.Tags)
 }
 
 func (r *CassandraAlbumRepository) Update(album Album) error {
@@ -77,12 +312,29 @@ func (r *CassandraAlbumRepository) Update(album Album) error {
 		return err
 	}
 
+	current, err := r.get(album.ID)
+	if errors.Is(err, gocql.ErrNotFound) || (err == nil && current.DeletedAt != nil) {
+		return ErrAlbumNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := r.claimIdentifiers(parsedUUID, album); err != nil {
+		return err
+	}
 	err = r.session.Query(
-		"UPDATE albums SET title = ?, artist = ?, price = ?, year = ?, image_url = ?, genre = ? WHERE id = ?",
-		album.Title, album.Artist, album.Price, album.Year, album.ImageUrl, album.Genre, parsedUUID,
+		"UPDATE albums SET title = ?, artist = ?, artist_id = ?, label_id = ?, label = ?, catalogue_number = ?, barcode = ?, price = ?, price_decimal = ?, currency = ?, currency_prices = ?, tax_category = ?, year = ?, image_url = ?, genre = ?, tags = ? WHERE id = ?",
+		album.Title, album.Artist, album.ArtistID, album.LabelID, album.Label, album.CatalogueNumber, album.Barcode,
+		album.Price, album.Price, currencyOrDefault(album.Currency), album.CurrencyPrices, album.TaxCategory, album.Year, album.ImageUrl, album.Genre,
+		[]string(album.Tags), parsedUUID,
 	).Exec()
+	if err != nil {
+		r.releaseIdentifiers(parsedUUID, changedIdentifiers(album, current))
+		return err
+	}
+	r.releaseIdentifiers(parsedUUID, changedIdentifiers(current, album))
 
-	return err
+	return r.indexTags(parsedUUID, current.Tags, album.Tags)
 }
 
 func (r *CassandraAlbumRepository) Delete(id string) error {
@@ -92,10 +344,71 @@ func (r *CassandraAlbumRepository) Delete(id string) error {
 		return err
 	}
 
+	current, err := r.get(id)
+	if err != nil && !errors.Is(err, gocql.ErrNotFound) {
+		return err
+	}
 	err = r.session.Query(
 		"DELETE FROM albums WHERE id = ?",
 		parsedUUID,
 	).Exec()
+	if err != nil {
+		return err
+	}
+	r.releaseIdentifiers(parsedUUID, current)
 
-	return err
+	return r.indexTags(parsedUUID, current.Tags, nil)
+}
+
+func (r *CassandraAlbumRepository) Trash(id, actor string) error {
+	current, err := r.GetByID(id)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return ErrAlbumNotFound
+	}
+	if err != nil {
+		return err
+	}
+	parsedUUID, _ := gocql.ParseUUID(id)
+	if err := r.session.Query(
+		"UPDATE albums SET deleted_at = ?, deleted_by = ? WHERE id = ?",
+		time.Now().UTC().Truncate(time.Millisecond), actor, parsedUUID,
+	).Exec(); err != nil {
+		return err
+	}
+	return r.indexTags(parsedUUID, current.Tags, nil)
+}
+
+func (r *CassandraAlbumRepository) GetTrash() ([]Album, error) {
+	var albums []Album
+	iter := r.session.Query("SELECT " + cassandraAlbumSelect + " FROM albums").Iter()
+	scanner := iter.Scanner()
+	for scanner.Next() {
+		album, err := scanAlbum(scanner.Scan)
+		if err != nil {
+			return nil, err
+		}
+		if album.DeletedAt != nil {
+			albums = append(albums, album)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sortTrash(albums)
+	return albums, nil
+}
+
+func (r *CassandraAlbumRepository) Restore(id string) error {
+	current, err := r.get(id)
+	if errors.Is(err, gocql.ErrNotFound) || (err == nil && current.DeletedAt == nil) {
+		return ErrAlbumNotFound
+	}
+	if err != nil {
+		return err
+	}
+	parsedUUID, _ := gocql.ParseUUID(id)
+	if err := r.session.Query("DELETE deleted_at, deleted_by FROM albums WHERE id = ?", parsedUUID).Exec(); err != nil {
+		return err
+	}
+	return r.indexTags(parsedUUID, nil, current.Tags)
 }
@@ -5,10 +5,13 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -17,6 +20,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
+	"github.com/tvergilio/motown-house-backend/money"
 )
 
 // Note: Cassandra integration tests use optimizations (disabled gossip/vnodes) for faster startup.
@@ -112,39 +116,54 @@ func setupTestCassandra(t *testing.T) (*gocql.Session, func()) {
 	return session, teardown
 }
 
-// runCassandraMigrations runs the actual Cassandra migration files
+// runCassandraMigrations runs the actual Cassandra migration files, in version order
 func runCassandraMigrations(session *gocql.Session) error {
-	// Find migration file relative to current file's directory
+	// Find migration files relative to current file's directory
 	_, currentFile, _, _ := runtime.Caller(0)
 	projectRoot := filepath.Dir(filepath.Dir(currentFile)) // Go up two levels from repository/
-	migrationFile := filepath.Join(projectRoot, "migrations", "cassandra", "1_create_albums_table.up.cql")
-
-	content, err := os.ReadFile(migrationFile)
+	migrationFiles, err := filepath.Glob(filepath.Join(projectRoot, "migrations", "cassandra", "*.up.cql"))
 	if err != nil {
-		return fmt.Errorf("failed to read migration file %s: %w", migrationFile, err)
+		return err
 	}
+	sort.Slice(migrationFiles, func(i, j int) bool {
+		return migrationVersion(migrationFiles[i]) < migrationVersion(migrationFiles[j])
+	})
 
-	// Split by semicolons and execute each statement
-	statements := strings.Split(string(content), ";")
-	for _, stmt := range statements {
-		stmt = strings.TrimSpace(stmt)
-		if stmt == "" || strings.HasPrefix(stmt, "--") {
-			continue
+	for _, migrationFile := range migrationFiles {
+		content, err := os.ReadFile(migrationFile)
+		if err != nil {
+			return fmt.Errorf("failed to read migration file %s: %w", migrationFile, err)
 		}
-		if err := session.Query(stmt).Exec(); err != nil {
-			return fmt.Errorf("failed to execute statement %q: %w", stmt, err)
+
+		// Split by semicolons and execute each statement
+		statements := strings.Split(string(content), ";")
+		for _, stmt := range statements {
+			stmt = strings.TrimSpace(stmt)
+			if stmt == "" || strings.HasPrefix(stmt, "--") {
+				continue
+			}
+			if err := session.Query(stmt).Exec(); err != nil {
+				return fmt.Errorf("failed to execute statement %q: %w", stmt, err)
+			}
 		}
 	}
 	return nil
 }
 
+// migrationVersion extracts the numeric prefix from a migration file name such as "2_create_inventory_tables.up.cql"
+func migrationVersion(path string) int {
+	prefix, _, _ := strings.Cut(filepath.Base(path), "_")
+	version, _ := strconv.Atoi(prefix)
+	return version
+}
+
 // TestCassandraAlbumRepository_Create tests only the Create method.
 func TestCassandraAlbumRepository_Create(t *testing.T) {
 	session, teardown := setupTestCassandra(t)
 	defer teardown()
 	repo := NewCassandraAlbumRepository(session)
 
-	album := Album{Title: "Where Did Our Love Go", Artist: "The Supremes", Price: 9.99, Year: 1964, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music123/v4/5d/c2/4d/5dc24de8-15d7-16e0-7585-72a2bcc721de/14UMGIM62198.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"}
+	album := Album{Title: "Where Did Our Love Go", Artist: "The Supremes", Price: money.MustParse("9.99"), Year: 1964, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music123/v4/5d/c2/4d/5dc24de8-15d7-16e0-7585-72a2bcc721de/14UMGIM62198.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"}
 	err := repo.Create(album)
 	require.NoError(t, err)
 
@@ -153,7 +172,7 @@ func TestCassandraAlbumRepository_Create(t *testing.T) {
 	require.Len(t, albums, 1)
 	require.Equal(t, "Where Did Our Love Go", albums[0].Title)
 	require.Equal(t, "The Supremes", albums[0].Artist)
-	require.Equal(t, 9.99, albums[0].Price)
+	require.Equal(t, money.MustParse("9.99"), albums[0].Price)
 	require.Equal(t, 1964, albums[0].Year)
 	require.Equal(t, "https://is1-ssl.mzstatic.com/image/thumb/Music123/v4/5d/c2/4d/5dc24de8-15d7-16e0-7585-72a2bcc721de/14UMGIM62198.rgb.jpg/100x100bb.jpg", albums[0].ImageUrl)
 	require.Equal(t, "R&B/Soul", albums[0].Genre)
@@ -166,9 +185,9 @@ func TestCassandraAlbumRepository_GetAll(t *testing.T) {
 	defer teardown()
 	repo := NewCassandraAlbumRepository(session)
 
-	_ = repo.Create(Album{Title: "ABC", Artist: "Jackson 5", Price: 1.0, Year: 1970, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music211/v4/cb/38/70/cb3870c2-1a9b-9310-e218-9d0f5a5e98f5/06UMGIM05267.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"})
-	_ = repo.Create(Album{Title: "Diana", Artist: "Diana Ross", Price: 2.0, Year: 1980, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music115/v4/aa/87/1c/aa871c20-95be-38bd-97e3-ecfeb8ec404b/15UMGIM06551.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"})
-	_ = repo.Create(Album{Title: "Sex Machine", Artist: "James Brown", Price: 3.0, Year: 1970, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music128/v4/17/8b/05/178b05de-5855-0136-9827-a0e8a6ccf3db/00602547021656.rgb.jpg/100x100bb.jpg", Genre: "Soul"})
+	_ = repo.Create(Album{Title: "ABC", Artist: "Jackson 5", Price: money.MustParse("1.00"), Year: 1970, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music211/v4/cb/38/70/cb3870c2-1a9b-9310-e218-9d0f5a5e98f5/06UMGIM05267.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"})
+	_ = repo.Create(Album{Title: "Diana", Artist: "Diana Ross", Price: money.MustParse("2.00"), Year: 1980, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music115/v4/aa/87/1c/aa871c20-95be-38bd-97e3-ecfeb8ec404b/15UMGIM06551.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"})
+	_ = repo.Create(Album{Title: "Sex Machine", Artist: "James Brown", Price: money.MustParse("3.00"), Year: 1970, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music128/v4/17/8b/05/178b05de-5855-0136-9827-a0e8a6ccf3db/00602547021656.rgb.jpg/100x100bb.jpg", Genre: "Soul"})
 
 	albums, err := repo.GetAll()
 	require.NoError(t, err)
@@ -180,7 +199,7 @@ func TestCassandraAlbumRepository_GetAll(t *testing.T) {
 		titles[album.Title] = true
 		require.NotEmpty(t, album.ID)
 		require.NotEmpty(t, album.Artist)
-		require.Greater(t, album.Price, 0.0)
+		require.Greater(t, album.Price, money.Amount(0))
 		require.Greater(t, album.Year, 0)
 		require.NotEmpty(t, album.ImageUrl)
 		require.NotEmpty(t, album.Genre)
@@ -197,7 +216,7 @@ func TestCassandraAlbumRepository_GetByID(t *testing.T) {
 	defer teardown()
 	repo := NewCassandraAlbumRepository(session)
 
-	_ = repo.Create(Album{Title: "ABC", Artist: "Jackson 5", Price: 1.0, Year: 1970, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music211/v4/cb/38/70/cb3870c2-1a9b-9310-e218-9d0f5a5e98f5/06UMGIM05267.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"})
+	_ = repo.Create(Album{Title: "ABC", Artist: "Jackson 5", Price: money.MustParse("1.00"), Year: 1970, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music211/v4/cb/38/70/cb3870c2-1a9b-9310-e218-9d0f5a5e98f5/06UMGIM05267.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"})
 	albums, err := repo.GetAll()
 	require.NoError(t, err)
 	require.NotEmpty(t, albums)
@@ -207,7 +226,7 @@ func TestCassandraAlbumRepository_GetByID(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "ABC", got.Title)
 	require.Equal(t, "Jackson 5", got.Artist)
-	require.Equal(t, 1.0, got.Price)
+	require.Equal(t, money.MustParse("1.00"), got.Price)
 	require.Equal(t, 1970, got.Year)
 	require.Equal(t, "https://is1-ssl.mzstatic.com/image/thumb/Music211/v4/cb/38/70/cb3870c2-1a9b-9310-e218-9d0f5a5e98f5/06UMGIM05267.rgb.jpg/100x100bb.jpg", got.ImageUrl)
 	require.Equal(t, "R&B/Soul", got.Genre)
@@ -221,7 +240,7 @@ func TestCassandraAlbumRepository_Update(t *testing.T) {
 	repo := NewCassandraAlbumRepository(session)
 
 	// Create an album
-	album := Album{Title: "ABC", Artist: "Shakira", Price: 1.0, Year: 2024, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music115/v4/8d/97/f4/8d97f427-2d17-1a51-1714-324483eb5fc1/886443546264.jpg/100x100bb.jpg", Genre: "Pop"}
+	album := Album{Title: "ABC", Artist: "Shakira", Price: money.MustParse("1.00"), Year: 2024, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music115/v4/8d/97/f4/8d97f427-2d17-1a51-1714-324483eb5fc1/886443546264.jpg/100x100bb.jpg", Genre: "Pop"}
 	err := repo.Create(album)
 	require.NoError(t, err)
 
@@ -232,7 +251,7 @@ func TestCassandraAlbumRepository_Update(t *testing.T) {
 	id := albums[0].ID
 
 	// Update the album
-	updated := Album{ID: id, Title: "ABC", Artist: "Jackson 5", Price: 20.0, Year: 1970, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music211/v4/cb/38/70/cb3870c2-1a9b-9310-e218-9d0f5a5e98f5/06UMGIM05267.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"}
+	updated := Album{ID: id, Title: "ABC", Artist: "Jackson 5", Price: money.MustParse("20.00"), Year: 1970, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music211/v4/cb/38/70/cb3870c2-1a9b-9310-e218-9d0f5a5e98f5/06UMGIM05267.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"}
 	err = repo.Update(updated)
 	require.NoError(t, err)
 
@@ -241,11 +260,56 @@ func TestCassandraAlbumRepository_Update(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "ABC", got.Title)
 	require.Equal(t, "Jackson 5", got.Artist)
-	require.Equal(t, 20.0, got.Price)
+	require.Equal(t, money.MustParse("20.00"), got.Price)
 	require.Equal(t, 1970, got.Year)
 	require.Equal(t, "https://is1-ssl.mzstatic.com/image/thumb/Music211/v4/cb/38/70/cb3870c2-1a9b-9310-e218-9d0f5a5e98f5/06UMGIM05267.rgb.jpg/100x100bb.jpg", got.ImageUrl)
 	require.Equal(t, "R&B/Soul", got.Genre)
 	require.Equal(t, id, got.ID)
+
+	// Updating an album that does not exist does not create it
+	missing := updated
+	missing.ID = gocql.TimeUUID().String()
+	require.True(t, errors.Is(repo.Update(missing), ErrAlbumNotFound))
+	_, err = repo.GetByID(missing.ID)
+	require.Error(t, err)
+}
+
+// TestCassandraAlbumRepository_TrashAndRestore tests that trashed albums are hidden until restored.
+func TestCassandraAlbumRepository_TrashAndRestore(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraAlbumRepository(session)
+
+	require.NoError(t, repo.Create(Album{Title: "ABC", Artist: "Jackson 5", Price: money.MustParse("1.00"), Year: 1970, ImageUrl: "x", Genre: "R&B/Soul"}))
+	albums, err := repo.GetAll()
+	require.NoError(t, err)
+	id := albums[0].ID
+
+	require.NoError(t, repo.Trash(id, "staff-1"))
+	require.True(t, errors.Is(repo.Trash(id, "staff-1"), ErrAlbumNotFound))
+	trashed := albums[0]
+	trashed.Title = "ABC (Remastered)"
+	require.True(t, errors.Is(repo.Update(trashed), ErrAlbumNotFound), "albums in the trash cannot be edited")
+	albums, err = repo.GetAll()
+	require.NoError(t, err)
+	require.Len(t, albums, 0)
+	_, err = repo.GetByID(id)
+	require.Error(t, err)
+	trash, err := repo.GetTrash()
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	require.NotNil(t, trash[0].DeletedAt)
+	require.Equal(t, "staff-1", trash[0].DeletedBy)
+	require.Equal(t, "ABC", trash[0].Title)
+
+	require.NoError(t, repo.Restore(id))
+	require.True(t, errors.Is(repo.Restore(id), ErrAlbumNotFound))
+	got, err := repo.GetByID(id)
+	require.NoError(t, err)
+	require.Nil(t, got.DeletedAt)
+	trash, err = repo.GetTrash()
+	require.NoError(t, err)
+	require.Len(t, trash, 0)
 }
 
 // TestCassandraAlbumRepository_Delete tests only the Delete method.
@@ -254,7 +318,7 @@ func TestCassandraAlbumRepository_Delete(t *testing.T) {
 	defer teardown()
 	repo := NewCassandraAlbumRepository(session)
 
-	_ = repo.Create(Album{Title: "ABC", Artist: "Jackson 5", Price: 1.0, Year: 1970, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music211/v4/cb/38/70/cb3870c2-1a9b-9310-e218-9d0f5a5e98f5/06UMGIM05267.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"})
+	_ = repo.Create(Album{Title: "ABC", Artist: "Jackson 5", Price: money.MustParse("1.00"), Year: 1970, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music211/v4/cb/38/70/cb3870c2-1a9b-9310-e218-9d0f5a5e98f5/06UMGIM05267.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"})
 	albums, err := repo.GetAll()
 	require.NoError(t, err)
 	require.NotEmpty(t, albums)
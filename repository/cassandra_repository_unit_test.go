@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
 )
 
 // MockSession is a mock implementation of gocql.Session for unit testing
@@ -62,7 +63,7 @@ func createTestAlbum() Album {
 		ID:       "550e8400-e29b-41d4-a716-446655440000",
 		Title:    "Where Did Our Love Go",
 		Artist:   "The Supremes",
-		Price:    9.99,
+		Price:    money.MustParse("9.99"),
 		Year:     1964,
 		ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music123/v4/5d/c2/4d/5dc24de8-15d7-16e0-7585-72a2bcc721de/14UMGIM62198.rgb.jpg/100x100bb.jpg",
 		Genre:    "R&B/Soul",
@@ -185,7 +186,7 @@ func TestCassandraAlbumRepository_AlbumValidation(t *testing.T) {
 				ID:       "550e8400-e29b-41d4-a716-446655440000",
 				Title:    "",
 				Artist:   "Jackson 5",
-				Price:    1.0,
+				Price:    money.MustParse("1.00"),
 				Year:     1970,
 				ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music211/v4/cb/38/70/cb3870c2-1a9b-9310-e218-9d0f5a5e98f5/06UMGIM05267.rgb.jpg/100x100bb.jpg",
 				Genre:    "R&B/Soul",
@@ -198,7 +199,7 @@ func TestCassandraAlbumRepository_AlbumValidation(t *testing.T) {
 				ID:       "550e8400-e29b-41d4-a716-446655440000",
 				Title:    "ABC",
 				Artist:   "",
-				Price:    1.0,
+				Price:    money.MustParse("1.00"),
 				Year:     1970,
 				ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music211/v4/cb/38/70/cb3870c2-1a9b-9310-e218-9d0f5a5e98f5/06UMGIM05267.rgb.jpg/100x100bb.jpg",
 				Genre:    "R&B/Soul",
@@ -211,7 +212,7 @@ func TestCassandraAlbumRepository_AlbumValidation(t *testing.T) {
 				ID:       "550e8400-e29b-41d4-a716-446655440000",
 				Title:    "Diana",
 				Artist:   "Diana Ross",
-				Price:    -2.0,
+				Price:    money.MustParse("-2.00"),
 				Year:     1980,
 				ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music115/v4/aa/87/1c/aa871c20-95be-38bd-97e3-ecfeb8ec404b/15UMGIM06551.rgb.jpg/100x100bb.jpg",
 				Genre:    "R&B/Soul",
@@ -224,7 +225,7 @@ func TestCassandraAlbumRepository_AlbumValidation(t *testing.T) {
 				ID:       "550e8400-e29b-41d4-a716-446655440000",
 				Title:    "Sex Machine",
 				Artist:   "James Brown",
-				Price:    3.0,
+				Price:    money.MustParse("3.00"),
 				Year:     0,
 				ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music128/v4/17/8b/05/178b05de-5855-0136-9827-a0e8a6ccf3db/00602547021656.rgb.jpg/100x100bb.jpg",
 				Genre:    "Soul",
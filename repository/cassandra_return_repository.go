@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// CassandraReturnRepository keeps lookup tables of returns by order and by user alongside the
+// returns themselves. Updates are guarded by a lightweight transaction on the current status.
+type CassandraReturnRepository struct {
+	session *gocql.Session
+}
+
+func NewCassandraReturnRepository(session *gocql.Session) *CassandraReturnRepository {
+	return &CassandraReturnRepository{session: session}
+}
+
+func (r *CassandraReturnRepository) Create(ret Return) (Return, error) {
+	orderID, err := gocql.ParseUUID(ret.OrderID)
+	if err != nil {
+		return Return{}, ErrOrderNotFound
+	}
+	returnID := gocql.TimeUUID()
+	now := time.Now().UTC()
+	ret.ID = returnID.String()
+	ret.Status = ReturnRequested
+	ret.CreatedAt = now
+	ret.UpdatedAt = now
+
+	batch := r.session.NewBatch(gocql.LoggedBatch)
+	batch.Query(
+		"INSERT INTO returns (id, order_id, user_id, status, refund_amount, refunded_amount, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		returnID, orderID, ret.UserID, ret.Status, ret.RefundAmount, ret.RefundedAmount, ret.CreatedAt, ret.UpdatedAt,
+	)
+	batch.Query("INSERT INTO returns_by_order (order_id, return_id) VALUES (?, ?)", orderID, returnID)
+	batch.Query("INSERT INTO returns_by_user (user_id, created_at, return_id) VALUES (?, ?, ?)", ret.UserID, ret.CreatedAt, returnID)
+	for i, line := range ret.Lines {
+		albumID, err := gocql.ParseUUID(line.AlbumID)
+		if err != nil {
+			return Return{}, err
+		}
+		batch.Query(
+			"INSERT INTO return_lines (return_id, line_no, album_id, release_id, format, title, quantity, reason, note, refund, restocked) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			returnID, i, albumID, line.ReleaseID, line.Format, line.Title, line.Quantity, line.Reason, line.Note, line.Refund, 0,
+		)
+	}
+	batch.Query(
+		"INSERT INTO return_events (return_id, id, status, actor, note) VALUES (?, ?, ?, ?, ?)",
+		returnID, gocql.TimeUUID(), ReturnRequested, ret.UserID, "",
+	)
+	if err := r.session.ExecuteBatch(batch); err != nil {
+		return Return{}, err
+	}
+	return r.GetByID(ret.ID)
+}
+
+func (r *CassandraReturnRepository) GetByID(id string) (Return, error) {
+	parsedUUID, err := gocql.ParseUUID(id)
+	if err != nil {
+		return Return{}, ErrReturnNotFound
+	}
+
+	var ret Return
+	var cassandraID, orderID gocql.UUID
+	err = r.session.Query(
+		"SELECT id, order_id, user_id, status, refund_amount, refunded_amount, created_at, updated_at FROM returns WHERE id = ?",
+		parsedUUID,
+	).Scan(&cassandraID, &orderID, &ret.UserID, &ret.Status, &ret.RefundAmount, &ret.RefundedAmount, &ret.CreatedAt, &ret.UpdatedAt)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return Return{}, ErrReturnNotFound
+	}
+	if err != nil {
+		return Return{}, err
+	}
+	ret.ID = cassandraID.String()
+	ret.OrderID = orderID.String()
+
+	iter := r.session.Query(
+		"SELECT album_id, release_id, format, title, quantity, reason, note, refund, restock, media_grade, sleeve_grade, restocked FROM return_lines WHERE return_id = ?",
+		parsedUUID,
+	).Iter()
+	var albumID gocql.UUID
+	for {
+		var line ReturnLine
+		if !iter.Scan(&albumID, &line.ReleaseID, &line.Format, &line.Title, &line.Quantity, &line.Reason, &line.Note, &line.Refund,
+			&line.Restock, &line.MediaGrade, &line.SleeveGrade, &line.Restocked) {
+			break
+		}
+		line.AlbumID = albumID.String()
+		ret.Lines = append(ret.Lines, line)
+	}
+	if err := iter.Close(); err != nil {
+		return Return{}, err
+	}
+
+	iter = r.session.Query("SELECT id, status, actor, note FROM return_events WHERE return_id = ?", parsedUUID).Iter()
+	var eventID gocql.UUID
+	for {
+		var event ReturnEvent
+		if !iter.Scan(&eventID, &event.Status, &event.Actor, &event.Note) {
+			break
+		}
+		event.CreatedAt = eventID.Time()
+		ret.History = append(ret.History, event)
+	}
+	if err := iter.Close(); err != nil {
+		return Return{}, err
+	}
+	return ret, nil
+}
+
+func (r *CassandraReturnRepository) ListByOrder(orderID string) ([]Return, error) {
+	parsedUUID, err := gocql.ParseUUID(orderID)
+	if err != nil {
+		return nil, nil
+	}
+	returns, err := r.list(r.session.Query("SELECT return_id FROM returns_by_order WHERE order_id = ?", parsedUUID).Iter(), "")
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(returns, func(i, j int) bool { return returns[i].CreatedAt.Before(returns[j].CreatedAt) })
+	return returns, nil
+}
+
+func (r *CassandraReturnRepository) ListByUser(userID string) ([]Return, error) {
+	return r.list(r.session.Query("SELECT return_id FROM returns_by_user WHERE user_id = ?", userID).Iter(), "")
+}
+
+func (r *CassandraReturnRepository) List(status ReturnStatus) ([]Return, error) {
+	returns, err := r.list(r.session.Query("SELECT id FROM returns").Iter(), status)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(returns, func(i, j int) bool { return returns[i].CreatedAt.After(returns[j].CreatedAt) })
+	return returns, nil
+}
+
+// list loads every return whose ID the iterator yields, keeping those in status (or all if status is empty).
+func (r *CassandraReturnRepository) list(iter *gocql.Iter, status ReturnStatus) ([]Return, error) {
+	var ids []gocql.UUID
+	var id gocql.UUID
+	for iter.Scan(&id) {
+		ids = append(ids, id)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+
+	var returns []Return
+	for _, id := range ids {
+		ret, err := r.GetByID(id.String())
+		if err != nil {
+			return nil, err
+		}
+		if status == "" || ret.Status == status {
+			returns = append(returns, ret)
+		}
+	}
+	return returns, nil
+}
+
+func (r *CassandraReturnRepository) Update(ret Return, from ReturnStatus, actor, note string) (Return, error) {
+	parsedUUID, err := gocql.ParseUUID(ret.ID)
+	if err != nil {
+		return Return{}, ErrReturnNotFound
+	}
+	var current ReturnStatus
+	applied, err := r.session.Query(
+		"UPDATE returns SET status = ?, refunded_amount = ?, updated_at = ? WHERE id = ? IF status = ?",
+		ret.Status, ret.RefundedAmount, time.Now().UTC(), parsedUUID, from,
+	).ScanCAS(&current)
+	if err != nil {
+		return Return{}, err
+	}
+	if !applied {
+		if current == "" {
+			return Return{}, ErrReturnNotFound
+		}
+		return Return{}, ErrReturnStatusChanged
+	}
+
+	batch := r.session.NewBatch(gocql.LoggedBatch)
+	for i, line := range ret.Lines {
+		batch.Query(
+			"UPDATE return_lines SET restock = ?, media_grade = ?, sleeve_grade = ? WHERE return_id = ? AND line_no = ?",
+			line.Restock, line.MediaGrade, line.SleeveGrade, parsedUUID, i,
+		)
+	}
+	batch.Query(
+		"INSERT INTO return_events (return_id, id, status, actor, note) VALUES (?, ?, ?, ?, ?)",
+		parsedUUID, gocql.TimeUUID(), ret.Status, actor, note,
+	)
+	if err := r.session.ExecuteBatch(batch); err != nil {
+		return Return{}, err
+	}
+	return r.GetByID(ret.ID)
+}
+
+// SetRestocked uses a lightweight transaction on the count. Lines received before counts were kept
+// have none, so the condition never holds and they cannot be restocked a second time.
+func (r *CassandraReturnRepository) SetRestocked(id string, line, from, to int) error {
+	parsedUUID, err := gocql.ParseUUID(id)
+	if err != nil {
+		return ErrReturnNotFound
+	}
+	var current int
+	applied, err := r.session.Query(
+		"UPDATE return_lines SET restocked = ? WHERE return_id = ? AND line_no = ? IF restocked = ?",
+		to, parsedUUID, line, from,
+	).ScanCAS(&current)
+	if err != nil {
+		return err
+	}
+	if !applied {
+		if _, err := r.GetByID(id); err != nil {
+			return err
+		}
+		return ErrReturnStatusChanged
+	}
+	return nil
+}
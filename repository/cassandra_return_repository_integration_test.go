@@ -0,0 +1,115 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestCassandraReturnRepository_Lifecycle tests creating a return, moving it through its statuses and listing it.
+func TestCassandraReturnRepository_Lifecycle(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraReturnRepository(session)
+	orderID := gocql.TimeUUID().String()
+	albumID := gocql.TimeUUID().String()
+
+	ret, err := repo.Create(Return{OrderID: orderID, UserID: "user-1", RefundAmount: money.MustParse("1.00"), Lines: []ReturnLine{
+		{AlbumID: albumID, Format: FormatLP, Title: "ABC", Quantity: 2, Reason: ReturnWarped, Note: "dished", Refund: money.MustParse("1.00")},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, ReturnRequested, ret.Status)
+	require.Len(t, ret.Lines, 1)
+	require.Equal(t, "dished", ret.Lines[0].Note)
+	require.Equal(t, money.MustParse("1.00"), ret.Lines[0].Refund)
+	require.Len(t, ret.History, 1)
+
+	ret.Status = ReturnApproved
+	ret, err = repo.Update(ret, ReturnRequested, "staff", "send it back")
+	require.NoError(t, err)
+
+	ret.Status = ReturnReceived
+	ret.Lines[0].Restock = RestockUsed
+	ret.Lines[0].MediaGrade, ret.Lines[0].SleeveGrade = "VG", "VG+"
+	_, err = repo.Update(ret, ReturnRequested, "staff", "")
+	require.True(t, errors.Is(err, ErrReturnStatusChanged))
+	received, err := repo.Update(ret, ReturnApproved, "staff", "")
+	require.NoError(t, err)
+	require.Equal(t, RestockUsed, received.Lines[0].Restock)
+	require.Equal(t, Grade("VG+"), received.Lines[0].SleeveGrade)
+	require.Equal(t, 0, received.Lines[0].Restocked)
+
+	require.NoError(t, repo.SetRestocked(received.ID, 0, 0, 1))
+	require.True(t, errors.Is(repo.SetRestocked(received.ID, 0, 0, 1), ErrReturnStatusChanged), "a unit is restocked once")
+	require.True(t, errors.Is(repo.SetRestocked("not-a-uuid", 0, 0, 1), ErrReturnNotFound))
+
+	received.Status = ReturnRefunded
+	received.RefundedAmount = money.MustParse("0.80")
+	refunded, err := repo.Update(received, ReturnReceived, "staff", "restocking fee")
+	require.NoError(t, err)
+	require.Equal(t, money.MustParse("0.80"), refunded.RefundedAmount)
+	require.Equal(t, 1, refunded.Lines[0].Restocked)
+	require.Len(t, refunded.History, 4)
+	require.Equal(t, "restocking fee", refunded.History[3].Note)
+
+	returns, err := repo.ListByOrder(orderID)
+	require.NoError(t, err)
+	require.Len(t, returns, 1)
+	returns, err = repo.ListByUser("user-1")
+	require.NoError(t, err)
+	require.Len(t, returns, 1)
+	returns, err = repo.List(ReturnRequested)
+	require.NoError(t, err)
+	require.Empty(t, returns)
+
+	_, err = repo.Update(Return{ID: gocql.TimeUUID().String(), Status: ReturnApproved}, ReturnRequested, "staff", "")
+	require.True(t, errors.Is(err, ErrReturnNotFound))
+}
+
+// TestCassandraReturnRepository_ConcurrentUpdate tests that the lightweight transaction on the status lets only
+// one of two members of staff act on a return, and only one request restock each unit.
+func TestCassandraReturnRepository_ConcurrentUpdate(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraReturnRepository(session)
+	ret, err := repo.Create(Return{OrderID: gocql.TimeUUID().String(), UserID: "user-1", Lines: []ReturnLine{
+		{AlbumID: gocql.TimeUUID().String(), Format: FormatCD, Title: "ABC", Quantity: 1, Reason: ReturnChangedMind},
+	}})
+	require.NoError(t, err)
+	ret.Status = ReturnApproved
+
+	var wg sync.WaitGroup
+	updateErrs := make([]error, 3)
+	restockErrs := make([]error, 3)
+	for i := range updateErrs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, updateErrs[i] = repo.Update(ret, ReturnRequested, "staff", "")
+			restockErrs[i] = repo.SetRestocked(ret.ID, 0, 0, 1)
+		}()
+	}
+	wg.Wait()
+
+	for _, errs := range [][]error{updateErrs, restockErrs} {
+		succeeded := 0
+		for _, err := range errs {
+			if err == nil {
+				succeeded++
+			} else {
+				require.True(t, errors.Is(err, ErrReturnStatusChanged), err)
+			}
+		}
+		require.Equal(t, 1, succeeded)
+	}
+	approved, err := repo.GetByID(ret.ID)
+	require.NoError(t, err)
+	require.Len(t, approved.History, 2)
+}
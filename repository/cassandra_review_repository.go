@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// CassandraReviewRepository keeps lookup tables of reviews by album and by moderation status, claims
+// each (album, user) pair with a lightweight transaction so a user can only review an album once,
+// and keeps each album's count and total of approved ratings in counters.
+type CassandraReviewRepository struct {
+	session *gocql.Session
+}
+
+func NewCassandraReviewRepository(session *gocql.Session) *CassandraReviewRepository {
+	return &CassandraReviewRepository{session: session}
+}
+
+const cassandraReviewColumns = "id, album_id, user_id, author, rating, title, body, status, created_at, updated_at"
+
+func (r *CassandraReviewRepository) Create(review Review) (Review, error) {
+	id := gocql.TimeUUID()
+	review.ID = id.String()
+	if review.Status == "" {
+		review.Status = ReviewPending
+	}
+	review.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	review.UpdatedAt = review.CreatedAt
+
+	var existingAlbumID, existingUserID string
+	var existingID gocql.UUID
+	applied, err := r.session.Query(
+		"INSERT INTO review_authors (album_id, user_id, review_id) VALUES (?, ?, ?) IF NOT EXISTS",
+		review.AlbumID, review.UserID, id,
+	).ScanCAS(&existingAlbumID, &existingUserID, &existingID)
+	if err != nil {
+		return Review{}, err
+	}
+	if !applied {
+		return Review{}, ErrDuplicateReview
+	}
+
+	batch := r.session.NewBatch(gocql.LoggedBatch)
+	batch.Query(
+		"INSERT INTO reviews ("+cassandraReviewColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		id, review.AlbumID, review.UserID, review.Author, review.Rating, review.Title, review.Body,
+		review.Status, review.CreatedAt, review.UpdatedAt,
+	)
+	batch.Query("INSERT INTO reviews_by_album (album_id, id) VALUES (?, ?)", review.AlbumID, id)
+	batch.Query("INSERT INTO reviews_by_status (status, id) VALUES (?, ?)", review.Status, id)
+	if err := r.session.ExecuteBatch(batch); err != nil {
+		return Review{}, err
+	}
+	if review.Status == ReviewApproved {
+		if err := r.count(review, 1); err != nil {
+			return Review{}, err
+		}
+	}
+	return review, nil
+}
+
+// count adds (sign 1) or removes (sign -1) a review's rating from its album's counters.
+func (r *CassandraReviewRepository) count(review Review, sign int) error {
+	return r.session.Query(
+		"UPDATE album_ratings SET review_count = review_count + ?, rating_total = rating_total + ? WHERE album_id = ?",
+		int64(sign), int64(sign*review.Rating), review.AlbumID,
+	).Exec()
+}
+
+func (r *CassandraReviewRepository) GetByID(id string) (Review, error) {
+	parsedUUID, err := gocql.ParseUUID(id)
+	if err != nil {
+		return Review{}, ErrReviewNotFound
+	}
+	review, err := scanReview(r.session.Query("SELECT "+cassandraReviewColumns+" FROM reviews WHERE id = ?", parsedUUID).Scan)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return Review{}, ErrReviewNotFound
+	}
+	return review, err
+}
+
+// scanReview reads one row selected with cassandraReviewColumns using the given scan function.
+func scanReview(scan func(dest ...interface{}) error) (Review, error) {
+	var review Review
+	var id gocql.UUID
+	if err := scan(&id, &review.AlbumID, &review.UserID, &review.Author, &review.Rating, &review.Title,
+		&review.Body, &review.Status, &review.CreatedAt, &review.UpdatedAt); err != nil {
+		return Review{}, err
+	}
+	review.ID = id.String()
+	return review, nil
+}
+
+func (r *CassandraReviewRepository) ListByAlbum(albumID string, status ReviewStatus) ([]Review, error) {
+	reviews, err := r.list(r.session.Query("SELECT id FROM reviews_by_album WHERE album_id = ?", albumID).Iter())
+	if err != nil || status == "" {
+		return reviews, err
+	}
+	matching := reviews[:0]
+	for _, review := range reviews {
+		if review.Status == status {
+			matching = append(matching, review)
+		}
+	}
+	return matching, nil
+}
+
+func (r *CassandraReviewRepository) List(status ReviewStatus) ([]Review, error) {
+	if status != "" {
+		return r.list(r.session.Query("SELECT id FROM reviews_by_status WHERE status = ?", status).Iter())
+	}
+	var reviews []Review
+	iter := r.session.Query("SELECT " + cassandraReviewColumns + " FROM reviews").Iter()
+	scanner := iter.Scanner()
+	for scanner.Next() {
+		review, err := scanReview(scanner.Scan)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sortReviews(reviews)
+	return reviews, nil
+}
+
+// list loads every review whose ID the iterator yields, skipping any deleted since the lookup row was read.
+func (r *CassandraReviewRepository) list(iter *gocql.Iter) ([]Review, error) {
+	var ids []gocql.UUID
+	var id gocql.UUID
+	for iter.Scan(&id) {
+		ids = append(ids, id)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	var reviews []Review
+	for _, id := range ids {
+		review, err := r.GetByID(id.String())
+		if errors.Is(err, ErrReviewNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+	sortReviews(reviews)
+	return reviews, nil
+}
+
+func (r *CassandraReviewRepository) SetStatus(id string, from, to ReviewStatus) (Review, error) {
+	review, err := r.GetByID(id)
+	if err != nil {
+		return Review{}, err
+	}
+	parsedUUID, _ := gocql.ParseUUID(id)
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	var current string
+	applied, err := r.session.Query(
+		"UPDATE reviews SET status = ?, updated_at = ? WHERE id = ? IF status = ?",
+		to, now, parsedUUID, from,
+	).ScanCAS(&current)
+	if err != nil {
+		return Review{}, err
+	}
+	if !applied {
+		return Review{}, ErrReviewStatusChanged
+	}
+
+	batch := r.session.NewBatch(gocql.LoggedBatch)
+	batch.Query("DELETE FROM reviews_by_status WHERE status = ? AND id = ?", from, parsedUUID)
+	batch.Query("INSERT INTO reviews_by_status (status, id) VALUES (?, ?)", to, parsedUUID)
+	if err := r.session.ExecuteBatch(batch); err != nil {
+		return Review{}, err
+	}
+	// Only the moderator whose transaction applied gets here, so each approval is counted once.
+	if from == ReviewApproved && to != ReviewApproved {
+		err = r.count(review, -1)
+	} else if to == ReviewApproved && from != ReviewApproved {
+		err = r.count(review, 1)
+	}
+	if err != nil {
+		return Review{}, err
+	}
+	review.Status = to
+	review.UpdatedAt = now
+	return review, nil
+}
+
+func (r *CassandraReviewRepository) Delete(id string) error {
+	review, err := r.GetByID(id)
+	if err != nil {
+		return err
+	}
+	parsedUUID, _ := gocql.ParseUUID(id)
+	batch := r.session.NewBatch(gocql.LoggedBatch)
+	batch.Query("DELETE FROM reviews WHERE id = ?", parsedUUID)
+	batch.Query("DELETE FROM reviews_by_album WHERE album_id = ? AND id = ?", review.AlbumID, parsedUUID)
+	batch.Query("DELETE FROM reviews_by_status WHERE status = ? AND id = ?", review.Status, parsedUUID)
+	batch.Query("DELETE FROM review_authors WHERE album_id = ? AND user_id = ?", review.AlbumID, review.UserID)
+	if err := r.session.ExecuteBatch(batch); err != nil {
+		return err
+	}
+	if review.Status == ReviewApproved {
+		return r.count(review, -1)
+	}
+	return nil
+}
+
+func (r *CassandraReviewRepository) Ratings() ([]RatingSummary, error) {
+	var ratings []RatingSummary
+	var albumID string
+	var count, total int64
+	iter := r.session.Query("SELECT album_id, review_count, rating_total FROM album_ratings").Iter()
+	for iter.Scan(&albumID, &count, &total) {
+		if count > 0 {
+			ratings = append(ratings, summarise(albumID, int(count), total))
+		}
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	return ratings, nil
+}
@@ -0,0 +1,99 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCassandraReviewRepository_Moderation tests that each user reviews an album once and that only approved
+// reviews are counted in the album's rating.
+func TestCassandraReviewRepository_Moderation(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraReviewRepository(session)
+	albumID := gocql.TimeUUID().String()
+
+	first, err := repo.Create(Review{AlbumID: albumID, UserID: "user-1", Author: "Ann", Rating: 5, Body: "A classic."})
+	require.NoError(t, err)
+	require.Equal(t, ReviewPending, first.Status)
+	_, err = repo.Create(Review{AlbumID: albumID, UserID: "user-1", Author: "Ann", Rating: 1, Body: "Changed my mind."})
+	require.True(t, errors.Is(err, ErrDuplicateReview))
+	second, err := repo.Create(Review{AlbumID: albumID, UserID: "user-2", Author: "Bob", Rating: 4, Body: "Great grooves."})
+	require.NoError(t, err)
+
+	ratings, err := repo.Ratings()
+	require.NoError(t, err)
+	require.Empty(t, ratings, "pending reviews are not counted")
+
+	_, err = repo.SetStatus(first.ID, ReviewPending, ReviewApproved)
+	require.NoError(t, err)
+	_, err = repo.SetStatus(first.ID, ReviewPending, ReviewRejected)
+	require.True(t, errors.Is(err, ErrReviewStatusChanged))
+	_, err = repo.SetStatus(second.ID, ReviewPending, ReviewApproved)
+	require.NoError(t, err)
+
+	ratings, err = repo.Ratings()
+	require.NoError(t, err)
+	require.Equal(t, []RatingSummary{{AlbumID: albumID, Count: 2, Average: 4.5}}, ratings)
+
+	reviews, err := repo.ListByAlbum(albumID, ReviewApproved)
+	require.NoError(t, err)
+	require.Len(t, reviews, 2)
+	reviews, err = repo.List(ReviewPending)
+	require.NoError(t, err)
+	require.Empty(t, reviews)
+
+	require.NoError(t, repo.Delete(first.ID))
+	require.True(t, errors.Is(repo.Delete(first.ID), ErrReviewNotFound))
+	ratings, err = repo.Ratings()
+	require.NoError(t, err)
+	require.Equal(t, []RatingSummary{{AlbumID: albumID, Count: 1, Average: 4}}, ratings)
+
+	_, err = repo.Create(Review{AlbumID: albumID, UserID: "user-1", Author: "Ann", Rating: 3, Body: "Second listen."})
+	require.NoError(t, err, "deleting a review lets its author review the album again")
+
+	_, err = repo.GetByID("not-a-uuid")
+	require.True(t, errors.Is(err, ErrReviewNotFound))
+}
+
+// TestCassandraReviewRepository_ConcurrentModeration tests that when moderators approve the same review at
+// once, the lightweight transaction on the status lets only one of them count its rating.
+func TestCassandraReviewRepository_ConcurrentModeration(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraReviewRepository(session)
+	albumID := gocql.TimeUUID().String()
+	review, err := repo.Create(Review{AlbumID: albumID, UserID: "user-1", Author: "Ann", Rating: 5, Body: "A classic."})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = repo.SetStatus(review.ID, ReviewPending, ReviewApproved)
+		}()
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+		} else {
+			require.True(t, errors.Is(err, ErrReviewStatusChanged), err)
+		}
+	}
+	require.Equal(t, 1, succeeded)
+	ratings, err := repo.Ratings()
+	require.NoError(t, err)
+	require.Equal(t, []RatingSummary{{AlbumID: albumID, Count: 1, Average: 5}}, ratings)
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// CassandraRevisionRepository keeps each album's revisions in one partition, newest first. Record
+// claims the next number with a lightweight transaction and tries the one after if another edit
+// took it first.
+type CassandraRevisionRepository struct {
+	session *gocql.Session
+}
+
+func NewCassandraRevisionRepository(session *gocql.Session) *CassandraRevisionRepository {
+	return &CassandraRevisionRepository{session: session}
+}
+
+const cassandraRevisionColumns = "number, snapshot, actor, note, rolled_back_to, created_at"
+
+func (r *CassandraRevisionRepository) Record(revision AlbumRevision) (AlbumRevision, error) {
+	albumID, err := gocql.ParseUUID(revision.AlbumID)
+	if err != nil {
+		return AlbumRevision{}, err
+	}
+	snapshot, err := revision.Album.Value()
+	if err != nil {
+		return AlbumRevision{}, err
+	}
+	latest := 0
+	if err := r.session.Query(
+		"SELECT number FROM album_revisions WHERE album_id = ? LIMIT 1", albumID,
+	).Scan(&latest); err != nil && !errors.Is(err, gocql.ErrNotFound) {
+		return AlbumRevision{}, err
+	}
+	revision.AlbumID = albumID.String()
+	revision.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	for attempt := 0; attempt < maxRevisionAttempts; attempt++ {
+		revision.Number = latest + 1
+		existing := map[string]interface{}{}
+		applied, err := r.session.Query(
+			"INSERT INTO album_revisions (album_id, "+cassandraRevisionColumns+") VALUES (?, ?, ?, ?, ?, ?, ?) IF NOT EXISTS",
+			albumID, revision.Number, snapshot, revision.Actor, revision.Note, revision.RolledBackTo, revision.CreatedAt,
+		).MapScanCAS(existing)
+		if err != nil {
+			return AlbumRevision{}, err
+		}
+		if applied {
+			return revision, nil
+		}
+		latest = revision.Number
+	}
+	return AlbumRevision{}, errors.New("could not number the revision: too many edits at once")
+}
+
+// scanRevision reads one row selected with cassandraRevisionColumns using the given scan function.
+func scanRevision(albumID string, scan func(dest ...interface{}) error) (AlbumRevision, error) {
+	revision := AlbumRevision{AlbumID: albumID}
+	var snapshot string
+	if err := scan(&revision.Number, &snapshot, &revision.Actor, &revision.Note, &revision.RolledBackTo,
+		&revision.CreatedAt); err != nil {
+		return AlbumRevision{}, err
+	}
+	if err := revision.Album.Scan(snapshot); err != nil {
+		return AlbumRevision{}, err
+	}
+	return revision, nil
+}
+
+func (r *CassandraRevisionRepository) List(albumID string) ([]AlbumRevision, error) {
+	parsedUUID, err := gocql.ParseUUID(albumID)
+	if err != nil {
+		return nil, nil
+	}
+	var revisions []AlbumRevision
+	iter := r.session.Query(
+		"SELECT "+cassandraRevisionColumns+" FROM album_revisions WHERE album_id = ?", parsedUUID,
+	).Iter()
+	scanner := iter.Scanner()
+	for scanner.Next() {
+		revision, err := scanRevision(parsedUUID.String(), scanner.Scan)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (r *CassandraRevisionRepository) Get(albumID string, number int) (AlbumRevision, error) {
+	parsedUUID, err := gocql.ParseUUID(albumID)
+	if err != nil {
+		return AlbumRevision{}, ErrRevisionNotFound
+	}
+	revision, err := scanRevision(parsedUUID.String(), r.session.Query(
+		"SELECT "+cassandraRevisionColumns+" FROM album_revisions WHERE album_id = ? AND number = ?", parsedUUID, number,
+	).Scan)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return AlbumRevision{}, ErrRevisionNotFound
+	}
+	return revision, err
+}
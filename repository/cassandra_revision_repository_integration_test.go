@@ -0,0 +1,80 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestCassandraRevisionRepository tests numbering, listing and reading back an album's revisions.
+func TestCassandraRevisionRepository(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraRevisionRepository(session)
+	album := Album{ID: gocql.TimeUUID().String(), Title: "ABC", Artist: "Jackson 5", Price: money.MustParse("9.99"), Year: 1970, Genre: "R&B/Soul", Tags: Tags{"motown"}}
+
+	first, err := repo.Record(AlbumRevision{AlbumID: album.ID, Album: SnapshotOf(album), Actor: "staff-1"})
+	require.NoError(t, err)
+	require.Equal(t, 1, first.Number)
+	require.False(t, first.CreatedAt.IsZero())
+	album.Price = money.MustParse("7.99")
+	second, err := repo.Record(AlbumRevision{AlbumID: album.ID, Album: SnapshotOf(album), Actor: "staff-2", Note: "rolled back to revision 1", RolledBackTo: 1})
+	require.NoError(t, err)
+	require.Equal(t, 2, second.Number)
+	other, err := repo.Record(AlbumRevision{AlbumID: gocql.TimeUUID().String(), Album: SnapshotOf(album), Actor: "staff-1"})
+	require.NoError(t, err)
+	require.Equal(t, 1, other.Number, "each album numbers its revisions from 1")
+
+	revisions, err := repo.List(album.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	require.Equal(t, 2, revisions[0].Number)
+	require.Equal(t, 1, revisions[0].RolledBackTo)
+
+	got, err := repo.Get(album.ID, 1)
+	require.NoError(t, err)
+	require.Equal(t, money.MustParse("9.99"), got.Album.Price)
+	require.Equal(t, Tags{"motown"}, got.Album.Tags)
+	_, err = repo.Get(album.ID, 3)
+	require.True(t, errors.Is(err, ErrRevisionNotFound))
+}
+
+// TestCassandraRevisionRepository_ConcurrentRecord tests that the lightweight transaction on revision numbers
+// gives each of several revisions recorded at once for the same album a number of its own.
+func TestCassandraRevisionRepository_ConcurrentRecord(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraRevisionRepository(session)
+	album := Album{ID: gocql.TimeUUID().String(), Title: "ABC", Artist: "Jackson 5", Price: money.MustParse("9.99"), Year: 1970, Genre: "R&B/Soul"}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	numbers := make([]int, len(errs))
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var revision AlbumRevision
+			revision, errs[i] = repo.Record(AlbumRevision{AlbumID: album.ID, Album: SnapshotOf(album), Actor: "staff-1"})
+			numbers[i] = revision.Number
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	sort.Ints(numbers)
+	require.Equal(t, []int{1, 2, 3, 4}, numbers)
+	revisions, err := repo.List(album.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 4)
+}
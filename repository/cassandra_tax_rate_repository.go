@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+type CassandraTaxRateRepository struct {
+	session *gocql.Session
+}
+
+func NewCassandraTaxRateRepository(session *gocql.Session) *CassandraTaxRateRepository {
+	return &CassandraTaxRateRepository{session: session}
+}
+
+func (r *CassandraTaxRateRepository) List() ([]TaxRate, error) {
+	iter := r.session.Query("SELECT country, region, category, name, rate, updated_at FROM tax_rates").Iter()
+	var rates []TaxRate
+	var rate TaxRate
+	for iter.Scan(&rate.Country, &rate.Region, &rate.Category, &rate.Name, &rate.Rate, &rate.UpdatedAt) {
+		rates = append(rates, rate)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	sortTaxRates(rates)
+	return rates, nil
+}
+
+// Replace writes the new table in a single logged batch, so readers see either the old rates or the new ones.
+func (r *CassandraTaxRateRepository) Replace(rates []TaxRate) ([]TaxRate, error) {
+	existing, err := r.List()
+	if err != nil {
+		return nil, err
+	}
+	kept := make(map[string]bool, len(rates))
+	for _, rate := range rates {
+		kept[rate.Key()] = true
+	}
+	batch := r.session.NewBatch(gocql.LoggedBatch)
+	for _, rate := range existing {
+		// As with exchange rates, a delete would beat an insert of the same row in the batch.
+		if !kept[rate.Key()] {
+			batch.Query("DELETE FROM tax_rates WHERE country = ? AND region = ? AND category = ?", rate.Country, rate.Region, rate.Category)
+		}
+	}
+	now := time.Now().UTC()
+	for _, rate := range rates {
+		batch.Query("INSERT INTO tax_rates (country, region, category, name, rate, updated_at) VALUES (?, ?, ?, ?, ?, ?)",
+			rate.Country, rate.Region, rate.Category, rate.Name, rate.Rate, now)
+	}
+	if err := r.session.ExecuteBatch(batch); err != nil {
+		return nil, err
+	}
+	return r.List()
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// CassandraUsedItemRepository keeps used items in used_items, so each item's status can be changed
+// with a lightweight transaction, and indexes them by album in used_items_by_album.
+type CassandraUsedItemRepository struct {
+	session *gocql.Session
+}
+
+func NewCassandraUsedItemRepository(session *gocql.Session) *CassandraUsedItemRepository {
+	return &CassandraUsedItemRepository{session: session}
+}
+
+const cassandraUsedItemColumns = "id, album_id, format, media_grade, sleeve_grade, notes, photos, price, currency, status, created_at, updated_at"
+
+func (r *CassandraUsedItemRepository) Create(item UsedItem) (UsedItem, error) {
+	id := gocql.TimeUUID()
+	item.ID = id.String()
+	item.Status = UsedItemAvailable
+	item.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	item.UpdatedAt = item.CreatedAt
+	if item.Photos == nil {
+		item.Photos = Photos{}
+	}
+	batch := r.session.NewBatch(gocql.LoggedBatch)
+	batch.Query(
+		"INSERT INTO used_items ("+cassandraUsedItemColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		id, item.AlbumID, string(item.Format), string(item.MediaGrade), string(item.SleeveGrade), item.Notes,
+		[]string(item.Photos), item.Price, item.Currency, string(item.Status), item.CreatedAt, item.UpdatedAt,
+	)
+	batch.Query("INSERT INTO used_items_by_album (album_id, id) VALUES (?, ?)", item.AlbumID, id)
+	if err := r.session.ExecuteBatch(batch); err != nil {
+		return UsedItem{}, err
+	}
+	return item, nil
+}
+
+func (r *CassandraUsedItemRepository) GetByID(id string) (UsedItem, error) {
+	parsedUUID, err := gocql.ParseUUID(id)
+	if err != nil {
+		return UsedItem{}, ErrUsedItemNotFound
+	}
+	item, err := scanUsedItem(r.session.Query(
+		"SELECT "+cassandraUsedItemColumns+" FROM used_items WHERE id = ?", parsedUUID,
+	).Scan)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return UsedItem{}, ErrUsedItemNotFound
+	}
+	return item, err
+}
+
+// scanUsedItem reads one row selected with cassandraUsedItemColumns using the given scan function.
+func scanUsedItem(scan func(dest ...interface{}) error) (UsedItem, error) {
+	var item UsedItem
+	var id gocql.UUID
+	var format, mediaGrade, sleeveGrade, status string
+	var photos []string
+	if err := scan(&id, &item.AlbumID, &format, &mediaGrade, &sleeveGrade, &item.Notes, &photos,
+		&item.Price, &item.Currency, &status, &item.CreatedAt, &item.UpdatedAt); err != nil {
+		return UsedItem{}, err
+	}
+	item.ID = id.String()
+	item.Format = Format(format)
+	item.MediaGrade, item.SleeveGrade = Grade(mediaGrade), Grade(sleeveGrade)
+	item.Photos = append(Photos{}, photos...)
+	item.Status = UsedItemStatus(status)
+	return item, nil
+}
+
+// List looks up an album's items through used_items_by_album, and scans the whole table otherwise.
+func (r *CassandraUsedItemRepository) List(filter UsedItemFilter) ([]UsedItem, error) {
+	var items []UsedItem
+	keep := func(item UsedItem) {
+		if filter.Matches(item) {
+			items = append(items, item)
+		}
+	}
+	if filter.AlbumID == "" {
+		scanner := r.session.Query("SELECT " + cassandraUsedItemColumns + " FROM used_items").Iter().Scanner()
+		for scanner.Next() {
+			item, err := scanUsedItem(scanner.Scan)
+			if err != nil {
+				return nil, err
+			}
+			keep(item)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	} else {
+		var ids []gocql.UUID
+		var id gocql.UUID
+		iter := r.session.Query("SELECT id FROM used_items_by_album WHERE album_id = ?", filter.AlbumID).Iter()
+		for iter.Scan(&id) {
+			ids = append(ids, id)
+		}
+		if err := iter.Close(); err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			item, err := r.GetByID(id.String())
+			if errors.Is(err, ErrUsedItemNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			keep(item)
+		}
+	}
+	sortUsedItems(items)
+	return items, nil
+}
+
+func (r *CassandraUsedItemRepository) Update(item UsedItem) (UsedItem, error) {
+	current, err := r.GetByID(item.ID)
+	if err != nil {
+		return UsedItem{}, err
+	}
+	if item.Photos == nil {
+		item.Photos = Photos{}
+	}
+	parsedUUID, _ := gocql.ParseUUID(item.ID)
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	if err := r.session.Query(
+		`UPDATE used_items SET format = ?, media_grade = ?, sleeve_grade = ?, notes = ?, photos = ?, price = ?,
+		 currency = ?, updated_at = ? WHERE id = ?`,
+		string(item.Format), string(item.MediaGrade), string(item.SleeveGrade), item.Notes, []string(item.Photos),
+		item.Price, item.Currency, now, parsedUUID,
+	).Exec(); err != nil {
+		return UsedItem{}, err
+	}
+	item.AlbumID = current.AlbumID
+	item.Status = current.Status
+	item.CreatedAt = current.CreatedAt
+	item.UpdatedAt = now
+	return item, nil
+}
+
+func (r *CassandraUsedItemRepository) SetStatus(id string, from, to UsedItemStatus) (UsedItem, error) {
+	item, err := r.GetByID(id)
+	if err != nil {
+		return UsedItem{}, err
+	}
+	parsedUUID, _ := gocql.ParseUUID(id)
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	var current string
+	applied, err := r.session.Query(
+		"UPDATE used_items SET status = ?, updated_at = ? WHERE id = ? IF status = ?",
+		string(to), now, parsedUUID, string(from),
+	).ScanCAS(&current)
+	if err != nil {
+		return UsedItem{}, err
+	}
+	if !applied {
+		return UsedItem{}, ErrUsedItemStatusChanged
+	}
+	item.Status = to
+	item.UpdatedAt = now
+	return item, nil
+}
+
+func (r *CassandraUsedItemRepository) Delete(id string) error {
+	item, err := r.GetByID(id)
+	if err != nil {
+		return err
+	}
+	parsedUUID, _ := gocql.ParseUUID(id)
+	batch := r.session.NewBatch(gocql.LoggedBatch)
+	batch.Query("DELETE FROM used_items WHERE id = ?", parsedUUID)
+	batch.Query("DELETE FROM used_items_by_album WHERE album_id = ? AND id = ?", item.AlbumID, parsedUUID)
+	return r.session.ExecuteBatch(batch)
+}
@@ -0,0 +1,105 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestCassandraUsedItemRepository tests filtering used items by grade and selling each copy once.
+func TestCassandraUsedItemRepository(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraUsedItemRepository(session)
+	albumID := gocql.TimeUUID().String()
+
+	good, err := repo.Create(UsedItem{AlbumID: albumID, Format: FormatLP, MediaGrade: "VG+", SleeveGrade: "VG", Notes: "Gatefold",
+		Photos: Photos{"https://example.com/front.jpg"}, Price: money.MustParse("18.00"), Currency: "GBP"})
+	require.NoError(t, err)
+	require.Equal(t, UsedItemAvailable, good.Status)
+	worn, err := repo.Create(UsedItem{AlbumID: albumID, Format: FormatLP, MediaGrade: "G", SleeveGrade: "G", Price: money.MustParse("4.00"), Currency: "GBP"})
+	require.NoError(t, err)
+	_, err = repo.Create(UsedItem{AlbumID: gocql.TimeUUID().String(), Format: FormatCD, MediaGrade: "NM", SleeveGrade: "NM", Price: money.MustParse("6.00"), Currency: "GBP"})
+	require.NoError(t, err)
+
+	found, err := repo.GetByID(good.ID)
+	require.NoError(t, err)
+	require.Equal(t, Photos{"https://example.com/front.jpg"}, found.Photos)
+	require.Equal(t, money.MustParse("18.00"), found.Price)
+
+	items, err := repo.List(UsedItemFilter{AlbumID: albumID})
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	require.Equal(t, good.ID, items[0].ID)
+	items, err = repo.List(UsedItemFilter{AlbumID: albumID, MinMediaGrade: "VG"})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	items, err = repo.List(UsedItemFilter{MinSleeveGrade: "VG"})
+	require.NoError(t, err)
+	require.Len(t, items, 2, "without an album every item is scanned")
+
+	worn.Price = money.MustParse("3.50")
+	updated, err := repo.Update(worn)
+	require.NoError(t, err)
+	require.Equal(t, money.MustParse("3.50"), updated.Price)
+	_, err = repo.Update(UsedItem{ID: gocql.TimeUUID().String()})
+	require.True(t, errors.Is(err, ErrUsedItemNotFound))
+
+	sold, err := repo.SetStatus(good.ID, UsedItemAvailable, UsedItemSold)
+	require.NoError(t, err)
+	require.Equal(t, UsedItemSold, sold.Status)
+	_, err = repo.SetStatus(good.ID, UsedItemAvailable, UsedItemSold)
+	require.True(t, errors.Is(err, ErrUsedItemStatusChanged))
+	items, err = repo.List(UsedItemFilter{AlbumID: albumID, Status: UsedItemAvailable})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	require.NoError(t, repo.Delete(worn.ID))
+	_, err = repo.GetByID(worn.ID)
+	require.True(t, errors.Is(err, ErrUsedItemNotFound))
+	items, err = repo.List(UsedItemFilter{AlbumID: albumID})
+	require.NoError(t, err)
+	require.Len(t, items, 1, "deleted items leave the album's index")
+}
+
+// TestCassandraUsedItemRepository_ConcurrentSale tests that when several buyers take the same copy at once,
+// the lightweight transaction on its status sells it to only one of them.
+func TestCassandraUsedItemRepository_ConcurrentSale(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	repo := NewCassandraUsedItemRepository(session)
+	item, err := repo.Create(UsedItem{AlbumID: gocql.TimeUUID().String(), Format: FormatLP, MediaGrade: "VG+", SleeveGrade: "VG+",
+		Price: money.MustParse("18.00"), Currency: "GBP"})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = repo.SetStatus(item.ID, UsedItemAvailable, UsedItemSold)
+		}()
+	}
+	wg.Wait()
+
+	sold := 0
+	for _, err := range errs {
+		if err == nil {
+			sold++
+		} else {
+			require.True(t, errors.Is(err, ErrUsedItemStatusChanged), err)
+		}
+	}
+	require.Equal(t, 1, sold)
+	found, err := repo.GetByID(item.ID)
+	require.NoError(t, err)
+	require.Equal(t, UsedItemSold, found.Status)
+}
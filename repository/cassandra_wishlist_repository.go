@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// CassandraWishlistRepository keeps a lookup table from share token to user alongside the wishlists.
+type CassandraWishlistRepository struct {
+	session *gocql.Session
+}
+
+func NewCassandraWishlistRepository(session *gocql.Session) *CassandraWishlistRepository {
+	return &CassandraWishlistRepository{session: session}
+}
+
+func (r *CassandraWishlistRepository) Get(userID string) (Wishlist, error) {
+	wishlist := Wishlist{UserID: userID, Items: []WishlistItem{}}
+	err := r.session.Query("SELECT share_token, updated_at FROM wishlists WHERE user_id = ?", userID).
+		Scan(&wishlist.ShareToken, &wishlist.UpdatedAt)
+	if err != nil && !errors.Is(err, gocql.ErrNotFound) {
+		return Wishlist{}, err
+	}
+
+	iter := r.session.Query("SELECT album_id, note, added_at FROM wishlist_items WHERE user_id = ?", userID).Iter()
+	var item WishlistItem
+	for iter.Scan(&item.AlbumID, &item.Note, &item.AddedAt) {
+		wishlist.Items = append(wishlist.Items, item)
+	}
+	if err := iter.Close(); err != nil {
+		return Wishlist{}, err
+	}
+	sortWishlistItems(wishlist.Items)
+	return wishlist, nil
+}
+
+func (r *CassandraWishlistRepository) GetByShareToken(token string) (Wishlist, error) {
+	var userID string
+	err := r.session.Query("SELECT user_id FROM wishlists_by_share_token WHERE share_token = ?", token).Scan(&userID)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return Wishlist{}, ErrWishlistNotFound
+	}
+	if err != nil {
+		return Wishlist{}, err
+	}
+	wishlist, err := r.Get(userID)
+	if err != nil {
+		return Wishlist{}, err
+	}
+	// The lookup row can outlive a token that has since been replaced; the wishlist has the final say.
+	if wishlist.ShareToken != token {
+		return Wishlist{}, ErrWishlistNotFound
+	}
+	return wishlist, nil
+}
+
+func (r *CassandraWishlistRepository) AddItem(userID string, item WishlistItem) error {
+	now := time.Now().UTC()
+	var existingUserID string
+	var existing WishlistItem
+	applied, err := r.session.Query(
+		"INSERT INTO wishlist_items (user_id, album_id, note, added_at) VALUES (?, ?, ?, ?) IF NOT EXISTS",
+		userID, item.AlbumID, item.Note, now,
+	).ScanCAS(&existingUserID, &existing.AlbumID, &existing.AddedAt, &existing.Note)
+	if err != nil {
+		return err
+	}
+	if !applied {
+		// Saving an album again keeps the date it was first added.
+		if err := r.session.Query("UPDATE wishlist_items SET note = ? WHERE user_id = ? AND album_id = ?",
+			item.Note, userID, item.AlbumID).Exec(); err != nil {
+			return err
+		}
+	}
+	return r.session.Query("UPDATE wishlists SET updated_at = ? WHERE user_id = ?", now, userID).Exec()
+}
+
+func (r *CassandraWishlistRepository) RemoveItem(userID, albumID string) error {
+	var note string
+	err := r.session.Query("SELECT note FROM wishlist_items WHERE user_id = ? AND album_id = ?", userID, albumID).Scan(&note)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return ErrWishlistItemNotFound
+	}
+	if err != nil {
+		return err
+	}
+	batch := r.session.NewBatch(gocql.LoggedBatch)
+	batch.Query("DELETE FROM wishlist_items WHERE user_id = ? AND album_id = ?", userID, albumID)
+	batch.Query("UPDATE wishlists SET updated_at = ? WHERE user_id = ?", time.Now().UTC(), userID)
+	return r.session.ExecuteBatch(batch)
+}
+
+func (r *CassandraWishlistRepository) SetShareToken(userID, token string) error {
+	var current string
+	err := r.session.Query("SELECT share_token FROM wishlists WHERE user_id = ?", userID).Scan(&current)
+	if err != nil && !errors.Is(err, gocql.ErrNotFound) {
+		return err
+	}
+	batch := r.session.NewBatch(gocql.LoggedBatch)
+	if current != "" && current != token {
+		batch.Query("DELETE FROM wishlists_by_share_token WHERE share_token = ?", current)
+	}
+	if token != "" {
+		batch.Query("INSERT INTO wishlists_by_share_token (share_token, user_id) VALUES (?, ?)", token, userID)
+	}
+	batch.Query("UPDATE wishlists SET share_token = ?, updated_at = ? WHERE user_id = ?", token, time.Now().UTC(), userID)
+	return r.session.ExecuteBatch(batch)
+}
+
+func (r *CassandraWishlistRepository) AlbumsByUser() (map[string][]string, error) {
+	albums := make(map[string][]string)
+	var userID, albumID string
+	iter := r.session.Query("SELECT user_id, album_id FROM wishlist_items").Iter()
+	for iter.Scan(&userID, &albumID) {
+		albums[userID] = append(albums[userID], albumID)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	return albums, nil
+}
@@ -0,0 +1,59 @@
+//go:build integration
+// +build integration
+
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCassandraWishlistRepository tests saving, re-saving and removing albums, and sharing a wishlist by token.
+func TestCassandraWishlistRepository(t *testing.T) {
+	session, teardown := setupTestCassandra(t)
+	defer teardown()
+	albumID := gocql.TimeUUID().String()
+	repo := NewCassandraWishlistRepository(session)
+
+	wishlist, err := repo.Get("user-1")
+	require.NoError(t, err)
+	require.Empty(t, wishlist.Items)
+
+	require.NoError(t, repo.AddItem("user-1", WishlistItem{AlbumID: albumID, Note: "LP"}))
+	first, err := repo.Get("user-1")
+	require.NoError(t, err)
+	require.NoError(t, repo.AddItem("user-1", WishlistItem{AlbumID: albumID, Note: "LP or CD"}))
+	wishlist, err = repo.Get("user-1")
+	require.NoError(t, err)
+	require.Len(t, wishlist.Items, 1)
+	require.Equal(t, albumID, wishlist.Items[0].AlbumID)
+	require.Equal(t, "LP or CD", wishlist.Items[0].Note)
+	require.Equal(t, first.Items[0].AddedAt, wishlist.Items[0].AddedAt, "saving an album again keeps the date it was first added")
+
+	require.NoError(t, repo.SetShareToken("user-1", "token-1"))
+	shared, err := repo.GetByShareToken("token-1")
+	require.NoError(t, err)
+	require.Equal(t, "user-1", shared.UserID)
+	require.Len(t, shared.Items, 1)
+
+	require.NoError(t, repo.SetShareToken("user-1", "token-2"))
+	_, err = repo.GetByShareToken("token-1")
+	require.True(t, errors.Is(err, ErrWishlistNotFound), "a replaced token no longer works")
+	_, err = repo.GetByShareToken("token-2")
+	require.NoError(t, err)
+
+	require.NoError(t, repo.SetShareToken("user-1", ""))
+	_, err = repo.GetByShareToken("token-2")
+	require.True(t, errors.Is(err, ErrWishlistNotFound))
+
+	albums, err := repo.AlbumsByUser()
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{"user-1": {albumID}}, albums)
+
+	require.NoError(t, repo.RemoveItem("user-1", albumID))
+	err = repo.RemoveItem("user-1", albumID)
+	require.True(t, errors.Is(err, ErrWishlistItemNotFound))
+}
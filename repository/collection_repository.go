@@ -0,0 +1,339 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+var (
+	// ErrCollectionNotFound is returned when a collection does not exist.
+	ErrCollectionNotFound = errors.New("collection not found")
+	// ErrDuplicateCollection is returned when another collection already has a collection's ID.
+	ErrDuplicateCollection = errors.New("another collection already has that title")
+	// ErrSmartCollection is returned when albums are added to or removed from a smart collection by hand.
+	ErrSmartCollection = errors.New("smart collections are chosen by their rules")
+)
+
+// CollectionKind says how a collection's albums are chosen.
+type CollectionKind string
+
+const (
+	// CollectionCurated collections list the albums staff put in them, in the order they chose.
+	CollectionCurated CollectionKind = "curated"
+	// CollectionSmart collections list every album matching their rules.
+	CollectionSmart CollectionKind = "smart"
+)
+
+// Collection is a list of albums shown together, such as "Staff Picks" or "Under £10".
+type Collection struct {
+	ID          string          `db:"id" json:"id"`
+	Title       string          `db:"title" json:"title"`
+	Description string          `db:"description" json:"description,omitempty"`
+	CoverURL    string          `db:"cover_url" json:"coverUrl,omitempty"`
+	Kind        CollectionKind  `db:"kind" json:"kind"`
+	AlbumIDs    AlbumIDs        `db:"album_ids" json:"albumIds,omitempty"`
+	Rules       CollectionRules `db:"rules" json:"rules,omitempty"`
+	CreatedAt   time.Time       `db:"created_at" json:"createdAt"`
+	UpdatedAt   time.Time       `db:"updated_at" json:"updatedAt"`
+}
+
+// AlbumIDs are the albums in a curated collection, in order, stored as a JSON array.
+type AlbumIDs []string
+
+// Value stores the album IDs as a JSON array.
+func (a AlbumIDs) Value() (driver.Value, error) {
+	return Aliases(a).Value()
+}
+
+// Scan reads album IDs stored as a JSON array.
+func (a *AlbumIDs) Scan(src interface{}) error {
+	return (*Aliases)(a).Scan(src)
+}
+
+// Normalize tidies the collection's title and description, giving it an ID made from its title the
+// way genre IDs are if it has none, dropping repeated albums and setting its kind from whether it
+// has rules.
+func (c *Collection) Normalize() {
+	c.Title = strings.Join(strings.Fields(c.Title), " ")
+	c.Description = strings.TrimSpace(c.Description)
+	c.CoverURL = strings.TrimSpace(c.CoverURL)
+	if c.ID == "" {
+		c.ID = GenreSlug(c.Title)
+	}
+	seen := map[string]bool{}
+	ids := AlbumIDs{}
+	for _, id := range c.AlbumIDs {
+		if id = strings.TrimSpace(id); id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	c.AlbumIDs = ids
+	c.Kind = CollectionCurated
+	if len(c.Rules) > 0 {
+		c.Kind = CollectionSmart
+	}
+}
+
+// Validate checks the collection has a title made of more than punctuation, a web address for its
+// cover if it has one, and either albums or rules but not both.
+func (c Collection) Validate() error {
+	if GenreKey(c.Title) == "" {
+		return errors.New("title is required")
+	}
+	if c.CoverURL != "" {
+		u, err := url.Parse(c.CoverURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid coverUrl %q: must be an http or https URL", c.CoverURL)
+		}
+	}
+	if len(c.Rules) > 0 && len(c.AlbumIDs) > 0 {
+		return errors.New("a collection has either albumIds or rules, not both")
+	}
+	return nil
+}
+
+// Select returns the collection's albums from the catalogue: for a curated collection those it
+// lists, in its order, leaving out any no longer in the catalogue; for a smart collection those
+// matching its rules, oldest first. Genre rules include sub-genres when the taxonomy is given.
+func (c Collection) Select(catalogue []Album, genres *GenreTree) []Album {
+	selected := []Album{}
+	if c.Kind == CollectionSmart {
+		for _, album := range catalogue {
+			if c.Rules.Matches(album, genres) {
+				selected = append(selected, album)
+			}
+		}
+		sort.SliceStable(selected, func(i, j int) bool {
+			if selected[i].Year != selected[j].Year {
+				return selected[i].Year < selected[j].Year
+			}
+			return strings.ToLower(selected[i].Title) < strings.ToLower(selected[j].Title)
+		})
+		return selected
+	}
+	byID := make(map[string]Album, len(catalogue))
+	for _, album := range catalogue {
+		byID[album.ID] = album
+	}
+	for _, id := range c.AlbumIDs {
+		if album, ok := byID[id]; ok {
+			selected = append(selected, album)
+		}
+	}
+	return selected
+}
+
+// sortCollections orders collections by title, ignoring case.
+func sortCollections(collections []Collection) {
+	sort.SliceStable(collections, func(i, j int) bool {
+		return strings.ToLower(collections[i].Title) < strings.ToLower(collections[j].Title)
+	})
+}
+
+// CollectionRule is one condition of a smart collection, such as year < 1975.
+type CollectionRule struct {
+	Field string
+	Op    string
+	Value string
+}
+
+// collectionRuleOps lists the comparisons a rule can make, longest first so "<=" is not read as "<".
+var collectionRuleOps = []string{"<=", ">=", "!=", "=", "<", ">"}
+
+// textRuleFields are compared by name with = and !=; the others are numbers and can also be
+// compared with <, <=, > and >=.
+var textRuleFields = map[string]bool{"genre": true, "artist": true, "label": true, "tag": true}
+
+var ruleSeparator = regexp.MustCompile(`\s+AND\s+`)
+
+// ParseCollectionRules reads rules written as conditions joined by an upper-case AND, such as
+// "genre=Soul AND year<1975". Fields are genre, artist, label, tag, year and price; prices may be
+// written with a currency symbol, as in "price<£10", and are compared with the album's own price.
+func ParseCollectionRules(s string) (CollectionRules, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var rules CollectionRules
+	for _, clause := range ruleSeparator.Split(strings.TrimSpace(s), -1) {
+		rule, err := parseCollectionRule(clause)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func parseCollectionRule(clause string) (CollectionRule, error) {
+	at, op := -1, ""
+	for _, candidate := range collectionRuleOps {
+		if i := strings.Index(clause, candidate); i >= 0 && (at < 0 || i < at) {
+			at, op = i, candidate
+		}
+	}
+	if at < 0 {
+		return CollectionRule{}, fmt.Errorf("invalid rule %q: must compare a field with =, !=, <, <=, > or >=", clause)
+	}
+	rule := CollectionRule{
+		Field: strings.ToLower(strings.TrimSpace(clause[:at])),
+		Op:    op,
+		Value: strings.Trim(strings.TrimSpace(clause[at+len(op):]), `"'`),
+	}
+	if rule.Value == "" {
+		return CollectionRule{}, fmt.Errorf("invalid rule %q: a value is required", clause)
+	}
+	switch {
+	case textRuleFields[rule.Field]:
+		if op != "=" && op != "!=" {
+			return CollectionRule{}, fmt.Errorf("invalid rule %q: %s can only be compared with = or !=", clause, rule.Field)
+		}
+	case rule.Field == "year":
+		if _, err := strconv.Atoi(rule.Value); err != nil {
+			return CollectionRule{}, fmt.Errorf("invalid rule %q: year must be a whole number", clause)
+		}
+	case rule.Field == "price":
+		rule.Value = strings.TrimSpace(strings.TrimLeft(rule.Value, "£$€"))
+		if _, err := money.Parse(rule.Value); err != nil {
+			return CollectionRule{}, fmt.Errorf("invalid rule %q: %w", clause, err)
+		}
+	default:
+		return CollectionRule{}, fmt.Errorf("invalid rule %q: unknown field %q", clause, rule.Field)
+	}
+	return rule, nil
+}
+
+// String writes the rule the way ParseCollectionRules reads it.
+func (r CollectionRule) String() string {
+	return r.Field + " " + r.Op + " " + r.Value
+}
+
+// Matches reports whether the album meets the rule.
+func (r CollectionRule) Matches(album Album, genres *GenreTree) bool {
+	switch r.Field {
+	case "year":
+		want, _ := strconv.Atoi(r.Value)
+		return compare(r.Op, album.Year-want)
+	case "price":
+		want, _ := money.Parse(r.Value)
+		return compare(r.Op, int(album.Price-want))
+	}
+	var same bool
+	switch r.Field {
+	case "genre":
+		same = inGenre(genres, r.Value, album.Genre)
+	case "artist":
+		same = ArtistNameKey(album.Artist) == ArtistNameKey(r.Value)
+	case "label":
+		same = album.Label != "" && LabelNameKey(album.Label) == LabelNameKey(r.Value)
+	case "tag":
+		tag := NormalizeTag(r.Value)
+		for _, t := range album.Tags {
+			same = same || t == tag
+		}
+	}
+	return same == (r.Op == "=")
+}
+
+// compare reports whether a difference between two numbers satisfies the comparison.
+func compare(op string, diff int) bool {
+	switch op {
+	case "=":
+		return diff == 0
+	case "!=":
+		return diff != 0
+	case "<":
+		return diff < 0
+	case "<=":
+		return diff <= 0
+	case ">":
+		return diff > 0
+	case ">=":
+		return diff >= 0
+	}
+	return false
+}
+
+// CollectionRules are the conditions a smart collection's albums meet, all of them. They are written
+// and stored in the form ParseCollectionRules reads.
+type CollectionRules []CollectionRule
+
+// Matches reports whether the album meets every rule.
+func (rules CollectionRules) Matches(album Album, genres *GenreTree) bool {
+	for _, rule := range rules {
+		if !rule.Matches(album, genres) {
+			return false
+		}
+	}
+	return true
+}
+
+func (rules CollectionRules) String() string {
+	clauses := make([]string, len(rules))
+	for i, rule := range rules {
+		clauses[i] = rule.String()
+	}
+	return strings.Join(clauses, " AND ")
+}
+
+func (rules CollectionRules) MarshalJSON() ([]byte, error) {
+	return json.Marshal(rules.String())
+}
+
+func (rules *CollectionRules) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return errors.New("rules must be a string such as \"genre=Soul AND year<1975\"")
+	}
+	parsed, err := ParseCollectionRules(s)
+	if err != nil {
+		return err
+	}
+	*rules = parsed
+	return nil
+}
+
+// Value stores the rules as text.
+func (rules CollectionRules) Value() (driver.Value, error) {
+	return rules.String(), nil
+}
+
+// Scan reads rules stored as text.
+func (rules *CollectionRules) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	case nil:
+	default:
+		return fmt.Errorf("cannot scan %T into CollectionRules", src)
+	}
+	parsed, err := ParseCollectionRules(s)
+	if err != nil {
+		return err
+	}
+	*rules = parsed
+	return nil
+}
+
+type CollectionRepository interface {
+	// Create adds a collection, failing with ErrDuplicateCollection if its ID is taken.
+	Create(collection Collection) (Collection, error)
+	GetByID(id string) (Collection, error)
+	// List returns every collection ordered by title.
+	List() ([]Collection, error)
+	Update(collection Collection) (Collection, error)
+	Delete(id string) error
+}
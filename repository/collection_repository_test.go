@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestParseCollectionRules tests reading smart collection rules and writing them back
+func TestParseCollectionRules(t *testing.T) {
+	rules, err := ParseCollectionRules("genre=Soul AND year<1975 AND price <= £10")
+	require.NoError(t, err)
+	assert.Equal(t, CollectionRules{
+		{Field: "genre", Op: "=", Value: "Soul"},
+		{Field: "year", Op: "<", Value: "1975"},
+		{Field: "price", Op: "<=", Value: "10"},
+	}, rules)
+	assert.Equal(t, "genre = Soul AND year < 1975 AND price <= 10", rules.String())
+
+	rules, err = ParseCollectionRules(`artist != "Sly and the Family Stone"`)
+	require.NoError(t, err)
+	assert.Equal(t, CollectionRules{{Field: "artist", Op: "!=", Value: "Sly and the Family Stone"}}, rules)
+
+	rules, err = ParseCollectionRules("  ")
+	assert.NoError(t, err)
+	assert.Empty(t, rules)
+
+	for _, invalid := range []string{"genre", "genre<Soul", "year=nineteen", "price<cheap", "colour=red", "tag="} {
+		_, err := ParseCollectionRules(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+// TestCollectionRules_JSON tests that rules are written in JSON as a string
+func TestCollectionRules_JSON(t *testing.T) {
+	var collection Collection
+	require.NoError(t, json.Unmarshal([]byte(`{"title":"Under £10","rules":"price<10"}`), &collection))
+	assert.Equal(t, CollectionRules{{Field: "price", Op: "<", Value: "10"}}, collection.Rules)
+
+	b, err := json.Marshal(collection.Rules)
+	require.NoError(t, err)
+	assert.JSONEq(t, `"price < 10"`, string(b))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"rules":["price<10"]}`), &collection))
+	assert.Error(t, json.Unmarshal([]byte(`{"rules":"price<<10"}`), &collection))
+}
+
+// TestCollection_NormalizeAndValidate tests giving collections an ID and kind, and that they have either albums or rules
+func TestCollection_NormalizeAndValidate(t *testing.T) {
+	collection := Collection{Title: "  Northern  Soul Essentials ", AlbumIDs: AlbumIDs{"2", " 1", "2"}}
+	collection.Normalize()
+	assert.Equal(t, "northern-soul-essentials", collection.ID)
+	assert.Equal(t, "Northern Soul Essentials", collection.Title)
+	assert.Equal(t, AlbumIDs{"2", "1"}, collection.AlbumIDs)
+	assert.Equal(t, CollectionCurated, collection.Kind)
+	assert.NoError(t, collection.Validate())
+
+	collection.Rules = CollectionRules{{Field: "year", Op: "<", Value: "1975"}}
+	collection.Normalize()
+	assert.Equal(t, CollectionSmart, collection.Kind)
+	assert.Error(t, collection.Validate(), "a collection cannot have both albums and rules")
+
+	assert.Error(t, Collection{Title: "!!"}.Validate())
+	assert.Error(t, Collection{Title: "Staff Picks", CoverURL: "javascript:alert(1)"}.Validate())
+	assert.NoError(t, Collection{Title: "Staff Picks", CoverURL: "https://example.com/picks.jpg"}.Validate())
+}
+
+// TestCollection_Select tests picking a collection's albums from the catalogue
+func TestCollection_Select(t *testing.T) {
+	catalogue := []Album{
+		{ID: "1", Title: "Thriller", Artist: "Michael Jackson", Genre: "Pop", Year: 1982, Price: money.MustParse("9.99"), Tags: Tags{"staff pick"}},
+		{ID: "2", Title: "What's Going On", Artist: "Marvin Gaye", Genre: "Motown", Label: "Tamla", Year: 1971, Price: money.MustParse("12.00")},
+		{ID: "3", Title: "Superfly", Artist: "Curtis Mayfield", Genre: "Soul", Year: 1972, Price: money.MustParse("8.50"), Tags: Tags{"staff pick"}},
+		{ID: "4", Title: "Songs in the Key of Life", Artist: "Stevie Wonder", Genre: "Motown", Label: "Tamla", Year: 1976, Price: money.MustParse("15.00")},
+	}
+	tree := NewGenreTree([]Genre{{ID: "soul", Name: "Soul"}, {ID: "motown", Name: "Motown", ParentID: "soul"}})
+	titles := func(albums []Album) []string {
+		var titles []string
+		for _, album := range albums {
+			titles = append(titles, album.Title)
+		}
+		return titles
+	}
+	smart := func(rules string) Collection {
+		parsed, err := ParseCollectionRules(rules)
+		require.NoError(t, err)
+		collection := Collection{Title: rules, Rules: parsed}
+		collection.Normalize()
+		return collection
+	}
+
+	curated := Collection{Title: "Staff Picks", AlbumIDs: AlbumIDs{"3", "missing", "1"}}
+	curated.Normalize()
+	assert.Equal(t, []string{"Superfly", "Thriller"}, titles(curated.Select(catalogue, tree)), "curated albums keep their order")
+
+	assert.Equal(t, []string{"What's Going On", "Superfly"}, titles(smart("genre=Soul AND year<1975").Select(catalogue, tree)), "genres include their sub-genres")
+	assert.Equal(t, []string{"Superfly"}, titles(smart("genre=Soul AND year<1975").Select(catalogue, nil)), "without a taxonomy genres match by name")
+	assert.Equal(t, []string{"Superfly", "Thriller"}, titles(smart("price<£10").Select(catalogue, nil)))
+	assert.Equal(t, []string{"Superfly", "Thriller"}, titles(smart("tag=Staff Pick").Select(catalogue, nil)))
+	assert.Equal(t, []string{"What's Going On", "Songs in the Key of Life"}, titles(smart("label=Tamla Records").Select(catalogue, nil)))
+	assert.Equal(t, []string{"Superfly", "Songs in the Key of Life", "Thriller"}, titles(smart("artist!=Marvin Gaye").Select(catalogue, nil)))
+	assert.Empty(t, smart("year>=1990").Select(catalogue, nil))
+}
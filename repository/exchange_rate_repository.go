@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// ExchangeRate is how many units of Currency one unit of money.DefaultCurrency buys. Prices
+// converted into the currency are rounded to RoundTo, or to money.DefaultRounding when it is zero.
+type ExchangeRate struct {
+	Currency  string       `db:"currency" json:"currency"`
+	Rate      float64      `db:"rate" json:"rate"`
+	RoundTo   money.Amount `db:"round_to" json:"roundTo,omitempty"`
+	UpdatedAt time.Time    `db:"updated_at" json:"updatedAt"`
+}
+
+// Validate checks the rate can be used for conversion.
+func (r ExchangeRate) Validate() error {
+	if r.Currency == money.DefaultCurrency {
+		return fmt.Errorf("%s is the base currency and always has a rate of 1", r.Currency)
+	}
+	if r.Rate <= 0 {
+		return fmt.Errorf("rate for %s must be positive", r.Currency)
+	}
+	if r.RoundTo < 0 {
+		return fmt.Errorf("roundTo for %s cannot be negative", r.Currency)
+	}
+	return nil
+}
+
+// Rounding returns the step prices converted into the currency are rounded to.
+func (r ExchangeRate) Rounding() money.Amount {
+	if r.RoundTo > 0 {
+		return r.RoundTo
+	}
+	return money.DefaultRounding(r.Currency)
+}
+
+// sortExchangeRates orders rates by currency code, the order List returns them in.
+func sortExchangeRates(rates []ExchangeRate) {
+	sort.Slice(rates, func(i, j int) bool {
+		return rates[i].Currency < rates[j].Currency
+	})
+}
+
+// ExchangeRateRepository stores the exchange-rate table staff upload. Replace swaps the whole table
+// for the rates given, so currencies left out of an upload stop being offered.
+type ExchangeRateRepository interface {
+	List() ([]ExchangeRate, error)
+	Replace(rates []ExchangeRate) ([]ExchangeRate, error)
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestExchangeRate_Validate tests that unusable rates are rejected
+func TestExchangeRate_Validate(t *testing.T) {
+	assert.NoError(t, ExchangeRate{Currency: "EUR", Rate: 1.17}.Validate())
+	assert.Error(t, ExchangeRate{Currency: money.DefaultCurrency, Rate: 1}.Validate())
+	assert.Error(t, ExchangeRate{Currency: "EUR", Rate: 0}.Validate())
+	assert.Error(t, ExchangeRate{Currency: "EUR", Rate: 1.17, RoundTo: money.MustParse("-0.05")}.Validate())
+}
+
+// TestExchangeRate_Rounding tests that an explicit step overrides the currency's default
+func TestExchangeRate_Rounding(t *testing.T) {
+	assert.Equal(t, money.MustParse("0.05"), ExchangeRate{Currency: "CHF"}.Rounding())
+	assert.Equal(t, money.MustParse("0.01"), ExchangeRate{Currency: "EUR"}.Rounding())
+	assert.Equal(t, money.MustParse("0.10"), ExchangeRate{Currency: "EUR", RoundTo: money.MustParse("0.10")}.Rounding())
+}
+
+// TestSortExchangeRates tests that rates are ordered by currency code
+func TestSortExchangeRates(t *testing.T) {
+	rates := []ExchangeRate{{Currency: "USD"}, {Currency: "EUR"}, {Currency: "JPY"}}
+
+	sortExchangeRates(rates)
+
+	assert.Equal(t, "EUR", rates[0].Currency)
+	assert.Equal(t, "JPY", rates[1].Currency)
+	assert.Equal(t, "USD", rates[2].Currency)
+}
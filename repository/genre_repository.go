@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	// ErrGenreNotFound is returned when a genre does not exist.
+	ErrGenreNotFound = errors.New("genre not found")
+	// ErrDuplicateGenre is returned when a genre's name or one of its aliases is already used by another genre.
+	ErrDuplicateGenre = errors.New("another genre already goes by that name")
+	// ErrGenreInUse is returned when deleting a genre that still has sub-genres or albums.
+	ErrGenreInUse = errors.New("genre still has sub-genres or albums")
+	// ErrGenreCycle is returned when a genre would become its own ancestor.
+	ErrGenreCycle = errors.New("a genre cannot be filed under itself or one of its sub-genres")
+)
+
+// Genre is one entry in the genre taxonomy. Genres without a parent are at the top of the tree.
+// Albums name their genre rather than referring to it by ID, so the catalogue reads the same with or
+// without the taxonomy.
+type Genre struct {
+	ID       string `db:"id" json:"id"`
+	Name     string `db:"name" json:"name"`
+	ParentID string `db:"parent_id" json:"parentId,omitempty"`
+
+	// Aliases are other names for the genre, such as the primaryGenreName iTunes uses for it.
+	// Albums given an alias as their genre are filed under the genre's name.
+	Aliases Aliases `db:"aliases" json:"aliases"`
+
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// GenreKey is the form genre names are matched in, so that "R&B/Soul", "r & b / soul" and
+// "R and B Soul" are the same genre.
+func GenreKey(name string) string {
+	folded := strings.ReplaceAll(strings.ToLower(name), "&", " and ")
+	folded = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, folded)
+	return strings.Join(strings.Fields(folded), " ")
+}
+
+// GenreSlug is the ID a new genre is given: its name key with dashes for spaces, such as "northern-soul".
+func GenreSlug(name string) string {
+	return strings.ReplaceAll(GenreKey(name), " ", "-")
+}
+
+// Normalize tidies the genre's name and aliases, giving it an ID from its name if it has none and
+// dropping aliases that are blank or match the name or another alias.
+func (g *Genre) Normalize() {
+	g.Name = strings.Join(strings.Fields(g.Name), " ")
+	g.ParentID = strings.TrimSpace(g.ParentID)
+	if g.ID == "" {
+		g.ID = GenreSlug(g.Name)
+	}
+	seen := map[string]bool{GenreKey(g.Name): true}
+	aliases := Aliases{}
+	for _, alias := range g.Aliases {
+		alias = strings.Join(strings.Fields(alias), " ")
+		key := GenreKey(alias)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		aliases = append(aliases, alias)
+	}
+	g.Aliases = aliases
+}
+
+// Validate checks the genre has a name made of more than punctuation.
+func (g Genre) Validate() error {
+	if GenreKey(g.Name) == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+// nameKeys returns the keys of the genre's name and aliases, which no other genre may use.
+func (g Genre) nameKeys() []string {
+	keys := []string{GenreKey(g.Name)}
+	for _, alias := range g.Aliases {
+		keys = append(keys, GenreKey(alias))
+	}
+	return keys
+}
+
+// sortGenres orders genres by name, ignoring case.
+func sortGenres(genres []Genre) {
+	sort.SliceStable(genres, func(i, j int) bool {
+		return strings.ToLower(genres[i].Name) < strings.ToLower(genres[j].Name)
+	})
+}
+
+type GenreRepository interface {
+	// Create adds a genre, failing with ErrDuplicateGenre if its ID, name or an alias is taken.
+	Create(genre Genre) (Genre, error)
+	GetByID(id string) (Genre, error)
+	// List returns every genre ordered by name.
+	List() ([]Genre, error)
+	Update(genre Genre) (Genre, error)
+	Delete(id string) error
+}
+
+// GenreTree is the whole taxonomy loaded at once, for looking genres up by name and walking the tree.
+type GenreTree struct {
+	byID     map[string]Genre
+	byKey    map[string]string
+	children map[string][]Genre
+}
+
+// NewGenreTree indexes the genres by ID, by the keys of their names and aliases, and by parent.
+func NewGenreTree(genres []Genre) *GenreTree {
+	tree := &GenreTree{byID: map[string]Genre{}, byKey: map[string]string{}, children: map[string][]Genre{}}
+	for _, genre := range genres {
+		tree.byID[genre.ID] = genre
+		for _, key := range genre.nameKeys() {
+			tree.byKey[key] = genre.ID
+		}
+		tree.children[genre.ParentID] = append(tree.children[genre.ParentID], genre)
+	}
+	for _, children := range tree.children {
+		sortGenres(children)
+	}
+	return tree
+}
+
+// Get returns the genre with the given ID.
+func (t *GenreTree) Get(id string) (Genre, bool) {
+	genre, ok := t.byID[id]
+	return genre, ok
+}
+
+// Lookup returns the genre that goes by name, either as its name or an alias.
+func (t *GenreTree) Lookup(name string) (Genre, bool) {
+	id, ok := t.byKey[GenreKey(name)]
+	if !ok {
+		return Genre{}, false
+	}
+	return t.byID[id], true
+}
+
+// Roots returns the genres at the top of the tree, by name.
+func (t *GenreTree) Roots() []Genre {
+	return t.Children("")
+}
+
+// Children returns the genres filed directly under id, by name.
+func (t *GenreTree) Children(id string) []Genre {
+	return append([]Genre{}, t.children[id]...)
+}
+
+// Path returns the genre's ancestors and the genre itself, starting from the top of the tree.
+func (t *GenreTree) Path(id string) []Genre {
+	var path []Genre
+	for genre, ok := t.byID[id]; ok && len(path) <= len(t.byID); genre, ok = t.byID[genre.ParentID] {
+		path = append([]Genre{genre}, path...)
+	}
+	return path
+}
+
+// Includes reports whether an album whose genre is named genreName belongs under the genre id,
+// either directly or in one of its sub-genres.
+func (t *GenreTree) Includes(id, genreName string) bool {
+	genre, ok := t.Lookup(genreName)
+	if !ok {
+		return false
+	}
+	for _, ancestor := range t.Path(genre.ID) {
+		if ancestor.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// inGenre reports whether an album whose genre is named albumGenre falls under genre, given by ID
+// or name, including its sub-genres. Without a tree, or for a genre not in it, the names must match.
+func inGenre(genres *GenreTree, genre, albumGenre string) bool {
+	if genres != nil {
+		found, ok := genres.Get(genre)
+		if !ok {
+			found, ok = genres.Lookup(genre)
+		}
+		if ok {
+			return genres.Includes(found.ID, albumGenre)
+		}
+	}
+	return GenreKey(albumGenre) == GenreKey(genre)
+}
+
+// CheckParent checks that the genre id can be filed under parentID: the parent must exist and must
+// not be the genre itself or one of its sub-genres.
+func (t *GenreTree) CheckParent(id, parentID string) error {
+	if parentID == "" {
+		return nil
+	}
+	if _, ok := t.byID[parentID]; !ok {
+		return fmt.Errorf("parent genre %q does not exist", parentID)
+	}
+	for _, ancestor := range t.Path(parentID) {
+		if ancestor.ID == id {
+			return ErrGenreCycle
+		}
+	}
+	return nil
+}
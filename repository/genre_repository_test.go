@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testGenreTree() *GenreTree {
+	return NewGenreTree([]Genre{
+		{ID: "soul", Name: "Soul", Aliases: Aliases{"R&B/Soul"}},
+		{ID: "motown", Name: "Motown", ParentID: "soul"},
+		{ID: "northern-soul", Name: "Northern Soul", ParentID: "motown"},
+		{ID: "funk", Name: "Funk", ParentID: "soul"},
+		{ID: "pop", Name: "Pop"},
+	})
+}
+
+// TestGenreKey tests that names differing only in case, spacing, punctuation or "&" match
+func TestGenreKey(t *testing.T) {
+	assert.Equal(t, "r and b soul", GenreKey("R&B/Soul"))
+	assert.Equal(t, GenreKey("R&B/Soul"), GenreKey(" r & b / SOUL "))
+	assert.Equal(t, "hip hop", GenreKey("Hip-Hop"))
+	assert.Equal(t, "", GenreKey("//"))
+	assert.Equal(t, "northern-soul", GenreSlug("Northern  Soul"))
+	assert.Equal(t, "r-and-b", GenreSlug("R&B"))
+}
+
+// TestGenre_Normalize tests giving genres an ID from their name and dropping repeated aliases
+func TestGenre_Normalize(t *testing.T) {
+	genre := Genre{Name: " Hip-Hop ", Aliases: Aliases{"Hip-Hop/Rap", "hip hop", "", "Rap"}}
+	genre.Normalize()
+
+	assert.Equal(t, "hip-hop", genre.ID)
+	assert.Equal(t, "Hip-Hop", genre.Name)
+	assert.Equal(t, Aliases{"Hip-Hop/Rap", "Rap"}, genre.Aliases)
+	assert.Error(t, Genre{Name: "/"}.Validate())
+	assert.NoError(t, genre.Validate())
+}
+
+// TestGenreTree_Lookup tests finding genres by name or alias
+func TestGenreTree_Lookup(t *testing.T) {
+	tree := testGenreTree()
+
+	genre, ok := tree.Lookup("r&b / soul")
+	require.True(t, ok)
+	assert.Equal(t, "soul", genre.ID)
+	_, ok = tree.Lookup("Polka")
+	assert.False(t, ok)
+}
+
+// TestGenreTree_Walk tests children, breadcrumb paths and whether a genre falls under another
+func TestGenreTree_Walk(t *testing.T) {
+	tree := testGenreTree()
+
+	assert.Equal(t, []string{"pop", "soul"}, genreIDs(tree.Roots()))
+	assert.Equal(t, []string{"funk", "motown"}, genreIDs(tree.Children("soul")))
+	assert.Equal(t, []string{"soul", "motown", "northern-soul"}, genreIDs(tree.Path("northern-soul")))
+	assert.Empty(t, tree.Path("missing"))
+
+	assert.True(t, tree.Includes("soul", "Northern Soul"))
+	assert.True(t, tree.Includes("soul", "R&B/Soul"))
+	assert.True(t, tree.Includes("motown", "Motown"))
+	assert.False(t, tree.Includes("motown", "Funk"))
+	assert.False(t, tree.Includes("soul", "Pop"))
+	assert.False(t, tree.Includes("soul", "Polka"))
+}
+
+// TestGenreTree_CheckParent tests that genres cannot be filed under themselves, their sub-genres or missing genres
+func TestGenreTree_CheckParent(t *testing.T) {
+	tree := testGenreTree()
+
+	assert.NoError(t, tree.CheckParent("funk", "motown"))
+	assert.NoError(t, tree.CheckParent("funk", ""))
+	assert.NoError(t, tree.CheckParent("new-genre", "soul"))
+	assert.ErrorIs(t, tree.CheckParent("soul", "soul"), ErrGenreCycle)
+	assert.ErrorIs(t, tree.CheckParent("soul", "northern-soul"), ErrGenreCycle)
+	assert.Error(t, tree.CheckParent("funk", "missing"))
+}
+
+func genreIDs(genres []Genre) []string {
+	var ids []string
+	for _, genre := range genres {
+		ids = append(ids, genre.ID)
+	}
+	return ids
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+var (
+	// ErrGiftCardNotFound is returned when a gift card or store credit account does not exist.
+	ErrGiftCardNotFound = errors.New("gift card not found")
+	// ErrInsufficientBalance is returned when a debit would take a balance below zero.
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	// ErrDuplicateGiftCardCode is returned when issuing a gift card with a code that is already in use.
+	ErrDuplicateGiftCardCode = errors.New("gift card code already in use")
+)
+
+// GiftCardKind says how a balance came to exist. Gift cards are bought and redeemed with a code;
+// store credit belongs to one customer and is spent while signed in.
+type GiftCardKind string
+
+const (
+	GiftCardVoucher GiftCardKind = "gift-card"
+	StoreCredit     GiftCardKind = "store-credit"
+)
+
+// GiftCardEntryReason says why a balance changed.
+type GiftCardEntryReason string
+
+const (
+	EntryIssued   GiftCardEntryReason = "issued"
+	EntryRedeemed GiftCardEntryReason = "redeemed"
+	EntryRefunded GiftCardEntryReason = "refunded"
+	EntryAdjusted GiftCardEntryReason = "adjusted"
+)
+
+// GiftCard is a prepaid balance. Only a hash of a gift card's code is stored, so the code itself is
+// shown once, when the card is issued; Last4 lets staff and customers tell cards apart. Store credit
+// has no code and is found by its UserID.
+type GiftCard struct {
+	ID             string          `db:"id" json:"id"`
+	Kind           GiftCardKind    `db:"kind" json:"kind"`
+	CodeHash       string          `db:"code_hash" json:"-"`
+	Last4          string          `db:"last4" json:"last4,omitempty"`
+	UserID         string          `db:"user_id" json:"userId,omitempty"`
+	InitialBalance money.Amount    `db:"initial_balance" json:"initialBalance"`
+	Balance        money.Amount    `db:"balance" json:"balance"`
+	CreatedAt      time.Time       `db:"created_at" json:"createdAt"`
+	UpdatedAt      time.Time       `db:"updated_at" json:"updatedAt"`
+	Entries        []GiftCardEntry `db:"-" json:"entries,omitempty"`
+}
+
+// GiftCardEntry is one line in a card's ledger. Amount is positive for credits and negative for
+// debits; Balance is the card's balance after it.
+type GiftCardEntry struct {
+	ID         string              `db:"id" json:"id"`
+	GiftCardID string              `db:"gift_card_id" json:"giftCardId"`
+	Amount     money.Amount        `db:"amount" json:"amount"`
+	Balance    money.Amount        `db:"balance" json:"balance"`
+	Reason     GiftCardEntryReason `db:"reason" json:"reason"`
+	OrderID    string              `db:"order_id" json:"orderId,omitempty"`
+	Actor      string              `db:"actor" json:"actor"`
+	Note       string              `db:"note" json:"note,omitempty"`
+	CreatedAt  time.Time           `db:"created_at" json:"createdAt"`
+}
+
+// NormalizeGiftCardCode puts a code the way it is hashed: upper case, without the dashes and spaces
+// customers type to break it up.
+func NormalizeGiftCardCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(code)))
+}
+
+// HashGiftCardCode returns the hash a gift card's code is stored and looked up by.
+func HashGiftCardCode(code string) string {
+	sum := sha256.Sum256([]byte(NormalizeGiftCardCode(code)))
+	return hex.EncodeToString(sum[:])
+}
+
+// lastFour returns the end of a code, for telling cards apart without revealing them.
+func lastFour(code string) string {
+	normalized := NormalizeGiftCardCode(code)
+	if len(normalized) < 4 {
+		return normalized
+	}
+	return normalized[len(normalized)-4:]
+}
+
+// GiftCardRepository stores gift cards, store credit and their ledgers. Every balance change is an
+// entry in the ledger. Create issues a gift card for code, failing with ErrDuplicateGiftCardCode if
+// the code is taken. Apply adds
+// entry.Amount to the balance, failing with ErrInsufficientBalance rather than going below zero.
+// AddStoreCredit credits a customer's store credit, opening the account on first use.
+type GiftCardRepository interface {
+	Create(code string, initialBalance money.Amount, actor, note string) (GiftCard, error)
+	GetByID(id string) (GiftCard, error)
+	GetByCode(code string) (GiftCard, error)
+	GetStoreCredit(userID string) (GiftCard, error)
+	List(kind GiftCardKind) ([]GiftCard, error)
+	Apply(id string, entry GiftCardEntry) (GiftCard, error)
+	AddStoreCredit(userID string, entry GiftCardEntry) (GiftCard, error)
+	Entries(id string) ([]GiftCardEntry, error)
+}
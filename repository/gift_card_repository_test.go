@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNormalizeGiftCardCode tests that codes match however customers type them
+func TestNormalizeGiftCardCode(t *testing.T) {
+	assert.Equal(t, "ABCD2345EFGH6789", NormalizeGiftCardCode(" abcd-2345 efgh-6789 "))
+	assert.Equal(t, HashGiftCardCode("ABCD-2345-EFGH-6789"), HashGiftCardCode("abcd2345efgh6789"))
+	assert.NotEqual(t, HashGiftCardCode("ABCD-2345-EFGH-6789"), HashGiftCardCode("ABCD-2345-EFGH-6788"))
+	assert.Len(t, HashGiftCardCode("ABCD-2345-EFGH-6789"), 64)
+}
+
+// TestLastFour tests the part of a code shown to tell cards apart
+func TestLastFour(t *testing.T) {
+	assert.Equal(t, "6789", lastFour("abcd-2345-efgh-6789"))
+	assert.Equal(t, "AB", lastFour("ab"))
+}
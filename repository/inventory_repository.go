@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInsufficientStock is returned when an operation would leave fewer units on hand than are reserved,
+// or would reserve more units than are available.
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+// Format identifies the physical medium an album is stocked in.
+type Format string
+
+const (
+	FormatLP       Format = "LP"
+	FormatCD       Format = "CD"
+	FormatCassette Format = "cassette"
+)
+
+// Formats lists every format the shop stocks, in display order.
+var Formats = []Format{FormatLP, FormatCD, FormatCassette}
+
+// ParseFormat converts user input such as "lp" or "Cassette" into a Format.
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if strings.EqualFold(strings.TrimSpace(s), string(f)) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("invalid format %q: must be one of LP, CD, cassette", s)
+}
+
+// AdjustmentReason records why staff changed the quantity on hand.
+type AdjustmentReason string
+
+const (
+	ReasonReceived   AdjustmentReason = "received"
+	ReasonDamaged    AdjustmentReason = "damaged"
+	ReasonLost       AdjustmentReason = "lost"
+	ReasonStocktake  AdjustmentReason = "stocktake"
+	ReasonReturned   AdjustmentReason = "returned"
+	ReasonCorrection AdjustmentReason = "correction"
+)
+
+// AdjustmentReasons lists the reason codes accepted for stock adjustments.
+var AdjustmentReasons = []AdjustmentReason{ReasonReceived, ReasonDamaged, ReasonLost, ReasonStocktake, ReasonReturned, ReasonCorrection}
+
+// ParseAdjustmentReason validates a reason code supplied by staff.
+func ParseAdjustmentReason(s string) (AdjustmentReason, error) {
+	for _, r := range AdjustmentReasons {
+		if strings.EqualFold(strings.TrimSpace(s), string(r)) {
+			return r, nil
+		}
+	}
+	return "", fmt.Errorf("invalid reason %q", s)
+}
+
+// StockLevel is the stock held for one album in one format.
+// Available is derived as OnHand minus Reserved.
+type StockLevel struct {
+	AlbumID   string `db:"album_id" json:"albumId"`
+	Format    Format `db:"format" json:"format"`
+	OnHand    int    `db:"on_hand" json:"onHand"`
+	Reserved  int    `db:"reserved" json:"reserved"`
+	Available int    `db:"available" json:"available"`
+}
+
+// StockAdjustment is an audit record of a staff change to the quantity on hand.
+type StockAdjustment struct {
+	ID        string           `db:"id" json:"id"`
+	AlbumID   string           `db:"album_id" json:"albumId"`
+	Format    Format           `db:"format" json:"format"`
+	Delta     int              `db:"delta" json:"delta"`
+	Reason    AdjustmentReason `db:"reason" json:"reason"`
+	Note      string           `db:"note" json:"note"`
+	Actor     string           `db:"actor" json:"actor"`
+	CreatedAt time.Time        `db:"created_at" json:"createdAt"`
+}
+
+// InventoryRepository tracks stock per album and format. Adjust, Reserve, Release and Fulfil are atomic:
+// concurrent callers can never drive OnHand or Reserved negative, nor reserve more than is on hand.
+// Fulfil takes reserved units out of stock when an order ships.
+type InventoryRepository interface {
+	GetStock(albumID string) ([]StockLevel, error)
+	GetAllStock() ([]StockLevel, error)
+	Adjust(adjustment StockAdjustment) (StockLevel, error)
+	Reserve(albumID string, format Format, quantity int) (StockLevel, error)
+	Release(albumID string, format Format, quantity int) (StockLevel, error)
+	Fulfil(albumID string, format Format, quantity int) (StockLevel, error)
+	ListAdjustments(albumID string) ([]StockAdjustment, error)
+}
+
+// applyAdjustment returns the stock level after adding delta units on hand.
+func applyAdjustment(level StockLevel, delta int) (StockLevel, error) {
+	if level.OnHand+delta < level.Reserved {
+		return level, ErrInsufficientStock
+	}
+	level.OnHand += delta
+	level.Available = level.OnHand - level.Reserved
+	return level, nil
+}
+
+// applyReservation returns the stock level after reserving quantity units (negative to release).
+func applyReservation(level StockLevel, quantity int) (StockLevel, error) {
+	if level.Reserved+quantity < 0 || level.Reserved+quantity > level.OnHand {
+		return level, ErrInsufficientStock
+	}
+	level.Reserved += quantity
+	level.Available = level.OnHand - level.Reserved
+	return level, nil
+}
+
+// applyFulfilment returns the stock level after quantity reserved units have left the shop.
+func applyFulfilment(level StockLevel, quantity int) (StockLevel, error) {
+	if quantity > level.Reserved {
+		return level, ErrInsufficientStock
+	}
+	level.OnHand -= quantity
+	level.Reserved -= quantity
+	level.Available = level.OnHand - level.Reserved
+	return level, nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseFormat tests that formats are matched case-insensitively and unknown formats are rejected
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat(" lp ")
+	require.NoError(t, err)
+	assert.Equal(t, FormatLP, format)
+
+	format, err = ParseFormat("CASSETTE")
+	require.NoError(t, err)
+	assert.Equal(t, FormatCassette, format)
+
+	_, err = ParseFormat("8-track")
+	assert.Error(t, err)
+}
+
+// TestApplyAdjustment tests that on-hand stock cannot drop below the reserved quantity
+func TestApplyAdjustment(t *testing.T) {
+	level := StockLevel{OnHand: 5, Reserved: 3}
+
+	next, err := applyAdjustment(level, -2)
+	require.NoError(t, err)
+	assert.Equal(t, 3, next.OnHand)
+	assert.Equal(t, 0, next.Available)
+
+	_, err = applyAdjustment(level, -3)
+	assert.True(t, errors.Is(err, ErrInsufficientStock))
+}
+
+// TestApplyReservation tests reserving and releasing against available stock
+func TestApplyReservation(t *testing.T) {
+	level := StockLevel{OnHand: 2, Reserved: 1}
+
+	next, err := applyReservation(level, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, next.Reserved)
+	assert.Equal(t, 0, next.Available)
+
+	_, err = applyReservation(next, 1)
+	assert.True(t, errors.Is(err, ErrInsufficientStock), "cannot reserve more than is on hand")
+
+	_, err = applyReservation(level, -2)
+	assert.True(t, errors.Is(err, ErrInsufficientStock), "cannot release more than is reserved")
+}
+
+// TestApplyFulfilment tests that shipping removes units from both on-hand and reserved stock
+func TestApplyFulfilment(t *testing.T) {
+	level := StockLevel{OnHand: 4, Reserved: 2}
+
+	next, err := applyFulfilment(level, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, next.OnHand)
+	assert.Equal(t, 0, next.Reserved)
+	assert.Equal(t, 2, next.Available)
+
+	_, err = applyFulfilment(level, 3)
+	assert.True(t, errors.Is(err, ErrInsufficientStock), "cannot ship more than is reserved")
+}
+
+// TestCassandraInventoryRepository_InvalidUUID tests error handling for invalid album UUIDs
+func TestCassandraInventoryRepository_InvalidUUID(t *testing.T) {
+	repo := &CassandraInventoryRepository{session: nil}
+
+	_, err := repo.GetStock("invalid-uuid")
+	assert.Contains(t, err.Error(), "invalid UUID")
+
+	_, err = repo.Reserve("invalid-uuid", FormatCD, 1)
+	assert.Contains(t, err.Error(), "invalid UUID")
+
+	_, err = repo.Adjust(StockAdjustment{AlbumID: "invalid-uuid", Format: FormatCD, Delta: 1})
+	assert.Contains(t, err.Error(), "invalid UUID")
+}
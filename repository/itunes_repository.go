@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"net/url"
 	"time"
+
+	"github.com/tvergilio/motown-house-backend/money"
 )
 
 // ITunesSearchResponse maps the top-level response from iTunes.
@@ -16,12 +18,15 @@ type ITunesSearchResponse struct {
 
 // ITunesAlbum maps one album entry from iTunes.
 type ITunesAlbum struct {
-	ArtistName       string  `json:"artistName"`
-	CollectionName   string  `json:"collectionName"`
-	CollectionPrice  float64 `json:"collectionPrice"`
-	ReleaseDate      string  `json:"releaseDate"`
-	PrimaryGenreName string  `json:"primaryGenreName"`
-	ArtworkUrl100    string  `json:"artworkUrl100"`
+	ArtistID         int64        `json:"artistId"`
+	ArtistName       string       `json:"artistName"`
+	CollectionName   string       `json:"collectionName"`
+	CollectionPrice  money.Amount `json:"collectionPrice"`
+	Currency         string       `json:"currency"`
+	ReleaseDate      string       `json:"releaseDate"`
+	PrimaryGenreName string       `json:"primaryGenreName"`
+	ArtworkUrl100    string       `json:"artworkUrl100"`
+	Copyright        string       `json:"copyright"`
 }
 
 // ITunesRepository interface for searching iTunes API
@@ -91,12 +96,15 @@ func (r *ITunesRepositoryImpl) Search(term string) ([]AlbumResponse, error) {
 		}
 
 		albumResponse := AlbumResponse{
-			Title:    itunesAlbum.CollectionName,
-			Artist:   itunesAlbum.ArtistName,
-			Price:    itunesAlbum.CollectionPrice,
-			Year:     year,
-			Genre:    itunesAlbum.PrimaryGenreName,
-			ImageURL: itunesAlbum.ArtworkUrl100,
+			Title:          itunesAlbum.CollectionName,
+			Artist:         itunesAlbum.ArtistName,
+			ITunesArtistID: itunesAlbum.ArtistID,
+			Price:          itunesAlbum.CollectionPrice,
+			Currency:       itunesAlbum.Currency,
+			Year:           year,
+			Genre:          itunesAlbum.PrimaryGenreName,
+			ImageURL:       itunesAlbum.ArtworkUrl100,
+			Label:          LabelFromCopyright(itunesAlbum.Copyright),
 		}
 		searchResults = append(searchResults, albumResponse)
 	}
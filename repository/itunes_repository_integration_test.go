@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
 )
 
 // TestITunesRepository_Search_Integration tests the actual iTunes API integration
@@ -27,7 +28,7 @@ func TestITunesRepository_Search_Integration(t *testing.T) {
 	require.NotEmpty(t, firstResult.Genre, "Genre should not be empty")
 	require.NotEmpty(t, firstResult.ImageURL, "ImageURL should not be empty")
 	require.Greater(t, firstResult.Year, 0, "Year should be greater than 0")
-	require.Greater(t, firstResult.Price, 0.0, "Price should be greater than 0")
+	require.Greater(t, firstResult.Price, money.Amount(0), "Price should be greater than 0")
 
 	// Test that we can find Michael Jackson in the results
 	foundMichaelJackson := false
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	// ErrLabelNotFound is returned when a record label does not exist.
+	ErrLabelNotFound = errors.New("label not found")
+	// ErrDuplicateLabel is returned when another label already goes by a label's name.
+	ErrDuplicateLabel = errors.New("another label already goes by that name")
+	// ErrLabelHasAlbums is returned when deleting a label that albums were still released on.
+	ErrLabelHasAlbums = errors.New("label still has albums")
+)
+
+// Label is a record label or imprint, such as Tamla or Gordy. Albums keep the label's name as well
+// as its ID, so they can be shown without looking the label up.
+type Label struct {
+	ID        string    `db:"id" json:"id"`
+	Name      string    `db:"name" json:"name"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// labelSuffixes are words left off the end of label names when matching them, so the "Motown
+// Records" in an iTunes copyright line is the Motown label.
+var labelSuffixes = []string{"inc", "ltd", "llc", "records", "recordings", "record company", "record corporation", "music"}
+
+// LabelNameKey is the form label names are matched in, so that "Tamla", "TAMLA" and "Tamla Records"
+// are the same label.
+func LabelNameKey(name string) string {
+	folded := strings.ReplaceAll(strings.ToLower(name), "&", " and ")
+	folded = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, folded)
+	key := strings.Join(strings.Fields(folded), " ")
+	for _, suffix := range labelSuffixes {
+		if trimmed := strings.TrimSuffix(key, " "+suffix); trimmed != "" {
+			key = trimmed
+		}
+	}
+	if key == "" {
+		return strings.ToLower(strings.TrimSpace(name))
+	}
+	return key
+}
+
+// LabelFromCopyright picks the label out of a copyright line such as iTunes gives for an album:
+// "℗ 1971 Motown Records, a Division of UMG Recordings, Inc." is "Motown Records". It returns ""
+// if the line does not name one.
+func LabelFromCopyright(copyright string) string {
+	line := copyright
+	for _, mark := range []string{"℗", "(P)", "©", "(C)"} {
+		if i := strings.Index(line, mark); i >= 0 {
+			line = line[i+len(mark):]
+			break
+		}
+	}
+	// Skip the year or years the copyright is for.
+	line = strings.TrimLeftFunc(line, func(r rune) bool {
+		return unicode.IsDigit(r) || unicode.IsSpace(r) || r == ',' || r == '-' || r == '/'
+	})
+	line, _, _ = strings.Cut(line, ",")
+	line, _, _ = strings.Cut(line, " under ")
+	return strings.Join(strings.Fields(line), " ")
+}
+
+// Normalize tidies the label's name.
+func (l *Label) Normalize() {
+	l.Name = strings.Join(strings.Fields(l.Name), " ")
+}
+
+// Validate checks the label has a name.
+func (l Label) Validate() error {
+	if strings.TrimSpace(l.Name) == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+// sortLabels orders labels by name, ignoring case.
+func sortLabels(labels []Label) {
+	sort.SliceStable(labels, func(i, j int) bool {
+		return strings.ToLower(labels[i].Name) < strings.ToLower(labels[j].Name)
+	})
+}
+
+type LabelRepository interface {
+	// Create adds a label, failing with ErrDuplicateLabel if another label goes by its name.
+	Create(label Label) (Label, error)
+	GetByID(id string) (Label, error)
+	// GetByName returns the label going by name, compared with LabelNameKey.
+	GetByName(name string) (Label, error)
+	// List returns every label ordered by name.
+	List() ([]Label, error)
+	Update(label Label) (Label, error)
+	// Delete removes a label. Postgres refuses with ErrLabelHasAlbums while albums refer to it;
+	// Cassandra cannot check, so callers must.
+	Delete(id string) error
+}
+
+// ResolveLabel returns the label going by name, creating it if there is none yet.
+func ResolveLabel(labels LabelRepository, name string) (Label, error) {
+	label, err := labels.GetByName(name)
+	if !errors.Is(err, ErrLabelNotFound) {
+		return label, err
+	}
+	label = Label{Name: name}
+	label.Normalize()
+	created, err := labels.Create(label)
+	if errors.Is(err, ErrDuplicateLabel) {
+		// Someone else created the label in the meantime.
+		return labels.GetByName(name)
+	}
+	return created, err
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLabelNameKey tests that names differing only in case, punctuation or a trailing "Records" match
+func TestLabelNameKey(t *testing.T) {
+	assert.Equal(t, "tamla", LabelNameKey("Tamla"))
+	assert.Equal(t, LabelNameKey("Tamla"), LabelNameKey("TAMLA Records"))
+	assert.Equal(t, LabelNameKey("Motown"), LabelNameKey("Motown Record Corporation"))
+	assert.Equal(t, LabelNameKey("Motown"), LabelNameKey("Motown Records, Inc."))
+	assert.Equal(t, LabelNameKey("V.I.P."), LabelNameKey("V I P"))
+	assert.NotEqual(t, LabelNameKey("Tamla"), LabelNameKey("Tamla Motown"))
+	assert.Equal(t, "records", LabelNameKey("Records"))
+}
+
+// TestLabelFromCopyright tests picking the label out of iTunes copyright lines
+func TestLabelFromCopyright(t *testing.T) {
+	assert.Equal(t, "Motown Records", LabelFromCopyright("℗ 1971 Motown Records, a Division of UMG Recordings, Inc."))
+	assert.Equal(t, "Tamla", LabelFromCopyright("This Compilation ℗ 1970, 1972 Tamla"))
+	assert.Equal(t, "Gordy Records", LabelFromCopyright("(P) 1968 Gordy Records under exclusive license to Universal"))
+	assert.Equal(t, "Motown Records", LabelFromCopyright("1976 Motown Records"))
+	assert.Equal(t, "", LabelFromCopyright("℗ 1971"))
+	assert.Equal(t, "", LabelFromCopyright(""))
+}
+
+// TestLabel_NormalizeAndValidate tests tidying label names and that labels need one
+func TestLabel_NormalizeAndValidate(t *testing.T) {
+	label := Label{Name: "  Rare   Earth "}
+	label.Normalize()
+	assert.Equal(t, "Rare Earth", label.Name)
+	assert.NoError(t, label.Validate())
+	assert.Error(t, Label{Name: " "}.Validate())
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+var (
+	// ErrOrderNotFound is returned when an order does not exist.
+	ErrOrderNotFound = errors.New("order not found")
+	// ErrInvalidTransition is returned when an order cannot move to the requested status.
+	ErrInvalidTransition = errors.New("invalid order status transition")
+)
+
+// OrderStatus is a stage in the order lifecycle.
+type OrderStatus string
+
+const (
+	OrderPending   OrderStatus = "pending"
+	OrderPaid      OrderStatus = "paid"
+	OrderShipped   OrderStatus = "shipped"
+	OrderDelivered OrderStatus = "delivered"
+	OrderCancelled OrderStatus = "cancelled"
+)
+
+// orderTransitions lists the statuses each status may move to. Delivered and cancelled are final.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderPending: {OrderPaid, OrderCancelled},
+	OrderPaid:    {OrderShipped, OrderCancelled},
+	OrderShipped: {OrderDelivered},
+}
+
+// ParseOrderStatus validates a status supplied by a client.
+func ParseOrderStatus(s string) (OrderStatus, error) {
+	status := OrderStatus(strings.ToLower(strings.TrimSpace(s)))
+	switch status {
+	case OrderPending, OrderPaid, OrderShipped, OrderDelivered, OrderCancelled:
+		return status, nil
+	}
+	return "", fmt.Errorf("invalid order status %q", s)
+}
+
+// CanTransitionTo reports whether an order in status s may move to next.
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Order is a customer's purchase. Lines snapshot the album details, price, any promotion and the
+// tax charged at checkout, so later catalogue or rate edits do not change what the customer paid.
+// Tax is the tax on all lines; Total includes it, whether it was added on top of the line totals
+// or, when TaxIncluded is set, already part of them.
+type Order struct {
+	ID          string       `db:"id" json:"id"`
+	UserID      string       `db:"user_id" json:"userId"`
+	Status      OrderStatus  `db:"status" json:"status"`
+	Lines       []OrderLine  `db:"-" json:"lines"`
+	CouponCode  string       `db:"coupon_code" json:"couponCode,omitempty"`
+	Discount    money.Amount `db:"discount" json:"discount"`
+	Country     string       `db:"country" json:"country,omitempty"`
+	Region      string       `db:"region" json:"region,omitempty"`
+	Tax         money.Amount `db:"tax" json:"tax"`
+	TaxIncluded bool         `db:"tax_included" json:"taxIncluded"`
+	Total       money.Amount `db:"total" json:"total"`
+	CreatedAt   time.Time    `db:"created_at" json:"createdAt"`
+	UpdatedAt   time.Time    `db:"updated_at" json:"updatedAt"`
+	History     []OrderEvent `db:"-" json:"history,omitempty"`
+}
+
+// OrderLine is one album and format in an order, or one release of the album when ReleaseID is
+// set. TaxName and TaxRate record which rate the line's
+// Tax was charged at.
+type OrderLine struct {
+	AlbumID     string       `db:"album_id" json:"albumId"`
+	ReleaseID   string       `db:"release_id" json:"releaseId,omitempty"`
+	Format      Format       `db:"format" json:"format"`
+	Title       string       `db:"title" json:"title"`
+	Artist      string       `db:"artist" json:"artist"`
+	UnitPrice   money.Amount `db:"unit_price" json:"unitPrice"`
+	Quantity    int          `db:"quantity" json:"quantity"`
+	Discount    money.Amount `db:"discount" json:"discount,omitempty"`
+	Promotion   string       `db:"promotion" json:"promotion,omitempty"`
+	LineTotal   money.Amount `db:"line_total" json:"lineTotal"`
+	TaxCategory string       `db:"tax_category" json:"taxCategory,omitempty"`
+	TaxName     string       `db:"tax_name" json:"taxName,omitempty"`
+	TaxRate     float64      `db:"tax_rate" json:"taxRate"`
+	Tax         money.Amount `db:"tax" json:"tax"`
+}
+
+// OrderEvent records a status change and who made it.
+type OrderEvent struct {
+	Status    OrderStatus `db:"status" json:"status"`
+	Actor     string      `db:"actor" json:"actor"`
+	Note      string      `db:"note" json:"note,omitempty"`
+	CreatedAt time.Time   `db:"created_at" json:"createdAt"`
+}
+
+// OrderRepository stores orders and keeps inventory in step with them:
+// Create reserves stock for every line (all or nothing, failing with ErrInsufficientStock),
+// cancelling releases the reservations and shipping fulfils them.
+type OrderRepository interface {
+	Create(order Order) (Order, error)
+	GetByID(id string) (Order, error)
+	ListByUser(userID string) ([]Order, error)
+	List(status OrderStatus) ([]Order, error)
+	UpdateStatus(id string, status OrderStatus, actor, note string) (Order, error)
+}
+
+// sortedLines returns the lines in album and format order, so that concurrent checkouts
+// lock stock rows in the same order and cannot deadlock.
+func sortedLines(lines []OrderLine) []OrderLine {
+	sorted := append([]OrderLine(nil), lines...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].AlbumID != sorted[j].AlbumID {
+			return sorted[i].AlbumID < sorted[j].AlbumID
+		}
+		return sorted[i].Format < sorted[j].Format
+	})
+	return sorted
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOrderStatus_CanTransitionTo tests the order state machine
+func TestOrderStatus_CanTransitionTo(t *testing.T) {
+	testCases := []struct {
+		from, to OrderStatus
+		allowed  bool
+	}{
+		{OrderPending, OrderPaid, true},
+		{OrderPending, OrderCancelled, true},
+		{OrderPending, OrderShipped, false},
+		{OrderPaid, OrderShipped, true},
+		{OrderPaid, OrderCancelled, true},
+		{OrderShipped, OrderDelivered, true},
+		{OrderShipped, OrderCancelled, false},
+		{OrderDelivered, OrderCancelled, false},
+		{OrderCancelled, OrderPending, false},
+	}
+	for _, tc := range testCases {
+		t.Run(string(tc.from)+"->"+string(tc.to), func(t *testing.T) {
+			assert.Equal(t, tc.allowed, tc.from.CanTransitionTo(tc.to))
+		})
+	}
+}
+
+// TestParseOrderStatus tests status parsing
+func TestParseOrderStatus(t *testing.T) {
+	status, err := ParseOrderStatus(" Shipped ")
+	require.NoError(t, err)
+	assert.Equal(t, OrderShipped, status)
+
+	_, err = ParseOrderStatus("lost")
+	assert.Error(t, err)
+}
+
+// TestSortedLines tests that lines are ordered by album and format without modifying the input
+func TestSortedLines(t *testing.T) {
+	lines := []OrderLine{{AlbumID: "2", Format: FormatLP}, {AlbumID: "1", Format: FormatLP}, {AlbumID: "1", Format: FormatCD}}
+
+	sorted := sortedLines(lines)
+
+	assert.Equal(t, []OrderLine{{AlbumID: "1", Format: FormatCD}, {AlbumID: "1", Format: FormatLP}, {AlbumID: "2", Format: FormatLP}}, sorted)
+	assert.Equal(t, "2", lines[0].AlbumID)
+}
+
+// TestCassandraOrderRepository_InvalidUUID tests that malformed order IDs are reported as not found
+func TestCassandraOrderRepository_InvalidUUID(t *testing.T) {
+	repo := NewCassandraOrderRepository(nil, nil)
+
+	_, err := repo.GetByID("invalid-uuid")
+
+	assert.True(t, errors.Is(err, ErrOrderNotFound))
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+var (
+	// ErrPaymentNotFound is returned when a payment intent does not exist.
+	ErrPaymentNotFound = errors.New("payment not found")
+	// ErrDuplicateIdempotencyKey is returned when creating an intent whose idempotency key is already used.
+	ErrDuplicateIdempotencyKey = errors.New("idempotency key already used")
+	// ErrPaymentInProgress is returned when creating an intent for an order that already has one pending or authorised.
+	ErrPaymentInProgress = errors.New("another payment for this order is in progress")
+)
+
+// PaymentStatus is a stage in a payment intent's lifecycle.
+type PaymentStatus string
+
+const (
+	PaymentPending    PaymentStatus = "pending"
+	PaymentAuthorized PaymentStatus = "authorized"
+	PaymentCaptured   PaymentStatus = "captured"
+	PaymentFailed     PaymentStatus = "failed"
+	PaymentRefunded   PaymentStatus = "refunded"
+)
+
+// inFlight reports whether an intent with this status may still be charged. An order has at most one
+// such intent at a time, so two payments cannot both be charged the same outstanding balance.
+func (s PaymentStatus) inFlight() bool {
+	return s == PaymentPending || s == PaymentAuthorized
+}
+
+// PaymentIntent is one attempt to pay for an order. The client-supplied IdempotencyKey is unique,
+// so retrying a request after a timeout returns the original intent rather than charging twice.
+type PaymentIntent struct {
+	ID             string        `db:"id" json:"id"`
+	OrderID        string        `db:"order_id" json:"orderId"`
+	IdempotencyKey string        `db:"idempotency_key" json:"-"`
+	Provider       string        `db:"provider" json:"provider"`
+	ProviderRef    string        `db:"provider_ref" json:"providerRef,omitempty"`
+	Amount         money.Amount  `db:"amount" json:"amount"`
+	RefundedAmount money.Amount  `db:"refunded_amount" json:"refundedAmount"`
+	Status         PaymentStatus `db:"status" json:"status"`
+	FailureReason  string        `db:"failure_reason" json:"failureReason,omitempty"`
+	CreatedAt      time.Time     `db:"created_at" json:"createdAt"`
+	UpdatedAt      time.Time     `db:"updated_at" json:"updatedAt"`
+}
+
+// PaymentRepository stores payment intents. Create fails with ErrDuplicateIdempotencyKey if the key is
+// taken, or ErrPaymentInProgress if the order already has a pending or authorised intent; Update
+// overwrites the provider reference, amounts, status and failure reason.
+type PaymentRepository interface {
+	Create(intent PaymentIntent) (PaymentIntent, error)
+	GetByID(id string) (PaymentIntent, error)
+	GetByIdempotencyKey(key string) (PaymentIntent, error)
+	GetByProviderRef(provider, ref string) (PaymentIntent, error)
+	ListByOrder(orderID string) ([]PaymentIntent, error)
+	Update(intent PaymentIntent) (PaymentIntent, error)
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCassandraPaymentRepository_InvalidUUID tests that malformed IDs are rejected before querying
+func TestCassandraPaymentRepository_InvalidUUID(t *testing.T) {
+	repo := NewCassandraPaymentRepository(nil)
+
+	_, err := repo.GetByID("invalid-uuid")
+	assert.True(t, errors.Is(err, ErrPaymentNotFound))
+
+	_, err = repo.Create(PaymentIntent{OrderID: "invalid-uuid", IdempotencyKey: "k"})
+	assert.True(t, errors.Is(err, ErrOrderNotFound))
+
+	intents, err := repo.ListByOrder("invalid-uuid")
+	assert.NoError(t, err)
+	assert.Empty(t, intents)
+}
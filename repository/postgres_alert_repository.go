@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PostgresAlertRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresAlertRepository(db *sqlx.DB) *PostgresAlertRepository {
+	return &PostgresAlertRepository{db: db}
+}
+
+const alertColumns = "id, user_id, email, album_id, kind, format, target_price, created_at, triggered_at"
+
+func (r *PostgresAlertRepository) Create(alert Alert) (Alert, error) {
+	var created Alert
+	err := r.db.Get(&created,
+		`INSERT INTO alerts (user_id, email, album_id, kind, format, target_price)
+		 VALUES ($1, $2, $3, $4, $5, $6) RETURNING `+alertColumns,
+		alert.UserID, alert.Email, alert.AlbumID, alert.Kind, alert.Format, alert.TargetPrice,
+	)
+	return created, err
+}
+
+func (r *PostgresAlertRepository) GetByID(id string) (Alert, error) {
+	if !validUUID(id) {
+		return Alert{}, ErrAlertNotFound
+	}
+	var alert Alert
+	err := r.db.Get(&alert, "SELECT "+alertColumns+" FROM alerts WHERE id = $1", id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Alert{}, ErrAlertNotFound
+	}
+	return alert, err
+}
+
+func (r *PostgresAlertRepository) ListByUser(userID string) ([]Alert, error) {
+	var alerts []Alert
+	err := r.db.Select(&alerts, "SELECT "+alertColumns+" FROM alerts WHERE user_id = $1 ORDER BY created_at DESC", userID)
+	return alerts, err
+}
+
+func (r *PostgresAlertRepository) ListActiveByAlbum(albumID string) ([]Alert, error) {
+	if !validAlbumID(albumID) {
+		return nil, nil
+	}
+	var alerts []Alert
+	err := r.db.Select(&alerts,
+		"SELECT "+alertColumns+" FROM alerts WHERE album_id = $1 AND triggered_at IS NULL ORDER BY created_at",
+		albumID,
+	)
+	return alerts, err
+}
+
+func (r *PostgresAlertRepository) MarkTriggered(id string, at time.Time) error {
+	if !validUUID(id) {
+		return ErrAlertNotFound
+	}
+	res, err := r.db.Exec("UPDATE alerts SET triggered_at = $1 WHERE id = $2", at, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAlertNotFound
+	}
+	return nil
+}
+
+func (r *PostgresAlertRepository) Delete(id string) error {
+	if !validUUID(id) {
+		return ErrAlertNotFound
+	}
+	res, err := r.db.Exec("DELETE FROM alerts WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAlertNotFound
+	}
+	return nil
+}
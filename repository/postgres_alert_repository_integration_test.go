@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestPostgresAlertRepository tests that triggered alerts drop out of the album's active alerts but stay listed for the user.
+func TestPostgresAlertRepository(t *testing.T) {
+	db, teardown := setupTestPostgres(t)
+	defer teardown()
+	albumID := createTestPostgresAlbum(t, NewPostgresAlbumRepository(db))
+	repo := NewPostgresAlertRepository(db)
+	target := money.MustParse("0.50")
+
+	priceDrop, err := repo.Create(Alert{UserID: "user-1", Email: "ann@example.com", AlbumID: albumID, Kind: AlertPriceDrop, TargetPrice: &target})
+	require.NoError(t, err)
+	require.Equal(t, money.MustParse("0.50"), *priceDrop.TargetPrice)
+	backInStock, err := repo.Create(Alert{UserID: "user-1", Email: "ann@example.com", AlbumID: albumID, Kind: AlertBackInStock, Format: FormatLP})
+	require.NoError(t, err)
+	require.Nil(t, backInStock.TargetPrice)
+
+	active, err := repo.ListActiveByAlbum(albumID)
+	require.NoError(t, err)
+	require.Len(t, active, 2)
+
+	require.NoError(t, repo.MarkTriggered(priceDrop.ID, time.Now()))
+	active, err = repo.ListActiveByAlbum(albumID)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	require.Equal(t, backInStock.ID, active[0].ID)
+
+	mine, err := repo.ListByUser("user-1")
+	require.NoError(t, err)
+	require.Len(t, mine, 2)
+
+	require.NoError(t, repo.Delete(backInStock.ID))
+	_, err = repo.GetByID(backInStock.ID)
+	require.True(t, errors.Is(err, ErrAlertNotFound))
+	require.True(t, errors.Is(repo.Delete(backInStock.ID), ErrAlertNotFound))
+}
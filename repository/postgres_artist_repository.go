@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// PostgresArtistRepository keeps the key of every name and alias an artist goes by in artist_names,
+// whose primary key stops two artists from claiming the same name.
+type PostgresArtistRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresArtistRepository(db *sqlx.DB) *PostgresArtistRepository {
+	return &PostgresArtistRepository{db: db}
+}
+
+const artistColumns = "id, name, sort_name, aliases, bio, image_url, COALESCE(itunes_artist_id, 0) AS itunes_artist_id, created_at, updated_at"
+
+func (r *PostgresArtistRepository) Create(artist Artist) (Artist, error) {
+	artist.Normalize()
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return Artist{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var created Artist
+	if err := tx.Get(&created,
+		`INSERT INTO artists (name, sort_name, aliases, bio, image_url, itunes_artist_id)
+		 VALUES ($1, $2, $3, $4, $5, NULLIF($6, 0)) RETURNING `+artistColumns,
+		artist.Name, artist.SortName, artist.Aliases, artist.Bio, artist.ImageURL, artist.ITunesArtistID,
+	); err != nil {
+		return Artist{}, err
+	}
+	if err := claimArtistNames(tx, created); err != nil {
+		return Artist{}, err
+	}
+	return created, tx.Commit()
+}
+
+// claimArtistNames replaces the names recorded for the artist with its current name and aliases.
+func claimArtistNames(tx *sqlx.Tx, artist Artist) error {
+	if _, err := tx.Exec("DELETE FROM artist_names WHERE artist_id = $1", artist.ID); err != nil {
+		return err
+	}
+	for _, key := range artist.nameKeys() {
+		_, err := tx.Exec("INSERT INTO artist_names (name_key, artist_id) VALUES ($1, $2)", key, artist.ID)
+		if isUniqueViolation(err) {
+			return ErrDuplicateArtist
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *PostgresArtistRepository) GetByID(id string) (Artist, error) {
+	if !validUUID(id) {
+		return Artist{}, ErrArtistNotFound
+	}
+	return r.get("SELECT "+artistColumns+" FROM artists WHERE id = $1", id)
+}
+
+func (r *PostgresArtistRepository) GetByName(name string) (Artist, error) {
+	return r.get(
+		"SELECT "+artistColumns+" FROM artists WHERE id = (SELECT artist_id FROM artist_names WHERE name_key = $1)",
+		ArtistNameKey(name),
+	)
+}
+
+func (r *PostgresArtistRepository) get(query, arg string) (Artist, error) {
+	var artist Artist
+	err := r.db.Get(&artist, query, arg)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Artist{}, ErrArtistNotFound
+	}
+	return artist, err
+}
+
+func (r *PostgresArtistRepository) List() ([]Artist, error) {
+	var artists []Artist
+	err := r.db.Select(&artists, "SELECT "+artistColumns+" FROM artists ORDER BY lower(sort_name), name")
+	return artists, err
+}
+
+func (r *PostgresArtistRepository) Update(artist Artist) (Artist, error) {
+	if !validUUID(artist.ID) {
+		return Artist{}, ErrArtistNotFound
+	}
+	artist.Normalize()
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return Artist{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var updated Artist
+	err = tx.Get(&updated,
+		`UPDATE artists SET name = $1, sort_name = $2, aliases = $3, bio = $4, image_url = $5,
+		 itunes_artist_id = NULLIF($6, 0), updated_at = now() WHERE id = $7 RETURNING `+artistColumns,
+		artist.Name, artist.SortName, artist.Aliases, artist.Bio, artist.ImageURL, artist.ITunesArtistID, artist.ID,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Artist{}, ErrArtistNotFound
+	}
+	if err != nil {
+		return Artist{}, err
+	}
+	if err := claimArtistNames(tx, updated); err != nil {
+		return Artist{}, err
+	}
+	return updated, tx.Commit()
+}
+
+func (r *PostgresArtistRepository) Delete(id string) error {
+	if !validUUID(id) {
+		return ErrArtistNotFound
+	}
+	res, err := r.db.Exec("DELETE FROM artists WHERE id = $1", id)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return ErrArtistHasAlbums
+	}
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrArtistNotFound
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestPostgresArtistRepository tests matching artists by name or alias, keeping names unique and linking albums.
+func TestPostgresArtistRepository(t *testing.T) {
+	db, teardown := setupTestPostgres(t)
+	defer teardown()
+	repo := NewPostgresArtistRepository(db)
+	albums := NewPostgresAlbumRepository(db)
+
+	stevie, err := repo.Create(Artist{Name: "Stevie Wonder", Aliases: Aliases{"Little Stevie Wonder"}, ITunesArtistID: 34584})
+	require.NoError(t, err)
+	require.Equal(t, int64(34584), stevie.ITunesArtistID)
+
+	found, err := repo.GetByName("little stevie wonder")
+	require.NoError(t, err)
+	require.Equal(t, stevie.ID, found.ID)
+	_, err = repo.Create(Artist{Name: "Little Stevie Wonder"})
+	require.True(t, errors.Is(err, ErrDuplicateArtist))
+
+	temptations, err := repo.Create(Artist{Name: "The Temptations"})
+	require.NoError(t, err)
+	require.Equal(t, "Temptations, The", temptations.SortName)
+	temptations.Aliases = Aliases{"Stevie Wonder"}
+	_, err = repo.Update(temptations)
+	require.True(t, errors.Is(err, ErrDuplicateArtist))
+
+	stevie.Aliases = nil
+	_, err = repo.Update(stevie)
+	require.NoError(t, err)
+	_, err = repo.GetByName("Little Stevie Wonder")
+	require.True(t, errors.Is(err, ErrArtistNotFound), "dropped aliases no longer match")
+
+	require.NoError(t, albums.Create(Album{Title: "Innervisions", Artist: "stevie  wonder", Price: money.MustParse("9.99"), Year: 1973, ImageUrl: "x", Genre: "Soul"}))
+	require.NoError(t, albums.Create(Album{Title: "What's Going On", Artist: "Marvin Gaye", Price: money.MustParse("9.99"), Year: 1971, ImageUrl: "x", Genre: "Soul"}))
+	linked, err := LinkAlbumArtists(albums, repo)
+	require.NoError(t, err)
+	require.Equal(t, 2, linked)
+	linked, err = LinkAlbumArtists(albums, repo)
+	require.NoError(t, err)
+	require.Zero(t, linked)
+
+	all, err := albums.GetAll()
+	require.NoError(t, err)
+	for _, album := range all {
+		require.NotEmpty(t, album.ArtistID)
+		if album.Title == "Innervisions" {
+			require.Equal(t, stevie.ID, album.ArtistID)
+			require.Equal(t, "Stevie Wonder", album.Artist)
+		}
+	}
+	require.True(t, errors.Is(repo.Delete(stevie.ID), ErrArtistHasAlbums))
+
+	artists, err := repo.List()
+	require.NoError(t, err)
+	require.Len(t, artists, 3)
+	require.Equal(t, "Marvin Gaye", artists[0].Name)
+
+	require.NoError(t, repo.Delete(temptations.ID))
+	require.True(t, errors.Is(repo.Delete(temptations.ID), ErrArtistNotFound))
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PostgresArtworkRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresArtworkRepository(db *sqlx.DB) *PostgresArtworkRepository {
+	return &PostgresArtworkRepository{db: db}
+}
+
+const artworkColumns = "album_id, storage_key, url, content_type, width, height, bytes, thumbnails, uploaded_by, uploaded_at"
+
+func (r *PostgresArtworkRepository) Save(artwork Artwork) (Artwork, error) {
+	var saved Artwork
+	err := r.db.Get(&saved,
+		`INSERT INTO album_artwork (album_id, storage_key, url, content_type, width, height, bytes, thumbnails, uploaded_by)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		 ON CONFLICT (album_id) DO UPDATE SET storage_key = EXCLUDED.storage_key, url = EXCLUDED.url,
+		 content_type = EXCLUDED.content_type, width = EXCLUDED.width, height = EXCLUDED.height,
+		 bytes = EXCLUDED.bytes, thumbnails = EXCLUDED.thumbnails, uploaded_by = EXCLUDED.uploaded_by,
+		 uploaded_at = now()
+		 RETURNING `+artworkColumns,
+		artwork.AlbumID, artwork.Key, artwork.URL, artwork.ContentType, artwork.Width, artwork.Height,
+		artwork.Bytes, artwork.Thumbnails, artwork.UploadedBy,
+	)
+	return saved, err
+}
+
+func (r *PostgresArtworkRepository) Get(albumID string) (Artwork, error) {
+	if !validAlbumID(albumID) {
+		return Artwork{}, ErrArtworkNotFound
+	}
+	var artwork Artwork
+	err := r.db.Get(&artwork, "SELECT "+artworkColumns+" FROM album_artwork WHERE album_id = $1", albumID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Artwork{}, ErrArtworkNotFound
+	}
+	return artwork, err
+}
+
+func (r *PostgresArtworkRepository) Delete(albumID string) error {
+	if !validAlbumID(albumID) {
+		return ErrArtworkNotFound
+	}
+	res, err := r.db.Exec("DELETE FROM album_artwork WHERE album_id = $1", albumID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrArtworkNotFound
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestPostgresArtworkRepository tests saving, replacing, reading and deleting an album's artwork.
+func TestPostgresArtworkRepository(t *testing.T) {
+	db, teardown := setupTestPostgres(t)
+	defer teardown()
+	albums := NewPostgresAlbumRepository(db)
+	repo := NewPostgresArtworkRepository(db)
+
+	require.NoError(t, albums.Create(Album{Title: "ABC", Artist: "Jackson 5", Price: money.MustParse("9.99"), Year: 1970, Genre: "R&B/Soul"}))
+	all, err := albums.GetAll()
+	require.NoError(t, err)
+	albumID := all[0].ID
+	_, err = repo.Get(albumID)
+	require.ErrorIs(t, err, ErrArtworkNotFound)
+
+	saved, err := repo.Save(Artwork{AlbumID: albumID, Key: "albums/1/a/original.png", URL: "/artwork/albums/1/a/original.png",
+		ContentType: "image/png", Width: 800, Height: 800, Bytes: 1234, UploadedBy: "staff-1",
+		Thumbnails: Thumbnails{{Size: 100, Key: "albums/1/a/100.png", URL: "/artwork/albums/1/a/100.png", ContentType: "image/png", Width: 100, Height: 100}}})
+	require.NoError(t, err)
+	require.False(t, saved.UploadedAt.IsZero())
+
+	_, err = repo.Save(Artwork{AlbumID: albumID, Key: "albums/1/b/original.jpg", URL: "/artwork/albums/1/b/original.jpg",
+		ContentType: "image/jpeg", Width: 600, Height: 600, Bytes: 999, UploadedBy: "staff-2"})
+	require.NoError(t, err)
+	got, err := repo.Get(albumID)
+	require.NoError(t, err)
+	require.Equal(t, "albums/1/b/original.jpg", got.Key, "saving again replaces the artwork")
+	require.Empty(t, got.Thumbnails)
+
+	require.NoError(t, repo.Delete(albumID))
+	require.ErrorIs(t, repo.Delete(albumID), ErrArtworkNotFound)
+}
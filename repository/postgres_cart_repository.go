@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PostgresCartRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresCartRepository(db *sqlx.DB) *PostgresCartRepository {
+	return &PostgresCartRepository{db: db}
+}
+
+const cartColumns = "id, COALESCE(user_id, '') AS user_id, coupon_code, created_at, updated_at"
+
+func (r *PostgresCartRepository) Create(cart Cart) (Cart, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return Cart{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var created Cart
+	if err := tx.Get(&created,
+		"INSERT INTO carts (user_id, coupon_code) VALUES (NULLIF($1, ''), $2) RETURNING "+cartColumns,
+		cart.UserID, cart.CouponCode,
+	); err != nil {
+		return Cart{}, err
+	}
+	created.Items = cart.Items
+	if err := insertCartItems(tx, created); err != nil {
+		return Cart{}, err
+	}
+	return created, tx.Commit()
+}
+
+func (r *PostgresCartRepository) GetByID(id string) (Cart, error) {
+	if !validUUID(id) {
+		return Cart{}, ErrCartNotFound
+	}
+	return r.get("SELECT "+cartColumns+" FROM carts WHERE id = $1", id)
+}
+
+func (r *PostgresCartRepository) GetByUser(userID string) (Cart, error) {
+	return r.get("SELECT "+cartColumns+" FROM carts WHERE user_id = $1", userID)
+}
+
+func (r *PostgresCartRepository) get(query string, arg string) (Cart, error) {
+	var cart Cart
+	err := r.db.Get(&cart, query, arg)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Cart{}, ErrCartNotFound
+	}
+	if err != nil {
+		return Cart{}, err
+	}
+	err = r.db.Select(&cart.Items,
+		"SELECT album_id, release_id, format, quantity FROM cart_items WHERE cart_id = $1 ORDER BY position",
+		cart.ID,
+	)
+	return cart, err
+}
+
+func (r *PostgresCartRepository) Save(cart Cart) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec("UPDATE carts SET user_id = NULLIF($1, ''), coupon_code = $2, updated_at = now() WHERE id = $3", cart.UserID, cart.CouponCode, cart.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrCartNotFound
+	}
+	if _, err := tx.Exec("DELETE FROM cart_items WHERE cart_id = $1", cart.ID); err != nil {
+		return err
+	}
+	if err := insertCartItems(tx, cart); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertCartItems(tx *sqlx.Tx, cart Cart) error {
+	for i, item := range cart.Items {
+		if _, err := tx.Exec(
+			"INSERT INTO cart_items (cart_id, position, album_id, release_id, format, quantity) VALUES ($1, $2, $3, $4, $5, $6)",
+			cart.ID, i, item.AlbumID, item.ReleaseID, item.Format, item.Quantity,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *PostgresCartRepository) Delete(id string) error {
+	if !validUUID(id) {
+		return nil
+	}
+	_, err := r.db.Exec("DELETE FROM carts WHERE id = $1", id)
+	return err
+}
+
+func (r *PostgresCartRepository) DeleteIdleSince(cutoff time.Time) (int64, error) {
+	res, err := r.db.Exec("DELETE FROM carts WHERE updated_at < $1", cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
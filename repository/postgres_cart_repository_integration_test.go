@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestPostgresCartRepository_SaveAndGet tests creating a cart, replacing its items and claiming it for a user.
+func TestPostgresCartRepository_SaveAndGet(t *testing.T) {
+	db, teardown := setupTestPostgres(t)
+	defer teardown()
+	albumID := createTestPostgresAlbum(t, NewPostgresAlbumRepository(db))
+	repo := NewPostgresCartRepository(db)
+
+	cart, err := repo.Create(Cart{Items: []CartItem{{AlbumID: albumID, Format: FormatLP, Quantity: 1}}})
+	require.NoError(t, err)
+	require.NotEmpty(t, cart.ID)
+
+	cart.UserID = "user-1"
+	cart.Items = []CartItem{{AlbumID: albumID, Format: FormatCD, Quantity: 2}, {AlbumID: albumID, Format: FormatLP, Quantity: 1},
+		{AlbumID: albumID, ReleaseID: "6ba7b810-9dad-11d1-80b4-00c04fd430c8", Format: FormatLP, Quantity: 1}}
+	require.NoError(t, repo.Save(cart))
+
+	got, err := repo.GetByUser("user-1")
+	require.NoError(t, err)
+	require.Equal(t, cart.ID, got.ID)
+	require.Len(t, got.Items, 3, "a release is an item of its own")
+	require.Equal(t, FormatCD, got.Items[0].Format)
+	require.Equal(t, 2, got.Items[0].Quantity)
+	require.Equal(t, "6ba7b810-9dad-11d1-80b4-00c04fd430c8", got.Items[2].ReleaseID)
+
+	_, err = repo.GetByID("not-a-uuid")
+	require.True(t, errors.Is(err, ErrCartNotFound))
+}
+
+// TestPostgresCartRepository_DeleteIdleSince tests that only carts idle past the cutoff are removed.
+func TestPostgresCartRepository_DeleteIdleSince(t *testing.T) {
+	db, teardown := setupTestPostgres(t)
+	defer teardown()
+	repo := NewPostgresCartRepository(db)
+
+	idle, err := repo.Create(Cart{})
+	require.NoError(t, err)
+	_, err = db.Exec("UPDATE carts SET updated_at = now() - interval '2 days' WHERE id = $1", idle.ID)
+	require.NoError(t, err)
+	active, err := repo.Create(Cart{})
+	require.NoError(t, err)
+
+	deleted, err := repo.DeleteIdleSince(time.Now().Add(-24 * time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+
+	_, err = repo.GetByID(idle.ID)
+	require.True(t, errors.Is(err, ErrCartNotFound))
+	_, err = repo.GetByID(active.ID)
+	require.NoError(t, err)
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// PostgresCollectionRepository stores a curated collection's albums as a JSON array, so their order
+// is kept, and a smart collection's rules as text.
+type PostgresCollectionRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresCollectionRepository(db *sqlx.DB) *PostgresCollectionRepository {
+	return &PostgresCollectionRepository{db: db}
+}
+
+const collectionColumns = "id, title, description, cover_url, kind, album_ids, rules, created_at, updated_at"
+
+func (r *PostgresCollectionRepository) Create(collection Collection) (Collection, error) {
+	collection.Normalize()
+	var created Collection
+	err := r.db.Get(&created,
+		`INSERT INTO collections (id, title, description, cover_url, kind, album_ids, rules)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING `+collectionColumns,
+		collection.ID, collection.Title, collection.Description, collection.CoverURL, collection.Kind,
+		collection.AlbumIDs, collection.Rules,
+	)
+	if isUniqueViolation(err) {
+		return Collection{}, ErrDuplicateCollection
+	}
+	return created, err
+}
+
+func (r *PostgresCollectionRepository) GetByID(id string) (Collection, error) {
+	var collection Collection
+	err := r.db.Get(&collection, "SELECT "+collectionColumns+" FROM collections WHERE id = $1", id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Collection{}, ErrCollectionNotFound
+	}
+	return collection, err
+}
+
+func (r *PostgresCollectionRepository) List() ([]Collection, error) {
+	var collections []Collection
+	err := r.db.Select(&collections, "SELECT "+collectionColumns+" FROM collections ORDER BY lower(title)")
+	return collections, err
+}
+
+func (r *PostgresCollectionRepository) Update(collection Collection) (Collection, error) {
+	collection.Normalize()
+	var updated Collection
+	err := r.db.Get(&updated,
+		`UPDATE collections SET title = $1, description = $2, cover_url = $3, kind = $4, album_ids = $5,
+		 rules = $6, updated_at = now() WHERE id = $7 RETURNING `+collectionColumns,
+		collection.Title, collection.Description, collection.CoverURL, collection.Kind, collection.AlbumIDs,
+		collection.Rules, collection.ID,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Collection{}, ErrCollectionNotFound
+	}
+	return updated, err
+}
+
+func (r *PostgresCollectionRepository) Delete(id string) error {
+	res, err := r.db.Exec("DELETE FROM collections WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrCollectionNotFound
+	}
+	return nil
+}
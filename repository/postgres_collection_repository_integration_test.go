@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestPostgresCollectionRepository tests storing curated and smart collections, keeping album order and rules.
+func TestPostgresCollectionRepository(t *testing.T) {
+	db, teardown := setupTestPostgres(t)
+	defer teardown()
+	repo := NewPostgresCollectionRepository(db)
+
+	picks, err := repo.Create(Collection{Title: "Staff Picks", Description: "Chosen by the shop", AlbumIDs: AlbumIDs{"3", "1", "2"}})
+	require.NoError(t, err)
+	require.Equal(t, "staff-picks", picks.ID)
+	require.Equal(t, CollectionCurated, picks.Kind)
+	require.Equal(t, AlbumIDs{"3", "1", "2"}, picks.AlbumIDs)
+	_, err = repo.Create(Collection{Title: "Staff picks!"})
+	require.True(t, errors.Is(err, ErrDuplicateCollection))
+
+	rules, err := ParseCollectionRules("genre=Soul AND year<1975")
+	require.NoError(t, err)
+	soul, err := repo.Create(Collection{Title: "Early Soul", Rules: rules})
+	require.NoError(t, err)
+	require.Equal(t, CollectionSmart, soul.Kind)
+
+	found, err := repo.GetByID(soul.ID)
+	require.NoError(t, err)
+	require.Equal(t, rules, found.Rules)
+
+	picks.AlbumIDs = AlbumIDs{"2"}
+	picks.Title = "Shop Favourites"
+	updated, err := repo.Update(picks)
+	require.NoError(t, err)
+	require.Equal(t, "staff-picks", updated.ID)
+	require.Equal(t, AlbumIDs{"2"}, updated.AlbumIDs)
+
+	collections, err := repo.List()
+	require.NoError(t, err)
+	require.Len(t, collections, 2)
+	require.Equal(t, "Early Soul", collections[0].Title)
+
+	require.NoError(t, repo.Delete(picks.ID))
+	_, err = repo.GetByID(picks.ID)
+	require.True(t, errors.Is(err, ErrCollectionNotFound))
+	require.True(t, errors.Is(repo.Delete(picks.ID), ErrCollectionNotFound))
+}
+
+// TestPostgresAlbumRepository_Tags tests finding albums by tag and counting tags.
+func TestPostgresAlbumRepository_Tags(t *testing.T) {
+	db, teardown := setupTestPostgres(t)
+	defer teardown()
+	repo := NewPostgresAlbumRepository(db)
+
+	require.NoError(t, repo.Create(Album{Title: "What's Going On", Artist: "Marvin Gaye", Price: money.MustParse("9.99"), Year: 1971, ImageUrl: "x", Genre: "Soul", Tags: Tags{"staff pick", "protest"}}))
+	require.NoError(t, repo.Create(Album{Title: "Superfly", Artist: "Curtis Mayfield", Price: money.MustParse("8.99"), Year: 1972, ImageUrl: "x", Genre: "Soul", Tags: Tags{"staff pick"}}))
+	require.NoError(t, repo.Create(Album{Title: "Untagged", Artist: "Nobody", Price: money.MustParse("1.00"), Year: 2000, ImageUrl: "x", Genre: "Pop"}))
+
+	albums, err := repo.GetByTag("staff pick")
+	require.NoError(t, err)
+	require.Len(t, albums, 2)
+	albums, err = repo.GetByTag("protest")
+	require.NoError(t, err)
+	require.Len(t, albums, 1)
+	require.Equal(t, Tags{"staff pick", "protest"}, albums[0].Tags)
+
+	counts, err := repo.TagCounts()
+	require.NoError(t, err)
+	require.Equal(t, []TagCount{{Tag: "staff pick", Count: 2}, {Tag: "protest", Count: 1}}, counts)
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PostgresExchangeRateRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresExchangeRateRepository(db *sqlx.DB) *PostgresExchangeRateRepository {
+	return &PostgresExchangeRateRepository{db: db}
+}
+
+func (r *PostgresExchangeRateRepository) List() ([]ExchangeRate, error) {
+	var rates []ExchangeRate
+	err := r.db.Select(&rates, "SELECT currency, rate, round_to, updated_at FROM exchange_rates ORDER BY currency")
+	return rates, err
+}
+
+func (r *PostgresExchangeRateRepository) Replace(rates []ExchangeRate) ([]ExchangeRate, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec("DELETE FROM exchange_rates"); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	for _, rate := range rates {
+		if _, err := tx.Exec(
+			"INSERT INTO exchange_rates (currency, rate, round_to, updated_at) VALUES ($1, $2, $3, $4)",
+			rate.Currency, rate.Rate, rate.RoundTo, now,
+		); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.List()
+}
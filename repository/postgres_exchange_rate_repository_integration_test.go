@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestPostgresExchangeRateRepository_Replace tests that an upload replaces the whole table.
+func TestPostgresExchangeRateRepository_Replace(t *testing.T) {
+	db, teardown := setupTestPostgres(t)
+	defer teardown()
+	repo := NewPostgresExchangeRateRepository(db)
+
+	rates, err := repo.Replace([]ExchangeRate{{Currency: "USD", Rate: 1.27}, {Currency: "EUR", Rate: 1.17, RoundTo: money.MustParse("0.05")}})
+	require.NoError(t, err)
+	require.Len(t, rates, 2)
+	require.Equal(t, "EUR", rates[0].Currency)
+	require.Equal(t, 1.17, rates[0].Rate)
+	require.Equal(t, money.MustParse("0.05"), rates[0].RoundTo)
+
+	rates, err = repo.Replace([]ExchangeRate{{Currency: "JPY", Rate: 190.5}})
+	require.NoError(t, err)
+	require.Len(t, rates, 1, "currencies left out of the upload are removed")
+	require.Equal(t, "JPY", rates[0].Currency)
+
+	rates, err = repo.Replace(nil)
+	require.NoError(t, err)
+	require.Empty(t, rates)
+}
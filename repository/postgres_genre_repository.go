@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// PostgresGenreRepository keeps the key of every name and alias a genre goes by in genre_names,
+// whose primary key stops two genres from claiming the same name.
+type PostgresGenreRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresGenreRepository(db *sqlx.DB) *PostgresGenreRepository {
+	return &PostgresGenreRepository{db: db}
+}
+
+const genreColumns = "id, name, COALESCE(parent_id, '') AS parent_id, aliases, created_at, updated_at"
+
+func (r *PostgresGenreRepository) Create(genre Genre) (Genre, error) {
+	genre.Normalize()
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return Genre{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var created Genre
+	err = tx.Get(&created,
+		"INSERT INTO genres (id, name, parent_id, aliases) VALUES ($1, $2, NULLIF($3, ''), $4) RETURNING "+genreColumns,
+		genre.ID, genre.Name, genre.ParentID, genre.Aliases,
+	)
+	if isUniqueViolation(err) {
+		return Genre{}, ErrDuplicateGenre
+	}
+	if err != nil {
+		return Genre{}, err
+	}
+	if err := claimGenreNames(tx, created); err != nil {
+		return Genre{}, err
+	}
+	return created, tx.Commit()
+}
+
+// claimGenreNames replaces the names recorded for the genre with its current name and aliases.
+func claimGenreNames(tx *sqlx.Tx, genre Genre) error {
+	if _, err := tx.Exec("DELETE FROM genre_names WHERE genre_id = $1", genre.ID); err != nil {
+		return err
+	}
+	for _, key := range genre.nameKeys() {
+		_, err := tx.Exec("INSERT INTO genre_names (name_key, genre_id) VALUES ($1, $2)", key, genre.ID)
+		if isUniqueViolation(err) {
+			return ErrDuplicateGenre
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *PostgresGenreRepository) GetByID(id string) (Genre, error) {
+	var genre Genre
+	err := r.db.Get(&genre, "SELECT "+genreColumns+" FROM genres WHERE id = $1", id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Genre{}, ErrGenreNotFound
+	}
+	return genre, err
+}
+
+func (r *PostgresGenreRepository) List() ([]Genre, error) {
+	var genres []Genre
+	err := r.db.Select(&genres, "SELECT "+genreColumns+" FROM genres ORDER BY lower(name)")
+	return genres, err
+}
+
+func (r *PostgresGenreRepository) Update(genre Genre) (Genre, error) {
+	genre.Normalize()
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return Genre{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var updated Genre
+	err = tx.Get(&updated,
+		`UPDATE genres SET name = $1, parent_id = NULLIF($2, ''), aliases = $3, updated_at = now()
+		 WHERE id = $4 RETURNING `+genreColumns,
+		genre.Name, genre.ParentID, genre.Aliases, genre.ID,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Genre{}, ErrGenreNotFound
+	}
+	if err != nil {
+		return Genre{}, err
+	}
+	if err := claimGenreNames(tx, updated); err != nil {
+		return Genre{}, err
+	}
+	return updated, tx.Commit()
+}
+
+func (r *PostgresGenreRepository) Delete(id string) error {
+	res, err := r.db.Exec("DELETE FROM genres WHERE id = $1", id)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return ErrGenreInUse
+	}
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrGenreNotFound
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestPostgresGenreRepository tests the seeded taxonomy, keeping names unique and refusing to delete genres in use.
+func TestPostgresGenreRepository(t *testing.T) {
+	db, teardown := setupTestPostgres(t)
+	defer teardown()
+	repo := NewPostgresGenreRepository(db)
+
+	genres, err := repo.List()
+	require.NoError(t, err)
+	tree := NewGenreTree(genres)
+	soul, ok := tree.Lookup("R&B/Soul")
+	require.True(t, ok, "the migration seeds the taxonomy")
+	require.True(t, tree.Includes(soul.ID, "Northern Soul"))
+
+	modern, err := repo.Create(Genre{Name: "Modern Soul", ParentID: "northern-soul"})
+	require.NoError(t, err)
+	require.Equal(t, "modern-soul", modern.ID)
+	_, err = repo.Create(Genre{Name: "modern  soul"})
+	require.True(t, errors.Is(err, ErrDuplicateGenre))
+	_, err = repo.Create(Genre{Name: "Crossover", Aliases: Aliases{"Motown"}})
+	require.True(t, errors.Is(err, ErrDuplicateGenre))
+
+	modern.Aliases = Aliases{"Rare Soul"}
+	modern.ParentID = "soul"
+	updated, err := repo.Update(modern)
+	require.NoError(t, err)
+	require.Equal(t, "soul", updated.ParentID)
+	_, err = repo.Update(Genre{ID: "missing", Name: "Missing"})
+	require.True(t, errors.Is(err, ErrGenreNotFound))
+
+	require.True(t, errors.Is(repo.Delete("motown"), ErrGenreInUse), "Northern Soul is still filed under Motown")
+	require.NoError(t, repo.Delete(modern.ID))
+	_, err = repo.GetByID(modern.ID)
+	require.True(t, errors.Is(err, ErrGenreNotFound))
+	_, err = repo.Create(Genre{Name: "Rare Soul"})
+	require.NoError(t, err, "a deleted genre's names are free again")
+}
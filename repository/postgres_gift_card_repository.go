@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+type PostgresGiftCardRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresGiftCardRepository(db *sqlx.DB) *PostgresGiftCardRepository {
+	return &PostgresGiftCardRepository{db: db}
+}
+
+const giftCardColumns = "id, kind, COALESCE(code_hash, '') AS code_hash, last4, COALESCE(user_id, '') AS user_id, initial_balance, balance, created_at, updated_at"
+
+const giftCardEntryColumns = "id, gift_card_id, amount, balance, reason, COALESCE(order_id, '') AS order_id, actor, note, created_at"
+
+func (r *PostgresGiftCardRepository) Create(code string, initialBalance money.Amount, actor, note string) (GiftCard, error) {
+	card, err := r.open(GiftCardVoucher, HashGiftCardCode(code), lastFour(code), "", GiftCardEntry{
+		Amount: initialBalance, Reason: EntryIssued, Actor: actor, Note: note,
+	})
+	if isUniqueViolation(err) {
+		return GiftCard{}, ErrDuplicateGiftCardCode
+	}
+	return card, err
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// open creates a card with the entry's amount as its opening balance, recording the entry.
+func (r *PostgresGiftCardRepository) open(kind GiftCardKind, codeHash, last4, userID string, entry GiftCardEntry) (GiftCard, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return GiftCard{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var card GiftCard
+	if err := tx.Get(&card,
+		`INSERT INTO gift_cards (kind, code_hash, last4, user_id, initial_balance, balance)
+		 VALUES ($1, NULLIF($2, ''), $3, NULLIF($4, ''), $5, $5) RETURNING `+giftCardColumns,
+		kind, codeHash, last4, userID, entry.Amount,
+	); err != nil {
+		return GiftCard{}, err
+	}
+	if err := insertGiftCardEntry(tx, card, entry); err != nil {
+		return GiftCard{}, err
+	}
+	return card, tx.Commit()
+}
+
+func insertGiftCardEntry(tx *sqlx.Tx, card GiftCard, entry GiftCardEntry) error {
+	_, err := tx.Exec(
+		`INSERT INTO gift_card_entries (gift_card_id, amount, balance, reason, order_id, actor, note)
+		 VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7)`,
+		card.ID, entry.Amount, card.Balance, entry.Reason, entry.OrderID, entry.Actor, entry.Note,
+	)
+	return err
+}
+
+func (r *PostgresGiftCardRepository) GetByID(id string) (GiftCard, error) {
+	if !validUUID(id) {
+		return GiftCard{}, ErrGiftCardNotFound
+	}
+	return r.get("SELECT "+giftCardColumns+" FROM gift_cards WHERE id = $1", id)
+}
+
+func (r *PostgresGiftCardRepository) GetByCode(code string) (GiftCard, error) {
+	return r.get("SELECT "+giftCardColumns+" FROM gift_cards WHERE code_hash = $1", HashGiftCardCode(code))
+}
+
+func (r *PostgresGiftCardRepository) GetStoreCredit(userID string) (GiftCard, error) {
+	return r.get("SELECT "+giftCardColumns+" FROM gift_cards WHERE kind = 'store-credit' AND user_id = $1", userID)
+}
+
+func (r *PostgresGiftCardRepository) get(query, arg string) (GiftCard, error) {
+	var card GiftCard
+	err := r.db.Get(&card, query, arg)
+	if errors.Is(err, sql.ErrNoRows) {
+		return GiftCard{}, ErrGiftCardNotFound
+	}
+	return card, err
+}
+
+func (r *PostgresGiftCardRepository) List(kind GiftCardKind) ([]GiftCard, error) {
+	var cards []GiftCard
+	err := r.db.Select(&cards,
+		"SELECT "+giftCardColumns+" FROM gift_cards WHERE $1 = '' OR kind = $1 ORDER BY created_at DESC",
+		string(kind),
+	)
+	return cards, err
+}
+
+func (r *PostgresGiftCardRepository) Apply(id string, entry GiftCardEntry) (GiftCard, error) {
+	if !validUUID(id) {
+		return GiftCard{}, ErrGiftCardNotFound
+	}
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return GiftCard{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var card GiftCard
+	err = tx.Get(&card,
+		`UPDATE gift_cards SET balance = balance + $1, updated_at = now()
+		 WHERE id = $2 AND balance + $1 >= 0 RETURNING `+giftCardColumns,
+		entry.Amount, id,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := r.GetByID(id); err != nil {
+			return GiftCard{}, err
+		}
+		return GiftCard{}, ErrInsufficientBalance
+	}
+	if err != nil {
+		return GiftCard{}, err
+	}
+	if err := insertGiftCardEntry(tx, card, entry); err != nil {
+		return GiftCard{}, err
+	}
+	return card, tx.Commit()
+}
+
+func (r *PostgresGiftCardRepository) AddStoreCredit(userID string, entry GiftCardEntry) (GiftCard, error) {
+	for {
+		existing, err := r.GetStoreCredit(userID)
+		if err == nil {
+			return r.Apply(existing.ID, entry)
+		}
+		if !errors.Is(err, ErrGiftCardNotFound) {
+			return GiftCard{}, err
+		}
+		card, err := r.open(StoreCredit, "", "", userID, entry)
+		if isUniqueViolation(err) {
+			// Another request opened the account first; credit that one instead.
+			continue
+		}
+		return card, err
+	}
+}
+
+func (r *PostgresGiftCardRepository) Entries(id string) ([]GiftCardEntry, error) {
+	if !validUUID(id) {
+		return nil, nil
+	}
+	var entries []GiftCardEntry
+	err := r.db.Select(&entries,
+		"SELECT "+giftCardEntryColumns+" FROM gift_card_entries WHERE gift_card_id = $1 ORDER BY created_at, id",
+		id,
+	)
+	return entries, err
+}
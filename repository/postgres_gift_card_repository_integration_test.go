@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestPostgresGiftCardRepository tests that balances never go below zero and every change is in the ledger.
+func TestPostgresGiftCardRepository(t *testing.T) {
+	db, teardown := setupTestPostgres(t)
+	defer teardown()
+	repo := NewPostgresGiftCardRepository(db)
+
+	card, err := repo.Create("ABCD-2345-EFGH-6789", money.MustParse("25.00"), "staff-1", "Christmas")
+	require.NoError(t, err)
+	require.Equal(t, "6789", card.Last4)
+	_, err = repo.Create("abcd2345efgh6789", money.MustParse("10.00"), "staff-1", "")
+	require.True(t, errors.Is(err, ErrDuplicateGiftCardCode))
+
+	found, err := repo.GetByCode("abcd 2345 efgh 6789")
+	require.NoError(t, err)
+	require.Equal(t, card.ID, found.ID)
+
+	card, err = repo.Apply(card.ID, GiftCardEntry{Amount: -money.MustParse("20.00"), Reason: EntryRedeemed, OrderID: "order-1"})
+	require.NoError(t, err)
+	require.Equal(t, money.MustParse("5.00"), card.Balance)
+	_, err = repo.Apply(card.ID, GiftCardEntry{Amount: -money.MustParse("5.01"), Reason: EntryRedeemed})
+	require.True(t, errors.Is(err, ErrInsufficientBalance))
+
+	entries, err := repo.Entries(card.ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, money.MustParse("25.00"), entries[0].Balance)
+	require.Equal(t, "order-1", entries[1].OrderID)
+
+	credit, err := repo.AddStoreCredit("user-1", GiftCardEntry{Amount: money.MustParse("12.00"), Reason: EntryRefunded})
+	require.NoError(t, err)
+	credit, err = repo.AddStoreCredit("user-1", GiftCardEntry{Amount: money.MustParse("3.00"), Reason: EntryRefunded})
+	require.NoError(t, err)
+	require.Equal(t, money.MustParse("15.00"), credit.Balance)
+	found, err = repo.GetStoreCredit("user-1")
+	require.NoError(t, err)
+	require.Equal(t, credit.ID, found.ID)
+
+	vouchers, err := repo.List(GiftCardVoucher)
+	require.NoError(t, err)
+	require.Len(t, vouchers, 1)
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PostgresInventoryRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresInventoryRepository(db *sqlx.DB) *PostgresInventoryRepository {
+	return &PostgresInventoryRepository{db: db}
+}
+
+const inventoryColumns = "album_id, format, on_hand, reserved, on_hand - reserved AS available"
+
+func (r *PostgresInventoryRepository) GetStock(albumID string) ([]StockLevel, error) {
+	var levels []StockLevel
+	err := r.db.Select(&levels, "SELECT "+inventoryColumns+" FROM inventory WHERE album_id = $1 ORDER BY format", albumID)
+	return levels, err
+}
+
+func (r *PostgresInventoryRepository) GetAllStock() ([]StockLevel, error) {
+	var levels []StockLevel
+	err := r.db.Select(&levels, "SELECT "+inventoryColumns+" FROM inventory ORDER BY album_id, format")
+	return levels, err
+}
+
+// lockStock makes sure a stock row exists for the album and format, then locks it until tx ends.
+func lockStock(tx *sqlx.Tx, albumID string, format Format) (StockLevel, error) {
+	if _, err := tx.Exec(
+		"INSERT INTO inventory (album_id, format) VALUES ($1, $2) ON CONFLICT (album_id, format) DO NOTHING",
+		albumID, format,
+	); err != nil {
+		return StockLevel{}, err
+	}
+	var level StockLevel
+	err := tx.Get(&level, "SELECT "+inventoryColumns+" FROM inventory WHERE album_id = $1 AND format = $2 FOR UPDATE", albumID, format)
+	return level, err
+}
+
+func saveStock(tx *sqlx.Tx, level StockLevel) error {
+	_, err := tx.Exec(
+		"UPDATE inventory SET on_hand = $1, reserved = $2, updated_at = now() WHERE album_id = $3 AND format = $4",
+		level.OnHand, level.Reserved, level.AlbumID, level.Format,
+	)
+	return err
+}
+
+func (r *PostgresInventoryRepository) Adjust(adjustment StockAdjustment) (StockLevel, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return StockLevel{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	level, err := lockStock(tx, adjustment.AlbumID, adjustment.Format)
+	if err != nil {
+		return StockLevel{}, err
+	}
+	level, err = applyAdjustment(level, adjustment.Delta)
+	if err != nil {
+		return level, err
+	}
+	if err := saveStock(tx, level); err != nil {
+		return StockLevel{}, err
+	}
+	if _, err := tx.Exec(
+		"INSERT INTO stock_adjustments (album_id, format, delta, reason, note, actor) VALUES ($1, $2, $3, $4, $5, $6)",
+		adjustment.AlbumID, adjustment.Format, adjustment.Delta, adjustment.Reason, adjustment.Note, adjustment.Actor,
+	); err != nil {
+		return StockLevel{}, err
+	}
+	return level, tx.Commit()
+}
+
+func (r *PostgresInventoryRepository) Reserve(albumID string, format Format, quantity int) (StockLevel, error) {
+	if quantity <= 0 {
+		return StockLevel{}, fmt.Errorf("quantity must be positive")
+	}
+	return r.reserve(albumID, format, quantity)
+}
+
+func (r *PostgresInventoryRepository) Release(albumID string, format Format, quantity int) (StockLevel, error) {
+	if quantity <= 0 {
+		return StockLevel{}, fmt.Errorf("quantity must be positive")
+	}
+	return r.reserve(albumID, format, -quantity)
+}
+
+func (r *PostgresInventoryRepository) Fulfil(albumID string, format Format, quantity int) (StockLevel, error) {
+	if quantity <= 0 {
+		return StockLevel{}, fmt.Errorf("quantity must be positive")
+	}
+	return r.update(albumID, format, func(level StockLevel) (StockLevel, error) {
+		return applyFulfilment(level, quantity)
+	})
+}
+
+func (r *PostgresInventoryRepository) reserve(albumID string, format Format, quantity int) (StockLevel, error) {
+	return r.update(albumID, format, func(level StockLevel) (StockLevel, error) {
+		return applyReservation(level, quantity)
+	})
+}
+
+// update applies change to the stock row in its own transaction, holding the row lock throughout.
+func (r *PostgresInventoryRepository) update(albumID string, format Format, change func(StockLevel) (StockLevel, error)) (StockLevel, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return StockLevel{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	level, err := updateStock(tx, albumID, format, change)
+	if err != nil {
+		return level, err
+	}
+	return level, tx.Commit()
+}
+
+// updateStock locks the stock row within tx, applies change and saves the result.
+func updateStock(tx *sqlx.Tx, albumID string, format Format, change func(StockLevel) (StockLevel, error)) (StockLevel, error) {
+	level, err := lockStock(tx, albumID, format)
+	if err != nil {
+		return StockLevel{}, err
+	}
+	level, err = change(level)
+	if err != nil {
+		return level, err
+	}
+	return level, saveStock(tx, level)
+}
+
+func (r *PostgresInventoryRepository) ListAdjustments(albumID string) ([]StockAdjustment, error) {
+	var adjustments []StockAdjustment
+	err := r.db.Select(&adjustments,
+		"SELECT id, album_id, format, delta, reason, note, actor, created_at FROM stock_adjustments WHERE album_id = $1 ORDER BY created_at DESC, id DESC",
+		albumID,
+	)
+	return adjustments, err
+}
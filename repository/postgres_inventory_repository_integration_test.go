@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// createTestPostgresAlbum inserts an album and returns its generated ID.
+func createTestPostgresAlbum(t *testing.T, repo *PostgresAlbumRepository) string {
+	t.Helper()
+	require.NoError(t, repo.Create(Album{Title: "ABC", Artist: "Jackson 5", Price: money.MustParse("1.00"), Year: 1970, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music211/v4/cb/38/70/cb3870c2-1a9b-9310-e218-9d0f5a5e98f5/06UMGIM05267.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"}))
+	albums, err := repo.GetAll()
+	require.NoError(t, err)
+	require.NotEmpty(t, albums)
+	return albums[len(albums)-1].ID
+}
+
+// TestPostgresInventoryRepository_AdjustAndReserve tests adjusting, reserving and releasing stock.
+func TestPostgresInventoryRepository_AdjustAndReserve(t *testing.T) {
+	db, teardown := setupTestPostgres(t)
+	defer teardown()
+	albumID := createTestPostgresAlbum(t, NewPostgresAlbumRepository(db))
+	repo := NewPostgresInventoryRepository(db)
+
+	level, err := repo.Adjust(StockAdjustment{AlbumID: albumID, Format: FormatLP, Delta: 3, Reason: ReasonReceived, Actor: "staff"})
+	require.NoError(t, err)
+	require.Equal(t, 3, level.OnHand)
+
+	level, err = repo.Reserve(albumID, FormatLP, 2)
+	require.NoError(t, err)
+	require.Equal(t, 2, level.Reserved)
+	require.Equal(t, 1, level.Available)
+
+	_, err = repo.Reserve(albumID, FormatLP, 2)
+	require.True(t, errors.Is(err, ErrInsufficientStock))
+
+	_, err = repo.Adjust(StockAdjustment{AlbumID: albumID, Format: FormatLP, Delta: -2, Reason: ReasonDamaged})
+	require.True(t, errors.Is(err, ErrInsufficientStock))
+
+	level, err = repo.Release(albumID, FormatLP, 2)
+	require.NoError(t, err)
+	require.Equal(t, 0, level.Reserved)
+
+	levels, err := repo.GetStock(albumID)
+	require.NoError(t, err)
+	require.Len(t, levels, 1)
+	require.Equal(t, 3, levels[0].Available)
+
+	adjustments, err := repo.ListAdjustments(albumID)
+	require.NoError(t, err)
+	require.Len(t, adjustments, 1)
+	require.Equal(t, ReasonReceived, adjustments[0].Reason)
+	require.Equal(t, "staff", adjustments[0].Actor)
+}
+
+// TestPostgresInventoryRepository_ConcurrentReserve tests that row locks prevent overselling.
+func TestPostgresInventoryRepository_ConcurrentReserve(t *testing.T) {
+	db, teardown := setupTestPostgres(t)
+	defer teardown()
+	albumID := createTestPostgresAlbum(t, NewPostgresAlbumRepository(db))
+	repo := NewPostgresInventoryRepository(db)
+	_, err := repo.Adjust(StockAdjustment{AlbumID: albumID, Format: FormatCD, Delta: 5, Reason: ReasonReceived})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := repo.Reserve(albumID, FormatCD, 1); err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, 5, succeeded)
+	levels, err := repo.GetStock(albumID)
+	require.NoError(t, err)
+	require.Equal(t, 0, levels[0].Available)
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// PostgresLabelRepository keeps each label's name key in a unique column, so two labels can never
+// go by the same name.
+type PostgresLabelRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresLabelRepository(db *sqlx.DB) *PostgresLabelRepository {
+	return &PostgresLabelRepository{db: db}
+}
+
+const labelColumns = "id, name, created_at, updated_at"
+
+func (r *PostgresLabelRepository) Create(label Label) (Label, error) {
+	label.Normalize()
+	var created Label
+	err := r.db.Get(&created,
+		"INSERT INTO labels (name, name_key) VALUES ($1, $2) RETURNING "+labelColumns,
+		label.Name, LabelNameKey(label.Name),
+	)
+	if isUniqueViolation(err) {
+		return Label{}, ErrDuplicateLabel
+	}
+	return created, err
+}
+
+func (r *PostgresLabelRepository) GetByID(id string) (Label, error) {
+	if !validUUID(id) {
+		return Label{}, ErrLabelNotFound
+	}
+	return r.get("SELECT "+labelColumns+" FROM labels WHERE id = $1", id)
+}
+
+func (r *PostgresLabelRepository) GetByName(name string) (Label, error) {
+	return r.get("SELECT "+labelColumns+" FROM labels WHERE name_key = $1", LabelNameKey(name))
+}
+
+func (r *PostgresLabelRepository) get(query, arg string) (Label, error) {
+	var label Label
+	err := r.db.Get(&label, query, arg)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Label{}, ErrLabelNotFound
+	}
+	return label, err
+}
+
+func (r *PostgresLabelRepository) List() ([]Label, error) {
+	var labels []Label
+	err := r.db.Select(&labels, "SELECT "+labelColumns+" FROM labels ORDER BY lower(name), name")
+	return labels, err
+}
+
+func (r *PostgresLabelRepository) Update(label Label) (Label, error) {
+	if !validUUID(label.ID) {
+		return Label{}, ErrLabelNotFound
+	}
+	label.Normalize()
+	var updated Label
+	err := r.db.Get(&updated,
+		"UPDATE labels SET name = $1, name_key = $2, updated_at = now() WHERE id = $3 RETURNING "+labelColumns,
+		label.Name, LabelNameKey(label.Name), label.ID,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Label{}, ErrLabelNotFound
+	}
+	if isUniqueViolation(err) {
+		return Label{}, ErrDuplicateLabel
+	}
+	return updated, err
+}
+
+func (r *PostgresLabelRepository) Delete(id string) error {
+	if !validUUID(id) {
+		return ErrLabelNotFound
+	}
+	res, err := r.db.Exec("DELETE FROM labels WHERE id = $1", id)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return ErrLabelHasAlbums
+	}
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrLabelNotFound
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestPostgresLabelRepository tests keeping label names unique and refusing to delete labels with albums.
+func TestPostgresLabelRepository(t *testing.T) {
+	db, teardown := setupTestPostgres(t)
+	defer teardown()
+	repo := NewPostgresLabelRepository(db)
+	albums := NewPostgresAlbumRepository(db)
+
+	tamla, err := repo.GetByName("Tamla Records")
+	require.NoError(t, err, "the migration seeds the Motown labels")
+	require.Equal(t, "Tamla", tamla.Name)
+
+	rareEarth, err := repo.Create(Label{Name: "Rare  Earth"})
+	require.NoError(t, err)
+	require.Equal(t, "Rare Earth", rareEarth.Name)
+	_, err = repo.Create(Label{Name: "Rare Earth Records"})
+	require.True(t, errors.Is(err, ErrDuplicateLabel))
+
+	rareEarth.Name = "Gordy"
+	_, err = repo.Update(rareEarth)
+	require.True(t, errors.Is(err, ErrDuplicateLabel))
+
+	labels, err := repo.List()
+	require.NoError(t, err)
+	require.Len(t, labels, 5)
+	require.Equal(t, "Gordy", labels[0].Name)
+
+	require.NoError(t, albums.Create(Album{Title: "What's Going On", Artist: "Marvin Gaye", LabelID: tamla.ID, Label: tamla.Name, CatalogueNumber: "TS 310", Price: money.MustParse("9.99"), Year: 1971, ImageUrl: "x", Genre: "Soul"}))
+	require.True(t, errors.Is(repo.Delete(tamla.ID), ErrLabelHasAlbums))
+	require.NoError(t, repo.Delete(rareEarth.ID))
+	_, err = repo.GetByID(rareEarth.ID)
+	require.True(t, errors.Is(err, ErrLabelNotFound))
+}
+
+// TestPostgresAlbumRepository_Identifiers tests looking albums up by barcode and catalogue number, and keeping both unique.
+func TestPostgresAlbumRepository_Identifiers(t *testing.T) {
+	db, teardown := setupTestPostgres(t)
+	defer teardown()
+	labels := NewPostgresLabelRepository(db)
+	repo := NewPostgresAlbumRepository(db)
+	tamla, err := labels.GetByName("Tamla")
+	require.NoError(t, err)
+	gordy, err := labels.GetByName("Gordy")
+	require.NoError(t, err)
+
+	album := Album{Title: "What's Going On", Artist: "Marvin Gaye", LabelID: tamla.ID, Label: tamla.Name, CatalogueNumber: "TS 310",
+		Barcode: "0036000291452", Price: money.MustParse("9.99"), Year: 1971, ImageUrl: "x", Genre: "Soul"}
+	require.NoError(t, repo.Create(album))
+
+	found, err := repo.GetByBarcode("0036000291452")
+	require.NoError(t, err)
+	require.Equal(t, "What's Going On", found.Title)
+	require.Equal(t, tamla.ID, found.LabelID)
+	found, err = repo.GetByCatalogueNumber(tamla.ID, "TS 310")
+	require.NoError(t, err)
+	require.Equal(t, "TS 310", found.CatalogueNumber)
+	_, err = repo.GetByCatalogueNumber(gordy.ID, "TS 310")
+	require.True(t, errors.Is(err, ErrAlbumNotFound))
+
+	other := album
+	other.Title, other.CatalogueNumber = "Let's Get It On", "T 329"
+	require.True(t, errors.Is(repo.Create(other), ErrDuplicateBarcode))
+	other.Barcode, other.CatalogueNumber = "", "TS 310"
+	require.True(t, errors.Is(repo.Create(other), ErrDuplicateCatalogueNumber))
+	other.LabelID, other.Label = gordy.ID, gordy.Name
+	require.NoError(t, repo.Create(other), "catalogue numbers are only unique within a label")
+
+	plain := Album{Title: "Diana", Artist: "Diana Ross", Price: money.MustParse("9.99"), Year: 1980, ImageUrl: "x", Genre: "Soul"}
+	require.NoError(t, repo.Create(plain))
+	require.NoError(t, repo.Create(plain), "albums without a barcode or catalogue number do not clash")
+}
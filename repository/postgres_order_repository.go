@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// PostgresOrderRepository reserves and releases stock in the same transaction as the order change,
+// so an order can never exist without its reservation or vice versa.
+type PostgresOrderRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresOrderRepository(db *sqlx.DB) *PostgresOrderRepository {
+	return &PostgresOrderRepository{db: db}
+}
+
+const orderColumns = "id, user_id, status, coupon_code, discount, country, region, tax, tax_included, total, created_at, updated_at"
+
+func (r *PostgresOrderRepository) Create(order Order) (Order, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return Order{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, line := range sortedLines(order.Lines) {
+		if _, err := updateStock(tx, line.AlbumID, line.Format, func(level StockLevel) (StockLevel, error) {
+			return applyReservation(level, line.Quantity)
+		}); err != nil {
+			return Order{}, err
+		}
+	}
+
+	order.Status = OrderPending
+	if err := tx.Get(&order,
+		"INSERT INTO orders (user_id, status, coupon_code, discount, country, region, tax, tax_included, total) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING "+orderColumns,
+		order.UserID, order.Status, order.CouponCode, order.Discount, order.Country, order.Region, order.Tax, order.TaxIncluded, order.Total,
+	); err != nil {
+		return Order{}, err
+	}
+	for i, line := range order.Lines {
+		if _, err := tx.Exec(
+			`INSERT INTO order_lines (order_id, line_no, album_id, release_id, format, title, artist, unit_price, quantity, discount, promotion, line_total, tax_category, tax_name, tax_rate, tax)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`,
+			order.ID, i, line.AlbumID, line.ReleaseID, line.Format, line.Title, line.Artist, line.UnitPrice, line.Quantity, line.Discount, line.Promotion, line.LineTotal,
+			line.TaxCategory, line.TaxName, line.TaxRate, line.Tax,
+		); err != nil {
+			return Order{}, err
+		}
+	}
+	if err := insertOrderEvent(tx, order.ID, OrderEvent{Status: OrderPending, Actor: order.UserID}); err != nil {
+		return Order{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Order{}, err
+	}
+	return r.GetByID(order.ID)
+}
+
+func insertOrderEvent(tx *sqlx.Tx, orderID string, event OrderEvent) error {
+	_, err := tx.Exec(
+		"INSERT INTO order_events (order_id, status, actor, note) VALUES ($1, $2, $3, $4)",
+		orderID, event.Status, event.Actor, event.Note,
+	)
+	return err
+}
+
+func (r *PostgresOrderRepository) GetByID(id string) (Order, error) {
+	if !validUUID(id) {
+		return Order{}, ErrOrderNotFound
+	}
+	var order Order
+	err := r.db.Get(&order, "SELECT "+orderColumns+" FROM orders WHERE id = $1", id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Order{}, ErrOrderNotFound
+	}
+	if err != nil {
+		return Order{}, err
+	}
+	if err := r.loadDetails(&order); err != nil {
+		return Order{}, err
+	}
+	return order, nil
+}
+
+func (r *PostgresOrderRepository) loadDetails(order *Order) error {
+	if err := r.db.Select(&order.Lines,
+		"SELECT album_id, release_id, format, title, artist, unit_price, quantity, discount, promotion, line_total, tax_category, tax_name, tax_rate, tax FROM order_lines WHERE order_id = $1 ORDER BY line_no",
+		order.ID,
+	); err != nil {
+		return err
+	}
+	return r.db.Select(&order.History,
+		"SELECT status, actor, note, created_at FROM order_events WHERE order_id = $1 ORDER BY id",
+		order.ID,
+	)
+}
+
+func (r *PostgresOrderRepository) ListByUser(userID string) ([]Order, error) {
+	return r.list("SELECT "+orderColumns+" FROM orders WHERE user_id = $1 ORDER BY created_at DESC", userID)
+}
+
+func (r *PostgresOrderRepository) List(status OrderStatus) ([]Order, error) {
+	return r.list("SELECT "+orderColumns+" FROM orders WHERE $1 = '' OR status = $1 ORDER BY created_at DESC", string(status))
+}
+
+func (r *PostgresOrderRepository) list(query string, arg string) ([]Order, error) {
+	var orders []Order
+	if err := r.db.Select(&orders, query, arg); err != nil {
+		return nil, err
+	}
+	for i := range orders {
+		if err := r.loadDetails(&orders[i]); err != nil {
+			return nil, err
+		}
+	}
+	return orders, nil
+}
+
+func (r *PostgresOrderRepository) UpdateStatus(id string, status OrderStatus, actor, note string) (Order, error) {
+	if !validUUID(id) {
+		return Order{}, ErrOrderNotFound
+	}
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return Order{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var current Order
+	err = tx.Get(&current, "SELECT "+orderColumns+" FROM orders WHERE id = $1 FOR UPDATE", id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Order{}, ErrOrderNotFound
+	}
+	if err != nil {
+		return Order{}, err
+	}
+	if !current.Status.CanTransitionTo(status) {
+		return Order{}, ErrInvalidTransition
+	}
+
+	var lines []OrderLine
+	if err := tx.Select(&lines, "SELECT album_id, format, quantity FROM order_lines WHERE order_id = $1", current.ID); err != nil {
+		return Order{}, err
+	}
+	if change := stockChangeFor(status); change != nil {
+		for _, line := range sortedLines(lines) {
+			if _, err := updateStock(tx, line.AlbumID, line.Format, func(level StockLevel) (StockLevel, error) {
+				return change(level, line.Quantity)
+			}); err != nil {
+				return Order{}, err
+			}
+		}
+	}
+
+	if _, err := tx.Exec("UPDATE orders SET status = $1, updated_at = now() WHERE id = $2", status, current.ID); err != nil {
+		return Order{}, err
+	}
+	if err := insertOrderEvent(tx, current.ID, OrderEvent{Status: status, Actor: actor, Note: note}); err != nil {
+		return Order{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Order{}, err
+	}
+	return r.GetByID(current.ID)
+}
+
+// stockChangeFor returns how reserved stock changes when an order moves to status, or nil if it does not.
+func stockChangeFor(status OrderStatus) func(StockLevel, int) (StockLevel, error) {
+	switch status {
+	case OrderCancelled:
+		return func(level StockLevel, quantity int) (StockLevel, error) {
+			return applyReservation(level, -quantity)
+		}
+	case OrderShipped:
+		return applyFulfilment
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestPostgresOrderRepository_Lifecycle tests that orders reserve, fulfil and release stock as they change status.
+func TestPostgresOrderRepository_Lifecycle(t *testing.T) {
+	db, teardown := setupTestPostgres(t)
+	defer teardown()
+	albumID := createTestPostgresAlbum(t, NewPostgresAlbumRepository(db))
+	inventory := NewPostgresInventoryRepository(db)
+	_, err := inventory.Adjust(StockAdjustment{AlbumID: albumID, Format: FormatLP, Delta: 3, Reason: ReasonReceived})
+	require.NoError(t, err)
+	repo := NewPostgresOrderRepository(db)
+
+	order, err := repo.Create(Order{UserID: "user-1", Country: "GB", Tax: money.MustParse("0.40"), Total: money.MustParse("2.40"), Lines: []OrderLine{
+		{AlbumID: albumID, ReleaseID: "6ba7b810-9dad-11d1-80b4-00c04fd430c8", Format: FormatLP, Title: "ABC", Artist: "Jackson 5", UnitPrice: money.MustParse("1.00"), Quantity: 2, LineTotal: money.MustParse("2.00"),
+			TaxCategory: "standard", TaxName: "VAT", TaxRate: 20, Tax: money.MustParse("0.40")},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, OrderPending, order.Status)
+	require.Equal(t, "GB", order.Country)
+	require.Equal(t, money.MustParse("0.40"), order.Tax)
+	require.Len(t, order.Lines, 1)
+	require.Equal(t, "VAT", order.Lines[0].TaxName)
+	require.Equal(t, "6ba7b810-9dad-11d1-80b4-00c04fd430c8", order.Lines[0].ReleaseID)
+	require.Equal(t, 20.0, order.Lines[0].TaxRate)
+	require.Len(t, order.History, 1)
+
+	levels, err := inventory.GetStock(albumID)
+	require.NoError(t, err)
+	require.Equal(t, 2, levels[0].Reserved)
+
+	_, err = repo.Create(Order{UserID: "user-2", Lines: []OrderLine{{AlbumID: albumID, Format: FormatLP, Quantity: 2}}})
+	require.True(t, errors.Is(err, ErrInsufficientStock))
+
+	_, err = repo.UpdateStatus(order.ID, OrderShipped, "staff", "")
+	require.True(t, errors.Is(err, ErrInvalidTransition))
+
+	_, err = repo.UpdateStatus(order.ID, OrderPaid, "staff", "")
+	require.NoError(t, err)
+	shipped, err := repo.UpdateStatus(order.ID, OrderShipped, "staff", "tracking 123")
+	require.NoError(t, err)
+	require.Equal(t, OrderShipped, shipped.Status)
+	require.Len(t, shipped.History, 3)
+
+	levels, err = inventory.GetStock(albumID)
+	require.NoError(t, err)
+	require.Equal(t, 1, levels[0].OnHand)
+	require.Equal(t, 0, levels[0].Reserved)
+
+	orders, err := repo.ListByUser("user-1")
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	orders, err = repo.List(OrderPaid)
+	require.NoError(t, err)
+	require.Empty(t, orders)
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type PostgresPaymentRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresPaymentRepository(db *sqlx.DB) *PostgresPaymentRepository {
+	return &PostgresPaymentRepository{db: db}
+}
+
+const paymentColumns = "id, order_id, idempotency_key, provider, provider_ref, amount, refunded_amount, status, failure_reason, created_at, updated_at"
+
+func (r *PostgresPaymentRepository) Create(intent PaymentIntent) (PaymentIntent, error) {
+	var created PaymentIntent
+	err := r.db.Get(&created,
+		`INSERT INTO payment_intents (order_id, idempotency_key, provider, provider_ref, amount, status, failure_reason)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING `+paymentColumns,
+		intent.OrderID, intent.IdempotencyKey, intent.Provider, intent.ProviderRef, intent.Amount, intent.Status, intent.FailureReason,
+	)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		if pqErr.Constraint == "payment_intents_in_flight_idx" {
+			return PaymentIntent{}, ErrPaymentInProgress
+		}
+		return PaymentIntent{}, ErrDuplicateIdempotencyKey
+	}
+	return created, err
+}
+
+func (r *PostgresPaymentRepository) GetByID(id string) (PaymentIntent, error) {
+	if !validUUID(id) {
+		return PaymentIntent{}, ErrPaymentNotFound
+	}
+	return r.get("SELECT "+paymentColumns+" FROM payment_intents WHERE id = $1", id)
+}
+
+func (r *PostgresPaymentRepository) GetByIdempotencyKey(key string) (PaymentIntent, error) {
+	return r.get("SELECT "+paymentColumns+" FROM payment_intents WHERE idempotency_key = $1", key)
+}
+
+func (r *PostgresPaymentRepository) GetByProviderRef(provider, ref string) (PaymentIntent, error) {
+	return r.get("SELECT "+paymentColumns+" FROM payment_intents WHERE provider = $1 AND provider_ref = $2", provider, ref)
+}
+
+func (r *PostgresPaymentRepository) get(query string, args ...interface{}) (PaymentIntent, error) {
+	var intent PaymentIntent
+	err := r.db.Get(&intent, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return PaymentIntent{}, ErrPaymentNotFound
+	}
+	return intent, err
+}
+
+func (r *PostgresPaymentRepository) ListByOrder(orderID string) ([]PaymentIntent, error) {
+	if !validUUID(orderID) {
+		return nil, nil
+	}
+	var intents []PaymentIntent
+	err := r.db.Select(&intents, "SELECT "+paymentColumns+" FROM payment_intents WHERE order_id = $1 ORDER BY created_at", orderID)
+	return intents, err
+}
+
+func (r *PostgresPaymentRepository) Update(intent PaymentIntent) (PaymentIntent, error) {
+	if !validUUID(intent.ID) {
+		return PaymentIntent{}, ErrPaymentNotFound
+	}
+	var updated PaymentIntent
+	err := r.db.Get(&updated,
+		`UPDATE payment_intents
+		 SET provider_ref = $1, amount = $2, refunded_amount = $3, status = $4, failure_reason = $5, updated_at = now()
+		 WHERE id = $6 RETURNING `+paymentColumns,
+		intent.ProviderRef, intent.Amount, intent.RefundedAmount, intent.Status, intent.FailureReason, intent.ID,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return PaymentIntent{}, ErrPaymentNotFound
+	}
+	return updated, err
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestPostgresPaymentRepository_Idempotency tests that idempotency keys are unique, that an order has one payment
+// in flight at a time, and that intents can be found by each key.
+func TestPostgresPaymentRepository_Idempotency(t *testing.T) {
+	db, teardown := setupTestPostgres(t)
+	defer teardown()
+	albumID := createTestPostgresAlbum(t, NewPostgresAlbumRepository(db))
+	_, err := NewPostgresInventoryRepository(db).Adjust(StockAdjustment{AlbumID: albumID, Format: FormatLP, Delta: 1, Reason: ReasonReceived})
+	require.NoError(t, err)
+	order, err := NewPostgresOrderRepository(db).Create(Order{UserID: "user-1", Total: money.MustParse("9.99"), Lines: []OrderLine{
+		{AlbumID: albumID, Format: FormatLP, Title: "ABC", Artist: "Jackson 5", UnitPrice: money.MustParse("9.99"), Quantity: 1, LineTotal: money.MustParse("9.99")},
+	}})
+	require.NoError(t, err)
+	repo := NewPostgresPaymentRepository(db)
+
+	intent, err := repo.Create(PaymentIntent{OrderID: order.ID, IdempotencyKey: "user-1:k", Provider: "fake", Amount: money.MustParse("9.99"), Status: PaymentPending})
+	require.NoError(t, err)
+	require.NotEmpty(t, intent.ID)
+
+	_, err = repo.Create(PaymentIntent{OrderID: order.ID, IdempotencyKey: "user-1:other", Provider: "fake", Amount: money.MustParse("9.99"), Status: PaymentPending})
+	require.True(t, errors.Is(err, ErrPaymentInProgress), "only one payment per order can be in flight")
+
+	intent.ProviderRef = "fake_123"
+	intent.Status = PaymentCaptured
+	_, err = repo.Update(intent)
+	require.NoError(t, err)
+
+	_, err = repo.Create(PaymentIntent{OrderID: order.ID, IdempotencyKey: "user-1:k", Provider: "fake", Amount: money.MustParse("9.99"), Status: PaymentPending})
+	require.True(t, errors.Is(err, ErrDuplicateIdempotencyKey))
+
+	found, err := repo.GetByProviderRef("fake", "fake_123")
+	require.NoError(t, err)
+	require.Equal(t, intent.ID, found.ID)
+	require.Equal(t, PaymentCaptured, found.Status)
+
+	found, err = repo.GetByIdempotencyKey("user-1:k")
+	require.NoError(t, err)
+	require.Equal(t, intent.ID, found.ID)
+
+	intents, err := repo.ListByOrder(order.ID)
+	require.NoError(t, err)
+	require.Len(t, intents, 1)
+
+	_, err = repo.GetByID("not-a-uuid")
+	require.True(t, errors.Is(err, ErrPaymentNotFound))
+}
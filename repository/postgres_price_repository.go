@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PostgresPriceRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresPriceRepository(db *sqlx.DB) *PostgresPriceRepository {
+	return &PostgresPriceRepository{db: db}
+}
+
+const (
+	priceChangeColumns    = "id, album_id, old_price, new_price, source, actor, note, changed_at"
+	scheduledPriceColumns = "id, album_id, price, effective_at, status, actor, note, created_at, updated_at"
+)
+
+func (r *PostgresPriceRepository) RecordChange(change PriceChange) (PriceChange, error) {
+	var recorded PriceChange
+	err := r.db.Get(&recorded,
+		`INSERT INTO price_history (album_id, old_price, new_price, source, actor, note)
+		 VALUES ($1, $2, $3, $4, $5, $6) RETURNING `+priceChangeColumns,
+		change.AlbumID, change.OldPrice, change.NewPrice, change.Source, change.Actor, change.Note,
+	)
+	return recorded, err
+}
+
+func (r *PostgresPriceRepository) ListChanges(albumID string) ([]PriceChange, error) {
+	if !validAlbumID(albumID) {
+		return nil, nil
+	}
+	var changes []PriceChange
+	err := r.db.Select(&changes,
+		"SELECT "+priceChangeColumns+" FROM price_history WHERE album_id = $1 ORDER BY changed_at DESC, id DESC",
+		albumID,
+	)
+	return changes, err
+}
+
+func (r *PostgresPriceRepository) Schedule(scheduled ScheduledPrice) (ScheduledPrice, error) {
+	var created ScheduledPrice
+	err := r.db.Get(&created,
+		`INSERT INTO scheduled_prices (album_id, price, effective_at, status, actor, note)
+		 VALUES ($1, $2, $3, $4, $5, $6) RETURNING `+scheduledPriceColumns,
+		scheduled.AlbumID, scheduled.Price, scheduled.EffectiveAt, SchedulePending, scheduled.Actor, scheduled.Note,
+	)
+	return created, err
+}
+
+func (r *PostgresPriceRepository) GetScheduled(id string) (ScheduledPrice, error) {
+	if !validUUID(id) {
+		return ScheduledPrice{}, ErrScheduledPriceNotFound
+	}
+	var scheduled ScheduledPrice
+	err := r.db.Get(&scheduled, "SELECT "+scheduledPriceColumns+" FROM scheduled_prices WHERE id = $1", id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ScheduledPrice{}, ErrScheduledPriceNotFound
+	}
+	return scheduled, err
+}
+
+func (r *PostgresPriceRepository) ListScheduled(albumID string) ([]ScheduledPrice, error) {
+	if !validAlbumID(albumID) {
+		return nil, nil
+	}
+	var scheduled []ScheduledPrice
+	err := r.db.Select(&scheduled,
+		"SELECT "+scheduledPriceColumns+" FROM scheduled_prices WHERE album_id = $1 ORDER BY effective_at, created_at",
+		albumID,
+	)
+	return scheduled, err
+}
+
+func (r *PostgresPriceRepository) Due(now time.Time) ([]ScheduledPrice, error) {
+	var due []ScheduledPrice
+	err := r.db.Select(&due,
+		"SELECT "+scheduledPriceColumns+" FROM scheduled_prices WHERE status = $1 AND effective_at <= $2 ORDER BY effective_at, created_at",
+		SchedulePending, now,
+	)
+	return due, err
+}
+
+func (r *PostgresPriceRepository) SetScheduleStatus(id string, from, to ScheduleStatus) error {
+	if !validUUID(id) {
+		return ErrScheduledPriceNotFound
+	}
+	res, err := r.db.Exec(
+		"UPDATE scheduled_prices SET status = $1, updated_at = now() WHERE id = $2 AND status = $3",
+		to, id, from,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		if _, err := r.GetScheduled(id); err != nil {
+			return err
+		}
+		return ErrScheduleStatusChanged
+	}
+	return nil
+}
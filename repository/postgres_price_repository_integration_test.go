@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestPostgresPriceRepository_HistoryAndSchedule tests recording changes and claiming scheduled changes exactly once.
+func TestPostgresPriceRepository_HistoryAndSchedule(t *testing.T) {
+	db, teardown := setupTestPostgres(t)
+	defer teardown()
+	albumID := createTestPostgresAlbum(t, NewPostgresAlbumRepository(db))
+	repo := NewPostgresPriceRepository(db)
+
+	_, err := repo.RecordChange(PriceChange{AlbumID: albumID, OldPrice: money.MustParse("10.00"), NewPrice: money.MustParse("12.00"), Source: PriceSourceManual, Actor: "staff-1"})
+	require.NoError(t, err)
+	_, err = repo.RecordChange(PriceChange{AlbumID: albumID, OldPrice: money.MustParse("12.00"), NewPrice: money.MustParse("9.00"), Source: PriceSourceScheduled})
+	require.NoError(t, err)
+	changes, err := repo.ListChanges(albumID)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Equal(t, money.MustParse("9.00"), changes[0].NewPrice, "newest first")
+
+	due, err := repo.Schedule(ScheduledPrice{AlbumID: albumID, Price: money.MustParse("8.00"), EffectiveAt: time.Now().Add(-time.Minute), Actor: "staff-1"})
+	require.NoError(t, err)
+	_, err = repo.Schedule(ScheduledPrice{AlbumID: albumID, Price: money.MustParse("7.00"), EffectiveAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+
+	dueNow, err := repo.Due(time.Now())
+	require.NoError(t, err)
+	require.Len(t, dueNow, 1)
+	require.Equal(t, due.ID, dueNow[0].ID)
+
+	require.NoError(t, repo.SetScheduleStatus(due.ID, SchedulePending, ScheduleApplied))
+	require.True(t, errors.Is(repo.SetScheduleStatus(due.ID, SchedulePending, ScheduleApplied), ErrScheduleStatusChanged))
+	require.True(t, errors.Is(repo.SetScheduleStatus("not-a-uuid", SchedulePending, ScheduleApplied), ErrScheduledPriceNotFound))
+
+	scheduled, err := repo.ListScheduled(albumID)
+	require.NoError(t, err)
+	require.Len(t, scheduled, 2)
+	require.Equal(t, ScheduleApplied, scheduled[0].Status)
+}
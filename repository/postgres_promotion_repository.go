@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type PostgresPromotionRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresPromotionRepository(db *sqlx.DB) *PostgresPromotionRepository {
+	return &PostgresPromotionRepository{db: db}
+}
+
+const promotionColumns = "id, name, COALESCE(code, '') AS code, kind, value, scope, starts_at, ends_at, usage_limit, usage_count, active, created_at"
+
+func (r *PostgresPromotionRepository) Create(promotion Promotion) (Promotion, error) {
+	var created Promotion
+	err := r.db.Get(&created,
+		`INSERT INTO promotions (name, code, kind, value, scope, starts_at, ends_at, usage_limit, active)
+		 VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7, $8, $9) RETURNING `+promotionColumns,
+		promotion.Name, NormalizeCode(promotion.Code), promotion.Kind, promotion.Value, promotion.Scope,
+		promotion.StartsAt, promotion.EndsAt, promotion.UsageLimit, promotion.Active,
+	)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return Promotion{}, ErrDuplicateCode
+	}
+	return created, err
+}
+
+func (r *PostgresPromotionRepository) GetByID(id string) (Promotion, error) {
+	if !validUUID(id) {
+		return Promotion{}, ErrPromotionNotFound
+	}
+	return r.get("SELECT "+promotionColumns+" FROM promotions WHERE id = $1", id)
+}
+
+func (r *PostgresPromotionRepository) GetByCode(code string) (Promotion, error) {
+	return r.get("SELECT "+promotionColumns+" FROM promotions WHERE code = $1", NormalizeCode(code))
+}
+
+func (r *PostgresPromotionRepository) get(query, arg string) (Promotion, error) {
+	var promotion Promotion
+	err := r.db.Get(&promotion, query, arg)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Promotion{}, ErrPromotionNotFound
+	}
+	return promotion, err
+}
+
+func (r *PostgresPromotionRepository) List() ([]Promotion, error) {
+	var promotions []Promotion
+	err := r.db.Select(&promotions, "SELECT "+promotionColumns+" FROM promotions ORDER BY created_at")
+	return promotions, err
+}
+
+func (r *PostgresPromotionRepository) Update(promotion Promotion) (Promotion, error) {
+	if !validUUID(promotion.ID) {
+		return Promotion{}, ErrPromotionNotFound
+	}
+	var updated Promotion
+	err := r.db.Get(&updated,
+		`UPDATE promotions SET name = $1, kind = $2, value = $3, scope = $4, starts_at = $5, ends_at = $6, usage_limit = $7, active = $8
+		 WHERE id = $9 RETURNING `+promotionColumns,
+		promotion.Name, promotion.Kind, promotion.Value, promotion.Scope,
+		promotion.StartsAt, promotion.EndsAt, promotion.UsageLimit, promotion.Active, promotion.ID,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Promotion{}, ErrPromotionNotFound
+	}
+	return updated, err
+}
+
+func (r *PostgresPromotionRepository) Delete(id string) error {
+	if !validUUID(id) {
+		return ErrPromotionNotFound
+	}
+	res, err := r.db.Exec("DELETE FROM promotions WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrPromotionNotFound
+	}
+	return nil
+}
+
+// Redeem increments the usage count only while it is below the limit, so concurrent checkouts
+// can never push a promotion past its limit.
+func (r *PostgresPromotionRepository) Redeem(id string) error {
+	if !validUUID(id) {
+		return ErrPromotionNotFound
+	}
+	res, err := r.db.Exec(
+		"UPDATE promotions SET usage_count = usage_count + 1 WHERE id = $1 AND (usage_limit = 0 OR usage_count < usage_limit)",
+		id,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		if _, err := r.GetByID(id); err != nil {
+			return err
+		}
+		return ErrPromotionExhausted
+	}
+	return nil
+}
+
+func (r *PostgresPromotionRepository) Unredeem(id string) error {
+	if !validUUID(id) {
+		return nil
+	}
+	_, err := r.db.Exec("UPDATE promotions SET usage_count = GREATEST(usage_count - 1, 0) WHERE id = $1", id)
+	return err
+}
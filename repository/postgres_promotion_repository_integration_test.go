@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestPostgresPromotionRepository_CodesAndLimits tests unique codes and that redemptions stop at the usage limit.
+func TestPostgresPromotionRepository_CodesAndLimits(t *testing.T) {
+	db, teardown := setupTestPostgres(t)
+	defer teardown()
+	repo := NewPostgresPromotionRepository(db)
+
+	created, err := repo.Create(Promotion{
+		Name: "Motown weekend", Code: "motown20", Kind: DiscountPercentage, Value: money.MustParse("20"),
+		Scope: PromotionScope{Genres: []string{"Motown"}}, UsageLimit: 2, Active: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "MOTOWN20", created.Code)
+	require.Equal(t, []string{"Motown"}, created.Scope.Genres)
+
+	_, err = repo.Create(Promotion{Name: "Copy", Code: "MOTOWN20", Kind: DiscountBOGO, Active: true})
+	require.True(t, errors.Is(err, ErrDuplicateCode))
+	_, err = repo.Create(Promotion{Name: "Automatic", Kind: DiscountBOGO, Active: true})
+	require.NoError(t, err, "promotions without a code do not clash")
+
+	found, err := repo.GetByCode(" motown20 ")
+	require.NoError(t, err)
+	require.Equal(t, created.ID, found.ID)
+	require.Equal(t, money.MustParse("20"), found.Value)
+
+	require.NoError(t, repo.Redeem(created.ID))
+	require.NoError(t, repo.Redeem(created.ID))
+	require.True(t, errors.Is(repo.Redeem(created.ID), ErrPromotionExhausted))
+	require.NoError(t, repo.Unredeem(created.ID))
+	require.NoError(t, repo.Redeem(created.ID))
+
+	created.Active = false
+	updated, err := repo.Update(created)
+	require.NoError(t, err)
+	require.False(t, updated.Active)
+	require.Equal(t, 2, updated.UsageCount)
+
+	promotions, err := repo.List()
+	require.NoError(t, err)
+	require.Len(t, promotions, 2)
+
+	require.NoError(t, repo.Delete(created.ID))
+	require.True(t, errors.Is(repo.Delete(created.ID), ErrPromotionNotFound))
+}
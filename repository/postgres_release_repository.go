@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PostgresReleaseRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresReleaseRepository(db *sqlx.DB) *PostgresReleaseRepository {
+	return &PostgresReleaseRepository{db: db}
+}
+
+const releaseColumns = "id, album_id, format, edition, year, COALESCE(label_id::text, '') AS label_id, label, catalogue_number, " +
+	"COALESCE(barcode, '') AS barcode, price, currency, created_at, updated_at"
+
+// releaseOrder lists releases oldest first, then in format display order (see Formats).
+const releaseOrder = " ORDER BY year, CASE format WHEN 'LP' THEN 0 WHEN 'CD' THEN 1 ELSE 2 END, lower(edition)"
+
+func (r *PostgresReleaseRepository) Create(release Release) (Release, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return Release{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var created Release
+	err = tx.Get(&created,
+		`INSERT INTO releases (album_id, format, edition, year, label_id, label, catalogue_number, barcode, price, currency)
+		 VALUES ($1, $2, $3, $4, NULLIF($5, '')::uuid, $6, $7, NULLIF($8, ''), $9, $10) RETURNING `+releaseColumns,
+		release.AlbumID, release.Format, release.Edition, release.Year, release.LabelID, release.Label,
+		release.CatalogueNumber, release.Barcode, release.Price, release.Currency,
+	)
+	if isUniqueViolation(err) {
+		return Release{}, ErrDuplicateBarcode
+	}
+	if err != nil {
+		return Release{}, err
+	}
+	if err := claimBarcode(tx, "release_id", created.ID, created.Barcode); err != nil {
+		return Release{}, err
+	}
+	return created, tx.Commit()
+}
+
+func (r *PostgresReleaseRepository) GetByID(albumID, id string) (Release, error) {
+	if !validAlbumID(albumID) || !validUUID(id) {
+		return Release{}, ErrReleaseNotFound
+	}
+	var release Release
+	err := r.db.Get(&release, "SELECT "+releaseColumns+" FROM releases WHERE album_id = $1 AND id = $2", albumID, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Release{}, ErrReleaseNotFound
+	}
+	return release, err
+}
+
+func (r *PostgresReleaseRepository) ListByAlbum(albumID string) ([]Release, error) {
+	if !validAlbumID(albumID) {
+		return nil, nil
+	}
+	var releases []Release
+	err := r.db.Select(&releases, "SELECT "+releaseColumns+" FROM releases WHERE album_id = $1"+releaseOrder, albumID)
+	return releases, err
+}
+
+func (r *PostgresReleaseRepository) List() ([]Release, error) {
+	var releases []Release
+	err := r.db.Select(&releases, "SELECT "+releaseColumns+" FROM releases"+releaseOrder)
+	return releases, err
+}
+
+func (r *PostgresReleaseRepository) Update(release Release) (Release, error) {
+	if !validAlbumID(release.AlbumID) || !validUUID(release.ID) {
+		return Release{}, ErrReleaseNotFound
+	}
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return Release{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var updated Release
+	err = tx.Get(&updated,
+		`UPDATE releases SET format = $1, edition = $2, year = $3, label_id = NULLIF($4, '')::uuid, label = $5, catalogue_number = $6,
+		 barcode = NULLIF($7, ''), price = $8, currency = $9, updated_at = now()
+		 WHERE album_id = $10 AND id = $11 RETURNING `+releaseColumns,
+		release.Format, release.Edition, release.Year, release.LabelID, release.Label, release.CatalogueNumber,
+		release.Barcode, release.Price, release.Currency, release.AlbumID, release.ID,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Release{}, ErrReleaseNotFound
+	}
+	if isUniqueViolation(err) {
+		return Release{}, ErrDuplicateBarcode
+	}
+	if err != nil {
+		return Release{}, err
+	}
+	if err := claimBarcode(tx, "release_id", updated.ID, updated.Barcode); err != nil {
+		return Release{}, err
+	}
+	return updated, tx.Commit()
+}
+
+func (r *PostgresReleaseRepository) GetByBarcode(barcode string) (Release, error) {
+	var release Release
+	err := r.db.Get(&release, "SELECT "+releaseColumns+" FROM releases WHERE barcode = $1", barcode)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Release{}, ErrReleaseNotFound
+	}
+	return release, err
+}
+
+func (r *PostgresReleaseRepository) Delete(albumID, id string) error {
+	if !validAlbumID(albumID) || !validUUID(id) {
+		return ErrReleaseNotFound
+	}
+	res, err := r.db.Exec("DELETE FROM releases WHERE album_id = $1 AND id = $2", albumID, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrReleaseNotFound
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestPostgresReleaseRepository tests keeping an album's releases in order, barcodes unique across
+// releases and albums, and deleting releases with their album.
+func TestPostgresReleaseRepository(t *testing.T) {
+	db, teardown := setupTestPostgres(t)
+	defer teardown()
+	repo := NewPostgresReleaseRepository(db)
+	albums := NewPostgresAlbumRepository(db)
+	labels := NewPostgresLabelRepository(db)
+
+	require.NoError(t, albums.Create(Album{Title: "What's Going On", Artist: "Marvin Gaye", Price: money.MustParse("9.99"), Year: 1971, ImageUrl: "x", Genre: "Soul"}))
+	all, err := albums.GetAll()
+	require.NoError(t, err)
+	var album Album
+	for _, a := range all {
+		if a.Title == "What's Going On" {
+			album = a
+		}
+	}
+	require.NotEmpty(t, album.ID)
+	tamla, err := labels.GetByName("Tamla")
+	require.NoError(t, err)
+
+	reissue, err := repo.Create(Release{AlbumID: album.ID, Format: FormatLP, Edition: "2021 Reissue", Year: 2021, Price: money.MustParse("27.99"), Currency: "GBP"})
+	require.NoError(t, err)
+	anniversary, err := repo.Create(Release{AlbumID: album.ID, Format: FormatCD, Edition: "40th Anniversary", Year: 2011, Barcode: "0602527736211", Price: money.MustParse("14.99"), Currency: "GBP"})
+	require.NoError(t, err)
+	original, err := repo.Create(Release{AlbumID: album.ID, Format: FormatLP, Edition: "Original", Year: 1971, LabelID: tamla.ID, Label: tamla.Name, CatalogueNumber: "TS 310", Price: money.MustParse("60.00"), Currency: "GBP"})
+	require.NoError(t, err)
+	require.Equal(t, tamla.ID, original.LabelID)
+
+	_, err = repo.Create(Release{AlbumID: album.ID, Format: FormatCD, Year: 2011, Barcode: "0602527736211", Price: money.MustParse("9.99"), Currency: "GBP"})
+	require.True(t, errors.Is(err, ErrDuplicateBarcode))
+	found, err := repo.GetByBarcode("0602527736211")
+	require.NoError(t, err)
+	require.Equal(t, anniversary.ID, found.ID)
+	_, err = repo.GetByBarcode("0036000291452")
+	require.True(t, errors.Is(err, ErrReleaseNotFound))
+
+	letsGetItOn := Album{Title: "Let's Get It On", Artist: "Marvin Gaye", Barcode: "0036000291452", Price: money.MustParse("9.99"), Year: 1973, ImageUrl: "x", Genre: "Soul"}
+	require.NoError(t, albums.Create(letsGetItOn))
+	_, err = repo.Create(Release{AlbumID: album.ID, Format: FormatCD, Year: 2011, Barcode: "0036000291452", Price: money.MustParse("9.99"), Currency: "GBP"})
+	require.True(t, errors.Is(err, ErrDuplicateBarcode), "releases cannot take an album's barcode")
+	letsGetItOn.Title, letsGetItOn.Barcode = "Trouble Man", "0602527736211"
+	require.True(t, errors.Is(albums.Create(letsGetItOn), ErrDuplicateBarcode), "albums cannot take a release's barcode")
+
+	releases, err := repo.ListByAlbum(album.ID)
+	require.NoError(t, err)
+	require.Len(t, releases, 3)
+	require.Equal(t, []string{original.ID, anniversary.ID, reissue.ID}, []string{releases[0].ID, releases[1].ID, releases[2].ID})
+
+	reissue.Price = money.MustParse("24.99")
+	updated, err := repo.Update(reissue)
+	require.NoError(t, err)
+	require.Equal(t, money.MustParse("24.99"), updated.Price)
+
+	_, err = repo.GetByID("999999", reissue.ID)
+	require.True(t, errors.Is(err, ErrReleaseNotFound), "releases are found under their own album only")
+	require.NoError(t, repo.Delete(album.ID, reissue.ID))
+	require.True(t, errors.Is(repo.Delete(album.ID, reissue.ID), ErrReleaseNotFound))
+
+	require.NoError(t, albums.Delete(album.ID))
+	releases, err = repo.List()
+	require.NoError(t, err)
+	require.Empty(t, releases, "releases are deleted with their album")
+}
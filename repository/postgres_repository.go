@@ -1,7 +1,13 @@
 package repository
 
 import (
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"github.com/gocql/gocql"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type PostgresAlbumRepository struct {
@@ -12,35 +18,190 @@ func NewPostgresAlbumRepository(db *sqlx.DB) *PostgresAlbumRepository {
 	return &PostgresAlbumRepository{db: db}
 }
 
+const albumColumns = "id, title, artist, COALESCE(artist_id::text, '') AS artist_id, COALESCE(label_id::text, '') AS label_id, label, " +
+	"COALESCE(catalogue_number, '') AS catalogue_number, COALESCE(barcode, '') AS barcode, price, currency, currency_prices, tax_category, year, image_url, genre, tags, deleted_at, deleted_by"
+
 func (r *PostgresAlbumRepository) GetAll() ([]Album, error) {
 	var albums []Album
-	err := r.db.Select(&albums, "SELECT id, title, artist, price, year, image_url, genre FROM albums")
+	err := r.db.Select(&albums, "SELECT "+albumColumns+" FROM albums WHERE deleted_at IS NULL")
 	return albums, err
 }
 
 func (r *PostgresAlbumRepository) GetByID(id string) (Album, error) {
 	var album Album
-	err := r.db.Get(&album, "SELECT id, title, artist, price, year, image_url, genre FROM albums WHERE id = $1", id)
+	err := r.db.Get(&album, "SELECT "+albumColumns+" FROM albums WHERE id = $1 AND deleted_at IS NULL", id)
+	return album, err
+}
+
+func (r *PostgresAlbumRepository) GetByBarcode(barcode string) (Album, error) {
+	return r.lookup("SELECT "+albumColumns+" FROM albums WHERE barcode = $1 AND deleted_at IS NULL", barcode)
+}
+
+func (r *PostgresAlbumRepository) GetByCatalogueNumber(labelID, catalogueNumber string) (Album, error) {
+	if !validUUID(labelID) {
+		return Album{}, ErrAlbumNotFound
+	}
+	return r.lookup("SELECT "+albumColumns+" FROM albums WHERE label_id = $1 AND catalogue_number = $2 AND deleted_at IS NULL", labelID, catalogueNumber)
+}
+
+// GetByTag finds the albums through the GIN index on tags.
+func (r *PostgresAlbumRepository) GetByTag(tag string) ([]Album, error) {
+	var albums []Album
+	err := r.db.Select(&albums, "SELECT "+albumColumns+" FROM albums WHERE tags @> jsonb_build_array($1::text) AND deleted_at IS NULL", tag)
+	return albums, err
+}
+
+func (r *PostgresAlbumRepository) TagCounts() ([]TagCount, error) {
+	var counts []TagCount
+	err := r.db.Select(&counts, "SELECT tag, count(*) AS count FROM albums, jsonb_array_elements_text(tags) AS tag WHERE deleted_at IS NULL GROUP BY tag")
+	if err != nil {
+		return nil, err
+	}
+	sortTagCounts(counts)
+	return counts, nil
+}
+
+func (r *PostgresAlbumRepository) lookup(query string, args ...interface{}) (Album, error) {
+	var album Album
+	err := r.db.Get(&album, query, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Album{}, ErrAlbumNotFound
+	}
 	return album, err
 }
 
+// validAlbumID reports whether id can be an album's id, which Postgres keeps as a serial integer.
+// Lookups compare ids in their column's own type so they use its index; an id that cannot be one
+// is treated as not found rather than sent to Postgres, which would fail the query.
+func validAlbumID(id string) bool {
+	_, err := strconv.ParseInt(id, 10, 32)
+	return err == nil
+}
+
+// validUUID reports whether id can be the id of a row keyed by UUID, as validAlbumID does for albums.
+func validUUID(id string) bool {
+	_, err := gocql.ParseUUID(id)
+	return err == nil
+}
+
+// albumIdentifierError turns a violation of the unique indexes on albums' barcodes and catalogue
+// numbers into ErrDuplicateBarcode or ErrDuplicateCatalogueNumber.
+func albumIdentifierError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != "23505" {
+		return err
+	}
+	switch pqErr.Constraint {
+	case "albums_barcode_idx":
+		return ErrDuplicateBarcode
+	case "albums_label_catalogue_number_idx":
+		return ErrDuplicateCatalogueNumber
+	}
+	return err
+}
+
 func (r *PostgresAlbumRepository) Create(album Album) error {
-	_, err := r.db.Exec(
-		"INSERT INTO albums (title, artist, price, year, image_url, genre) VALUES ($1, $2, $3, $4, $5, $6)",
-		album.Title, album.Artist, album.Price, album.Year, album.ImageUrl, album.Genre,
-	)
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var id string
+	if err := tx.Get(&id,
+		`INSERT INTO albums (title, artist, artist_id, label_id, label, catalogue_number, barcode, price, currency, currency_prices, tax_category, year, image_url, genre, tags)
+		 VALUES ($1, $2, NULLIF($3, '')::uuid, NULLIF($4, '')::uuid, $5, NULLIF($6, ''), NULLIF($7, ''), $8, $9, $10, $11, $12, $13, $14, $15) RETURNING id`,
+		album.Title, album.Artist, album.ArtistID, album.LabelID, album.Label, album.CatalogueNumber, album.Barcode,
+		album.Price, currencyOrDefault(album.Currency), album.CurrencyPrices, album.TaxCategory, album.Year, album.ImageUrl, album.Genre, album.Tags,
+	); err != nil {
+		return albumIdentifierError(err)
+	}
+	if err := claimBarcode(tx, "album_id", id, album.Barcode); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// claimBarcode replaces the barcode recorded in barcodes for the album or release whose ID is in
+// the owner column, failing with ErrDuplicateBarcode if another album or release has it.
+func claimBarcode(tx *sqlx.Tx, owner, id, barcode string) error {
+	if _, err := tx.Exec("DELETE FROM barcodes WHERE "+owner+" = $1", id); err != nil {
+		return err
+	}
+	if barcode == "" {
+		return nil
+	}
+	_, err := tx.Exec("INSERT INTO barcodes (barcode, "+owner+") VALUES ($1, $2)", barcode, id)
+	if isUniqueViolation(err) {
+		return ErrDuplicateBarcode
+	}
 	return err
 }
 
+// Update saves the album, or fails with ErrAlbumNotFound if it is not in the catalogue.
 func (r *PostgresAlbumRepository) Update(album Album) error {
-	_, err := r.db.Exec(
-		"UPDATE albums SET title = $1, artist = $2, price = $3, year = $4, image_url = $5, genre = $6 WHERE id = $7",
-		album.Title, album.Artist, album.Price, album.Year, album.ImageUrl, album.Genre, album.ID,
+	if !validAlbumID(album.ID) {
+		return ErrAlbumNotFound
+	}
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec(
+		`UPDATE albums SET title = $1, artist = $2, artist_id = NULLIF($3, '')::uuid, label_id = NULLIF($4, '')::uuid, label = $5,
+		 catalogue_number = NULLIF($6, ''), barcode = NULLIF($7, ''), price = $8, currency = $9, currency_prices = $10, tax_category = $11,
+		 year = $12, image_url = $13, genre = $14, tags = $15 WHERE id = $16 AND deleted_at IS NULL`,
+		album.Title, album.Artist, album.ArtistID, album.LabelID, album.Label, album.CatalogueNumber, album.Barcode,
+		album.Price, currencyOrDefault(album.Currency), album.CurrencyPrices, album.TaxCategory, album.Year, album.ImageUrl, album.Genre, album.Tags, album.ID,
 	)
-	return err
+	if err != nil {
+		return albumIdentifierError(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAlbumNotFound
+	}
+	if err := claimBarcode(tx, "album_id", album.ID, album.Barcode); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (r *PostgresAlbumRepository) Delete(id string) error {
 	_, err := r.db.Exec("DELETE FROM albums WHERE id = $1", id)
 	return err
 }
+
+func (r *PostgresAlbumRepository) Trash(id, actor string) error {
+	if !validAlbumID(id) {
+		return ErrAlbumNotFound
+	}
+	return r.setDeleted("UPDATE albums SET deleted_at = now(), deleted_by = $2 WHERE id = $1 AND deleted_at IS NULL", id, actor)
+}
+
+func (r *PostgresAlbumRepository) GetTrash() ([]Album, error) {
+	var albums []Album
+	err := r.db.Select(&albums, "SELECT "+albumColumns+" FROM albums WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC")
+	return albums, err
+}
+
+func (r *PostgresAlbumRepository) Restore(id string) error {
+	if !validAlbumID(id) {
+		return ErrAlbumNotFound
+	}
+	return r.setDeleted("UPDATE albums SET deleted_at = NULL, deleted_by = '' WHERE id = $1 AND deleted_at IS NOT NULL", id)
+}
+
+// setDeleted runs an update moving an album into or out of the trash, returning ErrAlbumNotFound if
+// it changed nothing.
+func (r *PostgresAlbumRepository) setDeleted(query string, args ...interface{}) error {
+	res, err := r.db.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAlbumNotFound
+	}
+	return nil
+}
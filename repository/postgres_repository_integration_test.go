@@ -16,6 +16,7 @@ import (
 	"github.com/testcontainers/testcontainers-go/wait"
 	"github.com/tvergilio/motown-house-backend/config"
 	"github.com/tvergilio/motown-house-backend/db"
+	"github.com/tvergilio/motown-house-backend/money"
 )
 
 // migrationsPath points to the directory containing the migration SQL files.
@@ -90,7 +91,7 @@ func TestPostgresAlbumRepository_Create(t *testing.T) {
 	defer teardown()
 	repo := NewPostgresAlbumRepository(db)
 
-	album := Album{Title: "Where Did Our Love Go", Artist: "The Supremes", Price: 9.99, Year: 1964, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music123/v4/5d/c2/4d/5dc24de8-15d7-16e0-7585-72a2bcc721de/14UMGIM62198.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"}
+	album := Album{Title: "Where Did Our Love Go", Artist: "The Supremes", Price: money.MustParse("9.99"), Year: 1964, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music123/v4/5d/c2/4d/5dc24de8-15d7-16e0-7585-72a2bcc721de/14UMGIM62198.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"}
 	err := repo.Create(album)
 	require.NoError(t, err)
 
@@ -99,7 +100,7 @@ func TestPostgresAlbumRepository_Create(t *testing.T) {
 	require.Len(t, albums, 1)
 	require.Equal(t, "Where Did Our Love Go", albums[0].Title)
 	require.Equal(t, "The Supremes", albums[0].Artist)
-	require.Equal(t, 9.99, albums[0].Price)
+	require.Equal(t, money.MustParse("9.99"), albums[0].Price)
 	require.Equal(t, 1964, albums[0].Year)
 	require.Equal(t, "https://is1-ssl.mzstatic.com/image/thumb/Music123/v4/5d/c2/4d/5dc24de8-15d7-16e0-7585-72a2bcc721de/14UMGIM62198.rgb.jpg/100x100bb.jpg", albums[0].ImageUrl)
 	require.Equal(t, "R&B/Soul", albums[0].Genre)
@@ -112,16 +113,16 @@ func TestPostgresAlbumRepository_GetAll(t *testing.T) {
 	defer teardown()
 	repo := NewPostgresAlbumRepository(db)
 
-	_ = repo.Create(Album{Title: "ABC", Artist: "Jackson 5", Price: 1.0, Year: 1970, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music211/v4/cb/38/70/cb3870c2-1a9b-9310-e218-9d0f5a5e98f5/06UMGIM05267.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"})
-	_ = repo.Create(Album{Title: "Diana", Artist: "Diana Ross", Price: 2.0, Year: 1980, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music115/v4/aa/87/1c/aa871c20-95be-38bd-97e3-ecfeb8ec404b/15UMGIM06551.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"})
-	_ = repo.Create(Album{Title: "Sex Machine", Artist: "James Brown", Price: 3.0, Year: 1970, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music128/v4/17/8b/05/178b05de-5855-0136-9827-a0e8a6ccf3db/00602547021656.rgb.jpg/100x100bb.jpg", Genre: "Soul"})
+	_ = repo.Create(Album{Title: "ABC", Artist: "Jackson 5", Price: money.MustParse("1.00"), Year: 1970, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music211/v4/cb/38/70/cb3870c2-1a9b-9310-e218-9d0f5a5e98f5/06UMGIM05267.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"})
+	_ = repo.Create(Album{Title: "Diana", Artist: "Diana Ross", Price: money.MustParse("2.00"), Year: 1980, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music115/v4/aa/87/1c/aa871c20-95be-38bd-97e3-ecfeb8ec404b/15UMGIM06551.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"})
+	_ = repo.Create(Album{Title: "Sex Machine", Artist: "James Brown", Price: money.MustParse("3.00"), Year: 1970, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music128/v4/17/8b/05/178b05de-5855-0136-9827-a0e8a6ccf3db/00602547021656.rgb.jpg/100x100bb.jpg", Genre: "Soul"})
 
 	albums, err := repo.GetAll()
 	require.NoError(t, err)
 	require.NotEmpty(t, albums)
 	require.Equal(t, "ABC", albums[0].Title)
 	require.Equal(t, "Jackson 5", albums[0].Artist)
-	require.Equal(t, 1.0, albums[0].Price)
+	require.Equal(t, money.MustParse("1.00"), albums[0].Price)
 	require.Equal(t, 1970, albums[0].Year)
 	require.Equal(t, "https://is1-ssl.mzstatic.com/image/thumb/Music211/v4/cb/38/70/cb3870c2-1a9b-9310-e218-9d0f5a5e98f5/06UMGIM05267.rgb.jpg/100x100bb.jpg", albums[0].ImageUrl)
 	require.Equal(t, "R&B/Soul", albums[0].Genre)
@@ -129,7 +130,7 @@ func TestPostgresAlbumRepository_GetAll(t *testing.T) {
 
 	require.Equal(t, "Diana", albums[1].Title)
 	require.Equal(t, "Diana Ross", albums[1].Artist)
-	require.Equal(t, 2.0, albums[1].Price)
+	require.Equal(t, money.MustParse("2.00"), albums[1].Price)
 	require.Equal(t, 1980, albums[1].Year)
 	require.Equal(t, "https://is1-ssl.mzstatic.com/image/thumb/Music115/v4/aa/87/1c/aa871c20-95be-38bd-97e3-ecfeb8ec404b/15UMGIM06551.rgb.jpg/100x100bb.jpg", albums[1].ImageUrl)
 	require.Equal(t, "R&B/Soul", albums[1].Genre)
@@ -137,7 +138,7 @@ func TestPostgresAlbumRepository_GetAll(t *testing.T) {
 
 	require.Equal(t, "Sex Machine", albums[2].Title)
 	require.Equal(t, "James Brown", albums[2].Artist)
-	require.Equal(t, 3.0, albums[2].Price)
+	require.Equal(t, money.MustParse("3.00"), albums[2].Price)
 	require.Equal(t, 1970, albums[2].Year)
 	require.Equal(t, "https://is1-ssl.mzstatic.com/image/thumb/Music128/v4/17/8b/05/178b05de-5855-0136-9827-a0e8a6ccf3db/00602547021656.rgb.jpg/100x100bb.jpg", albums[2].ImageUrl)
 	require.Equal(t, "Soul", albums[2].Genre)
@@ -150,7 +151,7 @@ func TestPostgresAlbumRepository_GetByID(t *testing.T) {
 	defer teardown()
 	repo := NewPostgresAlbumRepository(db)
 
-	_ = repo.Create(Album{Title: "ABC", Artist: "Jackson 5", Price: 1.0, Year: 1970, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music211/v4/cb/38/70/cb3870c2-1a9b-9310-e218-9d0f5a5e98f5/06UMGIM05267.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"})
+	_ = repo.Create(Album{Title: "ABC", Artist: "Jackson 5", Price: money.MustParse("1.00"), Year: 1970, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music211/v4/cb/38/70/cb3870c2-1a9b-9310-e218-9d0f5a5e98f5/06UMGIM05267.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"})
 	albums, err := repo.GetAll()
 	require.NoError(t, err)
 	require.NotEmpty(t, albums)
@@ -159,7 +160,7 @@ func TestPostgresAlbumRepository_GetByID(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "ABC", got.Title)
 	require.Equal(t, "Jackson 5", got.Artist)
-	require.Equal(t, 1.0, got.Price)
+	require.Equal(t, money.MustParse("1.00"), got.Price)
 	require.Equal(t, 1970, got.Year)
 	require.Equal(t, "https://is1-ssl.mzstatic.com/image/thumb/Music211/v4/cb/38/70/cb3870c2-1a9b-9310-e218-9d0f5a5e98f5/06UMGIM05267.rgb.jpg/100x100bb.jpg", got.ImageUrl)
 	require.Equal(t, "R&B/Soul", got.Genre)
@@ -173,7 +174,7 @@ func TestPostgresAlbumRepository_Update(t *testing.T) {
 	repo := NewPostgresAlbumRepository(db)
 
 	// Create an album
-	album := Album{Title: "ABC", Artist: "Shakira", Price: 1.0, Year: 2024, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music115/v4/8d/97/f4/8d97f427-2d17-1a51-1714-324483eb5fc1/886443546264.jpg/100x100bb.jpg", Genre: "Pop"}
+	album := Album{Title: "ABC", Artist: "Shakira", Price: money.MustParse("1.00"), Year: 2024, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music115/v4/8d/97/f4/8d97f427-2d17-1a51-1714-324483eb5fc1/886443546264.jpg/100x100bb.jpg", Genre: "Pop"}
 	err := repo.Create(album)
 	require.NoError(t, err)
 
@@ -184,7 +185,7 @@ func TestPostgresAlbumRepository_Update(t *testing.T) {
 	id := albums[0].ID
 
 	// Update the album
-	updated := Album{ID: id, Title: "ABC", Artist: "Jackson 5", Price: 20.0, Year: 1970, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music211/v4/cb/38/70/cb3870c2-1a9b-9310-e218-9d0f5a5e98f5/06UMGIM05267.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"}
+	updated := Album{ID: id, Title: "ABC", Artist: "Jackson 5", Price: money.MustParse("20.00"), Year: 1970, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music211/v4/cb/38/70/cb3870c2-1a9b-9310-e218-9d0f5a5e98f5/06UMGIM05267.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"}
 	err = repo.Update(updated)
 	require.NoError(t, err)
 
@@ -193,7 +194,7 @@ func TestPostgresAlbumRepository_Update(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "ABC", got.Title)
 	require.Equal(t, "Jackson 5", got.Artist)
-	require.Equal(t, 20.0, got.Price)
+	require.Equal(t, money.MustParse("20.00"), got.Price)
 	require.Equal(t, 1970, got.Year)
 	require.Equal(t, "https://is1-ssl.mzstatic.com/image/thumb/Music211/v4/cb/38/70/cb3870c2-1a9b-9310-e218-9d0f5a5e98f5/06UMGIM05267.rgb.jpg/100x100bb.jpg", got.ImageUrl)
 	require.Equal(t, "R&B/Soul", got.Genre)
@@ -206,7 +207,7 @@ func TestPostgresAlbumRepository_Delete(t *testing.T) {
 	defer teardown()
 	repo := NewPostgresAlbumRepository(db)
 
-	_ = repo.Create(Album{Title: "ABC", Artist: "Jackson 5", Price: 1.0, Year: 1970, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music211/v4/cb/38/70/cb3870c2-1a9b-9310-e218-9d0f5a5e98f5/06UMGIM05267.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"})
+	_ = repo.Create(Album{Title: "ABC", Artist: "Jackson 5", Price: money.MustParse("1.00"), Year: 1970, ImageUrl: "https://is1-ssl.mzstatic.com/image/thumb/Music211/v4/cb/38/70/cb3870c2-1a9b-9310-e218-9d0f5a5e98f5/06UMGIM05267.rgb.jpg/100x100bb.jpg", Genre: "R&B/Soul"})
 	albums, err := repo.GetAll()
 	require.NoError(t, err)
 	require.NotEmpty(t, albums)
@@ -217,3 +218,40 @@ func TestPostgresAlbumRepository_Delete(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, albums, 0)
 }
+
+// TestPostgresAlbumRepository_TrashAndRestore tests that trashed albums are hidden until restored.
+func TestPostgresAlbumRepository_TrashAndRestore(t *testing.T) {
+	db, teardown := setupTestPostgres(t)
+	defer teardown()
+	repo := NewPostgresAlbumRepository(db)
+
+	require.NoError(t, repo.Create(Album{Title: "ABC", Artist: "Jackson 5", Price: money.MustParse("1.00"), Year: 1970, Genre: "R&B/Soul"}))
+	albums, err := repo.GetAll()
+	require.NoError(t, err)
+	id := albums[0].ID
+
+	require.NoError(t, repo.Trash(id, "staff-1"))
+	require.ErrorIs(t, repo.Trash(id, "staff-1"), ErrAlbumNotFound)
+	trashed := albums[0]
+	trashed.Title = "ABC (Remastered)"
+	require.ErrorIs(t, repo.Update(trashed), ErrAlbumNotFound, "albums in the trash cannot be edited")
+	albums, err = repo.GetAll()
+	require.NoError(t, err)
+	require.Len(t, albums, 0)
+	_, err = repo.GetByID(id)
+	require.Error(t, err)
+	trash, err := repo.GetTrash()
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	require.NotNil(t, trash[0].DeletedAt)
+	require.Equal(t, "staff-1", trash[0].DeletedBy)
+
+	require.NoError(t, repo.Restore(id))
+	require.ErrorIs(t, repo.Restore(id), ErrAlbumNotFound)
+	got, err := repo.GetByID(id)
+	require.NoError(t, err)
+	require.Nil(t, got.DeletedAt)
+	trash, err = repo.GetTrash()
+	require.NoError(t, err)
+	require.Len(t, trash, 0)
+}
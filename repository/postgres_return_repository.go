@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PostgresReturnRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresReturnRepository(db *sqlx.DB) *PostgresReturnRepository {
+	return &PostgresReturnRepository{db: db}
+}
+
+const returnColumns = "id, order_id, user_id, status, refund_amount, refunded_amount, created_at, updated_at"
+
+func (r *PostgresReturnRepository) Create(ret Return) (Return, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return Return{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	ret.Status = ReturnRequested
+	if err := tx.Get(&ret,
+		"INSERT INTO returns (order_id, user_id, status, refund_amount) VALUES ($1, $2, $3, $4) RETURNING "+returnColumns,
+		ret.OrderID, ret.UserID, ret.Status, ret.RefundAmount,
+	); err != nil {
+		return Return{}, err
+	}
+	for i, line := range ret.Lines {
+		if _, err := tx.Exec(
+			`INSERT INTO return_lines (return_id, line_no, album_id, release_id, format, title, quantity, reason, note, refund)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+			ret.ID, i, line.AlbumID, line.ReleaseID, line.Format, line.Title, line.Quantity, line.Reason, line.Note, line.Refund,
+		); err != nil {
+			return Return{}, err
+		}
+	}
+	if err := insertReturnEvent(tx, ret.ID, ReturnEvent{Status: ReturnRequested, Actor: ret.UserID}); err != nil {
+		return Return{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Return{}, err
+	}
+	return r.GetByID(ret.ID)
+}
+
+func insertReturnEvent(tx *sqlx.Tx, returnID string, event ReturnEvent) error {
+	_, err := tx.Exec(
+		"INSERT INTO return_events (return_id, status, actor, note) VALUES ($1, $2, $3, $4)",
+		returnID, event.Status, event.Actor, event.Note,
+	)
+	return err
+}
+
+func (r *PostgresReturnRepository) GetByID(id string) (Return, error) {
+	if !validUUID(id) {
+		return Return{}, ErrReturnNotFound
+	}
+	var ret Return
+	err := r.db.Get(&ret, "SELECT "+returnColumns+" FROM returns WHERE id = $1", id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Return{}, ErrReturnNotFound
+	}
+	if err != nil {
+		return Return{}, err
+	}
+	if err := r.loadDetails(&ret); err != nil {
+		return Return{}, err
+	}
+	return ret, nil
+}
+
+func (r *PostgresReturnRepository) loadDetails(ret *Return) error {
+	if err := r.db.Select(&ret.Lines,
+		`SELECT album_id, release_id, format, title, quantity, reason, note, refund, restock, media_grade, sleeve_grade, restocked
+		 FROM return_lines WHERE return_id = $1 ORDER BY line_no`,
+		ret.ID,
+	); err != nil {
+		return err
+	}
+	return r.db.Select(&ret.History,
+		"SELECT status, actor, note, created_at FROM return_events WHERE return_id = $1 ORDER BY id",
+		ret.ID,
+	)
+}
+
+func (r *PostgresReturnRepository) ListByOrder(orderID string) ([]Return, error) {
+	if !validUUID(orderID) {
+		return nil, nil
+	}
+	return r.list("SELECT "+returnColumns+" FROM returns WHERE order_id = $1 ORDER BY created_at", orderID)
+}
+
+func (r *PostgresReturnRepository) ListByUser(userID string) ([]Return, error) {
+	return r.list("SELECT "+returnColumns+" FROM returns WHERE user_id = $1 ORDER BY created_at DESC", userID)
+}
+
+func (r *PostgresReturnRepository) List(status ReturnStatus) ([]Return, error) {
+	return r.list("SELECT "+returnColumns+" FROM returns WHERE $1 = '' OR status = $1 ORDER BY created_at DESC", string(status))
+}
+
+func (r *PostgresReturnRepository) list(query string, arg string) ([]Return, error) {
+	var returns []Return
+	if err := r.db.Select(&returns, query, arg); err != nil {
+		return nil, err
+	}
+	for i := range returns {
+		if err := r.loadDetails(&returns[i]); err != nil {
+			return nil, err
+		}
+	}
+	return returns, nil
+}
+
+func (r *PostgresReturnRepository) Update(ret Return, from ReturnStatus, actor, note string) (Return, error) {
+	if !validUUID(ret.ID) {
+		return Return{}, ErrReturnNotFound
+	}
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return Return{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec(
+		"UPDATE returns SET status = $1, refunded_amount = $2, updated_at = now() WHERE id = $3 AND status = $4",
+		ret.Status, ret.RefundedAmount, ret.ID, from,
+	)
+	if err != nil {
+		return Return{}, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		if _, err := r.GetByID(ret.ID); err != nil {
+			return Return{}, err
+		}
+		return Return{}, ErrReturnStatusChanged
+	}
+	for i, line := range ret.Lines {
+		if _, err := tx.Exec(
+			"UPDATE return_lines SET restock = $1, media_grade = $2, sleeve_grade = $3 WHERE return_id = $4 AND line_no = $5",
+			line.Restock, line.MediaGrade, line.SleeveGrade, ret.ID, i,
+		); err != nil {
+			return Return{}, err
+		}
+	}
+	if err := insertReturnEvent(tx, ret.ID, ReturnEvent{Status: ret.Status, Actor: actor, Note: note}); err != nil {
+		return Return{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return Return{}, err
+	}
+	return r.GetByID(ret.ID)
+}
+
+func (r *PostgresReturnRepository) SetRestocked(id string, line, from, to int) error {
+	if !validUUID(id) {
+		return ErrReturnNotFound
+	}
+	res, err := r.db.Exec(
+		"UPDATE return_lines SET restocked = $1 WHERE return_id = $2 AND line_no = $3 AND restocked = $4",
+		to, id, line, from,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		if _, err := r.GetByID(id); err != nil {
+			return err
+		}
+		return ErrReturnStatusChanged
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestPostgresReturnRepository_Lifecycle tests that a return keeps its lines, restock details and history
+// as it moves through its statuses, and that updates are guarded by the expected status.
+func TestPostgresReturnRepository_Lifecycle(t *testing.T) {
+	db, teardown := setupTestPostgres(t)
+	defer teardown()
+	albumID := createTestPostgresAlbum(t, NewPostgresAlbumRepository(db))
+	_, err := NewPostgresInventoryRepository(db).Adjust(StockAdjustment{AlbumID: albumID, Format: FormatLP, Delta: 2, Reason: ReasonReceived})
+	require.NoError(t, err)
+	order, err := NewPostgresOrderRepository(db).Create(Order{UserID: "user-1", Total: money.MustParse("2.00"), Lines: []OrderLine{
+		{AlbumID: albumID, Format: FormatLP, Title: "ABC", Quantity: 2, UnitPrice: money.MustParse("1.00"), LineTotal: money.MustParse("2.00")},
+	}})
+	require.NoError(t, err)
+	repo := NewPostgresReturnRepository(db)
+
+	ret, err := repo.Create(Return{OrderID: order.ID, UserID: "user-1", RefundAmount: money.MustParse("1.00"), Lines: []ReturnLine{
+		{AlbumID: albumID, Format: FormatLP, Title: "ABC", Quantity: 1, Reason: ReturnWarped, Note: "dished", Refund: money.MustParse("1.00")},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, ReturnRequested, ret.Status)
+	require.Len(t, ret.Lines, 1)
+	require.Equal(t, "dished", ret.Lines[0].Note)
+	require.Len(t, ret.History, 1)
+
+	ret.Status = ReturnApproved
+	ret, err = repo.Update(ret, ReturnRequested, "staff", "send it back")
+	require.NoError(t, err)
+
+	ret.Status = ReturnReceived
+	ret.Lines[0].Restock = RestockUsed
+	ret.Lines[0].MediaGrade, ret.Lines[0].SleeveGrade = "VG", "VG+"
+	_, err = repo.Update(ret, ReturnRequested, "staff", "")
+	require.True(t, errors.Is(err, ErrReturnStatusChanged))
+	received, err := repo.Update(ret, ReturnApproved, "staff", "")
+	require.NoError(t, err)
+	require.Equal(t, RestockUsed, received.Lines[0].Restock)
+	require.Equal(t, Grade("VG+"), received.Lines[0].SleeveGrade)
+	require.Equal(t, 0, received.Lines[0].Restocked)
+
+	require.NoError(t, repo.SetRestocked(received.ID, 0, 0, 1))
+	require.True(t, errors.Is(repo.SetRestocked(received.ID, 0, 0, 1), ErrReturnStatusChanged), "a unit is restocked once")
+	require.True(t, errors.Is(repo.SetRestocked("not-a-uuid", 0, 0, 1), ErrReturnNotFound))
+	received, err = repo.GetByID(received.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, received.Lines[0].Restocked)
+
+	received.Status = ReturnRefunded
+	received.RefundedAmount = money.MustParse("0.80")
+	refunded, err := repo.Update(received, ReturnReceived, "staff", "restocking fee")
+	require.NoError(t, err)
+	require.Equal(t, money.MustParse("0.80"), refunded.RefundedAmount)
+	require.Len(t, refunded.History, 4)
+	require.Equal(t, "restocking fee", refunded.History[3].Note)
+
+	returns, err := repo.ListByOrder(order.ID)
+	require.NoError(t, err)
+	require.Len(t, returns, 1)
+	returns, err = repo.ListByUser("user-1")
+	require.NoError(t, err)
+	require.Len(t, returns, 1)
+	returns, err = repo.List(ReturnRequested)
+	require.NoError(t, err)
+	require.Empty(t, returns)
+
+	_, err = repo.GetByID("00000000-0000-0000-0000-000000000000")
+	require.True(t, errors.Is(err, ErrReturnNotFound))
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type PostgresReviewRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresReviewRepository(db *sqlx.DB) *PostgresReviewRepository {
+	return &PostgresReviewRepository{db: db}
+}
+
+const reviewColumns = "id, album_id, user_id, author, rating, title, body, status, created_at, updated_at"
+
+func (r *PostgresReviewRepository) Create(review Review) (Review, error) {
+	if review.Status == "" {
+		review.Status = ReviewPending
+	}
+	var created Review
+	err := r.db.Get(&created,
+		`INSERT INTO reviews (album_id, user_id, author, rating, title, body, status)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING `+reviewColumns,
+		review.AlbumID, review.UserID, review.Author, review.Rating, review.Title, review.Body, review.Status,
+	)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return Review{}, ErrDuplicateReview
+	}
+	return created, err
+}
+
+func (r *PostgresReviewRepository) GetByID(id string) (Review, error) {
+	if !validUUID(id) {
+		return Review{}, ErrReviewNotFound
+	}
+	var review Review
+	err := r.db.Get(&review, "SELECT "+reviewColumns+" FROM reviews WHERE id = $1", id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Review{}, ErrReviewNotFound
+	}
+	return review, err
+}
+
+func (r *PostgresReviewRepository) ListByAlbum(albumID string, status ReviewStatus) ([]Review, error) {
+	if !validAlbumID(albumID) {
+		return nil, nil
+	}
+	var reviews []Review
+	err := r.db.Select(&reviews,
+		"SELECT "+reviewColumns+" FROM reviews WHERE album_id = $1 AND ($2 = '' OR status = $2) ORDER BY created_at DESC",
+		albumID, string(status),
+	)
+	return reviews, err
+}
+
+func (r *PostgresReviewRepository) List(status ReviewStatus) ([]Review, error) {
+	var reviews []Review
+	err := r.db.Select(&reviews,
+		"SELECT "+reviewColumns+" FROM reviews WHERE $1 = '' OR status = $1 ORDER BY created_at DESC",
+		string(status),
+	)
+	return reviews, err
+}
+
+func (r *PostgresReviewRepository) SetStatus(id string, from, to ReviewStatus) (Review, error) {
+	if !validUUID(id) {
+		return Review{}, ErrReviewNotFound
+	}
+	var review Review
+	err := r.db.Get(&review,
+		"UPDATE reviews SET status = $1, updated_at = now() WHERE id = $2 AND status = $3 RETURNING "+reviewColumns,
+		to, id, from,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := r.GetByID(id); err != nil {
+			return Review{}, err
+		}
+		return Review{}, ErrReviewStatusChanged
+	}
+	return review, err
+}
+
+func (r *PostgresReviewRepository) Delete(id string) error {
+	if !validUUID(id) {
+		return ErrReviewNotFound
+	}
+	res, err := r.db.Exec("DELETE FROM reviews WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrReviewNotFound
+	}
+	return nil
+}
+
+func (r *PostgresReviewRepository) Ratings() ([]RatingSummary, error) {
+	var ratings []RatingSummary
+	err := r.db.Select(&ratings,
+		`SELECT album_id::text AS album_id, COUNT(*) AS review_count, ROUND(AVG(rating), 1)::float8 AS average
+		 FROM reviews WHERE status = $1 GROUP BY album_id ORDER BY album_id`,
+		ReviewApproved,
+	)
+	return ratings, err
+}
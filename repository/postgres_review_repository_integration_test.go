@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestPostgresReviewRepository tests one review per user per album, moderation and the ratings of approved reviews.
+func TestPostgresReviewRepository(t *testing.T) {
+	db, teardown := setupTestPostgres(t)
+	defer teardown()
+	albumID := createTestPostgresAlbum(t, NewPostgresAlbumRepository(db))
+	repo := NewPostgresReviewRepository(db)
+
+	ann, err := repo.Create(Review{AlbumID: albumID, UserID: "user-1", Author: "Ann", Rating: 5, Body: "A masterpiece."})
+	require.NoError(t, err)
+	require.Equal(t, ReviewPending, ann.Status)
+	_, err = repo.Create(Review{AlbumID: albumID, UserID: "user-1", Author: "Ann", Rating: 1, Body: "Changed my mind."})
+	require.True(t, errors.Is(err, ErrDuplicateReview))
+	bob, err := repo.Create(Review{AlbumID: albumID, UserID: "user-2", Author: "Bob", Rating: 4, Body: "Great."})
+	require.NoError(t, err)
+
+	ratings, err := repo.Ratings()
+	require.NoError(t, err)
+	require.Empty(t, ratings)
+
+	_, err = repo.SetStatus(ann.ID, ReviewPending, ReviewApproved)
+	require.NoError(t, err)
+	_, err = repo.SetStatus(ann.ID, ReviewPending, ReviewRejected)
+	require.True(t, errors.Is(err, ErrReviewStatusChanged))
+	_, err = repo.SetStatus(bob.ID, ReviewPending, ReviewApproved)
+	require.NoError(t, err)
+
+	ratings, err = repo.Ratings()
+	require.NoError(t, err)
+	require.Equal(t, []RatingSummary{{AlbumID: albumID, Count: 2, Average: 4.5}}, ratings)
+
+	approved, err := repo.ListByAlbum(albumID, ReviewApproved)
+	require.NoError(t, err)
+	require.Len(t, approved, 2)
+
+	require.NoError(t, repo.Delete(bob.ID))
+	require.True(t, errors.Is(repo.Delete(bob.ID), ErrReviewNotFound))
+	pending, err := repo.List(ReviewPending)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
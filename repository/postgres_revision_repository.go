@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// PostgresRevisionRepository stores each revision's snapshot as JSON, keyed by album and number.
+// Record works out the next number in the same statement that inserts the revision and tries again
+// if another edit took the number first.
+type PostgresRevisionRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresRevisionRepository(db *sqlx.DB) *PostgresRevisionRepository {
+	return &PostgresRevisionRepository{db: db}
+}
+
+const (
+	revisionColumns = "album_id, number, snapshot, actor, note, rolled_back_to, created_at"
+	// maxRevisionAttempts is how many times Record tries for a number before giving up.
+	maxRevisionAttempts = 5
+)
+
+func (r *PostgresRevisionRepository) Record(revision AlbumRevision) (AlbumRevision, error) {
+	var recorded AlbumRevision
+	var err error
+	for attempt := 0; attempt < maxRevisionAttempts; attempt++ {
+		err = r.db.Get(&recorded,
+			`INSERT INTO album_revisions (album_id, number, snapshot, actor, note, rolled_back_to)
+			 SELECT $1::integer, COALESCE(MAX(number), 0) + 1, $2, $3, $4, $5
+			 FROM album_revisions WHERE album_id = $1::integer
+			 RETURNING `+revisionColumns,
+			revision.AlbumID, revision.Album, revision.Actor, revision.Note, revision.RolledBackTo,
+		)
+		if !isUniqueViolation(err) {
+			break
+		}
+	}
+	return recorded, err
+}
+
+func (r *PostgresRevisionRepository) List(albumID string) ([]AlbumRevision, error) {
+	if !validAlbumID(albumID) {
+		return nil, nil
+	}
+	var revisions []AlbumRevision
+	err := r.db.Select(&revisions,
+		"SELECT "+revisionColumns+" FROM album_revisions WHERE album_id = $1 ORDER BY number DESC",
+		albumID,
+	)
+	return revisions, err
+}
+
+func (r *PostgresRevisionRepository) Get(albumID string, number int) (AlbumRevision, error) {
+	if !validAlbumID(albumID) {
+		return AlbumRevision{}, ErrRevisionNotFound
+	}
+	var revision AlbumRevision
+	err := r.db.Get(&revision,
+		"SELECT "+revisionColumns+" FROM album_revisions WHERE album_id = $1 AND number = $2",
+		albumID, number,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return AlbumRevision{}, ErrRevisionNotFound
+	}
+	return revision, err
+}
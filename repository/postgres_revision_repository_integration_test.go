@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestPostgresRevisionRepository tests numbering, listing and reading back an album's revisions.
+func TestPostgresRevisionRepository(t *testing.T) {
+	db, teardown := setupTestPostgres(t)
+	defer teardown()
+	albums := NewPostgresAlbumRepository(db)
+	repo := NewPostgresRevisionRepository(db)
+
+	album := Album{Title: "ABC", Artist: "Jackson 5", Price: money.MustParse("9.99"), Year: 1970, Genre: "R&B/Soul", Tags: Tags{"motown"}}
+	require.NoError(t, albums.Create(album))
+	all, err := albums.GetAll()
+	require.NoError(t, err)
+	album.ID = all[0].ID
+
+	first, err := repo.Record(AlbumRevision{AlbumID: album.ID, Album: SnapshotOf(album), Actor: "staff-1"})
+	require.NoError(t, err)
+	require.Equal(t, 1, first.Number)
+	require.False(t, first.CreatedAt.IsZero())
+	album.Price = money.MustParse("7.99")
+	second, err := repo.Record(AlbumRevision{AlbumID: album.ID, Album: SnapshotOf(album), Actor: "staff-2", Note: "rolled back to revision 1", RolledBackTo: 1})
+	require.NoError(t, err)
+	require.Equal(t, 2, second.Number)
+
+	revisions, err := repo.List(album.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	require.Equal(t, 2, revisions[0].Number)
+	require.Equal(t, 1, revisions[0].RolledBackTo)
+
+	got, err := repo.Get(album.ID, 1)
+	require.NoError(t, err)
+	require.Equal(t, money.MustParse("9.99"), got.Album.Price)
+	require.Equal(t, Tags{"motown"}, got.Album.Tags)
+	_, err = repo.Get(album.ID, 3)
+	require.ErrorIs(t, err, ErrRevisionNotFound)
+}
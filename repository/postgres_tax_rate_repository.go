@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PostgresTaxRateRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresTaxRateRepository(db *sqlx.DB) *PostgresTaxRateRepository {
+	return &PostgresTaxRateRepository{db: db}
+}
+
+func (r *PostgresTaxRateRepository) List() ([]TaxRate, error) {
+	var rates []TaxRate
+	err := r.db.Select(&rates, "SELECT country, region, category, name, rate, updated_at FROM tax_rates ORDER BY country, region, category")
+	return rates, err
+}
+
+func (r *PostgresTaxRateRepository) Replace(rates []TaxRate) ([]TaxRate, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec("DELETE FROM tax_rates"); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	for _, rate := range rates {
+		if _, err := tx.Exec(
+			"INSERT INTO tax_rates (country, region, category, name, rate, updated_at) VALUES ($1, $2, $3, $4, $5, $6)",
+			rate.Country, rate.Region, rate.Category, rate.Name, rate.Rate, now,
+		); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.List()
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestPostgresTaxRateRepository_Replace tests that an upload replaces the whole table.
+func TestPostgresTaxRateRepository_Replace(t *testing.T) {
+	db, teardown := setupTestPostgres(t)
+	defer teardown()
+	repo := NewPostgresTaxRateRepository(db)
+
+	rates, err := repo.Replace([]TaxRate{
+		{Country: "US", Region: "CA", Name: "California sales tax", Rate: 7.25},
+		{Country: "GB", Name: "VAT", Rate: 20},
+		{Country: "GB", Category: "books", Name: "VAT", Rate: 0},
+	})
+	require.NoError(t, err)
+	require.Len(t, rates, 3)
+	require.Equal(t, "GB", rates[0].Key())
+	require.Equal(t, "GB//books", rates[1].Key())
+	require.Equal(t, 7.25, rates[2].Rate)
+
+	rates, err = repo.Replace([]TaxRate{{Country: "DE", Name: "MwSt", Rate: 19}})
+	require.NoError(t, err)
+	require.Len(t, rates, 1, "rates left out of the upload are removed")
+	require.Equal(t, "DE", rates[0].Country)
+}
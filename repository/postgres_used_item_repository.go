@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PostgresUsedItemRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresUsedItemRepository(db *sqlx.DB) *PostgresUsedItemRepository {
+	return &PostgresUsedItemRepository{db: db}
+}
+
+const usedItemColumns = "id, album_id, format, media_grade, sleeve_grade, notes, photos, price, currency, status, created_at, updated_at"
+
+func (r *PostgresUsedItemRepository) Create(item UsedItem) (UsedItem, error) {
+	var created UsedItem
+	err := r.db.Get(&created,
+		`INSERT INTO used_items (album_id, format, media_grade, sleeve_grade, notes, photos, price, currency, status)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING `+usedItemColumns,
+		item.AlbumID, item.Format, item.MediaGrade, item.SleeveGrade, item.Notes, item.Photos, item.Price, item.Currency, UsedItemAvailable,
+	)
+	return created, err
+}
+
+func (r *PostgresUsedItemRepository) GetByID(id string) (UsedItem, error) {
+	if !validUUID(id) {
+		return UsedItem{}, ErrUsedItemNotFound
+	}
+	var item UsedItem
+	err := r.db.Get(&item, "SELECT "+usedItemColumns+" FROM used_items WHERE id = $1", id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return UsedItem{}, ErrUsedItemNotFound
+	}
+	return item, err
+}
+
+// List narrows the items down by album and status in the query; grades are not ordered
+// alphabetically, so they are compared once the items are loaded.
+func (r *PostgresUsedItemRepository) List(filter UsedItemFilter) ([]UsedItem, error) {
+	if filter.AlbumID != "" && !validAlbumID(filter.AlbumID) {
+		return nil, nil
+	}
+	var all []UsedItem
+	err := r.db.Select(&all,
+		"SELECT "+usedItemColumns+" FROM used_items WHERE ($1 = '' OR album_id = $1) AND ($2 = '' OR status = $2)",
+		filter.AlbumID, filter.Status,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var items []UsedItem
+	for _, item := range all {
+		if filter.Matches(item) {
+			items = append(items, item)
+		}
+	}
+	sortUsedItems(items)
+	return items, nil
+}
+
+func (r *PostgresUsedItemRepository) Update(item UsedItem) (UsedItem, error) {
+	if !validUUID(item.ID) {
+		return UsedItem{}, ErrUsedItemNotFound
+	}
+	var updated UsedItem
+	err := r.db.Get(&updated,
+		`UPDATE used_items SET format = $1, media_grade = $2, sleeve_grade = $3, notes = $4, photos = $5, price = $6,
+		 currency = $7, updated_at = now() WHERE id = $8 RETURNING `+usedItemColumns,
+		item.Format, item.MediaGrade, item.SleeveGrade, item.Notes, item.Photos, item.Price, item.Currency, item.ID,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return UsedItem{}, ErrUsedItemNotFound
+	}
+	return updated, err
+}
+
+func (r *PostgresUsedItemRepository) SetStatus(id string, from, to UsedItemStatus) (UsedItem, error) {
+	if !validUUID(id) {
+		return UsedItem{}, ErrUsedItemNotFound
+	}
+	var item UsedItem
+	err := r.db.Get(&item,
+		"UPDATE used_items SET status = $1, updated_at = now() WHERE id = $2 AND status = $3 RETURNING "+usedItemColumns,
+		to, id, from,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := r.GetByID(id); err != nil {
+			return UsedItem{}, err
+		}
+		return UsedItem{}, ErrUsedItemStatusChanged
+	}
+	return item, err
+}
+
+func (r *PostgresUsedItemRepository) Delete(id string) error {
+	if !validUUID(id) {
+		return ErrUsedItemNotFound
+	}
+	res, err := r.db.Exec("DELETE FROM used_items WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrUsedItemNotFound
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestPostgresUsedItemRepository tests filtering used items by grade and selling each copy once.
+func TestPostgresUsedItemRepository(t *testing.T) {
+	db, teardown := setupTestPostgres(t)
+	defer teardown()
+	repo := NewPostgresUsedItemRepository(db)
+	albums := NewPostgresAlbumRepository(db)
+
+	require.NoError(t, albums.Create(Album{Title: "Let's Get It On", Artist: "Marvin Gaye", Price: money.MustParse("9.99"), Year: 1973, ImageUrl: "x", Genre: "Soul"}))
+	all, err := albums.GetAll()
+	require.NoError(t, err)
+	var album Album
+	for _, a := range all {
+		if a.Title == "Let's Get It On" {
+			album = a
+		}
+	}
+	require.NotEmpty(t, album.ID)
+
+	good, err := repo.Create(UsedItem{AlbumID: album.ID, Format: FormatLP, MediaGrade: "VG+", SleeveGrade: "VG", Notes: "Gatefold",
+		Photos: Photos{"https://example.com/front.jpg"}, Price: money.MustParse("18.00"), Currency: "GBP"})
+	require.NoError(t, err)
+	require.Equal(t, UsedItemAvailable, good.Status)
+	require.Equal(t, Photos{"https://example.com/front.jpg"}, good.Photos)
+	worn, err := repo.Create(UsedItem{AlbumID: album.ID, Format: FormatLP, MediaGrade: "G", SleeveGrade: "G", Photos: Photos{}, Price: money.MustParse("4.00"), Currency: "GBP"})
+	require.NoError(t, err)
+
+	items, err := repo.List(UsedItemFilter{AlbumID: album.ID})
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	require.Equal(t, good.ID, items[0].ID)
+	items, err = repo.List(UsedItemFilter{AlbumID: album.ID, MinMediaGrade: "VG"})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	worn.Price = money.MustParse("3.50")
+	updated, err := repo.Update(worn)
+	require.NoError(t, err)
+	require.Equal(t, money.MustParse("3.50"), updated.Price)
+
+	sold, err := repo.SetStatus(good.ID, UsedItemAvailable, UsedItemSold)
+	require.NoError(t, err)
+	require.Equal(t, UsedItemSold, sold.Status)
+	_, err = repo.SetStatus(good.ID, UsedItemAvailable, UsedItemSold)
+	require.True(t, errors.Is(err, ErrUsedItemStatusChanged))
+	items, err = repo.List(UsedItemFilter{AlbumID: album.ID, Status: UsedItemAvailable})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	require.NoError(t, repo.Delete(worn.ID))
+	_, err = repo.GetByID(worn.ID)
+	require.True(t, errors.Is(err, ErrUsedItemNotFound))
+}
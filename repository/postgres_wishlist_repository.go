@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PostgresWishlistRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresWishlistRepository(db *sqlx.DB) *PostgresWishlistRepository {
+	return &PostgresWishlistRepository{db: db}
+}
+
+func (r *PostgresWishlistRepository) Get(userID string) (Wishlist, error) {
+	wishlist := Wishlist{UserID: userID}
+	err := r.db.Get(&wishlist, "SELECT user_id, COALESCE(share_token, '') AS share_token, updated_at FROM wishlists WHERE user_id = $1", userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return Wishlist{}, err
+	}
+	return wishlist, r.loadItems(&wishlist)
+}
+
+func (r *PostgresWishlistRepository) GetByShareToken(token string) (Wishlist, error) {
+	var wishlist Wishlist
+	err := r.db.Get(&wishlist, "SELECT user_id, share_token, updated_at FROM wishlists WHERE share_token = $1", token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Wishlist{}, ErrWishlistNotFound
+	}
+	if err != nil {
+		return Wishlist{}, err
+	}
+	return wishlist, r.loadItems(&wishlist)
+}
+
+func (r *PostgresWishlistRepository) loadItems(wishlist *Wishlist) error {
+	wishlist.Items = []WishlistItem{}
+	return r.db.Select(&wishlist.Items,
+		"SELECT album_id, note, added_at FROM wishlist_items WHERE user_id = $1 ORDER BY added_at DESC",
+		wishlist.UserID,
+	)
+}
+
+func (r *PostgresWishlistRepository) AddItem(userID string, item WishlistItem) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec(
+		"INSERT INTO wishlists (user_id) VALUES ($1) ON CONFLICT (user_id) DO UPDATE SET updated_at = now()",
+		userID,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO wishlist_items (user_id, album_id, note) VALUES ($1, $2, $3)
+		 ON CONFLICT (user_id, album_id) DO UPDATE SET note = EXCLUDED.note`,
+		userID, item.AlbumID, item.Note,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *PostgresWishlistRepository) RemoveItem(userID, albumID string) error {
+	if !validAlbumID(albumID) {
+		return ErrWishlistItemNotFound
+	}
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec("DELETE FROM wishlist_items WHERE user_id = $1 AND album_id = $2", userID, albumID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrWishlistItemNotFound
+	}
+	if _, err := tx.Exec("UPDATE wishlists SET updated_at = now() WHERE user_id = $1", userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *PostgresWishlistRepository) SetShareToken(userID, token string) error {
+	_, err := r.db.Exec(
+		`INSERT INTO wishlists (user_id, share_token) VALUES ($1, NULLIF($2, ''))
+		 ON CONFLICT (user_id) DO UPDATE SET share_token = EXCLUDED.share_token, updated_at = now()`,
+		userID, token,
+	)
+	return err
+}
+
+func (r *PostgresWishlistRepository) AlbumsByUser() (map[string][]string, error) {
+	rows, err := r.db.Query("SELECT user_id, album_id::text FROM wishlist_items")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	albums := make(map[string][]string)
+	for rows.Next() {
+		var userID, albumID string
+		if err := rows.Scan(&userID, &albumID); err != nil {
+			return nil, err
+		}
+		albums[userID] = append(albums[userID], albumID)
+	}
+	return albums, rows.Err()
+}
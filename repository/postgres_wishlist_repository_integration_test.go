@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestPostgresWishlistRepository tests saving, re-saving and removing albums, and sharing a wishlist by token.
+func TestPostgresWishlistRepository(t *testing.T) {
+	db, teardown := setupTestPostgres(t)
+	defer teardown()
+	albumID := createTestPostgresAlbum(t, NewPostgresAlbumRepository(db))
+	repo := NewPostgresWishlistRepository(db)
+
+	wishlist, err := repo.Get("user-1")
+	require.NoError(t, err)
+	require.Empty(t, wishlist.Items)
+
+	require.NoError(t, repo.AddItem("user-1", WishlistItem{AlbumID: albumID, Note: "LP"}))
+	require.NoError(t, repo.AddItem("user-1", WishlistItem{AlbumID: albumID, Note: "LP or CD"}))
+	wishlist, err = repo.Get("user-1")
+	require.NoError(t, err)
+	require.Len(t, wishlist.Items, 1)
+	require.Equal(t, albumID, wishlist.Items[0].AlbumID)
+	require.Equal(t, "LP or CD", wishlist.Items[0].Note)
+
+	require.NoError(t, repo.SetShareToken("user-1", "token-1"))
+	shared, err := repo.GetByShareToken("token-1")
+	require.NoError(t, err)
+	require.Equal(t, "user-1", shared.UserID)
+	require.Len(t, shared.Items, 1)
+
+	require.NoError(t, repo.SetShareToken("user-1", ""))
+	_, err = repo.GetByShareToken("token-1")
+	require.True(t, errors.Is(err, ErrWishlistNotFound))
+
+	require.NoError(t, repo.RemoveItem("user-1", albumID))
+	err = repo.RemoveItem("user-1", albumID)
+	require.True(t, errors.Is(err, ErrWishlistItemNotFound))
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+var (
+	// ErrScheduledPriceNotFound is returned when a scheduled price change does not exist.
+	ErrScheduledPriceNotFound = errors.New("scheduled price change not found")
+	// ErrScheduleStatusChanged is returned when a scheduled price change is no longer in the expected status,
+	// for example because it was cancelled or another worker has already applied it.
+	ErrScheduleStatusChanged = errors.New("scheduled price change status has changed")
+)
+
+// Sources of a price change.
+const (
+	PriceSourceManual    = "manual"
+	PriceSourceScheduled = "scheduled"
+)
+
+// PriceChange records one change to an album's price and who made it.
+type PriceChange struct {
+	ID        string       `db:"id" json:"id"`
+	AlbumID   string       `db:"album_id" json:"albumId"`
+	OldPrice  money.Amount `db:"old_price" json:"oldPrice"`
+	NewPrice  money.Amount `db:"new_price" json:"newPrice"`
+	Source    string       `db:"source" json:"source"`
+	Actor     string       `db:"actor" json:"actor"`
+	Note      string       `db:"note" json:"note,omitempty"`
+	ChangedAt time.Time    `db:"changed_at" json:"changedAt"`
+}
+
+// ScheduleStatus is a stage in a scheduled price change's lifecycle.
+type ScheduleStatus string
+
+const (
+	SchedulePending   ScheduleStatus = "pending"
+	ScheduleApplied   ScheduleStatus = "applied"
+	ScheduleCancelled ScheduleStatus = "cancelled"
+	ScheduleFailed    ScheduleStatus = "failed"
+)
+
+// ScheduledPrice is a price change that takes effect at EffectiveAt.
+type ScheduledPrice struct {
+	ID          string         `db:"id" json:"id"`
+	AlbumID     string         `db:"album_id" json:"albumId"`
+	Price       money.Amount   `db:"price" json:"price"`
+	EffectiveAt time.Time      `db:"effective_at" json:"effectiveAt"`
+	Status      ScheduleStatus `db:"status" json:"status"`
+	Actor       string         `db:"actor" json:"actor"`
+	Note        string         `db:"note" json:"note,omitempty"`
+	CreatedAt   time.Time      `db:"created_at" json:"createdAt"`
+	UpdatedAt   time.Time      `db:"updated_at" json:"updatedAt"`
+}
+
+// sortScheduledPrices orders changes by when they take effect.
+func sortScheduledPrices(scheduled []ScheduledPrice) {
+	sort.SliceStable(scheduled, func(i, j int) bool {
+		return scheduled[i].EffectiveAt.Before(scheduled[j].EffectiveAt)
+	})
+}
+
+// PriceRepository stores the price history of each album and its scheduled price changes.
+// ListChanges returns the newest change first; ListScheduled returns changes in effective order.
+// Due returns pending changes whose effective time has passed. SetScheduleStatus moves a change
+// from one status to another only if it is still in the from status, failing with
+// ErrScheduleStatusChanged otherwise, so two workers can never both apply the same change.
+type PriceRepository interface {
+	RecordChange(change PriceChange) (PriceChange, error)
+	ListChanges(albumID string) ([]PriceChange, error)
+	Schedule(scheduled ScheduledPrice) (ScheduledPrice, error)
+	GetScheduled(id string) (ScheduledPrice, error)
+	ListScheduled(albumID string) ([]ScheduledPrice, error)
+	Due(now time.Time) ([]ScheduledPrice, error)
+	SetScheduleStatus(id string, from, to ScheduleStatus) error
+}
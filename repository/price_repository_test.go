@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSortScheduledPrices tests that scheduled changes are ordered by effective time
+func TestSortScheduledPrices(t *testing.T) {
+	now := time.Now()
+	scheduled := []ScheduledPrice{{ID: "b", EffectiveAt: now.Add(time.Hour)}, {ID: "a", EffectiveAt: now}}
+
+	sortScheduledPrices(scheduled)
+
+	assert.Equal(t, "a", scheduled[0].ID)
+	assert.Equal(t, "b", scheduled[1].ID)
+}
+
+// TestCassandraPriceRepository_InvalidUUID tests that malformed IDs are handled before querying
+func TestCassandraPriceRepository_InvalidUUID(t *testing.T) {
+	repo := NewCassandraPriceRepository(nil)
+
+	_, err := repo.GetScheduled("invalid-uuid")
+	assert.True(t, errors.Is(err, ErrScheduledPriceNotFound))
+
+	changes, err := repo.ListChanges("invalid-uuid")
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	_, err = repo.RecordChange(PriceChange{AlbumID: "invalid-uuid"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+var (
+	// ErrPromotionNotFound is returned when a promotion or coupon code does not exist.
+	ErrPromotionNotFound = errors.New("promotion not found")
+	// ErrDuplicateCode is returned when creating a promotion whose coupon code is already in use.
+	ErrDuplicateCode = errors.New("coupon code already in use")
+	// ErrPromotionExhausted is returned when redeeming a promotion that has reached its usage limit.
+	ErrPromotionExhausted = errors.New("promotion usage limit reached")
+)
+
+// DiscountKind is how a promotion reduces the price.
+type DiscountKind string
+
+const (
+	// DiscountPercentage takes Value percent off each unit.
+	DiscountPercentage DiscountKind = "percentage"
+	// DiscountFixed takes Value off each unit, never going below zero.
+	DiscountFixed DiscountKind = "fixed"
+	// DiscountBOGO makes every second unit of the same album and format free.
+	DiscountBOGO DiscountKind = "bogo"
+)
+
+// PromotionScope limits which albums a promotion applies to. Every criterion that is set must match;
+// within a list any entry may match. An empty scope covers the whole catalogue.
+type PromotionScope struct {
+	Genres   []string `json:"genres,omitempty"`
+	Artists  []string `json:"artists,omitempty"`
+	YearFrom int      `json:"yearFrom,omitempty"`
+	YearTo   int      `json:"yearTo,omitempty"`
+	AlbumIDs []string `json:"albumIds,omitempty"`
+}
+
+// Matches reports whether the album falls within the scope. A genre in the scope includes its
+// sub-genres in genres, which may be nil.
+func (s PromotionScope) Matches(album Album, genres *GenreTree) bool {
+	if len(s.Genres) > 0 && !slices.ContainsFunc(s.Genres, func(genre string) bool { return inGenre(genres, genre, album.Genre) }) {
+		return false
+	}
+	if len(s.Artists) > 0 && !containsFold(s.Artists, album.Artist) {
+		return false
+	}
+	if s.YearFrom != 0 && album.Year < s.YearFrom {
+		return false
+	}
+	if s.YearTo != 0 && album.Year > s.YearTo {
+		return false
+	}
+	if len(s.AlbumIDs) > 0 && !slices.Contains(s.AlbumIDs, album.ID) {
+		return false
+	}
+	return true
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), strings.TrimSpace(s)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Value stores the scope as JSON.
+func (s PromotionScope) Value() (driver.Value, error) {
+	b, err := json.Marshal(s)
+	return string(b), err
+}
+
+// Scan reads a scope stored as JSON.
+func (s *PromotionScope) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	case nil:
+		*s = PromotionScope{}
+		return nil
+	}
+	return fmt.Errorf("cannot scan %T into PromotionScope", src)
+}
+
+// Promotion is a discount rule. Promotions without a Code apply automatically; those with one
+// apply only to carts the code has been entered on. A UsageLimit of zero means unlimited.
+type Promotion struct {
+	ID         string         `db:"id" json:"id"`
+	Name       string         `db:"name" json:"name"`
+	Code       string         `db:"code" json:"code,omitempty"`
+	Kind       DiscountKind   `db:"kind" json:"kind"`
+	Value      money.Amount   `db:"value" json:"value"`
+	Scope      PromotionScope `db:"scope" json:"scope"`
+	StartsAt   *time.Time     `db:"starts_at" json:"startsAt,omitempty"`
+	EndsAt     *time.Time     `db:"ends_at" json:"endsAt,omitempty"`
+	UsageLimit int            `db:"usage_limit" json:"usageLimit"`
+	UsageCount int            `db:"usage_count" json:"usageCount"`
+	Active     bool           `db:"active" json:"active"`
+	CreatedAt  time.Time      `db:"created_at" json:"createdAt"`
+}
+
+// NormalizeCode puts a coupon code into the form it is stored and looked up in.
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Validate checks the promotion's rule is well formed.
+func (p Promotion) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return errors.New("name is required")
+	}
+	switch p.Kind {
+	case DiscountPercentage:
+		if p.Value <= 0 || p.Value > money.MustParse("100") {
+			return errors.New("percentage must be between 0 and 100")
+		}
+	case DiscountFixed:
+		if p.Value <= 0 {
+			return errors.New("fixed discount must be positive")
+		}
+	case DiscountBOGO:
+	default:
+		return fmt.Errorf("invalid discount kind %q", p.Kind)
+	}
+	if p.Scope.YearFrom != 0 && p.Scope.YearTo != 0 && p.Scope.YearFrom > p.Scope.YearTo {
+		return errors.New("yearFrom must not be after yearTo")
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		return errors.New("endsAt must be after startsAt")
+	}
+	if p.UsageLimit < 0 {
+		return errors.New("usageLimit cannot be negative")
+	}
+	return nil
+}
+
+// AvailableAt reports whether the promotion can be used at the given time: it is active,
+// within its validity window and below its usage limit.
+func (p Promotion) AvailableAt(now time.Time) bool {
+	if !p.Active {
+		return false
+	}
+	if p.StartsAt != nil && now.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && !now.Before(*p.EndsAt) {
+		return false
+	}
+	return p.UsageLimit == 0 || p.UsageCount < p.UsageLimit
+}
+
+// sortPromotions orders promotions oldest first, the order List returns them in.
+func sortPromotions(promotions []Promotion) {
+	sort.Slice(promotions, func(i, j int) bool {
+		return promotions[i].CreatedAt.Before(promotions[j].CreatedAt)
+	})
+}
+
+// PromotionRepository stores promotions. Coupon codes are unique and stored normalised.
+// Redeem counts one use, failing with ErrPromotionExhausted once the limit is reached,
+// and Unredeem gives a use back when the order it was counted for could not be placed.
+// Update changes everything except the code and usage count.
+type PromotionRepository interface {
+	Create(promotion Promotion) (Promotion, error)
+	GetByID(id string) (Promotion, error)
+	GetByCode(code string) (Promotion, error)
+	List() ([]Promotion, error)
+	Update(promotion Promotion) (Promotion, error)
+	Delete(id string) error
+	Redeem(id string) error
+	Unredeem(id string) error
+}
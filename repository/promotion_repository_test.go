@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestPromotionScope_Matches tests that every criterion set must match
+func TestPromotionScope_Matches(t *testing.T) {
+	album := Album{ID: "7", Artist: "Marvin Gaye", Genre: "Motown", Year: 1971}
+	testCases := []struct {
+		name     string
+		scope    PromotionScope
+		expected bool
+	}{
+		{"empty scope", PromotionScope{}, true},
+		{"genre is case-insensitive", PromotionScope{Genres: []string{"motown"}}, true},
+		{"other genre", PromotionScope{Genres: []string{"Jazz"}}, false},
+		{"artist and year range", PromotionScope{Artists: []string{"Marvin Gaye"}, YearFrom: 1970, YearTo: 1979}, true},
+		{"outside year range", PromotionScope{YearFrom: 1980}, false},
+		{"specific albums", PromotionScope{AlbumIDs: []string{"3", "7"}}, true},
+		{"genre matches but album does not", PromotionScope{Genres: []string{"Motown"}, AlbumIDs: []string{"3"}}, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.scope.Matches(album, nil))
+		})
+	}
+}
+
+// TestPromotionScope_MatchesSubGenres tests that a genre in the scope includes its sub-genres
+func TestPromotionScope_MatchesSubGenres(t *testing.T) {
+	tree := NewGenreTree([]Genre{{ID: "soul", Name: "Soul", Aliases: Aliases{"R&B/Soul"}}, {ID: "motown", Name: "Motown", ParentID: "soul"}})
+	motown := Album{ID: "7", Genre: "Motown"}
+	scope := PromotionScope{Genres: []string{"R&B/Soul"}}
+
+	assert.True(t, scope.Matches(motown, tree))
+	assert.False(t, scope.Matches(motown, nil), "without the taxonomy only the genre itself matches")
+	assert.False(t, PromotionScope{Genres: []string{"Motown"}}.Matches(Album{Genre: "Soul"}, tree), "a sub-genre does not include its parent")
+}
+
+// TestPromotionScope_ValueScan tests the scope round-trips through its JSON column
+func TestPromotionScope_ValueScan(t *testing.T) {
+	scope := PromotionScope{Genres: []string{"Motown"}, YearFrom: 1960}
+	value, err := scope.Value()
+	require.NoError(t, err)
+
+	var scanned PromotionScope
+	require.NoError(t, scanned.Scan([]byte(value.(string))))
+	assert.Equal(t, scope, scanned)
+}
+
+// TestPromotion_AvailableAt tests the validity window, active flag and usage limit
+func TestPromotion_AvailableAt(t *testing.T) {
+	now := time.Date(2025, 6, 14, 12, 0, 0, 0, time.UTC)
+	start, end := now.Add(-time.Hour), now.Add(time.Hour)
+
+	assert.True(t, Promotion{Active: true, StartsAt: &start, EndsAt: &end}.AvailableAt(now))
+	assert.False(t, Promotion{Active: false}.AvailableAt(now))
+	assert.False(t, Promotion{Active: true, StartsAt: &end}.AvailableAt(now))
+	assert.False(t, Promotion{Active: true, EndsAt: &now}.AvailableAt(now), "the end time is exclusive")
+	assert.False(t, Promotion{Active: true, UsageLimit: 5, UsageCount: 5}.AvailableAt(now))
+	assert.True(t, Promotion{Active: true, UsageLimit: 5, UsageCount: 4}.AvailableAt(now))
+}
+
+// TestPromotion_Validate tests rule validation
+func TestPromotion_Validate(t *testing.T) {
+	assert.NoError(t, Promotion{Name: "BOGO", Kind: DiscountBOGO}.Validate())
+	assert.NoError(t, Promotion{Name: "20%", Kind: DiscountPercentage, Value: money.MustParse("20")}.Validate())
+	assert.Error(t, Promotion{Kind: DiscountBOGO}.Validate(), "name is required")
+	assert.Error(t, Promotion{Name: "x", Kind: DiscountPercentage, Value: money.MustParse("0")}.Validate())
+	assert.Error(t, Promotion{Name: "x", Kind: DiscountFixed, Value: money.MustParse("5"), UsageLimit: -1}.Validate())
+}
+
+// TestCassandraPromotionRepository_InvalidUUID tests that malformed IDs are reported as not found
+func TestCassandraPromotionRepository_InvalidUUID(t *testing.T) {
+	repo := NewCassandraPromotionRepository(nil)
+
+	_, err := repo.GetByID("invalid-uuid")
+	assert.True(t, errors.Is(err, ErrPromotionNotFound))
+
+	err = repo.Redeem("invalid-uuid")
+	assert.True(t, errors.Is(err, ErrPromotionNotFound))
+}
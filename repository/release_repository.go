@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// ErrReleaseNotFound is returned when an album has no release with the given ID.
+var ErrReleaseNotFound = errors.New("release not found")
+
+// Release is one edition of an album in one format, such as the original 1971 LP of "What's Going
+// On" or its 40th anniversary CD. An album groups its releases; albums without any are sold as
+// they are.
+type Release struct {
+	ID      string `db:"id" json:"id"`
+	AlbumID string `db:"album_id" json:"albumId"`
+	Format  Format `db:"format" json:"format"`
+
+	// Edition tells releases in the same format apart, such as "Original" or "2021 Reissue".
+	Edition string `db:"edition" json:"edition,omitempty"`
+	Year    int    `db:"year" json:"year"`
+
+	// LabelID, Label, CatalogueNumber and Barcode identify the release as they do an album. Barcodes
+	// are unique across releases; catalogue numbers are not, as reissues often keep the original's.
+	LabelID         string `db:"label_id" json:"labelId,omitempty"`
+	Label           string `db:"label" json:"label,omitempty"`
+	CatalogueNumber string `db:"catalogue_number" json:"catalogueNumber,omitempty"`
+	Barcode         string `db:"barcode" json:"barcode,omitempty"`
+
+	Price    money.Amount `db:"price" json:"price"`
+	Currency string       `db:"currency" json:"currency"`
+
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// Validate checks the release has a format the shop stocks, a year and a price that is not negative.
+func (r Release) Validate() error {
+	if _, err := ParseFormat(string(r.Format)); err != nil {
+		return err
+	}
+	if r.Year <= 0 {
+		return errors.New("year is required")
+	}
+	if r.Price < 0 {
+		return errors.New("price cannot be negative")
+	}
+	return nil
+}
+
+// ForRelease returns the album as one of its releases: with the release's format, edition, year,
+// label, identifiers and price in place of the album's own. Prices set by hand for other
+// currencies are the album's, so they are dropped.
+func (a Album) ForRelease(release Release) Album {
+	a.ReleaseID = release.ID
+	a.Format = release.Format
+	a.Edition = release.Edition
+	a.Year = release.Year
+	a.LabelID, a.Label = release.LabelID, release.Label
+	a.CatalogueNumber, a.Barcode = release.CatalogueNumber, release.Barcode
+	a.Price, a.Currency = release.Price, release.Currency
+	a.CurrencyPrices = nil
+	a.Releases = nil
+	return a
+}
+
+// sortReleases orders releases oldest first, then by format in display order and by edition.
+func sortReleases(releases []Release) {
+	position := make(map[Format]int, len(Formats))
+	for i, format := range Formats {
+		position[format] = i
+	}
+	sort.SliceStable(releases, func(i, j int) bool {
+		a, b := releases[i], releases[j]
+		if a.Year != b.Year {
+			return a.Year < b.Year
+		}
+		if a.Format != b.Format {
+			return position[a.Format] < position[b.Format]
+		}
+		return strings.ToLower(a.Edition) < strings.ToLower(b.Edition)
+	})
+}
+
+type ReleaseRepository interface {
+	// Create adds a release to an album, failing with ErrDuplicateBarcode if another release or an
+	// album already has its barcode.
+	Create(release Release) (Release, error)
+	GetByID(albumID, id string) (Release, error)
+	// GetByBarcode returns the release with the barcode, or ErrReleaseNotFound if there is none.
+	GetByBarcode(barcode string) (Release, error)
+	// ListByAlbum returns the album's releases, oldest first.
+	ListByAlbum(albumID string) ([]Release, error)
+	// List returns every release of every album.
+	List() ([]Release, error)
+	Update(release Release) (Release, error)
+	Delete(albumID, id string) error
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestReleaseValidate tests that releases need a stocked format, a year and a price that is not negative.
+func TestReleaseValidate(t *testing.T) {
+	valid := Release{Format: FormatLP, Year: 1971, Price: money.MustParse("24.99")}
+	assert.NoError(t, valid.Validate())
+
+	for name, release := range map[string]Release{
+		"unknown format": {Format: "8-track", Year: 1971, Price: money.MustParse("24.99")},
+		"no year":        {Format: FormatLP, Price: money.MustParse("24.99")},
+		"negative price": {Format: FormatLP, Year: 1971, Price: money.MustParse("-1")},
+	} {
+		assert.Error(t, release.Validate(), name)
+	}
+}
+
+// TestAlbumForRelease tests that an album shown as one of its releases takes the release's details.
+func TestAlbumForRelease(t *testing.T) {
+	album := Album{
+		ID: "7", Title: "What's Going On", Year: 1971, Label: "Tamla", Price: money.MustParse("9.99"), Currency: "GBP",
+		CurrencyPrices: CurrencyPrices{"USD": money.MustParse("12.99")},
+		Releases:       []Release{{ID: "r1"}, {ID: "r2"}},
+	}
+	release := Release{ID: "r2", AlbumID: "7", Format: FormatCD, Edition: "40th Anniversary", Year: 2011,
+		Label: "Motown", Barcode: "0602527736211", Price: money.MustParse("14.99"), Currency: "GBP"}
+
+	entry := album.ForRelease(release)
+
+	assert.Equal(t, "7", entry.ID)
+	assert.Equal(t, "What's Going On", entry.Title)
+	assert.Equal(t, "r2", entry.ReleaseID)
+	assert.Equal(t, FormatCD, entry.Format)
+	assert.Equal(t, "40th Anniversary", entry.Edition)
+	assert.Equal(t, 2011, entry.Year)
+	assert.Equal(t, "Motown", entry.Label)
+	assert.Equal(t, "0602527736211", entry.Barcode)
+	assert.Equal(t, money.MustParse("14.99"), entry.Price)
+	assert.Empty(t, entry.CurrencyPrices, "prices set for the album do not apply to the release")
+	assert.Empty(t, entry.Releases)
+	assert.Len(t, album.Releases, 2, "the album itself is unchanged")
+}
+
+// TestSortReleases tests that releases are ordered by year, then format, then edition.
+func TestSortReleases(t *testing.T) {
+	releases := []Release{
+		{ID: "reissue", Format: FormatLP, Edition: "2021 Reissue", Year: 2021},
+		{ID: "cd", Format: FormatCD, Year: 1971},
+		{ID: "deluxe", Format: FormatLP, Edition: "deluxe", Year: 1971},
+		{ID: "original", Format: FormatLP, Edition: "Canadian", Year: 1971},
+	}
+
+	sortReleases(releases)
+
+	var ids []string
+	for _, release := range releases {
+		ids = append(ids, release.ID)
+	}
+	assert.Equal(t, []string{"original", "deluxe", "cd", "reissue"}, ids)
+}
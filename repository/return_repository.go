@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+var (
+	// ErrReturnNotFound is returned when a return does not exist.
+	ErrReturnNotFound = errors.New("return not found")
+	// ErrReturnStatusChanged is returned when a return is no longer in the status an update expected,
+	// for example because another member of staff has already approved or refunded it.
+	ErrReturnStatusChanged = errors.New("return status has changed")
+)
+
+// ReturnStatus is a stage in a return's lifecycle.
+type ReturnStatus string
+
+const (
+	ReturnRequested ReturnStatus = "requested"
+	ReturnApproved  ReturnStatus = "approved"
+	ReturnRejected  ReturnStatus = "rejected"
+	ReturnReceived  ReturnStatus = "received"
+	ReturnRefunded  ReturnStatus = "refunded"
+	ReturnCancelled ReturnStatus = "cancelled"
+)
+
+// returnTransitions lists the statuses each status may move to. Rejected, refunded and cancelled are final.
+var returnTransitions = map[ReturnStatus][]ReturnStatus{
+	ReturnRequested: {ReturnApproved, ReturnRejected, ReturnCancelled},
+	ReturnApproved:  {ReturnReceived, ReturnCancelled},
+	ReturnReceived:  {ReturnRefunded},
+}
+
+// ParseReturnStatus validates a status supplied by a client.
+func ParseReturnStatus(s string) (ReturnStatus, error) {
+	status := ReturnStatus(strings.ToLower(strings.TrimSpace(s)))
+	switch status {
+	case ReturnRequested, ReturnApproved, ReturnRejected, ReturnReceived, ReturnRefunded, ReturnCancelled:
+		return status, nil
+	}
+	return "", fmt.Errorf("invalid return status %q", s)
+}
+
+// CanTransitionTo reports whether a return in status s may move to next.
+func (s ReturnStatus) CanTransitionTo(next ReturnStatus) bool {
+	for _, allowed := range returnTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Open reports whether the return still counts against the quantities that can be returned.
+func (s ReturnStatus) Open() bool {
+	return s != ReturnRejected && s != ReturnCancelled
+}
+
+// ReturnReason records why the customer is sending an item back.
+type ReturnReason string
+
+const (
+	ReturnScratched        ReturnReason = "scratched"
+	ReturnWarped           ReturnReason = "warped"
+	ReturnDamagedInTransit ReturnReason = "damaged-in-transit"
+	ReturnWrongItem        ReturnReason = "wrong-item"
+	ReturnNotAsDescribed   ReturnReason = "not-as-described"
+	ReturnChangedMind      ReturnReason = "changed-mind"
+	ReturnOther            ReturnReason = "other"
+)
+
+// ReturnReasons lists the reason codes accepted for returns.
+var ReturnReasons = []ReturnReason{ReturnScratched, ReturnWarped, ReturnDamagedInTransit, ReturnWrongItem, ReturnNotAsDescribed, ReturnChangedMind, ReturnOther}
+
+// ParseReturnReason validates a reason code supplied by a customer.
+func ParseReturnReason(s string) (ReturnReason, error) {
+	for _, r := range ReturnReasons {
+		if strings.EqualFold(strings.TrimSpace(s), string(r)) {
+			return r, nil
+		}
+	}
+	return "", fmt.Errorf("invalid reason %q", s)
+}
+
+// Restock says what happens to a returned item once it is back in the shop.
+type Restock string
+
+const (
+	// RestockNew puts the item back into stock to be sold as new.
+	RestockNew Restock = "new"
+	// RestockUsed keeps the item to be sold second-hand at the grade it was given.
+	RestockUsed Restock = "used"
+	// RestockNone writes the item off.
+	RestockNone Restock = "none"
+)
+
+// ParseRestock validates a restock choice supplied by staff.
+func ParseRestock(s string) (Restock, error) {
+	restock := Restock(strings.ToLower(strings.TrimSpace(s)))
+	switch restock {
+	case RestockNew, RestockUsed, RestockNone:
+		return restock, nil
+	}
+	return "", fmt.Errorf("invalid restock %q: must be new, used or none", s)
+}
+
+// Grade is a condition grade on the Goldmine scale, used for second-hand media and sleeves.
+type Grade string
+
+// Grades lists the Goldmine grades from best to worst.
+var Grades = []Grade{"M", "NM", "VG+", "VG", "G+", "G", "F", "P"}
+
+// ParseGrade validates a grade such as "vg+" or "NM".
+func ParseGrade(s string) (Grade, error) {
+	for _, g := range Grades {
+		if strings.EqualFold(strings.TrimSpace(s), string(g)) {
+			return g, nil
+		}
+	}
+	return "", fmt.Errorf("invalid grade %q: must be one of M, NM, VG+, VG, G+, G, F, P", s)
+}
+
+// rank is the grade's position in Grades, 0 being the best. Unknown grades rank below them all.
+func (g Grade) rank() int {
+	for i, grade := range Grades {
+		if g == grade {
+			return i
+		}
+	}
+	return len(Grades)
+}
+
+// AtLeast reports whether the grade is min or better. Every grade is at least the empty grade.
+func (g Grade) AtLeast(min Grade) bool {
+	return min == "" || g.rank() <= min.rank()
+}
+
+// Return is a customer's request to send back some of the items from an order. RefundAmount is
+// what the returned items cost, tax included, and RefundedAmount how much of it has been paid back.
+type Return struct {
+	ID             string        `db:"id" json:"id"`
+	OrderID        string        `db:"order_id" json:"orderId"`
+	UserID         string        `db:"user_id" json:"userId"`
+	Status         ReturnStatus  `db:"status" json:"status"`
+	Lines          []ReturnLine  `db:"-" json:"lines"`
+	RefundAmount   money.Amount  `db:"refund_amount" json:"refundAmount"`
+	RefundedAmount money.Amount  `db:"refunded_amount" json:"refundedAmount"`
+	CreatedAt      time.Time     `db:"created_at" json:"createdAt"`
+	UpdatedAt      time.Time     `db:"updated_at" json:"updatedAt"`
+	History        []ReturnEvent `db:"-" json:"history,omitempty"`
+}
+
+// ReturnLine is a quantity of one order line being returned. Restock and the grades are filled in
+// by staff when the items arrive back; Restocked counts the units put back into stock or listed as
+// used so far.
+type ReturnLine struct {
+	AlbumID     string       `db:"album_id" json:"albumId"`
+	ReleaseID   string       `db:"release_id" json:"releaseId,omitempty"`
+	Format      Format       `db:"format" json:"format"`
+	Title       string       `db:"title" json:"title"`
+	Quantity    int          `db:"quantity" json:"quantity"`
+	Reason      ReturnReason `db:"reason" json:"reason"`
+	Note        string       `db:"note" json:"note,omitempty"`
+	Refund      money.Amount `db:"refund" json:"refund"`
+	Restock     Restock      `db:"restock" json:"restock,omitempty"`
+	MediaGrade  Grade        `db:"media_grade" json:"mediaGrade,omitempty"`
+	SleeveGrade Grade        `db:"sleeve_grade" json:"sleeveGrade,omitempty"`
+	Restocked   int          `db:"restocked" json:"restocked"`
+}
+
+// ReturnEvent records a status change and who made it.
+type ReturnEvent struct {
+	Status    ReturnStatus `db:"status" json:"status"`
+	Actor     string       `db:"actor" json:"actor"`
+	Note      string       `db:"note" json:"note,omitempty"`
+	CreatedAt time.Time    `db:"created_at" json:"createdAt"`
+}
+
+// ReturnRepository stores returns and their history. Update saves the return's status, the restock
+// details of its lines and the amount refunded, provided the return is still in status from; it fails
+// with ErrReturnStatusChanged otherwise, so two members of staff cannot both act on the same return.
+// SetRestocked moves the Restocked count of the line at index line from from to to, failing with
+// ErrReturnStatusChanged if it is no longer from, so no unit is restocked twice.
+type ReturnRepository interface {
+	Create(ret Return) (Return, error)
+	GetByID(id string) (Return, error)
+	ListByOrder(orderID string) ([]Return, error)
+	ListByUser(userID string) ([]Return, error)
+	List(status ReturnStatus) ([]Return, error)
+	Update(ret Return, from ReturnStatus, actor, note string) (Return, error)
+	SetRestocked(id string, line, from, to int) error
+}
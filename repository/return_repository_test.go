@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReturnStatus_CanTransitionTo tests the return state machine
+func TestReturnStatus_CanTransitionTo(t *testing.T) {
+	testCases := []struct {
+		from, to ReturnStatus
+		allowed  bool
+	}{
+		{ReturnRequested, ReturnApproved, true},
+		{ReturnRequested, ReturnRejected, true},
+		{ReturnRequested, ReturnCancelled, true},
+		{ReturnRequested, ReturnReceived, false},
+		{ReturnApproved, ReturnReceived, true},
+		{ReturnApproved, ReturnCancelled, true},
+		{ReturnApproved, ReturnRefunded, false},
+		{ReturnReceived, ReturnRefunded, true},
+		{ReturnReceived, ReturnCancelled, false},
+		{ReturnRejected, ReturnApproved, false},
+		{ReturnRefunded, ReturnReceived, false},
+	}
+	for _, tc := range testCases {
+		t.Run(string(tc.from)+"->"+string(tc.to), func(t *testing.T) {
+			assert.Equal(t, tc.allowed, tc.from.CanTransitionTo(tc.to))
+		})
+	}
+}
+
+// TestReturnStatus_Open tests that rejected and cancelled returns no longer count against the order
+func TestReturnStatus_Open(t *testing.T) {
+	assert.True(t, ReturnRequested.Open())
+	assert.True(t, ReturnRefunded.Open())
+	assert.False(t, ReturnRejected.Open())
+	assert.False(t, ReturnCancelled.Open())
+}
+
+// TestParseReturnReason tests reason parsing
+func TestParseReturnReason(t *testing.T) {
+	reason, err := ParseReturnReason(" Damaged-In-Transit ")
+	require.NoError(t, err)
+	assert.Equal(t, ReturnDamagedInTransit, reason)
+
+	_, err = ParseReturnReason("bored")
+	assert.Error(t, err)
+}
+
+// TestParseRestock tests restock parsing
+func TestParseRestock(t *testing.T) {
+	restock, err := ParseRestock("USED")
+	require.NoError(t, err)
+	assert.Equal(t, RestockUsed, restock)
+
+	_, err = ParseRestock("")
+	assert.Error(t, err)
+}
+
+// TestParseGrade tests that grades are matched case-insensitively against the Goldmine scale
+func TestParseGrade(t *testing.T) {
+	grade, err := ParseGrade("vg+")
+	require.NoError(t, err)
+	assert.Equal(t, Grade("VG+"), grade)
+
+	for _, s := range []string{"", "A", "VG++"} {
+		_, err = ParseGrade(s)
+		assert.Error(t, err, s)
+	}
+}
+
+// TestGrade_AtLeast tests comparing grades from mint down to poor
+func TestGrade_AtLeast(t *testing.T) {
+	assert.True(t, Grade("M").AtLeast("P"))
+	assert.True(t, Grade("VG+").AtLeast("VG+"))
+	assert.True(t, Grade("VG+").AtLeast("VG"))
+	assert.False(t, Grade("VG").AtLeast("VG+"))
+	assert.False(t, Grade("P").AtLeast("F"))
+	assert.True(t, Grade("P").AtLeast(""), "every grade is at least no grade")
+}
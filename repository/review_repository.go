@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	// ErrReviewNotFound is returned when a review does not exist.
+	ErrReviewNotFound = errors.New("review not found")
+	// ErrDuplicateReview is returned when a user reviews an album they have already reviewed.
+	ErrDuplicateReview = errors.New("you have already reviewed this album")
+	// ErrReviewStatusChanged is returned when a review is no longer in the status a moderation
+	// decision expected, for example because another moderator got there first.
+	ErrReviewStatusChanged = errors.New("review status has changed")
+)
+
+// MaxReviewLength is the longest review body accepted, in characters.
+const MaxReviewLength = 5000
+
+// ReviewStatus is where a review is in moderation. Only approved reviews are shown and counted.
+type ReviewStatus string
+
+const (
+	ReviewPending  ReviewStatus = "pending"
+	ReviewApproved ReviewStatus = "approved"
+	ReviewRejected ReviewStatus = "rejected"
+)
+
+// ParseReviewStatus validates a status supplied by a client.
+func ParseReviewStatus(s string) (ReviewStatus, error) {
+	status := ReviewStatus(strings.ToLower(strings.TrimSpace(s)))
+	switch status {
+	case ReviewPending, ReviewApproved, ReviewRejected:
+		return status, nil
+	}
+	return "", fmt.Errorf("invalid review status %q", s)
+}
+
+// Review is a customer's rating of an album, from 1 to 5 stars, with what they thought of it.
+// Each user can review an album once.
+type Review struct {
+	ID        string       `db:"id" json:"id"`
+	AlbumID   string       `db:"album_id" json:"albumId"`
+	UserID    string       `db:"user_id" json:"userId,omitempty"`
+	Author    string       `db:"author" json:"author"`
+	Rating    int          `db:"rating" json:"rating"`
+	Title     string       `db:"title" json:"title,omitempty"`
+	Body      string       `db:"body" json:"body"`
+	Status    ReviewStatus `db:"status" json:"status"`
+	CreatedAt time.Time    `db:"created_at" json:"createdAt"`
+	UpdatedAt time.Time    `db:"updated_at" json:"updatedAt"`
+}
+
+// Validate checks the rating is between 1 and 5 stars and the review has an author and a body
+// that is not too long.
+func (r Review) Validate() error {
+	if r.Rating < 1 || r.Rating > 5 {
+		return errors.New("rating must be between 1 and 5")
+	}
+	if strings.TrimSpace(r.Author) == "" {
+		return errors.New("author is required")
+	}
+	if strings.TrimSpace(r.Body) == "" {
+		return errors.New("body is required")
+	}
+	if utf8.RuneCountInString(r.Body) > MaxReviewLength {
+		return fmt.Errorf("body must be at most %d characters", MaxReviewLength)
+	}
+	return nil
+}
+
+// RatingSummary is the average rating and number of approved reviews of an album.
+type RatingSummary struct {
+	AlbumID string  `db:"album_id" json:"albumId"`
+	Count   int     `db:"review_count" json:"count"`
+	Average float64 `db:"average" json:"average"`
+}
+
+// summarise works out a summary from a count of reviews and the total of their ratings, rounding
+// the average to one decimal place as it is shown.
+func summarise(albumID string, count int, total int64) RatingSummary {
+	summary := RatingSummary{AlbumID: albumID, Count: count}
+	if count > 0 {
+		summary.Average = math.Round(float64(total)/float64(count)*10) / 10
+	}
+	return summary
+}
+
+// sortReviews orders reviews with the newest first.
+func sortReviews(reviews []Review) {
+	sort.SliceStable(reviews, func(i, j int) bool { return reviews[i].CreatedAt.After(reviews[j].CreatedAt) })
+}
+
+// ReviewRepository stores reviews and keeps the rating summaries of approved reviews up to date.
+// Create fails with ErrDuplicateReview if the user has already reviewed the album. SetStatus only
+// applies if the review is still in status from. An empty status lists reviews in every status.
+type ReviewRepository interface {
+	Create(review Review) (Review, error)
+	GetByID(id string) (Review, error)
+	ListByAlbum(albumID string, status ReviewStatus) ([]Review, error)
+	List(status ReviewStatus) ([]Review, error)
+	SetStatus(id string, from, to ReviewStatus) (Review, error)
+	Delete(id string) error
+	Ratings() ([]RatingSummary, error)
+}
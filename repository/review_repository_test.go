@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseReviewStatus tests status parsing
+func TestParseReviewStatus(t *testing.T) {
+	status, err := ParseReviewStatus(" Approved ")
+	require.NoError(t, err)
+	assert.Equal(t, ReviewApproved, status)
+
+	_, err = ParseReviewStatus("hidden")
+	assert.Error(t, err)
+}
+
+// TestReview_Validate tests that reviews need a rating from 1 to 5 stars, an author and a body of reasonable length
+func TestReview_Validate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		review Review
+		valid  bool
+	}{
+		{"five stars", Review{Rating: 5, Author: "Ann", Body: "A masterpiece."}, true},
+		{"one star", Review{Rating: 1, Author: "Ann", Body: "Not for me."}, true},
+		{"no stars", Review{Rating: 0, Author: "Ann", Body: "Hmm."}, false},
+		{"six stars", Review{Rating: 6, Author: "Ann", Body: "Off the scale."}, false},
+		{"no author", Review{Rating: 4, Author: " ", Body: "Good."}, false},
+		{"no body", Review{Rating: 4, Author: "Ann"}, false},
+		{"longest body", Review{Rating: 4, Author: "Ann", Body: strings.Repeat("é", MaxReviewLength)}, true},
+		{"body too long", Review{Rating: 4, Author: "Ann", Body: strings.Repeat("a", MaxReviewLength+1)}, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.review.Validate()
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+// TestSummarise tests that averages are rounded to one decimal place
+func TestSummarise(t *testing.T) {
+	assert.Equal(t, RatingSummary{AlbumID: "1", Count: 3, Average: 4.3}, summarise("1", 3, 13))
+	assert.Equal(t, RatingSummary{AlbumID: "1", Count: 2, Average: 4.5}, summarise("1", 2, 9))
+	assert.Equal(t, RatingSummary{AlbumID: "1"}, summarise("1", 0, 0))
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// ErrRevisionNotFound is returned when an album has no revision with the number looked up.
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// AlbumRevision is a snapshot of an album as it was saved by one edit. Revisions are numbered from
+// 1 for each album and are never changed once recorded.
+type AlbumRevision struct {
+	AlbumID   string        `db:"album_id" json:"albumId"`
+	Number    int           `db:"number" json:"number"`
+	Album     AlbumSnapshot `db:"snapshot" json:"album"`
+	Actor     string        `db:"actor" json:"actor"`
+	Note      string        `db:"note" json:"note,omitempty"`
+	CreatedAt time.Time     `db:"created_at" json:"createdAt"`
+
+	// RolledBackTo is the number of the earlier revision this one put the album back to, if it was
+	// made by a rollback.
+	RolledBackTo int `db:"rolled_back_to" json:"rolledBackTo,omitempty"`
+}
+
+// AlbumSnapshot holds the fields of an album staff edit, leaving out its ID, the trash fields and
+// everything the API layer fills in. It is stored as a JSON object.
+type AlbumSnapshot struct {
+	Title           string         `json:"title"`
+	Artist          string         `json:"artist"`
+	ArtistID        string         `json:"artistId"`
+	Price           money.Amount   `json:"price"`
+	Currency        string         `json:"currency"`
+	CurrencyPrices  CurrencyPrices `json:"currencyPrices"`
+	Year            int            `json:"year"`
+	ImageUrl        string         `json:"imageUrl"`
+	Genre           string         `json:"genre"`
+	LabelID         string         `json:"labelId"`
+	Label           string         `json:"label"`
+	CatalogueNumber string         `json:"catalogueNumber"`
+	Barcode         string         `json:"barcode"`
+	TaxCategory     string         `json:"taxCategory"`
+	Tags            Tags           `json:"tags"`
+}
+
+// SnapshotOf returns the edited fields of the album.
+func SnapshotOf(album Album) AlbumSnapshot {
+	return AlbumSnapshot{
+		Title:           album.Title,
+		Artist:          album.Artist,
+		ArtistID:        album.ArtistID,
+		Price:           album.Price,
+		Currency:        album.Currency,
+		CurrencyPrices:  album.CurrencyPrices,
+		Year:            album.Year,
+		ImageUrl:        album.ImageUrl,
+		Genre:           album.Genre,
+		LabelID:         album.LabelID,
+		Label:           album.Label,
+		CatalogueNumber: album.CatalogueNumber,
+		Barcode:         album.Barcode,
+		TaxCategory:     album.TaxCategory,
+		Tags:            album.Tags,
+	}
+}
+
+// Album returns the album with the given ID as the snapshot has it.
+func (s AlbumSnapshot) Album(id string) Album {
+	return Album{
+		ID:              id,
+		Title:           s.Title,
+		Artist:          s.Artist,
+		ArtistID:        s.ArtistID,
+		Price:           s.Price,
+		Currency:        s.Currency,
+		CurrencyPrices:  s.CurrencyPrices,
+		Year:            s.Year,
+		ImageUrl:        s.ImageUrl,
+		Genre:           s.Genre,
+		LabelID:         s.LabelID,
+		Label:           s.Label,
+		CatalogueNumber: s.CatalogueNumber,
+		Barcode:         s.Barcode,
+		TaxCategory:     s.TaxCategory,
+		Tags:            s.Tags,
+	}
+}
+
+// FieldChange is one field that differs between two snapshots, with its value in each as JSON.
+type FieldChange struct {
+	Field string          `json:"field"`
+	From  json.RawMessage `json:"from"`
+	To    json.RawMessage `json:"to"`
+}
+
+// Diff returns the fields whose values differ between the snapshot and another, by name. Empty and
+// missing currency prices and tags count as the same.
+func (s AlbumSnapshot) Diff(to AlbumSnapshot) ([]FieldChange, error) {
+	from, err := s.fields()
+	if err != nil {
+		return nil, err
+	}
+	other, err := to.fields()
+	if err != nil {
+		return nil, err
+	}
+	changes := []FieldChange{}
+	for field, value := range from {
+		if !bytes.Equal(value, other[field]) {
+			changes = append(changes, FieldChange{Field: field, From: value, To: other[field]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
+
+// fields returns each field of the snapshot as JSON, keyed by its name.
+func (s AlbumSnapshot) fields() (map[string]json.RawMessage, error) {
+	if len(s.CurrencyPrices) == 0 {
+		s.CurrencyPrices = CurrencyPrices{}
+	}
+	if s.Tags == nil {
+		s.Tags = Tags{}
+	}
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	return fields, json.Unmarshal(b, &fields)
+}
+
+// Value stores the snapshot as a JSON object.
+func (s AlbumSnapshot) Value() (driver.Value, error) {
+	b, err := json.Marshal(s)
+	return string(b), err
+}
+
+// Scan reads a snapshot stored as a JSON object.
+func (s *AlbumSnapshot) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, s)
+	case string:
+		return json.Unmarshal([]byte(v), s)
+	}
+	return fmt.Errorf("cannot scan %T into AlbumSnapshot", src)
+}
+
+// RevisionRepository stores the revisions of each album. Record numbers a revision one after the
+// album's latest, so numbers never repeat even when two edits are saved at once; the AlbumID,
+// Album, Actor, Note and RolledBackTo of the revision given are kept. List returns the newest
+// revision first.
+type RevisionRepository interface {
+	Record(revision AlbumRevision) (AlbumRevision, error)
+	List(albumID string) ([]AlbumRevision, error)
+	Get(albumID string, number int) (AlbumRevision, error)
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestAlbumSnapshot_RoundTrip tests that a snapshot keeps the edited fields of an album and nothing else.
+func TestAlbumSnapshot_RoundTrip(t *testing.T) {
+	inStock := true
+	album := Album{ID: "1", Title: "Thriller", Artist: "Michael Jackson", Price: money.MustParse("25.99"), Currency: "GBP",
+		Year: 1982, Genre: "Pop", Barcode: "0074643811224", Tags: Tags{"staff pick"}, InStock: &inStock}
+
+	snapshot := SnapshotOf(album)
+	value, err := snapshot.Value()
+	require.NoError(t, err)
+	var scanned AlbumSnapshot
+	require.NoError(t, scanned.Scan([]byte(value.(string))))
+
+	restored := scanned.Album("1")
+	album.InStock = nil
+	assert.Equal(t, album, restored)
+}
+
+// TestAlbumSnapshot_Diff tests that only changed fields are listed, by name.
+func TestAlbumSnapshot_Diff(t *testing.T) {
+	before := AlbumSnapshot{Title: "Thriller", Price: money.MustParse("25.99"), Year: 1982}
+	after := before
+	after.Title = "Thriller (Remastered)"
+	after.Price = money.MustParse("19.99")
+	after.Tags = Tags{}
+
+	changes, err := before.Diff(after)
+
+	require.NoError(t, err)
+	require.Len(t, changes, 2, "no tags and an empty list of tags are the same")
+	assert.Equal(t, "price", changes[0].Field)
+	assert.JSONEq(t, "25.99", string(changes[0].From))
+	assert.JSONEq(t, "19.99", string(changes[0].To))
+	assert.Equal(t, "title", changes[1].Field)
+
+	changes, err = before.Diff(before)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
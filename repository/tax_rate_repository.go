@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TaxRate is the VAT or sales tax charged on goods shipped to a country, optionally narrowed to a
+// region within it (a US state, say) and to a product category. Rate is a percentage.
+type TaxRate struct {
+	Country   string    `db:"country" json:"country"`
+	Region    string    `db:"region" json:"region,omitempty"`
+	Category  string    `db:"category" json:"category,omitempty"`
+	Name      string    `db:"name" json:"name"`
+	Rate      float64   `db:"rate" json:"rate"`
+	UpdatedAt time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// Validate checks the rate can be charged.
+func (r TaxRate) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("name is required for the %s rate", r.Key())
+	}
+	if r.Rate < 0 || r.Rate > 100 {
+		return fmt.Errorf("rate for %s must be between 0 and 100", r.Key())
+	}
+	return nil
+}
+
+// Key identifies the rate within the table, e.g. "US/CA/standard" or "GB".
+func (r TaxRate) Key() string {
+	key := r.Country
+	if r.Region != "" || r.Category != "" {
+		key += "/" + r.Region
+	}
+	if r.Category != "" {
+		key += "/" + r.Category
+	}
+	return key
+}
+
+// sortTaxRates orders rates by country, region and category, the order List returns them in.
+func sortTaxRates(rates []TaxRate) {
+	sort.Slice(rates, func(i, j int) bool {
+		if rates[i].Country != rates[j].Country {
+			return rates[i].Country < rates[j].Country
+		}
+		if rates[i].Region != rates[j].Region {
+			return rates[i].Region < rates[j].Region
+		}
+		return rates[i].Category < rates[j].Category
+	})
+}
+
+// TaxRateRepository stores the tax-rate table staff maintain. Replace swaps the whole table for the
+// rates given, so rates left out of an upload stop being charged.
+type TaxRateRepository interface {
+	List() ([]TaxRate, error)
+	Replace(rates []TaxRate) ([]TaxRate, error)
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTaxRate_Validate tests that unusable rates are rejected
+func TestTaxRate_Validate(t *testing.T) {
+	assert.NoError(t, TaxRate{Country: "GB", Name: "VAT", Rate: 20}.Validate())
+	assert.NoError(t, TaxRate{Country: "GB", Category: "books", Name: "VAT", Rate: 0}.Validate())
+	assert.Error(t, TaxRate{Country: "GB", Rate: 20}.Validate())
+	assert.Error(t, TaxRate{Country: "GB", Name: "VAT", Rate: -5}.Validate())
+	assert.Error(t, TaxRate{Country: "GB", Name: "VAT", Rate: 101}.Validate())
+}
+
+// TestTaxRate_Key tests that keys tell apart rates for a region and for a category
+func TestTaxRate_Key(t *testing.T) {
+	assert.Equal(t, "GB", TaxRate{Country: "GB"}.Key())
+	assert.Equal(t, "US/CA", TaxRate{Country: "US", Region: "CA"}.Key())
+	assert.Equal(t, "GB//books", TaxRate{Country: "GB", Category: "books"}.Key())
+	assert.Equal(t, "US/NY/books", TaxRate{Country: "US", Region: "NY", Category: "books"}.Key())
+}
+
+// TestSortTaxRates tests that rates are ordered by country, region and category
+func TestSortTaxRates(t *testing.T) {
+	rates := []TaxRate{{Country: "US", Region: "NY"}, {Country: "US", Region: "CA"}, {Country: "GB", Category: "books"}, {Country: "GB"}}
+
+	sortTaxRates(rates)
+
+	assert.Equal(t, "GB", rates[0].Key())
+	assert.Equal(t, "GB//books", rates[1].Key())
+	assert.Equal(t, "US/CA", rates[2].Key())
+	assert.Equal(t, "US/NY", rates[3].Key())
+}
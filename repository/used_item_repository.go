@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+var (
+	// ErrUsedItemNotFound is returned when a used item does not exist.
+	ErrUsedItemNotFound = errors.New("used item not found")
+	// ErrUsedItemStatusChanged is returned when a used item is no longer in the status a sale
+	// expected, for example because it has just been sold to someone else.
+	ErrUsedItemStatusChanged = errors.New("used item status has changed")
+)
+
+// MaxUsedItemPhotos is the most photos a used item can have.
+const MaxUsedItemPhotos = 12
+
+// UsedItemStatus says whether a used item can still be bought. Each item is a single copy, so it
+// is sold once.
+type UsedItemStatus string
+
+const (
+	UsedItemAvailable UsedItemStatus = "available"
+	UsedItemSold      UsedItemStatus = "sold"
+)
+
+// ParseUsedItemStatus validates a status supplied by a client.
+func ParseUsedItemStatus(s string) (UsedItemStatus, error) {
+	status := UsedItemStatus(strings.ToLower(strings.TrimSpace(s)))
+	switch status {
+	case UsedItemAvailable, UsedItemSold:
+		return status, nil
+	}
+	return "", fmt.Errorf("invalid used item status %q: must be available or sold", s)
+}
+
+// Photos are the URLs of pictures of a used item, stored as a JSON array.
+type Photos []string
+
+// Value stores the photos as a JSON array.
+func (p Photos) Value() (driver.Value, error) {
+	return Aliases(p).Value()
+}
+
+// Scan reads photos stored as a JSON array.
+func (p *Photos) Scan(src interface{}) error {
+	return (*Aliases)(p).Scan(src)
+}
+
+// UsedItem is one second-hand copy of an album, graded on the Goldmine scale and priced on its own.
+type UsedItem struct {
+	ID          string         `db:"id" json:"id"`
+	AlbumID     string         `db:"album_id" json:"albumId"`
+	Format      Format         `db:"format" json:"format"`
+	MediaGrade  Grade          `db:"media_grade" json:"mediaGrade"`
+	SleeveGrade Grade          `db:"sleeve_grade" json:"sleeveGrade"`
+	Notes       string         `db:"notes" json:"notes,omitempty"`
+	Photos      Photos         `db:"photos" json:"photos"`
+	Price       money.Amount   `db:"price" json:"price"`
+	Currency    string         `db:"currency" json:"currency"`
+	Status      UsedItemStatus `db:"status" json:"status"`
+	CreatedAt   time.Time      `db:"created_at" json:"createdAt"`
+	UpdatedAt   time.Time      `db:"updated_at" json:"updatedAt"`
+}
+
+// Validate checks the item has a format the shop stocks, both grades, a price that is not negative
+// and no more than MaxUsedItemPhotos photos, each a web address.
+func (i UsedItem) Validate() error {
+	if _, err := ParseFormat(string(i.Format)); err != nil {
+		return err
+	}
+	if _, err := ParseGrade(string(i.MediaGrade)); err != nil {
+		return fmt.Errorf("mediaGrade: %w", err)
+	}
+	if _, err := ParseGrade(string(i.SleeveGrade)); err != nil {
+		return fmt.Errorf("sleeveGrade: %w", err)
+	}
+	if i.Price < 0 {
+		return errors.New("price cannot be negative")
+	}
+	if len(i.Photos) > MaxUsedItemPhotos {
+		return fmt.Errorf("a used item can have at most %d photos", MaxUsedItemPhotos)
+	}
+	for _, photo := range i.Photos {
+		u, err := url.Parse(photo)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid photo %q: must be an http or https URL", photo)
+		}
+	}
+	return nil
+}
+
+// UsedItemFilter picks the used items to list. Zero fields match every item; grades are the worst
+// grade accepted.
+type UsedItemFilter struct {
+	AlbumID        string
+	Status         UsedItemStatus
+	MinMediaGrade  Grade
+	MinSleeveGrade Grade
+}
+
+// Matches reports whether the item passes the filter.
+func (f UsedItemFilter) Matches(item UsedItem) bool {
+	return (f.AlbumID == "" || item.AlbumID == f.AlbumID) &&
+		(f.Status == "" || item.Status == f.Status) &&
+		item.MediaGrade.AtLeast(f.MinMediaGrade) &&
+		item.SleeveGrade.AtLeast(f.MinSleeveGrade)
+}
+
+// sortUsedItems orders used items from the best media grade down, then by sleeve grade and by
+// price, cheapest first.
+func sortUsedItems(items []UsedItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.MediaGrade != b.MediaGrade {
+			return a.MediaGrade.rank() < b.MediaGrade.rank()
+		}
+		if a.SleeveGrade != b.SleeveGrade {
+			return a.SleeveGrade.rank() < b.SleeveGrade.rank()
+		}
+		return a.Price < b.Price
+	})
+}
+
+// UsedItemRepository stores used items. Update saves an item's details but not its status, which
+// only changes through SetStatus, provided the item is still in status from; it fails with
+// ErrUsedItemStatusChanged otherwise, so a copy cannot be sold twice.
+type UsedItemRepository interface {
+	Create(item UsedItem) (UsedItem, error)
+	GetByID(id string) (UsedItem, error)
+	// List returns the items passing the filter, ordered by sortUsedItems.
+	List(filter UsedItemFilter) ([]UsedItem, error)
+	Update(item UsedItem) (UsedItem, error)
+	SetStatus(id string, from, to UsedItemStatus) (UsedItem, error)
+	Delete(id string) error
+}
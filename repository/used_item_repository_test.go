@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+// TestUsedItemValidate tests that used items need a stocked format, both grades, a price that is
+// not negative and web addresses for photos
+func TestUsedItemValidate(t *testing.T) {
+	valid := UsedItem{Format: FormatLP, MediaGrade: "VG+", SleeveGrade: "VG", Price: money.MustParse("18"),
+		Photos: Photos{"https://example.com/front.jpg"}}
+	assert.NoError(t, valid.Validate())
+
+	tooMany := valid
+	tooMany.Photos = make(Photos, MaxUsedItemPhotos+1)
+	for i := range tooMany.Photos {
+		tooMany.Photos[i] = "https://example.com/photo.jpg"
+	}
+	for name, item := range map[string]UsedItem{
+		"unknown format":       {Format: "8-track", MediaGrade: "VG", SleeveGrade: "VG"},
+		"no media grade":       {Format: FormatLP, SleeveGrade: "VG"},
+		"unknown sleeve grade": {Format: FormatLP, MediaGrade: "VG", SleeveGrade: "Excellent"},
+		"negative price":       {Format: FormatLP, MediaGrade: "VG", SleeveGrade: "VG", Price: money.MustParse("-1")},
+		"local photo":          {Format: FormatLP, MediaGrade: "VG", SleeveGrade: "VG", Photos: Photos{"/tmp/front.jpg"}},
+		"too many photos":      tooMany,
+	} {
+		assert.Error(t, item.Validate(), name)
+	}
+}
+
+// TestUsedItemFilter tests matching used items by album, status and the worst grades accepted
+func TestUsedItemFilter(t *testing.T) {
+	item := UsedItem{AlbumID: "2", MediaGrade: "VG+", SleeveGrade: "G+", Status: UsedItemAvailable}
+
+	assert.True(t, UsedItemFilter{}.Matches(item))
+	assert.True(t, UsedItemFilter{AlbumID: "2", Status: UsedItemAvailable, MinMediaGrade: "VG+", MinSleeveGrade: "G"}.Matches(item))
+	assert.False(t, UsedItemFilter{AlbumID: "1"}.Matches(item))
+	assert.False(t, UsedItemFilter{Status: UsedItemSold}.Matches(item))
+	assert.False(t, UsedItemFilter{MinMediaGrade: "NM"}.Matches(item))
+	assert.False(t, UsedItemFilter{MinSleeveGrade: "VG"}.Matches(item))
+}
+
+// TestSortUsedItems tests that used items are ordered by media grade, then sleeve grade, then price
+func TestSortUsedItems(t *testing.T) {
+	items := []UsedItem{
+		{ID: "worn", MediaGrade: "G", SleeveGrade: "VG"},
+		{ID: "cheap", MediaGrade: "VG+", SleeveGrade: "VG", Price: money.MustParse("12")},
+		{ID: "dear", MediaGrade: "VG+", SleeveGrade: "VG", Price: money.MustParse("20")},
+		{ID: "sleeve", MediaGrade: "VG+", SleeveGrade: "NM", Price: money.MustParse("30")},
+		{ID: "mint", MediaGrade: "M", SleeveGrade: "P"},
+	}
+
+	sortUsedItems(items)
+
+	var ids []string
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	assert.Equal(t, []string{"mint", "sleeve", "cheap", "dear", "worn"}, ids)
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/tvergilio/motown-house-backend/money"
+)
+
+var (
+	// ErrWishlistNotFound is returned when no wishlist is shared under a token.
+	ErrWishlistNotFound = errors.New("wishlist not found")
+	// ErrWishlistItemNotFound is returned when removing an album that is not in the wishlist.
+	ErrWishlistItemNotFound = errors.New("album not in wishlist")
+)
+
+// Wishlist is the albums a customer has saved for later. Every user has one; it starts empty.
+// ShareToken is set while the wishlist is shared, and anyone with the token can view it.
+type Wishlist struct {
+	UserID     string         `db:"user_id" json:"userId,omitempty"`
+	ShareToken string         `db:"share_token" json:"shareToken,omitempty"`
+	Items      []WishlistItem `db:"-" json:"items"`
+	UpdatedAt  time.Time      `db:"updated_at" json:"updatedAt"`
+}
+
+// WishlistItem is one saved album. Only the album, note and date added are stored; the descriptive,
+// price and stock fields are filled in from the album every time the wishlist is viewed.
+type WishlistItem struct {
+	AlbumID string    `db:"album_id" json:"albumId"`
+	Note    string    `db:"note" json:"note,omitempty"`
+	AddedAt time.Time `db:"added_at" json:"addedAt"`
+
+	Title     string        `db:"-" json:"title,omitempty"`
+	Artist    string        `db:"-" json:"artist,omitempty"`
+	ImageUrl  string        `db:"-" json:"imageUrl,omitempty"`
+	Price     money.Amount  `db:"-" json:"price"`
+	Currency  string        `db:"-" json:"currency,omitempty"`
+	SalePrice *money.Amount `db:"-" json:"salePrice,omitempty"`
+	Promotion string        `db:"-" json:"promotion,omitempty"`
+	Stock     []StockLevel  `db:"-" json:"stock,omitempty"`
+	InStock   bool          `db:"-" json:"inStock"`
+}
+
+// sortWishlistItems orders items with the most recently added first.
+func sortWishlistItems(items []WishlistItem) {
+	sort.SliceStable(items, func(i, j int) bool { return items[i].AddedAt.After(items[j].AddedAt) })
+}
+
+// WishlistRepository stores wishlists. Get returns an empty wishlist for users who have never saved
+// anything. AddItem saves an album, or updates its note if it is already saved. SetShareToken shares
+// the wishlist under token, or stops sharing it if token is empty. AlbumsByUser returns the IDs of the
+// albums saved in every wishlist, keyed by user.
+type WishlistRepository interface {
+	Get(userID string) (Wishlist, error)
+	GetByShareToken(token string) (Wishlist, error)
+	AddItem(userID string, item WishlistItem) error
+	RemoveItem(userID, albumID string) error
+	SetShareToken(userID, token string) error
+	AlbumsByUser() (map[string][]string, error)
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSortWishlistItems tests that the most recently added albums come first
+func TestSortWishlistItems(t *testing.T) {
+	now := time.Now()
+	items := []WishlistItem{{AlbumID: "1", AddedAt: now.Add(-time.Hour)}, {AlbumID: "2", AddedAt: now}, {AlbumID: "3", AddedAt: now.Add(-2 * time.Hour)}}
+
+	sortWishlistItems(items)
+
+	assert.Equal(t, []string{"2", "1", "3"}, []string{items[0].AlbumID, items[1].AlbumID, items[2].AlbumID})
+}
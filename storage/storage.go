@@ -0,0 +1,31 @@
+// Package storage keeps uploaded files, such as album artwork, so the rest of the shop never depends
+// on where they are kept.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Store is implemented by each place files can be kept. Keys are slash-separated paths such as
+// "albums/1/front.jpg"; URL returns the address the file stored under a key is served from.
+type Store interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+// validKey checks a key is a relative path without empty, "." or ".." segments, so it cannot reach
+// outside the store.
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, `\`) {
+		return fmt.Errorf("invalid storage key %q", key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("invalid storage key %q", key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore(t *testing.T) {
+	dir := t.TempDir()
+	store := NewLocalStore(dir, "https://shop.example.com/artwork/")
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "albums/1/front.jpg", []byte("sleeve"), "image/jpeg"))
+
+	data, err := os.ReadFile(filepath.Join(dir, "albums", "1", "front.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, "sleeve", string(data))
+	assert.Equal(t, "https://shop.example.com/artwork/albums/1/front.jpg", store.URL("albums/1/front.jpg"))
+	entries, err := os.ReadDir(filepath.Join(dir, "albums", "1"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files are left behind")
+
+	require.NoError(t, store.Delete(ctx, "albums/1/front.jpg"))
+	_, err = os.Stat(filepath.Join(dir, "albums", "1", "front.jpg"))
+	assert.True(t, os.IsNotExist(err))
+	assert.NoError(t, store.Delete(ctx, "albums/1/front.jpg"), "deleting a missing file is not an error")
+}
+
+func TestLocalStore_RejectsKeysOutsideTheStore(t *testing.T) {
+	store := NewLocalStore(t.TempDir(), "/artwork")
+	for _, key := range []string{"", "../secret", "albums/../../secret", "/etc/passwd", "albums//1", `albums\1`} {
+		assert.Error(t, store.Put(context.Background(), key, []byte("x"), "image/jpeg"), key)
+		assert.Error(t, store.Delete(context.Background(), key), key)
+	}
+}
+
+// TestSigningKey checks the key derivation against the example in the AWS Signature Version 4 documentation.
+func TestSigningKey(t *testing.T) {
+	key := signingKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam")
+
+	assert.Equal(t, "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d", hex.EncodeToString(key))
+}
+
+func TestS3Store(t *testing.T) {
+	var method, path, contentType, auth, date, payloadHash, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.EscapedPath(), string(b)
+		contentType, auth = r.Header.Get("Content-Type"), r.Header.Get("Authorization")
+		date, payloadHash = r.Header.Get("X-Amz-Date"), r.Header.Get("X-Amz-Content-Sha256")
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+	store := NewS3Store(server.URL, "eu-west-2", "sleeves", "AKIDEXAMPLE", "secret", "")
+	store.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "albums/1/front cover.jpg", []byte("sleeve"), "image/jpeg"))
+
+	assert.Equal(t, http.MethodPut, method)
+	assert.Equal(t, "/sleeves/albums/1/front%20cover.jpg", path)
+	assert.Equal(t, "sleeve", body)
+	assert.Equal(t, "image/jpeg", contentType)
+	assert.Equal(t, "20240301T120000Z", date)
+	assert.Equal(t, sha256Hex([]byte("sleeve")), payloadHash)
+	assert.True(t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20240301/eu-west-2/s3/aws4_request, "+
+		"SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date, Signature="), auth)
+	assert.Equal(t, server.URL+"/sleeves/albums/1/front%20cover.jpg", store.URL("albums/1/front cover.jpg"))
+
+	require.NoError(t, store.Delete(ctx, "albums/1/front cover.jpg"))
+	assert.Equal(t, http.MethodDelete, method)
+	assert.Contains(t, auth, "SignedHeaders=host;x-amz-content-sha256;x-amz-date,")
+}
+
+func TestS3Store_ReportsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, "<Error><Code>SignatureDoesNotMatch</Code></Error>")
+	}))
+	defer server.Close()
+	store := NewS3Store(server.URL, "us-east-1", "sleeves", "AKIDEXAMPLE", "secret", "https://cdn.example.com/")
+
+	err := store.Put(context.Background(), "albums/1/front.jpg", []byte("sleeve"), "image/jpeg")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "SignatureDoesNotMatch")
+	assert.Equal(t, "https://cdn.example.com/albums/1/front.jpg", store.URL("albums/1/front.jpg"))
+}
@@ -0,0 +1,103 @@
+// Package tax works out the VAT or sales tax due on a sale from the rate table staff maintain. Like
+// the promotions package it is pure calculation: callers load the rates and store the results.
+package tax
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+// CategoryStandard is the product category of albums that have not been given one.
+const CategoryStandard = "standard"
+
+// Destination is where goods are shipped, which decides the tax charged on them.
+type Destination struct {
+	Country string
+	Region  string
+}
+
+// ParseDestination normalises an ISO 3166-1 alpha-2 country code and an optional region code
+// within it, such as "us" and "ca" to "US" and "CA".
+func ParseDestination(country, region string) (Destination, error) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if len(country) != 2 || !isLetters(country) {
+		return Destination{}, fmt.Errorf("invalid country %q: must be a two-letter ISO 3166 code", country)
+	}
+	return Destination{Country: country, Region: NormalizeRegion(region)}, nil
+}
+
+// NormalizeRegion makes region codes comparable regardless of case or surrounding spaces.
+func NormalizeRegion(region string) string {
+	return strings.ToUpper(strings.TrimSpace(region))
+}
+
+// NormalizeCategory makes product categories comparable regardless of case or surrounding spaces.
+func NormalizeCategory(category string) string {
+	return strings.ToLower(strings.TrimSpace(category))
+}
+
+func isLetters(s string) bool {
+	for _, r := range s {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Policy is how the shop's catalogue prices relate to tax. When PricesIncludeTax is set, album
+// prices already include the tax due at the destination and tax is worked out of them; otherwise
+// tax is added on top. Home is the destination assumed when the customer has not given one.
+type Policy struct {
+	PricesIncludeTax bool
+	Home             Destination
+}
+
+// Find returns the rate charged on goods of the category shipped to dest. A rate for the region
+// beats one for the whole country, and a rate for the category beats one for all categories.
+// It reports false when no rate applies, in which case no tax is due.
+func Find(rates []repository.TaxRate, dest Destination, category string) (repository.TaxRate, bool) {
+	category = NormalizeCategory(category)
+	if category == "" {
+		category = CategoryStandard
+	}
+	var best repository.TaxRate
+	bestScore := -1
+	for _, rate := range rates {
+		if rate.Country != dest.Country {
+			continue
+		}
+		if rate.Region != "" && rate.Region != dest.Region {
+			continue
+		}
+		if rate.Category != "" && rate.Category != category {
+			continue
+		}
+		score := 0
+		if rate.Region != "" {
+			score += 2
+		}
+		if rate.Category != "" {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = rate, score
+		}
+	}
+	return best, bestScore >= 0
+}
+
+// Split divides an amount into the price before tax and the tax at rate per cent. If the amount
+// includes tax the tax is worked out of it; otherwise it is added on top, rounded to the nearest
+// minor unit.
+func Split(amount money.Amount, rate float64, includesTax bool) (net, tax money.Amount) {
+	if !includesTax {
+		return amount, amount.Percent(rate)
+	}
+	net = money.FromMinor(int64(math.Round(float64(amount) * 100 / (100 + rate))))
+	return net, amount - net
+}
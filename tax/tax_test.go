@@ -0,0 +1,69 @@
+package tax
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tvergilio/motown-house-backend/money"
+	"github.com/tvergilio/motown-house-backend/repository"
+)
+
+var rates = []repository.TaxRate{
+	{Country: "GB", Name: "VAT", Rate: 20},
+	{Country: "GB", Category: "books", Name: "VAT", Rate: 0},
+	{Country: "DE", Name: "MwSt", Rate: 19},
+	{Country: "US", Region: "CA", Name: "California sales tax", Rate: 7.25},
+	{Country: "US", Region: "NY", Name: "New York sales tax", Rate: 4},
+	{Country: "US", Region: "NY", Category: "standard", Name: "New York sales tax", Rate: 8.875},
+}
+
+func TestParseDestination(t *testing.T) {
+	dest, err := ParseDestination(" us ", "ca")
+	require.NoError(t, err)
+	assert.Equal(t, Destination{Country: "US", Region: "CA"}, dest)
+
+	for _, country := range []string{"", "USA", "U1"} {
+		_, err := ParseDestination(country, "")
+		assert.Error(t, err, country)
+	}
+}
+
+func TestFind(t *testing.T) {
+	testCases := []struct {
+		name     string
+		dest     Destination
+		category string
+		expected float64
+		found    bool
+	}{
+		{"country rate", Destination{Country: "GB"}, "", 20, true},
+		{"category beats country", Destination{Country: "GB"}, "Books", 0, true},
+		{"region ignored where the country has one rate", Destination{Country: "DE", Region: "BE"}, "", 19, true},
+		{"region rate", Destination{Country: "US", Region: "CA"}, "", 7.25, true},
+		{"region and category beat region", Destination{Country: "US", Region: "NY"}, "standard", 8.875, true},
+		{"region without a rate", Destination{Country: "US", Region: "OR"}, "", 0, false},
+		{"country without a rate", Destination{Country: "JP"}, "", 0, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rate, found := Find(rates, tc.dest, tc.category)
+			assert.Equal(t, tc.found, found)
+			assert.Equal(t, tc.expected, rate.Rate)
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	net, tax := Split(money.MustParse("25.00"), 20, false)
+	assert.Equal(t, money.MustParse("25.00"), net)
+	assert.Equal(t, money.MustParse("5.00"), tax)
+
+	net, tax = Split(money.MustParse("25.99"), 20, true)
+	assert.Equal(t, money.MustParse("21.66"), net)
+	assert.Equal(t, money.MustParse("4.33"), tax)
+
+	net, tax = Split(money.MustParse("10.00"), 7.25, false)
+	assert.Equal(t, money.MustParse("10.00"), net)
+	assert.Equal(t, money.MustParse("0.73"), tax, "7.25% of 10.00 rounds to the nearest cent")
+}